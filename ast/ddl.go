@@ -23,6 +23,7 @@ var (
 	_ DDLNode = &CreateDatabaseStmt{}
 	_ DDLNode = &CreateIndexStmt{}
 	_ DDLNode = &CreateTableStmt{}
+	_ DDLNode = &CreateViewStmt{}
 	_ DDLNode = &DropDatabaseStmt{}
 	_ DDLNode = &DropIndexStmt{}
 	_ DDLNode = &DropTableStmt{}
@@ -398,9 +399,11 @@ type CreateTableStmt struct {
 	Cols        []*ColumnDef
 	Constraints []*Constraint
 	Options     []*TableOption
+	Partition   *PartitionOptions
 }
 
 // Accept implements Node Accept interface.
+// The partition expressions are not visited, because they are resolved against the new table in DDL.
 func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 	newNode, skipChildren := v.Enter(n)
 	if skipChildren {
@@ -436,6 +439,24 @@ func (n *CreateTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// PartitionDefinition defines a single partition.
+type PartitionDefinition struct {
+	Name model.CIStr
+	// LessThan is the upper bound of a range partition.
+	LessThan []ExprNode
+	// MaxValue is true for VALUES LESS THAN MAXVALUE.
+	MaxValue bool
+}
+
+// PartitionOptions is the PARTITION BY clause of the CREATE TABLE statement.
+// See https://dev.mysql.com/doc/refman/5.7/en/partitioning.html
+type PartitionOptions struct {
+	Tp          model.PartitionType
+	Expr        ExprNode
+	Num         uint64
+	Definitions []*PartitionDefinition
+}
+
 // DropTableStmt is a statement to drop one or more tables.
 // See https://dev.mysql.com/doc/refman/5.7/en/drop-table.html
 type DropTableStmt struct {
@@ -443,6 +464,7 @@ type DropTableStmt struct {
 
 	IfExists bool
 	Tables   []*TableName
+	IsView   bool
 }
 
 // Accept implements Node Accept interface.
@@ -462,6 +484,37 @@ func (n *DropTableStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateViewStmt is a statement to create a view.
+// See https://dev.mysql.com/doc/refman/5.7/en/create-view.html
+type CreateViewStmt struct {
+	ddlNode
+
+	OrReplace bool
+	ViewName  *TableName
+	Cols      []model.CIStr
+	Select    StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateViewStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateViewStmt)
+	node, ok := n.ViewName.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.ViewName = node.(*TableName)
+	selnode, ok := n.Select.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Select = selnode.(StmtNode)
+	return v.Leave(n)
+}
+
 // RenameTableStmt is a statement to rename a table.
 // See http://dev.mysql.com/doc/refman/5.7/en/rename-table.html
 type RenameTableStmt struct {
@@ -643,6 +696,9 @@ const (
 	AlterTableRenameTable
 	AlterTableAlterColumn
 	AlterTableLock
+	AlterTableAddPartitions
+	AlterTableDropPartition
+	AlterTableTruncatePartition
 
 // TODO: Add more actions
 )
@@ -672,6 +728,10 @@ type AlterTableSpec struct {
 	OldColumnName *ColumnName
 	Position      *ColumnPosition
 	LockType      LockType
+	// PartDefinitions are the partitions added by ADD PARTITION.
+	PartDefinitions []*PartitionDefinition
+	// PartitionNames are the partitions dropped or truncated.
+	PartitionNames []model.CIStr
 }
 
 // Accept implements Node Accept interface.
@@ -26,6 +26,7 @@ var (
 	_ DMLNode = &SelectStmt{}
 	_ DMLNode = &ShowStmt{}
 	_ DMLNode = &LoadDataStmt{}
+	_ DMLNode = &NonTransactionalDMLStmt{}
 
 	_ Node = &Assignment{}
 	_ Node = &ByItem{}
@@ -42,6 +43,8 @@ var (
 	_ Node = &TableSource{}
 	_ Node = &UnionSelectList{}
 	_ Node = &WildCardField{}
+	_ Node = &WithClause{}
+	_ Node = &CommonTableExpression{}
 )
 
 // JoinType is join type, including cross/left/right/full.
@@ -440,6 +443,56 @@ func (n *OrderByClause) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CommonTableExpression represents a named temporary result set defined in the WITH clause.
+// See https://dev.mysql.com/doc/refman/8.0/en/with.html
+type CommonTableExpression struct {
+	node
+
+	Name        model.CIStr
+	ColNameList []model.CIStr
+	Query       *SubqueryExpr
+}
+
+// Accept implements Node Accept interface.
+func (n *CommonTableExpression) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CommonTableExpression)
+	node, ok := n.Query.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.Query = node.(*SubqueryExpr)
+	return v.Leave(n)
+}
+
+// WithClause represents the WITH clause which defines common table expressions for a query.
+type WithClause struct {
+	node
+
+	IsRecursive bool
+	CTEs        []*CommonTableExpression
+}
+
+// Accept implements Node Accept interface.
+func (n *WithClause) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*WithClause)
+	for i, cte := range n.CTEs {
+		node, ok := cte.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.CTEs[i] = node.(*CommonTableExpression)
+	}
+	return v.Leave(n)
+}
+
 // SelectStmt represents the select query node.
 // See https://dev.mysql.com/doc/refman/5.7/en/select.html
 type SelectStmt struct {
@@ -468,6 +521,8 @@ type SelectStmt struct {
 	LockTp SelectLockType
 	// TableHints represents the level Optimizer Hint
 	TableHints []*TableOptimizerHint
+	// With is the WITH clause which defines common table expressions for the query.
+	With *WithClause
 }
 
 // Accept implements Node Accept interface.
@@ -478,6 +533,14 @@ func (n *SelectStmt) Accept(v Visitor) (Node, bool) {
 	}
 
 	n = newNode.(*SelectStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
+
 	if n.TableHints != nil && len(n.TableHints) != 0 {
 		newHints := make([]*TableOptimizerHint, len(n.TableHints))
 		for i, hint := range n.TableHints {
@@ -583,6 +646,7 @@ type UnionStmt struct {
 	SelectList *UnionSelectList
 	OrderBy    *OrderByClause
 	Limit      *Limit
+	With       *WithClause
 }
 
 // Accept implements Node Accept interface.
@@ -592,6 +656,13 @@ func (n *UnionStmt) Accept(v Visitor) (Node, bool) {
 		return v.Leave(newNode)
 	}
 	n = newNode.(*UnionStmt)
+	if n.With != nil {
+		node, ok := n.With.Accept(v)
+		if !ok {
+			return n, false
+		}
+		n.With = node.(*WithClause)
+	}
 	if n.SelectList != nil {
 		node, ok := n.SelectList.Accept(v)
 		if !ok {
@@ -887,6 +958,40 @@ func (n *UpdateStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// NonTransactionalDMLStmt is a statement to split a DELETE or UPDATE statement into batches by the values of
+// the shard column, each batch runs in its own transaction.
+// The syntax is "BATCH ON col LIMIT n DELETE/UPDATE ...".
+type NonTransactionalDMLStmt struct {
+	dmlNode
+
+	// ShardColumn is the column the rows are split by.
+	ShardColumn *ColumnName
+	// Limit is the number of the rows in a batch.
+	Limit uint64
+	// DMLStmt is the DeleteStmt or UpdateStmt.
+	DMLStmt StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *NonTransactionalDMLStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*NonTransactionalDMLStmt)
+	node, ok := n.ShardColumn.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.ShardColumn = node.(*ColumnName)
+	node, ok = n.DMLStmt.Accept(v)
+	if !ok {
+		return n, false
+	}
+	n.DMLStmt = node.(StmtNode)
+	return v.Leave(n)
+}
+
 // Limit is the limit clause.
 type Limit struct {
 	node
@@ -947,6 +1052,8 @@ const (
 	ShowStatsMeta
 	ShowStatsHistograms
 	ShowStatsBuckets
+	ShowCreateView
+	ShowBindings
 )
 
 // ShowStmt is a statement to provide information about databases, tables, columns and so on.
@@ -963,7 +1070,7 @@ type ShowStmt struct {
 	Full   bool
 	User   string // Used for show grants.
 
-	// GlobalScope is used by show variables
+	// GlobalScope is used by show variables and show bindings.
 	GlobalScope bool
 	Pattern     *PatternLikeExpr
 	Where       ExprNode
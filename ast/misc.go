@@ -75,6 +75,14 @@ type AuthOption struct {
 	// TODO: support auth_plugin
 }
 
+// Explain output formats.
+const (
+	// ExplainFormatJSON outputs every plan node as a JSON blob with its parent ID.
+	ExplainFormatJSON = "json"
+	// ExplainFormatRow outputs the physical plan as an indented operator tree.
+	ExplainFormatRow = "row"
+)
+
 // ExplainStmt is a statement to provide information about how is SQL statement executed
 // or get columns information in a table.
 // See https://dev.mysql.com/doc/refman/5.7/en/explain.html
@@ -82,6 +90,10 @@ type ExplainStmt struct {
 	stmtNode
 
 	Stmt StmtNode
+	// Format is the output format of EXPLAIN, it's one of ExplainFormatJSON and ExplainFormatRow.
+	Format string
+	// Analyze is true for EXPLAIN ANALYZE, which executes Stmt and reports its runtime statistics.
+	Analyze bool
 }
 
 // Accept implements Node Accept interface.
@@ -171,10 +183,22 @@ func (n *ExecuteStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// Transaction modes for BeginStmt.
+const (
+	// DefaultTxnMode means the transaction mode is decided by the tidb_txn_mode variable.
+	DefaultTxnMode = ""
+	// OptimisticTxnMode checks the write conflicts when the transaction commits.
+	OptimisticTxnMode = "OPTIMISTIC"
+	// PessimisticTxnMode locks the written rows when the DML statements run.
+	PessimisticTxnMode = "PESSIMISTIC"
+)
+
 // BeginStmt is a statement to start a new transaction.
 // See https://dev.mysql.com/doc/refman/5.7/en/commit.html
 type BeginStmt struct {
 	stmtNode
+
+	Mode string
 }
 
 // Accept implements Node Accept interface.
@@ -435,6 +459,18 @@ func (u *UserSpec) SecurityString() string {
 	return u.User
 }
 
+// RequireType is the type of the REQUIRE clause, which specifies whether a user must connect with SSL.
+type RequireType int
+
+// RequireType values.
+const (
+	// RequireNotSpecified means there is no REQUIRE clause, so the requirement isn't changed.
+	RequireNotSpecified RequireType = iota
+	RequireNone
+	RequireSSL
+	RequireX509
+)
+
 // CreateUserStmt creates user account.
 // See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 type CreateUserStmt struct {
@@ -442,6 +478,7 @@ type CreateUserStmt struct {
 
 	IfNotExists bool
 	Specs       []*UserSpec
+	Require     RequireType
 }
 
 // Accept implements Node Accept interface.
@@ -462,6 +499,7 @@ type AlterUserStmt struct {
 	IfExists    bool
 	CurrentAuth *AuthOption
 	Specs       []*UserSpec
+	Require     RequireType
 }
 
 // Accept implements Node Accept interface.
@@ -493,6 +531,46 @@ func (n *DropUserStmt) Accept(v Visitor) (Node, bool) {
 	return v.Leave(n)
 }
 
+// CreateBindingStmt creates a binding of the statements whose normalized form is the same as OriginSel to the
+// hints of HintedSel, the statements are optimized with the hints.
+// The syntax is "CREATE [GLOBAL|SESSION] BINDING FOR SelectStmt USING SelectStmt".
+type CreateBindingStmt struct {
+	stmtNode
+
+	GlobalScope bool
+	OriginSel   StmtNode
+	HintedSel   StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *CreateBindingStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*CreateBindingStmt)
+	return v.Leave(n)
+}
+
+// DropBindingStmt drops the binding of the statements whose normalized form is the same as OriginSel.
+// The syntax is "DROP [GLOBAL|SESSION] BINDING FOR SelectStmt".
+type DropBindingStmt struct {
+	stmtNode
+
+	GlobalScope bool
+	OriginSel   StmtNode
+}
+
+// Accept implements Node Accept interface.
+func (n *DropBindingStmt) Accept(v Visitor) (Node, bool) {
+	newNode, skipChildren := v.Enter(n)
+	if skipChildren {
+		return v.Leave(newNode)
+	}
+	n = newNode.(*DropBindingStmt)
+	return v.Leave(n)
+}
+
 // DoStmt is the struct for DO statement.
 type DoStmt struct {
 	stmtNode
@@ -524,14 +602,32 @@ type AdminStmtType int
 const (
 	AdminShowDDL = iota + 1
 	AdminCheckTable
+	AdminShowDDLJobs
+	AdminCancelDDLJobs
+	AdminCheckIndex
+	AdminRecoverIndex
+	AdminCleanupIndex
+	AdminPauseDDLJobs
+	AdminResumeDDLJobs
 )
 
+// HandleRange represents a range of handles, both Begin and End are included.
+type HandleRange struct {
+	Begin int64
+	End   int64
+}
+
 // AdminStmt is the struct for Admin statement.
 type AdminStmt struct {
 	stmtNode
 
 	Tp     AdminStmtType
 	Tables []*TableName
+	JobIDs []int64
+	// Index is the index name of the check, recover and cleanup index statements.
+	Index string
+	// HandleRanges is the handle ranges to check in the check index statement.
+	HandleRanges []HandleRange
 }
 
 // Accept implements Node Accpet interface.
@@ -644,6 +740,7 @@ type GrantStmt struct {
 	ObjectType ObjectTypeType
 	Level      *GrantLevel
 	Users      []*UserSpec
+	Require    RequireType
 	WithGrant  bool
 }
 
@@ -706,6 +803,14 @@ type TableOptimizerHint struct {
 	// It allows only table name or alias (if table has an alias)
 	HintName model.CIStr
 	Tables   []model.CIStr
+	// Indexes is the index names of USE_INDEX and IGNORE_INDEX hints.
+	Indexes []model.CIStr
+	// StoreType is the storage of READ_FROM_STORAGE hint, such as TIKV.
+	StoreType model.CIStr
+	// MaxExecutionTime is the timeout in milliseconds of MAX_EXECUTION_TIME hint.
+	MaxExecutionTime uint64
+	// InvalidArgs is set if the arguments of the hint can't be recognized, the hint is ignored.
+	InvalidArgs bool
 }
 
 // Accept implements Node Accept interface.
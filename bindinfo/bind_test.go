@@ -0,0 +1,71 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindinfo
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testBindSuite{})
+
+type testBindSuite struct{}
+
+func (s *testBindSuite) TestNewBindRecord(c *C) {
+	defer testleak.AfterTest(c)()
+	_, err := NewBindRecord("select * from t where a = 1", "select /*+ TIDB_INLJ(t) */ * from t where b = 1", "test", "", "")
+	c.Assert(err, NotNil)
+
+	r, err := NewBindRecord("select * from t join t1 on t.a = t1.a where t.b = 1",
+		"SELECT /*+ TIDB_SMJ(t, t1) */ * FROM t JOIN t1 ON t.a = t1.a WHERE t.b = 2", "test", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(r.OriginalSQL, Equals, "select * from t join t1 on t . a = t1 . a where t . b = ?")
+	c.Assert(r.Status, Equals, Using)
+	c.Assert(r.hints, HasLen, 1)
+	c.Assert(r.hints[0], HasLen, 1)
+
+	c.Assert(removeHints("select /*+ tidb_smj ( t ) */ * from t where a in ( select /*+ tidb_inlj ( t1 ) */ a from t1 )"),
+		Equals, "select * from t where a in ( select a from t1 )")
+}
+
+func (s *testBindSuite) TestApplyHints(c *C) {
+	defer testleak.AfterTest(c)()
+	r, err := NewBindRecord("select * from t where a in (select a from t1)",
+		"select /*+ TIDB_INLJ(t) */ * from t where a in (select /*+ TIDB_SMJ(t1) */ a from t1)", "test", "", "")
+	c.Assert(err, IsNil)
+
+	stmt, err := parser.New().ParseOneStmt("select * from t where a in (select a from t1)", "", "")
+	c.Assert(err, IsNil)
+	sel := stmt.(*ast.SelectStmt)
+	restore := r.ApplyHints(stmt)
+	c.Assert(sel.TableHints, HasLen, 1)
+	c.Assert(sel.TableHints[0].HintName.L, Equals, "tidb_inlj")
+	restore()
+	c.Assert(sel.TableHints, HasLen, 0)
+
+	// The hints aren't applied if the statement has different SelectStmts.
+	stmt, err = parser.New().ParseOneStmt("select * from t", "", "")
+	c.Assert(err, IsNil)
+	r.ApplyHints(stmt)
+	c.Assert(stmt.(*ast.SelectStmt).TableHints, HasLen, 0)
+}
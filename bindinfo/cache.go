@@ -0,0 +1,177 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bindinfo manages the SQL bindings. A binding binds the statements of the same normalized form to the
+// optimizer hints of a statement, so the hints are used without changing the SQL of the applications.
+package bindinfo
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// The status of the bindings in mysql.bind_info.
+const (
+	// Using means the binding is in use.
+	Using = "using"
+	// deleted means the binding is dropped, it's kept in mysql.bind_info to tell the other TiDB servers.
+	deleted = "deleted"
+)
+
+// BindRecord is a binding of the statements whose normalized form is OriginalSQL in the default database Db.
+type BindRecord struct {
+	// OriginalSQL is the normalized form of the bound statements.
+	OriginalSQL string
+	// BindSQL is the statement with the hints.
+	BindSQL    string
+	Db         string
+	Status     string
+	CreateTime types.Time
+	UpdateTime types.Time
+	Charset    string
+	Collation  string
+
+	// hints are the hints of the SelectStmts in BindSQL, in the order they are visited.
+	hints [][]*ast.TableOptimizerHint
+}
+
+// NewBindRecord creates a BindRecord which binds originSQL to the hints of bindSQL, the two statements must
+// be the same except the hints.
+func NewBindRecord(originSQL, bindSQL, db, charset, collation string) (*BindRecord, error) {
+	originSQL = parser.Normalize(originSQL)
+	if originSQL != removeHints(parser.Normalize(bindSQL)) {
+		return nil, errors.Errorf("the bind SQL %s doesn't match the original SQL", bindSQL)
+	}
+	record := &BindRecord{
+		OriginalSQL: originSQL,
+		BindSQL:     bindSQL,
+		Db:          db,
+		Status:      Using,
+		Charset:     charset,
+		Collation:   collation,
+	}
+	return record, errors.Trace(record.parseHints())
+}
+
+// removeHints removes the hints "/*+ ... */" from the normalized sql.
+func removeHints(normalized string) string {
+	for {
+		start := strings.Index(normalized, "/*+ ")
+		if start < 0 {
+			return normalized
+		}
+		end := strings.Index(normalized[start:], "*/")
+		if end < 0 {
+			return normalized
+		}
+		end += start + len("*/")
+		if end < len(normalized) && normalized[end] == ' ' {
+			end++
+		}
+		normalized = normalized[:start] + normalized[end:]
+	}
+}
+
+type hintsCollector struct {
+	hints [][]*ast.TableOptimizerHint
+	sels  []*ast.SelectStmt
+}
+
+func (c *hintsCollector) Enter(in ast.Node) (ast.Node, bool) {
+	if sel, ok := in.(*ast.SelectStmt); ok {
+		c.hints = append(c.hints, sel.TableHints)
+		c.sels = append(c.sels, sel)
+	}
+	return in, false
+}
+
+func (c *hintsCollector) Leave(in ast.Node) (ast.Node, bool) {
+	return in, true
+}
+
+func (r *BindRecord) parseHints() error {
+	stmt, err := parser.New().ParseOneStmt(r.BindSQL, r.Charset, r.Collation)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c := &hintsCollector{}
+	stmt.Accept(c)
+	r.hints = c.hints
+	return nil
+}
+
+// ApplyHints replaces the hints of the SelectStmts in stmt with the hints of the binding, and returns a function
+// to restore the original hints, because stmt may be a prepared statement which is optimized again later.
+func (r *BindRecord) ApplyHints(stmt ast.StmtNode) (restore func()) {
+	c := &hintsCollector{}
+	stmt.Accept(c)
+	if len(c.sels) != len(r.hints) {
+		return func() {}
+	}
+	for i, sel := range c.sels {
+		sel.TableHints = r.hints[i]
+	}
+	return func() {
+		for i, sel := range c.sels {
+			sel.TableHints = c.hints[i]
+		}
+	}
+}
+
+type bindKey struct {
+	originalSQL string
+	db          string
+}
+
+// bindCache is the bindings of a TiDB server or a session, it's read only after it's built.
+type bindCache map[bindKey]*BindRecord
+
+func (c bindCache) copy() bindCache {
+	newCache := make(bindCache, len(c))
+	for k, v := range c {
+		newCache[k] = v
+	}
+	return newCache
+}
+
+func (c bindCache) get(normalizedSQL, db string) *BindRecord {
+	return c[bindKey{originalSQL: normalizedSQL, db: db}]
+}
+
+func (c bindCache) put(record *BindRecord) {
+	c[bindKey{originalSQL: record.OriginalSQL, db: record.Db}] = record
+}
+
+func (c bindCache) remove(normalizedSQL, db string) {
+	delete(c, bindKey{originalSQL: normalizedSQL, db: db})
+}
+
+// records returns the bindings in order by the original SQL and the database.
+func (c bindCache) records() []*BindRecord {
+	records := make([]*BindRecord, 0, len(c))
+	for _, record := range c {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].OriginalSQL != records[j].OriginalSQL {
+			return records[i].OriginalSQL < records[j].OriginalSQL
+		}
+		return records[i].Db < records[j].Db
+	})
+	return records
+}
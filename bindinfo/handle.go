@@ -0,0 +1,176 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindinfo
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/util/sqlexec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// Handle is the global bindings of a TiDB server, the bindings are stored in mysql.bind_info.
+type Handle struct {
+	bindings atomic.Value
+
+	// mu serializes the updates of the bindings.
+	mu sync.Mutex
+	// lastUpdateTime is the max update_time of the loaded bindings, the next Update loads the bindings
+	// updated after it.
+	lastUpdateTime types.Time
+}
+
+// NewHandle returns a Handle.
+func NewHandle() *Handle {
+	h := &Handle{lastUpdateTime: types.ZeroTimestamp}
+	h.bindings.Store(make(bindCache))
+	return h
+}
+
+func (h *Handle) get() bindCache {
+	return h.bindings.Load().(bindCache)
+}
+
+// Size returns the number of the bindings.
+func (h *Handle) Size() int {
+	return len(h.get())
+}
+
+// GetBindRecord returns the binding of the normalized sql in the database db, it returns nil if there isn't one.
+func (h *Handle) GetBindRecord(normalizedSQL, db string) *BindRecord {
+	return h.get().get(normalizedSQL, db)
+}
+
+// GetAllBindRecords returns all the bindings.
+func (h *Handle) GetAllBindRecords() []*BindRecord {
+	return h.get().records()
+}
+
+// Update loads the bindings updated after the last Update from mysql.bind_info.
+func (h *Handle) Update(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	sql := fmt.Sprintf(`SELECT original_sql, bind_sql, default_db, status, create_time, update_time, charset, collation
+		FROM %s.%s WHERE update_time > "%s" ORDER BY update_time`, mysql.SystemDB, mysql.BindInfoTable, h.lastUpdateTime)
+	rs, err := ctx.(sqlexec.SQLExecutor).Execute(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer rs[0].Close()
+
+	newCache := h.get().copy()
+	for {
+		row, err := rs[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		record := &BindRecord{
+			OriginalSQL: row.Data[0].GetString(),
+			BindSQL:     row.Data[1].GetString(),
+			Db:          row.Data[2].GetString(),
+			Status:      row.Data[3].GetString(),
+			CreateTime:  row.Data[4].GetMysqlTime(),
+			UpdateTime:  row.Data[5].GetMysqlTime(),
+			Charset:     row.Data[6].GetString(),
+			Collation:   row.Data[7].GetString(),
+		}
+		if record.UpdateTime.Compare(h.lastUpdateTime) > 0 {
+			h.lastUpdateTime = record.UpdateTime
+		}
+		if record.Status != Using {
+			newCache.remove(record.OriginalSQL, record.Db)
+			continue
+		}
+		if err = record.parseHints(); err != nil {
+			log.Warnf("[bindinfo] parse bind sql %s failed %v", record.BindSQL, err)
+			continue
+		}
+		newCache.put(record)
+	}
+	h.bindings.Store(newCache)
+	return nil
+}
+
+func currentTimestamp() types.Time {
+	t := types.CurrentTime(mysql.TypeTimestamp)
+	t.Fsp = 3
+	t, err := t.RoundFrac(t.Fsp)
+	if err != nil {
+		log.Warnf("[bindinfo] round time %v failed %v", t, err)
+	}
+	return t
+}
+
+// AddBindRecord writes the binding to mysql.bind_info with ctx, and adds it to the bindings, it replaces the
+// binding of the same statement.
+func (h *Handle) AddBindRecord(ctx context.Context, record *BindRecord) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	record.CreateTime = currentTimestamp()
+	record.UpdateTime = record.CreateTime
+	sql := fmt.Sprintf(`REPLACE INTO %s.%s VALUES ("%s", "%s", "%s", "%s", "%s", "%s", "%s", "%s")`,
+		mysql.SystemDB, mysql.BindInfoTable, escape(record.OriginalSQL), escape(record.BindSQL), escape(record.Db),
+		record.Status, record.CreateTime, record.UpdateTime, record.Charset, record.Collation)
+	_, err := ctx.(sqlexec.SQLExecutor).Execute(sql)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	newCache := h.get().copy()
+	newCache.put(record)
+	h.bindings.Store(newCache)
+	return nil
+}
+
+// DropBindRecord marks the binding of the normalized sql in the database db as deleted in mysql.bind_info with ctx,
+// and removes it from the bindings. It returns false if there isn't the binding.
+func (h *Handle) DropBindRecord(ctx context.Context, normalizedSQL, db string) (bool, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.get().get(normalizedSQL, db) == nil {
+		return false, nil
+	}
+	sql := fmt.Sprintf(`UPDATE %s.%s SET status = "%s", update_time = "%s" WHERE original_sql = "%s" AND default_db = "%s"`,
+		mysql.SystemDB, mysql.BindInfoTable, deleted, currentTimestamp(), escape(normalizedSQL), escape(db))
+	_, err := ctx.(sqlexec.SQLExecutor).Execute(sql)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	newCache := h.get().copy()
+	newCache.remove(normalizedSQL, db)
+	h.bindings.Store(newCache)
+	return true, nil
+}
+
+// escape escapes the string in a double quoted string literal.
+func escape(s string) string {
+	var buf []byte
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\', '"':
+			buf = append(buf, '\\', s[i])
+		default:
+			buf = append(buf, s[i])
+		}
+	}
+	return string(buf)
+}
@@ -0,0 +1,80 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bindinfo
+
+import (
+	"github.com/pingcap/tidb/context"
+)
+
+// SessionHandle is the session bindings, they are used only by the session and override the global bindings.
+type SessionHandle struct {
+	bindings bindCache
+}
+
+// NewSessionHandle returns a SessionHandle.
+func NewSessionHandle() *SessionHandle {
+	return &SessionHandle{bindings: make(bindCache)}
+}
+
+// Size returns the number of the bindings.
+func (h *SessionHandle) Size() int {
+	return len(h.bindings)
+}
+
+// GetBindRecord returns the binding of the normalized sql in the database db, it returns nil if there isn't one.
+func (h *SessionHandle) GetBindRecord(normalizedSQL, db string) *BindRecord {
+	return h.bindings.get(normalizedSQL, db)
+}
+
+// GetAllBindRecords returns all the bindings.
+func (h *SessionHandle) GetAllBindRecords() []*BindRecord {
+	return h.bindings.records()
+}
+
+// AddBindRecord adds the binding, it replaces the binding of the same statement.
+func (h *SessionHandle) AddBindRecord(record *BindRecord) {
+	record.CreateTime = currentTimestamp()
+	record.UpdateTime = record.CreateTime
+	h.bindings.put(record)
+}
+
+// DropBindRecord drops the binding of the normalized sql in the database db, it returns false if there isn't one.
+func (h *SessionHandle) DropBindRecord(normalizedSQL, db string) bool {
+	if h.bindings.get(normalizedSQL, db) == nil {
+		return false
+	}
+	h.bindings.remove(normalizedSQL, db)
+	return true
+}
+
+type keyType int
+
+func (k keyType) String() string {
+	return "bindinfo-key"
+}
+
+const sessionBindInfoKey keyType = 0
+
+// BindSessionHandle binds the SessionHandle to ctx.
+func BindSessionHandle(ctx context.Context, h *SessionHandle) {
+	ctx.SetValue(sessionBindInfoKey, h)
+}
+
+// GetSessionHandle returns the SessionHandle of ctx, it returns nil if there isn't one.
+func GetSessionHandle(ctx context.Context) *SessionHandle {
+	if h, ok := ctx.Value(sessionBindInfoKey).(*SessionHandle); ok {
+		return h
+	}
+	return nil
+}
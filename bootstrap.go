@@ -65,6 +65,7 @@ const (
 		Create_user_priv		ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Event_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
 		Trigger_priv			ENUM('N','Y') NOT NULL DEFAULT 'N',
+		ssl_type			ENUM('','ANY','X509','SPECIFIED') NOT NULL DEFAULT '',
 		PRIMARY KEY (Host, User));`
 	// CreateDBPrivTable is the SQL statement creates DB scope privilege table in system db.
 	CreateDBPrivTable = `CREATE TABLE if not exists mysql.db (
@@ -159,6 +160,7 @@ const (
 		null_count bigint(64) NOT NULL DEFAULT 0,
 		modify_count bigint(64) NOT NULL DEFAULT 0,
 		version bigint(64) unsigned NOT NULL DEFAULT 0,
+		cm_sketch blob,
 		unique index tbl(table_id, is_index, hist_id)
 	);`
 
@@ -174,6 +176,21 @@ const (
 		lower_bound blob ,
 		unique index tbl(table_id, is_index, hist_id, bucket_id)
 	);`
+
+	// CreateBindInfoTable stores the SQL bindings, a dropped binding is marked as deleted so the other
+	// TiDB servers can find it when they load the updated bindings.
+	CreateBindInfoTable = `CREATE TABLE if not exists mysql.bind_info (
+		original_sql text NOT NULL,
+		bind_sql text NOT NULL,
+		default_db text NOT NULL,
+		status text NOT NULL,
+		create_time timestamp(3) NOT NULL,
+		update_time timestamp(3) NOT NULL,
+		charset text NOT NULL,
+		collation text NOT NULL,
+		unique index sql_index(original_sql(1024), default_db(1024)),
+		index time_index(update_time)
+	);`
 )
 
 // bootstrap initiates system DB for a store.
@@ -214,6 +231,9 @@ const (
 	version12 = 12
 	version13 = 13
 	version14 = 14
+	version15 = 15
+	version16 = 16
+	version17 = 17
 )
 
 func checkBootstrapped(s Session) (bool, error) {
@@ -324,6 +344,18 @@ func upgrade(s Session) {
 		upgradeToVer14(s)
 	}
 
+	if ver < version15 {
+		upgradeToVer15(s)
+	}
+
+	if ver < version16 {
+		upgradeToVer16(s)
+	}
+
+	if ver < version17 {
+		upgradeToVer17(s)
+	}
+
 	updateBootstrapVer(s)
 	_, err = s.Execute("COMMIT")
 
@@ -522,6 +554,18 @@ func upgradeToVer14(s Session) {
 	}
 }
 
+func upgradeToVer15(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.user ADD COLUMN `ssl_type` enum('','ANY','X509','SPECIFIED') CHARACTER SET utf8 NOT NULL DEFAULT '' AFTER `Trigger_priv`", infoschema.ErrColumnExists)
+}
+
+func upgradeToVer16(s Session) {
+	doReentrantDDL(s, "ALTER TABLE mysql.stats_histograms ADD COLUMN `cm_sketch` blob", infoschema.ErrColumnExists)
+}
+
+func upgradeToVer17(s Session) {
+	mustExecute(s, CreateBindInfoTable)
+}
+
 // updateBootstrapVer updates bootstrap version variable in mysql.TiDB table.
 func updateBootstrapVer(s Session) {
 	// Update bootstrap version.
@@ -566,6 +610,8 @@ func doDDLWorks(s Session) {
 	mustExecute(s, CreateStatsColsTable)
 	// Create stats_buckets table.
 	mustExecute(s, CreateStatsBucketsTable)
+	// Create bind_info table.
+	mustExecute(s, CreateBindInfoTable)
 }
 
 // doDMLWorks executes DML statements in bootstrap stage.
@@ -575,7 +621,7 @@ func doDMLWorks(s Session) {
 
 	// Insert a default user with empty password.
 	mustExecute(s, `INSERT INTO mysql.user VALUES
-		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y")`)
+		("%", "root", "", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "Y", "")`)
 
 	// Init global system variables table.
 	values := make([]string, 0, len(variable.SysVars))
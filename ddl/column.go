@@ -14,6 +14,9 @@
 package ddl
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
@@ -25,7 +28,9 @@ import (
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
@@ -428,41 +433,31 @@ func (d *ddl) onModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	newCol := &model.ColumnInfo{}
 	oldColName := &model.CIStr{}
 	pos := &ast.ColumnPosition{}
-	err := job.DecodeArgs(newCol, oldColName, pos)
+	var needReorg, strict bool
+	err := job.DecodeArgs(newCol, oldColName, pos, &needReorg, &strict)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
 	}
 
+	if needReorg {
+		return d.doReorgModifyColumn(t, job, newCol, oldColName, pos, strict)
+	}
 	return d.doModifyColumn(t, job, newCol, oldColName, pos)
 }
 
-// doModifyColumn updates the column information and reorders all columns.
-func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
-	tblInfo, err := getTableInfo(t, job, job.SchemaID)
-	if err != nil {
-		return ver, errors.Trace(err)
-	}
-
-	oldCol := findCol(tblInfo.Columns, oldName.L)
-	if oldCol == nil || oldCol.State != model.StatePublic {
-		job.State = model.JobCancelled
-		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
-	}
-
-	// Calculate column's new position.
+// getModifyColumnPosition returns the new offset of the column oldCol which is modified with the position pos.
+func getModifyColumnPosition(tblInfo *model.TableInfo, oldCol *model.ColumnInfo, pos *ast.ColumnPosition) (int, error) {
 	oldPos, newPos := oldCol.Offset, oldCol.Offset
 	if pos.Tp == ast.ColumnPositionAfter {
-		if oldName.L == pos.RelativeColumn.Name.L {
-			// `alter table tableName modify column b int after b` will return ver,ErrColumnNotExists.
-			job.State = model.JobCancelled
-			return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+		if oldCol.Name.L == pos.RelativeColumn.Name.L {
+			// `alter table tableName modify column b int after b` will return ErrColumnNotExists.
+			return 0, infoschema.ErrColumnNotExists.GenByArgs(oldCol.Name, tblInfo.Name)
 		}
 
 		relative := findCol(tblInfo.Columns, pos.RelativeColumn.Name.L)
 		if relative == nil || relative.State != model.StatePublic {
-			job.State = model.JobCancelled
-			return ver, infoschema.ErrColumnNotExists.GenByArgs(pos.RelativeColumn, tblInfo.Name)
+			return 0, infoschema.ErrColumnNotExists.GenByArgs(pos.RelativeColumn, tblInfo.Name)
 		}
 
 		if relative.Offset < oldPos {
@@ -473,23 +468,48 @@ func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo
 	} else if pos.Tp == ast.ColumnPositionFirst {
 		newPos = 0
 	}
+	return newPos, nil
+}
+
+// moveColumn moves the column at oldPos to newPos in place, the columns between them are shifted.
+func moveColumn(cols []*model.ColumnInfo, oldPos, newPos int) {
+	col := cols[oldPos]
+	if newPos < oldPos {
+		copy(cols[newPos+1:], cols[newPos:oldPos])
+	} else {
+		copy(cols[oldPos:], cols[oldPos+1:newPos+1])
+	}
+	cols[newPos] = col
+}
+
+// doModifyColumn updates the column information and reorders all columns.
+func (d *ddl) doModifyColumn(t *meta.Meta, job *model.Job, col *model.ColumnInfo, oldName *model.CIStr, pos *ast.ColumnPosition) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	oldCol := findCol(tblInfo.Columns, oldName.L)
+	if oldCol == nil || oldCol.State != model.StatePublic {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+	}
+
+	// Calculate column's new position.
+	oldPos := oldCol.Offset
+	newPos, err := getModifyColumnPosition(tblInfo, oldCol, pos)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
 
 	columnChanged := make(map[string]*model.ColumnInfo)
 	columnChanged[oldName.L] = col
 
-	if newPos == oldPos {
-		tblInfo.Columns[newPos] = col
-	} else {
-		cols := tblInfo.Columns
-
+	tblInfo.Columns[oldPos] = col
+	if newPos != oldPos {
 		// Reorder columns in place.
-		if newPos < oldPos {
-			copy(cols[newPos+1:], cols[newPos:oldPos])
-		} else {
-			copy(cols[oldPos:], cols[oldPos+1:newPos+1])
-		}
-		cols[newPos] = col
-
+		moveColumn(tblInfo.Columns, oldPos, newPos)
 		for i, col := range tblInfo.Columns {
 			if col.Offset != i {
 				columnChanged[col.Name.L] = col
@@ -561,3 +581,646 @@ func allocateColumnID(tblInfo *model.TableInfo) int64 {
 	tblInfo.MaxColumnID++
 	return tblInfo.MaxColumnID
 }
+
+const (
+	// changingColumnPrefix and changingIndexPrefix are the name prefixes of the hidden column and indices which are
+	// created by a modify column job to hold the converted data, they're renamed when the original ones are replaced.
+	changingColumnPrefix = "_Col$_"
+	changingIndexPrefix  = "_Idx$_"
+)
+
+// genChangingName generates the name of a changing column or index, the name doesn't conflict with the existing ones.
+func genChangingName(prefix string, name model.CIStr, exists func(nameL string) bool) model.CIStr {
+	newName := prefix + name.O
+	for i := 1; exists(strings.ToLower(newName)); i++ {
+		newName = fmt.Sprintf("%s%s_%d", prefix, name.O, i)
+	}
+	return model.NewCIStr(newName)
+}
+
+// getChangingColumn returns the column which is being filled by a modify column job.
+func getChangingColumn(tblInfo *model.TableInfo) *model.ColumnInfo {
+	for _, col := range tblInfo.Columns {
+		if col.ChangeStateInfo != nil && !col.ChangeStateInfo.Obsolete {
+			return col
+		}
+	}
+	return nil
+}
+
+// findColumnIndices returns the indices which contain the column colName in the order of the table indices.
+func findColumnIndices(tblInfo *model.TableInfo, colName string) []*model.IndexInfo {
+	var indices []*model.IndexInfo
+	for _, idx := range tblInfo.Indices {
+		if findIndexColumn(idx, colName) != nil {
+			indices = append(indices, idx)
+		}
+	}
+	return indices
+}
+
+// resetIndexColumnOffsets sets the offsets of the index columns by their names after the columns are reordered.
+func resetIndexColumnOffsets(tblInfo *model.TableInfo) {
+	for _, idx := range tblInfo.Indices {
+		for _, ic := range idx.Columns {
+			ic.Offset = findCol(tblInfo.Columns, ic.Name.L).Offset
+		}
+	}
+}
+
+// removeColumnAndIndices removes the column and the indices from the table.
+func removeColumnAndIndices(tblInfo *model.TableInfo, colInfo *model.ColumnInfo, indices []*model.IndexInfo) {
+	newIndices := make([]*model.IndexInfo, 0, len(tblInfo.Indices))
+	for _, idx := range tblInfo.Indices {
+		if findIndexByName(idx.Name.L, indices) == nil {
+			newIndices = append(newIndices, idx)
+		}
+	}
+	tblInfo.Indices = newIndices
+
+	newCols := make([]*model.ColumnInfo, 0, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if col.ID != colInfo.ID {
+			col.Offset = len(newCols)
+			newCols = append(newCols, col)
+		}
+	}
+	tblInfo.Columns = newCols
+	resetIndexColumnOffsets(tblInfo)
+}
+
+// setChangingState sets the state of the column and the indices.
+func setChangingState(colInfo *model.ColumnInfo, indices []*model.IndexInfo, state model.SchemaState) {
+	colInfo.State = state
+	for _, idx := range indices {
+		idx.State = state
+	}
+}
+
+// doReorgModifyColumn modifies the column by reorganizing the data, it's used when the existing data need to be
+// converted or checked. The steps are:
+//  1. Add a hidden changing column with the new definition, and a hidden copy of every index on the column.
+//     The value of the changing column is converted from the original column whenever a row is written.
+//  2. Backfill the changing column and its indices in the write reorganization state, the conversion errors
+//     are returned in the strict SQL mode and the job is rolled back.
+//  3. Replace the original column and indices with the changing ones atomically.
+//  4. Drop the original column and indices like a drop column job.
+func (d *ddl) doReorgModifyColumn(t *meta.Meta, job *model.Job, newCol *model.ColumnInfo, oldName *model.CIStr,
+	pos *ast.ColumnPosition, strict bool) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	// Handle rollback job.
+	if job.State == model.JobRollback {
+		ver, err = d.rollbackChangingColumn(t, job, tblInfo)
+		return ver, errors.Trace(err)
+	}
+
+	// The new column has the ID of the original column, the ID is kept by the original column until it's dropped.
+	var oldCol *model.ColumnInfo
+	for _, col := range tblInfo.Columns {
+		if col.ID == newCol.ID {
+			oldCol = col
+			break
+		}
+	}
+	if oldCol == nil {
+		job.State = model.JobCancelled
+		return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+	}
+	if oldCol.State != model.StatePublic {
+		// The original column has been replaced, drop it.
+		ver, err = d.dropObsoleteColumn(t, job, tblInfo, oldCol)
+		return ver, errors.Trace(err)
+	}
+
+	changingCol := getChangingColumn(tblInfo)
+	if changingCol == nil {
+		if oldCol.Name.L != oldName.L {
+			job.State = model.JobCancelled
+			return ver, infoschema.ErrColumnNotExists.GenByArgs(oldName, tblInfo.Name)
+		}
+		if _, err = getModifyColumnPosition(tblInfo, oldCol, pos); err != nil {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(err)
+		}
+		changingCol = createChangingColumn(tblInfo, oldCol, newCol)
+	}
+	changingIdxs := findColumnIndices(tblInfo, changingCol.Name.L)
+
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateNone:
+		// none -> delete only
+		job.SchemaState = model.StateDeleteOnly
+		setChangingState(changingCol, changingIdxs, model.StateDeleteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> write only
+		job.SchemaState = model.StateWriteOnly
+		setChangingState(changingCol, changingIdxs, model.StateWriteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteOnly:
+		// write only -> reorganization
+		job.SchemaState = model.StateWriteReorganization
+		setChangingState(changingCol, changingIdxs, model.StateWriteReorganization)
+		// Initialize SnapshotVer to 0 for later reorganization check.
+		job.SnapshotVer = 0
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateWriteReorganization:
+		// reorganization -> public
+		reorgInfo, err := d.getReorgInfo(t, job)
+		if err != nil || reorgInfo.first {
+			// If we run reorg firstly, we should update the job snapshot version
+			// and then run the reorg next time.
+			return ver, errors.Trace(err)
+		}
+
+		var tbl table.Table
+		tbl, err = d.getTable(job.SchemaID, tblInfo)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		err = d.runReorgJob(job, func() error {
+			return d.backfillChangingColumn(tbl.(table.PhysicalTable), oldCol, changingCol, changingIdxs, strict, reorgInfo, job)
+		})
+		var newPos int
+		if err == nil {
+			newPos, err = getModifyColumnPosition(tblInfo, oldCol, pos)
+		}
+		if err != nil {
+			if terror.ErrorEqual(err, errWaitReorgTimeout) {
+				// if timeout, we should return, check for the owner and re-wait job done.
+				return ver, nil
+			}
+			if isModifyColumnDataError(err) {
+				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
+				ver, err = d.convertModifyColumn2RollbackJob(t, job, tblInfo, err)
+			}
+			return ver, errors.Trace(err)
+		}
+
+		job.SetProgress(100)
+		replaceWithChangingColumn(tblInfo, oldCol, changingCol, newCol.Name, newPos)
+		job.SchemaState = model.StatePublic
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+
+	return ver, errors.Trace(err)
+}
+
+// createChangingColumn adds the changing column of newCol and the copies of the indices on oldCol to the table,
+// the changing column is the last column of the table.
+func createChangingColumn(tblInfo *model.TableInfo, oldCol, newCol *model.ColumnInfo) *model.ColumnInfo {
+	changingCol := newCol.Clone()
+	changingCol.ID = allocateColumnID(tblInfo)
+	changingCol.Name = genChangingName(changingColumnPrefix, newCol.Name, func(name string) bool {
+		return findCol(tblInfo.Columns, name) != nil
+	})
+	changingCol.Offset = len(tblInfo.Columns)
+	changingCol.State = model.StateNone
+	changingCol.Flag |= oldCol.Flag & (mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag)
+	// The rows which miss the changing column are always backfilled, so it has no origin default value.
+	changingCol.OriginDefaultValue = nil
+	changingCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: oldCol.Offset}
+	tblInfo.Columns = append(tblInfo.Columns, changingCol)
+
+	for _, idx := range findColumnIndices(tblInfo, oldCol.Name.L) {
+		changingIdx := idx.Clone()
+		changingIdx.ID = allocateIndexID(tblInfo)
+		changingIdx.Name = genChangingName(changingIndexPrefix, idx.Name, func(name string) bool {
+			return findIndexByName(name, tblInfo.Indices) != nil
+		})
+		changingIdx.Primary = false
+		changingIdx.State = model.StateNone
+		for _, ic := range changingIdx.Columns {
+			if ic.Name.L != oldCol.Name.L {
+				continue
+			}
+			ic.Name = changingCol.Name
+			ic.Offset = changingCol.Offset
+			// The prefix length is removed if it's not valid for the new type.
+			if !types.IsTypePrefixable(changingCol.Tp) || (!types.IsTypeBlob(changingCol.Tp) && ic.Length >= changingCol.Flen) {
+				ic.Length = types.UnspecifiedLength
+			}
+		}
+		tblInfo.Indices = append(tblInfo.Indices, changingIdx)
+	}
+	return changingCol
+}
+
+// replaceWithChangingColumn replaces the original column and indices with the changing ones, the changing column
+// is renamed to newName and moved to newPos. The original column becomes an obsolete write only column which is
+// converted from the new column, so the servers which use the previous schema can still read it.
+func replaceWithChangingColumn(tblInfo *model.TableInfo, oldCol, changingCol *model.ColumnInfo, newName model.CIStr, newPos int) {
+	oldIdxs := findColumnIndices(tblInfo, oldCol.Name.L)
+	changingIdxs := findColumnIndices(tblInfo, changingCol.Name.L)
+	for i, changingIdx := range changingIdxs {
+		idx := oldIdxs[i]
+		for _, ic := range changingIdx.Columns {
+			if ic.Name.L == changingCol.Name.L {
+				ic.Name = newName
+			}
+		}
+		for _, ic := range idx.Columns {
+			if ic.Name.L == oldCol.Name.L {
+				ic.Name = changingCol.Name
+			}
+		}
+		idx.Name, changingIdx.Name = changingIdx.Name, idx.Name
+		changingIdx.Primary, idx.Primary = idx.Primary, false
+		changingIdx.State = model.StatePublic
+		idx.State = model.StateWriteOnly
+	}
+
+	oldCol.Name, changingCol.Name = changingCol.Name, newName
+	oldPos := oldCol.Offset
+	tblInfo.Columns[oldPos], tblInfo.Columns[changingCol.Offset] = changingCol, oldCol
+	moveColumn(tblInfo.Columns, oldPos, newPos)
+	for i, col := range tblInfo.Columns {
+		col.Offset = i
+	}
+	resetIndexColumnOffsets(tblInfo)
+
+	changingCol.State = model.StatePublic
+	changingCol.ChangeStateInfo = nil
+	oldCol.State = model.StateWriteOnly
+	oldCol.ChangeStateInfo = &model.ChangeStateInfo{DependencyColumnOffset: changingCol.Offset, Obsolete: true}
+}
+
+// dropObsoleteColumn drops the original column and indices which have been replaced. The job has finished the
+// modification, so its schema state is kept public, and the schema version is updated by the column state.
+func (d *ddl) dropObsoleteColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, oldCol *model.ColumnInfo) (ver int64, err error) {
+	oldIdxs := findColumnIndices(tblInfo, oldCol.Name.L)
+	originalState := oldCol.State
+	switch oldCol.State {
+	case model.StateWriteOnly:
+		// write only -> delete only
+		setChangingState(oldCol, oldIdxs, model.StateDeleteOnly)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		setChangingState(oldCol, oldIdxs, model.StateDeleteReorganization)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		// Deleting the indices counts the deleted keys in the job, the row count of the job is kept as the
+		// number of the converted rows.
+		rowCount := job.GetRowCount()
+		err = d.runReorgJob(job, func() error {
+			return d.dropTableIndices(tblInfo, oldIdxs, job)
+		})
+		job.SetRowCount(rowCount)
+		if err != nil {
+			// If the timeout happens, we should return.
+			// Then check for the owner and re-wait job to finish.
+			return ver, errors.Trace(filterError(err, errWaitReorgTimeout))
+		}
+
+		removeColumnAndIndices(tblInfo, oldCol, oldIdxs)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		// Finish this job.
+		job.SetProgress(100)
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", oldCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+func (d *ddl) dropTableIndices(tblInfo *model.TableInfo, indices []*model.IndexInfo, job *model.Job) error {
+	for _, idx := range indices {
+		if err := d.dropTableIndex(tblInfo, idx, job); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// isModifyColumnDataError checks if the error is caused by the data which can't be converted to the new column,
+// the job is rolled back when it happens.
+func isModifyColumnDataError(err error) bool {
+	if terror.ErrorEqual(err, errInvalidUseOfNull) || terror.ErrorEqual(err, kv.ErrKeyExists) {
+		return true
+	}
+	tErr, ok := errors.Cause(err).(*terror.Error)
+	return ok && tErr.Class() == terror.ClassTypes
+}
+
+// convertModifyColumn2RollbackJob converts the modify column job to a rollback job which drops the changing column
+// and its indices. If no data is written to them, they're dropped directly.
+func (d *ddl) convertModifyColumn2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, err error) (ver int64, _ error) {
+	changingCol := getChangingColumn(tblInfo)
+	if changingCol == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	changingIdxs := findColumnIndices(tblInfo, changingCol.Name.L)
+
+	originalState := job.SchemaState
+	if changingCol.State == model.StateDeleteOnly {
+		removeColumnAndIndices(tblInfo, changingCol, changingIdxs)
+		job.SchemaState = model.StateNone
+		ver, err1 := updateTableInfo(t, job, tblInfo, originalState)
+		if err1 != nil {
+			return ver, errors.Trace(err1)
+		}
+		job.State = model.JobRollbackDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+		return ver, errors.Trace(err)
+	}
+
+	// The changing column may have been written, it's dropped in the same way as the drop column job.
+	job.State = model.JobRollback
+	job.SchemaState = model.StateDeleteOnly
+	setChangingState(changingCol, changingIdxs, model.StateDeleteOnly)
+	ver, err1 := updateTableInfo(t, job, tblInfo, originalState)
+	if err1 != nil {
+		return ver, errors.Trace(err1)
+	}
+	return ver, errors.Trace(err)
+}
+
+// rollbackChangingColumn drops the changing column and its indices of the rollback job.
+func (d *ddl) rollbackChangingColumn(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo) (ver int64, err error) {
+	changingCol := getChangingColumn(tblInfo)
+	if changingCol == nil {
+		job.State = model.JobRollbackDone
+		return ver, nil
+	}
+	changingIdxs := findColumnIndices(tblInfo, changingCol.Name.L)
+
+	originalState := changingCol.State
+	switch changingCol.State {
+	case model.StateDeleteOnly:
+		// delete only -> reorganization
+		job.SchemaState = model.StateDeleteReorganization
+		setChangingState(changingCol, changingIdxs, model.StateDeleteReorganization)
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+	case model.StateDeleteReorganization:
+		// reorganization -> absent
+		err = d.runReorgJob(job, func() error {
+			return d.dropTableIndices(tblInfo, changingIdxs, job)
+		})
+		if err != nil {
+			// If the timeout happens, we should return.
+			// Then check for the owner and re-wait job to finish.
+			return ver, errors.Trace(filterError(err, errWaitReorgTimeout))
+		}
+
+		removeColumnAndIndices(tblInfo, changingCol, changingIdxs)
+		job.SchemaState = model.StateNone
+		ver, err = updateTableInfo(t, job, tblInfo, originalState)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+
+		job.State = model.JobRollbackDone
+		job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	default:
+		err = ErrInvalidColumnState.Gen("invalid column state %v", changingCol.State)
+	}
+	return ver, errors.Trace(err)
+}
+
+// rollbackModifyColumn rolls back the modify column job which is cancelled by users.
+func (d *ddl) rollbackModifyColumn(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	// The arguments are decoded so they're kept when the job is updated.
+	newCol := &model.ColumnInfo{}
+	oldColName := &model.CIStr{}
+	pos := &ast.ColumnPosition{}
+	var needReorg, strict bool
+	err = job.DecodeArgs(newCol, oldColName, pos, &needReorg, &strict)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	if job.SchemaState == model.StatePublic {
+		// The original column has been replaced, the job can't be rolled back, go on dropping the original column.
+		log.Warnf("[ddl] the DDL job %v can't be cancelled after the column is replaced", job)
+		job.State = model.JobRunning
+		return d.onModifyColumn(t, job)
+	}
+	if job.SchemaState == model.StateWriteReorganization {
+		d.stopReorgJob(job, reorgCancelled)
+	}
+	ver, err = d.convertModifyColumn2RollbackJob(t, job, tblInfo, errCancelledDDLJob)
+	return ver, errors.Trace(err)
+}
+
+// backfillChangingColumn converts the values of oldCol to the type of changingCol, and writes them and the entries
+// of changingIdxs for the rows in the snapshot of the reorganization. The rows are handled in batches, a batch is
+// committed with the reorganization handle in a transaction, so the reorganization can be resumed after the batch.
+func (d *ddl) backfillChangingColumn(t table.PhysicalTable, oldCol, changingCol *model.ColumnInfo, changingIdxs []*model.IndexInfo,
+	strict bool, reorgInfo *reorgInfo, job *model.Job) error {
+	tblInfo := t.Meta()
+	colMap := make(map[int64]*types.FieldType, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		colMap[col.ID] = &col.FieldType
+	}
+	indices := make([]table.Index, 0, len(changingIdxs))
+	for _, idxInfo := range changingIdxs {
+		indices = append(indices, tables.NewIndex(t.GetPhysicalID(), tblInfo, idxInfo))
+	}
+	backfiller := &changingColumnBackfiller{
+		ctx:         d.newContext(),
+		t:           t,
+		colMap:      colMap,
+		oldCol:      oldCol,
+		changingCol: changingCol,
+		indices:     indices,
+		strict:      strict,
+	}
+
+	// Unlike adding index, the rows with negative handles must be reorganized too,
+	// so the reorganization starts from the min handle if it's not resumed.
+	seekHandle := reorgInfo.Handle
+	if seekHandle == 0 {
+		seekHandle = math.MinInt64
+	}
+	firstHandle, lastHandle, err := d.getTableHandleRange(t, reorgInfo.SnapshotVer, math.MinInt64)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	count := job.GetRowCount()
+	for {
+		params := d.getBackfillParams()
+		batchSize := params.batchSize
+		if params.rateLimit > 0 && batchSize > params.rateLimit {
+			batchSize = params.rateLimit
+		}
+
+		startTime := time.Now()
+		handles := make([]int64, 0, batchSize)
+		err = d.iterateSnapshotRows(t, reorgInfo.SnapshotVer, seekHandle,
+			func(h int64, rowKey kv.Key, rawRecord []byte) (bool, error) {
+				handles = append(handles, h)
+				return len(handles) < batchSize, nil
+			})
+		if err != nil {
+			return errors.Trace(err)
+		} else if len(handles) == 0 {
+			return nil
+		}
+
+		doneHandle := handles[len(handles)-1]
+		err = kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+			if err1 := d.isReorgRunnable(txn, ddlJobFlag); err1 != nil {
+				return errors.Trace(err1)
+			}
+			for _, h := range handles {
+				if err1 := backfiller.backfillRow(txn, h); err1 != nil {
+					return errors.Trace(err1)
+				}
+			}
+			// Update the reorg handle that has been processed, the reorganization continues from the next handle
+			// when it's restarted, see getReorgInfo.
+			return errors.Trace(reorgInfo.UpdateHandle(txn, doneHandle))
+		})
+		sub := time.Since(startTime).Seconds()
+		if err != nil {
+			log.Warnf("[ddl] modified column for %d rows, this batch of %d rows failed, take time %v", count, len(handles), sub)
+			return errors.Trace(err)
+		}
+
+		count += int64(len(handles))
+		d.setReorgRowCount(count)
+		if lastHandle > firstHandle {
+			ratio := (float64(doneHandle) - float64(firstHandle)) / (float64(lastHandle) - float64(firstHandle))
+			d.setReorgProgress(reorgInfo.progress(math.Min(ratio, 1)))
+		}
+		batchHandleDataHistogram.WithLabelValues(batchModifyCol).Observe(sub)
+		log.Infof("[ddl] modified column for %d rows, this batch modified %d rows, take time %v", count, len(handles), sub)
+
+		if len(handles) < batchSize || doneHandle == math.MaxInt64 {
+			return nil
+		}
+		seekHandle = doneHandle + 1
+		if err = d.throttleBackfill(int64(len(handles)), time.Since(startTime), params.rateLimit); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// changingColumnBackfiller converts the value of the original column of a row and writes it to the changing column.
+type changingColumnBackfiller struct {
+	ctx         context.Context
+	t           table.PhysicalTable
+	colMap      map[int64]*types.FieldType
+	oldCol      *model.ColumnInfo
+	changingCol *model.ColumnInfo
+	indices     []table.Index
+	strict      bool
+}
+
+func (b *changingColumnBackfiller) backfillRow(txn kv.Transaction, h int64) error {
+	rowKey := b.t.RecordKey(h)
+	rowVal, err := txn.Get(rowKey)
+	if err != nil {
+		if terror.ErrorEqual(err, kv.ErrNotExist) {
+			// If row doesn't exist, skip it.
+			return nil
+		}
+		return errors.Trace(err)
+	}
+	row, err := tablecodec.DecodeRow(rowVal, b.colMap, time.UTC)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	oldVal, ok := row[b.oldCol.ID]
+	if !ok {
+		oldVal, err = table.GetColOriginDefaultValue(b.ctx, b.oldCol)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if b.strict && oldVal.IsNull() && mysql.HasNotNullFlag(b.changingCol.Flag) {
+		return errInvalidUseOfNull
+	}
+	sc := &variable.StatementContext{TruncateAsWarning: !b.strict}
+	newVal, err := table.CastChangingValue(sc, oldVal, b.changingCol)
+	if err != nil {
+		if _, ok := errors.Cause(err).(*terror.Error); !ok {
+			err = types.ErrTruncated.Gen("Data truncated for column '%s': %v", b.oldCol.Name.O, err)
+		}
+		return errors.Trace(err)
+	}
+	row[b.changingCol.ID] = newVal
+
+	colIDs := make([]int64, 0, len(row))
+	vals := make([]types.Datum, 0, len(row))
+	for colID, val := range row {
+		colIDs = append(colIDs, colID)
+		vals = append(vals, val)
+	}
+	newRowVal, err := tablecodec.EncodeRow(vals, colIDs, time.UTC)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if err = txn.Set(rowKey, newRowVal); err != nil {
+		return errors.Trace(err)
+	}
+
+	if len(b.indices) == 0 {
+		return nil
+	}
+	tblInfo := b.t.Meta()
+	data := make([]types.Datum, len(tblInfo.Columns))
+	for _, col := range tblInfo.Columns {
+		if val, ok := row[col.ID]; ok {
+			data[col.Offset] = val
+		} else if tblInfo.PKIsHandle && mysql.HasPriKeyFlag(col.Flag) {
+			// The integer primary key isn't stored in the row, it's the handle.
+			if mysql.HasUnsignedFlag(col.Flag) {
+				data[col.Offset].SetUint64(uint64(h))
+			} else {
+				data[col.Offset].SetInt64(h)
+			}
+		} else if col.State == model.StatePublic {
+			data[col.Offset], err = table.GetColOriginDefaultValue(b.ctx, col)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	for _, idx := range b.indices {
+		idxVals, err := idx.FetchValues(data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		handle, err := idx.Create(txn, idxVals, h)
+		if err != nil {
+			if terror.ErrorEqual(err, kv.ErrKeyExists) {
+				if handle == h {
+					// Index already exists, skip it.
+					continue
+				}
+				name := strings.TrimPrefix(idx.Meta().Name.O, changingIndexPrefix)
+				return kv.ErrKeyExists.Gen("Duplicate for key %s", name)
+			}
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -21,11 +21,14 @@ import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/meta/autoid"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
@@ -368,3 +371,121 @@ func datumsToInterfaces(datums []types.Datum) []interface{} {
 	}
 	return ifs
 }
+
+func (s *testColumnChangeSuite) TestModifyColumnReorg(c *C) {
+	defer testleak.AfterTest(c)()
+	d := newDDL(goctx.Background(), nil, s.store, nil, nil, testLease)
+	defer d.Stop()
+	// create table t_modify (c1 int, c2 int, index c2(c2));
+	tblInfo := testTableInfo(c, d, "t_modify", 2)
+	ctx := testNewContext(d)
+	testCreateTable(c, ctx, d, s.dbInfo, tblInfo)
+	testCreateIndex(c, ctx, d, s.dbInfo, tblInfo, false, "c2", "c2")
+	// insert t_modify values (1, 1);
+	err := ctx.NewTxn()
+	c.Assert(err, IsNil)
+	originTable := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	h, err := originTable.AddRecord(ctx, types.MakeDatums(1, 1))
+	c.Assert(err, IsNil)
+	err = ctx.Txn().Commit()
+	c.Assert(err, IsNil)
+
+	tc := &testDDLCallback{}
+	prevState := model.StateNone
+	var (
+		checkErr     error
+		deletedRow   []types.Datum
+		deletedH     int64
+		insertedVals = int64(1)
+		rowCount     = int64(-1)
+	)
+	// Insert and update rows in every state, the values of the changing column must be kept
+	// consistent with the original column.
+	tc.onJobUpdated = func(job *model.Job) {
+		if job.SchemaState == prevState || job.Type != model.ActionModifyColumn {
+			return
+		}
+		prevState = job.SchemaState
+		if job.SchemaState == model.StatePublic && rowCount < 0 {
+			rowCount = job.GetRowCount()
+		}
+		hookCtx := mock.NewContext()
+		hookCtx.Store = s.store
+		checkErr = func() error {
+			if err := hookCtx.NewTxn(); err != nil {
+				return errors.Trace(err)
+			}
+			t, err := getCurrentTable(d, s.dbInfo.ID, tblInfo.ID)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			insertedVals++
+			var newVal types.Datum
+			if job.SchemaState == model.StatePublic {
+				newVal = types.NewStringDatum(fmt.Sprintf("%d", insertedVals*10))
+			} else {
+				newVal = types.NewIntDatum(insertedVals * 10)
+			}
+			newH, err := t.AddRecord(hookCtx, []types.Datum{types.NewIntDatum(insertedVals), newVal})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			oldRow, err := t.Row(hookCtx, h)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			newRow := []types.Datum{oldRow[0], newVal}
+			if err = t.UpdateRecord(hookCtx, h, oldRow, newRow, map[int]bool{1: true}); err != nil {
+				return errors.Trace(err)
+			}
+			switch job.SchemaState {
+			case model.StateDeleteOnly:
+				deletedRow, deletedH = []types.Datum{types.NewIntDatum(insertedVals), newVal}, newH
+			case model.StateWriteReorganization:
+				if err = t.RemoveRecord(hookCtx, deletedH, deletedRow); err != nil {
+					return errors.Trace(err)
+				}
+			}
+			return errors.Trace(hookCtx.Txn().Commit())
+		}()
+	}
+	d.setHook(tc)
+
+	newCol := tblInfo.Columns[1].Clone()
+	newCol.FieldType = *types.NewFieldType(mysql.TypeVarchar)
+	newCol.Flen = 10
+	newCol.Charset, newCol.Collate = charset.CharsetUTF8, charset.CollationUTF8
+	job := &model.Job{
+		SchemaID:   s.dbInfo.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionModifyColumn,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{newCol, newCol.Name, &ast.ColumnPosition{}, true, true},
+	}
+	err = d.doDDLJob(ctx, job)
+	c.Assert(err, IsNil)
+	c.Assert(errors.ErrorStack(checkErr), Equals, "")
+	testCheckJobDone(c, d, job, true)
+	// The row count is the number of the converted rows, the deleted keys of the original index aren't counted.
+	c.Assert(rowCount, Greater, int64(0))
+	kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+		historyJob, err1 := meta.NewMeta(txn).GetHistoryDDLJob(job.ID)
+		c.Assert(err1, IsNil)
+		c.Assert(historyJob.RowCount, Equals, rowCount)
+		return nil
+	})
+
+	publicTable := testGetTable(c, d, s.dbInfo.ID, tblInfo.ID)
+	c.Assert(publicTable.Meta().Columns, HasLen, 2)
+	c.Assert(publicTable.Meta().Indices, HasLen, 1)
+	c.Assert(publicTable.Cols()[1].Tp, Equals, mysql.TypeVarchar)
+	err = ctx.NewTxn()
+	c.Assert(err, IsNil)
+	err = checkResult(ctx, publicTable, publicTable.WritableCols(), [][]interface{}{
+		{1, []byte("50")}, {3, []byte("30")}, {4, []byte("40")}, {5, []byte("50")}})
+	c.Assert(err, IsNil)
+	err = inspectkv.CompareIndexData(ctx.Txn(), publicTable, publicTable.Indices()[0])
+	c.Assert(err, IsNil)
+	err = ctx.Txn().Commit()
+	c.Assert(err, IsNil)
+}
@@ -50,6 +50,9 @@ var (
 	errRunMultiSchemaChanges = terror.ClassDDL.New(codeRunMultiSchemaChanges, "can't run multi schema change")
 	errWaitReorgTimeout      = terror.ClassDDL.New(codeWaitReorgTimeout, "wait for reorganization timeout")
 	errInvalidStoreVer       = terror.ClassDDL.New(codeInvalidStoreVer, "invalid storage current version")
+	errCancelledDDLJob       = terror.ClassDDL.New(codeCancelledDDLJob, "cancelled DDL job")
+	errPausedDDLJob          = terror.ClassDDL.New(codePausedDDLJob, "paused DDL job")
+	errDDLQueueBlocked       = terror.ClassDDL.New(codeDDLQueueBlocked, "DDL job %d is paused and blocks the DDL queue, resume or cancel it first")
 
 	// We don't support dropping column with index covered now.
 	errCantDropColWithIndex    = terror.ClassDDL.New(codeCantDropColWithIndex, "can't drop column with index")
@@ -84,6 +87,8 @@ var (
 	errJSONUsedAsKey = terror.ClassDDL.New(codeJSONUsedAsKey, mysql.MySQLErrName[mysql.ErrJSONUsedAsKey])
 	// errBlobCantHaveDefault forbiddens to give not null default value to TEXT/BLOB/JSON.
 	errBlobCantHaveDefault = terror.ClassDDL.New(codeBlobCantHaveDefault, mysql.MySQLErrName[mysql.ErrBlobCantHaveDefault])
+	// errPrimaryCantHaveNull forbiddens to add a primary key on the nullable columns.
+	errPrimaryCantHaveNull = terror.ClassDDL.New(codePrimaryCantHaveNull, mysql.MySQLErrName[mysql.ErrPrimaryCantHaveNull])
 
 	// ErrInvalidDBState returns for invalid database state.
 	ErrInvalidDBState = terror.ClassDDL.New(codeInvalidDBState, "invalid database state")
@@ -95,7 +100,7 @@ var (
 	ErrInvalidIndexState = terror.ClassDDL.New(codeInvalidIndexState, "invalid index state")
 	// ErrInvalidForeignKeyState returns for invalid foreign key state.
 	ErrInvalidForeignKeyState = terror.ClassDDL.New(codeInvalidForeignKeyState, "invalid foreign key state")
-	// ErrUnsupportedModifyPrimaryKey returns an error when add or drop the primary key.
+	// ErrUnsupportedModifyPrimaryKey returns an error when drop the integer primary key which is the handle.
 	// It's exported for testing.
 	ErrUnsupportedModifyPrimaryKey = terror.ClassDDL.New(codeUnsupportedModifyPrimaryKey, "unsupported %s primary key")
 
@@ -111,6 +116,35 @@ var (
 	ErrTooLongIdent = terror.ClassDDL.New(codeTooLongIdent, "Identifier name too long")
 	// ErrWrongTableName return for wrong table name.
 	ErrWrongTableName = terror.ClassDDL.New(codeWrongTableName, "Incorrect table name '%s'")
+	// ErrWrongObject returns for wrong object.
+	ErrWrongObject = terror.ClassDDL.New(codeWrongObject, mysql.MySQLErrName[mysql.ErrWrongObject])
+
+	// ErrPartitionRequiresValues returns when a range partition has no VALUES LESS THAN.
+	ErrPartitionRequiresValues = terror.ClassDDL.New(codePartitionRequiresValues, mysql.MySQLErrName[mysql.ErrPartitionRequiresValues])
+	// ErrPartitionWrongValues returns when a hash partition has VALUES LESS THAN.
+	ErrPartitionWrongValues = terror.ClassDDL.New(codePartitionWrongValues, mysql.MySQLErrName[mysql.ErrPartitionWrongValues])
+	// ErrPartitionMaxvalue returns when MAXVALUE is not used in the last partition.
+	ErrPartitionMaxvalue = terror.ClassDDL.New(codePartitionMaxvalue, mysql.MySQLErrName[mysql.ErrPartitionMaxvalue])
+	// ErrPartitionFuncNotAllowed returns when the partition expression is not an integer expression.
+	ErrPartitionFuncNotAllowed = terror.ClassDDL.New(codePartitionFuncNotAllowed, mysql.MySQLErrName[mysql.ErrPartitionFuncNotAllowed])
+	// ErrPartitionsMustBeDefined returns when a range partitioned table has no partition definition.
+	ErrPartitionsMustBeDefined = terror.ClassDDL.New(codePartitionsMustBeDefined, mysql.MySQLErrName[mysql.ErrPartitionsMustBeDefined])
+	// ErrRangeNotIncreasing returns when the bounds of the range partitions are not strictly increasing.
+	ErrRangeNotIncreasing = terror.ClassDDL.New(codeRangeNotIncreasing, mysql.MySQLErrName[mysql.ErrRangeNotIncreasing])
+	// ErrUniqueKeyNeedAllFieldsInPf returns when a unique key doesn't include all the columns in the partition expression.
+	ErrUniqueKeyNeedAllFieldsInPf = terror.ClassDDL.New(codeUniqueKeyNeedAllFieldsInPf, mysql.MySQLErrName[mysql.ErrUniqueKeyNeedAllFieldsInPf])
+	// ErrPartitionMgmtOnNonpartitioned returns when managing the partitions of a non-partitioned table.
+	ErrPartitionMgmtOnNonpartitioned = terror.ClassDDL.New(codePartitionMgmtOnNonpartitioned, mysql.MySQLErrName[mysql.ErrPartitionMgmtOnNonpartitioned])
+	// ErrDropPartitionNonExistent returns when the partition to drop or truncate doesn't exist.
+	ErrDropPartitionNonExistent = terror.ClassDDL.New(codeDropPartitionNonExistent, mysql.MySQLErrName[mysql.ErrDropPartitionNonExistent])
+	// ErrDropLastPartition returns when dropping all the partitions of a table.
+	ErrDropLastPartition = terror.ClassDDL.New(codeDropLastPartition, mysql.MySQLErrName[mysql.ErrDropLastPartition])
+	// ErrOnlyOnRangeListPartition returns when adding or dropping partitions of a hash partitioned table.
+	ErrOnlyOnRangeListPartition = terror.ClassDDL.New(codeOnlyOnRangeListPartition, mysql.MySQLErrName[mysql.ErrOnlyOnRangeListPartition])
+	// ErrSameNamePartition returns when the partition names are duplicated.
+	ErrSameNamePartition = terror.ClassDDL.New(codeSameNamePartition, mysql.MySQLErrName[mysql.ErrSameNamePartition])
+	// ErrPartitionColumnList returns when VALUES LESS THAN has more than one value.
+	ErrPartitionColumnList = terror.ClassDDL.New(codePartitionColumnList, mysql.MySQLErrName[mysql.ErrPartitionColumnList])
 )
 
 // DDL is responsible for updating schema in data store and maintaining in-memory InfoSchema cache.
@@ -118,9 +152,11 @@ type DDL interface {
 	CreateSchema(ctx context.Context, name model.CIStr, charsetInfo *ast.CharsetOpt) error
 	DropSchema(ctx context.Context, schema model.CIStr) error
 	CreateTable(ctx context.Context, ident ast.Ident, cols []*ast.ColumnDef,
-		constrs []*ast.Constraint, options []*ast.TableOption) error
+		constrs []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) error
 	CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ident) error
+	CreateView(ctx context.Context, ident ast.Ident, viewInfo *model.ViewInfo, cols []*model.ColumnInfo, orReplace bool) error
 	DropTable(ctx context.Context, tableIdent ast.Ident) (err error)
+	DropView(ctx context.Context, tableIdent ast.Ident) (err error)
 	CreateIndex(ctx context.Context, tableIdent ast.Ident, unique bool, indexName model.CIStr,
 		columnNames []*ast.IndexColName) error
 	DropIndex(ctx context.Context, tableIdent ast.Ident, indexName model.CIStr) error
@@ -134,7 +170,7 @@ type DDL interface {
 	// GetLease returns current schema lease time.
 	GetLease() time.Duration
 	// Stats returns the DDL statistics.
-	Stats() (map[string]interface{}, error)
+	Stats(vars *variable.SessionVars) (map[string]interface{}, error)
 	// GetScope gets the status variables scope.
 	GetScope(status string) variable.ScopeFlag
 	// Stop stops DDL worker.
@@ -147,6 +183,9 @@ type DDL interface {
 	OwnerManager() OwnerManager
 	// WorkerVars gets the session variables for DDL worker.
 	WorkerVars() *variable.SessionVars
+	// SetGlobalVarsAccessor sets the accessor which is used by the DDL worker to read the global variables,
+	// e.g. the parameters of the backfill.
+	SetGlobalVarsAccessor(accessor variable.GlobalVarAccessor)
 }
 
 // Event is an event that a ddl operation happened.
@@ -197,11 +236,17 @@ type ddl struct {
 	reorgDoneCh chan error
 	// reorgRowCount is for reorganization, it uses to simulate a job's row count.
 	reorgRowCount int64
+	// reorgProgress is the bits of the estimated completion percent of the reorganization.
+	reorgProgress uint64
+	// reorgStopped is set when the running reorganization job is cancelled or paused by users.
+	reorgStopped int32
 
 	quitCh chan struct{}
 	wait   sync.WaitGroup
 
 	workerVars *variable.SessionVars
+	// varsMu protects the global variables accessor of workerVars.
+	varsMu sync.RWMutex
 }
 
 // RegisterEventCh registers passed channel for ddl Event.
@@ -462,6 +507,13 @@ func (d *ddl) WorkerVars() *variable.SessionVars {
 	return d.workerVars
 }
 
+func (d *ddl) SetGlobalVarsAccessor(accessor variable.GlobalVarAccessor) {
+	d.varsMu.Lock()
+	defer d.varsMu.Unlock()
+
+	d.workerVars.GlobalVarsAccessor = accessor
+}
+
 func filterError(err, exceptErr error) error {
 	if terror.ErrorEqual(err, exceptErr) {
 		return nil
@@ -481,6 +533,9 @@ const (
 	codeInvalidStoreVer                      = 8
 	codeUnknownTypeLength                    = 9
 	codeUnknownFractionLength                = 10
+	codeCancelledDDLJob                      = 11
+	codePausedDDLJob                         = 12
+	codeDDLQueueBlocked                      = 13
 
 	codeInvalidDBState         = 100
 	codeInvalidTableState      = 101
@@ -509,14 +564,30 @@ const (
 	codeCantDropFieldOrKey           = 1091
 	codeWrongDBName                  = 1102
 	codeWrongTableName               = 1103
+	codeWrongObject                  = 1347
 	codeInvalidUseOfNull             = 1138
 	codeBlobKeyWithoutLength         = 1170
+	codePrimaryCantHaveNull          = 1171
 	codeInvalidOnUpdate              = 1294
 	codeUnsupportedOnGeneratedColumn = 3106
 	codeGeneratedColumnNonPrior      = 3107
 	codeDependentByGeneratedColumn   = 3108
 	codeJSONUsedAsKey                = 3152
 	codeBlobCantHaveDefault          = 1101
+
+	codePartitionRequiresValues       = 1479
+	codePartitionWrongValues          = 1480
+	codePartitionMaxvalue             = 1481
+	codePartitionFuncNotAllowed       = 1491
+	codePartitionsMustBeDefined       = 1492
+	codeRangeNotIncreasing            = 1493
+	codeUniqueKeyNeedAllFieldsInPf    = 1503
+	codePartitionMgmtOnNonpartitioned = 1505
+	codeDropPartitionNonExistent      = 1507
+	codeDropLastPartition             = 1508
+	codeOnlyOnRangeListPartition      = 1512
+	codeSameNamePartition             = 1517
+	codePartitionColumnList           = 1653
 )
 
 func init() {
@@ -533,6 +604,7 @@ func init() {
 		codeDupKeyName:                   mysql.ErrDupKeyName,
 		codeWrongDBName:                  mysql.ErrWrongDBName,
 		codeWrongTableName:               mysql.ErrWrongTableName,
+		codeWrongObject:                  mysql.ErrWrongObject,
 		codeFileNotFound:                 mysql.ErrFileNotFound,
 		codeErrorOnRename:                mysql.ErrErrorOnRename,
 		codeBadField:                     mysql.ErrBadField,
@@ -543,6 +615,21 @@ func init() {
 		codeDependentByGeneratedColumn:   mysql.ErrDependentByGeneratedColumn,
 		codeJSONUsedAsKey:                mysql.ErrJSONUsedAsKey,
 		codeBlobCantHaveDefault:          mysql.ErrBlobCantHaveDefault,
+		codePrimaryCantHaveNull:          mysql.ErrPrimaryCantHaveNull,
+
+		codePartitionRequiresValues:       mysql.ErrPartitionRequiresValues,
+		codePartitionWrongValues:          mysql.ErrPartitionWrongValues,
+		codePartitionMaxvalue:             mysql.ErrPartitionMaxvalue,
+		codePartitionFuncNotAllowed:       mysql.ErrPartitionFuncNotAllowed,
+		codePartitionsMustBeDefined:       mysql.ErrPartitionsMustBeDefined,
+		codeRangeNotIncreasing:            mysql.ErrRangeNotIncreasing,
+		codeUniqueKeyNeedAllFieldsInPf:    mysql.ErrUniqueKeyNeedAllFieldsInPf,
+		codePartitionMgmtOnNonpartitioned: mysql.ErrPartitionMgmtOnNonpartitioned,
+		codeDropPartitionNonExistent:      mysql.ErrDropPartitionNonExistent,
+		codeDropLastPartition:             mysql.ErrDropLastPartition,
+		codeOnlyOnRangeListPartition:      mysql.ErrOnlyOnRangeListPartition,
+		codeSameNamePartition:             mysql.ErrSameNamePartition,
+		codePartitionColumnList:           mysql.ErrPartitionColumnList,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassDDL] = ddlMySQLErrCodes
 }
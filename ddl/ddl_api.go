@@ -648,6 +648,9 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	if err != nil {
 		return infoschema.ErrTableNotExists.GenByArgs(referIdent.Schema, referIdent.Name)
 	}
+	if referTbl.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(referIdent.Schema, referIdent.Name, "BASE TABLE")
+	}
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
 		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
@@ -664,6 +667,15 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tblInfo.Partition != nil {
+		tblInfo.Partition = tblInfo.Partition.Clone()
+		for i := range tblInfo.Partition.Definitions {
+			tblInfo.Partition.Definitions[i].ID, err = d.genGlobalID()
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    tblInfo.ID,
@@ -678,7 +690,7 @@ func (d *ddl) CreateTableWithLike(ctx context.Context, ident, referIdent ast.Ide
 }
 
 func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.ColumnDef,
-	constraints []*ast.Constraint, options []*ast.TableOption) (err error) {
+	constraints []*ast.Constraint, options []*ast.TableOption, partition *ast.PartitionOptions) (err error) {
 	is := d.GetInformationSchema()
 	schema, ok := is.SchemaByName(ident.Schema)
 	if !ok {
@@ -714,6 +726,12 @@ func (d *ddl) CreateTable(ctx context.Context, ident ast.Ident, colDefs []*ast.C
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if partition != nil {
+		tbInfo.Partition, err = d.buildTablePartitionInfo(ctx, partition, tbInfo)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -774,7 +792,63 @@ func handleTableOptions(options []*ast.TableOption, tbInfo *model.TableInfo) {
 	}
 }
 
+// CreateView creates a view named ident, or replaces the definition of an existing view if orReplace is true.
+func (d *ddl) CreateView(ctx context.Context, ident ast.Ident, viewInfo *model.ViewInfo, cols []*model.ColumnInfo, orReplace bool) (err error) {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	if err = checkTooLongTable(ident.Name); err != nil {
+		return errors.Trace(err)
+	}
+
+	tbInfo := &model.TableInfo{
+		Name:    ident.Name,
+		Columns: cols,
+		View:    viewInfo,
+	}
+	for i, col := range cols {
+		col.ID = allocateColumnID(tbInfo)
+		col.Offset = i
+		col.State = model.StatePublic
+	}
+
+	oldTbl, err := is.TableByName(ident.Schema, ident.Name)
+	if err == nil {
+		if !orReplace {
+			return infoschema.ErrTableExists.GenByArgs(ident.Name)
+		}
+		if !oldTbl.Meta().IsView() {
+			return ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "VIEW")
+		}
+		// Replace the definition in place, so the view keeps its ID.
+		tbInfo.ID = oldTbl.Meta().ID
+	} else {
+		tbInfo.ID, err = d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tbInfo.ID,
+		Type:       model.ActionCreateView,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{tbInfo, orReplace},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.AlterTableSpec) (err error) {
+	is := d.GetInformationSchema()
+	if tb, err1 := is.TableByName(ident.Schema, ident.Name); err1 == nil && tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ident.Schema, ident.Name, "BASE TABLE")
+	}
+
 	// Only handle valid specs, AlterTableLock is ignored.
 	validSpecs := make([]*ast.AlterTableSpec, 0, len(specs))
 	for _, spec := range specs {
@@ -808,7 +882,7 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			case ast.ConstraintForeignKey:
 				err = d.CreateForeignKey(ctx, ident, model.NewCIStr(constr.Name), spec.Constraint.Keys, spec.Constraint.Refer)
 			case ast.ConstraintPrimaryKey:
-				err = ErrUnsupportedModifyPrimaryKey.GenByArgs("add")
+				err = d.CreatePrimaryKey(ctx, ident, spec.Constraint.Keys)
 			default:
 				// Nothing to do now.
 			}
@@ -824,7 +898,13 @@ func (d *ddl) AlterTable(ctx context.Context, ident ast.Ident, specs []*ast.Alte
 			newIdent := ast.Ident{Schema: spec.NewTable.Schema, Name: spec.NewTable.Name}
 			err = d.RenameTable(ctx, ident, newIdent)
 		case ast.AlterTableDropPrimaryKey:
-			err = ErrUnsupportedModifyPrimaryKey.GenByArgs("drop")
+			err = d.DropPrimaryKey(ctx, ident)
+		case ast.AlterTableAddPartitions:
+			err = d.AddTablePartitions(ctx, ident, spec)
+		case ast.AlterTableDropPartition:
+			err = d.DropTablePartition(ctx, ident, spec)
+		case ast.AlterTableTruncatePartition:
+			err = d.TruncateTablePartition(ctx, ident, spec)
 		default:
 			// Nothing to do now.
 		}
@@ -1149,10 +1229,6 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	err = modifiable(&col.FieldType, &newCol.FieldType)
-	if err != nil {
-		return nil, errors.Trace(err)
-	}
 	if err := setDefaultAndComment(ctx, newCol, spec.NewColumn.Options); err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -1170,28 +1246,72 @@ func (d *ddl) getModifiableColumnJob(ctx context.Context, ident ast.Ident, origi
 		return nil, errUnsupportedModifyColumn.GenByArgs("set auto_increment")
 	}
 
-	// We don't support modifying the type definitions from 'null' to 'not null' now.
-	if !mysql.HasNotNullFlag(col.Flag) && mysql.HasNotNullFlag(newCol.Flag) {
-		return nil, errUnsupportedModifyColumn.GenByArgs("null to not null")
-	}
 	// As same with MySQL, we don't support modifying the stored status for generated columns.
 	if err = checkModifyGeneratedColumn(t.Cols(), col, newCol); err != nil {
 		return nil, errors.Trace(err)
 	}
 
+	// If the existing data can't be kept as it is, the data is converted to the new type and checked
+	// by reorganizing the column.
+	needReorg := false
+	if err = modifiable(&col.FieldType, &newCol.FieldType); err != nil {
+		needReorg = true
+	} else if !mysql.HasNotNullFlag(col.Flag) && mysql.HasNotNullFlag(newCol.Flag) {
+		needReorg = true
+	}
+	if needReorg {
+		if err = checkReorgModifiable(t, col, newCol); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
 	job := &model.Job{
 		SchemaID:   schema.ID,
 		TableID:    t.Meta().ID,
 		Type:       model.ActionModifyColumn,
 		BinlogInfo: &model.HistoryInfo{},
-		Args:       []interface{}{&newCol, originalColName, spec.Position},
+		Args:       []interface{}{&newCol, originalColName, spec.Position, needReorg, ctx.GetSessionVars().StrictSQLMode},
 	}
 	return job, nil
 }
 
+// checkReorgModifiable checks if the column can be modified to newCol by reorganizing the data.
+func checkReorgModifiable(t table.Table, col, newCol *table.Column) error {
+	tblInfo := t.Meta()
+	if col.IsPKHandleColumn(tblInfo) {
+		return errUnsupportedModifyColumn.GenByArgs("data of the integer primary key")
+	}
+	if mysql.HasAutoIncrementFlag(col.Flag) {
+		return errUnsupportedModifyColumn.GenByArgs("data of the auto_increment column")
+	}
+	if tblInfo.Partition != nil {
+		return errUnsupportedModifyColumn.GenByArgs("data of the partitioned table")
+	}
+	if len(col.GeneratedExprString) != 0 {
+		return errUnsupportedModifyColumn.GenByArgs("data of the generated column")
+	}
+	for _, c := range t.Cols() {
+		if _, ok := c.Dependences[col.Name.L]; ok {
+			return errUnsupportedModifyColumn.GenByArgs("data of the column which generated columns depend on")
+		}
+	}
+	for _, idx := range tblInfo.Indices {
+		ic := findIndexColumn(idx, col.Name.L)
+		if ic == nil {
+			continue
+		}
+		if newCol.Tp == mysql.TypeJSON {
+			return errJSONUsedAsKey.GenByArgs(newCol.Name.O)
+		}
+		if types.IsTypeBlob(newCol.Tp) && ic.Length == types.UnspecifiedLength {
+			return errBlobKeyWithoutLength
+		}
+	}
+	return nil
+}
+
 // ChangeColumn renames an existing column and modifies the column's definition,
-// currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// the changes that need to change or check data on the table are done by reorganizing the data.
 func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return errWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1216,8 +1336,8 @@ func (d *ddl) ChangeColumn(ctx context.Context, ident ast.Ident, spec *ast.Alter
 	return errors.Trace(err)
 }
 
-// ModifyColumn does modification on an existing column, currently we only support limited kind of changes
-// that do not need to change or check data on the table.
+// ModifyColumn does modification on an existing column,
+// the changes that need to change or check data on the table are done by reorganizing the data.
 func (d *ddl) ModifyColumn(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
 	if len(spec.NewColumn.Name.Schema.O) != 0 && ident.Schema.L != spec.NewColumn.Name.Schema.L {
 		return errWrongDBName.GenByArgs(spec.NewColumn.Name.Schema.O)
@@ -1292,6 +1412,38 @@ func (d *ddl) DropTable(ctx context.Context, ti ast.Ident) (err error) {
 	if err != nil {
 		return infoschema.ErrTableNotExists.GenByArgs(ti)
 	}
+	if tb.Meta().IsView() {
+		return infoschema.ErrTableNotExists.GenByArgs(ti.Schema, ti.Name)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tb.Meta().ID,
+		Type:       model.ActionDropTable,
+		BinlogInfo: &model.HistoryInfo{},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropView drops the view ti. Dropping a view only removes its meta, the same
+// job type as dropping a table is used since the view has no data.
+func (d *ddl) DropView(ctx context.Context, ti ast.Ident) (err error) {
+	is := d.GetInformationSchema()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+
+	tb, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return infoschema.ErrTableNotExists.GenByArgs(ti)
+	}
+	if !tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "VIEW")
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -1315,6 +1467,9 @@ func (d *ddl) TruncateTable(ctx context.Context, ti ast.Ident) error {
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if tb.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "BASE TABLE")
+	}
 	newTableID, err := d.genGlobalID()
 	if err != nil {
 		return errors.Trace(err)
@@ -1331,6 +1486,108 @@ func (d *ddl) TruncateTable(ctx context.Context, ti ast.Ident) error {
 	return errors.Trace(err)
 }
 
+// AddTablePartitions adds range partitions after the last partition of the table.
+func (d *ddl) AddTablePartitions(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	if pi.Type != model.PartitionTypeRange {
+		return ErrOnlyOnRangeListPartition.GenByArgs("ADD")
+	}
+	partInfo := &model.PartitionInfo{Type: pi.Type, Expr: pi.Expr}
+	partInfo.Definitions, err = buildRangePartitionDefinitions(ctx, spec.PartDefinitions)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// Check the new partitions together with the existing partitions.
+	allPartitions := pi.Clone()
+	allPartitions.Definitions = append(allPartitions.Definitions, partInfo.Definitions...)
+	if err = checkPartitionDefinitions(allPartitions); err != nil {
+		return errors.Trace(err)
+	}
+	for i := range partInfo.Definitions {
+		partInfo.Definitions[i].ID, err = d.genGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       model.ActionAddTablePartition,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partInfo},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
+// DropTablePartition drops range partitions and deletes their data.
+func (d *ddl) DropTablePartition(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	return errors.Trace(d.dropOrTruncateTablePartition(ctx, ident, spec, model.ActionDropTablePartition))
+}
+
+// TruncateTablePartition deletes all the data of the partitions.
+func (d *ddl) TruncateTablePartition(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec) error {
+	return errors.Trace(d.dropOrTruncateTablePartition(ctx, ident, spec, model.ActionTruncateTablePartition))
+}
+
+func (d *ddl) dropOrTruncateTablePartition(ctx context.Context, ident ast.Ident, spec *ast.AlterTableSpec, tp model.ActionType) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ident.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ident.Schema)
+	}
+	t, err := is.TableByName(ident.Schema, ident.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	pi := t.Meta().Partition
+	if pi == nil {
+		return errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	operation := "TRUNCATE"
+	if tp == model.ActionDropTablePartition {
+		operation = "DROP"
+		if pi.Type != model.PartitionTypeRange {
+			return ErrOnlyOnRangeListPartition.GenByArgs(operation)
+		}
+	}
+	partNames := make([]string, 0, len(spec.PartitionNames))
+	for _, name := range spec.PartitionNames {
+		if findPartitionByName(pi, name.L) < 0 {
+			return ErrDropPartitionNonExistent.GenByArgs(operation)
+		}
+		partNames = append(partNames, name.L)
+	}
+	if tp == model.ActionDropTablePartition && len(partNames) >= len(pi.Definitions) {
+		return errors.Trace(ErrDropLastPartition)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    t.Meta().ID,
+		Type:       tp,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{partNames},
+	}
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func (d *ddl) RenameTable(ctx context.Context, oldIdent, newIdent ast.Ident) error {
 	is := d.GetInformationSchema()
 	oldSchema, ok := is.SchemaByName(oldIdent.Schema)
@@ -1386,6 +1643,9 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	if err != nil {
 		return errors.Trace(infoschema.ErrTableNotExists)
 	}
+	if t.Meta().IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "BASE TABLE")
+	}
 
 	// Deal with anonymous index.
 	if len(indexName.L) == 0 {
@@ -1395,6 +1655,12 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	if indexInfo := findIndexByName(indexName.L, t.Meta().Indices); indexInfo != nil {
 		return errDupKeyName.Gen("index already exist %s", indexName)
 	}
+	// The uniqueness is only checked in each partition.
+	if unique {
+		if err = checkIndexPartitionKeys(t.Meta(), idxColNames, "UNIQUE INDEX"); err != nil {
+			return errors.Trace(err)
+		}
+	}
 
 	job := &model.Job{
 		SchemaID:   schema.ID,
@@ -1409,6 +1675,65 @@ func (d *ddl) CreateIndex(ctx context.Context, ti ast.Ident, unique bool, indexN
 	return errors.Trace(err)
 }
 
+// CreatePrimaryKey adds a primary key to a table that has none. The primary key is built as a unique
+// index, so it never becomes the handle of the table, even if it's a single integer column.
+func (d *ddl) CreatePrimaryKey(ctx context.Context, ti ast.Ident, idxColNames []*ast.IndexColName) error {
+	is := d.infoHandle.Get()
+	schema, ok := is.SchemaByName(ti.Schema)
+	if !ok {
+		return infoschema.ErrDatabaseNotExists.GenByArgs(ti.Schema)
+	}
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	tblInfo := t.Meta()
+	if tblInfo.IsView() {
+		return ErrWrongObject.GenByArgs(ti.Schema, ti.Name, "BASE TABLE")
+	}
+	if tblInfo.PKIsHandle || findPrimaryIndex(tblInfo) != nil {
+		return infoschema.ErrMultiplePriKey
+	}
+
+	for _, key := range idxColNames {
+		col := table.FindCol(t.Cols(), key.Column.Name.O)
+		if col == nil {
+			return errKeyColumnDoesNotExits.Gen("key column %s doesn't exist in table", key.Column.Name)
+		}
+		// Virtual columns cannot be used in primary key.
+		if len(col.GeneratedExprString) != 0 && !col.GeneratedStored {
+			return errUnsupportedOnGeneratedColumn.GenByArgs("Defining a virtual generated column as primary key")
+		}
+		// Unlike MySQL, we don't change the nullable columns to NOT NULL implicitly,
+		// the user should modify them to NOT NULL first.
+		if !mysql.HasNotNullFlag(col.Flag) {
+			return errPrimaryCantHaveNull.Gen("All parts of a PRIMARY KEY must be NOT NULL; column %s is nullable and "+
+				"isn't changed to NOT NULL implicitly as MySQL does, modify it to NOT NULL first", col.Name.O)
+		}
+	}
+	// The uniqueness is only checked in each partition.
+	if err = checkIndexPartitionKeys(tblInfo, idxColNames, "PRIMARY KEY"); err != nil {
+		return errors.Trace(err)
+	}
+
+	indexName := model.NewCIStr(table.PrimaryKeyName)
+	if indexInfo := findIndexByName(indexName.L, tblInfo.Indices); indexInfo != nil {
+		return errDupKeyName.Gen("index already exist %s", indexName)
+	}
+
+	job := &model.Job{
+		SchemaID:   schema.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionAddIndex,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{true, indexName, idxColNames, true},
+	}
+
+	err = d.doDDLJob(ctx, job)
+	err = d.callHookOnChanged(err)
+	return errors.Trace(err)
+}
+
 func buildFKInfo(fkName model.CIStr, keys []*ast.IndexColName, refer *ast.ReferenceDef) (*model.FKInfo, error) {
 	var fkInfo model.FKInfo
 	fkInfo.Name = fkName
@@ -1515,6 +1840,24 @@ func (d *ddl) DropIndex(ctx context.Context, ti ast.Ident, indexName model.CIStr
 	return errors.Trace(err)
 }
 
+// DropPrimaryKey drops the primary key of a table. The primary key that is the handle of the table can't be dropped.
+func (d *ddl) DropPrimaryKey(ctx context.Context, ti ast.Ident) error {
+	is := d.infoHandle.Get()
+	t, err := is.TableByName(ti.Schema, ti.Name)
+	if err != nil {
+		return errors.Trace(infoschema.ErrTableNotExists)
+	}
+	tblInfo := t.Meta()
+	if tblInfo.PKIsHandle {
+		return ErrUnsupportedModifyPrimaryKey.GenByArgs("drop integer")
+	}
+	indexInfo := findPrimaryIndex(tblInfo)
+	if indexInfo == nil {
+		return ErrCantDropFieldOrKey.Gen("index %s doesn't exist", table.PrimaryKeyName)
+	}
+	return errors.Trace(d.DropIndex(ctx, ti, indexInfo.Name))
+}
+
 // findCol finds column in cols by name.
 func findCol(cols []*model.ColumnInfo, name string) *model.ColumnInfo {
 	name = strings.ToLower(name)
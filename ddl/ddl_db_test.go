@@ -34,6 +34,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	tmysql "github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/store/localstore"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
@@ -193,6 +194,127 @@ func (s *testDBSuite) TestAddIndexWithPK(c *C) {
 	s.tk.MustQuery("select * from test_add_index_with_pk2").Check(testkit.Rows("1 1 1 1", "2 2 2 2"))
 }
 
+func (s *testDBSuite) TestPauseAndResumeAddIndex(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+
+	s.tk.MustExec("create table test_pause_add_index (c1 int, c2 int)")
+	count := 100
+	for i := 0; i < count; i++ {
+		s.mustExec(c, "insert into test_pause_add_index values (?, ?)", i, i)
+	}
+	// Backfill 20 rows per second by 2 tasks of 10 rows, so the backfill takes about 5 seconds.
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_worker_cnt = 4")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_batch_size = 10")
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_rate_limit = 20")
+	_, err := s.tk.Exec("set @@global.tidb_ddl_reorg_worker_cnt = 0")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	_, err = s.tk.Exec("set @@global.tidb_ddl_reorg_rate_limit = -5")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("err %v", err))
+	defer func() {
+		s.mustExec(c, "set @@global.tidb_ddl_reorg_worker_cnt = 16")
+		s.mustExec(c, "set @@global.tidb_ddl_reorg_batch_size = 128")
+		s.mustExec(c, "set @@global.tidb_ddl_reorg_rate_limit = 0")
+	}()
+
+	done := make(chan error, 1)
+	go backgroundExec(s.store, "alter table test_pause_add_index add index c2(c2)", done)
+
+	// getJob gets the first row of admin show ddl jobs, which is the running add index job.
+	getJob := func() []interface{} {
+		rows := s.mustQuery(c, "admin show ddl jobs")
+		c.Assert(rows, Not(HasLen), 0)
+		return rows[0]
+	}
+	var job []interface{}
+	for i := 0; i < 100; i++ {
+		job = getJob()
+		if job[3] == "add index" && job[5] != "0" {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	c.Assert(job[3], Equals, "add index")
+	c.Assert(job[5], Not(Equals), "0", Commentf("the backfill doesn't start, job %v", job))
+	jobID := job[0].(string)
+
+	// Queue a job behind the running add index job, only the first job can be paused.
+	queuedDone := make(chan error, 1)
+	go backgroundExec(s.store, "create table test_pause_add_index_queued (c int)", queuedDone)
+	var queued []interface{}
+	for i := 0; i < 100; i++ {
+		rows := s.mustQuery(c, "admin show ddl jobs")
+		if len(rows) > 1 && rows[1][3] == "create table" && rows[1][9] == "none" {
+			queued = rows[1]
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	c.Assert(queued, NotNil)
+	queuedID := queued[0].(string)
+	s.tk.MustQuery("admin pause ddl jobs " + queuedID).Check(testkit.Rows(
+		fmt.Sprintf("%s error: [inspectkv:7]This job:%s can't be paused now", queuedID, queuedID)))
+	s.tk.MustQuery("admin pause ddl jobs " + jobID).Check(testkit.Rows(jobID + " successful"))
+	s.tk.MustQuery("admin pause ddl jobs " + jobID).Check(testkit.Rows(
+		fmt.Sprintf("%s error: [inspectkv:7]This job:%s can't be paused now", jobID, jobID)))
+
+	// The paused job isn't finished, and its row count and progress are kept.
+	time.Sleep(3 * s.lease)
+	select {
+	case err := <-done:
+		c.Fatalf("the paused job is finished, err %v", err)
+	default:
+	}
+	job = getJob()
+	c.Assert(job[0], Equals, jobID)
+	c.Assert(job[4], Equals, "write reorganization")
+	c.Assert(job[9], Equals, "paused")
+	rowCount, err := strconv.Atoi(job[5].(string))
+	c.Assert(err, IsNil)
+	c.Assert(rowCount, Greater, 0)
+	c.Assert(rowCount, Less, count)
+	progress, err := strconv.ParseFloat(job[6].(string), 64)
+	c.Assert(err, IsNil)
+	c.Assert(progress, Greater, float64(0))
+	c.Assert(progress, Less, float64(100))
+
+	// The new DDL is rejected while the job is paused, instead of waiting behind it.
+	_, err = s.tk.Exec("create table test_pause_add_index_blocked (c int)")
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Equals, fmt.Sprintf("[ddl:13]DDL job %s is paused and blocks the DDL queue, resume or cancel it first", jobID))
+	rows := s.mustQuery(c, "admin show ddl jobs")
+	c.Assert(rows[0][0], Equals, jobID)
+	c.Assert(rows[1][0], Equals, queuedID)
+	c.Assert(rows[1][9], Equals, fmt.Sprintf("none (blocked by paused job %s)", jobID))
+
+	s.mustExec(c, "set @@global.tidb_ddl_reorg_rate_limit = 0")
+	s.tk.MustQuery("admin resume ddl jobs " + jobID).Check(testkit.Rows(jobID + " successful"))
+	select {
+	case err = <-done:
+		c.Assert(err, IsNil)
+	case <-time.After(30 * time.Second):
+		c.Fatal("the resumed job isn't finished")
+	}
+	select {
+	case err = <-queuedDone:
+		c.Assert(err, IsNil)
+	case <-time.After(30 * time.Second):
+		c.Fatal("the queued job isn't finished")
+	}
+	s.mustExec(c, "admin check table test_pause_add_index")
+	// The queued job is finished after the add index job, so it comes first in the history.
+	rows = s.mustQuery(c, "admin show ddl jobs")
+	c.Assert(rows[0][0], Equals, queuedID)
+	job = rows[1]
+	c.Assert(job[0], Equals, jobID)
+	c.Assert(job[5], Equals, strconv.Itoa(count))
+	c.Assert(job[6], Equals, "100")
+	c.Assert(job[9], Equals, "synced")
+	s.mustExec(c, "admin check table test_pause_add_index")
+	s.mustExec(c, "create table test_pause_add_index_blocked (c int)")
+}
+
 func (s *testDBSuite) TestIndex(c *C) {
 	defer testleak.AfterTest(c)()
 	s.tk = testkit.NewTestKit(c, s.store)
@@ -511,7 +633,7 @@ LOOP:
 	}
 	// Make sure there is no index with name c3_index.
 	c.Assert(nidx, IsNil)
-	idx := tables.NewIndex(t.Meta(), c3idx.Meta())
+	idx := tables.NewIndex(t.Meta().ID, t.Meta(), c3idx.Meta())
 	c.Assert(ctx.NewTxn(), IsNil)
 	defer ctx.Txn().Rollback()
 
@@ -784,11 +906,56 @@ func (s *testDBSuite) TestPrimaryKey(c *C) {
 	s.tk = testkit.NewTestKit(c, s.store)
 	s.tk.MustExec("use " + s.schemaName)
 
-	s.mustExec(c, "create table primary_key_test (a int, b varchar(10))")
-	_, err := s.tk.Exec("alter table primary_key_test add primary key(a)")
-	c.Assert(ddl.ErrUnsupportedModifyPrimaryKey.Equal(err), IsTrue)
-	_, err = s.tk.Exec("alter table primary_key_test drop primary key")
+	s.mustExec(c, "create table primary_key_test (a int, b varchar(10), c int not null)")
+	s.mustExec(c, "insert into primary_key_test values (1, 'a', 1), (2, 'b', 1), (3, 'a', 2)")
+	s.testErrorCode(c, "alter table primary_key_test drop primary key", tmysql.ErrCantDropFieldOrKey)
+	s.testErrorCode(c, "alter table primary_key_test add primary key(b)", tmysql.ErrPrimaryCantHaveNull)
+	_, err := s.tk.Exec("alter table primary_key_test add primary key(b)")
+	c.Assert(err.Error(), Matches, ".*column b is nullable.*modify it to NOT NULL first")
+	s.testErrorCode(c, "alter table primary_key_test add primary key(d)", tmysql.ErrKeyColumnDoesNotExits)
+	s.mustExec(c, "alter table primary_key_test modify b varchar(10) not null")
+	s.testErrorCode(c, "alter table primary_key_test add primary key(b)", tmysql.ErrDupEntry)
+	s.tk.MustExec("admin check table primary_key_test")
+
+	// The primary key is built as a unique index, the same as a primary key that isn't the handle in CREATE TABLE.
+	s.mustExec(c, "alter table primary_key_test add primary key(b, c)")
+	s.testErrorCode(c, "alter table primary_key_test add primary key(a)", tmysql.ErrMultiplePriKey)
+	s.tk.MustQuery("show create table primary_key_test").Check(testkit.Rows("primary_key_test CREATE TABLE `primary_key_test` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` varchar(10) NOT NULL,\n" +
+		"  `c` int(11) NOT NULL,\n" +
+		"  PRIMARY KEY (`b`,`c`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	s.tk.MustQuery("select column_name, column_key from information_schema.columns where table_name = 'primary_key_test'").Check(
+		testkit.Rows("a ", "b PRI", "c PRI"))
+	s.tk.MustQuery("select constraint_name, column_name from information_schema.key_column_usage where table_name = 'primary_key_test'").Check(
+		testkit.Rows("PRIMARY b", "PRIMARY c"))
+	s.testErrorCode(c, "insert into primary_key_test values (4, 'a', 1)", tmysql.ErrDupEntry)
+	s.tk.MustQuery("select a from primary_key_test use index(`primary`) where b = 'a' and c = 2").Check(testkit.Rows("3"))
+	s.tk.MustExec("admin check table primary_key_test")
+
+	s.mustExec(c, "alter table primary_key_test drop primary key")
+	s.tk.MustQuery("show create table primary_key_test").Check(testkit.Rows("primary_key_test CREATE TABLE `primary_key_test` (\n" +
+		"  `a` int(11) DEFAULT NULL,\n" +
+		"  `b` varchar(10) NOT NULL,\n" +
+		"  `c` int(11) NOT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	s.tk.MustQuery("select column_name, column_key from information_schema.columns where table_name = 'primary_key_test'").Check(
+		testkit.Rows("a ", "b ", "c "))
+	s.mustExec(c, "insert into primary_key_test values (4, 'a', 1)")
+
+	// The integer primary key which is the handle can't be dropped.
+	s.mustExec(c, "create table primary_key_handle (a int primary key, b int)")
+	_, err = s.tk.Exec("alter table primary_key_handle drop primary key")
 	c.Assert(ddl.ErrUnsupportedModifyPrimaryKey.Equal(err), IsTrue)
+	s.testErrorCode(c, "alter table primary_key_handle add primary key(b)", tmysql.ErrMultiplePriKey)
+
+	// The primary key of a partitioned table includes all the columns in the partition expression.
+	s.mustExec(c, "create table primary_key_partition (a int not null, b int not null) partition by hash (a) partitions 2")
+	s.mustExec(c, "insert into primary_key_partition values (1, 1), (2, 1)")
+	s.testErrorCode(c, "alter table primary_key_partition add primary key(b)", tmysql.ErrUniqueKeyNeedAllFieldsInPf)
+	s.mustExec(c, "alter table primary_key_partition add primary key(b, a)")
+	s.testErrorCode(c, "insert into primary_key_partition values (1, 1)", tmysql.ErrDupEntry)
 }
 
 func (s *testDBSuite) TestChangeColumn(c *C) {
@@ -846,9 +1013,71 @@ func (s *testDBSuite) TestChangeColumn(c *C) {
 	sql = "alter table t3 change t.a aa bigint"
 	s.testErrorCode(c, sql, tmysql.ErrWrongTableName)
 	sql = "alter table t3 change aa a bigint not null"
-	s.testErrorCode(c, sql, tmysql.ErrUnknown)
-	sql = "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'"
-	s.testErrorCode(c, sql, tmysql.ErrUnknown)
+	s.testErrorCode(c, sql, tmysql.ErrInvalidUseOfNull)
+
+	// The enum values are converted by their names.
+	s.mustExec(c, "alter table t3 modify en enum('a', 'z', 'b', 'c') not null default 'a'")
+	s.tk.MustQuery("select en from t3").Check(testkit.Rows("a", "a", "a"))
+}
+
+func (s *testDBSuite) TestModifyColumnReorg(c *C) {
+	defer testleak.AfterTest(c)()
+	s.tk = testkit.NewTestKit(c, s.store)
+	s.tk.MustExec("use " + s.schemaName)
+	s.tk.MustExec("drop table if exists t_reorg")
+	s.tk.MustExec("create table t_reorg (id int primary key, a varchar(10), b int, c int, unique key ua(a), key bc(b, c))")
+	s.tk.MustExec("insert into t_reorg values (-1, 'abcdefg', 1, 1), (1, 'abc', null, 2), (2, '12', 3, null)")
+
+	// The data which can't be converted makes the job rolled back in the strict SQL mode.
+	s.testErrorCode(c, "alter table t_reorg modify a varchar(5)", tmysql.ErrDataTooLong)
+	s.testErrorCode(c, "alter table t_reorg modify b int not null", tmysql.ErrInvalidUseOfNull)
+	s.testErrorCode(c, "alter table t_reorg modify a int", tmysql.WarnDataTruncated)
+	s.tk.MustQuery("show create table t_reorg").Check(testkit.Rows("t_reorg CREATE TABLE `t_reorg` (\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `a` varchar(10) DEFAULT NULL,\n" +
+		"  `b` int(11) DEFAULT NULL,\n" +
+		"  `c` int(11) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `ua` (`a`),\n" +
+		"  KEY `bc` (`b`,`c`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	s.tk.MustExec("admin check table t_reorg")
+
+	s.tk.MustExec("update t_reorg set a = 'abcde' where id = -1")
+	s.tk.MustExec("alter table t_reorg modify a varchar(5)")
+	s.tk.MustExec("update t_reorg set b = 0 where b is null")
+	s.tk.MustExec("alter table t_reorg change b b bigint not null first")
+	s.tk.MustExec("alter table t_reorg modify c varchar(10)")
+	s.tk.MustQuery("show create table t_reorg").Check(testkit.Rows("t_reorg CREATE TABLE `t_reorg` (\n" +
+		"  `b` bigint(21) NOT NULL,\n" +
+		"  `id` int(11) NOT NULL,\n" +
+		"  `a` varchar(5) DEFAULT NULL,\n" +
+		"  `c` varchar(10) DEFAULT NULL,\n" +
+		"  PRIMARY KEY (`id`),\n" +
+		"  UNIQUE KEY `ua` (`a`),\n" +
+		"  KEY `bc` (`b`,`c`)\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin"))
+	s.tk.MustExec("admin check table t_reorg")
+	s.tk.MustQuery("select * from t_reorg").Check(testkit.Rows("1 -1 abcde 1", "0 1 abc 2", "3 2 12 <nil>"))
+	s.tk.MustQuery("select id from t_reorg use index(bc) where b = 0 and c = '2'").Check(testkit.Rows("1"))
+	s.tk.MustQuery("select id from t_reorg use index(ua) where a = 'abcde'").Check(testkit.Rows("-1"))
+
+	// The converted values which are duplicated in a unique index make the job rolled back.
+	s.tk.MustExec("delete from t_reorg where a like 'ab%'")
+	s.tk.MustExec("insert into t_reorg values (5, 4, '012', 1), (6, 5, '0', 2)")
+	s.testErrorCode(c, "alter table t_reorg modify a int", tmysql.ErrDupEntry)
+	s.tk.MustExec("admin check table t_reorg")
+
+	// The data is converted with the truncated errors ignored if it's not in the strict SQL mode.
+	s.tk.MustExec("insert into t_reorg values (1, 1, 'abcde', 1), (0, 3, 'abc', 2)")
+	s.tk.MustExec("alter table t_reorg drop index ua")
+	s.tk.MustExec("set @@sql_mode = ''")
+	s.tk.MustExec("alter table t_reorg modify c int not null")
+	s.tk.MustQuery("select c from t_reorg order by id").Check(testkit.Rows("1", "0", "2", "1", "2"))
+	s.tk.MustExec("alter table t_reorg modify a int")
+	s.tk.MustQuery("select a from t_reorg order by id").Check(testkit.Rows("0", "12", "0", "12", "0"))
+	s.tk.MustExec("set @@sql_mode = 'STRICT_TRANS_TABLES'")
+	s.tk.MustExec("admin check table t_reorg")
 }
 
 func (s *testDBSuite) TestAlterColumn(c *C) {
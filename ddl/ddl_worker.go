@@ -86,11 +86,21 @@ func (d *ddl) addDDLJob(ctx context.Context, job *model.Job) error {
 	return kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
 		t := meta.NewMeta(txn)
 
-		var err error
+		// The queue is serial, a paused job blocks the jobs behind it until it's resumed, so the new job is
+		// rejected instead of waiting for the user to resume it. Only the first job can be paused.
+		first, err := d.getFirstDDLJob(t)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if first != nil && first.IsPaused() {
+			return errDDLQueueBlocked.GenByArgs(first.ID)
+		}
+
 		job.ID, err = t.GenGlobalID()
 		if err != nil {
 			return errors.Trace(err)
 		}
+		job.StartTS = txn.StartTS()
 
 		err = t.EnQueueDDLJob(job)
 		return errors.Trace(err)
@@ -107,7 +117,7 @@ func (d *ddl) getFirstDDLJob(t *meta.Meta) (*model.Job, error) {
 // Every time we enter another state except final state, we must call this function.
 func (d *ddl) updateDDLJob(t *meta.Meta, job *model.Job, updateTS uint64) error {
 	job.LastUpdateTS = int64(updateTS)
-	err := t.UpdateDDLJob(0, job)
+	err := t.UpdateDDLJob(0, job, true)
 	return errors.Trace(err)
 }
 
@@ -121,7 +131,8 @@ func (d *ddl) finishDDLJob(t *meta.Meta, job *model.Job) error {
 		return errors.Trace(err)
 	}
 	switch job.Type {
-	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable:
+	case model.ActionDropSchema, model.ActionDropTable, model.ActionTruncateTable,
+		model.ActionDropTablePartition, model.ActionTruncateTablePartition:
 		if err = d.prepareBgJob(t, job); err != nil {
 			return errors.Trace(err)
 		}
@@ -188,6 +199,18 @@ func (d *ddl) handleDDLJobQueue() error {
 				return errors.Trace(err)
 			}
 
+			if job.IsPaused() {
+				// The job is paused by the user, stop its reorganization and save the row count and progress.
+				// The job stays at the head of the queue until it's resumed or cancelled.
+				if d.stopReorgJob(job, reorgPaused) {
+					job.LastUpdateTS = int64(txn.StartTS())
+					// The args of the job aren't decoded, so the raw args are kept.
+					err = t.UpdateDDLJob(0, job, false)
+				}
+				job = nil
+				return errors.Trace(err)
+			}
+
 			if job.IsRunning() || job.IsDone() {
 				// If we enter a new state, crash when waiting 2 * lease time, and restart quickly,
 				// we may run the job immediately again, but we don't wait enough 2 * lease time to
@@ -264,6 +287,10 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		return
 	}
 
+	if job.IsCancelling() {
+		return d.cancelDDLJob(t, job)
+	}
+
 	if job.State != model.JobRollback {
 		job.State = model.JobRunning
 	}
@@ -276,6 +303,8 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onDropSchema(t, job)
 	case model.ActionCreateTable:
 		ver, err = d.onCreateTable(t, job)
+	case model.ActionCreateView:
+		ver, err = d.onCreateView(t, job)
 	case model.ActionDropTable:
 		ver, err = d.onDropTable(t, job)
 	case model.ActionAddColumn:
@@ -298,6 +327,12 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 		ver, err = d.onRenameTable(t, job)
 	case model.ActionSetDefaultValue:
 		ver, err = d.onSetDefaultValue(t, job)
+	case model.ActionAddTablePartition:
+		ver, err = d.onAddTablePartition(t, job)
+	case model.ActionDropTablePartition:
+		ver, err = d.onDropTablePartition(t, job)
+	case model.ActionTruncateTablePartition:
+		ver, err = d.onTruncateTablePartition(t, job)
 	default:
 		// Invalid job, cancel it.
 		job.State = model.JobCancelled
@@ -319,6 +354,27 @@ func (d *ddl) runDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
 	return
 }
 
+// cancelDDLJob cancels the job which is marked cancelling by users.
+// An add index job or a modify column job which has changed the schema is converted to a rollback job,
+// other jobs are cancelled directly.
+func (d *ddl) cancelDDLJob(t *meta.Meta, job *model.Job) (ver int64) {
+	var err error
+	if job.Type == model.ActionAddIndex && job.SchemaState != model.StateNone {
+		ver, err = d.rollbackAddIndex(t, job)
+	} else if job.Type == model.ActionModifyColumn && job.SchemaState != model.StateNone {
+		ver, err = d.rollbackModifyColumn(t, job)
+	} else {
+		job.State = model.JobCancelled
+		err = errCancelledDDLJob
+	}
+	log.Infof("[ddl] cancel DDL job %s, err %v", job, err)
+	if err != nil {
+		job.Error = toTError(err)
+		job.ErrorCount++
+	}
+	return
+}
+
 func toTError(err error) *terror.Error {
 	originErr := errors.Cause(err)
 	tErr, ok := originErr.(*terror.Error)
@@ -16,13 +16,16 @@ package ddl
 import (
 	"time"
 
+	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/inspectkv"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
 	goctx "golang.org/x/net/context"
@@ -194,6 +197,150 @@ func (s *testDDLSuite) TestColumnError(c *C) {
 	doDDLJobErr(c, dbInfo.ID, tblInfo.ID, model.ActionDropColumn, []interface{}{model.NewCIStr("c5")}, ctx, d)
 }
 
+func (s *testDDLSuite) TestCancelAddIndex(c *C) {
+	defer testleak.AfterTest(c)()
+	store := testCreateStore(c, "test_cancel_add_index")
+	defer store.Close()
+	d := newDDL(goctx.Background(), nil, store, nil, nil, testLease)
+	defer d.Stop()
+	ctx := testNewContext(d)
+
+	dbInfo := testSchemaInfo(c, d, "test_cancel_job")
+	testCreateSchema(c, ctx, d, dbInfo)
+	tblInfo := testTableInfo(c, d, "t", 3)
+	testCreateTable(c, ctx, d, dbInfo, tblInfo)
+
+	tc := &testDDLCallback{}
+	var (
+		cancelState model.SchemaState
+		checkErr    error
+	)
+	tc.onJobUpdated = func(job *model.Job) {
+		if job.Type != model.ActionAddIndex || job.State != model.JobRunning || job.SchemaState != cancelState {
+			return
+		}
+		checkErr = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+			errs, err := inspectkv.CancelJobs(txn, []int64{job.ID})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(errs[0])
+		})
+	}
+	d.setHook(tc)
+
+	states := []model.SchemaState{model.StateDeleteOnly, model.StateWriteOnly, model.StateWriteReorganization}
+	for _, state := range states {
+		cancelState = state
+		job := &model.Job{
+			SchemaID:   dbInfo.ID,
+			TableID:    tblInfo.ID,
+			Type:       model.ActionAddIndex,
+			BinlogInfo: &model.HistoryInfo{},
+			Args: []interface{}{false, model.NewCIStr("c1_index"),
+				[]*ast.IndexColName{{
+					Column: &ast.ColumnName{Name: model.NewCIStr("c1")},
+					Length: types.UnspecifiedLength}}},
+		}
+		err := d.doDDLJob(ctx, job)
+		c.Assert(errors.ErrorStack(checkErr), Equals, "")
+		c.Assert(terror.ErrorEqual(err, errCancelledDDLJob), IsTrue, Commentf("err:%v", err))
+
+		kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+			t := meta.NewMeta(txn)
+			historyJob, err := t.GetHistoryDDLJob(job.ID)
+			c.Assert(err, IsNil)
+			c.Assert(historyJob.State, Equals, model.JobRollbackDone)
+			info, err := t.GetTable(dbInfo.ID, tblInfo.ID)
+			c.Assert(err, IsNil)
+			c.Assert(info.Indices, HasLen, 0)
+			return nil
+		})
+	}
+
+	// The index can be added after the cancelled jobs.
+	cancelState = model.StateNone
+	testCreateIndex(c, ctx, d, dbInfo, tblInfo, false, "c1_index", "c1")
+}
+
+func (s *testDDLSuite) TestCancelModifyColumn(c *C) {
+	defer testleak.AfterTest(c)()
+	store := testCreateStore(c, "test_cancel_modify_column")
+	defer store.Close()
+	d := newDDL(goctx.Background(), nil, store, nil, nil, testLease)
+	defer d.Stop()
+	ctx := testNewContext(d)
+
+	dbInfo := testSchemaInfo(c, d, "test_cancel_job")
+	testCreateSchema(c, ctx, d, dbInfo)
+	tblInfo := testTableInfo(c, d, "t", 3)
+	testCreateTable(c, ctx, d, dbInfo, tblInfo)
+	testCreateIndex(c, ctx, d, dbInfo, tblInfo, false, "c1_index", "c1")
+
+	tc := &testDDLCallback{}
+	var (
+		cancelState model.SchemaState
+		checkErr    error
+	)
+	tc.onJobUpdated = func(job *model.Job) {
+		if job.Type != model.ActionModifyColumn || job.State != model.JobRunning || job.SchemaState != cancelState {
+			return
+		}
+		checkErr = kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+			errs, err := inspectkv.CancelJobs(txn, []int64{job.ID})
+			if err != nil {
+				return errors.Trace(err)
+			}
+			return errors.Trace(errs[0])
+		})
+	}
+	d.setHook(tc)
+
+	newCol := tblInfo.Columns[0].Clone()
+	newCol.FieldType = *types.NewFieldType(mysql.TypeLonglong)
+	newCol.Flag |= mysql.NotNullFlag
+	states := []model.SchemaState{model.StateDeleteOnly, model.StateWriteOnly, model.StateWriteReorganization}
+	for _, state := range states {
+		cancelState = state
+		job := &model.Job{
+			SchemaID:   dbInfo.ID,
+			TableID:    tblInfo.ID,
+			Type:       model.ActionModifyColumn,
+			BinlogInfo: &model.HistoryInfo{},
+			Args:       []interface{}{newCol, newCol.Name, &ast.ColumnPosition{}, true, true},
+		}
+		err := d.doDDLJob(ctx, job)
+		c.Assert(errors.ErrorStack(checkErr), Equals, "")
+		c.Assert(terror.ErrorEqual(err, errCancelledDDLJob), IsTrue, Commentf("err:%v", err))
+
+		kv.RunInNewTxn(store, false, func(txn kv.Transaction) error {
+			t := meta.NewMeta(txn)
+			historyJob, err := t.GetHistoryDDLJob(job.ID)
+			c.Assert(err, IsNil)
+			c.Assert(historyJob.State, Equals, model.JobRollbackDone)
+			info, err := t.GetTable(dbInfo.ID, tblInfo.ID)
+			c.Assert(err, IsNil)
+			c.Assert(info.Columns, HasLen, 3)
+			c.Assert(info.Columns[0].Tp, Equals, mysql.TypeLong)
+			c.Assert(info.Indices, HasLen, 1)
+			return nil
+		})
+	}
+
+	// The column can be modified after the cancelled jobs.
+	cancelState = model.StateNone
+	job := &model.Job{
+		SchemaID:   dbInfo.ID,
+		TableID:    tblInfo.ID,
+		Type:       model.ActionModifyColumn,
+		BinlogInfo: &model.HistoryInfo{},
+		Args:       []interface{}{newCol, newCol.Name, &ast.ColumnPosition{}, true, true},
+	}
+	err := d.doDDLJob(ctx, job)
+	c.Assert(err, IsNil)
+	testCheckJobDone(c, d, job, true)
+}
+
 func testCheckOwner(c *C, d *ddl, isOwner bool, flag JobType) {
 	c.Assert(d.isOwner(flag), Equals, isOwner)
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
@@ -137,6 +138,13 @@ func buildIndexInfo(tblInfo *model.TableInfo, indexName model.CIStr, idxColNames
 }
 
 func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
+	if indexInfo.Primary {
+		for _, col := range indexInfo.Columns {
+			tblInfo.Columns[col.Offset].Flag |= mysql.PriKeyFlag
+		}
+		return
+	}
+
 	col := indexInfo.Columns[0]
 
 	if indexInfo.Unique && len(indexInfo.Columns) == 1 {
@@ -147,6 +155,14 @@ func addIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
 }
 
 func dropIndexColumnFlag(tblInfo *model.TableInfo, indexInfo *model.IndexInfo) {
+	if indexInfo.Primary {
+		// The columns keep their NOT NULL flag, the same as MySQL does.
+		for _, col := range indexInfo.Columns {
+			tblInfo.Columns[col.Offset].Flag &= ^uint(mysql.PriKeyFlag)
+		}
+		return
+	}
+
 	col := indexInfo.Columns[0]
 
 	if indexInfo.Unique && len(indexInfo.Columns) == 1 {
@@ -190,8 +206,10 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 		unique      bool
 		indexName   model.CIStr
 		idxColNames []*ast.IndexColName
+		isPrimary   bool
 	)
-	err = job.DecodeArgs(&unique, &indexName, &idxColNames)
+	// The isPrimary argument is only set by the jobs that add a primary key.
+	err = job.DecodeArgs(&unique, &indexName, &idxColNames, &isPrimary)
 	if err != nil {
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
@@ -204,12 +222,16 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	}
 
 	if indexInfo == nil {
+		if isPrimary && (tblInfo.PKIsHandle || findPrimaryIndex(tblInfo) != nil) {
+			job.State = model.JobCancelled
+			return ver, infoschema.ErrMultiplePriKey
+		}
 		indexInfo, err = buildIndexInfo(tblInfo, indexName, idxColNames, model.StateNone)
 		if err != nil {
 			job.State = model.JobCancelled
 			return ver, errors.Trace(err)
 		}
-		indexInfo.Primary = false
+		indexInfo.Primary = isPrimary
 		indexInfo.Unique = unique
 		indexInfo.ID = allocateIndexID(tblInfo)
 		tblInfo.Indices = append(tblInfo.Indices, indexInfo)
@@ -248,9 +270,18 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 		if err != nil {
 			return ver, errors.Trace(err)
 		}
+		if tblInfo.Partition != nil {
+			reorgInfo.PartitionID, err = t.GetDDLReorgPartition(job)
+			if err != nil {
+				return ver, errors.Trace(err)
+			}
+		}
 
 		err = d.runReorgJob(job, func() error {
-			return d.addTableIndex(tbl, indexInfo, reorgInfo, job)
+			if pt, ok := tbl.(table.PartitionedTable); ok {
+				return d.addPartitionedTableIndex(pt, indexInfo, reorgInfo, job)
+			}
+			return d.addTableIndex(tbl.(table.PhysicalTable), indexInfo, reorgInfo, job)
 		})
 		if err != nil {
 			if terror.ErrorEqual(err, errWaitReorgTimeout) {
@@ -259,11 +290,13 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 			}
 			if terror.ErrorEqual(err, kv.ErrKeyExists) {
 				log.Warnf("[ddl] run DDL job %v err %v, convert job to rollback job", job, err)
-				ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo)
+				ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo,
+					kv.ErrKeyExists.Gen("Duplicate for key %s", indexInfo.Name.O))
 			}
 			return ver, errors.Trace(err)
 		}
 
+		job.SetProgress(100)
 		indexInfo.State = model.StatePublic
 		// Set column index flag.
 		addIndexColumnFlag(tblInfo, indexInfo)
@@ -284,7 +317,8 @@ func (d *ddl) onCreateIndex(t *meta.Meta, job *model.Job) (ver int64, err error)
 	return ver, errors.Trace(err)
 }
 
-func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo) (ver int64, _ error) {
+// convert2RollbackJob converts an add index job to a rollback job, the err is the reason of the rollback.
+func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.TableInfo, indexInfo *model.IndexInfo, err error) (ver int64, _ error) {
 	job.State = model.JobRollback
 	job.Args = []interface{}{indexInfo.Name}
 	// If add index job rollbacks in write reorganization state, its need to delete all keys which has been added.
@@ -294,11 +328,42 @@ func (d *ddl) convert2RollbackJob(t *meta.Meta, job *model.Job, tblInfo *model.T
 	indexInfo.State = model.StateDeleteOnly
 	originalState := indexInfo.State
 	job.SchemaState = model.StateDeleteOnly
-	_, err := updateTableInfo(t, job, tblInfo, originalState)
+	ver, err1 := updateTableInfo(t, job, tblInfo, originalState)
+	if err1 != nil {
+		return ver, errors.Trace(err1)
+	}
+	return ver, err
+}
+
+// rollbackAddIndex cancels a running add index job and rolls back the index that has been added.
+func (d *ddl) rollbackAddIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	var (
+		unique      bool
+		indexName   model.CIStr
+		idxColNames []*ast.IndexColName
+	)
+	err = job.DecodeArgs(&unique, &indexName, &idxColNames)
 	if err != nil {
+		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
 	}
-	return ver, kv.ErrKeyExists.Gen("Duplicate for key %s", indexInfo.Name.O)
+
+	indexInfo := findIndexByName(indexName.L, tblInfo.Indices)
+	if indexInfo == nil {
+		job.State = model.JobCancelled
+		return ver, errCancelledDDLJob
+	}
+	if indexInfo.State == model.StateWriteReorganization {
+		d.stopReorgJob(job, reorgCancelled)
+	}
+	ver, err = d.convert2RollbackJob(t, job, tblInfo, indexInfo, errCancelledDDLJob)
+	return ver, errors.Trace(err)
 }
 
 func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
@@ -340,7 +405,7 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	case model.StateDeleteReorganization:
 		// reorganization -> absent
 		err = d.runReorgJob(job, func() error {
-			return d.dropTableIndex(indexInfo, job)
+			return d.dropTableIndex(tblInfo, indexInfo, job)
 		})
 		if err != nil {
 			// If the timeout happens, we should return.
@@ -382,7 +447,7 @@ func (d *ddl) onDropIndex(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 func (d *ddl) fetchRowColVals(txn kv.Transaction, t table.Table, taskOpInfo *indexTaskOpInfo, handleInfo *handleInfo) (
 	[]*indexRecord, *taskResult) {
 	startTime := time.Now()
-	handleCnt := defaultTaskHandleCnt
+	handleCnt := taskOpInfo.batchSize
 	rawRecords := make([][]byte, 0, handleCnt)
 	idxRecords := make([]*indexRecord, 0, handleCnt)
 	ret := &taskResult{doneHandle: handleInfo.startHandle}
@@ -467,8 +532,6 @@ func (d *ddl) getIndexRecords(t table.Table, taskOpInfo *indexTaskOpInfo, rawRec
 const (
 	defaultBatchCnt      = 1024
 	defaultSmallBatchCnt = 128
-	defaultTaskHandleCnt = 128
-	defaultTaskCnt       = 16
 )
 
 // taskResult is the result of the task.
@@ -497,13 +560,14 @@ type indexTaskOpInfo struct {
 	colMap    map[int64]*types.FieldType // It's the index columns map.
 	taskRetCh chan *taskResult           // Get the results of all tasks.
 	nextCh    chan int64                 // It notifies to start the next task.
+	batchSize int                        // It's the number of the handles that each task deals with.
 }
 
 // addTableIndex adds index into table.
 // TODO: Move this to doc or wiki.
 // How to add index in reorganization state?
-// Concurrently process the defaultTaskHandleCnt tasks. Each task deals with a handle range of the index record.
-// The handle range size is defaultTaskHandleCnt.
+// Concurrently process the tidb_ddl_reorg_worker_cnt tasks. Each task deals with a handle range of the index record.
+// The handle range size is tidb_ddl_reorg_batch_size.
 // Because each handle range depends on the previous one, it's necessary to obtain the handle range serially.
 // Real concurrent processing needs to perform after the handle range has been acquired.
 // The operation flow of the each task of data is as follows:
@@ -518,25 +582,44 @@ type indexTaskOpInfo struct {
 // task results, get the total number of rows in the concurrent task and update the processed handle value. If
 // an error message is displayed, exit the traversal.
 // Finally, update the concurrent processing of the total number of rows, and store the completed handle value.
-func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
+// The worker count, the batch size and the rate limit are read from the global variables before every round, and
+// the round sleeps if it's faster than the rate limit.
+func (d *ddl) addTableIndex(t table.PhysicalTable, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
 	cols := t.Cols()
 	colMap := make(map[int64]*types.FieldType)
 	for _, v := range indexInfo.Columns {
 		col := cols[v.Offset]
 		colMap[col.ID] = &col.FieldType
 	}
-	taskCnt := defaultTaskCnt
 	taskOpInfo := &indexTaskOpInfo{
-		tblIndex:  tables.NewIndex(t.Meta(), indexInfo),
-		colMap:    colMap,
-		nextCh:    make(chan int64, 1),
-		taskRetCh: make(chan *taskResult, taskCnt),
+		tblIndex: tables.NewIndex(t.GetPhysicalID(), t.Meta(), indexInfo),
+		colMap:   colMap,
+		nextCh:   make(chan int64, 1),
 	}
 
+	// The reorganization starts from handle 0, see getReorgInfo.
+	firstHandle, lastHandle, err := d.getTableHandleRange(t, reorgInfo.SnapshotVer, 0)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	addedCount := job.GetRowCount()
 	taskStartHandle := reorgInfo.Handle
 
 	for {
+		params := d.getBackfillParams()
+		taskCnt, batchSize := params.workerCnt, params.batchSize
+		if params.rateLimit > 0 {
+			// A round handles about rateLimit rows at most, so the backfill doesn't burst.
+			if batchSize > params.rateLimit {
+				batchSize = params.rateLimit
+			}
+			if cnt := (params.rateLimit + batchSize - 1) / batchSize; taskCnt > cnt {
+				taskCnt = cnt
+			}
+		}
+		taskOpInfo.batchSize = batchSize
+		taskOpInfo.taskRetCh = make(chan *taskResult, taskCnt)
+
 		startTime := time.Now()
 		wg := sync.WaitGroup{}
 		for i := 0; i < taskCnt; i++ {
@@ -553,10 +636,11 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 
 		retCnt := len(taskOpInfo.taskRetCh)
 		taskAddedCount, doneHandle, err := getCountAndHandle(taskOpInfo)
-		// Update the reorg handle that has been processed.
+		// Update the reorg handle that has been processed, the reorganization continues from the next handle
+		// when it's restarted, see getReorgInfo.
 		if taskAddedCount != 0 {
 			err1 := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
-				return errors.Trace(reorgInfo.UpdateHandle(txn, doneHandle+1))
+				return errors.Trace(reorgInfo.UpdateHandle(txn, doneHandle))
 			})
 			if err1 != nil {
 				if err == nil {
@@ -575,6 +659,10 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 			return errors.Trace(err)
 		}
 		d.setReorgRowCount(addedCount)
+		if taskAddedCount != 0 && lastHandle > firstHandle {
+			ratio := float64(doneHandle-firstHandle) / float64(lastHandle-firstHandle)
+			d.setReorgProgress(reorgInfo.progress(math.Min(ratio, 1)))
+		}
 		batchHandleDataHistogram.WithLabelValues(batchAddIdx).Observe(sub)
 		log.Infof("[ddl] total added index for %d rows, this task added index for %d rows, take time %v",
 			addedCount, taskAddedCount, sub)
@@ -582,7 +670,42 @@ func (d *ddl) addTableIndex(t table.Table, indexInfo *model.IndexInfo, reorgInfo
 		if retCnt < taskCnt {
 			return nil
 		}
+		if err = d.throttleBackfill(taskAddedCount, time.Since(startTime), params.rateLimit); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// addPartitionedTableIndex adds index into the partitions one by one, it starts from the partition in reorgInfo,
+// or the first partition if the reorganization has not started.
+func (d *ddl) addPartitionedTableIndex(t table.PartitionedTable, indexInfo *model.IndexInfo, reorgInfo *reorgInfo, job *model.Job) error {
+	defs := t.Meta().Partition.Definitions
+	start := 0
+	for i, def := range defs {
+		if def.ID == reorgInfo.PartitionID {
+			start = i
+			break
+		}
+	}
+	reorgInfo.partitionCnt = len(defs)
+	for i := start; i < len(defs); i++ {
+		reorgInfo.partitionIdx = i
+		if defs[i].ID != reorgInfo.PartitionID {
+			err := kv.RunInNewTxn(d.store, true, func(txn kv.Transaction) error {
+				return errors.Trace(reorgInfo.UpdatePartition(txn, defs[i].ID))
+			})
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		// The added count of the previous partitions is kept in the reorg row count.
+		job.SetRowCount(d.getReorgRowCount())
+		err := d.addTableIndex(t.GetPartition(defs[i].ID), indexInfo, reorgInfo, job)
+		if err != nil {
+			return errors.Trace(err)
+		}
 	}
+	return nil
 }
 
 // handleInfo records start and end handle that is used in a task.
@@ -684,12 +807,21 @@ func (d *ddl) doBackfillIndexTaskInTxn(t table.Table, txn kv.Transaction, taskOp
 	return taskRet
 }
 
-func (d *ddl) dropTableIndex(indexInfo *model.IndexInfo, job *model.Job) error {
-	startKey := tablecodec.EncodeTableIndexPrefix(job.TableID, indexInfo.ID)
-	// It's asynchronous so it doesn't need to consider if it completes.
-	deleteAll := -1
-	_, _, err := d.delKeysWithStartKey(startKey, startKey, ddlJobFlag, job, deleteAll)
-	return errors.Trace(err)
+func (d *ddl) dropTableIndex(tblInfo *model.TableInfo, indexInfo *model.IndexInfo, job *model.Job) error {
+	physicalTableIDs := []int64{job.TableID}
+	if tblInfo.Partition != nil {
+		physicalTableIDs = getPartitionIDs(tblInfo)
+	}
+	for _, id := range physicalTableIDs {
+		startKey := tablecodec.EncodeTableIndexPrefix(id, indexInfo.ID)
+		// It's asynchronous so it doesn't need to consider if it completes.
+		deleteAll := -1
+		_, _, err := d.delKeysWithStartKey(startKey, startKey, ddlJobFlag, job, deleteAll)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
 }
 
 func findIndexByName(idxName string, indices []*model.IndexInfo) *model.IndexInfo {
@@ -701,6 +833,16 @@ func findIndexByName(idxName string, indices []*model.IndexInfo) *model.IndexInf
 	return nil
 }
 
+// findPrimaryIndex returns the primary key index of the table, or nil if the primary key is the handle or doesn't exist.
+func findPrimaryIndex(tblInfo *model.TableInfo) *model.IndexInfo {
+	for _, idx := range tblInfo.Indices {
+		if idx.Primary {
+			return idx
+		}
+	}
+	return nil
+}
+
 func allocateIndexID(tblInfo *model.TableInfo) int64 {
 	tblInfo.MaxIndexID++
 	return tblInfo.MaxIndexID
@@ -709,6 +851,50 @@ func allocateIndexID(tblInfo *model.TableInfo) int64 {
 // recordIterFunc is used for low-level record iteration.
 type recordIterFunc func(h int64, rowKey kv.Key, rawRecord []byte) (more bool, err error)
 
+// getTableHandleRange gets the first and the last handle of the table in the snapshot of version, the handles which
+// are less than startHandle are ignored. The last handle is found by binary search, because not all the storages
+// support reverse seeking. If the table is empty, the first and the last handle are both startHandle.
+func (d *ddl) getTableHandleRange(t table.PhysicalTable, version uint64, startHandle int64) (first, last int64, err error) {
+	snap, err := d.store.GetSnapshot(kv.Version{Ver: version})
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	// seek returns the first handle which isn't less than h.
+	seek := func(h int64) (int64, bool, error) {
+		it, err1 := snap.Seek(t.RecordKey(h))
+		if err1 != nil {
+			return 0, false, errors.Trace(err1)
+		}
+		defer it.Close()
+		if !it.Valid() || !it.Key().HasPrefix(t.RecordPrefix()) {
+			return 0, false, nil
+		}
+		h, err1 = tablecodec.DecodeRowKey(it.Key())
+		return h, true, errors.Trace(err1)
+	}
+
+	first, ok, err := seek(startHandle)
+	if err != nil || !ok {
+		return startHandle, startHandle, errors.Trace(err)
+	}
+	// The last handle is in [last, upper].
+	last, upper := first, int64(math.MaxInt64)
+	for last < upper {
+		// The difference is computed in uint64 because it may overflow int64 if last is negative.
+		mid := last + int64((uint64(upper)-uint64(last))/2) + 1
+		h, ok, err := seek(mid)
+		if err != nil {
+			return 0, 0, errors.Trace(err)
+		}
+		if ok {
+			last = h
+		} else {
+			upper = mid - 1
+		}
+	}
+	return first, last, nil
+}
+
 func (d *ddl) iterateSnapshotRows(t table.Table, version uint64, seekHandle int64, fn recordIterFunc) error {
 	ver := kv.Version{Ver: version}
 	snap, err := d.store.GetSnapshot(ver)
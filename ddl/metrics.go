@@ -39,6 +39,7 @@ var (
 	// handle batch data type.
 	batchAddCol              = "batch_add_col"
 	batchAddIdx              = "batch_add_idx"
+	batchModifyCol           = "batch_modify_col"
 	batchDelData             = "batch_del_data"
 	batchHandleDataHistogram = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
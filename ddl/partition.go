@@ -0,0 +1,411 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+const partitionMaxValue = "MAXVALUE"
+
+// buildTablePartitionInfo builds the partition info of the new table from the PARTITION BY clause.
+func (d *ddl) buildTablePartitionInfo(ctx context.Context, s *ast.PartitionOptions, tbInfo *model.TableInfo) (*model.PartitionInfo, error) {
+	pi := &model.PartitionInfo{
+		Type: s.Tp,
+		Expr: strings.TrimSpace(s.Expr.Text()),
+	}
+	expr, err := expression.RewriteAstExpr(ctx, s.Expr, tbInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if expr.GetType().ToClass() != types.ClassInt {
+		return nil, ErrPartitionFuncNotAllowed.GenByArgs("PARTITION")
+	}
+
+	switch s.Tp {
+	case model.PartitionTypeHash:
+		num := s.Num
+		if len(s.Definitions) > 0 {
+			num = uint64(len(s.Definitions))
+		}
+		if num == 0 {
+			num = 1
+		}
+		for i := uint64(0); i < num; i++ {
+			def := model.PartitionDefinition{Name: model.NewCIStr(fmt.Sprintf("p%d", i))}
+			if len(s.Definitions) > 0 {
+				if len(s.Definitions[i].LessThan) > 0 || s.Definitions[i].MaxValue {
+					return nil, ErrPartitionWrongValues.GenByArgs("RANGE", "LESS THAN")
+				}
+				def.Name = s.Definitions[i].Name
+			}
+			pi.Definitions = append(pi.Definitions, def)
+		}
+	case model.PartitionTypeRange:
+		if len(s.Definitions) == 0 {
+			return nil, ErrPartitionsMustBeDefined.GenByArgs("RANGE")
+		}
+		pi.Definitions, err = buildRangePartitionDefinitions(ctx, s.Definitions)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	if err = checkPartitionDefinitions(pi); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if err = checkPartitionKeysConstraint(tbInfo, expr); err != nil {
+		return nil, errors.Trace(err)
+	}
+	for i := range pi.Definitions {
+		pi.Definitions[i].ID, err = d.genGlobalID()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	pi.Num = uint64(len(pi.Definitions))
+	return pi, nil
+}
+
+// buildRangePartitionDefinitions evaluates the VALUES LESS THAN values of the range partitions.
+func buildRangePartitionDefinitions(ctx context.Context, defs []*ast.PartitionDefinition) ([]model.PartitionDefinition, error) {
+	definitions := make([]model.PartitionDefinition, 0, len(defs))
+	for _, def := range defs {
+		if def.MaxValue {
+			definitions = append(definitions, model.PartitionDefinition{Name: def.Name, LessThan: []string{partitionMaxValue}})
+			continue
+		}
+		if len(def.LessThan) == 0 {
+			return nil, ErrPartitionRequiresValues.GenByArgs("RANGE", "LESS THAN")
+		}
+		if len(def.LessThan) > 1 {
+			return nil, ErrPartitionColumnList
+		}
+		v, err := expression.EvalAstExpr(def.LessThan[0], ctx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		bound, err := v.ToInt64(ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		definitions = append(definitions, model.PartitionDefinition{Name: def.Name, LessThan: []string{strconv.FormatInt(bound, 10)}})
+	}
+	return definitions, nil
+}
+
+// checkPartitionDefinitions checks that the partition names are unique, and the bounds of the range partitions
+// are strictly increasing.
+func checkPartitionDefinitions(pi *model.PartitionInfo) error {
+	names := make(map[string]struct{}, len(pi.Definitions))
+	for _, def := range pi.Definitions {
+		if _, ok := names[def.Name.L]; ok {
+			return ErrSameNamePartition.GenByArgs(def.Name.O)
+		}
+		names[def.Name.L] = struct{}{}
+	}
+	if pi.Type != model.PartitionTypeRange {
+		return nil
+	}
+	prev := int64(math.MinInt64)
+	for i, def := range pi.Definitions {
+		if def.LessThan[0] == partitionMaxValue {
+			if i != len(pi.Definitions)-1 {
+				return ErrPartitionMaxvalue
+			}
+			continue
+		}
+		bound, err := strconv.ParseInt(def.LessThan[0], 10, 64)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if i > 0 && bound <= prev {
+			return ErrRangeNotIncreasing
+		}
+		prev = bound
+	}
+	return nil
+}
+
+// checkPartitionKeysConstraint checks that every unique key includes all the columns in the partition expression,
+// so the rows with the same unique key are always in the same partition.
+func checkPartitionKeysConstraint(tbInfo *model.TableInfo, expr expression.Expression) error {
+	partCols := expression.ExtractColumns(expr)
+	if tbInfo.PKIsHandle {
+		pkCol := tbInfo.GetPkColInfo()
+		for _, col := range partCols {
+			if tbInfo.Columns[col.Index].Name.L != pkCol.Name.L {
+				return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("PRIMARY KEY")
+			}
+		}
+	}
+	for _, idx := range tbInfo.Indices {
+		if !idx.Unique {
+			continue
+		}
+		for _, col := range partCols {
+			if findIndexColumn(idx, tbInfo.Columns[col.Index].Name.L) == nil {
+				if idx.Primary {
+					return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("PRIMARY KEY")
+				}
+				return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs("UNIQUE INDEX")
+			}
+		}
+	}
+	return nil
+}
+
+// checkIndexPartitionKeys checks that the new unique key includes all the columns in the partition expression of
+// the partitioned table, keyName is the kind of the key in the error.
+func checkIndexPartitionKeys(tblInfo *model.TableInfo, idxColNames []*ast.IndexColName, keyName string) error {
+	if tblInfo.Partition == nil {
+		return nil
+	}
+	partCols, err := partitionColumnNames(tblInfo.Partition)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, name := range partCols {
+		found := false
+		for _, idxCol := range idxColNames {
+			if idxCol.Column.Name.L == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrUniqueKeyNeedAllFieldsInPf.GenByArgs(keyName)
+		}
+	}
+	return nil
+}
+
+// partitionColumnNames returns the lower case names of the columns in the partition expression.
+func partitionColumnNames(pi *model.PartitionInfo) ([]string, error) {
+	stmt, err := parser.New().ParseOneStmt("select "+pi.Expr, "", "")
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	extractor := &columnNameExtractor{}
+	stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.Accept(extractor)
+	return extractor.names, nil
+}
+
+// columnNameExtractor collects the names of the columns in an expression.
+type columnNameExtractor struct {
+	names []string
+}
+
+// Enter implements ast.Visitor Enter interface.
+func (e *columnNameExtractor) Enter(node ast.Node) (ast.Node, bool) {
+	return node, false
+}
+
+// Leave implements ast.Visitor Leave interface.
+func (e *columnNameExtractor) Leave(node ast.Node) (ast.Node, bool) {
+	if col, ok := node.(*ast.ColumnNameExpr); ok {
+		e.names = append(e.names, col.Name.Name.L)
+	}
+	return node, true
+}
+
+func findIndexColumn(idx *model.IndexInfo, name string) *model.IndexColumn {
+	for _, col := range idx.Columns {
+		if col.Name.L == name {
+			return col
+		}
+	}
+	return nil
+}
+
+// getPartitionIDs returns the IDs of the partitions of the table.
+func getPartitionIDs(tblInfo *model.TableInfo) []int64 {
+	if tblInfo.Partition == nil {
+		return nil
+	}
+	ids := make([]int64, 0, len(tblInfo.Partition.Definitions))
+	for _, def := range tblInfo.Partition.Definitions {
+		ids = append(ids, def.ID)
+	}
+	return ids
+}
+
+// getPhysicalTableIDs returns the IDs under which the data of the table is stored.
+func getPhysicalTableIDs(tblInfo *model.TableInfo) []int64 {
+	return append([]int64{tblInfo.ID}, getPartitionIDs(tblInfo)...)
+}
+
+// resetPartitionIDs allocates new IDs for all the partitions, so the old data can not be accessed any more.
+func resetPartitionIDs(t *meta.Meta, pi *model.PartitionInfo) error {
+	for i := range pi.Definitions {
+		id, err := t.GenGlobalID()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		pi.Definitions[i].ID = id
+	}
+	return nil
+}
+
+// findPartitionByName returns the offset of the partition in the definitions, it returns -1 if it's not found.
+func findPartitionByName(pi *model.PartitionInfo, name string) int {
+	for i, def := range pi.Definitions {
+		if def.Name.L == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func (d *ddl) onAddTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	partInfo := &model.PartitionInfo{}
+	if err := job.DecodeArgs(partInfo); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.Partition == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	pi := tblInfo.Partition.Clone()
+	pi.Definitions = append(pi.Definitions, partInfo.Definitions...)
+	if err = checkPartitionDefinitions(pi); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	pi.Num = uint64(len(pi.Definitions))
+	tblInfo.Partition = pi
+
+	// The new partitions are empty, so they can be public at once.
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	return ver, nil
+}
+
+func (d *ddl) onDropTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var partNames []string
+	if err := job.DecodeArgs(&partNames); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.Partition == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	pi := tblInfo.Partition.Clone()
+	droppedIDs := make([]int64, 0, len(partNames))
+	for _, name := range partNames {
+		offset := findPartitionByName(pi, name)
+		if offset < 0 {
+			job.State = model.JobCancelled
+			return ver, ErrDropPartitionNonExistent.GenByArgs("DROP")
+		}
+		droppedIDs = append(droppedIDs, pi.Definitions[offset].ID)
+		pi.Definitions = append(pi.Definitions[:offset], pi.Definitions[offset+1:]...)
+	}
+	if len(pi.Definitions) == 0 {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrDropLastPartition)
+	}
+	pi.Num = uint64(len(pi.Definitions))
+	tblInfo.Partition = pi
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StateNone
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	// A background job will be created to delete the data of the dropped partitions.
+	job.Args = []interface{}{tablecodec.EncodeTablePrefix(droppedIDs[0]), droppedIDs}
+	return ver, nil
+}
+
+// onTruncateTablePartition allocates new IDs for the partitions, as all the old data is encoded with the old IDs,
+// it can not be accessed any more. A background job will be created to delete old data.
+func (d *ddl) onTruncateTablePartition(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	var partNames []string
+	if err := job.DecodeArgs(&partNames); err != nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+	tblInfo, err := getTableInfo(t, job, job.SchemaID)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if tblInfo.Partition == nil {
+		job.State = model.JobCancelled
+		return ver, errors.Trace(ErrPartitionMgmtOnNonpartitioned)
+	}
+	pi := tblInfo.Partition.Clone()
+	oldIDs := make([]int64, 0, len(partNames))
+	for _, name := range partNames {
+		offset := findPartitionByName(pi, name)
+		if offset < 0 {
+			job.State = model.JobCancelled
+			return ver, ErrDropPartitionNonExistent.GenByArgs("TRUNCATE")
+		}
+		oldIDs = append(oldIDs, pi.Definitions[offset].ID)
+		pi.Definitions[offset].ID, err = t.GenGlobalID()
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+	}
+	tblInfo.Partition = pi
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+	if err = t.UpdateTable(job.SchemaID, tblInfo); err != nil {
+		return ver, errors.Trace(err)
+	}
+	job.State = model.JobDone
+	job.SchemaState = model.StatePublic
+	job.BinlogInfo.AddTableInfo(ver, tblInfo)
+	job.Args = []interface{}{tablecodec.EncodeTablePrefix(oldIDs[0]), oldIDs}
+	return ver, nil
+}
@@ -14,6 +14,8 @@
 package ddl
 
 import (
+	"math"
+	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -24,6 +26,8 @@ import (
 	"github.com/pingcap/tidb/meta"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/mock"
 )
@@ -46,6 +50,14 @@ func (d *ddl) getReorgRowCount() int64 {
 	return atomic.LoadInt64(&d.reorgRowCount)
 }
 
+func (d *ddl) setReorgProgress(progress float64) {
+	atomic.StoreUint64(&d.reorgProgress, math.Float64bits(progress))
+}
+
+func (d *ddl) getReorgProgress() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&d.reorgProgress))
+}
+
 func (d *ddl) runReorgJob(job *model.Job, f func() error) error {
 	if d.reorgDoneCh == nil {
 		// start a reorganization job
@@ -72,30 +84,76 @@ func (d *ddl) runReorgJob(job *model.Job, f func() error) error {
 	case err := <-d.reorgDoneCh:
 		log.Info("[ddl] run reorg job done")
 		d.reorgDoneCh = nil
-		// Update a job's RowCount.
+		// Update a job's RowCount and Progress.
 		job.SetRowCount(d.getReorgRowCount())
+		job.SetProgress(d.getReorgProgress())
 		d.setReorgRowCount(0)
+		d.setReorgProgress(0)
 		return errors.Trace(err)
 	case <-d.quitCh:
 		log.Info("[ddl] run reorg job ddl quit")
 		d.setReorgRowCount(0)
+		d.setReorgProgress(0)
 		// We return errWaitReorgTimeout here too, so that outer loop will break.
 		return errWaitReorgTimeout
 	case <-time.After(waitTimeout):
 		log.Infof("[ddl] run reorg job wait timeout %v", waitTimeout)
-		// Update a job's RowCount.
+		// Update a job's RowCount and Progress.
 		job.SetRowCount(d.getReorgRowCount())
+		job.SetProgress(d.getReorgProgress())
 		// If timeout, we will return, check the owner and retry to wait job done again.
 		return errWaitReorgTimeout
 	}
 }
 
+// The reasons of stopping the running reorganization.
+const (
+	reorgNotStopped int32 = iota
+	reorgCancelled
+	reorgPaused
+)
+
+// stopReorgJob stops the running reorganization goroutine because the job is cancelled or paused by users,
+// and waits for it to exit. The row count and progress of the reorganization are saved in the job.
+// It returns false if there is no running reorganization.
+func (d *ddl) stopReorgJob(job *model.Job, reason int32) bool {
+	if d.reorgDoneCh == nil {
+		return false
+	}
+	atomic.StoreInt32(&d.reorgStopped, reason)
+	err := <-d.reorgDoneCh
+	log.Infof("[ddl] stop reorg job, err %v", err)
+	d.reorgDoneCh = nil
+	job.SetRowCount(d.getReorgRowCount())
+	job.SetProgress(d.getReorgProgress())
+	d.setReorgRowCount(0)
+	d.setReorgProgress(0)
+	atomic.StoreInt32(&d.reorgStopped, reorgNotStopped)
+	return true
+}
+
+// checkReorgStopped returns an error if the running reorganization is stopped by users.
+func (d *ddl) checkReorgStopped() error {
+	switch atomic.LoadInt32(&d.reorgStopped) {
+	case reorgCancelled:
+		return errCancelledDDLJob
+	case reorgPaused:
+		return errPausedDDLJob
+	}
+	return nil
+}
+
 func (d *ddl) isReorgRunnable(txn kv.Transaction, flag JobType) error {
 	if d.isClosed() {
 		// worker is closed, can't run reorganization.
 		return errInvalidWorker.Gen("worker is closed")
 	}
 
+	if err := d.checkReorgStopped(); err != nil {
+		// The job is cancelled or paused, the reorganization should be stopped.
+		return errors.Trace(err)
+	}
+
 	if !d.isOwner(flag) {
 		// If it's not the owner, we will try later, so here just returns an error.
 		log.Infof("[ddl] the %s not the %s job owner, txnTS:%d", d.uuid, flag, txn.StartTS())
@@ -104,6 +162,76 @@ func (d *ddl) isReorgRunnable(txn kv.Transaction, flag JobType) error {
 	return nil
 }
 
+const (
+	maxDDLReorgWorkerCnt = 256
+	maxDDLReorgBatchSize = 10240
+	// throttleCheckInterval is the max interval to check if the reorganization is stopped when it's throttled.
+	throttleCheckInterval = 100 * time.Millisecond
+)
+
+// backfillParams is the parameters of the backfill, they're read from the global variables before every round.
+type backfillParams struct {
+	workerCnt int
+	batchSize int
+	// rateLimit is the max number of rows backfilled per second, 0 means no limit.
+	rateLimit int
+}
+
+func (d *ddl) getBackfillParams() *backfillParams {
+	d.varsMu.RLock()
+	defer d.varsMu.RUnlock()
+
+	return &backfillParams{
+		workerCnt: d.getGlobalIntVar(variable.TiDBDDLReorgWorkerCnt, variable.DefDDLReorgWorkerCnt, 1, maxDDLReorgWorkerCnt),
+		batchSize: d.getGlobalIntVar(variable.TiDBDDLReorgBatchSize, variable.DefDDLReorgBatchSize, 1, maxDDLReorgBatchSize),
+		rateLimit: d.getGlobalIntVar(variable.TiDBDDLReorgRateLimit, variable.DefDDLReorgRateLimit, 0, math.MaxInt32),
+	}
+}
+
+// getGlobalIntVar reads an integer global variable. It returns def if there is no global variables accessor,
+// or the value can't be read or isn't in [min, max].
+func (d *ddl) getGlobalIntVar(name string, def, min, max int) int {
+	if d.workerVars.GlobalVarsAccessor == nil {
+		return def
+	}
+	str, err := varsutil.GetGlobalSystemVar(d.workerVars, name)
+	if err != nil {
+		log.Warnf("[ddl] get global variable %s err %v, use %d", name, err, def)
+		return def
+	}
+	val, err := strconv.Atoi(str)
+	if err != nil || val < min || val > max {
+		log.Warnf("[ddl] invalid value %q of global variable %s, use %d", str, name, def)
+		return def
+	}
+	return val
+}
+
+// throttleBackfill sleeps to keep the speed of the backfill under the rate limit, after the rows are backfilled
+// in the elapsed time. It returns an error if the reorganization is stopped during the sleep.
+func (d *ddl) throttleBackfill(rows int64, elapsed time.Duration, rateLimit int) error {
+	if rateLimit <= 0 || rows <= 0 {
+		return nil
+	}
+	wait := time.Duration(float64(rows)/float64(rateLimit)*float64(time.Second)) - elapsed
+	for wait > 0 {
+		interval := wait
+		if interval > throttleCheckInterval {
+			interval = throttleCheckInterval
+		}
+		select {
+		case <-d.quitCh:
+			return errInvalidWorker.Gen("worker is closed")
+		case <-time.After(interval):
+		}
+		wait -= interval
+		if err := d.checkReorgStopped(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
 // delKeysWithStartKey deletes keys with start key in a limited number. If limit < 0, deletes all keys.
 // It returns the number of rows deleted, next start key and the error.
 func (d *ddl) delKeysWithStartKey(prefix, startKey kv.Key, jobType JobType, job *model.Job, limit int) (int, kv.Key, error) {
@@ -186,8 +314,23 @@ func (d *ddl) delKeysWithStartKey(prefix, startKey kv.Key, jobType JobType, job
 type reorgInfo struct {
 	*model.Job
 	Handle int64
-	d      *ddl
-	first  bool
+	// PartitionID is the partition which is being reorganized if the table is partitioned.
+	PartitionID int64
+	d           *ddl
+	first       bool
+	// partitionIdx and partitionCnt are the index of the partition which is being reorganized and the number
+	// of the partitions, they're used to estimate the progress of the partitioned table.
+	partitionIdx int
+	partitionCnt int
+}
+
+// progress returns the estimated completion percent of the reorganization,
+// ratio is the completed ratio of the table or partition which is being reorganized.
+func (r *reorgInfo) progress(ratio float64) float64 {
+	if r.partitionCnt == 0 {
+		return ratio * 100
+	}
+	return (float64(r.partitionIdx) + ratio) / float64(r.partitionCnt) * 100
 }
 
 func (d *ddl) getReorgInfo(t *meta.Meta, job *model.Job) (*reorgInfo, error) {
@@ -229,3 +372,14 @@ func (r *reorgInfo) UpdateHandle(txn kv.Transaction, handle int64) error {
 	t := meta.NewMeta(txn)
 	return errors.Trace(t.UpdateDDLReorgHandle(r.Job, handle))
 }
+
+// UpdatePartition moves the reorganization to the beginning of the partition.
+func (r *reorgInfo) UpdatePartition(txn kv.Transaction, partitionID int64) error {
+	t := meta.NewMeta(txn)
+	if err := t.UpdateDDLReorgPartition(r.Job, partitionID); err != nil {
+		return errors.Trace(err)
+	}
+	r.PartitionID = partitionID
+	r.Handle = 0
+	return errors.Trace(t.UpdateDDLReorgHandle(r.Job, 0))
+}
@@ -137,6 +137,9 @@ func getIDs(tables []*model.TableInfo) []int64 {
 	ids := make([]int64, 0, len(tables))
 	for _, t := range tables {
 		ids = append(ids, t.ID)
+		if t.Partition != nil {
+			ids = append(ids, getPartitionIDs(t)...)
+		}
 	}
 
 	return ids
@@ -177,7 +180,7 @@ func (d *ddl) dropSchemaData(tIDs []int64, startKey kv.Key, job *model.Job, m *m
 		if startKey == nil {
 			startKey = tablecodec.EncodeTablePrefix(id)
 		}
-		delCount, err := d.dropTableData(startKey, job, defaultBatchCnt)
+		delCount, err := d.dropTableData(id, startKey, job, defaultBatchCnt)
 		if err != nil {
 			return false, errors.Trace(err)
 		}
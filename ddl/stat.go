@@ -61,7 +61,7 @@ func (d *ddl) GetScope(status string) variable.ScopeFlag {
 }
 
 // Stats returns the DDL statistics.
-func (d *ddl) Stats() (map[string]interface{}, error) {
+func (d *ddl) Stats(vars *variable.SessionVars) (map[string]interface{}, error) {
 	m := make(map[string]interface{})
 	m[serverID] = d.uuid
 	var ddlInfo, bgInfo *inspectkv.DDLInfo
@@ -64,6 +64,63 @@ func (d *ddl) onCreateTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	}
 }
 
+func (d *ddl) onCreateView(t *meta.Meta, job *model.Job) (ver int64, _ error) {
+	schemaID := job.SchemaID
+	tbInfo := &model.TableInfo{}
+	var orReplace bool
+	if err := job.DecodeArgs(tbInfo, &orReplace); err != nil {
+		// Invalid arguments, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(err)
+	}
+
+	tbInfo.State = model.StateNone
+	oldTbInfo, err := t.GetTable(schemaID, tbInfo.ID)
+	if err != nil {
+		if terror.ErrorEqual(err, meta.ErrDBNotExists) {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(infoschema.ErrDatabaseNotExists)
+		}
+		return ver, errors.Trace(err)
+	}
+	if oldTbInfo == nil || !orReplace {
+		err = checkTableNotExists(t, job, schemaID, tbInfo.Name.L)
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+	} else if !oldTbInfo.IsView() || oldTbInfo.Name.L != tbInfo.Name.L {
+		// The view was dropped or renamed by a concurrent DDL, cancel this job.
+		job.State = model.JobCancelled
+		return ver, errors.Trace(infoschema.ErrTableNotExists.GenByArgs("", tbInfo.Name))
+	}
+
+	ver, err = updateSchemaVersion(t, job)
+	if err != nil {
+		return ver, errors.Trace(err)
+	}
+
+	switch tbInfo.State {
+	case model.StateNone:
+		// none -> public
+		job.SchemaState = model.StatePublic
+		tbInfo.State = model.StatePublic
+		if oldTbInfo != nil {
+			err = t.UpdateTable(schemaID, tbInfo)
+		} else {
+			err = t.CreateTable(schemaID, tbInfo)
+		}
+		if err != nil {
+			return ver, errors.Trace(err)
+		}
+		// Finish this job.
+		job.State = model.JobDone
+		job.BinlogInfo.AddTableInfo(ver, tbInfo)
+		return ver, nil
+	default:
+		return ver, ErrInvalidTableState.Gen("invalid view state %v", tbInfo.State)
+	}
+}
+
 func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 	schemaID := job.SchemaID
 	tableID := job.TableID
@@ -110,7 +167,7 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 		job.State = model.JobDone
 		job.BinlogInfo.AddTableInfo(ver, tblInfo)
 		startKey := tablecodec.EncodeTablePrefix(tableID)
-		job.Args = append(job.Args, startKey)
+		job.Args = append(job.Args, startKey, getPhysicalTableIDs(tblInfo))
 		d.asyncNotifyEvent(&Event{Tp: model.ActionDropTable, TableInfo: tblInfo})
 	default:
 		err = ErrInvalidTableState.Gen("invalid table state %v", tblInfo.State)
@@ -122,22 +179,34 @@ func (d *ddl) onDropTable(t *meta.Meta, job *model.Job) (ver int64, _ error) {
 // Maximum number of keys to delete for each reorg table job run.
 var reorgTableDeleteLimit = 65536
 
+// delReorgTable deletes the data of the physical tables in the job arguments, the data of the first physical table
+// is deleted from the startKey. If there is no physical table in the arguments, the data of job.TableID is deleted.
 func (d *ddl) delReorgTable(t *meta.Meta, job *model.Job) error {
 	var startKey kv.Key
-	if err := job.DecodeArgs(&startKey); err != nil {
+	var physicalTableIDs []int64
+	if err := job.DecodeArgs(&startKey, &physicalTableIDs); err != nil {
 		job.State = model.JobCancelled
 		return errors.Trace(err)
 	}
+	if len(physicalTableIDs) == 0 {
+		physicalTableIDs = []int64{job.TableID}
+	}
 
 	limit := reorgTableDeleteLimit
-	delCount, err := d.dropTableData(startKey, job, limit)
+	delCount, err := d.dropTableData(physicalTableIDs[0], startKey, job, limit)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	// Finish this background job.
+	job.Args = append(job.Args, physicalTableIDs)
 	if delCount < limit {
-		job.SchemaState = model.StateNone
-		job.State = model.JobDone
+		physicalTableIDs = physicalTableIDs[1:]
+		// Finish this background job.
+		if len(physicalTableIDs) == 0 {
+			job.SchemaState = model.StateNone
+			job.State = model.JobDone
+			return nil
+		}
+		job.Args = []interface{}{tablecodec.EncodeTablePrefix(physicalTableIDs[0]), physicalTableIDs}
 	}
 	return nil
 }
@@ -173,9 +242,9 @@ func getTableInfo(t *meta.Meta, job *model.Job, schemaID int64) (*model.TableInf
 	return tblInfo, nil
 }
 
-// dropTableData deletes data in a limited number. If limit < 0, deletes all data.
-func (d *ddl) dropTableData(startKey kv.Key, job *model.Job, limit int) (int, error) {
-	prefix := tablecodec.EncodeTablePrefix(job.TableID)
+// dropTableData deletes data of the physical table in a limited number. If limit < 0, deletes all data.
+func (d *ddl) dropTableData(physicalTableID int64, startKey kv.Key, job *model.Job, limit int) (int, error) {
+	prefix := tablecodec.EncodeTablePrefix(physicalTableID)
 	delCount, nextStartKey, err := d.delKeysWithStartKey(prefix, startKey, bgJobFlag, job, limit)
 	job.Args = []interface{}{nextStartKey}
 	return delCount, errors.Trace(err)
@@ -203,7 +272,15 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 		job.State = model.JobCancelled
 		return ver, errors.Trace(err)
 	}
+	oldPhysicalTableIDs := getPhysicalTableIDs(tblInfo)
 	tblInfo.ID = newTableID
+	if tblInfo.Partition != nil {
+		err = resetPartitionIDs(t, tblInfo.Partition)
+		if err != nil {
+			job.State = model.JobCancelled
+			return ver, errors.Trace(err)
+		}
+	}
 	err = t.CreateTable(schemaID, tblInfo)
 	if err != nil {
 		job.State = model.JobCancelled
@@ -217,7 +294,7 @@ func (d *ddl) onTruncateTable(t *meta.Meta, job *model.Job) (ver int64, _ error)
 	job.State = model.JobDone
 	job.BinlogInfo.AddTableInfo(ver, tblInfo)
 	startKey := tablecodec.EncodeTablePrefix(tableID)
-	job.Args = []interface{}{startKey}
+	job.Args = []interface{}{startKey, oldPhysicalTableIDs}
 	return ver, nil
 }
 
@@ -21,6 +21,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/execdetails"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -61,6 +62,9 @@ type selectResult struct {
 	aggregate bool
 	resp      kv.Response
 
+	// execDetails records the coprocessor tasks of the statement, it may be nil.
+	execDetails *execdetails.ExecDetails
+
 	results chan resultWithErr
 	closed  chan struct{}
 }
@@ -92,6 +96,9 @@ func (r *selectResult) fetch(ctx goctx.Context) {
 		}
 		pr := &partialResult{}
 		pr.unmarshal(resultSubset)
+		if r.execDetails != nil {
+			r.execDetails.AddCopTask(pr.rowCount())
+		}
 
 		select {
 		case r.results <- resultWithErr{result: pr}:
@@ -139,6 +146,15 @@ func (pr *partialResult) unmarshal(resultSubset []byte) error {
 	return nil
 }
 
+// rowCount returns the number of the rows in the partial result.
+func (pr *partialResult) rowCount() int64 {
+	rows := int64(len(pr.resp.Rows))
+	for _, chunk := range pr.resp.Chunks {
+		rows += int64(len(chunk.RowsMeta))
+	}
+	return rows
+}
+
 var zeroLenData = make([]byte, 0)
 
 // Next returns the next row of the sub result.
@@ -184,7 +200,8 @@ func (pr *partialResult) Close() error {
 // concurrency: The max concurrency for underlying coprocessor request.
 // keepOrder: If the result should returned in key order. For example if we need keep data in order by
 //            scan index, we should set keepOrder to true.
-func Select(client kv.Client, ctx goctx.Context, req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool, isolationLevel kv.IsoLevel) (SelectResult, error) {
+// execDetails: The coprocessor tasks are recorded in it if it isn't nil.
+func Select(client kv.Client, ctx goctx.Context, req *tipb.SelectRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool, isolationLevel kv.IsoLevel, execDetails *execdetails.ExecDetails) (SelectResult, error) {
 	var err error
 	defer func() {
 		// Add metrics
@@ -208,9 +225,10 @@ func Select(client kv.Client, ctx goctx.Context, req *tipb.SelectRequest, keyRan
 		return nil, err
 	}
 	result := &selectResult{
-		resp:    resp,
-		results: make(chan resultWithErr, 5),
-		closed:  make(chan struct{}),
+		resp:        resp,
+		execDetails: execDetails,
+		results:     make(chan resultWithErr, 5),
+		closed:      make(chan struct{}),
 	}
 	// If Aggregates is not nil, we should set result fields latter.
 	if len(req.Aggregates) == 0 && len(req.GroupBy) == 0 {
@@ -229,7 +247,8 @@ func Select(client kv.Client, ctx goctx.Context, req *tipb.SelectRequest, keyRan
 // concurrency: The max concurrency for underlying coprocessor request.
 // keepOrder: If the result should returned in key order. For example if we need keep data in order by
 //            scan index, we should set keepOrder to true.
-func SelectDAG(client kv.Client, ctx goctx.Context, dag *tipb.DAGRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool, desc bool, isolationLevel kv.IsoLevel) (SelectResult, error) {
+// execDetails: The coprocessor tasks are recorded in it if it isn't nil.
+func SelectDAG(client kv.Client, ctx goctx.Context, dag *tipb.DAGRequest, keyRanges []kv.KeyRange, concurrency int, keepOrder bool, desc bool, isolationLevel kv.IsoLevel, execDetails *execdetails.ExecDetails) (SelectResult, error) {
 	var err error
 	defer func() {
 		// Add metrics.
@@ -259,10 +278,11 @@ func SelectDAG(client kv.Client, ctx goctx.Context, dag *tipb.DAGRequest, keyRan
 		return nil, errors.Trace(err)
 	}
 	result := &selectResult{
-		label:   "dag",
-		resp:    resp,
-		results: make(chan resultWithErr, concurrency),
-		closed:  make(chan struct{}),
+		label:       "dag",
+		resp:        resp,
+		execDetails: execDetails,
+		results:     make(chan resultWithErr, concurrency),
+		closed:      make(chan struct{}),
 	}
 	return result, nil
 }
@@ -339,6 +359,10 @@ func ColumnsToProto(columns []*model.ColumnInfo, pkIsHandle bool) []*tipb.Column
 		} else {
 			col.PkHandle = false
 		}
+		if c.State != model.StatePublic {
+			// The non-public column may be missing in the rows, it's read as null.
+			col.Flag &= ^int32(mysql.NotNullFlag)
+		}
 		cols = append(cols, col)
 	}
 	return cols
@@ -21,6 +21,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/ngaut/pools"
+	"github.com/pingcap/tidb/bindinfo"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
@@ -32,6 +33,9 @@ import (
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/statistics"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/deadlock"
+	"github.com/pingcap/tidb/util/deadlock/waitfor"
+	"github.com/pingcap/tidb/util/userlock/userlocks"
 	goctx "golang.org/x/net/context"
 )
 
@@ -41,6 +45,7 @@ type Domain struct {
 	store           kv.Storage
 	infoHandle      *infoschema.Handle
 	privHandle      *privileges.Handle
+	bindHandle      *bindinfo.Handle
 	statsHandle     *statistics.Handle
 	statsLease      time.Duration
 	ddl             ddl.DDL
@@ -49,6 +54,8 @@ type Domain struct {
 	sysSessionPool  *pools.ResourcePool
 	exit            chan struct{}
 	etcdClient      *clientv3.Client
+	userLockMgr     *userlocks.LockManager
+	deadlockSvc     *waitfor.GraphService
 
 	MockReloadFailed MockFailure // It mocks reload failed.
 }
@@ -340,6 +347,10 @@ func (do *Domain) Close() {
 		do.etcdClient.Close()
 	}
 	do.sysSessionPool.Close()
+	do.userLockMgr.Close()
+	if do.deadlockSvc != nil {
+		do.deadlockSvc.Close()
+	}
 }
 
 type ddlCallback struct {
@@ -385,6 +396,10 @@ type etcdBackend interface {
 	EtcdAddrs() []string
 }
 
+type deadlockBackend interface {
+	SetDeadlockService(svc deadlock.Service)
+}
+
 // NewDomain creates a new domain. Should not create multiple domains for the same store.
 func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duration, factory pools.Factory) (d *Domain, err error) {
 	capacity := 200                // capacity of the sysSessionPool size
@@ -430,6 +445,12 @@ func NewDomain(store kv.Storage, ddlLease time.Duration, statsLease time.Duratio
 		// Local store needs to get the change information for every DDL state in each session.
 		go d.loadSchemaInLoop(ddlLease)
 	}
+	d.userLockMgr = userlocks.NewLockManager(d.store)
+	if dbd, ok := store.(deadlockBackend); ok {
+		// The background owner detects the deadlocks among the transactions of all the TiDB servers.
+		d.deadlockSvc = waitfor.NewGraphService(d.store, d.ddl.OwnerManager().IsBgOwner)
+		dbd.SetDeadlockService(d.deadlockSvc)
+	}
 
 	return d, nil
 }
@@ -487,11 +508,51 @@ func (do *Domain) LoadPrivilegeLoop(ctx context.Context) error {
 	return nil
 }
 
+// bindInfoLease is the interval to load the bindings updated by the other TiDB servers.
+var bindInfoLease = 3 * time.Second
+
+// LoadBindInfoLoop creates a goroutine loads the updated bindings in a loop, it should be called only once
+// in BootstrapSession.
+func (do *Domain) LoadBindInfoLoop(ctx context.Context) error {
+	do.bindHandle = bindinfo.NewHandle()
+	err := do.bindHandle.Update(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	go func() {
+		ticker := time.NewTicker(bindInfoLease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-do.exit:
+				return
+			case <-ticker.C:
+			}
+			err := do.bindHandle.Update(ctx)
+			if err != nil {
+				log.Error("[domain] load bindings fail:", errors.ErrorStack(err))
+			}
+		}
+	}()
+	return nil
+}
+
+// BindHandle returns the global bindings.
+func (do *Domain) BindHandle() *bindinfo.Handle {
+	return do.bindHandle
+}
+
 // PrivilegeHandle returns the MySQLPrivilege.
 func (do *Domain) PrivilegeHandle() *privileges.Handle {
 	return do.privHandle
 }
 
+// UserLockManager returns the manager of the user level locks.
+func (do *Domain) UserLockManager() *userlocks.LockManager {
+	return do.userLockMgr
+}
+
 // StatsHandle returns the statistic handle.
 func (do *Domain) StatsHandle() *statistics.Handle {
 	return do.statsHandle
@@ -545,6 +606,35 @@ func (do *Domain) UpdateTableStatsLoop(ctx context.Context) error {
 	return nil
 }
 
+// AutoAnalyzeLoop creates a goroutine analyzes the tables automatically in a loop. It runs apart from the stats
+// loop with its own session, so a slow ANALYZE doesn't delay the loading of the stats and the DDL events. It
+// should be called only once in BootstrapSession, after UpdateTableStatsLoop.
+func (do *Domain) AutoAnalyzeLoop(ctx context.Context) {
+	lease := do.statsLease
+	if lease <= 0 {
+		return
+	}
+	go func(do *Domain) {
+		analyzeTicker := time.NewTicker(lease * 5)
+		defer analyzeTicker.Stop()
+
+		for {
+			select {
+			case <-analyzeTicker.C:
+				// The background owner acts as the stats owner, so only one server analyzes the tables automatically.
+				if do.ddl.OwnerManager().IsBgOwner() {
+					err := do.statsHandle.HandleAutoAnalyze(ctx, do.InfoSchema())
+					if err != nil {
+						log.Error(errors.ErrorStack(err))
+					}
+				}
+			case <-do.exit:
+				return
+			}
+		}
+	}(do)
+}
+
 const privilegeKey = "/tidb/privilege"
 
 // NotifyUpdatePrivilege updates privilege key in etcd, TiDB client that watches
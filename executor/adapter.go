@@ -23,14 +23,27 @@ import (
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/perfschema"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/slowlog"
+	goctx "golang.org/x/net/context"
 )
 
 type processinfoSetter interface {
 	SetProcessInfo(string)
 }
 
+// stmtGoCtxSetter is implemented by the session, the statement which has a max execution time sets a
+// goctx with the deadline to it, so the executors and coprocessor requests using GoCtx stop at the deadline.
+type stmtGoCtxSetter interface {
+	SetStmtGoCtx(goctx.Context)
+}
+
 // recordSet wraps an executor, implements ast.RecordSet interface
 type recordSet struct {
 	fields      []*ast.ResultField
@@ -60,7 +73,12 @@ func (a *recordSet) Fields() ([]*ast.ResultField, error) {
 
 func (a *recordSet) Next() (*ast.Row, error) {
 	row, err := a.executor.Next()
+	if (err != nil || row == nil) && a.stmt != nil && a.stmt.timedOut() {
+		// The canceled executors may return no more rows without an error.
+		err = ErrQueryTimeout
+	}
 	if err != nil {
+		a.err = err
 		return nil, errors.Trace(err)
 	}
 	if row == nil {
@@ -78,7 +96,7 @@ func (a *recordSet) Next() (*ast.Row, error) {
 
 func (a *recordSet) Close() error {
 	err := a.executor.Close()
-	a.stmt.logSlowQuery()
+	a.stmt.finish(a.err)
 	if a.processinfo != nil {
 		a.processinfo.SetProcessInfo("")
 	}
@@ -94,6 +112,13 @@ type statement struct {
 	plan           plan.Plan
 	startTime      time.Time
 	isPreparedStmt bool
+	// readOnly is true if the statement is a read-only SELECT, the max execution time only applies to it.
+	readOnly bool
+
+	// stmtGoCtx is set to the session while the statement is running, it's canceled by the watchdog when
+	// the statement exceeds its max execution time.
+	stmtGoCtx  goctx.Context
+	cancelStmt goctx.CancelFunc
 }
 
 func (a *statement) OriginText() string {
@@ -109,6 +134,40 @@ func (a *statement) IsPrepared() bool {
 // like the INSERT, UPDATE statements, it executes in this function, if the Executor returns
 // result, execution is done after this function returns, in the returned ast.RecordSet Next method.
 func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
+	rs, err := a.execWithRetry(ctx)
+	if err != nil && a.timedOut() {
+		err = ErrQueryTimeout
+	}
+	if rs == nil {
+		// The statement which returns result finishes when the ast.RecordSet is closed.
+		a.finish(err)
+	}
+	return rs, errors.Trace(err)
+}
+
+// pessimisticMaxRetry is the max times a pessimistic locking statement retries for the write conflicts.
+var pessimisticMaxRetry = 10
+
+func (a *statement) execWithRetry(ctx context.Context) (ast.RecordSet, error) {
+	for retry := 0; ; retry++ {
+		rs, err := a.exec(ctx)
+		txnCtx := ctx.GetSessionVars().TxnCtx
+		if txnCtx.ForUpdateTS == 0 {
+			return rs, errors.Trace(err)
+		}
+		// The pessimistic locking statement locks the rows before writing them, so it can be retried with
+		// a new forUpdateTS if the locking finds a row is written after the current forUpdateTS.
+		txnCtx.ForUpdateTS = 0
+		ctx.Txn().DelOption(kv.ForUpdateTS)
+		ctx.Txn().DelOption(kv.LockCtx)
+		if !terror.ErrorEqual(err, kv.ErrWriteConflict) || retry >= pessimisticMaxRetry {
+			return rs, errors.Trace(err)
+		}
+		log.Infof("[%d] pessimistic statement write conflict, retry %d: %s", ctx.GetSessionVars().ConnectionID, retry+1, a.text)
+	}
+}
+
+func (a *statement) exec(ctx context.Context) (ast.RecordSet, error) {
 	a.startTime = time.Now()
 	a.ctx = ctx
 	if _, ok := a.plan.(*plan.Execute); !ok {
@@ -142,9 +201,11 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 		a.text = executorExec.Stmt.Text()
 		a.isPreparedStmt = true
 		a.plan = executorExec.Plan
+		a.readOnly = isReadOnlySelect(executorExec.Stmt)
 		e = executorExec.StmtExec
 	}
 
+	a.startWatchdog(ctx)
 	err := e.Open()
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -174,7 +235,6 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 				pi.SetProcessInfo("")
 			}
 			e.Close()
-			a.logSlowQuery()
 		}()
 		for {
 			row, err := e.Next()
@@ -198,23 +258,152 @@ func (a *statement) Exec(ctx context.Context) (ast.RecordSet, error) {
 	}, nil
 }
 
-const (
-	queryLogMaxLen = 2048
-	slowThreshold  = 300 * time.Millisecond
-)
+// isReadOnlySelect checks if node is a SELECT statement which doesn't lock the rows.
+func isReadOnlySelect(node ast.Node) bool {
+	switch x := node.(type) {
+	case *ast.SelectStmt:
+		return x.LockTp == ast.SelectLockNone
+	case *ast.UnionStmt:
+		return true
+	}
+	return false
+}
+
+// startWatchdog starts the watchdog of the max execution time, which is set by the MAX_EXECUTION_TIME hint or
+// the max_execution_time variable. The goctx of the statement is canceled when the time is exceeded.
+func (a *statement) startWatchdog(ctx context.Context) {
+	sessVars := ctx.GetSessionVars()
+	setter, ok := ctx.(stmtGoCtxSetter)
+	if !ok || !a.readOnly || sessVars.InRestrictedSQL || a.cancelStmt != nil {
+		return
+	}
+	maxExecutionTime := sessVars.StmtCtx.MaxExecutionTime
+	if maxExecutionTime == 0 {
+		maxExecutionTime = sessVars.MaxExecutionTime
+	}
+	if maxExecutionTime == 0 {
+		return
+	}
+	a.stmtGoCtx, a.cancelStmt = goctx.WithTimeout(ctx.GoCtx(), time.Duration(maxExecutionTime)*time.Millisecond)
+	setter.SetStmtGoCtx(a.stmtGoCtx)
+}
+
+// stopWatchdog stops the watchdog when the statement finishes.
+func (a *statement) stopWatchdog() {
+	if a.cancelStmt == nil {
+		return
+	}
+	a.cancelStmt()
+	a.cancelStmt = nil
+	a.ctx.(stmtGoCtxSetter).SetStmtGoCtx(nil)
+}
 
-func (a *statement) logSlowQuery() {
-	costTime := time.Since(a.startTime)
+// timedOut checks if the statement is canceled by the watchdog.
+func (a *statement) timedOut() bool {
+	return a.stmtGoCtx != nil && a.stmtGoCtx.Err() == goctx.DeadlineExceeded
+}
+
+const queryLogMaxLen = 2048
+
+// finish is called when the statement finishes with err, it logs the slow query and adds the statement to
+// the statement summary.
+func (a *statement) finish(err error) {
+	a.stopWatchdog()
+	sessVars := a.ctx.GetSessionVars()
+	costTime := time.Since(a.startTime) + sessVars.DurationParse + sessVars.DurationCompile
+	a.logSlowQuery(costTime)
+	a.summarizeStmt(costTime, err)
+}
+
+// summarizeStmt adds the statement to events_statements_summary_by_digest of performance_schema.
+func (a *statement) summarizeStmt(costTime time.Duration, err error) {
+	sessVars := a.ctx.GetSessionVars()
+	do := sessionctx.GetDomain(a.ctx)
+	if sessVars.InRestrictedSQL || do == nil {
+		return
+	}
+	stmtCtx := sessVars.StmtCtx
+	do.PerfSchema().SummarizeStatement(&perfschema.StmtExecInfo{
+		SchemaName:   sessVars.CurrentDB,
+		OriginalSQL:  a.text,
+		Latency:      costTime,
+		Err:          err,
+		Warnings:     uint64(stmtCtx.WarningCount()),
+		RowsAffected: stmtCtx.AffectedRows(),
+		RowsSent:     stmtCtx.FoundRows(),
+		RowsExamined: uint64(stmtCtx.ExecDetails.ProcessedKeys()),
+		EndTime:      time.Now(),
+	})
+}
+
+// logSlowQuery logs the query, it's written to the slow query log if its running time, including the time
+// of parsing and compiling, exceeds tidb_slow_log_threshold.
+func (a *statement) logSlowQuery(costTime time.Duration) {
+	sessVars := a.ctx.GetSessionVars()
 	sql := a.text
 	if len(sql) > queryLogMaxLen {
 		sql = sql[:queryLogMaxLen] + fmt.Sprintf("(len:%d)", len(sql))
 	}
-	connID := a.ctx.GetSessionVars().ConnectionID
-	if costTime < slowThreshold {
+	connID := sessVars.ConnectionID
+	if costTime < time.Duration(sessVars.SlowLogThreshold)*time.Millisecond {
 		log.Debugf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
-	} else {
-		log.Warnf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+		return
+	}
+	log.Warnf("[%d][TIME_QUERY] %v %s", connID, costTime, sql)
+	if slowlog.FilePath() == "" {
+		return
+	}
+	execDetails := &sessVars.StmtCtx.ExecDetails
+	err := slowlog.Write(&slowlog.Entry{
+		Time:        time.Now(),
+		TxnStartTS:  sessVars.TxnCtx.StartTS,
+		User:        sessVars.User,
+		ConnID:      connID,
+		QueryTime:   costTime,
+		ParseTime:   sessVars.DurationParse,
+		CompileTime: sessVars.DurationCompile,
+		CopTasks:    execDetails.CopTasks(),
+		ProcessKeys: execDetails.ProcessedKeys(),
+		DB:          sessVars.CurrentDB,
+		IndexNames:  collectIndexNames(a.plan, nil),
+		Digest:      parser.DigestHash(a.text),
+		Query:       sql,
+	})
+	if err != nil {
+		log.Warnf("[%d] write slow query log failed %v", connID, err)
+	}
+}
+
+// collectIndexNames returns the indices used by the plan in the format of "table:index".
+func collectIndexNames(p plan.Plan, names []string) []string {
+	if p == nil {
+		return names
+	}
+	var children []plan.Plan
+	switch x := p.(type) {
+	case *plan.PhysicalIndexScan:
+		name := x.Table.Name.O + ":" + x.Index.Name.O
+		for _, n := range names {
+			if n == name {
+				return names
+			}
+		}
+		return append(names, name)
+	case *plan.PhysicalIndexReader:
+		for _, child := range x.IndexPlans {
+			children = append(children, child)
+		}
+	case *plan.PhysicalIndexLookUpReader:
+		for _, child := range x.IndexPlans {
+			children = append(children, child)
+		}
+	default:
+		children = p.Children()
+	}
+	for _, child := range children {
+		names = collectIndexNames(child, names)
 	}
+	return names
 }
 
 // IsPointGetWithPKOrUniqueKeyByAutoCommit returns true when meets following conditions:
@@ -0,0 +1,343 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"io"
+	"math"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/inspectkv"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+var (
+	_ Executor = &CheckIndexExec{}
+	_ Executor = &RecoverIndexExec{}
+	_ Executor = &CleanupIndexExec{}
+)
+
+// adminBatchSize is the number of rows or index entries handled in a transaction
+// when recovering or cleaning up an index.
+const adminBatchSize = 1024
+
+// physicalIndex is an index of a physical table. The admin index statements handle every partition
+// of a partitioned table, because the rows and index entries are stored under the partition IDs.
+type physicalIndex struct {
+	table table.Table
+	index table.Index
+}
+
+// CheckIndexExec represents a check index executor.
+// It is built from the "admin check index" statement, and it returns the records
+// which are inconsistent with the index in the handle ranges. For every physical index,
+// the index entries are scanned once to find the entries whose records are missing or mismatched,
+// only the entries whose handles are in the ranges are checked, then the records in every range
+// are scanned and the index is probed for them. The results are returned batch by batch.
+type CheckIndexExec struct {
+	baseExecutor
+
+	idxes        []physicalIndex
+	handleRanges []ast.HandleRange
+
+	txn          kv.Transaction
+	ownTxn       bool
+	idxPos       int
+	idxIter      table.IndexIterator
+	indexScanned bool
+	rangePos     int
+	nextHandle   int64
+	mismatches   []*inspectkv.MismatchedRecord
+	cursor       int
+}
+
+// Open implements the Executor Open interface.
+func (e *CheckIndexExec) Open() error {
+	if len(e.handleRanges) == 0 {
+		e.handleRanges = []ast.HandleRange{{Begin: math.MinInt64, End: math.MaxInt64}}
+	}
+	e.idxPos, e.rangePos, e.indexScanned = 0, 0, false
+	e.nextHandle = e.handleRanges[0].Begin
+	e.mismatches, e.cursor = nil, 0
+	e.txn, e.ownTxn = e.ctx.Txn(), false
+	if !e.ctx.GetSessionVars().InTxn() {
+		// The autocommit transaction is committed once the statement is executed,
+		// so the mismatches are fetched by a transaction with the same start TS.
+		txn, err := sessionctx.GetDomain(e.ctx).Store().BeginWithStartTS(e.txn.StartTS())
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.txn, e.ownTxn = txn, true
+	}
+	return nil
+}
+
+// Close implements the Executor Close interface.
+func (e *CheckIndexExec) Close() error {
+	if e.idxIter != nil {
+		e.idxIter.Close()
+		e.idxIter = nil
+	}
+	if e.ownTxn {
+		e.ownTxn = false
+		return errors.Trace(e.txn.Rollback())
+	}
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CheckIndexExec) Next() (*Row, error) {
+	for e.cursor >= len(e.mismatches) {
+		if e.idxPos >= len(e.idxes) {
+			return nil, nil
+		}
+		e.mismatches, e.cursor = e.mismatches[:0], 0
+		if err := e.fetchMismatches(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	m := e.mismatches[e.cursor]
+	row := &Row{Data: make([]types.Datum, 3)}
+	if m.Index != nil {
+		row.Data[0].SetInt64(m.Index.Handle)
+		vals, err := types.DatumsToString(m.Index.Values)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row.Data[1].SetString(vals)
+	}
+	if m.Record != nil {
+		row.Data[0].SetInt64(m.Record.Handle)
+		vals, err := types.DatumsToString(m.Record.Values)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		row.Data[2].SetString(vals)
+	}
+	e.cursor++
+
+	return row, nil
+}
+
+// fetchMismatches checks a batch of the index entries or the records of the current physical index.
+func (e *CheckIndexExec) fetchMismatches() error {
+	txn := e.txn
+	pi := e.idxes[e.idxPos]
+	if !e.indexScanned {
+		if e.idxIter == nil {
+			it, err := pi.index.SeekFirst(txn)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			e.idxIter = it
+		}
+		for i := 0; i < adminBatchSize; i++ {
+			vals, h, err := e.idxIter.Next()
+			if terror.ErrorEqual(err, io.EOF) {
+				e.idxIter.Close()
+				e.idxIter = nil
+				e.indexScanned = true
+				return nil
+			} else if err != nil {
+				return errors.Trace(err)
+			}
+			if !e.inRanges(h) {
+				continue
+			}
+			m, err := inspectkv.CheckIndexEntry(txn, pi.table, pi.index, vals, h)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if m != nil {
+				e.mismatches = append(e.mismatches, m)
+			}
+		}
+		return nil
+	}
+
+	r := e.handleRanges[e.rangePos]
+	mismatches, lastHandle, scanCnt, err := inspectkv.CheckIndexRange(txn, pi.table, pi.index, e.nextHandle, r.End, adminBatchSize)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.mismatches = append(e.mismatches, mismatches...)
+	if scanCnt == adminBatchSize && lastHandle < r.End {
+		e.nextHandle = lastHandle + 1
+		return nil
+	}
+	e.rangePos++
+	if e.rangePos >= len(e.handleRanges) {
+		e.idxPos++
+		e.rangePos, e.indexScanned = 0, false
+	}
+	e.nextHandle = e.handleRanges[e.rangePos].Begin
+	return nil
+}
+
+func (e *CheckIndexExec) inRanges(h int64) bool {
+	for _, r := range e.handleRanges {
+		if h >= r.Begin && h <= r.End {
+			return true
+		}
+	}
+	return false
+}
+
+// RecoverIndexExec represents a recover index executor.
+// It is built from the "admin recover index" statement, and it adds the missing index
+// entries from the records. The records are scanned in batches and every batch is
+// handled in its own transaction, so it can run online.
+type RecoverIndexExec struct {
+	baseExecutor
+
+	idxes    []physicalIndex
+	addedCnt int64
+	scanCnt  int64
+	done     bool
+}
+
+// Open implements the Executor Open interface.
+func (e *RecoverIndexExec) Open() error {
+	for _, pi := range e.idxes {
+		if err := e.recover(pi); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (e *RecoverIndexExec) recover(pi physicalIndex) error {
+	store := sessionctx.GetDomain(e.ctx).Store()
+	startHandle := int64(math.MinInt64)
+	for {
+		var lastHandle, scanCnt, addedCnt int64
+		err := kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+			var err1 error
+			lastHandle, scanCnt, addedCnt, err1 = inspectkv.RecoverIndex(txn, pi.table, pi.index, startHandle, adminBatchSize)
+			return errors.Trace(err1)
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+		e.scanCnt += scanCnt
+		e.addedCnt += addedCnt
+		if scanCnt < adminBatchSize || lastHandle == math.MaxInt64 {
+			return nil
+		}
+		startHandle = lastHandle + 1
+	}
+}
+
+// Next implements the Executor Next interface.
+func (e *RecoverIndexExec) Next() (*Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return &Row{Data: types.MakeDatums(e.addedCnt, e.scanCnt)}, nil
+}
+
+// CleanupIndexExec represents a cleanup index executor.
+// It is built from the "admin cleanup index" statement, and it deletes the index entries
+// whose records are missing or don't match the entries. The index is scanned on a snapshot,
+// and the entries are checked again and deleted in batches in their own transactions.
+type CleanupIndexExec struct {
+	baseExecutor
+
+	idxes      []physicalIndex
+	removedCnt int64
+	scanCnt    int64
+	done       bool
+}
+
+// Open implements the Executor Open interface.
+func (e *CleanupIndexExec) Open() error {
+	store := sessionctx.GetDomain(e.ctx).Store()
+	ver, err := store.CurrentVersion()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	snapshot, err := store.GetSnapshot(ver)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, pi := range e.idxes {
+		if err = e.cleanupIndex(store, snapshot, pi); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (e *CleanupIndexExec) cleanupIndex(store kv.Storage, snapshot kv.Snapshot, pi physicalIndex) error {
+	it, err := pi.index.SeekFirst(snapshot)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer it.Close()
+
+	idxRows := make([]*inspectkv.RecordData, 0, adminBatchSize)
+	for {
+		vals, h, err := it.Next()
+		if err != nil && !terror.ErrorEqual(err, io.EOF) {
+			return errors.Trace(err)
+		}
+		if err == nil {
+			idxRows = append(idxRows, &inspectkv.RecordData{Handle: h, Values: vals})
+			e.scanCnt++
+			if len(idxRows) < adminBatchSize {
+				continue
+			}
+		}
+		if err = e.cleanup(store, pi, idxRows); err != nil {
+			return errors.Trace(err)
+		}
+		if len(idxRows) < adminBatchSize {
+			return nil
+		}
+		idxRows = idxRows[:0]
+	}
+}
+
+func (e *CleanupIndexExec) cleanup(store kv.Storage, pi physicalIndex, idxRows []*inspectkv.RecordData) error {
+	if len(idxRows) == 0 {
+		return nil
+	}
+	var removedCnt int64
+	err := kv.RunInNewTxn(store, true, func(txn kv.Transaction) error {
+		var err1 error
+		removedCnt, err1 = inspectkv.CleanupIndex(txn, pi.table, pi.index, idxRows)
+		return errors.Trace(err1)
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+	e.removedCnt += removedCnt
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CleanupIndexExec) Next() (*Row, error) {
+	if e.done {
+		return nil, nil
+	}
+	e.done = true
+	return &Row{Data: types.MakeDatums(e.removedCnt, e.scanCnt)}, nil
+}
@@ -39,9 +39,11 @@ type AnalyzeExec struct {
 }
 
 const (
-	maxSampleCount     = 10000
-	maxSketchSize      = 1000
-	defaultBucketCount = 256
+	maxSampleCount       = 10000
+	maxSketchSize        = 1000
+	defaultBucketCount   = 256
+	defaultCMSketchDepth = 5
+	defaultCMSketchWidth = 2048
 )
 
 // Schema implements the Executor Schema interface.
@@ -95,8 +97,8 @@ func (e *AnalyzeExec) Next() (*Row, error) {
 		}
 	}
 	for _, result := range results {
-		for _, hg := range result.hist {
-			err = hg.SaveToStorage(e.ctx, result.tableID, result.count, result.isIndex)
+		for i, hg := range result.hist {
+			err = hg.SaveToStorage(e.ctx, result.tableID, result.count, result.isIndex, result.cms[i])
 			if err != nil {
 				return nil, errors.Trace(err)
 			}
@@ -145,6 +147,7 @@ type analyzeTask struct {
 type analyzeResult struct {
 	tableID int64
 	hist    []*statistics.Histogram
+	cms     []*statistics.CMSketch
 	count   int64
 	isIndex int
 	err     error
@@ -170,12 +173,14 @@ func (e *AnalyzeExec) analyzeColumns(task *analyzeTask) analyzeResult {
 	if task.PKInfo != nil {
 		result.count = pkBuilder.Count
 		result.hist = []*statistics.Histogram{pkBuilder.Hist}
+		result.cms = []*statistics.CMSketch{nil}
 	} else {
 		result.count = collectors[0].Count + collectors[0].NullCount
 	}
 	for i, col := range task.Columns {
 		hg, err := statistics.BuildColumn(e.ctx, defaultBucketCount, col.ID, collectors[i].Sketch.NDV(), collectors[i].Count, collectors[i].NullCount, collectors[i].samples)
 		result.hist = append(result.hist, hg)
+		result.cms = append(result.cms, collectors[i].CMSketch)
 		if err != nil && result.err == nil {
 			result.err = err
 		}
@@ -184,16 +189,18 @@ func (e *AnalyzeExec) analyzeColumns(task *analyzeTask) analyzeResult {
 }
 
 func (e *AnalyzeExec) analyzeIndex(task *analyzeTask) analyzeResult {
-	count, hg, err := statistics.BuildIndex(e.ctx, defaultBucketCount, task.indexInfo.ID, &recordSet{executor: task.src})
-	return analyzeResult{tableID: task.tableInfo.ID, hist: []*statistics.Histogram{hg}, count: count, isIndex: 1, err: err}
+	count, hg, cms, err := statistics.BuildIndex(e.ctx, defaultBucketCount, task.indexInfo.ID, &recordSet{executor: task.src}, defaultCMSketchDepth, defaultCMSketchWidth)
+	return analyzeResult{tableID: task.tableInfo.ID, hist: []*statistics.Histogram{hg}, cms: []*statistics.CMSketch{cms}, count: count, isIndex: 1, err: err}
 }
 
 // SampleCollector will collect samples and calculate the count and ndv of an attribute.
+// It also builds the CM sketch of the attribute from all the non-null values.
 type SampleCollector struct {
 	samples   []types.Datum
 	NullCount int64
 	Count     int64
 	Sketch    *statistics.FMSketch
+	CMSketch  *statistics.CMSketch
 }
 
 func (c *SampleCollector) collect(d types.Datum) error {
@@ -211,7 +218,11 @@ func (c *SampleCollector) collect(d types.Datum) error {
 			c.samples[idx] = d
 		}
 	}
-	return errors.Trace(c.Sketch.InsertValue(d))
+	err := c.Sketch.InsertValue(d)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(c.CMSketch.InsertValue(d))
 }
 
 // CollectSamplesAndEstimateNDVs collects sample from the result set using Reservoir Sampling algorithm,
@@ -228,7 +239,8 @@ func CollectSamplesAndEstimateNDVs(ctx context.Context, e ast.RecordSet, numCols
 	collectors := make([]*SampleCollector, numCols)
 	for i := range collectors {
 		collectors[i] = &SampleCollector{
-			Sketch: statistics.NewFMSketch(maxSketchSize),
+			Sketch:   statistics.NewFMSketch(maxSketchSize),
+			CMSketch: statistics.NewCMSketch(defaultCMSketchDepth, defaultCMSketchWidth),
 		}
 	}
 	for {
@@ -0,0 +1,252 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/parser/opcode"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// NonTransactionalDMLExec splits a DELETE or UPDATE statement into batches by the shard column, and runs every
+// batch in its own transaction. It's built from the "BATCH ON col LIMIT n DELETE/UPDATE ..." statement.
+// The next batch reads the first n values of the shard column after the end of the last batch in order by the handle
+// or the shard index, so the batches walk the range of it instead of scanning the table from the beginning every time.
+// It returns a row for every batch, an error of a batch is reported in the row and doesn't stop the batches after it.
+type NonTransactionalDMLExec struct {
+	baseExecutor
+
+	stmt        *ast.NonTransactionalDMLStmt
+	tableName   model.CIStr
+	shardColumn *model.ColumnInfo
+	shardIndex  *model.IndexInfo
+
+	rows   []*Row
+	cursor int
+}
+
+// Open implements the Executor Open interface. All the batches run in Open, because Next is called after
+// the transaction of the statement is committed.
+func (e *NonTransactionalDMLExec) Open() error {
+	sessVars := e.ctx.GetSessionVars()
+	if sessVars.InTxn() {
+		return ErrNonTransactionalDMLInTxn
+	}
+	if sessVars.SnapshotTS != 0 {
+		return errors.New("can not execute write statement when 'tidb_snapshot' is set")
+	}
+	var (
+		// lower is the end of the last batch, the next batch starts after it.
+		lower *types.Datum
+		// nullsDone is true if the rows whose shard column is NULL have been processed.
+		nullsDone bool
+	)
+	for batch := 1; ; batch++ {
+		values, err := e.nextShardValues(lower, nullsDone)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if len(values) == 0 {
+			return nil
+		}
+		start, end := values[0], values[len(values)-1]
+		affected, err := e.runBatch(start, end)
+		errMsg := types.Datum{}
+		if err != nil {
+			log.Warnf("[%d] non-transactional DML batch %d [%v, %v] failed %v", sessVars.ConnectionID, batch,
+				start.GetValue(), end.GetValue(), err)
+			errMsg.SetString(err.Error())
+		} else {
+			log.Infof("[%d] non-transactional DML batch %d [%v, %v] done, affected rows %d", sessVars.ConnectionID,
+				batch, start.GetValue(), end.GetValue(), affected)
+		}
+		e.rows = append(e.rows, &Row{Data: []types.Datum{
+			types.NewIntDatum(int64(batch)),
+			shardValueDatum(start),
+			shardValueDatum(end),
+			types.NewUintDatum(affected),
+			errMsg,
+		}})
+		if end.IsNull() {
+			nullsDone = true
+		} else {
+			lower = &end
+		}
+	}
+}
+
+func shardValueDatum(d types.Datum) types.Datum {
+	if d.IsNull() {
+		return d
+	}
+	s, err := d.ToString()
+	if err != nil {
+		return types.Datum{}
+	}
+	return types.NewStringDatum(s)
+}
+
+// Next implements the Executor Next interface.
+func (e *NonTransactionalDMLExec) Next() (*Row, error) {
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+func (e *NonTransactionalDMLExec) shardColumnExpr() ast.ExprNode {
+	return &ast.ColumnNameExpr{Name: &ast.ColumnName{Table: e.tableName, Name: e.shardColumn.Name}}
+}
+
+func (e *NonTransactionalDMLExec) valueExpr(d types.Datum) ast.ExprNode {
+	v := &ast.ValueExpr{}
+	v.SetDatum(d)
+	v.SetType(&e.shardColumn.FieldType)
+	return v
+}
+
+func andWhere(where ast.ExprNode, cond ast.ExprNode) ast.ExprNode {
+	if where == nil {
+		return cond
+	}
+	return &ast.BinaryOperationExpr{Op: opcode.AndAnd, L: where, R: cond}
+}
+
+func (e *NonTransactionalDMLExec) dmlParts() (*ast.TableRefsClause, ast.ExprNode) {
+	switch x := e.stmt.DMLStmt.(type) {
+	case *ast.DeleteStmt:
+		return x.TableRefs, x.Where
+	case *ast.UpdateStmt:
+		return x.TableRefs, x.Where
+	}
+	return nil, nil
+}
+
+// shardTableRefs returns the table of the DML statement with the hint to read it by the shard index,
+// or by the handle if the shard index is nil.
+func (e *NonTransactionalDMLExec) shardTableRefs(refs *ast.TableRefsClause) *ast.TableRefsClause {
+	ts := refs.TableRefs.Left.(*ast.TableSource)
+	tn := *ts.Source.(*ast.TableName)
+	hint := &ast.IndexHint{HintType: ast.HintUse, HintScope: ast.HintForScan}
+	if e.shardIndex != nil {
+		hint.IndexNames = []model.CIStr{e.shardIndex.Name}
+	}
+	tn.IndexHints = []*ast.IndexHint{hint}
+	return &ast.TableRefsClause{TableRefs: &ast.Join{Left: &ast.TableSource{Source: &tn, AsName: ts.AsName}}}
+}
+
+// nextShardValues reads the shard column values of the next batch in order.
+func (e *NonTransactionalDMLExec) nextShardValues(lower *types.Datum, nullsDone bool) ([]types.Datum, error) {
+	refs, where := e.dmlParts()
+	if lower != nil {
+		where = andWhere(where, &ast.BinaryOperationExpr{Op: opcode.GT, L: e.shardColumnExpr(), R: e.valueExpr(*lower)})
+	} else if nullsDone {
+		where = andWhere(where, &ast.IsNullExpr{Expr: e.shardColumnExpr(), Not: true})
+	}
+	sel := &ast.SelectStmt{
+		SelectStmtOpts: &ast.SelectStmtOpts{},
+		Fields:         &ast.FieldList{Fields: []*ast.SelectField{{Expr: e.shardColumnExpr()}}},
+		From:           e.shardTableRefs(refs),
+		Where:          where,
+		OrderBy:        &ast.OrderByClause{Items: []*ast.ByItem{{Expr: e.shardColumnExpr()}}},
+		Limit:          &ast.Limit{Count: ast.NewValueExpr(e.stmt.Limit)},
+	}
+	stmt, err := (&Compiler{}).Compile(e.ctx, sel)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	rs, err := stmt.Exec(e.ctx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer rs.Close()
+	var values []types.Datum
+	for {
+		row, err := rs.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			return values, nil
+		}
+		values = append(values, row.Data[0])
+	}
+}
+
+// runBatch runs the DML statement on the rows whose shard column is in [start, end] in a new transaction,
+// and returns the number of the affected rows.
+func (e *NonTransactionalDMLExec) runBatch(start, end types.Datum) (uint64, error) {
+	var cond ast.ExprNode
+	switch {
+	case start.IsNull() && end.IsNull():
+		cond = &ast.IsNullExpr{Expr: e.shardColumnExpr()}
+	case start.IsNull():
+		cond = &ast.BinaryOperationExpr{
+			Op: opcode.OrOr,
+			L:  &ast.IsNullExpr{Expr: e.shardColumnExpr()},
+			R:  &ast.BinaryOperationExpr{Op: opcode.LE, L: e.shardColumnExpr(), R: e.valueExpr(end)},
+		}
+	default:
+		cond = &ast.BetweenExpr{Expr: e.shardColumnExpr(), Left: e.valueExpr(start), Right: e.valueExpr(end)}
+	}
+	var dml ast.StmtNode
+	switch x := e.stmt.DMLStmt.(type) {
+	case *ast.DeleteStmt:
+		del := *x
+		del.Where = andWhere(x.Where, cond)
+		dml = &del
+	case *ast.UpdateStmt:
+		upd := *x
+		upd.Where = andWhere(x.Where, cond)
+		dml = &upd
+	}
+
+	stmtCtx := e.ctx.GetSessionVars().StmtCtx
+	affected := stmtCtx.AffectedRows()
+	stmt, err := (&Compiler{}).Compile(e.ctx, dml)
+	if err == nil {
+		_, err = stmt.Exec(e.ctx)
+	}
+	if err != nil {
+		if err1 := e.ctx.Txn().Rollback(); err1 != nil {
+			log.Warnf("[%d] rollback non-transactional DML batch failed %v", e.ctx.GetSessionVars().ConnectionID, err1)
+		}
+		return 0, e.renewTxn(err)
+	}
+	affected = stmtCtx.AffectedRows() - affected
+	if err = e.ctx.RefreshTxnCtx(); err != nil {
+		return 0, e.renewTxn(err)
+	}
+	e.resetTxnCtx()
+	return affected, nil
+}
+
+// renewTxn starts a new transaction for the next batch after the batch fails with err.
+func (e *NonTransactionalDMLExec) renewTxn(err error) error {
+	e.resetTxnCtx()
+	if err1 := e.ctx.NewTxn(); err1 != nil {
+		log.Warnf("[%d] start transaction for non-transactional DML failed %v", e.ctx.GetSessionVars().ConnectionID, err1)
+	}
+	return errors.Trace(err)
+}
+
+// resetTxnCtx drops the rows buffered for the finished batch, they are not read by the next batches.
+func (e *NonTransactionalDMLExec) resetTxnCtx() {
+	e.ctx.GetSessionVars().TxnCtx.DirtyDB = nil
+}
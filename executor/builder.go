@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/types"
 	"github.com/pingcap/tipb/go-tipb"
 	goctx "golang.org/x/net/context"
@@ -42,6 +43,12 @@ type executorBuilder struct {
 	is  infoschema.InfoSchema
 	// err is set when there is error happened during Executor building process.
 	err error
+	// runtimeStats is not nil when building executors for EXPLAIN ANALYZE, every built executor
+	// is wrapped to record its runtime statistics into it, keyed by plan ID.
+	runtimeStats map[string]*runtimeStats
+	// cteStorages maps the common table expressions to their materialized results, which are shared by
+	// all the executors reading the same common table expression.
+	cteStorages map[*plan.CTEDefinition]*cteStorage
 }
 
 func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executorBuilder {
@@ -52,11 +59,27 @@ func newExecutorBuilder(ctx context.Context, is infoschema.InfoSchema) *executor
 }
 
 func (b *executorBuilder) build(p plan.Plan) Executor {
+	e := b.buildExecutor(p)
+	if e == nil || b.runtimeStats == nil {
+		return e
+	}
+	stats := &runtimeStats{}
+	b.runtimeStats[p.ID()] = stats
+	return &runtimeStatsExec{Executor: e, stats: stats}
+}
+
+func (b *executorBuilder) buildExecutor(p plan.Plan) Executor {
 	switch v := p.(type) {
 	case nil:
 		return nil
 	case *plan.CheckTable:
 		return b.buildCheckTable(v)
+	case *plan.CheckIndex:
+		return b.buildCheckIndex(v)
+	case *plan.RecoverIndex:
+		return b.buildRecoverIndex(v)
+	case *plan.CleanupIndex:
+		return b.buildCleanupIndex(v)
 	case *plan.DDL:
 		return b.buildDDL(v)
 	case *plan.Deallocate:
@@ -71,6 +94,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildInsert(v)
 	case *plan.LoadData:
 		return b.buildLoadData(v)
+	case *plan.NonTransactionalDML:
+		return b.buildNonTransactionalDML(v)
 	case *plan.Limit:
 		return b.buildLimit(v)
 	case *plan.Prepare:
@@ -79,6 +104,14 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSelectLock(v)
 	case *plan.ShowDDL:
 		return b.buildShowDDL(v)
+	case *plan.ShowDDLJobs:
+		return b.buildShowDDLJobs(v)
+	case *plan.CancelDDLJobs:
+		return b.buildUpdateDDLJobs(v.Schema(), v.JobIDs, inspectkv.CancelJobs)
+	case *plan.PauseDDLJobs:
+		return b.buildUpdateDDLJobs(v.Schema(), v.JobIDs, inspectkv.PauseJobs)
+	case *plan.ResumeDDLJobs:
+		return b.buildUpdateDDLJobs(v.Schema(), v.JobIDs, inspectkv.ResumeJobs)
 	case *plan.Show:
 		return b.buildShow(v)
 	case *plan.Simple:
@@ -107,6 +140,8 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildSelection(v)
 	case *plan.PhysicalAggregation:
 		return b.buildAggregation(v)
+	case *plan.PhysicalWindow:
+		return b.buildWindow(v)
 	case *plan.Projection:
 		return b.buildProjection(v)
 	case *plan.PhysicalMemTable:
@@ -125,6 +160,10 @@ func (b *executorBuilder) build(p plan.Plan) Executor {
 		return b.buildMaxOneRow(v)
 	case *plan.Cache:
 		return b.buildCache(v)
+	case *plan.CTE:
+		return b.buildCTE(v)
+	case *plan.CTETable:
+		return b.buildCTETable(v)
 	case *plan.Analyze:
 		return b.buildAnalyze(v)
 	case *plan.PhysicalTableReader:
@@ -180,6 +219,46 @@ func (b *executorBuilder) buildShowDDL(v *plan.ShowDDL) Executor {
 	return e
 }
 
+func (b *executorBuilder) buildShowDDLJobs(v *plan.ShowDDLJobs) Executor {
+	e := &ShowDDLJobsExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+	}
+
+	jobs, err := inspectkv.GetDDLJobs(e.ctx.Txn())
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	historyJobs, err := inspectkv.GetHistoryDDLJobs(e.ctx.Txn())
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	e.jobs = append(jobs, historyJobs...)
+	e.queueLen = len(jobs)
+	return e
+}
+
+// buildUpdateDDLJobs builds the executor of the cancel, pause and resume DDL jobs statements,
+// update is one of inspectkv.CancelJobs, inspectkv.PauseJobs and inspectkv.ResumeJobs.
+func (b *executorBuilder) buildUpdateDDLJobs(schema *expression.Schema, jobIDs []int64,
+	update func(kv.Transaction, []int64) ([]error, error)) Executor {
+	// The jobs are updated in the statement's transaction, which is committed
+	// before the result set is read.
+	e := &UpdateDDLJobsExec{
+		baseExecutor: newBaseExecutor(schema, b.ctx),
+		jobIDs:       jobIDs,
+	}
+
+	var err error
+	e.errs, err = update(e.ctx.Txn(), e.jobIDs)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	return e
+}
+
 func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	return &CheckTableExec{
 		tables: v.Tables,
@@ -188,6 +267,77 @@ func (b *executorBuilder) buildCheckTable(v *plan.CheckTable) Executor {
 	}
 }
 
+// getTableIndex gets the physical tables and their indices for the admin index statements.
+// A partitioned table stores its rows and index entries in its partitions, so every partition is returned.
+func (b *executorBuilder) getTableIndex(tn *ast.TableName, idxName string) []physicalIndex {
+	tbl, err := b.is.TableByName(tn.Schema, tn.Name)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	var idx table.Index
+	for _, index := range tbl.Indices() {
+		if index.Meta().Name.L == strings.ToLower(idxName) && index.Meta().State == model.StatePublic {
+			idx = index
+			break
+		}
+	}
+	if idx == nil {
+		b.err = plan.ErrKeyDoesNotExist.GenByArgs(idxName, tn.Name.O)
+		return nil
+	}
+	pt, ok := tbl.(table.PartitionedTable)
+	if !ok {
+		return []physicalIndex{{table: tbl, index: idx}}
+	}
+	defs := tbl.Meta().Partition.Definitions
+	idxes := make([]physicalIndex, 0, len(defs))
+	for _, def := range defs {
+		p := pt.GetPartition(def.ID)
+		for _, index := range p.Indices() {
+			if index.Meta().ID == idx.Meta().ID {
+				idxes = append(idxes, physicalIndex{table: p, index: index})
+				break
+			}
+		}
+	}
+	return idxes
+}
+
+func (b *executorBuilder) buildCheckIndex(v *plan.CheckIndex) Executor {
+	idxes := b.getTableIndex(v.Table, v.IndexName)
+	if b.err != nil {
+		return nil
+	}
+	return &CheckIndexExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		idxes:        idxes,
+		handleRanges: v.HandleRanges,
+	}
+}
+
+func (b *executorBuilder) buildRecoverIndex(v *plan.RecoverIndex) Executor {
+	idxes := b.getTableIndex(v.Table, v.IndexName)
+	if b.err != nil {
+		return nil
+	}
+	return &RecoverIndexExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		idxes:        idxes,
+	}
+}
+
+func (b *executorBuilder) buildCleanupIndex(v *plan.CleanupIndex) Executor {
+	idxes := b.getTableIndex(v.Table, v.IndexName)
+	if b.err != nil {
+		return nil
+	}
+	return &CleanupIndexExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		idxes:        idxes,
+	}
+}
+
 func (b *executorBuilder) buildDeallocate(v *plan.Deallocate) Executor {
 	return &DeallocateExec{
 		ctx:  b.ctx,
@@ -196,6 +346,9 @@ func (b *executorBuilder) buildDeallocate(v *plan.Deallocate) Executor {
 }
 
 func (b *executorBuilder) buildSelectLock(v *plan.SelectLock) Executor {
+	if v.Lock == ast.SelectLockForUpdate {
+		b.refreshForUpdateTS()
+	}
 	src := b.build(v.Children()[0])
 	if !b.ctx.GetSessionVars().InTxn() {
 		// Locking of rows for update using SELECT FOR UPDATE only applies when autocommit
@@ -344,6 +497,7 @@ func (b *executorBuilder) buildGrant(grant *ast.GrantStmt) Executor {
 		ObjectType: grant.ObjectType,
 		Level:      grant.Level,
 		Users:      grant.Users,
+		Require:    grant.Require,
 		WithGrant:  grant.WithGrant,
 		is:         b.is,
 	}
@@ -365,10 +519,22 @@ func (b *executorBuilder) buildDDL(v *plan.DDL) Executor {
 }
 
 func (b *executorBuilder) buildExplain(v *plan.Explain) Executor {
-	return &ExplainExec{
+	e := &ExplainExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
 		StmtPlan:     v.StmtPlan,
+		Format:       v.Format,
+	}
+	if v.Analyze {
+		analyzeBuilder := newExecutorBuilder(b.ctx, b.is)
+		analyzeBuilder.runtimeStats = make(map[string]*runtimeStats)
+		e.analyzeExec = analyzeBuilder.build(v.StmtPlan)
+		if analyzeBuilder.err != nil {
+			b.err = errors.Trace(analyzeBuilder.err)
+			return nil
+		}
+		e.runtimeStats = analyzeBuilder.runtimeStats
 	}
+	return e
 }
 
 func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor {
@@ -377,16 +543,16 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 		return nil
 	}
 	us := &UnionScanExec{baseExecutor: newBaseExecutor(v.Schema(), b.ctx, src)}
-	switch x := src.(type) {
+	switch x := unwrapRuntimeStats(src).(type) {
 	case *XSelectTableExec:
 		us.desc = x.desc
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.tableID)
 		us.conditions = v.Conditions
 		us.columns = x.Columns
 		us.buildAndSortAddedRows(x.table, x.asName)
 	case *TableReaderExecutor:
 		us.desc = x.desc
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.tableID)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table, x.asName)
@@ -400,7 +566,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 				}
 			}
 		}
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.tableID)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table, x.asName)
@@ -414,7 +580,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 				}
 			}
 		}
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.tableID)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table, x.asName)
@@ -428,7 +594,7 @@ func (b *executorBuilder) buildUnionScanExec(v *plan.PhysicalUnionScan) Executor
 				}
 			}
 		}
-		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.table.Meta().ID)
+		us.dirty = getDirtyDB(b.ctx).getDirtyTable(x.tableID)
 		us.conditions = v.Conditions
 		us.columns = x.columns
 		us.buildAndSortAddedRows(x.table, x.asName)
@@ -565,6 +731,22 @@ func (b *executorBuilder) buildAggregation(v *plan.PhysicalAggregation) Executor
 	}
 }
 
+func (b *executorBuilder) buildWindow(v *plan.PhysicalWindow) Executor {
+	e := &WindowExec{
+		baseExecutor:    newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
+		WindowFuncDescs: v.WindowFuncDescs,
+		PartitionBy:     v.PartitionBy,
+		OrderBy:         v.OrderBy,
+		Frame:           v.Frame,
+		sc:              b.ctx.GetSessionVars().StmtCtx,
+		aggFuncs:        make([]expression.AggregationFunction, len(v.WindowFuncDescs)),
+	}
+	for i, desc := range v.WindowFuncDescs {
+		e.aggFuncs[i] = expression.NewAggFunction(desc.Name, desc.Args, false)
+	}
+	return e
+}
+
 func (b *executorBuilder) buildSelection(v *plan.Selection) Executor {
 	exec := &SelectionExec{
 		baseExecutor:   newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
@@ -590,12 +772,37 @@ func (b *executorBuilder) buildTableDual(v *plan.TableDual) Executor {
 
 func (b *executorBuilder) getStartTS() uint64 {
 	startTS := b.ctx.GetSessionVars().SnapshotTS
+	if startTS == 0 {
+		startTS = b.ctx.GetSessionVars().TxnCtx.ForUpdateTS
+	}
 	if startTS == 0 {
 		startTS = b.ctx.Txn().StartTS()
 	}
 	return startTS
 }
 
+// refreshForUpdateTS gets a new timestamp for the pessimistic locking statement, it must be called before building
+// the readers of the statement. The statement reads the data of this timestamp, and the rows it locks are checked
+// for the write conflicts at this timestamp. The locks wait for the locks held by others at most innodb_lock_wait_timeout,
+// and stop waiting if the statement is killed.
+func (b *executorBuilder) refreshForUpdateTS() {
+	sessVars := b.ctx.GetSessionVars()
+	if !sessVars.TxnCtx.IsPessimistic || !sessVars.InTxn() || sessVars.SnapshotTS != 0 {
+		return
+	}
+	ver, err := sessionctx.GetDomain(b.ctx).Store().CurrentVersion()
+	if err != nil {
+		b.err = errors.Trace(err)
+		return
+	}
+	sessVars.TxnCtx.ForUpdateTS = ver.Ver
+	b.ctx.Txn().SetOption(kv.ForUpdateTS, ver.Ver)
+	b.ctx.Txn().SetOption(kv.LockWaitTimeout, time.Duration(sessVars.LockWaitTimeout)*time.Second)
+	if goCtx := b.ctx.GoCtx(); goCtx != nil {
+		b.ctx.Txn().SetOption(kv.LockCtx, goCtx)
+	}
+}
+
 func (b *executorBuilder) buildMemTable(v *plan.PhysicalMemTable) Executor {
 	table, _ := b.is.TableByID(v.Table.ID)
 	ts := &TableScanExec{
@@ -626,6 +833,7 @@ func (b *executorBuilder) buildTableScan(v *plan.PhysicalTableScan) Executor {
 		supportDesc: supportDesc,
 		asName:      v.TableAsName,
 		table:       table,
+		tableID:     v.PhysicalTableID,
 		schema:      v.Schema(),
 		Columns:     v.Columns,
 		ranges:      v.Ranges,
@@ -655,6 +863,7 @@ func (b *executorBuilder) buildIndexScan(v *plan.PhysicalIndexScan) Executor {
 		supportDesc:          supportDesc,
 		asName:               v.TableAsName,
 		table:                table,
+		tableID:              v.PhysicalTableID,
 		singleReadMode:       !v.DoubleRead,
 		startTS:              startTS,
 		where:                v.TableConditionPBExpr,
@@ -801,6 +1010,7 @@ func (b *executorBuilder) buildUnion(v *plan.Union) Executor {
 }
 
 func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
+	b.refreshForUpdateTS()
 	return &UpdateExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
 		SelectExec:   b.build(v.Children()[0]),
@@ -809,6 +1019,7 @@ func (b *executorBuilder) buildUpdate(v *plan.Update) Executor {
 }
 
 func (b *executorBuilder) buildDelete(v *plan.Delete) Executor {
+	b.refreshForUpdateTS()
 	return &DeleteExec{
 		baseExecutor: newBaseExecutor(nil, b.ctx),
 		SelectExec:   b.build(v.Children()[0]),
@@ -817,12 +1028,53 @@ func (b *executorBuilder) buildDelete(v *plan.Delete) Executor {
 	}
 }
 
+func (b *executorBuilder) buildNonTransactionalDML(v *plan.NonTransactionalDML) Executor {
+	tableName := v.TableAsName
+	if tableName.L == "" {
+		tableName = v.Table.Name
+	}
+	return &NonTransactionalDMLExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		stmt:         v.Stmt,
+		tableName:    tableName,
+		shardColumn:  v.ShardColumn,
+		shardIndex:   v.ShardIndex,
+	}
+}
+
 func (b *executorBuilder) buildCache(v *plan.Cache) Executor {
 	return &CacheExec{
 		baseExecutor: newBaseExecutor(v.Schema(), b.ctx, b.build(v.Children()[0])),
 	}
 }
 
+func (b *executorBuilder) buildCTE(v *plan.CTE) Executor {
+	storage, ok := b.cteStorages[v.Definition]
+	if !ok {
+		storage = &cteStorage{ctx: b.ctx, isDistinct: v.Definition.IsDistinct}
+		if b.cteStorages == nil {
+			b.cteStorages = make(map[*plan.CTEDefinition]*cteStorage)
+		}
+		// The storage must be registered before building the recursive part, which reads it.
+		b.cteStorages[v.Definition] = storage
+		storage.seedExec = b.build(v.Definition.SeedPlan)
+		if v.Definition.RecursivePlan != nil {
+			storage.recursiveExec = b.build(v.Definition.RecursivePlan)
+		}
+	}
+	return &CTEExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		storage:      storage,
+	}
+}
+
+func (b *executorBuilder) buildCTETable(v *plan.CTETable) Executor {
+	return &CTETableExec{
+		baseExecutor: newBaseExecutor(v.Schema(), b.ctx),
+		storage:      b.cteStorages[v.Definition],
+	}
+}
+
 func (b *executorBuilder) buildTableScanForAnalyze(tblInfo *model.TableInfo, pk *model.ColumnInfo, cols []*model.ColumnInfo) Executor {
 	startTS := b.getStartTS()
 	if b.err != nil {
@@ -866,6 +1118,7 @@ func (b *executorBuilder) buildTableScanForAnalyze(tblInfo *model.TableInfo, pk
 	}
 	e := &XSelectTableExec{
 		tableInfo: tblInfo,
+		tableID:   tblInfo.ID,
 		ctx:       b.ctx,
 		startTS:   startTS,
 		table:     table,
@@ -921,6 +1174,7 @@ func (b *executorBuilder) buildIndexScanForAnalyze(tblInfo *model.TableInfo, idx
 	}
 	e := &XSelectIndexExec{
 		tableInfo:       tblInfo,
+		tableID:         tblInfo.ID,
 		ctx:             b.ctx,
 		table:           table,
 		singleReadMode:  true,
@@ -1004,7 +1258,7 @@ func (b *executorBuilder) buildTableReader(v *plan.PhysicalTableReader) Executor
 		schema:    v.Schema(),
 		dagPB:     dagReq,
 		asName:    ts.TableAsName,
-		tableID:   ts.Table.ID,
+		tableID:   ts.PhysicalTableID,
 		table:     table,
 		keepOrder: ts.KeepOrder,
 		desc:      ts.Desc,
@@ -1031,7 +1285,7 @@ func (b *executorBuilder) buildIndexReader(v *plan.PhysicalIndexReader) Executor
 		schema:    v.Schema(),
 		dagPB:     dagReq,
 		asName:    is.TableAsName,
-		tableID:   is.Table.ID,
+		tableID:   is.PhysicalTableID,
 		table:     table,
 		index:     is.Index,
 		keepOrder: !is.OutOfOrder,
@@ -1068,7 +1322,7 @@ func (b *executorBuilder) buildIndexLookUpReader(v *plan.PhysicalIndexLookUpRead
 		schema:       v.Schema(),
 		dagPB:        indexReq,
 		asName:       is.TableAsName,
-		tableID:      is.Table.ID,
+		tableID:      is.PhysicalTableID,
 		table:        table,
 		index:        is.Index,
 		keepOrder:    !is.OutOfOrder,
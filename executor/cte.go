@@ -0,0 +1,155 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/util/codec"
+)
+
+// cteStorage materializes the result of a common table expression. It is shared by all the executors
+// which read the same common table expression, so the common table expression is executed only once.
+type cteStorage struct {
+	ctx           context.Context
+	seedExec      Executor
+	recursiveExec Executor
+	isDistinct    bool
+
+	materialized bool
+	rows         []*Row
+	// iterRows are the rows produced by the last iteration, they are read by the CTETableExec
+	// in the next iteration.
+	iterRows []*Row
+	// hashTable stores the encoded rows which have been produced, it is used to remove
+	// duplicated rows for UNION DISTINCT.
+	hashTable map[string]struct{}
+}
+
+// materialize executes the seed part, then executes the recursive part repeatedly until it produces no new row.
+func (s *cteStorage) materialize() error {
+	if s.materialized {
+		return nil
+	}
+	if s.isDistinct {
+		s.hashTable = make(map[string]struct{})
+	}
+	newRows, err := s.execute(s.seedExec)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	s.iterRows = newRows
+	if s.recursiveExec != nil {
+		maxDepth := s.ctx.GetSessionVars().CTEMaxRecursionDepth
+		for iter := 1; len(s.iterRows) > 0; iter++ {
+			if iter > maxDepth {
+				return ErrCTEMaxRecursionDepth.GenByArgs(iter)
+			}
+			newRows, err = s.execute(s.recursiveExec)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			s.iterRows = newRows
+		}
+	}
+	s.iterRows = nil
+	s.hashTable = nil
+	s.materialized = true
+	return nil
+}
+
+// execute runs the executor to the end and appends the new rows to the result.
+func (s *cteStorage) execute(e Executor) (newRows []*Row, err error) {
+	if err = e.Open(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer func() {
+		if closeErr := e.Close(); err == nil {
+			err = errors.Trace(closeErr)
+		}
+	}()
+	for {
+		row, err := e.Next()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		if s.isDistinct {
+			key, err := codec.EncodeValue(nil, row.Data...)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			if _, ok := s.hashTable[string(key)]; ok {
+				continue
+			}
+			s.hashTable[string(key)] = struct{}{}
+		}
+		// The row keys of the source tables are meaningless to the readers of the common table expression.
+		newRows = append(newRows, &Row{Data: row.Data})
+	}
+	s.rows = append(s.rows, newRows...)
+	return newRows, nil
+}
+
+// CTEExec reads the result of a common table expression.
+type CTEExec struct {
+	baseExecutor
+
+	storage *cteStorage
+	cursor  int
+}
+
+// Open implements the Executor Open interface.
+func (e *CTEExec) Open() error {
+	e.cursor = 0
+	return errors.Trace(e.storage.materialize())
+}
+
+// Next implements the Executor Next interface.
+func (e *CTEExec) Next() (*Row, error) {
+	if e.cursor >= len(e.storage.rows) {
+		return nil, nil
+	}
+	row := e.storage.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
+
+// CTETableExec reads the rows produced by the last iteration of a recursive common table expression.
+type CTETableExec struct {
+	baseExecutor
+
+	storage *cteStorage
+	rows    []*Row
+	cursor  int
+}
+
+// Open implements the Executor Open interface.
+func (e *CTETableExec) Open() error {
+	e.rows = e.storage.iterRows
+	e.cursor = 0
+	return nil
+}
+
+// Next implements the Executor Next interface.
+func (e *CTETableExec) Next() (*Row, error) {
+	if e.cursor >= len(e.rows) {
+		return nil, nil
+	}
+	row := e.rows[e.cursor]
+	e.cursor++
+	return row, nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/executor"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestCommonTableExpression(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, s")
+	tk.MustExec("create table t (a int, b int)")
+	tk.MustExec("insert into t values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("create table s (a int)")
+
+	result := tk.MustQuery("with c as (select a, b from t where a > 1) select * from c order by a")
+	result.Check(testkit.Rows("2 2", "3 3"))
+	result = tk.MustQuery("with c (x, y) as (select a, b * 10 from t) select x, y from c where x < 3 order by x")
+	result.Check(testkit.Rows("1 10", "2 20"))
+	// A common table expression can refer to the ones defined before it, and can be referenced more than once.
+	result = tk.MustQuery("with c1 as (select a from t), c2 as (select a + 1 as a from c1) select c1.a, c2.a from c1 join c2 on c1.a = c2.a order by c1.a")
+	result.Check(testkit.Rows("2 2", "3 3"))
+	result = tk.MustQuery("with c as (select a from t) select a from c where a > (select min(a) from c) order by a")
+	result.Check(testkit.Rows("2", "3"))
+	result = tk.MustQuery("select * from (with c as (select 1 as x) select x from c) as d")
+	result.Check(testkit.Rows("1"))
+	result = tk.MustQuery("with t as (select 10 as a) select a from t")
+	result.Check(testkit.Rows("10"))
+	result = tk.MustQuery("with c as (select 1 as x union select 1 union select 2) select * from c order by x")
+	result.Check(testkit.Rows("1", "2"))
+	tk.MustExec("insert into s with c as (select a * 100 as a from t) select a from c")
+	tk.MustQuery("select a from s order by a").Check(testkit.Rows("100", "200", "300"))
+
+	result = tk.MustQuery("with recursive c (n) as (select 1 union all select n + 1 from c where n < 5) select * from c")
+	result.Check(testkit.Rows("1", "2", "3", "4", "5"))
+	result = tk.MustQuery("with recursive c (n, fact) as (select 1, 1 union all select n + 1, (n + 1) * fact from c where n < 6) select fact from c where n = 6")
+	result.Check(testkit.Rows("720"))
+	// UNION DISTINCT stops the recursion when no new row is produced.
+	result = tk.MustQuery("with recursive c (n) as (select 1 union select (n + 1) % 3 from c) select * from c order by n")
+	result.Check(testkit.Rows("0", "1", "2"))
+	result = tk.MustQuery("with recursive c (n) as (select 1 union all select n + 1 from c where n < 3) select c1.n, c2.n from c as c1, c as c2 where c1.n = c2.n order by c1.n")
+	result.Check(testkit.Rows("1 1", "2 2", "3 3"))
+
+	// Org chart.
+	tk.MustExec("drop table if exists emp")
+	tk.MustExec("create table emp (id int, name varchar(20), manager_id int)")
+	tk.MustExec(`insert into emp values (1, "ceo", null), (2, "cto", 1), (3, "cfo", 1), (4, "dev", 2), (5, "ops", 2), (6, "intern", 4)`)
+	result = tk.MustQuery(`with recursive chain (id, name, lvl) as (
+		select id, name, 0 from emp where manager_id is null
+		union all
+		select e.id, e.name, chain.lvl + 1 from emp as e join chain on e.manager_id = chain.id)
+		select name, lvl from chain order by lvl, id`)
+	result.Check(testkit.Rows("ceo 0", "cto 1", "cfo 1", "dev 2", "ops 2", "intern 3"))
+	result = tk.MustQuery(`with recursive reports (id) as (
+		select id from emp where name = "cto"
+		union all
+		select emp.id from reports, emp where emp.manager_id = reports.id)
+		select count(*) from reports`)
+	result.Check(testkit.Rows("4"))
+
+	// Bill of materials.
+	tk.MustExec("drop table if exists bom")
+	tk.MustExec("create table bom (part varchar(20), sub_part varchar(20), quantity int)")
+	tk.MustExec(`insert into bom values ("bike", "wheel", 2), ("bike", "frame", 1), ("wheel", "spoke", 32), ("wheel", "tire", 1), ("frame", "tube", 3)`)
+	result = tk.MustQuery(`with recursive parts (sub_part, quantity) as (
+		select sub_part, quantity from bom where part = "bike"
+		union all
+		select bom.sub_part, parts.quantity * bom.quantity from parts join bom on bom.part = parts.sub_part)
+		select sub_part, sum(quantity) from parts group by sub_part order by sub_part`)
+	result.Check(testkit.Rows("frame 1", "spoke 64", "tire 2", "tube 3", "wheel 2"))
+
+	// The recursion depth is limited by cte_max_recursion_depth.
+	tk.MustExec("set @@cte_max_recursion_depth = 10")
+	result = tk.MustQuery("with recursive c (n) as (select 1 union all select n + 1 from c where n < 10) select count(*) from c")
+	result.Check(testkit.Rows("10"))
+	_, err := tk.Exec("with recursive c (n) as (select 1 union all select n + 1 from c where n < 20) select count(*) from c")
+	c.Assert(terror.ErrorEqual(err, executor.ErrCTEMaxRecursionDepth), IsTrue)
+	_, err = tk.Exec("with recursive c (n) as (select 1 union all select n from c) select * from c")
+	c.Assert(terror.ErrorEqual(err, executor.ErrCTEMaxRecursionDepth), IsTrue)
+	tk.MustExec("set @@cte_max_recursion_depth = default")
+
+	_, err = tk.Exec("with c as (select 1), c as (select 2) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNonUniqTable), IsTrue)
+	_, err = tk.Exec("with c (x, y) as (select 1) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrViewWrongList), IsTrue)
+	_, err = tk.Exec("with recursive c as (select a from c) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresUnion), IsTrue)
+	_, err = tk.Exec("with recursive c (n) as (select n from c union all select 1) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresNonRecursiveFirst), IsTrue)
+	_, err = tk.Exec("with recursive c (n) as (select 1 union all select max(n) from c) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveForbidsAggregation), IsTrue)
+	_, err = tk.Exec("with recursive c (n) as (select 1 union all select c1.n from c as c1, c as c2) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresSingleReference), IsTrue)
+	_, err = tk.Exec("with recursive c (n) as (select 1 union all select a from t where a in (select n from c)) select * from c")
+	c.Assert(terror.ErrorEqual(err, plan.ErrCTERecursiveRequiresSingleReference), IsTrue)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
@@ -59,6 +60,8 @@ func (e *DDLExec) Next() (*Row, error) {
 		err = e.executeCreateTable(x)
 	case *ast.CreateIndexStmt:
 		err = e.executeCreateIndex(x)
+	case *ast.CreateViewStmt:
+		err = e.executeCreateView(x)
 	case *ast.DropDatabaseStmt:
 		err = e.executeDropDatabase(x)
 	case *ast.DropTableStmt:
@@ -135,7 +138,7 @@ func (e *DDLExec) executeCreateTable(s *ast.CreateTableStmt) error {
 	ident := ast.Ident{Schema: s.Table.Schema, Name: s.Table.Name}
 	var err error
 	if s.ReferTable == nil {
-		err = sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options)
+		err = sessionctx.GetDomain(e.ctx).DDL().CreateTable(e.ctx, ident, s.Cols, s.Constraints, s.Options, s.Partition)
 	} else {
 		referIdent := ast.Ident{Schema: s.ReferTable.Schema, Name: s.ReferTable.Name}
 		err = sessionctx.GetDomain(e.ctx).DDL().CreateTableWithLike(e.ctx, ident, referIdent)
@@ -180,6 +183,38 @@ func (e *DDLExec) executeDropDatabase(s *ast.DropDatabaseStmt) error {
 	return errors.Trace(err)
 }
 
+func (e *DDLExec) executeCreateView(s *ast.CreateViewStmt) error {
+	p, err := plan.BuildLogicalPlan(e.ctx, s.Select, e.is)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	schema := p.Schema()
+	cols := make([]*model.ColumnInfo, 0, schema.Len())
+	viewCols := make([]model.CIStr, 0, schema.Len())
+	for i, col := range schema.Columns {
+		name := col.ColName
+		if len(s.Cols) > 0 {
+			name = s.Cols[i]
+		}
+		colInfo := &model.ColumnInfo{
+			Name:      name,
+			FieldType: *col.RetType,
+		}
+		// Key and auto increment attributes of the base table don't belong to the view.
+		colInfo.Flag &^= mysql.PriKeyFlag | mysql.UniqueKeyFlag | mysql.MultipleKeyFlag | mysql.AutoIncrementFlag | mysql.OnUpdateNowFlag
+		cols = append(cols, colInfo)
+		viewCols = append(viewCols, name)
+	}
+	viewInfo := &model.ViewInfo{
+		Definer:    e.ctx.GetSessionVars().User,
+		SelectStmt: s.Select.Text(),
+		Cols:       viewCols,
+	}
+	ident := ast.Ident{Schema: s.ViewName.Schema, Name: s.ViewName.Name}
+	err = sessionctx.GetDomain(e.ctx).DDL().CreateView(e.ctx, ident, viewInfo, cols, s.OrReplace)
+	return errors.Trace(err)
+}
+
 func (e *DDLExec) executeDropTable(s *ast.DropTableStmt) error {
 	var notExistTables []string
 	for _, tn := range s.Tables {
@@ -199,7 +234,11 @@ func (e *DDLExec) executeDropTable(s *ast.DropTableStmt) error {
 			return errors.Trace(err)
 		}
 
-		err = sessionctx.GetDomain(e.ctx).DDL().DropTable(e.ctx, fullti)
+		if s.IsView {
+			err = sessionctx.GetDomain(e.ctx).DDL().DropView(e.ctx, fullti)
+		} else {
+			err = sessionctx.GetDomain(e.ctx).DDL().DropTable(e.ctx, fullti)
+		}
 		if infoschema.ErrDatabaseNotExists.Equal(err) || infoschema.ErrTableNotExists.Equal(err) {
 			notExistTables = append(notExistTables, fullti.String())
 		} else if err != nil {
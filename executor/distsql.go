@@ -349,6 +349,7 @@ func closeAll(objs ...Closeable) error {
 type XSelectIndexExec struct {
 	tableInfo      *model.TableInfo
 	table          table.Table
+	tableID        int64 // the partition ID if the table is partitioned.
 	asName         *model.CIStr
 	ctx            context.Context
 	supportDesc    bool
@@ -639,6 +640,7 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 	selIdxReq.TimeZoneOffset = timeZoneOffset(e.ctx)
 	selIdxReq.Flags = statementContextToFlags(e.ctx.GetSessionVars().StmtCtx)
 	selIdxReq.IndexInfo = distsql.IndexToProto(e.table.Meta(), e.index)
+	selIdxReq.IndexInfo.TableId = e.tableID
 	if e.desc {
 		selIdxReq.OrderBy = []*tipb.ByItem{{Desc: e.desc}}
 	}
@@ -664,11 +666,11 @@ func (e *XSelectIndexExec) doIndexRequest() (distsql.SelectResult, error) {
 	}
 	sv := e.ctx.GetSessionVars()
 	sc := sv.StmtCtx
-	keyRanges, err := indexRangesToKVRanges(sc, e.table.Meta().ID, e.index.ID, e.ranges, fieldTypes)
+	keyRanges, err := indexRangesToKVRanges(sc, e.tableID, e.index.ID, e.ranges, fieldTypes)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
-	return distsql.Select(e.ctx.GetClient(), e.ctx.GoCtx(), selIdxReq, keyRanges, e.scanConcurrency, !e.outOfOrder, getIsolationLevel(sv))
+	return distsql.Select(e.ctx.GetClient(), e.ctx.GoCtx(), selIdxReq, keyRanges, e.scanConcurrency, !e.outOfOrder, getIsolationLevel(sv), &sv.StmtCtx.ExecDetails)
 }
 
 func getIsolationLevel(sv *variable.SessionVars) kv.IsoLevel {
@@ -806,7 +808,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	selTableReq.TimeZoneOffset = timeZoneOffset(e.ctx)
 	selTableReq.Flags = statementContextToFlags(e.ctx.GetSessionVars().StmtCtx)
 	selTableReq.TableInfo = &tipb.TableInfo{
-		TableId: e.table.Meta().ID,
+		TableId: e.tableID,
 	}
 	selTableReq.TableInfo.Columns = distsql.ColumnsToProto(e.columns, e.table.Meta().PKIsHandle)
 	err := setPBColumnsDefaultValue(e.ctx, selTableReq.TableInfo.Columns, e.columns)
@@ -817,10 +819,10 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 	// Aggregate Info
 	selTableReq.Aggregates = e.aggFuncs
 	selTableReq.GroupBy = e.byItems
-	keyRanges := tableHandlesToKVRanges(e.table.Meta().ID, handles)
+	keyRanges := tableHandlesToKVRanges(e.tableID, handles)
 	// Use the table scan concurrency variable to do table request.
 	concurrency := e.ctx.GetSessionVars().DistSQLScanConcurrency
-	resp, err := distsql.Select(e.ctx.GetClient(), goctx.Background(), selTableReq, keyRanges, concurrency, false, getIsolationLevel(e.ctx.GetSessionVars()))
+	resp, err := distsql.Select(e.ctx.GetClient(), goctx.Background(), selTableReq, keyRanges, concurrency, false, getIsolationLevel(e.ctx.GetSessionVars()), &e.ctx.GetSessionVars().StmtCtx.ExecDetails)
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
@@ -833,6 +835,7 @@ func (e *XSelectIndexExec) doTableRequest(handles []int64) (distsql.SelectResult
 type XSelectTableExec struct {
 	tableInfo   *model.TableInfo
 	table       table.Table
+	tableID     int64 // the partition ID if the table is partitioned.
 	asName      *model.CIStr
 	ctx         context.Context
 	supportDesc bool
@@ -882,7 +885,7 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.Flags = statementContextToFlags(e.ctx.GetSessionVars().StmtCtx)
 	selReq.Where = e.where
 	selReq.TableInfo = &tipb.TableInfo{
-		TableId: e.tableInfo.ID,
+		TableId: e.tableID,
 	}
 	selReq.TableInfo.Columns = distsql.ColumnsToProto(e.Columns, e.tableInfo.PKIsHandle)
 	err := setPBColumnsDefaultValue(e.ctx, selReq.TableInfo.Columns, e.Columns)
@@ -899,8 +902,8 @@ func (e *XSelectTableExec) doRequest() error {
 	selReq.Aggregates = e.aggFuncs
 	selReq.GroupBy = e.byItems
 
-	kvRanges := tableRangesToKVRanges(e.table.Meta().ID, e.ranges)
-	e.result, err = distsql.Select(e.ctx.GetClient(), goctx.Background(), selReq, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, getIsolationLevel(e.ctx.GetSessionVars()))
+	kvRanges := tableRangesToKVRanges(e.tableID, e.ranges)
+	e.result, err = distsql.Select(e.ctx.GetClient(), goctx.Background(), selReq, kvRanges, e.ctx.GetSessionVars().DistSQLScanConcurrency, e.keepOrder, getIsolationLevel(e.ctx.GetSessionVars()), &e.ctx.GetSessionVars().StmtCtx.ExecDetails)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -14,8 +14,10 @@
 package executor
 
 import (
+	"fmt"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -27,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
@@ -36,6 +39,8 @@ import (
 
 var (
 	_ Executor = &CheckTableExec{}
+	_ Executor = &ShowDDLJobsExec{}
+	_ Executor = &UpdateDDLJobsExec{}
 	_ Executor = &DummyScanExec{}
 	_ Executor = &ExistsExec{}
 	_ Executor = &HashAggExec{}
@@ -55,35 +60,43 @@ var (
 
 // Error instances.
 var (
-	ErrUnknownPlan          = terror.ClassExecutor.New(codeUnknownPlan, "Unknown plan")
-	ErrPrepareMulti         = terror.ClassExecutor.New(codePrepareMulti, "Can not prepare multiple statements")
-	ErrStmtNotFound         = terror.ClassExecutor.New(codeStmtNotFound, "Prepared statement not found")
-	ErrSchemaChanged        = terror.ClassExecutor.New(codeSchemaChanged, "Schema has changed")
-	ErrWrongParamCount      = terror.ClassExecutor.New(codeWrongParamCount, "Wrong parameter count")
-	ErrRowKeyCount          = terror.ClassExecutor.New(codeRowKeyCount, "Wrong row key entry count")
-	ErrPrepareDDL           = terror.ClassExecutor.New(codePrepareDDL, "Can not prepare DDL statements")
-	ErrPasswordNoMatch      = terror.ClassExecutor.New(CodePasswordNoMatch, "Can't find any matching row in the user table")
-	ErrResultIsEmpty        = terror.ClassExecutor.New(codeResultIsEmpty, "result is empty")
-	ErrBuildExecutor        = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
-	ErrBatchInsertFail      = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
-	ErrWrongValueCountOnRow = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrUnknownPlan              = terror.ClassExecutor.New(codeUnknownPlan, "Unknown plan")
+	ErrPrepareMulti             = terror.ClassExecutor.New(codePrepareMulti, "Can not prepare multiple statements")
+	ErrStmtNotFound             = terror.ClassExecutor.New(codeStmtNotFound, "Prepared statement not found")
+	ErrSchemaChanged            = terror.ClassExecutor.New(codeSchemaChanged, "Schema has changed")
+	ErrWrongParamCount          = terror.ClassExecutor.New(codeWrongParamCount, "Wrong parameter count")
+	ErrRowKeyCount              = terror.ClassExecutor.New(codeRowKeyCount, "Wrong row key entry count")
+	ErrPrepareDDL               = terror.ClassExecutor.New(codePrepareDDL, "Can not prepare DDL statements")
+	ErrPasswordNoMatch          = terror.ClassExecutor.New(CodePasswordNoMatch, "Can't find any matching row in the user table")
+	ErrResultIsEmpty            = terror.ClassExecutor.New(codeResultIsEmpty, "result is empty")
+	ErrBuildExecutor            = terror.ClassExecutor.New(codeErrBuildExec, "Failed to build executor")
+	ErrBatchInsertFail          = terror.ClassExecutor.New(codeBatchInsertFail, "Batch insert failed, please clean the table and try again.")
+	ErrNonTransactionalDMLInTxn = terror.ClassExecutor.New(codeNonTransactionalDMLInTxn, "Can not execute non-transactional DML in a transaction")
+	ErrBindingNotExists         = terror.ClassExecutor.New(codeBindingNotExists, "There is no binding for the statement")
+	ErrWrongValueCountOnRow     = terror.ClassExecutor.New(codeWrongValueCountOnRow, "Column count doesn't match value count at row %d")
+	ErrCTEMaxRecursionDepth     = terror.ClassExecutor.New(codeCTEMaxRecursionDepth, mysql.MySQLErrName[mysql.ErrCTEMaxRecursionDepth])
+	ErrQueryTimeout             = terror.ClassExecutor.New(codeQueryTimeout, mysql.MySQLErrName[mysql.ErrQueryTimeout])
 )
 
 // Error codes.
 const (
-	codeUnknownPlan          terror.ErrCode = 1
-	codePrepareMulti         terror.ErrCode = 2
-	codeStmtNotFound         terror.ErrCode = 3
-	codeSchemaChanged        terror.ErrCode = 4
-	codeWrongParamCount      terror.ErrCode = 5
-	codeRowKeyCount          terror.ErrCode = 6
-	codePrepareDDL           terror.ErrCode = 7
-	codeResultIsEmpty        terror.ErrCode = 8
-	codeErrBuildExec         terror.ErrCode = 9
-	codeBatchInsertFail      terror.ErrCode = 10
-	CodePasswordNoMatch      terror.ErrCode = 1133 // MySQL error code
-	CodeCannotUser           terror.ErrCode = 1396 // MySQL error code
-	codeWrongValueCountOnRow terror.ErrCode = 1136 // MySQL error code
+	codeUnknownPlan              terror.ErrCode = 1
+	codePrepareMulti             terror.ErrCode = 2
+	codeStmtNotFound             terror.ErrCode = 3
+	codeSchemaChanged            terror.ErrCode = 4
+	codeWrongParamCount          terror.ErrCode = 5
+	codeRowKeyCount              terror.ErrCode = 6
+	codePrepareDDL               terror.ErrCode = 7
+	codeResultIsEmpty            terror.ErrCode = 8
+	codeErrBuildExec             terror.ErrCode = 9
+	codeBatchInsertFail          terror.ErrCode = 10
+	codeNonTransactionalDMLInTxn terror.ErrCode = 11
+	codeBindingNotExists         terror.ErrCode = 12
+	CodePasswordNoMatch          terror.ErrCode = 1133 // MySQL error code
+	CodeCannotUser               terror.ErrCode = 1396 // MySQL error code
+	codeWrongValueCountOnRow     terror.ErrCode = 1136 // MySQL error code
+	codeCTEMaxRecursionDepth     terror.ErrCode = 3636 // MySQL error code
+	codeQueryTimeout             terror.ErrCode = 3024 // MySQL error code
 )
 
 // Row represents a result set row, it may be returned from a table, a join, or a projection.
@@ -198,6 +211,88 @@ func (e *ShowDDLExec) Next() (*Row, error) {
 	return row, nil
 }
 
+// ShowDDLJobsExec represents a show DDL jobs executor.
+type ShowDDLJobsExec struct {
+	baseExecutor
+
+	cursor int
+	jobs   []*model.Job
+	// queueLen is the number of the jobs in the queue, the history jobs come after them.
+	queueLen int
+}
+
+// Next implements the Executor Next interface.
+func (e *ShowDDLJobsExec) Next() (*Row, error) {
+	if e.cursor >= len(e.jobs) {
+		return nil, nil
+	}
+
+	job := e.jobs[e.cursor]
+	state := job.State.String()
+	if e.cursor > 0 && e.cursor < e.queueLen && e.jobs[0].IsPaused() {
+		// The queue is serial, so the jobs behind the paused job wait until it's resumed or cancelled.
+		state = fmt.Sprintf("%s (blocked by paused job %d)", state, e.jobs[0].ID)
+	}
+	row := &Row{}
+	row.Data = types.MakeDatums(
+		job.ID,
+		job.SchemaID,
+		job.TableID,
+		job.Type.String(),
+		job.SchemaState.String(),
+		job.GetRowCount(),
+		nil,
+		nil,
+		nil,
+		state,
+	)
+	// Only the add index job reports its progress.
+	if job.Type == model.ActionAddIndex {
+		row.Data[6].SetFloat64(job.GetProgress())
+	}
+	if job.StartTS > 0 {
+		row.Data[7].SetMysqlTime(tsToTime(job.StartTS))
+	}
+	if job.LastUpdateTS > 0 {
+		row.Data[8].SetMysqlTime(tsToTime(uint64(job.LastUpdateTS)))
+	}
+	e.cursor++
+
+	return row, nil
+}
+
+// tsToTime converts a timestamp allocated by the store to a datetime.
+func tsToTime(ts uint64) types.Time {
+	t := time.Unix(0, oracle.ExtractPhysical(ts)*int64(time.Millisecond))
+	return types.Time{Time: types.FromGoTime(t), Type: mysql.TypeDatetime}
+}
+
+// UpdateDDLJobsExec represents the executor of the cancel, pause and resume DDL jobs statements.
+type UpdateDDLJobsExec struct {
+	baseExecutor
+
+	cursor int
+	jobIDs []int64
+	errs   []error
+}
+
+// Next implements the Executor Next interface.
+func (e *UpdateDDLJobsExec) Next() (*Row, error) {
+	if e.cursor >= len(e.jobIDs) {
+		return nil, nil
+	}
+
+	result := "successful"
+	if e.errs[e.cursor] != nil {
+		result = fmt.Sprintf("error: %v", e.errs[e.cursor])
+	}
+	row := &Row{}
+	row.Data = types.MakeDatums(e.jobIDs[e.cursor], result)
+	e.cursor++
+
+	return row, nil
+}
+
 // CheckTableExec represents a check table executor.
 // It is built from the "admin check table" statement, and it checks if the
 // index matches the records in the table.
@@ -247,14 +342,60 @@ func (e *CheckTableExec) Close() error {
 // After the execution, the keys are buffered in transaction, and will be sent to KV
 // when doing commit. If there is any key already locked by another transaction,
 // the transaction will rollback and retry.
+// In a pessimistic transaction, it locks all the row keys when it is opened, and waits if a key is
+// locked by another transaction.
 type SelectLockExec struct {
 	baseExecutor
 
 	Lock ast.SelectLockType
+
+	pessimistic bool
+	rows        []*Row
+	cursor      int
+}
+
+// Open implements the Executor Open interface.
+func (e *SelectLockExec) Open() error {
+	if err := e.baseExecutor.Open(); err != nil {
+		return errors.Trace(err)
+	}
+	e.pessimistic = e.Lock == ast.SelectLockForUpdate && e.ctx.GetSessionVars().TxnCtx.ForUpdateTS != 0
+	if !e.pessimistic {
+		return nil
+	}
+	e.rows, e.cursor = e.rows[:0], 0
+	var keys []kv.Key
+	for {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		e.rows = append(e.rows, row)
+		for _, k := range row.RowKeys {
+			keys = append(keys, tablecodec.EncodeRowKeyWithHandle(k.Tbl.Meta().ID, k.Handle))
+		}
+	}
+	// Lock all the rows before returning any of them, so the statement can be retried if the locking fails.
+	if err := e.ctx.Txn().LockKeys(keys...); err != nil {
+		e.children[0].Close()
+		return errors.Trace(err)
+	}
+	return nil
 }
 
 // Next implements the Executor Next interface.
 func (e *SelectLockExec) Next() (*Row, error) {
+	if e.pessimistic {
+		if e.cursor >= len(e.rows) {
+			return nil, nil
+		}
+		row := e.rows[e.cursor]
+		e.cursor++
+		return row, nil
+	}
 	row, err := e.children[0].Next()
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -351,6 +492,8 @@ func init() {
 		CodeCannotUser:           mysql.ErrCannotUser,
 		CodePasswordNoMatch:      mysql.ErrPasswordNoMatch,
 		codeWrongValueCountOnRow: mysql.ErrWrongValueCountOnRow,
+		codeCTEMaxRecursionDepth: mysql.ErrCTEMaxRecursionDepth,
+		codeQueryTimeout:         mysql.ErrQueryTimeout,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExecutor] = tableMySQLErrCodes
 }
@@ -433,7 +576,7 @@ func (e *SelectionExec) initController() error {
 		newConds = append(newConds, newCond)
 	}
 
-	switch x := e.children[0].(type) {
+	switch x := unwrapRuntimeStats(e.children[0]).(type) {
 	case *XSelectTableExec:
 		accessCondition, restCondtion := ranger.DetachColumnConditions(newConds, x.tableInfo.GetPkName())
 		x.where, _, _ = expression.ExpressionsToPB(sc, restCondtion, client)
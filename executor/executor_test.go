@@ -16,7 +16,9 @@ package executor_test
 import (
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -39,10 +41,13 @@ import (
 	"github.com/pingcap/tidb/store/tikv"
 	mocktikv "github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/deadlock"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 	"github.com/pingcap/tidb/util/types"
+	goctx "golang.org/x/net/context"
 )
 
 func TestT(t *testing.T) {
@@ -140,6 +145,51 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(row, IsNil)
 
+	// show DDL jobs test
+	r, err = tk.Exec("admin show ddl jobs")
+	c.Assert(err, IsNil)
+	row, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data, HasLen, 10)
+	txn, err = s.store.Begin()
+	c.Assert(err, IsNil)
+	historyJobs, err := inspectkv.GetHistoryDDLJobs(txn)
+	c.Assert(len(historyJobs), Greater, 1)
+	c.Assert(err, IsNil)
+	c.Assert(row.Data[0].GetInt64(), Equals, historyJobs[0].ID)
+	c.Assert(row.Data[3].GetString(), Equals, "create table")
+	c.Assert(row.Data[9].GetString(), Equals, "synced")
+	// Only the add index job has the progress.
+	c.Assert(row.Data[6].IsNull(), IsTrue)
+	c.Assert(row.Data[7].IsNull(), IsFalse)
+
+	// cancel DDL jobs test
+	r, err = tk.Exec("admin cancel ddl jobs 1")
+	c.Assert(err, IsNil)
+	row, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data, HasLen, 2)
+	c.Assert(row.Data[0].GetInt64(), Equals, int64(1))
+	c.Assert(row.Data[1].GetString(), Equals, "error: [inspectkv:4]DDL Job:1 not found")
+	r, err = tk.Exec(fmt.Sprintf("admin cancel ddl jobs %d", historyJobs[0].ID))
+	c.Assert(err, IsNil)
+	row, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data[1].GetString(), Matches, "error: .*DDL Job:.* not found")
+
+	// pause and resume DDL jobs test
+	r, err = tk.Exec("admin pause ddl jobs 1")
+	c.Assert(err, IsNil)
+	row, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data, HasLen, 2)
+	c.Assert(row.Data[1].GetString(), Equals, "error: [inspectkv:4]DDL Job:1 not found")
+	r, err = tk.Exec("admin resume ddl jobs 1")
+	c.Assert(err, IsNil)
+	row, err = r.Next()
+	c.Assert(err, IsNil)
+	c.Assert(row.Data[1].GetString(), Equals, "error: [inspectkv:4]DDL Job:1 not found")
+
 	// check table test
 	tk.MustExec("create table admin_test1 (c1 int, c2 int default 1, index (c1))")
 	tk.MustExec("insert admin_test1 (c1) values (21),(22)")
@@ -165,6 +215,60 @@ func (s *testSuite) TestAdmin(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testSuite) TestAdminIndex(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists admin_index_test")
+	tk.MustExec("create table admin_index_test (c1 int, c2 int, c3 int default 1, index idx (c1))")
+	tk.MustExec("insert admin_index_test (c1, c2) values (1, 1), (2, 2), (3, 3)")
+	tk.MustQuery("admin check index admin_index_test idx").Check(testkit.Rows())
+	tk.MustQuery("admin recover index admin_index_test idx").Check(testkit.Rows("0 3"))
+	tk.MustQuery("admin cleanup index admin_index_test idx").Check(testkit.Rows("0 3"))
+	_, err := tk.Exec("admin check index admin_index_test c2")
+	c.Assert(terror.ErrorEqual(err, plan.ErrKeyDoesNotExist), IsTrue, Commentf("err:%v", err))
+	_, err = tk.Exec("admin recover index admin_index_test_error idx")
+	c.Assert(err, NotNil)
+
+	// Make the index inconsistent with the records:
+	// index     data (handle, c1): (1, 1), (2, 20), (4, 4)
+	// table     data (handle, c1): (1, 1), (2, 2), (3, 3)
+	is := sessionctx.GetDomain(tk.Se.(context.Context)).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("admin_index_test"))
+	c.Assert(err, IsNil)
+	idx := tb.Indices()[0]
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	c.Assert(idx.Delete(txn, types.MakeDatums(int64(2)), 2), IsNil)
+	c.Assert(idx.Delete(txn, types.MakeDatums(int64(3)), 3), IsNil)
+	_, err = idx.Create(txn, types.MakeDatums(int64(20)), 2)
+	c.Assert(err, IsNil)
+	_, err = idx.Create(txn, types.MakeDatums(int64(4)), 4)
+	c.Assert(err, IsNil)
+	c.Assert(txn.Commit(), IsNil)
+
+	tk.MustQuery("admin check index admin_index_test idx").Check(testkit.Rows(
+		"4 4 <nil>",
+		"2 20 2",
+		"2 <nil> 2",
+		"3 <nil> 3",
+	))
+	tk.MustQuery("admin check index admin_index_test idx (1, 2)").Check(testkit.Rows("2 20 2", "2 <nil> 2"))
+	tk.MustQuery("admin check index admin_index_test idx (-10, 1), (4, 10)").Check(testkit.Rows("4 4 <nil>"))
+	_, err = tk.Exec("admin check table admin_index_test")
+	c.Assert(err, NotNil)
+
+	tk.MustQuery("admin cleanup index admin_index_test idx").Check(testkit.Rows("2 3"))
+	tk.MustQuery("admin check index admin_index_test idx").Check(testkit.Rows("2 <nil> 2", "3 <nil> 3"))
+	tk.MustQuery("admin recover index admin_index_test idx").Check(testkit.Rows("2 3"))
+	tk.MustQuery("admin check index admin_index_test idx").Check(testkit.Rows())
+	tk.MustExec("admin check table admin_index_test")
+	tk.MustQuery("select c1 from admin_index_test use index (idx) where c1 > 1").Check(testkit.Rows("2", "3"))
+}
+
 func (s *testSuite) fillData(tk *testkit.TestKit, table string) {
 	tk.MustExec("use test")
 	tk.MustExec(fmt.Sprintf("create table %s(id int not null default 1, name varchar(255), PRIMARY KEY(id));", table))
@@ -1643,6 +1747,35 @@ func (s *testSuite) TestTableScan(c *C) {
 	result.Check(testkit.Rows(rowStr1, rowStr2))
 }
 
+func (s *testSuite) TestSlowQueryLog(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	dir, err := ioutil.TempDir("", "slowlog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	c.Assert(slowlog.SetFile(filepath.Join(dir, "slow.log")), IsNil)
+	defer slowlog.SetFile("")
+
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists slow_query")
+	tk.MustExec("create table slow_query (a int, b int, index idx_a(a))")
+	tk.MustExec("insert slow_query values (1, 1), (2, 2), (3, 3)")
+	tk.MustExec("set @@session.tidb_slow_log_threshold = 100")
+	fastSQL := "select a from slow_query use index(idx_a) where a > 1"
+	tk.MustQuery(fastSQL).Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select count(*) from information_schema.slow_query").Check(testkit.Rows("0"))
+
+	tk.MustExec("set @@session.tidb_slow_log_threshold = 1")
+	slowSQL := "select a from slow_query use index(idx_a) where a > 1 and sleep(0.01) = 0"
+	tk.MustQuery(slowSQL).Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select db, index_names, cop_tasks, process_keys, digest, query_time >= 0.02, query from information_schema.slow_query where query like 'select a from slow_query%'").Check(testkit.Rows(
+		fmt.Sprintf("test slow_query:idx_a 1 2 %s 1 %s;", parser.DigestHash(slowSQL), slowSQL),
+	))
+}
+
 func (s *testSuite) TestAdapterStatement(c *C) {
 	defer testleak.AfterTest(c)()
 	se, err := tidb.CreateSession(s.store)
@@ -2067,6 +2200,168 @@ func (s *testSuite) TestSelectForUpdate(c *C) {
 	tk1.MustExec("commit")
 }
 
+// waitNotifyDetector sends the transaction it waits for to the channel when a transaction waits for a lock.
+// The waiting transaction checks the lock periodically, so the channel is unbuffered to drop the stale ones.
+type waitNotifyDetector struct {
+	*deadlock.Detector
+	waitFor chan uint64
+}
+
+func (d *waitNotifyDetector) Detect(sourceTxn, waitForTxn, keyHash uint64) error {
+	err := d.Detector.Detect(sourceTxn, waitForTxn, keyHash)
+	if err == nil {
+		select {
+		case d.waitFor <- waitForTxn:
+		default:
+		}
+	}
+	return err
+}
+
+// waitForLock returns after a transaction waits for the lock held by the transaction startTS.
+func (d *waitNotifyDetector) waitForLock(startTS uint64) {
+	for txn := range d.waitFor {
+		if txn == startTS {
+			return
+		}
+	}
+}
+
+func (s *testSuite) TestPessimisticTxn(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk1.MustExec("use test")
+
+	if !kv.SupportPessimisticTxn(s.store) {
+		_, err := tk.Exec("begin pessimistic")
+		c.Assert(terror.ErrorEqual(err, kv.ErrPessimisticTxnNotSupported), IsTrue)
+		_, err = tk.Exec("set @@tidb_txn_mode = 'pessimistic'")
+		c.Assert(terror.ErrorEqual(err, kv.ErrPessimisticTxnNotSupported), IsTrue)
+		return
+	}
+	type deadlockServiceSetter interface {
+		SetDeadlockService(svc deadlock.Service)
+	}
+	detector := &waitNotifyDetector{Detector: deadlock.NewDetector(), waitFor: make(chan uint64)}
+	s.store.(deadlockServiceSetter).SetDeadlockService(detector)
+	defer s.store.(deadlockServiceSetter).SetDeadlockService(deadlock.NewDetector())
+
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (id int primary key, v int, unique key uk(v))")
+	tk.MustExec("insert t values (1, 1), (2, 2), (3, 3)")
+
+	// The DML statements of a pessimistic transaction read the latest committed data.
+	tk.MustExec("begin pessimistic")
+	c.Assert(tk.Se.GetSessionVars().TxnCtx.IsPessimistic, IsTrue)
+	tk1.MustExec("update t set v = v + 10 where id = 1")
+	tk.MustExec("update t set v = v + 1 where id = 1")
+	tk.MustQuery("select v from t where id = 1").Check(testkit.Rows("12"))
+	tk.MustExec("commit")
+	tk1.MustQuery("select v from t where id = 1").Check(testkit.Rows("12"))
+
+	// The conflicting statement waits for the lock and is retried after the lock is released.
+	tk.MustExec("begin pessimistic")
+	tk.MustExec("update t set v = 20 where id = 2")
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := tk1.Exec("begin pessimistic")
+		if err == nil {
+			_, err = tk1.Exec("update t set v = v + 1 where id = 2")
+		}
+		if err == nil {
+			_, err = tk1.Exec("commit")
+		}
+		errCh <- err
+	}()
+	detector.waitForLock(tk.Se.Txn().StartTS())
+	tk.MustExec("commit")
+	c.Assert(<-errCh, IsNil)
+	tk.MustQuery("select v from t where id = 2").Check(testkit.Rows("21"))
+
+	// SELECT FOR UPDATE locks the rows.
+	tk.MustExec("begin pessimistic")
+	tk.MustQuery("select * from t where id = 1 for update").Check(testkit.Rows("1 12"))
+	go func() {
+		_, err := tk1.Exec("begin pessimistic")
+		if err == nil {
+			_, err = tk1.Exec("delete from t where id = 1")
+		}
+		if err == nil {
+			_, err = tk1.Exec("commit")
+		}
+		errCh <- err
+	}()
+	detector.waitForLock(tk.Se.Txn().StartTS())
+	tk.MustExec("update t set v = 13 where id = 1")
+	tk.MustExec("commit")
+	c.Assert(<-errCh, IsNil)
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 21", "3 3"))
+
+	// One of the transactions in a deadlock is aborted.
+	tk.MustExec("begin pessimistic")
+	tk.MustExec("update t set v = 30 where id = 2")
+	tk1.MustExec("begin pessimistic")
+	tk1.MustExec("update t set v = 40 where id = 3")
+	go func() {
+		_, err := tk.Exec("update t set v = 31 where id = 3")
+		errCh <- err
+	}()
+	detector.waitForLock(tk1.Se.Txn().StartTS())
+	_, err := tk1.Exec("update t set v = 41 where id = 2")
+	c.Assert(terror.ErrorEqual(err, kv.ErrDeadlock), IsTrue)
+	tk1.MustExec("rollback")
+	c.Assert(<-errCh, IsNil)
+	tk.MustExec("commit")
+	tk.MustQuery("select * from t").Check(testkit.Rows("2 30", "3 31"))
+
+	// The lock wait times out after innodb_lock_wait_timeout seconds.
+	tk.MustExec("begin pessimistic")
+	tk.MustQuery("select * from t where id = 2 for update").Check(testkit.Rows("2 30"))
+	tk1.MustExec("set innodb_lock_wait_timeout = 1")
+	tk1.MustExec("begin pessimistic")
+	start := time.Now()
+	_, err = tk1.Exec("update t set v = 50 where id = 2")
+	c.Assert(terror.ErrorEqual(err, kv.ErrLockWaitTimeout), IsTrue)
+	c.Assert(time.Since(start) >= time.Second, IsTrue)
+	tk1.MustExec("rollback")
+
+	// The lock wait stops when the statement is killed.
+	tk1.MustExec("set innodb_lock_wait_timeout = 50")
+	tk1.MustExec("begin pessimistic")
+	go func() {
+		_, err1 := tk1.Exec("update t set v = 50 where id = 2")
+		errCh <- err1
+	}()
+	detector.waitForLock(tk.Se.Txn().StartTS())
+	tk1.Se.Cancel()
+	c.Assert(terror.ErrorEqual(<-errCh, goctx.Canceled), IsTrue)
+	tk1.MustExec("rollback")
+	tk.MustExec("commit")
+
+	// The transaction mode can be set by the session variable and overridden by BEGIN.
+	tk.MustExec("set @@tidb_txn_mode = 'pessimistic'")
+	tk.MustExec("begin")
+	c.Assert(tk.Se.GetSessionVars().TxnCtx.IsPessimistic, IsTrue)
+	tk.MustExec("commit")
+	tk.MustExec("begin optimistic")
+	c.Assert(tk.Se.GetSessionVars().TxnCtx.IsPessimistic, IsFalse)
+	tk.MustExec("commit")
+	tk.MustExec("set @@tidb_txn_mode = 'OPTIMISTIC'")
+	tk.MustExec("begin")
+	c.Assert(tk.Se.GetSessionVars().TxnCtx.IsPessimistic, IsFalse)
+	tk.MustExec("commit")
+	_, err = tk.Exec("set @@tidb_txn_mode = 'pessimist'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = tk.Exec("set @@global.tidb_txn_mode = ''")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	tk.MustQuery("select @@tidb_txn_mode, @@global.tidb_txn_mode").Check(testkit.Rows("OPTIMISTIC optimistic"))
+}
+
 func (s *testSuite) TestFuncREPEAT(c *C) {
 	tk := testkit.NewTestKit(c, s.store)
 	defer func() {
@@ -2152,3 +2447,111 @@ func (s *testSuite) TestMiscellaneousBuiltin(c *C) {
 		}
 	}
 }
+
+func (s *testSuite) TestUserLock(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+
+	tk1 := testkit.NewTestKit(c, s.store)
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk1.MustQuery("select get_lock('l1', 1), get_lock('l1', 1), is_free_lock('l1')").Check(testkit.Rows("1 1 0"))
+	tk2.MustQuery("select get_lock('l1', 0.01), is_used_lock('l1') is null, is_free_lock('l2')").Check(testkit.Rows("0 0 1"))
+	tk2.MustQuery("select release_lock('l1'), release_lock('l2')").Check(testkit.Rows("0 <nil>"))
+	tk1.MustQuery("select release_lock('l1'), get_lock('l2', 0)").Check(testkit.Rows("1 1"))
+	tk1.MustQuery("select release_all_locks(), is_free_lock('l1')").Check(testkit.Rows("2 1"))
+	tk2.MustQuery("select get_lock('l1', -1)").Check(testkit.Rows("1"))
+
+	// The locks are released when the session is closed.
+	tk2.Se.Close()
+	tk1.MustQuery("select get_lock('l1', 0), release_lock('l1')").Check(testkit.Rows("1 1"))
+	rs, err := tk1.Exec("select get_lock(null, 1)")
+	c.Assert(err, IsNil)
+	_, err = rs.Next()
+	c.Assert(err, NotNil)
+	c.Assert(err.Error(), Matches, ".*Incorrect user-level lock name 'NULL'.*")
+}
+
+func (s *testSuite) TestOptimizerHints(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t(a int, b int, key idx_a(a))")
+	tk.MustExec("create table t1(a int, b int, key idx_a(a))")
+	tk.MustExec("insert into t values(1, 1), (2, 2), (2, 3), (3, 4)")
+	tk.MustExec("insert into t1 values(1, 2), (2, 3), (4, 5)")
+
+	rows := tk.MustQuery("explain select /*+ HASH_JOIN(t, t1) */ * from t join t1 on t.a = t1.a").Rows()
+	c.Assert(rows[len(rows)-1][0], Matches, "Hash.*Join.*")
+	tk.MustQuery("select /*+ HASH_JOIN(t, t1) */ t.a, t.b, t1.b from t join t1 on t.a = t1.a order by t.b").Check(testkit.Rows("1 1 2", "2 2 3", "2 3 3"))
+	tk.MustQuery("select /*+ STREAM_AGG() */ a, count(*), sum(b) from t group by a").Check(testkit.Rows("1 1 1", "2 2 5", "3 1 4"))
+	tk.MustQuery("select /*+ HASH_AGG() */ a, count(*), sum(b) from t group by a order by a").Check(testkit.Rows("1 1 1", "2 2 5", "3 1 4"))
+	tk.MustQuery("select /*+ USE_INDEX(t, idx_a) */ a from t where b > 1").Check(testkit.Rows("2", "2", "3"))
+	tk.MustQuery("select /*+ IGNORE_INDEX(t, idx_a) NO_AGG_PUSH_DOWN() */ count(*) from t where a > 1").Check(testkit.Rows("3"))
+	tk.MustQuery("select /*+ READ_FROM_STORAGE(TIKV[t]) MAX_EXECUTION_TIME(1000) */ count(*) from t").Check(testkit.Rows("4"))
+	tk.MustQuery("show warnings").Check(testkit.Rows())
+
+	// Unknown or inapplicable hints are ignored with warnings.
+	tk.MustQuery("select /*+ NO_SUCH_HINT() HASH_JOIN(t2) */ count(*) from t").Check(testkit.Rows("4"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1064|Optimizer hint NO_SUCH_HINT is inapplicable, the hint is unknown",
+		"Warning|3128|Unresolved name 't2' for hash_join hint"))
+	tk.MustQuery("select /*+ FOO(1, 'x') HASH_AGG(t,) */ count(*) from t").Check(testkit.Rows("4"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1064|Optimizer hint FOO is inapplicable, the arguments can't be recognized",
+		"Warning|1064|Optimizer hint HASH_AGG is inapplicable, the arguments can't be recognized"))
+	tk.MustQuery("select /*+ USE_INDEX(t, idx_b) STREAM_AGG() HASH_AGG() */ count(*) from t").Check(testkit.Rows("4"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|3126|Hint stream_agg is ignored as conflicting/duplicated",
+		"Warning|1064|Optimizer hint use_index is inapplicable, index idx_b doesn't exist in table t"))
+	tk.MustQuery("select (select /*+ MAX_EXECUTION_TIME(1000) */ count(*) from t1) from t where a = 1").Check(testkit.Rows("3"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1064|Optimizer hint MAX_EXECUTION_TIME is inapplicable, it's only supported in the top level SELECT"))
+	tk.MustQuery("select /*+ READ_FROM_STORAGE(TIFLASH[t]) */ count(*) from t").Check(testkit.Rows("4"))
+	tk.MustQuery("show warnings").Check(testutil.RowsWithSep("|",
+		"Warning|1064|Optimizer hint READ_FROM_STORAGE is inapplicable, storage TIFLASH is not supported"))
+}
+
+func (s *testSuite) TestMaxExecutionTime(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t(a int, b int)")
+	tk.MustExec("insert into t values(1, 1), (2, 2), (3, 3)")
+
+	checkTimeout := func(sql string) {
+		rs, err := tk.Exec(sql)
+		if err == nil {
+			_, err = tidb.GetRows(rs)
+		}
+		c.Assert(terror.ErrorEqual(err, executor.ErrQueryTimeout), IsTrue, Commentf("sql: %s, err: %v", sql, err))
+	}
+	checkTimeout("select /*+ MAX_EXECUTION_TIME(50) */ sleep(0.05), a from t")
+	tk.MustQuery("select /*+ MAX_EXECUTION_TIME(10000) */ sleep(0.05), a from t").Check(testkit.Rows("0 1", "0 2", "0 3"))
+
+	tk.MustExec("set @@max_execution_time = 50")
+	tk.MustQuery("select @@max_execution_time").Check(testkit.Rows("50"))
+	checkTimeout("select sleep(0.05), a from t")
+	checkTimeout("select sleep(0.05), a from t union all select 0, 4")
+	// The hint takes precedence over the variable.
+	tk.MustQuery("select /*+ MAX_EXECUTION_TIME(10000) */ sleep(0.05), a from t").Check(testkit.Rows("0 1", "0 2", "0 3"))
+	// The max execution time doesn't apply to the statements other than read-only SELECT.
+	tk.MustExec("begin")
+	tk.MustQuery("select sleep(0.05), a from t for update").Check(testkit.Rows("0 1", "0 2", "0 3"))
+	checkTimeout("select sleep(0.05), a from t")
+	// The transaction isn't affected by the canceled statement.
+	tk.MustExec("insert into t values(4, 4)")
+	tk.MustQuery("select a from t where b = 4").Check(testkit.Rows("4"))
+	tk.MustExec("commit")
+	tk.MustExec("set @@max_execution_time = 0")
+	tk.MustQuery("select sleep(0.05), a from t where a < 3").Check(testkit.Rows("0 1", "0 2"))
+}
@@ -15,8 +15,12 @@ package executor
 
 import (
 	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
 
 	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/types"
@@ -28,8 +32,13 @@ type ExplainExec struct {
 	baseExecutor
 
 	StmtPlan plan.Plan
+	Format   string
 	rows     []*Row
 	cursor   int
+
+	// analyzeExec and runtimeStats are only set for EXPLAIN ANALYZE.
+	analyzeExec  Executor
+	runtimeStats map[string]*runtimeStats
 }
 
 // Schema implements the Executor Schema interface.
@@ -59,12 +68,116 @@ func (e *ExplainExec) prepareExplainInfo(p plan.Plan, parent plan.Plan) error {
 	return nil
 }
 
+const (
+	taskTypeRoot = "root"
+	taskTypeCop  = "cop"
+)
+
+// prepareOperatorInfo renders p and its descendants as an indented operator tree, the
+// plans pushed down to coprocessor are rendered as the children of their reader.
+func (e *ExplainExec) prepareOperatorInfo(p plan.Plan, taskType string, indent string, isLastChild bool) {
+	id := p.ID()
+	childIndent := indent
+	if len(e.rows) > 0 {
+		if isLastChild {
+			id = indent + "└─" + id
+			childIndent = indent + "  "
+		} else {
+			id = indent + "├─" + id
+			childIndent = indent + "│ "
+		}
+	}
+	row := &Row{Data: types.MakeDatums(id, nil, taskType, plan.ExplainInfo(p))}
+	if count, ok := plan.EstimatedRowCount(p); ok {
+		row.Data[1].SetString(strconv.FormatFloat(count, 'f', 2, 64))
+	}
+	e.rows = append(e.rows, row)
+
+	children := p.Children()
+	childTaskType := taskType
+	switch x := p.(type) {
+	case *plan.PhysicalTableReader:
+		children = []plan.Plan{x.TablePlans[len(x.TablePlans)-1]}
+		childTaskType = taskTypeCop
+	case *plan.PhysicalIndexReader:
+		children = []plan.Plan{x.IndexPlans[len(x.IndexPlans)-1]}
+		childTaskType = taskTypeCop
+	case *plan.PhysicalIndexLookUpReader:
+		children = []plan.Plan{x.IndexPlans[len(x.IndexPlans)-1], x.TablePlans[len(x.TablePlans)-1]}
+		childTaskType = taskTypeCop
+	}
+	for i, child := range children {
+		e.prepareOperatorInfo(child, childTaskType, childIndent, i == len(children)-1)
+	}
+}
+
+// Open implements the Executor Open interface.
+// For EXPLAIN ANALYZE, the explained statement is executed here, so that write statements
+// are done before the transaction is committed.
+func (e *ExplainExec) Open() error {
+	if e.analyzeExec == nil {
+		return nil
+	}
+	return errors.Trace(e.runAnalyzeExec())
+}
+
+// runAnalyzeExec executes the explained statement to fill the runtime statistics.
+func (e *ExplainExec) runAnalyzeExec() error {
+	switch unwrapRuntimeStats(e.analyzeExec).(type) {
+	case *DeleteExec, *InsertExec, *UpdateExec, *ReplaceExec:
+		if e.ctx.GetSessionVars().SnapshotTS != 0 {
+			return errors.New("can not execute write statement when 'tidb_snapshot' is set")
+		}
+	}
+	err := e.analyzeExec.Open()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for {
+		row, err := e.analyzeExec.Next()
+		if err != nil {
+			e.analyzeExec.Close()
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+	}
+	return errors.Trace(e.analyzeExec.Close())
+}
+
+func (e *ExplainExec) prepareExplainAnalyzeInfo(p plan.Plan, parent plan.Plan) {
+	for _, child := range p.Children() {
+		e.prepareExplainAnalyzeInfo(child, p)
+	}
+	parentStr := ""
+	if parent != nil {
+		parentStr = parent.ID()
+	}
+	row := &Row{Data: types.MakeDatums(p.ID(), parentStr, nil, nil, nil, nil)}
+	if count, ok := plan.EstimatedRowCount(p); ok {
+		row.Data[2].SetFloat64(count)
+	}
+	if stats, ok := e.runtimeStats[p.ID()]; ok {
+		row.Data[3].SetInt64(atomic.LoadInt64(&stats.rows))
+		row.Data[4].SetInt64(atomic.LoadInt64(&stats.loops))
+		row.Data[5].SetString(time.Duration(atomic.LoadInt64(&stats.consume)).String())
+	}
+	e.rows = append(e.rows, row)
+}
+
 // Next implements Execution Next interface.
 func (e *ExplainExec) Next() (*Row, error) {
 	if e.cursor == 0 {
-		err := e.prepareExplainInfo(e.StmtPlan, nil)
-		if err != nil {
-			return nil, errors.Trace(err)
+		if e.analyzeExec != nil {
+			e.prepareExplainAnalyzeInfo(e.StmtPlan, nil)
+		} else if e.Format == ast.ExplainFormatRow {
+			e.prepareOperatorInfo(e.StmtPlan, taskTypeRoot, "", true)
+		} else {
+			err := e.prepareExplainInfo(e.StmtPlan, nil)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
 		}
 	}
 	if e.cursor >= len(e.rows) {
@@ -80,3 +193,44 @@ func (e *ExplainExec) Close() error {
 	e.rows = nil
 	return nil
 }
+
+// runtimeStats records the runtime statistics of an executor for EXPLAIN ANALYZE.
+type runtimeStats struct {
+	// rows is the number of rows returned by the executor.
+	rows int64
+	// loops is the number of times Next is called.
+	loops int64
+	// consume is the total wall time spent in Next, in nanoseconds.
+	consume int64
+}
+
+func (s *runtimeStats) record(d time.Duration, hasRow bool) {
+	atomic.AddInt64(&s.loops, 1)
+	atomic.AddInt64(&s.consume, int64(d))
+	if hasRow {
+		atomic.AddInt64(&s.rows, 1)
+	}
+}
+
+// runtimeStatsExec wraps an Executor and records its runtime statistics.
+type runtimeStatsExec struct {
+	Executor
+
+	stats *runtimeStats
+}
+
+// Next implements the Executor Next interface.
+func (e *runtimeStatsExec) Next() (*Row, error) {
+	start := time.Now()
+	row, err := e.Executor.Next()
+	e.stats.record(time.Since(start), row != nil)
+	return row, errors.Trace(err)
+}
+
+// unwrapRuntimeStats returns the executor wrapped by runtimeStatsExec, or e itself if it is not wrapped.
+func unwrapRuntimeStats(e Executor) Executor {
+	if x, ok := e.(*runtimeStatsExec); ok {
+		return x.Executor
+	}
+	return e
+}
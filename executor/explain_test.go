@@ -15,6 +15,7 @@ package executor_test
 
 import (
 	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/testleak"
 )
@@ -686,3 +687,63 @@ func (s *testSuite) TestExplain(c *C) {
 		result.Check(testkit.Rows(resultList...))
 	}
 }
+
+func (s *testSuite) TestExplainAnalyze(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (c1 int primary key, c2 int)")
+	tk.MustExec("insert into t values(1, 1), (2, 2), (3, 3)")
+
+	rows := tk.MustQuery("explain analyze select c2 from t where c2 > 1").Rows()
+	c.Assert(len(rows), Greater, 0)
+	// The root plan is the last row and has no parent.
+	root := rows[len(rows)-1]
+	c.Assert(root[1], Equals, "")
+	c.Assert(root[3], Equals, "2")
+	c.Assert(root[4], Equals, "3")
+	for _, row := range rows {
+		c.Assert(row[5], Not(Equals), "<nil>")
+	}
+
+	// EXPLAIN ANALYZE really executes the statement.
+	tk.MustQuery("explain analyze delete from t where c1 = 1")
+	tk.MustQuery("select c1 from t").Check(testkit.Rows("2", "3"))
+}
+
+func (s *testSuite) TestExplainRowFormat(c *C) {
+	tk := testkit.NewTestKit(c, s.store)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t1, t2")
+	tk.MustExec("create table t1 (c1 int primary key, c2 int, c3 int, index c2 (c2))")
+	tk.MustExec("create table t2 (c1 int unique, c2 int)")
+
+	tk.MustQuery("explain format = 'row' select * from t1 where c1 > 1").Check(testkit.Rows(
+		"TableReader_5 3333333.33 root ",
+		"└─TableScan_4 3333333.33 cop table:t1, range:[[2,+inf)], keep order:false",
+	))
+	tk.MustQuery("explain format = 'row' select * from t1 where c2 = 1").Check(testkit.Rows(
+		"IndexLookUp_9 10000.00 root ",
+		"├─IndexScan_7 10000.00 cop table:t1, index:c2, range:[[1,1]], out of order:true",
+		"└─TableScan_8 10000.00 cop table:t1, keep order:false",
+	))
+	tk.MustQuery("explain format = 'row' select c2 from t1 where c2 > 1 order by c2 limit 1").Check(testkit.Rows(
+		"Limit_28 1.00 root offset:0, count:1",
+		"└─IndexReader_30 1.00 root ",
+		"  └─Limit_29 1.00 cop offset:0, count:1",
+		"    └─IndexScan_27 3333333.33 cop table:t1, index:c2, range:[(1,+inf]], out of order:false",
+	))
+	rows := tk.MustQuery("explain format = 'json' select * from t1 where c1 > 1").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0], HasLen, 3)
+	_, err := tk.Exec("explain format = 'text' select * from t1")
+	c.Assert(plan.ErrUnknownExplainFormat.Equal(err), IsTrue)
+}
@@ -45,6 +45,7 @@ type GrantExec struct {
 	ObjectType ast.ObjectTypeType
 	Level      *ast.GrantLevel
 	Users      []*ast.UserSpec
+	Require    ast.RequireType
 	WithGrant  bool
 
 	ctx  context.Context
@@ -91,6 +92,14 @@ func (e *GrantExec) Next() (*Row, error) {
 				return nil, errors.Trace(err)
 			}
 		}
+		if e.Require != ast.RequireNotSpecified {
+			sql := fmt.Sprintf(`UPDATE %s.%s SET ssl_type = "%s" WHERE Host = "%s" and User = "%s";`,
+				mysql.SystemDB, mysql.UserTable, sslType(e.Require), host, userName)
+			_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
 
 		// If there is no privilege entry in corresponding table, insert a new one.
 		// DB scope:		mysql.DB
@@ -0,0 +1,235 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/ddl"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testSuite) TestPartitionTable(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, h")
+	tk.MustExec(`create table t (id int, month int, v int, key idx_v (v)) partition by range (month) (
+		partition p1 values less than (2),
+		partition p2 values less than (3),
+		partition p3 values less than (4))`)
+	tk.MustExec("insert into t values (1, 1, 10), (2, 2, 20), (3, 3, 30), (4, 1, 40), (5, null, 50)")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("1", "2", "3", "4", "5"))
+	tk.MustQuery("select id from t where month = 1 order by id").Check(testkit.Rows("1", "4"))
+	tk.MustQuery("select id from t where month >= 2 order by id").Check(testkit.Rows("2", "3"))
+	tk.MustQuery("select id from t where month is null").Check(testkit.Rows("5"))
+	tk.MustQuery("select id from t where month > 5").Check(testkit.Rows())
+	tk.MustQuery("select id from t where v = 30").Check(testkit.Rows("3"))
+	tk.MustQuery("select month, count(*) from t group by month order by month").Check(testkit.Rows("<nil> 1", "1 2", "2 1", "3 1"))
+	c.Assert(countTableReaders(tk, "select * from t"), Equals, 3)
+	c.Assert(countTableReaders(tk, "select * from t where month = 2"), Equals, 1)
+	c.Assert(countTableReaders(tk, "select * from t where month < 3"), Equals, 2)
+	_, err := tk.Exec("insert into t values (6, 4, 60)")
+	c.Assert(terror.ErrorEqual(err, table.ErrNoPartitionForGivenValue), IsTrue)
+
+	// The row moves to another partition when the partition column is updated.
+	tk.MustExec("update t set month = 3 where id = 1")
+	tk.MustQuery("select id from t where month = 3 order by id").Check(testkit.Rows("1", "3"))
+	tk.MustQuery("select id from t where month = 1").Check(testkit.Rows("4"))
+	tk.MustExec("delete from t where month = 3")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2", "4", "5"))
+
+	// The uncommitted rows are read from the partitions they belong to.
+	tk.MustExec("begin")
+	tk.MustExec("insert into t values (7, 3, 70)")
+	tk.MustExec("update t set month = 1 where id = 2")
+	tk.MustQuery("select id from t where month = 1 order by id").Check(testkit.Rows("2", "4"))
+	tk.MustQuery("select id from t where month = 3").Check(testkit.Rows("7"))
+	tk.MustExec("rollback")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2", "4", "5"))
+
+	tk.MustExec("create index idx_id on t (id)")
+	tk.MustQuery("select month from t use index (idx_id) where id = 2").Check(testkit.Rows("2"))
+
+	// Drop the partition of the oldest month and add a new one.
+	tk.MustExec("alter table t drop partition p1")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2"))
+	tk.MustExec("alter table t add partition (partition p4 values less than (5))")
+	tk.MustExec("insert into t values (6, 4, 60)")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2", "6"))
+	tk.MustExec("alter table t truncate partition p4")
+	tk.MustQuery("select id from t order by id").Check(testkit.Rows("2"))
+	tk.MustExec("alter table t add partition (partition pmax values less than maxvalue)")
+	tk.MustExec("insert into t values (8, 100, 80)")
+	tk.MustQuery("select id from t where month > 10").Check(testkit.Rows("8"))
+	tk.MustExec("truncate table t")
+	tk.MustQuery("select count(*) from t").Check(testkit.Rows("0"))
+
+	tk.MustExec("create table h (id int, v int) partition by hash (id) partitions 4")
+	tk.MustExec("insert into h values (1, 1), (2, 2), (3, 3), (4, 4), (-5, 5), (null, 6)")
+	tk.MustQuery("select v from h order by v").Check(testkit.Rows("1", "2", "3", "4", "5", "6"))
+	tk.MustQuery("select v from h where id = -5").Check(testkit.Rows("5"))
+	tk.MustQuery("select v from h where id in (1, 2)").Sort().Check(testkit.Rows("1", "2"))
+	c.Assert(countTableReaders(tk, "select * from h where id = 3"), Equals, 1)
+	c.Assert(countTableReaders(tk, "select * from h where id > 3"), Equals, 4)
+	tk.MustExec("update h set id = 6 where v = 1")
+	tk.MustQuery("select v from h where id = 6").Check(testkit.Rows("1"))
+	tk.MustExec("alter table h truncate partition p2")
+	tk.MustQuery("select v from h order by v").Check(testkit.Rows("3", "4", "5", "6"))
+
+	_, err = tk.Exec("create table t1 (a int) partition by range (a) (partition p0 values less than (2), partition p1 values less than (1))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrRangeNotIncreasing), IsTrue)
+	_, err = tk.Exec("create table t1 (a int, b int, unique key (b)) partition by hash (a) partitions 2")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue)
+	_, err = tk.Exec("create table t1 (a int) partition by range (a) (partition p0 values less than (1), partition p0 values less than (2))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrSameNamePartition), IsTrue)
+	_, err = tk.Exec("alter table h add unique index idx_v (v)")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue)
+	_, err = tk.Exec("create unique index idx_v on h (v)")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrUniqueKeyNeedAllFieldsInPf), IsTrue)
+	tk.MustExec("create unique index idx_id_v on h (v, id)")
+	_, err = tk.Exec("insert into h values (3, 3)")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("alter table h add partition (partition p4 values less than (1))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrOnlyOnRangeListPartition), IsTrue)
+	_, err = tk.Exec("alter table t add partition (partition p5 values less than (6))")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMaxvalue), IsTrue)
+	_, err = tk.Exec("alter table t drop partition p9")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrDropPartitionNonExistent), IsTrue)
+	_, err = tk.Exec("alter table t drop partition p2, p3, p4, pmax")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrDropLastPartition), IsTrue)
+	tk.MustExec("drop table if exists t1")
+	tk.MustExec("create table t1 (a int)")
+	_, err = tk.Exec("alter table t1 drop partition p0")
+	c.Assert(terror.ErrorEqual(err, ddl.ErrPartitionMgmtOnNonpartitioned), IsTrue)
+	_, err = tk.Exec("analyze table t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNotSupportedYet), IsTrue)
+}
+
+// countTableReaders returns the number of the table readers in the plan of the query.
+func countTableReaders(tk *testkit.TestKit, sql string) int {
+	count := 0
+	for _, row := range tk.MustQuery("explain " + sql).Rows() {
+		if strings.HasPrefix(fmt.Sprintf("%s", row[0]), "TableReader_") {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *testSuite) TestShowCreatePartitionTable(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, h, h1")
+	tk.MustExec(`create table t (id int, month int) partition by range (month) (
+		partition p1 values less than (2),
+		partition p2 values less than (3),
+		partition pmax values less than maxvalue)`)
+	createSQL := strings.Join([]string{
+		"CREATE TABLE `t` (",
+		"  `id` int(11) DEFAULT NULL,",
+		"  `month` int(11) DEFAULT NULL",
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin",
+		"PARTITION BY RANGE (month) (",
+		"  PARTITION `p1` VALUES LESS THAN (2),",
+		"  PARTITION `p2` VALUES LESS THAN (3),",
+		"  PARTITION `pmax` VALUES LESS THAN MAXVALUE",
+		")",
+	}, "\n")
+	tk.MustQuery("show create table t").Check(testkit.Rows("t " + createSQL))
+	// The output of SHOW CREATE TABLE creates the same table.
+	tk.MustExec("drop table t")
+	tk.MustExec(createSQL)
+	tk.MustQuery("show create table t").Check(testkit.Rows("t " + createSQL))
+
+	tk.MustExec("create table h (id int) partition by hash (id) partitions 2")
+	tk.MustQuery("show create table h").Check(testkit.Rows("h CREATE TABLE `h` (\n" +
+		"  `id` int(11) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin\n" +
+		"PARTITION BY HASH (id)\n" +
+		"PARTITIONS 2"))
+	tk.MustExec("create table h1 (id int) partition by hash (id) (partition a, partition b)")
+	tk.MustQuery("show create table h1").Check(testkit.Rows("h1 CREATE TABLE `h1` (\n" +
+		"  `id` int(11) DEFAULT NULL\n" +
+		") ENGINE=InnoDB DEFAULT CHARSET=utf8 COLLATE=utf8_bin\n" +
+		"PARTITION BY HASH (id) (\n" +
+		"  PARTITION `a`,\n" +
+		"  PARTITION `b`\n" +
+		")"))
+
+	// The scans of EXPLAIN are labeled with the partitions they read.
+	var infos []string
+	for _, row := range tk.MustQuery("explain format = 'row' select * from t where month < 3").Rows() {
+		if info := fmt.Sprintf("%s", row[len(row)-1]); strings.Contains(info, "table:t") {
+			infos = append(infos, info)
+		}
+	}
+	c.Assert(infos, HasLen, 2)
+	c.Assert(infos[0], Matches, "table:t, partition:p1, .*")
+	c.Assert(infos[1], Matches, "table:t, partition:p2, .*")
+}
+
+func (s *testSuite) TestAdminIndexPartitionTable(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists admin_partition_test")
+	tk.MustExec("create table admin_partition_test (id int primary key, v int, index idx_v (v)) partition by hash (id) partitions 2")
+	tk.MustExec("insert admin_partition_test values (1, 1), (2, 2), (3, 3), (4, 4)")
+	tk.MustQuery("admin check index admin_partition_test idx_v").Check(testkit.Rows())
+	tk.MustQuery("admin recover index admin_partition_test idx_v").Check(testkit.Rows("0 4"))
+	tk.MustQuery("admin cleanup index admin_partition_test idx_v").Check(testkit.Rows("0 4"))
+
+	// Make the index of the partitions inconsistent with the records:
+	// p0 index data (handle, v): (2, 2), (4, 4), (6, 6)
+	// p1 index data (handle, v): (1, 1)
+	is := sessionctx.GetDomain(tk.Se.(context.Context)).InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("admin_partition_test"))
+	c.Assert(err, IsNil)
+	defs := tb.Meta().Partition.Definitions
+	p0 := tb.(table.PartitionedTable).GetPartition(defs[0].ID)
+	p1 := tb.(table.PartitionedTable).GetPartition(defs[1].ID)
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	_, err = p0.Indices()[0].Create(txn, types.MakeDatums(int64(6)), 6)
+	c.Assert(err, IsNil)
+	c.Assert(p1.Indices()[0].Delete(txn, types.MakeDatums(int64(3)), 3), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+
+	tk.MustQuery("admin check index admin_partition_test idx_v").Check(testkit.Rows("6 6 <nil>", "3 <nil> 3"))
+	tk.MustQuery("admin check index admin_partition_test idx_v (3, 5)").Check(testkit.Rows("3 <nil> 3"))
+	tk.MustQuery("admin cleanup index admin_partition_test idx_v").Check(testkit.Rows("1 4"))
+	tk.MustQuery("admin recover index admin_partition_test idx_v").Check(testkit.Rows("1 4"))
+	tk.MustQuery("admin check index admin_partition_test idx_v").Check(testkit.Rows())
+}
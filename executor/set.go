@@ -114,6 +114,14 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			err = varsutil.ValidateSystemVar(name, svalue)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			err = e.checkStoreSupport(name, svalue)
+			if err != nil {
+				return errors.Trace(err)
+			}
 			err = sessionVars.GlobalVarsAccessor.SetGlobalSysVar(name, svalue)
 			if err != nil {
 				return errors.Trace(err)
@@ -127,6 +135,16 @@ func (e *SetExecutor) executeSet() error {
 			if err != nil {
 				return errors.Trace(err)
 			}
+			if !value.IsNull() {
+				svalue, err1 := value.ToString()
+				if err1 != nil {
+					return errors.Trace(err1)
+				}
+				err = e.checkStoreSupport(name, svalue)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
 			oldSnapshotTS := sessionVars.SnapshotTS
 			err = varsutil.SetSessionSystemVar(sessionVars, name, value)
 			if err != nil {
@@ -154,6 +172,22 @@ func (e *SetExecutor) executeSet() error {
 	return nil
 }
 
+// checkStoreSupport checks that the store supports the kind of transactions enabled by the variable.
+func (e *SetExecutor) checkStoreSupport(name string, value string) error {
+	store := sessionctx.GetDomain(e.ctx).Store()
+	switch name {
+	case variable.TiDBTxnMode:
+		if strings.EqualFold(value, variable.PessimisticTxnMode) && !kv.SupportPessimisticTxn(store) {
+			return kv.ErrPessimisticTxnNotSupported
+		}
+	case variable.TiDBLargeTxn:
+		if (strings.EqualFold(value, "ON") || value == "1") && !kv.SupportLargeTxn(store) {
+			return kv.ErrLargeTxnNotSupported
+		}
+	}
+	return nil
+}
+
 // validateSnapshot checks that the newly set snapshot time is after GC safe point time.
 func validateSnapshot(ctx context.Context, snapshotTS uint64) error {
 	sql := "SELECT variable_value FROM mysql.tidb WHERE variable_name = 'tikv_gc_safe_point'"
@@ -22,10 +22,13 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/bindinfo"
+	"github.com/pingcap/tidb/ddl"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
@@ -46,7 +49,7 @@ type ShowExec struct {
 	Full   bool
 	User   string // Used for show grants.
 
-	// GlobalScope is used by show variables
+	// GlobalScope is used by show variables and show bindings.
 	GlobalScope bool
 
 	is infoschema.InfoSchema
@@ -82,8 +85,12 @@ func (e *ShowExec) fetchAll() error {
 		return e.fetchShowColumns()
 	case ast.ShowCreateTable:
 		return e.fetchShowCreateTable()
+	case ast.ShowCreateView:
+		return e.fetchShowCreateView()
 	case ast.ShowCreateDatabase:
 		return e.fetchShowCreateDatabase()
+	case ast.ShowBindings:
+		return e.fetchShowBindings()
 	case ast.ShowDatabases:
 		return e.fetchShowDatabases()
 	case ast.ShowEngines:
@@ -178,6 +185,31 @@ func (e *ShowExec) fetchShowProcessList() error {
 	return nil
 }
 
+func (e *ShowExec) fetchShowBindings() error {
+	var records []*bindinfo.BindRecord
+	if e.GlobalScope {
+		records = sessionctx.GetDomain(e.ctx).BindHandle().GetAllBindRecords()
+	} else if h := bindinfo.GetSessionHandle(e.ctx); h != nil {
+		records = h.GetAllBindRecords()
+	}
+	for _, record := range records {
+		row := &Row{
+			Data: types.MakeDatums(
+				record.OriginalSQL,
+				record.BindSQL,
+				record.Db,
+				record.Status,
+				record.CreateTime,
+				record.UpdateTime,
+				record.Charset,
+				record.Collation,
+			),
+		}
+		e.rows = append(e.rows, row)
+	}
+	return nil
+}
+
 func (e *ShowExec) fetchShowTables() error {
 	if !e.is.SchemaExists(e.DBName) {
 		return errors.Errorf("Can not find DB: %s", e.DBName)
@@ -185,6 +217,7 @@ func (e *ShowExec) fetchShowTables() error {
 	checker := privilege.GetPrivilegeManager(e.ctx)
 	// sort for tables
 	var tableNames []string
+	tableTypes := make(map[string]string)
 	for _, v := range e.is.SchemaTables(e.DBName) {
 		// Test with mysql.AllPrivMask means any privilege would be OK.
 		// TODO: Should consider column privileges, which also make a table visible.
@@ -192,14 +225,17 @@ func (e *ShowExec) fetchShowTables() error {
 			continue
 		}
 		tableNames = append(tableNames, v.Meta().Name.O)
+		if v.Meta().IsView() {
+			tableTypes[v.Meta().Name.O] = "VIEW"
+		} else {
+			tableTypes[v.Meta().Name.O] = "BASE TABLE"
+		}
 	}
 	sort.Strings(tableNames)
 	for _, v := range tableNames {
 		data := types.MakeDatums(v)
 		if e.Full {
-			// TODO: support "VIEW" later if we have supported view feature.
-			// now, just use "BASE TABLE".
-			data = append(data, types.NewDatum("BASE TABLE"))
+			data = append(data, types.NewDatum(tableTypes[v]))
 		}
 		e.rows = append(e.rows, &Row{Data: data})
 	}
@@ -367,7 +403,7 @@ func (e *ShowExec) fetchShowVariables() error {
 }
 
 func (e *ShowExec) fetchShowStatus() error {
-	statusVars, err := variable.GetStatusVars()
+	statusVars, err := variable.GetStatusVars(e.ctx.GetSessionVars())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -389,11 +425,50 @@ func (e *ShowExec) fetchShowStatus() error {
 	return nil
 }
 
+// appendPartitionInfo appends the PARTITION BY clause of the table to buf.
+func appendPartitionInfo(pi *model.PartitionInfo, buf *bytes.Buffer) {
+	buf.WriteString(fmt.Sprintf("\nPARTITION BY %s (%s)", pi.Type, pi.Expr))
+	if pi.Type == model.PartitionTypeHash {
+		defaultNames := true
+		for i, def := range pi.Definitions {
+			if def.Name.L != fmt.Sprintf("p%d", i) {
+				defaultNames = false
+				break
+			}
+		}
+		// The partitions created by PARTITIONS num are named p0, p1 and so on.
+		if defaultNames {
+			buf.WriteString(fmt.Sprintf("\nPARTITIONS %d", len(pi.Definitions)))
+			return
+		}
+	}
+	buf.WriteString(" (\n")
+	for i, def := range pi.Definitions {
+		buf.WriteString(fmt.Sprintf("  PARTITION `%s`", def.Name.O))
+		if len(def.LessThan) > 0 {
+			if def.LessThan[0] == "MAXVALUE" {
+				buf.WriteString(" VALUES LESS THAN MAXVALUE")
+			} else {
+				buf.WriteString(fmt.Sprintf(" VALUES LESS THAN (%s)", strings.Join(def.LessThan, ",")))
+			}
+		}
+		if i != len(pi.Definitions)-1 {
+			buf.WriteString(",\n")
+		}
+	}
+	buf.WriteString("\n)")
+}
+
 func (e *ShowExec) fetchShowCreateTable() error {
 	tb, err := e.getTable()
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if tb.Meta().IsView() {
+		data := types.MakeDatums(tb.Meta().Name.O, composeCreateView(tb.Meta()))
+		e.rows = append(e.rows, &Row{Data: data})
+		return nil
+	}
 
 	// TODO: let the result more like MySQL.
 	var buf bytes.Buffer
@@ -533,11 +608,48 @@ func (e *ShowExec) fetchShowCreateTable() error {
 		buf.WriteString(fmt.Sprintf(" COMMENT='%s'", tb.Meta().Comment))
 	}
 
+	if tb.Meta().Partition != nil {
+		appendPartitionInfo(tb.Meta().Partition, &buf)
+	}
+
 	data := types.MakeDatums(tb.Meta().Name.O, buf.String())
 	e.rows = append(e.rows, &Row{Data: data})
 	return nil
 }
 
+// fetchShowCreateView composes show create view result.
+func (e *ShowExec) fetchShowCreateView() error {
+	tb, err := e.getTable()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !tb.Meta().IsView() {
+		return ddl.ErrWrongObject.GenByArgs(e.Table.Schema.O, tb.Meta().Name.O, "VIEW")
+	}
+	data := types.MakeDatums(tb.Meta().Name.O, composeCreateView(tb.Meta()), charset.CharsetUTF8, charset.CollationUTF8)
+	e.rows = append(e.rows, &Row{Data: data})
+	return nil
+}
+
+func composeCreateView(tblInfo *model.TableInfo) string {
+	var buf bytes.Buffer
+	buf.WriteString("CREATE ALGORITHM=UNDEFINED DEFINER=")
+	if idx := strings.LastIndex(tblInfo.View.Definer, "@"); idx >= 0 {
+		fmt.Fprintf(&buf, "`%s`@`%s`", tblInfo.View.Definer[:idx], tblInfo.View.Definer[idx+1:])
+	} else {
+		buf.WriteString("CURRENT_USER")
+	}
+	fmt.Fprintf(&buf, " SQL SECURITY DEFINER VIEW `%s` (", tblInfo.Name.O)
+	for i, col := range tblInfo.View.Cols {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "`%s`", col.O)
+	}
+	fmt.Fprintf(&buf, ") AS %s", tblInfo.View.SelectStmt)
+	return buf.String()
+}
+
 // fetchShowCreateDatabase composes show create database result.
 func (e *ShowExec) fetchShowCreateDatabase() error {
 	db, ok := e.is.SchemaByName(e.DBName)
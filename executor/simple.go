@@ -20,10 +20,13 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/bindinfo"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/terror"
@@ -76,6 +79,10 @@ func (e *SimpleExec) Next() (*Row, error) {
 		return nil, nil
 	case *ast.DropStatsStmt:
 		err = e.executeDropStats(x)
+	case *ast.CreateBindingStmt:
+		err = e.executeCreateBinding(x)
+	case *ast.DropBindingStmt:
+		err = e.executeDropBinding(x)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -110,6 +117,18 @@ func (e *SimpleExec) executeBegin(s *ast.BeginStmt) error {
 			return errors.Trace(err)
 		}
 	}
+	switch s.Mode {
+	case ast.PessimisticTxnMode:
+		txnCtx.IsPessimistic = true
+	case ast.OptimisticTxnMode:
+		txnCtx.IsPessimistic = false
+	default:
+		txnCtx.IsPessimistic = e.ctx.GetSessionVars().TxnMode == variable.PessimisticTxnMode
+	}
+	if txnCtx.IsPessimistic && !kv.SupportPessimisticTxn(sessionctx.GetDomain(e.ctx).Store()) {
+		txnCtx.IsPessimistic = false
+		return kv.ErrPessimisticTxnNotSupported
+	}
 	// With START TRANSACTION, autocommit remains disabled until you end
 	// the transaction with COMMIT or ROLLBACK. The autocommit mode then
 	// reverts to its previous state.
@@ -153,13 +172,13 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 				pwd = util.EncodePassword(spec.AuthOpt.HashString)
 			}
 		}
-		user := fmt.Sprintf(`("%s", "%s", "%s")`, host, userName, pwd)
+		user := fmt.Sprintf(`("%s", "%s", "%s", "%s")`, host, userName, pwd, sslType(s.Require))
 		users = append(users, user)
 	}
 	if len(users) == 0 {
 		return nil
 	}
-	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password) VALUES %s;`, mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
+	sql := fmt.Sprintf(`INSERT INTO %s.%s (Host, User, Password, ssl_type) VALUES %s;`, mysql.SystemDB, mysql.UserTable, strings.Join(users, ", "))
 	_, err := e.ctx.(sqlexec.SQLExecutor).Execute(sql)
 	if err != nil {
 		return errors.Trace(err)
@@ -168,6 +187,17 @@ func (e *SimpleExec) executeCreateUser(s *ast.CreateUserStmt) error {
 	return errors.Trace(err)
 }
 
+// sslType returns the value of the ssl_type column in mysql.user for the REQUIRE clause.
+func sslType(require ast.RequireType) string {
+	switch require {
+	case ast.RequireSSL:
+		return "ANY"
+	case ast.RequireX509:
+		return "X509"
+	}
+	return ""
+}
+
 func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 	if s.CurrentAuth != nil {
 		user := e.ctx.GetSessionVars().User
@@ -195,16 +225,24 @@ func (e *SimpleExec) executeAlterUser(s *ast.AlterUserStmt) error {
 			}
 			continue
 		}
-		pwd := ""
-		if spec.AuthOpt != nil {
-			if spec.AuthOpt.ByAuthString {
-				pwd = util.EncodePassword(spec.AuthOpt.AuthString)
-			} else {
-				pwd = util.EncodePassword(spec.AuthOpt.HashString)
+		var assignments []string
+		// The password is kept if the statement only changes the REQUIRE option.
+		if spec.AuthOpt != nil || s.Require == ast.RequireNotSpecified {
+			pwd := ""
+			if spec.AuthOpt != nil {
+				if spec.AuthOpt.ByAuthString {
+					pwd = util.EncodePassword(spec.AuthOpt.AuthString)
+				} else {
+					pwd = util.EncodePassword(spec.AuthOpt.HashString)
+				}
 			}
+			assignments = append(assignments, fmt.Sprintf(`Password = "%s"`, pwd))
+		}
+		if s.Require != ast.RequireNotSpecified {
+			assignments = append(assignments, fmt.Sprintf(`ssl_type = "%s"`, sslType(s.Require)))
 		}
-		sql := fmt.Sprintf(`UPDATE %s.%s SET Password = "%s" WHERE Host = "%s" and User = "%s";`,
-			mysql.SystemDB, mysql.UserTable, pwd, host, userName)
+		sql := fmt.Sprintf(`UPDATE %s.%s SET %s WHERE Host = "%s" and User = "%s";`,
+			mysql.SystemDB, mysql.UserTable, strings.Join(assignments, ", "), host, userName)
 		_, _, err = e.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(e.ctx, sql)
 		if err != nil {
 			failedUsers = append(failedUsers, spec.User)
@@ -327,3 +365,50 @@ func (e *SimpleExec) executeDropStats(s *ast.DropStatsStmt) error {
 	h := sessionctx.GetDomain(e.ctx).StatsHandle()
 	return errors.Trace(h.DeleteTableStatsFromKV(s.Table.TableInfo.ID))
 }
+
+func (e *SimpleExec) executeCreateBinding(s *ast.CreateBindingStmt) error {
+	sessVars := e.ctx.GetSessionVars()
+	charset, collation := sessVars.GetCharsetInfo()
+	record, err := bindinfo.NewBindRecord(s.OriginSel.Text(), s.HintedSel.Text(), sessVars.CurrentDB, charset, collation)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !s.GlobalScope {
+		bindinfo.GetSessionHandle(e.ctx).AddBindRecord(record)
+		return nil
+	}
+	// The global binding is written in a system session, so it isn't a part of the current transaction.
+	sysSessionPool := sessionctx.GetDomain(e.ctx).SysSessionPool()
+	ctx, err := sysSessionPool.Get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer sysSessionPool.Put(ctx)
+	err = sessionctx.GetDomain(e.ctx).BindHandle().AddBindRecord(ctx.(context.Context), record)
+	return errors.Trace(err)
+}
+
+func (e *SimpleExec) executeDropBinding(s *ast.DropBindingStmt) error {
+	normalized := parser.Normalize(s.OriginSel.Text())
+	db := e.ctx.GetSessionVars().CurrentDB
+	if !s.GlobalScope {
+		if !bindinfo.GetSessionHandle(e.ctx).DropBindRecord(normalized, db) {
+			return ErrBindingNotExists
+		}
+		return nil
+	}
+	sysSessionPool := sessionctx.GetDomain(e.ctx).SysSessionPool()
+	ctx, err := sysSessionPool.Get()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer sysSessionPool.Put(ctx)
+	dropped, err := sessionctx.GetDomain(e.ctx).BindHandle().DropBindRecord(ctx.(context.Context), normalized, db)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if !dropped {
+		return ErrBindingNotExists
+	}
+	return nil
+}
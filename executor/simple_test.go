@@ -16,6 +16,7 @@ package executor_test
 import (
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
+	"github.com/pingcap/tidb/bindinfo"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/model"
@@ -175,6 +176,24 @@ func (s *testSuite) TestUser(c *C) {
 	tk.MustExec(dropUserSQL)
 }
 
+func (s *testSuite) TestUserRequireSSL(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec(`CREATE USER 'ssl1'@'localhost' IDENTIFIED BY '123' REQUIRE SSL`)
+	tk.MustExec(`CREATE USER 'ssl2'@'localhost'`)
+	tk.MustQuery(`SELECT User, ssl_type FROM mysql.User WHERE User like "ssl%" order by User`).Check(testkit.Rows("ssl1 ANY", "ssl2 "))
+	// The password is kept if only the REQUIRE option is changed.
+	tk.MustExec(`ALTER USER 'ssl1'@'localhost' REQUIRE X509`)
+	tk.MustQuery(`SELECT Password, ssl_type FROM mysql.User WHERE User="ssl1"`).Check(testkit.Rows(util.EncodePassword("123") + " X509"))
+	tk.MustExec(`ALTER USER 'ssl1'@'localhost' IDENTIFIED BY '456' REQUIRE NONE`)
+	tk.MustQuery(`SELECT Password, ssl_type FROM mysql.User WHERE User="ssl1"`).Check(testkit.Rows(util.EncodePassword("456") + " "))
+	tk.MustExec(`ALTER USER 'ssl1'@'localhost' IDENTIFIED BY '123'`)
+	tk.MustQuery(`SELECT ssl_type FROM mysql.User WHERE User="ssl1"`).Check(testkit.Rows(""))
+	tk.MustExec(`GRANT SELECT ON *.* TO 'ssl2'@'localhost' REQUIRE SSL`)
+	tk.MustQuery(`SELECT Select_priv, ssl_type FROM mysql.User WHERE User="ssl2"`).Check(testkit.Rows("Y ANY"))
+	tk.MustExec(`DROP USER 'ssl1'@'localhost', 'ssl2'@'localhost'`)
+}
+
 func (s *testSuite) TestSetPwd(c *C) {
 	defer testleak.AfterTest(c)()
 	tk := testkit.NewTestKit(c, s.store)
@@ -258,3 +277,80 @@ func (s *testSuite) TestDropStats(c *C) {
 	statsTbl = h.GetTableStats(tableInfo.ID)
 	c.Assert(statsTbl.Pseudo, IsTrue)
 }
+
+func (s *testSuite) TestBinding(c *C) {
+	defer testleak.AfterTest(c)()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t, t1")
+	tk.MustExec("create table t(a int, b int, key(a))")
+	tk.MustExec("create table t1(a int, b int, key(a))")
+	tk.MustExec("insert into t values(1, 1), (2, 2)")
+	tk.MustExec("insert into t1 values(1, 1), (3, 3)")
+
+	joinOf := func(tk *testkit.TestKit, sql string) string {
+		rows := tk.MustQuery("explain " + sql).Rows()
+		return rows[len(rows)-1][0].(string)
+	}
+	sql := "select * from t join t1 on t.a = t1.a where t.b > 0"
+	c.Assert(joinOf(tk, sql), Matches, "IndexJoin.*")
+
+	// The bind sql must be the same as the original sql except the hints.
+	_, err := tk.Exec("create binding for select * from t using select /*+ TIDB_SMJ(t, t1) */ * from t1")
+	c.Assert(err, NotNil)
+
+	tk.MustExec("create session binding for select * from t join t1 on t.a = t1.a where t.b > 1 " +
+		"using select /*+ TIDB_SMJ(t, t1) */ * from t join t1 on t.a = t1.a where t.b > 1")
+	c.Assert(joinOf(tk, sql), Matches, "MergeJoin.*")
+	tk.MustQuery(sql).Check(testkit.Rows("1 1 1 1"))
+	rows := tk.MustQuery("show session bindings").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][:4], DeepEquals, []interface{}{"select * from t join t1 on t . a = t1 . a where t . b > ?",
+		"select /*+ TIDB_SMJ(t, t1) */ * from t join t1 on t.a = t1.a where t.b > 1", "test", bindinfo.Using})
+	tk.MustQuery("show global bindings").Check(testkit.Rows())
+
+	// The session binding isn't used in the other databases and sessions.
+	tk.MustExec("create database bind_db")
+	tk.MustExec("use bind_db")
+	tk.MustExec("create table t(a int, b int, key(a))")
+	tk.MustExec("create table t1(a int, b int, key(a))")
+	c.Assert(joinOf(tk, sql), Matches, "IndexJoin.*")
+	tk.MustExec("drop database bind_db")
+	tk.MustExec("use test")
+	tk2 := testkit.NewTestKit(c, s.store)
+	tk2.MustExec("use test")
+	c.Assert(joinOf(tk2, sql), Matches, "IndexJoin.*")
+
+	tk.MustExec("drop session binding for select * from t join t1 on t.a = t1.a where t.b > 2")
+	c.Assert(joinOf(tk, sql), Matches, "IndexJoin.*")
+	_, err = tk.Exec("drop session binding for select * from t join t1 on t.a = t1.a where t.b > 2")
+	c.Assert(terror.ErrorEqual(err, executor.ErrBindingNotExists), IsTrue)
+
+	// The global binding is used by all the sessions, and the session binding overrides it.
+	tk.MustExec("create global binding for select * from t join t1 on t.a = t1.a where t.b > 1 " +
+		"using select /*+ TIDB_SMJ(t, t1) */ * from t join t1 on t.a = t1.a where t.b > 1")
+	c.Assert(joinOf(tk, sql), Matches, "MergeJoin.*")
+	c.Assert(joinOf(tk2, sql), Matches, "MergeJoin.*")
+	tk2.MustExec("create session binding for select * from t join t1 on t.a = t1.a where t.b > 1 " +
+		"using select /*+ TIDB_INLJ(t) */ * from t join t1 on t.a = t1.a where t.b > 1")
+	c.Assert(joinOf(tk2, sql), Matches, "IndexJoin.*")
+	tk.MustQuery("select bind_sql, default_db, status from mysql.bind_info").Check(testkit.Rows(
+		"select /*+ TIDB_SMJ(t, t1) */ * from t join t1 on t.a = t1.a where t.b > 1 test using"))
+	rows = tk2.MustQuery("show global bindings").Rows()
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0][1], Equals, "select /*+ TIDB_SMJ(t, t1) */ * from t join t1 on t.a = t1.a where t.b > 1")
+
+	// The other TiDB servers load the bindings from mysql.bind_info.
+	h := bindinfo.NewHandle()
+	c.Assert(h.Update(tk.Se), IsNil)
+	c.Assert(h.Size(), Equals, 1)
+	c.Assert(h.GetBindRecord("select * from t join t1 on t . a = t1 . a where t . b > ?", "test"), NotNil)
+
+	tk.MustExec("drop global binding for select * from t join t1 on t.a = t1.a where t.b > 1")
+	c.Assert(joinOf(tk, sql), Matches, "IndexJoin.*")
+	tk.MustQuery("select status from mysql.bind_info").Check(testkit.Rows("deleted"))
+	c.Assert(h.Update(tk.Se), IsNil)
+	c.Assert(h.Size(), Equals, 0)
+	_, err = tk.Exec("drop global binding for select * from t join t1 on t.a = t1.a where t.b > 1")
+	c.Assert(terror.ErrorEqual(err, executor.ErrBindingNotExists), IsTrue)
+}
@@ -65,6 +65,20 @@ func (udb *dirtyDB) getDirtyTable(tid int64) *dirtyTable {
 	return dt
 }
 
+// getPhysicalTableID returns the ID of the dirty table which the row belongs to,
+// it's the partition ID if the table is partitioned.
+func getPhysicalTableID(ctx context.Context, t table.Table, row []types.Datum) (int64, error) {
+	pt, ok := t.(table.PartitionedTable)
+	if !ok {
+		return t.Meta().ID, nil
+	}
+	p, err := pt.GetPartitionByRow(ctx, row)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	return p.GetPhysicalID(), nil
+}
+
 type dirtyTable struct {
 	// addedRows ...
 	// the key is handle.
@@ -0,0 +1,452 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor
+
+import (
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// WindowExec is the executor for window functions. Its child is sorted by the partition by items and the order by
+// items, so it reads the rows of one partition at a time, computes the window functions over the partition, and
+// appends the results to the rows.
+type WindowExec struct {
+	baseExecutor
+
+	WindowFuncDescs []*plan.WindowFuncDesc
+	PartitionBy     []*plan.ByItems
+	OrderBy         []*plan.ByItems
+	Frame           *plan.WindowFrame
+
+	sc *variable.StatementContext
+	// aggFuncs are the aggregate functions computed over the window frames, they are nil for the other functions.
+	aggFuncs []expression.AggregationFunction
+
+	// rows are the rows of the current partition, and results are the window function results of them.
+	rows    []*Row
+	results [][]types.Datum
+	cursor  int
+	// nextRow is the first row of the next partition, which has been read from the child.
+	nextRow     *Row
+	nextPartKey []types.Datum
+	childDone   bool
+
+	// peerStart and peerEnd are the first and the last peer of every row, peers are the rows that have the same
+	// values of the order by items.
+	peerStart []int
+	peerEnd   []int
+	// rangeKeys are the values of the order by item of every row, they are used to compute the RANGE frames
+	// with offsets. The values are negated for descending order, so they are always in ascending order.
+	rangeKeys  []float64
+	rangeNulls []bool
+}
+
+// Open implements the Executor Open interface.
+func (e *WindowExec) Open() error {
+	e.rows = nil
+	e.results = nil
+	e.cursor = 0
+	e.nextRow = nil
+	e.nextPartKey = nil
+	e.childDone = false
+	return errors.Trace(e.children[0].Open())
+}
+
+// Close implements the Executor Close interface.
+func (e *WindowExec) Close() error {
+	e.rows = nil
+	e.results = nil
+	e.nextRow = nil
+	return errors.Trace(e.children[0].Close())
+}
+
+// Next implements the Executor Next interface.
+func (e *WindowExec) Next() (*Row, error) {
+	for e.cursor >= len(e.rows) {
+		hasMore, err := e.fetchPartition()
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if !hasMore {
+			return nil, nil
+		}
+	}
+	row := e.rows[e.cursor]
+	data := make([]types.Datum, 0, len(row.Data)+len(e.WindowFuncDescs))
+	data = append(data, row.Data...)
+	data = append(data, e.results[e.cursor]...)
+	e.cursor++
+	return &Row{Data: data, RowKeys: row.RowKeys}, nil
+}
+
+// fetchPartition reads the rows of the next partition from the child, and computes the window functions for them.
+func (e *WindowExec) fetchPartition() (bool, error) {
+	e.rows = e.rows[:0]
+	e.cursor = 0
+	if e.nextRow == nil {
+		if e.childDone {
+			return false, nil
+		}
+		row, err := e.children[0].Next()
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if row == nil {
+			e.childDone = true
+			return false, nil
+		}
+		e.nextRow = row
+		e.nextPartKey, err = evalByItems(e.PartitionBy, row)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+	}
+	partKey := e.nextPartKey
+	e.rows = append(e.rows, e.nextRow)
+	e.nextRow, e.nextPartKey = nil, nil
+	for {
+		row, err := e.children[0].Next()
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if row == nil {
+			e.childDone = true
+			break
+		}
+		key, err := evalByItems(e.PartitionBy, row)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		same, err := e.equalKeys(partKey, key)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if !same {
+			e.nextRow, e.nextPartKey = row, key
+			break
+		}
+		e.rows = append(e.rows, row)
+	}
+	return true, errors.Trace(e.computePartition())
+}
+
+func evalByItems(items []*plan.ByItems, row *Row) ([]types.Datum, error) {
+	key := make([]types.Datum, 0, len(items))
+	for _, item := range items {
+		d, err := item.Expr.Eval(row.Data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key = append(key, d)
+	}
+	return key, nil
+}
+
+func (e *WindowExec) equalKeys(a, b []types.Datum) (bool, error) {
+	for i := range a {
+		cmp, err := a[i].CompareDatum(e.sc, b[i])
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// computePartition computes the window functions for the rows of the current partition.
+func (e *WindowExec) computePartition() error {
+	if err := e.computePeers(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.computeRangeKeys(); err != nil {
+		return errors.Trace(err)
+	}
+	n := len(e.rows)
+	e.results = make([][]types.Datum, n)
+	for i := range e.results {
+		e.results[i] = make([]types.Datum, len(e.WindowFuncDescs))
+	}
+	for j, desc := range e.WindowFuncDescs {
+		var err error
+		switch desc.Name {
+		case ast.WindowFuncRowNumber:
+			for i := 0; i < n; i++ {
+				e.results[i][j].SetInt64(int64(i + 1))
+			}
+		case ast.WindowFuncRank:
+			for i := 0; i < n; i++ {
+				e.results[i][j].SetInt64(int64(e.peerStart[i] + 1))
+			}
+		case ast.WindowFuncDenseRank:
+			rank := int64(0)
+			for i := 0; i < n; i++ {
+				if e.peerStart[i] == i {
+					rank++
+				}
+				e.results[i][j].SetInt64(rank)
+			}
+		case ast.WindowFuncLead, ast.WindowFuncLag:
+			err = e.computeLeadLag(j, desc)
+		case ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+			err = e.computeFirstLastValue(j, desc)
+		default:
+			err = e.computeAggregate(j)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// computePeers finds the peers of every row. Without ORDER BY, all the rows of a partition are peers.
+func (e *WindowExec) computePeers() error {
+	n := len(e.rows)
+	e.peerStart = make([]int, n)
+	e.peerEnd = make([]int, n)
+	if len(e.OrderBy) == 0 {
+		for i := 0; i < n; i++ {
+			e.peerStart[i], e.peerEnd[i] = 0, n-1
+		}
+		return nil
+	}
+	var lastKey []types.Datum
+	start := 0
+	for i, row := range e.rows {
+		key, err := evalByItems(e.OrderBy, row)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if i > 0 {
+			same, err := e.equalKeys(lastKey, key)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if !same {
+				for k := start; k < i; k++ {
+					e.peerEnd[k] = i - 1
+				}
+				start = i
+			}
+		}
+		e.peerStart[i] = start
+		lastKey = key
+	}
+	for k := start; k < n; k++ {
+		e.peerEnd[k] = n - 1
+	}
+	return nil
+}
+
+// computeRangeKeys evaluates the order by item for the RANGE frames with offsets.
+func (e *WindowExec) computeRangeKeys() error {
+	e.rangeKeys, e.rangeNulls = nil, nil
+	if e.Frame.Type != ast.Ranges || !hasOffset(e.Frame.Start) && !hasOffset(e.Frame.End) {
+		return nil
+	}
+	e.rangeKeys = make([]float64, len(e.rows))
+	e.rangeNulls = make([]bool, len(e.rows))
+	item := e.OrderBy[0]
+	for i, row := range e.rows {
+		d, err := item.Expr.Eval(row.Data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if d.IsNull() {
+			e.rangeNulls[i] = true
+			continue
+		}
+		e.rangeKeys[i], err = d.ToFloat64(e.sc)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if item.Desc {
+			e.rangeKeys[i] = -e.rangeKeys[i]
+		}
+	}
+	return nil
+}
+
+func hasOffset(bound *plan.FrameBound) bool {
+	return bound.Type != ast.CurrentRow && !bound.UnBounded
+}
+
+// frameRange returns the range [start, end) of the frame of the i-th row.
+func (e *WindowExec) frameRange(i int) (int, int, error) {
+	start, err := e.frameBound(i, e.Frame.Start, true)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	end, err := e.frameBound(i, e.Frame.End, false)
+	if err != nil {
+		return 0, 0, errors.Trace(err)
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, nil
+}
+
+// frameBound returns the position of a frame bound for the i-th row. The start bound is inclusive, while the end
+// bound is exclusive.
+func (e *WindowExec) frameBound(i int, bound *plan.FrameBound, isStart bool) (int, error) {
+	n := len(e.rows)
+	if bound.UnBounded {
+		if bound.Type == ast.Preceding {
+			return 0, nil
+		}
+		return n, nil
+	}
+	if e.Frame.Type == ast.Rows {
+		pos := i
+		if bound.Type != ast.CurrentRow {
+			num, err := bound.Num.ToInt64(e.sc)
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			if bound.Type == ast.Preceding {
+				pos = i - int(num)
+			} else {
+				pos = i + int(num)
+			}
+		}
+		if !isStart {
+			pos++
+		}
+		return clampInt(pos, 0, n), nil
+	}
+	// For RANGE frames, the bounds of the rows with NULL order by values are their peers.
+	if bound.Type == ast.CurrentRow || e.rangeNulls[i] {
+		if isStart {
+			return e.peerStart[i], nil
+		}
+		return e.peerEnd[i] + 1, nil
+	}
+	num, err := bound.Num.ToFloat64(e.sc)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	target := e.rangeKeys[i] + num
+	if bound.Type == ast.Preceding {
+		target = e.rangeKeys[i] - num
+	}
+	// The rows with NULL order by values are sorted together, so the rows with values are continuous.
+	lo, hi := 0, n
+	for lo < n && e.rangeNulls[lo] {
+		lo++
+	}
+	for hi > lo && e.rangeNulls[hi-1] {
+		hi--
+	}
+	if isStart {
+		return lo + sort.Search(hi-lo, func(k int) bool { return e.rangeKeys[lo+k] >= target }), nil
+	}
+	return lo + sort.Search(hi-lo, func(k int) bool { return e.rangeKeys[lo+k] > target }), nil
+}
+
+func clampInt(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+func (e *WindowExec) computeLeadLag(j int, desc *plan.WindowFuncDesc) error {
+	for i, row := range e.rows {
+		offset := int64(1)
+		if len(desc.Args) > 1 {
+			d, err := desc.Args[1].Eval(row.Data)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			offset, err = d.ToInt64(e.sc)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+		pos := i + int(offset)
+		if desc.Name == ast.WindowFuncLag {
+			pos = i - int(offset)
+		}
+		var err error
+		if pos >= 0 && pos < len(e.rows) {
+			e.results[i][j], err = desc.Args[0].Eval(e.rows[pos].Data)
+		} else if len(desc.Args) > 2 {
+			e.results[i][j], err = desc.Args[2].Eval(row.Data)
+		}
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+func (e *WindowExec) computeFirstLastValue(j int, desc *plan.WindowFuncDesc) error {
+	for i := range e.rows {
+		start, end, err := e.frameRange(i)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if start == end {
+			continue
+		}
+		pos := start
+		if desc.Name == ast.WindowFuncLastValue {
+			pos = end - 1
+		}
+		e.results[i][j], err = desc.Args[0].Eval(e.rows[pos].Data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// computeAggregate computes an aggregate function over the frames. When the frames start from the beginning of the
+// partition, their ends never move backward, so the aggregate function is updated incrementally. Otherwise it is
+// recomputed for every frame.
+func (e *WindowExec) computeAggregate(j int) error {
+	agg := e.aggFuncs[j]
+	agg.Reset()
+	incremental := e.Frame.Start.Type == ast.Preceding && e.Frame.Start.UnBounded
+	updated := 0
+	for i := range e.rows {
+		start, end, err := e.frameRange(i)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if !incremental {
+			agg.Reset()
+			updated = start
+		}
+		for ; updated < end; updated++ {
+			if err = agg.Update(e.rows[updated].Data, nil, e.sc); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		e.results[i][j] = agg.GetGroupResult(nil)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package executor_test
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/plan"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/testkit"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func (s *testSuite) TestWindowFunction(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists t")
+	tk.MustExec("create table t (a int, b int, c int)")
+	tk.MustExec("insert into t values (1, 1, 1), (1, 2, 2), (1, 2, 3), (1, 4, 4), (2, 1, 5), (2, 3, 6), (3, null, 7)")
+
+	result := tk.MustQuery("select a, c, row_number() over (partition by a order by c) from t order by c")
+	result.Check(testkit.Rows("1 1 1", "1 2 2", "1 3 3", "1 4 4", "2 5 1", "2 6 2", "3 7 1"))
+	result = tk.MustQuery("select a, b, rank() over (partition by a order by b), dense_rank() over (partition by a order by b) from t where a = 1 order by c")
+	result.Check(testkit.Rows("1 1 1 1", "1 2 2 2", "1 2 2 2", "1 4 4 3"))
+	result = tk.MustQuery("select c, lead(c) over (order by c), lag(c, 2) over (order by c), lag(c, 2, 0) over (order by c) from t order by c")
+	result.Check(testkit.Rows("1 2 <nil> 0", "2 3 <nil> 0", "3 4 1 1", "4 5 2 2", "5 6 3 3", "6 7 4 4", "7 <nil> 5 5"))
+	result = tk.MustQuery("select c, first_value(c) over (partition by a order by c desc), last_value(c) over (partition by a order by c) from t order by c")
+	result.Check(testkit.Rows("1 4 1", "2 4 2", "3 4 3", "4 4 4", "5 6 5", "6 6 6", "7 7 7"))
+
+	// The default frame contains the peers of the current row.
+	result = tk.MustQuery("select b, sum(c) over (partition by a order by b), count(*) over (partition by a) from t where a = 1 order by c")
+	result.Check(testkit.Rows("1 1 4", "2 6 4", "2 6 4", "4 10 4"))
+	result = tk.MustQuery("select c, sum(c) over (order by c rows between 1 preceding and 1 following), avg(c) over (order by c rows 2 preceding) from t order by c")
+	result.Check(testkit.Rows("1 3 1.0000", "2 6 1.5000", "3 9 2.0000", "4 12 3.0000", "5 15 4.0000", "6 18 5.0000", "7 13 6.0000"))
+	result = tk.MustQuery("select c, max(c) over (order by c rows between 2 following and unbounded following) from t order by c")
+	result.Check(testkit.Rows("1 7", "2 7", "3 7", "4 7", "5 7", "6 <nil>", "7 <nil>"))
+	result = tk.MustQuery("select b, sum(c) over (order by b range between 1 preceding and current row), count(*) over (order by b desc range 1 preceding) from t order by c")
+	result.Check(testkit.Rows("1 6 4", "2 11 3", "2 11 3", "4 10 1", "1 6 4", "3 11 2", "<nil> 7 1"))
+
+	// Window functions with aggregation and other clauses.
+	result = tk.MustQuery("select a, sum(c), rank() over (order by sum(c) desc) as r from t group by a order by r")
+	result.Check(testkit.Rows("2 11 1", "1 10 2", "3 7 3"))
+	result = tk.MustQuery("select a, sum(sum(c)) over (order by a) from t group by a having count(*) > 1")
+	result.Check(testkit.Rows("1 10", "2 21"))
+	result = tk.MustQuery("select distinct a, count(*) over (partition by a) from t order by a limit 2")
+	result.Check(testkit.Rows("1 4", "2 2"))
+	result = tk.MustQuery("select c * 10 + row_number() over (order by c desc) from t where a = 2")
+	result.Sort().Check(testkit.Rows("52", "61"))
+
+	_, err := tk.Exec("select a from t where row_number() over () > 1")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowInvalidWindowFuncUse), IsTrue)
+	_, err = tk.Exec("select sum(distinct a) over () from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrNotSupportedYet), IsTrue)
+	_, err = tk.Exec("select sum(a) over (rows between current row and unbounded preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameEndIllegal), IsTrue)
+	_, err = tk.Exec("select sum(a) over (rows between unbounded following and current row) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameStartIllegal), IsTrue)
+	_, err = tk.Exec("select sum(a) over (order by a rows 1.5 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameIllegal), IsTrue)
+	_, err = tk.Exec("select sum(a) over (order by a, b range 1 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowRangeFrameOrderType), IsTrue)
+	_, err = tk.Exec("select sum(a) over (order by a rows between 2 following and 1 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameIllegal), IsTrue)
+	_, err = tk.Exec("select sum(a) over (order by a rows between 1 following and current row) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameIllegal), IsTrue)
+	_, err = tk.Exec("select sum(a) over (order by a range between current row and 1 preceding) from t")
+	c.Assert(terror.ErrorEqual(err, plan.ErrWindowFrameIllegal), IsTrue)
+	// The frame starting after its end by the offsets is empty.
+	tk.MustQuery("select count(a) over (order by c rows between 1 preceding and 2 preceding) from t limit 1").Check(testkit.Rows("0"))
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -122,9 +124,16 @@ func updateRecord(ctx context.Context, h int64, oldData, newData []types.Datum,
 		return false, errors.Trace(err)
 	}
 	dirtyDB := getDirtyDB(ctx)
-	tid := t.Meta().ID
-	dirtyDB.deleteRow(tid, h)
-	dirtyDB.addRow(tid, h, newData)
+	oldTID, err := getPhysicalTableID(ctx, t, oldData)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	newTID, err := getPhysicalTableID(ctx, t, newData)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	dirtyDB.deleteRow(oldTID, h)
+	dirtyDB.addRow(newTID, h, newData)
 
 	// Record affected rows.
 	if !onDuplicateUpdate {
@@ -205,6 +214,21 @@ func (e *DeleteExec) deleteMultiTables() error {
 			tblRowMap[entry.Tbl][entry.Handle] = data
 		}
 	}
+	if e.ctx.GetSessionVars().TxnCtx.ForUpdateTS != 0 {
+		var keys []kv.Key
+		for t, rowMap := range tblRowMap {
+			for handle, data := range rowMap {
+				var err error
+				keys, err = appendRowLockKeys(e.ctx, keys, t, handle, data)
+				if err != nil {
+					return errors.Trace(err)
+				}
+			}
+		}
+		if err := e.ctx.Txn().LockKeys(keys...); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	for t, rowMap := range tblRowMap {
 		for handle, data := range rowMap {
 			err := e.removeRow(e.ctx, t, handle, data)
@@ -231,6 +255,9 @@ func isMatchTableName(entry *RowKeyEntry, tblMap map[int64][]string) bool {
 }
 
 func (e *DeleteExec) deleteSingleTable() error {
+	if e.ctx.GetSessionVars().TxnCtx.ForUpdateTS != 0 {
+		return e.lockAndDeleteSingleTable()
+	}
 	for {
 		row, err := e.SelectExec.Next()
 		if err != nil {
@@ -248,12 +275,85 @@ func (e *DeleteExec) deleteSingleTable() error {
 	return nil
 }
 
+// lockAndDeleteSingleTable locks all the rows before deleting any of them in the pessimistic transaction,
+// so the statement can be retried if the locking fails.
+func (e *DeleteExec) lockAndDeleteSingleTable() error {
+	var (
+		rows []*Row
+		keys []kv.Key
+	)
+	for {
+		row, err := e.SelectExec.Next()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if row == nil {
+			break
+		}
+		rowKey := row.RowKeys[0]
+		keys, err = appendRowLockKeys(e.ctx, keys, rowKey.Tbl, rowKey.Handle, row.Data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		rows = append(rows, row)
+	}
+	if err := e.ctx.Txn().LockKeys(keys...); err != nil {
+		return errors.Trace(err)
+	}
+	for _, row := range rows {
+		rowKey := row.RowKeys[0]
+		err := e.removeRow(e.ctx, rowKey.Tbl, rowKey.Handle, row.Data)
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// appendRowLockKeys appends the keys of the row and its index entries, which are locked before the row is
+// written in the pessimistic transaction.
+func appendRowLockKeys(ctx context.Context, keys []kv.Key, t table.Table, h int64, data []types.Datum) ([]kv.Key, error) {
+	if pt, ok := t.(table.PartitionedTable); ok {
+		p, err := pt.GetPartitionByRow(ctx, data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		t = p
+	}
+	tid := t.Meta().ID
+	if pt, ok := t.(table.PhysicalTable); ok {
+		tid = pt.GetPhysicalID()
+	}
+	keys = append(keys, tablecodec.EncodeRowKeyWithHandle(tid, h))
+	// The index entries of the changing columns are generated by their converted values.
+	data, err := table.FillChangingValues(&variable.StatementContext{IgnoreTruncate: true}, t.Meta(), data)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, idx := range t.Indices() {
+		vals, err := idx.FetchValues(data)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		key, _, err := idx.GenIndexKey(vals, h)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
 func (e *DeleteExec) removeRow(ctx context.Context, t table.Table, h int64, data []types.Datum) error {
 	err := t.RemoveRecord(ctx, h, data)
 	if err != nil {
 		return errors.Trace(err)
 	}
-	getDirtyDB(ctx).deleteRow(t.Meta().ID, h)
+	tid, err := getPhysicalTableID(ctx, t, data)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	getDirtyDB(ctx).deleteRow(tid, h)
 	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
 	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.Meta().ID, -1, 1)
 	return nil
@@ -666,7 +766,11 @@ func (e *InsertExec) Next() (*Row, error) {
 		h, err := e.Table.AddRecord(e.ctx, row)
 		txn.DelOption(kv.PresumeKeyNotExists)
 		if err == nil {
-			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
+			tid, err := getPhysicalTableID(e.ctx, e.Table, row)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+			getDirtyDB(e.ctx).addRow(tid, h, row)
 			rowCount++
 			continue
 		}
@@ -1081,7 +1185,11 @@ func (e *ReplaceExec) Next() (*Row, error) {
 		row := rows[idx]
 		h, err1 := e.Table.AddRecord(e.ctx, row)
 		if err1 == nil {
-			getDirtyDB(e.ctx).addRow(e.Table.Meta().ID, h, row)
+			tid, err1 := getPhysicalTableID(e.ctx, e.Table, row)
+			if err1 != nil {
+				return nil, errors.Trace(err1)
+			}
+			getDirtyDB(e.ctx).addRow(tid, h, row)
 			idx++
 			continue
 		}
@@ -1107,7 +1215,11 @@ func (e *ReplaceExec) Next() (*Row, error) {
 		if err1 != nil {
 			return nil, errors.Trace(err1)
 		}
-		getDirtyDB(e.ctx).deleteRow(e.Table.Meta().ID, h)
+		tid, err1 := getPhysicalTableID(e.ctx, e.Table, oldRow)
+		if err1 != nil {
+			return nil, errors.Trace(err1)
+		}
+		getDirtyDB(e.ctx).deleteRow(tid, h)
 		e.ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
 	}
 
@@ -1141,6 +1253,12 @@ func (e *UpdateExec) Next() (*Row, error) {
 		if err != nil {
 			return nil, errors.Trace(err)
 		}
+		if e.ctx.GetSessionVars().TxnCtx.ForUpdateTS != 0 {
+			err = e.lockRows()
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
 		e.fetched = true
 	}
 
@@ -1219,6 +1337,52 @@ func (e *UpdateExec) fetchRows() error {
 	}
 }
 
+// lockRows locks the old and new rows before updating any of them in the pessimistic transaction,
+// so the statement can be retried if the locking fails.
+func (e *UpdateExec) lockRows() error {
+	assignFlag, err := getUpdateColumns(e.OrderedList, e.SelectExec.Schema().Len())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	sc := e.ctx.GetSessionVars().StmtCtx
+	var keys []kv.Key
+	for i, row := range e.rows {
+		for _, entry := range row.RowKeys {
+			tbl := entry.Tbl
+			offset := getTableOffset(e.SelectExec.Schema(), entry)
+			end := offset + len(tbl.WritableCols())
+			keys, err = appendRowLockKeys(e.ctx, keys, tbl, entry.Handle, row.Data[offset:end])
+			if err != nil {
+				return errors.Trace(err)
+			}
+			// The assigned values are casted here to generate the index keys, casting them again in
+			// updateRecord doesn't change them.
+			newHandle := entry.Handle
+			newTableData := e.newRowsData[i][offset:end]
+			for j, col := range tbl.WritableCols() {
+				if !assignFlag[offset+j] {
+					continue
+				}
+				newTableData[j], err = table.CastValue(e.ctx, newTableData[j], col.ToInfo())
+				if err != nil {
+					return errors.Trace(err)
+				}
+				if col.IsPKHandleColumn(tbl.Meta()) && !newTableData[j].IsNull() {
+					newHandle, err = newTableData[j].ToInt64(sc)
+					if err != nil {
+						return errors.Trace(err)
+					}
+				}
+			}
+			keys, err = appendRowLockKeys(e.ctx, keys, tbl, newHandle, newTableData)
+			if err != nil {
+				return errors.Trace(err)
+			}
+		}
+	}
+	return errors.Trace(e.ctx.Txn().LockKeys(keys...))
+}
+
 func getTableOffset(schema *expression.Schema, entry *RowKeyEntry) int {
 	for i := 0; i < schema.Len(); i++ {
 		s := schema.Columns[i]
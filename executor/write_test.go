@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/tidb/executor"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/plan"
 	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/testkit"
@@ -1007,6 +1008,110 @@ func (s *testSuite) TestBatchInsert(c *C) {
 	r.Check(testkit.Rows("320"))
 }
 
+func (s *testSuite) TestLargeTxn(c *C) {
+	originLimit := atomic.LoadUint64(&kv.TxnEntryCountLimit)
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+		atomic.StoreUint64(&kv.TxnEntryCountLimit, originLimit)
+	}()
+	// Set the limitation to a small value, make it easier to reach the limitation.
+	atomic.StoreUint64(&kv.TxnEntryCountLimit, 100)
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists large_txn")
+	tk.MustExec("create table large_txn (c int)")
+	tk.MustExec("insert into large_txn values (1),(1),(1),(1),(1),(1),(1),(1),(1),(1)")
+	for i := 0; i < 4; i++ {
+		tk.MustExec("insert into large_txn (c) select * from large_txn;")
+	}
+	tk.MustQuery("select count(*) from large_txn;").Check(testkit.Rows("160"))
+	_, err := tk.Exec("insert into large_txn (c) select * from large_txn;")
+	c.Assert(kv.ErrTxnTooLarge.Equal(err), IsTrue)
+
+	// The large transaction isn't limited by TxnEntryCountLimit.
+	tk.MustExec("set @@session.tidb_large_txn=1;")
+	tk.MustExec("insert into large_txn (c) select * from large_txn;")
+	tk.MustQuery("select count(*) from large_txn;").Check(testkit.Rows("320"))
+	tk.MustExec("begin;")
+	tk.MustExec("insert into large_txn (c) select * from large_txn;")
+	tk.MustExec("update large_txn set c = 2;")
+	tk.MustQuery("select count(*), sum(c) from large_txn;").Check(testkit.Rows("640 1280"))
+	tk.MustExec("commit;")
+	tk.MustQuery("select count(*), sum(c) from large_txn;").Check(testkit.Rows("640 1280"))
+
+	tk.MustExec("set @@session.tidb_large_txn=0;")
+	_, err = tk.Exec("update large_txn set c = 3;")
+	c.Assert(kv.ErrTxnTooLarge.Equal(err), IsTrue)
+}
+
+func (s *testSuite) TestNonTransactionalDML(c *C) {
+	defer func() {
+		s.cleanEnv(c)
+		testleak.AfterTest(c)()
+	}()
+	tk := testkit.NewTestKit(c, s.store)
+	tk.MustExec("use test")
+	tk.MustExec("drop table if exists batch_dml")
+	tk.MustExec("create table batch_dml (id int primary key, a int, b int, index idx_a(a))")
+	tk.MustExec("insert into batch_dml values (1, null, 1), (2, 1, 1), (3, 2, 1), (4, 2, 1), (5, 3, 1), (6, 5, 1), (7, 8, 1)")
+
+	// The values of the shard column in a batch aren't split.
+	tk.MustQuery("batch on a limit 2 update batch_dml set b = 2 where id > 1").Check(testkit.Rows(
+		"1 1 2 3 <nil>",
+		"2 3 5 2 <nil>",
+		"3 8 8 1 <nil>",
+	))
+	tk.MustQuery("select id from batch_dml where b = 2").Check(testkit.Rows("2", "3", "4", "5", "6", "7"))
+	tk.MustQuery("batch on a limit 3 update batch_dml set b = 3").Check(testkit.Rows(
+		"1 <nil> 2 4 <nil>",
+		"2 3 8 3 <nil>",
+	))
+	tk.MustQuery("select count(*) from batch_dml where b = 3").Check(testkit.Rows("7"))
+
+	tk.MustQuery("batch on id limit 3 delete from batch_dml where a > 1").Check(testkit.Rows(
+		"1 3 5 3 <nil>",
+		"2 6 7 2 <nil>",
+	))
+	tk.MustQuery("select id from batch_dml").Check(testkit.Rows("1", "2"))
+	tk.MustQuery("batch on batch_dml.id limit 1 delete from batch_dml").Check(testkit.Rows(
+		"1 1 1 1 <nil>",
+		"2 2 2 1 <nil>",
+	))
+	tk.MustQuery("select count(*) from batch_dml").Check(testkit.Rows("0"))
+	tk.MustQuery("batch on id limit 1 delete from batch_dml").Check(testkit.Rows())
+
+	// An error of a batch is reported in its row and the other batches are committed.
+	tk.MustExec("insert into batch_dml values (1, 1, 1), (2, 2, 2), (3, 3, 3)")
+	result := tk.MustQuery("batch on a limit 1 update batch_dml set id = 3 where id = 2 or id = 3")
+	c.Assert(result.Rows(), HasLen, 2)
+	c.Assert(result.Rows()[0][4], Matches, ".*Duplicate entry.*")
+	c.Assert(result.Rows()[1][4], Equals, "<nil>")
+	tk.MustQuery("select * from batch_dml").Check(testkit.Rows("1 1 1", "2 2 2", "3 3 3"))
+
+	_, err := tk.Exec("batch on b limit 1 delete from batch_dml")
+	c.Assert(err, NotNil)
+	// The rows whose shard column is updated would be read again by the later batches.
+	_, err = tk.Exec("batch on id limit 2 update batch_dml set id = id + 10")
+	c.Assert(plan.ErrInvalidNonTransactionalDML.Equal(err), IsTrue)
+	_, err = tk.Exec("batch on a limit 2 update batch_dml t set b = 1, t.a = a + 10")
+	c.Assert(plan.ErrInvalidNonTransactionalDML.Equal(err), IsTrue)
+	tk.MustQuery("select * from batch_dml").Check(testkit.Rows("1 1 1", "2 2 2", "3 3 3"))
+	_, err = tk.Exec("batch on id limit 0 delete from batch_dml")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("batch on id limit 1 delete from batch_dml order by id limit 1")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("batch on c limit 1 delete from batch_dml")
+	c.Assert(err, NotNil)
+	_, err = tk.Exec("batch on id limit 1 delete t1 from batch_dml t1, batch_dml t2 where t1.id = t2.id")
+	c.Assert(err, NotNil)
+	tk.MustExec("begin")
+	_, err = tk.Exec("batch on id limit 1 delete from batch_dml")
+	c.Assert(executor.ErrNonTransactionalDMLInTxn.Equal(err), IsTrue)
+	tk.MustExec("rollback")
+	tk.MustQuery("select count(*) from batch_dml").Check(testkit.Rows("3"))
+}
+
 func (s *testSuite) TestNullDefault(c *C) {
 	defer func() {
 		s.cleanEnv(c)
@@ -20,11 +20,13 @@ import (
 	"net"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/userlock"
 	"github.com/twinj/uuid"
 )
 
@@ -136,6 +138,7 @@ type lockFunctionClass struct {
 
 func (c *lockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -145,10 +148,40 @@ type builtinLockSig struct {
 
 // eval evals a builtinLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_get-lock
-// The lock function will do nothing.
-// Warning: get_lock() function is parsed but ignored.
-func (b *builtinLockSig) eval(_ []types.Datum) (d types.Datum, err error) {
-	d.SetInt64(1)
+// It waits forever if the timeout is negative.
+func (b *builtinLockSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	name, err := getUserLockName(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	var timeout float64
+	if !args[1].IsNull() {
+		timeout, err = args[1].ToFloat64(b.ctx.GetSessionVars().StmtCtx)
+		if err != nil {
+			return d, errors.Trace(err)
+		}
+	}
+	duration := time.Duration(-1)
+	if timeout >= 0 {
+		duration = time.Duration(timeout * float64(time.Second))
+	}
+	m, err := getUserLockManager(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	acquired, err := m.GetLock(b.ctx, name, duration)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if acquired {
+		d.SetInt64(1)
+	} else {
+		d.SetInt64(0)
+	}
 	return d, nil
 }
 
@@ -158,6 +191,7 @@ type releaseLockFunctionClass struct {
 
 func (c *releaseLockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinReleaseLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -167,11 +201,62 @@ type builtinReleaseLockSig struct {
 
 // eval evals a builtinReleaseLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-lock
-// The release lock function will do nothing.
-// Warning: release_lock() function is parsed but ignored.
-func (b *builtinReleaseLockSig) eval(_ []types.Datum) (d types.Datum, err error) {
-	d.SetInt64(1)
-	return
+func (b *builtinReleaseLockSig) eval(row []types.Datum) (d types.Datum, err error) {
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	name, err := getUserLockName(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	m, err := getUserLockManager(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	released, err := m.ReleaseLock(b.ctx, name)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if released {
+		d.SetInt64(1)
+		return d, nil
+	}
+	// The result is 0 if the lock is held by another session, or NULL if the lock doesn't exist.
+	_, used, err := m.IsUsedLock(name)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if used {
+		d.SetInt64(0)
+	}
+	return d, nil
+}
+
+// maxUserLockNameLen is the max length of the name of a user level lock.
+const maxUserLockNameLen = 64
+
+// getUserLockName checks and returns the name of a user level lock.
+func getUserLockName(arg types.Datum) (string, error) {
+	if arg.IsNull() {
+		return "", errUserLockWrongName.GenByArgs("NULL")
+	}
+	name, err := arg.ToString()
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	if len(name) == 0 || utf8.RuneCountInString(name) > maxUserLockNameLen {
+		return "", errUserLockWrongName.GenByArgs(name)
+	}
+	return name, nil
+}
+
+func getUserLockManager(ctx context.Context) (userlock.Manager, error) {
+	m := userlock.GetManager(ctx)
+	if m == nil {
+		return nil, errors.New("user level lock manager is not bound to the context")
+	}
+	return m, nil
 }
 
 type anyValueFunctionClass struct {
@@ -450,6 +535,7 @@ type isFreeLockFunctionClass struct {
 
 func (c *isFreeLockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinIsFreeLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -460,7 +546,28 @@ type builtinIsFreeLockSig struct {
 // eval evals a builtinIsFreeLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-free-lock
 func (b *builtinIsFreeLockSig) eval(row []types.Datum) (d types.Datum, err error) {
-	return d, errFunctionNotExists.GenByArgs("IS_FREE_LOCK")
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	name, err := getUserLockName(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	m, err := getUserLockManager(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	_, used, err := m.IsUsedLock(name)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if used {
+		d.SetInt64(0)
+	} else {
+		d.SetInt64(1)
+	}
+	return d, nil
 }
 
 type isIPv4FunctionClass struct {
@@ -641,6 +748,7 @@ type isUsedLockFunctionClass struct {
 
 func (c *isUsedLockFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinIsUsedLockSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -650,8 +758,28 @@ type builtinIsUsedLockSig struct {
 
 // eval evals a builtinIsUsedLockSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_is-used-lock
+// It returns the connection ID of the session holding the lock, or NULL if the lock is free.
 func (b *builtinIsUsedLockSig) eval(row []types.Datum) (d types.Datum, err error) {
-	return d, errFunctionNotExists.GenByArgs("IS_USED_LOCK")
+	args, err := b.evalArgs(row)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	name, err := getUserLockName(args[0])
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	m, err := getUserLockManager(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	connID, used, err := m.IsUsedLock(name)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	if used {
+		d.SetUint64(connID)
+	}
+	return d, nil
 }
 
 type masterPosWaitFunctionClass struct {
@@ -698,6 +826,7 @@ type releaseAllLocksFunctionClass struct {
 
 func (c *releaseAllLocksFunctionClass) getFunction(args []Expression, ctx context.Context) (builtinFunc, error) {
 	sig := &builtinReleaseAllLocksSig{newBaseBuiltinFunc(args, ctx)}
+	sig.deterministic = false
 	return sig.setSelf(sig), errors.Trace(c.verifyArgs(args))
 }
 
@@ -707,8 +836,18 @@ type builtinReleaseAllLocksSig struct {
 
 // eval evals a builtinReleaseAllLocksSig.
 // See https://dev.mysql.com/doc/refman/5.7/en/miscellaneous-functions.html#function_release-all-locks
+// It returns the number of the locks released.
 func (b *builtinReleaseAllLocksSig) eval(row []types.Datum) (d types.Datum, err error) {
-	return d, errFunctionNotExists.GenByArgs("RELEASEA_ALL_LOCKS")
+	m, err := getUserLockManager(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	count, err := m.ReleaseAllLocks(b.ctx)
+	if err != nil {
+		return d, errors.Trace(err)
+	}
+	d.SetInt64(count)
+	return d, nil
 }
 
 type uuidFunctionClass struct {
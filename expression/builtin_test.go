@@ -15,6 +15,7 @@ package expression
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
@@ -22,6 +23,7 @@ import (
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/types"
@@ -188,19 +190,21 @@ func (s *testEvaluatorSuite) TestIsNullFunc(c *C) {
 func (s *testEvaluatorSuite) TestLock(c *C) {
 	defer testleak.AfterTest(c)()
 
-	lock := funcs[ast.GetLock]
-	f, err := lock.getFunction(datumsToConstants(types.MakeDatums(nil, 1)), s.ctx)
-	c.Assert(err, IsNil)
-	v, err := f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+	// The name of the lock is checked before the lock is acquired or released.
+	for _, name := range []interface{}{nil, "", strings.Repeat("a", 65)} {
+		lock := funcs[ast.GetLock]
+		f, err := lock.getFunction(datumsToConstants(types.MakeDatums(name, 1)), s.ctx)
+		c.Assert(err, IsNil)
+		c.Assert(f.isDeterministic(), IsFalse)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, errUserLockWrongName), IsTrue)
 
-	releaseLock := funcs[ast.ReleaseLock]
-	f, err = releaseLock.getFunction(datumsToConstants(types.MakeDatums(1)), s.ctx)
-	c.Assert(err, IsNil)
-	v, err = f.eval(nil)
-	c.Assert(err, IsNil)
-	c.Assert(v.GetInt64(), Equals, int64(1))
+		releaseLock := funcs[ast.ReleaseLock]
+		f, err = releaseLock.getFunction(datumsToConstants(types.MakeDatums(name)), s.ctx)
+		c.Assert(err, IsNil)
+		_, err = f.eval(nil)
+		c.Assert(terror.ErrorEqual(err, errUserLockWrongName), IsTrue)
+	}
 }
 
 // newFunctionForTest creates a new ScalarFunction using funcName and arguments,
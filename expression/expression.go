@@ -34,6 +34,7 @@ var (
 	errInvalidOperation        = terror.ClassExpression.New(codeInvalidOperation, "invalid operation")
 	errIncorrectParameterCount = terror.ClassExpression.New(codeIncorrectParameterCount, "Incorrect parameter count in the call to native function '%s'")
 	errFunctionNotExists       = terror.ClassExpression.New(codeFunctionNotExists, "FUNCTION %s does not exist")
+	errUserLockWrongName       = terror.ClassExpression.New(codeUserLockWrongName, mysql.MySQLErrName[mysql.ErrUserLockWrongName])
 )
 
 // Error codes.
@@ -41,6 +42,7 @@ const (
 	codeInvalidOperation        terror.ErrCode = 1
 	codeIncorrectParameterCount                = 1582
 	codeFunctionNotExists                      = 1305
+	codeUserLockWrongName                      = 3057
 )
 
 // TurnOnNewExprEval indicates whether turn on the new expression evaluation architecture.
@@ -49,6 +51,10 @@ var TurnOnNewExprEval bool
 // EvalAstExpr evaluates ast expression directly.
 var EvalAstExpr func(expr ast.ExprNode, ctx context.Context) (types.Datum, error)
 
+// RewriteAstExpr rewrites ast expression, whose columns refer to the columns of tblInfo, to Expression.
+// The columns in the result are resolved to the offsets in the row of tblInfo.
+var RewriteAstExpr func(ctx context.Context, expr ast.ExprNode, tblInfo *model.TableInfo) (Expression, error)
+
 // Expression represents all scalar expression in SQL.
 type Expression interface {
 	fmt.Stringer
@@ -550,6 +556,7 @@ func init() {
 	expressionMySQLErrCodes := map[terror.ErrCode]uint16{
 		codeIncorrectParameterCount: mysql.ErrWrongParamcountToNativeFct,
 		codeFunctionNotExists:       mysql.ErrSpDoesNotExist,
+		codeUserLockWrongName:       mysql.ErrUserLockWrongName,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassExpression] = expressionMySQLErrCodes
 }
@@ -16,6 +16,8 @@ package infoschema
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/context"
@@ -28,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/util/charset"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -63,6 +66,7 @@ const (
 	tableOptimizerTrace                     = "OPTIMIZER_TRACE"
 	tableTableSpaces                        = "TABLESPACES"
 	tableCollationCharacterSetApplicability = "COLLATION_CHARACTER_SET_APPLICABILITY"
+	tableSlowQuery                          = "SLOW_QUERY"
 )
 
 type columnInfo struct {
@@ -508,6 +512,22 @@ var tableTableSpacesCols = []columnInfo{
 	{"TABLESPACE_COMMENT", mysql.TypeVarchar, 2048, 0, nil, nil},
 }
 
+var tableSlowQueryCols = []columnInfo{
+	{"TIME", mysql.TypeDatetime, 26, 0, nil, nil},
+	{"TXN_START_TS", mysql.TypeLonglong, 20, 0, nil, nil},
+	{"USER", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"CONN_ID", mysql.TypeLonglong, 20, 0, nil, nil},
+	{"QUERY_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"PARSE_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"COMPILE_TIME", mysql.TypeDouble, 22, 0, nil, nil},
+	{"COP_TASKS", mysql.TypeLonglong, 20, 0, nil, nil},
+	{"PROCESS_KEYS", mysql.TypeLonglong, 20, 0, nil, nil},
+	{"DB", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"INDEX_NAMES", mysql.TypeVarchar, 1024, 0, nil, nil},
+	{"DIGEST", mysql.TypeVarchar, 64, 0, nil, nil},
+	{"QUERY", mysql.TypeLongBlob, types.UnspecifiedLength, 0, nil, nil},
+}
+
 var tableCollationCharacterSetApplicabilityCols = []columnInfo{
 	{"TABLESPACE_NAME", mysql.TypeVarchar, 64, 0, nil, nil},
 	{"ENGINE", mysql.TypeVarchar, 64, 0, nil, nil},
@@ -542,6 +562,50 @@ func dataForColltions() (records [][]types.Datum) {
 	return records
 }
 
+// slowQueryMaxReadSize is the max size of the slow query log read by the SLOW_QUERY table, only the latest
+// entries are read if the file is larger.
+var slowQueryMaxReadSize int64 = 64 * 1024 * 1024
+
+// dataForSlowQuery parses the slow query log file of this TiDB server. Like the PROCESSLIST, the users without
+// the SUPER or PROCESS privilege can only see their own queries.
+func dataForSlowQuery(ctx context.Context) (records [][]types.Datum, err error) {
+	path := slowlog.FilePath()
+	if path == "" {
+		return nil, nil
+	}
+	entries, err := slowlog.ReadFile(path, slowQueryMaxReadSize)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	showAll := true
+	if pm := privilege.GetPrivilegeManager(ctx); pm != nil {
+		showAll = pm.RequestVerification("", "", "", mysql.SuperPriv) || pm.RequestVerification("", "", "", mysql.ProcessPriv)
+	}
+	user := ctx.GetSessionVars().User
+	for _, e := range entries {
+		if !showAll && e.User != user {
+			continue
+		}
+		t := types.Time{Time: types.FromGoTime(e.Time.In(time.Local)), Type: mysql.TypeDatetime, Fsp: types.MaxFsp}
+		records = append(records, types.MakeDatums(
+			t,
+			e.TxnStartTS,
+			e.User,
+			e.ConnID,
+			e.QueryTime.Seconds(),
+			e.ParseTime.Seconds(),
+			e.CompileTime.Seconds(),
+			e.CopTasks,
+			e.ProcessKeys,
+			e.DB,
+			strings.Join(e.IndexNames, ","),
+			e.Digest,
+			e.Query,
+		))
+	}
+	return records, nil
+}
+
 func dataForSessionVar(ctx context.Context) (records [][]types.Datum, err error) {
 	sessionVars := ctx.GetSessionVars()
 	for _, v := range variable.SysVars {
@@ -633,6 +697,33 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
 		for _, table := range schema.Tables {
+			if table.IsView() {
+				record := types.MakeDatums(
+					catalogVal,    // TABLE_CATALOG
+					schema.Name.O, // TABLE_SCHEMA
+					table.Name.O,  // TABLE_NAME
+					"VIEW",        // TABLE_TYPE
+					nil,           // ENGINE
+					nil,           // VERSION
+					nil,           // ROW_FORMAT
+					nil,           // TABLE_ROWS
+					nil,           // AVG_ROW_LENGTH
+					nil,           // DATA_LENGTH
+					nil,           // MAX_DATA_LENGTH
+					nil,           // INDEX_LENGTH
+					nil,           // DATA_FREE
+					nil,           // AUTO_INCREMENT
+					nil,           // CREATE_TIME
+					nil,           // UPDATE_TIME
+					nil,           // CHECK_TIME
+					nil,           // TABLE_COLLATION
+					nil,           // CHECKSUM
+					nil,           // CREATE_OPTIONS
+					"VIEW",        // TABLE_COMMENT
+				)
+				rows = append(rows, record)
+				continue
+			}
 			record := types.MakeDatums(
 				catalogVal,          // TABLE_CATALOG
 				schema.Name.O,       // TABLE_SCHEMA
@@ -662,6 +753,31 @@ func dataForTables(schemas []*model.DBInfo) [][]types.Datum {
 	return rows
 }
 
+func dataForViews(schemas []*model.DBInfo) [][]types.Datum {
+	rows := [][]types.Datum{}
+	for _, schema := range schemas {
+		for _, table := range schema.Tables {
+			if !table.IsView() {
+				continue
+			}
+			record := types.MakeDatums(
+				catalogVal,            // TABLE_CATALOG
+				schema.Name.O,         // TABLE_SCHEMA
+				table.Name.O,          // TABLE_NAME
+				table.View.SelectStmt, // VIEW_DEFINITION
+				"NONE",                // CHECK_OPTION
+				"NO",                  // IS_UPDATABLE
+				table.View.Definer,    // DEFINER
+				"DEFINER",             // SECURITY_TYPE
+				charset.CharsetUTF8,   // CHARACTER_SET_CLIENT
+				charset.CollationUTF8, // COLLATION_CONNECTION
+			)
+			rows = append(rows, record)
+		}
+	}
+	return rows
+}
+
 func dataForColumns(schemas []*model.DBInfo) [][]types.Datum {
 	rows := [][]types.Datum{}
 	for _, schema := range schemas {
@@ -974,6 +1090,7 @@ var tableNameToColumns = map[string]([]columnInfo){
 	tableOptimizerTrace:                     tableOptimizerTraceCols,
 	tableTableSpaces:                        tableTableSpacesCols,
 	tableCollationCharacterSetApplicability: tableCollationCharacterSetApplicabilityCols,
+	tableSlowQuery:                          tableSlowQueryCols,
 }
 
 func createInfoSchemaTable(handle *Handle, meta *model.TableInfo) *infoschemaTable {
@@ -1043,6 +1160,7 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableEngines:
 		fullRows = dataForEngines()
 	case tableViews:
+		fullRows = dataForViews(dbs)
 	case tableRoutines:
 	// TODO: Fill the following tables.
 	case tableSchemaPrivileges:
@@ -1056,6 +1174,8 @@ func (it *infoschemaTable) getRows(ctx context.Context, cols []*table.Column) (f
 	case tableOptimizerTrace:
 	case tableTableSpaces:
 	case tableCollationCharacterSetApplicability:
+	case tableSlowQuery:
+		fullRows, err = dataForSlowQuery(ctx)
 	}
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -14,6 +14,7 @@
 package inspectkv
 
 import (
+	"bytes"
 	"io"
 	"reflect"
 	"time"
@@ -82,6 +83,107 @@ func GetBgDDLInfo(txn kv.Transaction) (*DDLInfo, error) {
 	return info, nil
 }
 
+// GetDDLJobs returns the DDL jobs in the queue, the running job comes first.
+func GetDDLJobs(txn kv.Transaction) ([]*model.Job, error) {
+	t := meta.NewMeta(txn)
+	jobs, err := t.GetAllDDLJobs()
+	return jobs, errors.Trace(err)
+}
+
+// DefNumHistoryJobs is the number of the history DDL jobs returned by GetHistoryDDLJobs.
+const DefNumHistoryJobs = 10
+
+// GetHistoryDDLJobs returns the latest DefNumHistoryJobs history DDL jobs, the most recent job comes first.
+func GetHistoryDDLJobs(txn kv.Transaction) ([]*model.Job, error) {
+	t := meta.NewMeta(txn)
+	jobs, err := t.GetLastNHistoryDDLJobs(DefNumHistoryJobs)
+	return jobs, errors.Trace(err)
+}
+
+// CancelJobs marks the DDL jobs of ids in the queue as cancelling, and the DDL owner cancels them later.
+// It returns an error for every id, the error is nil if the job is marked successfully.
+func CancelJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	return updateJobs(txn, ids, func(_ int, job *model.Job) error {
+		switch {
+		case job.IsFinished() || job.IsSynced():
+			return errCancelFinishedDDLJob.GenByArgs(job.ID)
+		case !isJobCancellable(job):
+			return errCannotCancelDDLJob.GenByArgs(job.ID)
+		}
+		job.State = model.JobCancelling
+		return nil
+	})
+}
+
+// PauseJobs marks the add index job of ids at the head of the queue as paused, and the DDL owner stops its
+// reorganization until it's resumed by ResumeJobs. Notice that the queue is serial and a paused job stays at
+// the head of the queue, because the jobs behind it may depend on the index. So the jobs behind it are blocked,
+// and the new DDL jobs are rejected until it's resumed or cancelled.
+// It returns an error for every id, the error is nil if the job is marked successfully.
+func PauseJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	return updateJobs(txn, ids, func(pos int, job *model.Job) error {
+		if pos != 0 || job.Type != model.ActionAddIndex || (job.State != model.JobNone && job.State != model.JobRunning) {
+			return errCannotPauseDDLJob.GenByArgs(job.ID)
+		}
+		job.State = model.JobPaused
+		return nil
+	})
+}
+
+// ResumeJobs marks the paused DDL jobs of ids in the queue as running, and the DDL owner continues them later.
+// It returns an error for every id, the error is nil if the job is marked successfully.
+func ResumeJobs(txn kv.Transaction, ids []int64) ([]error, error) {
+	return updateJobs(txn, ids, func(_ int, job *model.Job) error {
+		if !job.IsPaused() {
+			return errDDLJobNotPaused.GenByArgs(job.ID)
+		}
+		job.State = model.JobRunning
+		return nil
+	})
+}
+
+// updateJobs calls fn for the DDL jobs of ids and their positions in the queue, and writes the job back
+// if fn returns nil.
+func updateJobs(txn kv.Transaction, ids []int64, fn func(pos int, job *model.Job) error) ([]error, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	t := meta.NewMeta(txn)
+	jobs, err := t.GetAllDDLJobs()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	errs := make([]error, len(ids))
+	for i, id := range ids {
+		errs[i] = errDDLJobNotFound.GenByArgs(id)
+		for j, job := range jobs {
+			if job.ID != id {
+				continue
+			}
+			errs[i] = fn(j, job)
+			if errs[i] == nil {
+				// The args of the job aren't decoded, so the raw args are kept.
+				errs[i] = errors.Trace(t.UpdateDDLJob(int64(j), job, false))
+			}
+			break
+		}
+	}
+	return errs, nil
+}
+
+// isJobCancellable checks if the job can be cancelled safely. The job which hasn't changed the schema is cancelled
+// directly, the add index job can be rolled back by dropping the index, and the modify column job can be rolled
+// back by dropping the changing column before the original column is replaced.
+func isJobCancellable(job *model.Job) bool {
+	if job.State == model.JobRollback || job.IsCancelling() {
+		return false
+	}
+	return job.SchemaState == model.StateNone || job.Type == model.ActionAddIndex ||
+		(job.Type == model.ActionModifyColumn && job.SchemaState != model.StatePublic)
+}
+
 func nextIndexVals(data []types.Datum) []types.Datum {
 	// Add 0x0 to the end of data.
 	return append(data, types.Datum{})
@@ -235,6 +337,166 @@ func checkRecordAndIndex(txn kv.Transaction, t table.Table, idx table.Index) err
 	return nil
 }
 
+// MismatchedRecord is a pair of the inconsistent index data and record data of the same handle.
+// Index is nil if the index entry of the record is missing, and Record is nil if the record of
+// the index entry is missing.
+type MismatchedRecord struct {
+	Index  *RecordData
+	Record *RecordData
+}
+
+// CheckIndexRange compares at most limit records whose handles are in [begin, end] with their index entries,
+// the index is probed by the values of every record, so only the records in the range are scanned.
+// It returns the mismatched records, the handle of the last scanned record and the number of the scanned records.
+// The index entries whose records are missing aren't found by it, use CheckIndexEntry to check the index entries.
+func CheckIndexRange(txn kv.Transaction, t table.Table, idx table.Index, begin, end, limit int64) (
+	mismatches []*MismatchedRecord, lastHandle, scanCnt int64, err error) {
+	cols := indexColumns(t, idx)
+	filterFunc := func(h1 int64, vals1 []types.Datum, cols []*table.Column) (bool, error) {
+		if h1 > end {
+			return false, nil
+		}
+		lastHandle = h1
+		scanCnt++
+		isExist, h2, err := idx.Exist(txn, vals1, h1)
+		if terror.ErrorEqual(err, kv.ErrKeyExists) {
+			mismatches = append(mismatches, &MismatchedRecord{
+				Index:  &RecordData{Handle: h2, Values: vals1},
+				Record: &RecordData{Handle: h1, Values: vals1},
+			})
+			return scanCnt < limit, nil
+		}
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if !isExist {
+			mismatches = append(mismatches, &MismatchedRecord{Record: &RecordData{Handle: h1, Values: vals1}})
+		}
+		return scanCnt < limit, nil
+	}
+	err = iterRecords(txn, t, t.RecordKey(begin), cols, filterFunc)
+	if err != nil {
+		return nil, 0, 0, errors.Trace(err)
+	}
+	return mismatches, lastHandle, scanCnt, nil
+}
+
+// CheckIndexEntry compares the index entry of vals and h with its record.
+// It returns nil if the record exists and generates the same index entry.
+func CheckIndexEntry(txn kv.Transaction, t table.Table, idx table.Index, vals []types.Datum, h int64) (
+	*MismatchedRecord, error) {
+	recordVals, err := rowWithCols(txn, t, h, indexColumns(t, idx))
+	if terror.ErrorEqual(err, kv.ErrNotExist) {
+		return &MismatchedRecord{Index: &RecordData{Handle: h, Values: vals}}, nil
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	equal, err := indexValuesEqual(idx, vals, recordVals, h)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if equal {
+		return nil, nil
+	}
+	return &MismatchedRecord{
+		Index:  &RecordData{Handle: h, Values: vals},
+		Record: &RecordData{Handle: h, Values: recordVals},
+	}, nil
+}
+
+// RecoverIndex adds the missing index entries of at most limit records from startHandle.
+// It returns the handle of the last scanned record, the number of the scanned records
+// and the number of the added index entries.
+func RecoverIndex(txn kv.Transaction, t table.Table, idx table.Index, startHandle, limit int64) (
+	lastHandle, scanCnt, addedCnt int64, err error) {
+	cols := indexColumns(t, idx)
+	var records []*RecordData
+	filterFunc := func(h int64, vals []types.Datum, cols []*table.Column) (bool, error) {
+		records = append(records, &RecordData{Handle: h, Values: vals})
+		return int64(len(records)) < limit, nil
+	}
+	err = iterRecords(txn, t, t.RecordKey(startHandle), cols, filterFunc)
+	if err != nil {
+		return 0, 0, 0, errors.Trace(err)
+	}
+
+	for _, r := range records {
+		isExist, _, err1 := idx.Exist(txn, r.Values, r.Handle)
+		if terror.ErrorEqual(err1, kv.ErrKeyExists) {
+			return 0, 0, 0, kv.ErrKeyExists.Gen("Duplicate for key %s, handle:%d", idx.Meta().Name.O, r.Handle)
+		}
+		if err1 != nil {
+			return 0, 0, 0, errors.Trace(err1)
+		}
+		if isExist {
+			continue
+		}
+		_, err1 = idx.Create(txn, r.Values, r.Handle)
+		if err1 != nil {
+			return 0, 0, 0, errors.Trace(err1)
+		}
+		addedCnt++
+	}
+	if len(records) > 0 {
+		lastHandle = records[len(records)-1].Handle
+	}
+
+	return lastHandle, int64(len(records)), addedCnt, nil
+}
+
+// CleanupIndex deletes the index entries in idxRows whose records are missing or
+// don't match the entries. It returns the number of the deleted index entries.
+func CleanupIndex(txn kv.Transaction, t table.Table, idx table.Index, idxRows []*RecordData) (int64, error) {
+	cols := indexColumns(t, idx)
+	var removedCnt int64
+	for _, r := range idxRows {
+		vals, err := rowWithCols(txn, t, r.Handle, cols)
+		if err != nil && !terror.ErrorEqual(err, kv.ErrNotExist) {
+			return 0, errors.Trace(err)
+		}
+		if err == nil {
+			equal, err1 := indexValuesEqual(idx, r.Values, vals, r.Handle)
+			if err1 != nil {
+				return 0, errors.Trace(err1)
+			}
+			if equal {
+				continue
+			}
+		}
+		err = idx.Delete(txn, r.Values, r.Handle)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		removedCnt++
+	}
+
+	return removedCnt, nil
+}
+
+func indexColumns(t table.Table, idx table.Index) []*table.Column {
+	cols := make([]*table.Column, len(idx.Meta().Columns))
+	for i, col := range idx.Meta().Columns {
+		cols[i] = t.Cols()[col.Offset]
+	}
+	return cols
+}
+
+// indexValuesEqual checks whether the index values and the record values generate the same index key,
+// so the prefix index values are compared correctly.
+func indexValuesEqual(idx table.Index, idxVals, recordVals []types.Datum, h int64) (bool, error) {
+	// GenIndexKey may truncate the values, so copy them first.
+	key1, _, err := idx.GenIndexKey(append([]types.Datum(nil), idxVals...), h)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	key2, _, err := idx.GenIndexKey(append([]types.Datum(nil), recordVals...), h)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return bytes.Equal(key1, key2), nil
+}
+
 func scanTableData(retriever kv.Retriever, t table.Table, cols []*table.Column, startHandle, limit int64) (
 	[]*RecordData, int64, error) {
 	var records []*RecordData
@@ -477,13 +739,24 @@ func iterRecords(retriever kv.Retriever, t table.Table, startKey kv.Key, cols []
 
 // inspectkv error codes.
 const (
-	codeDataNotEqual       terror.ErrCode = 1
-	codeRepeatHandle                      = 2
-	codeInvalidColumnState                = 3
+	codeDataNotEqual         terror.ErrCode = 1
+	codeRepeatHandle                        = 2
+	codeInvalidColumnState                  = 3
+	codeDDLJobNotFound                      = 4
+	codeCancelFinishedDDLJob                = 5
+	codeCannotCancelDDLJob                  = 6
+	codeCannotPauseDDLJob                   = 7
+	codeDDLJobNotPaused                     = 8
 )
 
 var (
 	errDateNotEqual       = terror.ClassInspectkv.New(codeDataNotEqual, "data isn't equal")
 	errRepeatHandle       = terror.ClassInspectkv.New(codeRepeatHandle, "handle is repeated")
 	errInvalidColumnState = terror.ClassInspectkv.New(codeInvalidColumnState, "invalid column state")
+
+	errDDLJobNotFound       = terror.ClassInspectkv.New(codeDDLJobNotFound, "DDL Job:%v not found")
+	errCancelFinishedDDLJob = terror.ClassInspectkv.New(codeCancelFinishedDDLJob, "This job:%v is finished, so can't be cancelled")
+	errCannotCancelDDLJob   = terror.ClassInspectkv.New(codeCannotCancelDDLJob, "This job:%v can't be cancelled now")
+	errCannotPauseDDLJob    = terror.ClassInspectkv.New(codeCannotPauseDDLJob, "This job:%v can't be paused now")
+	errDDLJobNotPaused      = terror.ClassInspectkv.New(codeDDLJobNotPaused, "This job:%v isn't paused")
 )
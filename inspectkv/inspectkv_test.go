@@ -15,6 +15,7 @@ package inspectkv
 
 import (
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/table/tables"
 	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/codec"
 	"github.com/pingcap/tidb/util/mock"
 	"github.com/pingcap/tidb/util/testleak"
@@ -182,6 +184,150 @@ func (s *testSuite) TestGetBgDDLInfo(c *C) {
 	c.Assert(err, IsNil)
 }
 
+func (s *testSuite) TestDDLJobs(c *C) {
+	defer testleak.AfterTest(c)()
+	txn, err := s.store.Begin()
+	c.Assert(err, IsNil)
+	defer txn.Rollback()
+	t := meta.NewMeta(txn)
+
+	oldJobs, err := GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	jobs := []*model.Job{
+		{ID: 100, Type: model.ActionCreateTable, SchemaState: model.StateNone, State: model.JobRunning},
+		{ID: 101, Type: model.ActionAddIndex, SchemaState: model.StateWriteReorganization, State: model.JobRunning},
+		{ID: 102, Type: model.ActionDropTable, SchemaState: model.StateWriteOnly, State: model.JobRunning},
+		{ID: 103, Type: model.ActionAddIndex, SchemaState: model.StatePublic, State: model.JobDone},
+		{ID: 104, Type: model.ActionAddIndex, SchemaState: model.StateDeleteOnly, State: model.JobRollback},
+	}
+	for _, job := range jobs {
+		job.Args = []interface{}{job.ID}
+		err = t.EnQueueDDLJob(job)
+		c.Assert(err, IsNil)
+	}
+	allJobs, err := GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	c.Assert(allJobs, HasLen, len(oldJobs)+len(jobs))
+
+	errs, err := CancelJobs(txn, []int64{100, 101, 102, 103, 104, 105})
+	c.Assert(err, IsNil)
+	c.Assert(errs, HasLen, 6)
+	c.Assert(errs[0], IsNil)
+	c.Assert(errs[1], IsNil)
+	c.Assert(errs[2].Error(), Matches, ".*This job:102 can't be cancelled now")
+	c.Assert(errs[3].Error(), Matches, ".*This job:103 is finished, so can't be cancelled")
+	c.Assert(errs[4].Error(), Matches, ".*This job:104 can't be cancelled now")
+	c.Assert(errs[5].Error(), Matches, ".*DDL Job:105 not found")
+
+	allJobs, err = GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	for _, job := range allJobs[len(oldJobs):] {
+		if job.ID == 100 || job.ID == 101 {
+			c.Assert(job.State, Equals, model.JobCancelling)
+		} else {
+			c.Assert(job.State, Not(Equals), model.JobCancelling)
+		}
+		// The args are kept when the job is cancelled.
+		var id int64
+		c.Assert(job.DecodeArgs(&id), IsNil)
+		c.Assert(id, Equals, job.ID)
+	}
+	// A job can't be cancelled twice.
+	errs, err = CancelJobs(txn, []int64{100})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0].Error(), Matches, ".*This job:100 can't be cancelled now")
+
+	// A modify column job can't be cancelled after the original column is replaced.
+	modifyJobs := []*model.Job{
+		{ID: 110, Type: model.ActionModifyColumn, SchemaState: model.StateWriteReorganization, State: model.JobRunning},
+		{ID: 111, Type: model.ActionModifyColumn, SchemaState: model.StatePublic, State: model.JobRunning},
+	}
+	for _, job := range modifyJobs {
+		err = t.EnQueueDDLJob(job)
+		c.Assert(err, IsNil)
+	}
+	errs, err = CancelJobs(txn, []int64{110, 111})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0], IsNil)
+	c.Assert(errs[1].Error(), Matches, ".*This job:111 can't be cancelled now")
+
+	// Only the first job of the queue can be paused, so clear the queue first.
+	for {
+		job, err1 := t.DeQueueDDLJob()
+		c.Assert(err1, IsNil)
+		if job == nil {
+			break
+		}
+	}
+	pauseJobs := []*model.Job{
+		{ID: 106, Type: model.ActionAddIndex, SchemaState: model.StateWriteReorganization, State: model.JobRunning},
+		{ID: 107, Type: model.ActionAddIndex, SchemaState: model.StateNone, State: model.JobNone},
+		{ID: 108, Type: model.ActionCreateTable, SchemaState: model.StateNone, State: model.JobNone},
+	}
+	for _, job := range pauseJobs {
+		job.Args = []interface{}{job.ID}
+		err = t.EnQueueDDLJob(job)
+		c.Assert(err, IsNil)
+	}
+	errs, err = PauseJobs(txn, []int64{106, 107, 108, 105})
+	c.Assert(err, IsNil)
+	c.Assert(errs, HasLen, 4)
+	c.Assert(errs[0], IsNil)
+	c.Assert(errs[1].Error(), Matches, ".*This job:107 can't be paused now")
+	c.Assert(errs[2].Error(), Matches, ".*This job:108 can't be paused now")
+	c.Assert(errs[3].Error(), Matches, ".*DDL Job:105 not found")
+	// A job can't be paused twice.
+	errs, err = PauseJobs(txn, []int64{106})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0].Error(), Matches, ".*This job:106 can't be paused now")
+
+	errs, err = ResumeJobs(txn, []int64{106, 107, 108})
+	c.Assert(err, IsNil)
+	c.Assert(errs, HasLen, 3)
+	c.Assert(errs[0], IsNil)
+	c.Assert(errs[1].Error(), Matches, ".*This job:107 isn't paused")
+	c.Assert(errs[2].Error(), Matches, ".*This job:108 isn't paused")
+	// A paused job can be cancelled.
+	errs, err = PauseJobs(txn, []int64{106})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0], IsNil)
+	errs, err = CancelJobs(txn, []int64{106})
+	c.Assert(err, IsNil)
+	c.Assert(errs[0], IsNil)
+	allJobs, err = GetDDLJobs(txn)
+	c.Assert(err, IsNil)
+	states := make(map[int64]model.JobState)
+	for _, job := range allJobs {
+		states[job.ID] = job.State
+	}
+	c.Assert(states, DeepEquals, map[int64]model.JobState{
+		106: model.JobCancelling,
+		107: model.JobNone,
+		108: model.JobNone,
+	})
+
+	// The job IDs are global IDs.
+	var historyIDs []int64
+	for i := int64(0); i < DefNumHistoryJobs+2; i++ {
+		id, err1 := t.GenGlobalID()
+		c.Assert(err1, IsNil)
+		err = t.AddHistoryDDLJob(&model.Job{ID: id})
+		c.Assert(err, IsNil)
+		historyIDs = append(historyIDs, id)
+		// Other global IDs are allocated between the jobs.
+		for j := int64(0); j < i; j++ {
+			_, err = t.GenGlobalID()
+			c.Assert(err, IsNil)
+		}
+	}
+	historyJobs, err := GetHistoryDDLJobs(txn)
+	c.Assert(err, IsNil)
+	c.Assert(historyJobs, HasLen, DefNumHistoryJobs)
+	for i, job := range historyJobs {
+		c.Assert(job.ID, Equals, historyIDs[len(historyIDs)-1-i])
+	}
+}
+
 func (s *testSuite) TestScan(c *C) {
 	defer testleak.AfterTest(c)()
 	alloc := autoid.NewAllocator(s.store, s.dbInfo.ID)
@@ -222,7 +368,7 @@ func (s *testSuite) TestScan(c *C) {
 
 	idxRow1 := &RecordData{Handle: int64(1), Values: types.MakeDatums(int64(10))}
 	idxRow2 := &RecordData{Handle: int64(2), Values: types.MakeDatums(int64(20))}
-	kvIndex := tables.NewIndex(tb.Meta(), indices[0].Meta())
+	kvIndex := tables.NewIndex(tb.Meta().ID, tb.Meta(), indices[0].Meta())
 	idxRows, nextVals, err := ScanIndexData(txn, kvIndex, idxRow1.Values, 2)
 	c.Assert(err, IsNil)
 	c.Assert(idxRows, DeepEquals, []*RecordData{idxRow1, idxRow2})
@@ -402,6 +548,87 @@ func (s *testSuite) testIndex(c *C, tb table.Table, idx table.Index) {
 	c.Assert(err, NotNil)
 	diffMsg = newDiffRetError("index", nil, record1)
 	c.Assert(err.Error(), DeepEquals, diffMsg)
+
+	// index     data (handle, data): (1, 10), (2, 20), (3, 30)
+	// table     data (handle, data): (1, 10), (2, 20), (3, 30), (4, 40), (5, 30)
+	mismatches, lastHandle, scanCnt, err := CheckIndexRange(txn, tb, idx, math.MinInt64, math.MaxInt64, 10)
+	c.Assert(err, IsNil)
+	c.Assert([]int64{lastHandle, scanCnt}, DeepEquals, []int64{5, 5})
+	c.Assert(mismatches, DeepEquals, []*MismatchedRecord{
+		{Record: &RecordData{Handle: 4, Values: types.MakeDatums(int64(40))}},
+		{
+			Index:  &RecordData{Handle: 3, Values: types.MakeDatums(int64(30))},
+			Record: &RecordData{Handle: 5, Values: types.MakeDatums(int64(30))},
+		},
+	})
+	mismatches, lastHandle, scanCnt, err = CheckIndexRange(txn, tb, idx, math.MinInt64, math.MaxInt64, 2)
+	c.Assert(err, IsNil)
+	c.Assert([]int64{lastHandle, scanCnt}, DeepEquals, []int64{2, 2})
+	c.Assert(mismatches, HasLen, 0)
+	mismatches, _, scanCnt, err = CheckIndexRange(txn, tb, idx, 1, 3, 10)
+	c.Assert(err, IsNil)
+	c.Assert(scanCnt, Equals, int64(3))
+	c.Assert(mismatches, HasLen, 0)
+	mismatches, _, scanCnt, err = CheckIndexRange(txn, tb, idx, 4, 4, 10)
+	c.Assert(err, IsNil)
+	c.Assert(scanCnt, Equals, int64(1))
+	c.Assert(mismatches, HasLen, 1)
+	_, _, _, err = RecoverIndex(txn, tb, idx, 5, 10)
+	c.Assert(terror.ErrorEqual(err, kv.ErrKeyExists), IsTrue)
+
+	// index     data (handle, data): (1, 10), (2, 20), (3, 30), (4, 40)
+	// table     data (handle, data): (1, 10), (2, 20), (3, 30), (4, 40)
+	key = tablecodec.EncodeRowKey(tb.Meta().ID, codec.EncodeInt(nil, 5))
+	c.Assert(txn.Delete(key), IsNil)
+	var addedCnt int64
+	lastHandle, scanCnt, addedCnt, err = RecoverIndex(txn, tb, idx, math.MinInt64, 2)
+	c.Assert(err, IsNil)
+	c.Assert([]int64{lastHandle, scanCnt, addedCnt}, DeepEquals, []int64{2, 2, 0})
+	lastHandle, scanCnt, addedCnt, err = RecoverIndex(txn, tb, idx, lastHandle+1, 10)
+	c.Assert(err, IsNil)
+	c.Assert([]int64{lastHandle, scanCnt, addedCnt}, DeepEquals, []int64{4, 2, 1})
+	mismatches, _, _, err = CheckIndexRange(txn, tb, idx, math.MinInt64, math.MaxInt64, 10)
+	c.Assert(err, IsNil)
+	c.Assert(mismatches, HasLen, 0)
+
+	// index     data (handle, data): (1, 10), (2, 20), (3, 30), (4, 40), (6, 60)
+	// table     data (handle, data): (1, 10), (2, 21), (3, 30), (4, 40)
+	_, err = idx.Create(txn, types.MakeDatums(int64(60)), 6)
+	c.Assert(err, IsNil)
+	key = tablecodec.EncodeRowKey(tb.Meta().ID, codec.EncodeInt(nil, 2))
+	setColValue(c, txn, key, types.NewDatum(int64(21)))
+	mismatches, _, _, err = CheckIndexRange(txn, tb, idx, math.MinInt64, math.MaxInt64, 10)
+	c.Assert(err, IsNil)
+	c.Assert(mismatches, DeepEquals, []*MismatchedRecord{
+		{Record: &RecordData{Handle: 2, Values: types.MakeDatums(int64(21))}},
+	})
+	idxRows, _, err := ScanIndexData(txn, idx, nil, -1)
+	c.Assert(err, IsNil)
+	mismatches = mismatches[:0]
+	for _, r := range idxRows {
+		m, err1 := CheckIndexEntry(txn, tb, idx, r.Values, r.Handle)
+		c.Assert(err1, IsNil)
+		if m != nil {
+			mismatches = append(mismatches, m)
+		}
+	}
+	c.Assert(mismatches, DeepEquals, []*MismatchedRecord{
+		{
+			Index:  &RecordData{Handle: 2, Values: types.MakeDatums(int64(20))},
+			Record: &RecordData{Handle: 2, Values: types.MakeDatums(int64(21))},
+		},
+		{Index: &RecordData{Handle: 6, Values: types.MakeDatums(int64(60))}},
+	})
+	removedCnt, err := CleanupIndex(txn, tb, idx, idxRows)
+	c.Assert(err, IsNil)
+	c.Assert(removedCnt, Equals, int64(2))
+	_, scanCnt, addedCnt, err = RecoverIndex(txn, tb, idx, math.MinInt64, 10)
+	c.Assert(err, IsNil)
+	c.Assert([]int64{scanCnt, addedCnt}, DeepEquals, []int64{4, 1})
+	mismatches, _, _, err = CheckIndexRange(txn, tb, idx, math.MinInt64, math.MaxInt64, 10)
+	c.Assert(err, IsNil)
+	c.Assert(mismatches, HasLen, 0)
+	c.Assert(txn.Rollback(), IsNil)
 }
 
 func setColValue(c *C, txn kv.Transaction, key kv.Key, v types.Datum) {
@@ -34,8 +34,14 @@ const (
 	codeNotImplemented                            = 10
 	codeTxnTooLarge                               = 11
 	codeEntryTooLarge                             = 12
+	codeWriteConflict                             = 13
+	codePessimisticTxnNotSupported                = 14
+	codeLargeTxnNotSupported                      = 15
+	codePessimisticLockExpired                    = 16
 
-	codeKeyExists = 1062
+	codeKeyExists       = 1062
+	codeLockWaitTimeout = 1205
+	codeDeadlock        = 1213
 )
 
 var (
@@ -60,6 +66,19 @@ var (
 	ErrTxnTooLarge = terror.ClassKV.New(codeTxnTooLarge, "transaction is too large")
 	// ErrEntryTooLarge is the error when a key value entry is too large.
 	ErrEntryTooLarge = terror.ClassKV.New(codeEntryTooLarge, "entry is too large")
+	// ErrWriteConflict is the error when the pessimistic lock finds the key is written after the for update timestamp.
+	ErrWriteConflict = terror.ClassKV.New(codeWriteConflict, "write conflict")
+	// ErrPessimisticTxnNotSupported is the error when the pessimistic transaction is used on the storage which doesn't support it.
+	ErrPessimisticTxnNotSupported = terror.ClassKV.New(codePessimisticTxnNotSupported, "pessimistic transaction is not supported by the storage")
+	// ErrLargeTxnNotSupported is the error when the large transaction is used on the storage which doesn't support it.
+	ErrLargeTxnNotSupported = terror.ClassKV.New(codeLargeTxnNotSupported, "large transaction is not supported by the storage")
+	// ErrPessimisticLockExpired is the error when the pessimistic locks of a transaction may be released by the
+	// other transactions, because the transaction lasts longer than their TTL and the TTL can't be extended.
+	ErrPessimisticLockExpired = terror.ClassKV.New(codePessimisticLockExpired, "pessimistic locks are expired after %d ms, the transaction must be rolled back")
+	// ErrLockWaitTimeout is the error when the pessimistic lock waits for another transaction too long.
+	ErrLockWaitTimeout = terror.ClassKV.New(codeLockWaitTimeout, mysql.MySQLErrName[mysql.ErrLockWaitTimeout])
+	// ErrDeadlock is the error when the pessimistic lock waits for a transaction which is waiting for it.
+	ErrDeadlock = terror.ClassKV.New(codeDeadlock, mysql.MySQLErrName[mysql.ErrLockDeadlock])
 
 	// ErrNotCommitted is the error returned by CommitVersion when this
 	// transaction is not committed.
@@ -73,7 +92,9 @@ var (
 
 func init() {
 	kvMySQLErrCodes := map[terror.ErrCode]uint16{
-		codeKeyExists: mysql.ErrDupEntry,
+		codeKeyExists:       mysql.ErrDupEntry,
+		codeLockWaitTimeout: mysql.ErrLockWaitTimeout,
+		codeDeadlock:        mysql.ErrLockDeadlock,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassKV] = kvMySQLErrCodes
 }
@@ -38,6 +38,19 @@ const (
 	IsolationLevel
 	// Priority marks the priority of this transaction.
 	Priority
+	// ForUpdateTS is the timestamp of the running pessimistic locking statement. When it is set, LockKeys
+	// acquires the pessimistic locks which are checked for write conflicts at this timestamp, and the
+	// transaction reads the data of this timestamp.
+	ForUpdateTS
+	// LockWaitTimeout is the max time.Duration the pessimistic locks wait for the locks held by other transactions.
+	LockWaitTimeout
+	// LargeTxn marks the transaction as a large transaction. Its buffer isn't limited by TxnEntryCountLimit and
+	// TxnTotalSizeLimit and spills to disk when it's too large, and its 2PC runs in batches. It's set by
+	// Transaction.SetLarge.
+	LargeTxn
+	// LockCtx is the goctx.Context of the running pessimistic locking statement. The pessimistic locks stop
+	// waiting for the locks held by other transactions when it's done, e.g. the statement is killed.
+	LockCtx
 )
 
 // Priority value for transaction priority.
@@ -67,6 +80,9 @@ var (
 	TxnTotalSizeLimit = 100 * 1024 * 1024
 )
 
+// LargeTxnSpillSize is the size of the buffer of a large transaction beyond which the buffer spills to disk.
+var LargeTxnSpillSize = 64 * 1024 * 1024
+
 // Retriever is the interface wraps the basic Get and Seek methods.
 type Retriever interface {
 	// Get gets the value for key k from kv store.
@@ -124,6 +140,9 @@ type Transaction interface {
 	SetOption(opt Option, val interface{})
 	// DelOption deletes an option.
 	DelOption(opt Option)
+	// SetLarge makes the transaction a large transaction, see LargeTxn. It should be called before the
+	// transaction writes any data.
+	SetLarge() error
 	// IsReadOnly checks if the transaction has only performed read operations.
 	IsReadOnly() bool
 	// StartTS returns the transaction start timestamp.
@@ -218,6 +237,35 @@ type Storage interface {
 	GetOracle() oracle.Oracle
 }
 
+// PessimisticTxnStorage is the storage which may support the pessimistic transactions. The pessimistic
+// transactions need the storage to lock the keys before the transactions commit.
+type PessimisticTxnStorage interface {
+	// SupportPessimisticTxn returns true if the storage supports the pessimistic transactions.
+	SupportPessimisticTxn() bool
+}
+
+// SupportPessimisticTxn returns true if the store supports the pessimistic transactions.
+func SupportPessimisticTxn(store Storage) bool {
+	if s, ok := store.(PessimisticTxnStorage); ok {
+		return s.SupportPessimisticTxn()
+	}
+	return false
+}
+
+// LargeTxnStorage is the storage which may support the large transactions.
+type LargeTxnStorage interface {
+	// SupportLargeTxn returns true if the storage supports the large transactions.
+	SupportLargeTxn() bool
+}
+
+// SupportLargeTxn returns true if the store supports the large transactions.
+func SupportLargeTxn(store Storage) bool {
+	if s, ok := store.(LargeTxnStorage); ok {
+		return s.SupportLargeTxn()
+	}
+	return false
+}
+
 // FnKeyCmp is the function for iterator the keys
 type FnKeyCmp func(key Key) bool
 
@@ -0,0 +1,208 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kv
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/goleveldb/leveldb"
+	"github.com/pingcap/goleveldb/leveldb/comparer"
+	"github.com/pingcap/goleveldb/leveldb/memdb"
+	"github.com/pingcap/goleveldb/leveldb/util"
+	"github.com/pingcap/tidb/terror"
+)
+
+// largeMemBuffer is the MemBuffer of a large transaction. It isn't limited by TxnEntryCountLimit and
+// TxnTotalSizeLimit, and it spills to a temporary leveldb on disk when its size exceeds LargeTxnSpillSize.
+type largeMemBuffer struct {
+	// mem is nil after the buffer spills to disk.
+	mem  *memdb.DB
+	dir  string
+	disk *leveldb.DB
+	// size and length are approximate after spilling, the overwritten entries are counted repeatedly.
+	size   int
+	length int
+}
+
+// diskIter is the iterator on the spilled buffer, it copies the keys and values because leveldb reuses
+// their memory when the iterator moves.
+type diskIter struct {
+	memDbIter
+}
+
+// Key implements the Iterator Key.
+func (i *diskIter) Key() Key {
+	return append(Key(nil), i.iter.Key()...)
+}
+
+// Value implements the Iterator Value.
+func (i *diskIter) Value() []byte {
+	return append([]byte(nil), i.iter.Value()...)
+}
+
+func newLargeMemBuffer() *largeMemBuffer {
+	return &largeMemBuffer{
+		mem: memdb.New(comparer.DefaultComparer, 4*1024),
+	}
+}
+
+func (m *largeMemBuffer) newIter(r *util.Range, reverse bool) Iterator {
+	if m.disk != nil {
+		return &diskIter{memDbIter{iter: m.disk.NewIterator(r, nil), reverse: reverse}}
+	}
+	return &memDbIter{iter: m.mem.NewIterator(r), reverse: reverse}
+}
+
+// Seek implements the Retriever Seek interface.
+func (m *largeMemBuffer) Seek(k Key) (Iterator, error) {
+	i := m.newIter(&util.Range{Start: []byte(k)}, false)
+	i.Next()
+	return i, nil
+}
+
+// SeekReverse implements the Retriever SeekReverse interface.
+func (m *largeMemBuffer) SeekReverse(k Key) (Iterator, error) {
+	r := &util.Range{}
+	if k != nil {
+		r.Limit = []byte(k)
+	}
+	i := m.newIter(r, true)
+	if di, ok := i.(*diskIter); ok {
+		di.iter.Last()
+	} else {
+		i.(*memDbIter).iter.Last()
+	}
+	return i, nil
+}
+
+// Get implements the Retriever Get interface.
+func (m *largeMemBuffer) Get(k Key) ([]byte, error) {
+	var (
+		v   []byte
+		err error
+	)
+	if m.disk != nil {
+		v, err = m.disk.Get(k, nil)
+	} else {
+		v, err = m.mem.Get(k)
+	}
+	if terror.ErrorEqual(err, leveldb.ErrNotFound) {
+		return nil, ErrNotExist
+	}
+	return v, errors.Trace(err)
+}
+
+// Set implements the Mutator Set interface.
+func (m *largeMemBuffer) Set(k Key, v []byte) error {
+	if len(v) == 0 {
+		return errors.Trace(ErrCannotSetNilValue)
+	}
+	if len(k)+len(v) > TxnEntrySizeLimit {
+		return ErrEntryTooLarge.Gen("entry too large, size: %d", len(k)+len(v))
+	}
+	return errors.Trace(m.put(k, v))
+}
+
+// Delete implements the Mutator Delete interface.
+func (m *largeMemBuffer) Delete(k Key) error {
+	return errors.Trace(m.put(k, nil))
+}
+
+func (m *largeMemBuffer) put(k Key, v []byte) error {
+	if m.disk != nil {
+		m.size += len(k) + len(v)
+		m.length++
+		return errors.Trace(m.disk.Put(k, v, nil))
+	}
+	if err := m.mem.Put(k, v); err != nil {
+		return errors.Trace(err)
+	}
+	if m.mem.Size() > LargeTxnSpillSize {
+		return errors.Trace(m.spill())
+	}
+	return nil
+}
+
+// spill moves the entries in memory to a temporary leveldb.
+func (m *largeMemBuffer) spill() error {
+	dir, err := ioutil.TempDir("", "tidb-txn-")
+	if err != nil {
+		return errors.Trace(err)
+	}
+	db, err := leveldb.OpenFile(dir, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		return errors.Trace(err)
+	}
+	const batchSize = 1024
+	batch := new(leveldb.Batch)
+	iter := m.mem.NewIterator(nil)
+	defer iter.Release()
+	for iter.Next() {
+		batch.Put(iter.Key(), iter.Value())
+		if batch.Len() < batchSize {
+			continue
+		}
+		if err = db.Write(batch, nil); err != nil {
+			break
+		}
+		batch.Reset()
+	}
+	if err == nil {
+		err = db.Write(batch, nil)
+	}
+	if err != nil {
+		db.Close()
+		os.RemoveAll(dir)
+		return errors.Trace(err)
+	}
+	log.Infof("[kv] the buffer of the large transaction spills to %s, size: %d, len: %d", dir, m.mem.Size(), m.mem.Len())
+	m.size, m.length = m.mem.Size(), m.mem.Len()
+	m.mem = nil
+	m.dir, m.disk = dir, db
+	return nil
+}
+
+// Size implements the MemBuffer Size interface.
+func (m *largeMemBuffer) Size() int {
+	if m.disk != nil {
+		return m.size
+	}
+	return m.mem.Size()
+}
+
+// Len implements the MemBuffer Len interface.
+func (m *largeMemBuffer) Len() int {
+	if m.disk != nil {
+		return m.length
+	}
+	return m.mem.Len()
+}
+
+// release removes the temporary leveldb.
+func (m *largeMemBuffer) release() {
+	if m.disk == nil {
+		return
+	}
+	if err := m.disk.Close(); err != nil {
+		log.Warnf("[kv] close the spilled buffer %s failed %v", m.dir, err)
+	}
+	if err := os.RemoveAll(m.dir); err != nil {
+		log.Warnf("[kv] remove the spilled buffer %s failed %v", m.dir, err)
+	}
+	m.disk = nil
+}
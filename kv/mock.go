@@ -52,6 +52,11 @@ func (t *mockTxn) DelOption(opt Option) {
 	return
 }
 
+func (t *mockTxn) SetLarge() error {
+	t.opts[LargeTxn] = true
+	return nil
+}
+
 func (t *mockTxn) GetOption(opt Option) interface{} {
 	return t.opts[opt]
 }
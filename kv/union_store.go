@@ -35,6 +35,12 @@ type UnionStore interface {
 	DelOption(opt Option)
 	// GetOption gets an option.
 	GetOption(opt Option) interface{}
+	// SetLarge makes the buffer a large transaction buffer and sets the LargeTxn option.
+	SetLarge() error
+	// GetMemBuffer returns the buffer of the written data.
+	GetMemBuffer() MemBuffer
+	// Release releases the resources held by the buffer, it's called when the transaction finishes.
+	Release()
 }
 
 // Option is used for customizing kv store's behaviors during a transaction.
@@ -97,6 +103,46 @@ func (it invalidIterator) Close() {}
 
 type lazyMemBuffer struct {
 	mb MemBuffer
+	// large is true if the buffer is created for a large transaction.
+	large bool
+}
+
+func (lmb *lazyMemBuffer) newBuffer() MemBuffer {
+	if lmb.large {
+		return newLargeMemBuffer()
+	}
+	return NewMemDbBuffer()
+}
+
+// setLarge makes the buffer a large transaction buffer, the data written before is moved to the new buffer.
+func (lmb *lazyMemBuffer) setLarge() error {
+	if lmb.large {
+		return nil
+	}
+	lmb.large = true
+	if lmb.mb == nil {
+		return nil
+	}
+	mb := newLargeMemBuffer()
+	iter, err := lmb.mb.Seek(nil)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer iter.Close()
+	for ; iter.Valid(); iter.Next() {
+		if err = mb.put(iter.Key(), iter.Value()); err != nil {
+			mb.release()
+			return errors.Trace(err)
+		}
+	}
+	lmb.mb = mb
+	return nil
+}
+
+func (lmb *lazyMemBuffer) release() {
+	if mb, ok := lmb.mb.(*largeMemBuffer); ok {
+		mb.release()
+	}
 }
 
 func (lmb *lazyMemBuffer) Get(k Key) ([]byte, error) {
@@ -109,7 +155,7 @@ func (lmb *lazyMemBuffer) Get(k Key) ([]byte, error) {
 
 func (lmb *lazyMemBuffer) Set(key Key, value []byte) error {
 	if lmb.mb == nil {
-		lmb.mb = NewMemDbBuffer()
+		lmb.mb = lmb.newBuffer()
 	}
 
 	return lmb.mb.Set(key, value)
@@ -117,7 +163,7 @@ func (lmb *lazyMemBuffer) Set(key Key, value []byte) error {
 
 func (lmb *lazyMemBuffer) Delete(k Key) error {
 	if lmb.mb == nil {
-		lmb.mb = NewMemDbBuffer()
+		lmb.mb = lmb.newBuffer()
 	}
 
 	return lmb.mb.Delete(k)
@@ -220,6 +266,17 @@ func (us *unionStore) SetOption(opt Option, val interface{}) {
 	us.opts[opt] = val
 }
 
+// SetLarge implements the UnionStore SetLarge interface.
+func (us *unionStore) SetLarge() error {
+	if lmb, ok := us.MemBuffer.(*lazyMemBuffer); ok {
+		if err := lmb.setLarge(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	us.opts[LargeTxn] = true
+	return nil
+}
+
 // DelOption implements the UnionStore DelOption interface.
 func (us *unionStore) DelOption(opt Option) {
 	delete(us.opts, opt)
@@ -230,6 +287,18 @@ func (us *unionStore) GetOption(opt Option) interface{} {
 	return us.opts[opt]
 }
 
+// GetMemBuffer implements the UnionStore GetMemBuffer interface.
+func (us *unionStore) GetMemBuffer() MemBuffer {
+	return us.BufferStore.MemBuffer
+}
+
+// Release implements the UnionStore Release interface.
+func (us *unionStore) Release() {
+	if lmb, ok := us.MemBuffer.(*lazyMemBuffer); ok {
+		lmb.release()
+	}
+}
+
 type options map[Option]interface{}
 
 func (opts options) Get(opt Option) (interface{}, bool) {
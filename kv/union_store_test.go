@@ -14,6 +14,9 @@
 package kv
 
 import (
+	"fmt"
+	"os"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testleak"
@@ -137,3 +140,47 @@ func checkIterator(c *C, iter Iterator, keys [][]byte, values [][]byte) {
 	}
 	c.Assert(iter.Valid(), IsFalse)
 }
+
+func (s *testUnionStoreSuite) TestLargeTxnBuffer(c *C) {
+	defer testleak.AfterTest(c)()
+	defer func(size int) {
+		LargeTxnSpillSize = size
+	}(LargeTxnSpillSize)
+	LargeTxnSpillSize = 1024
+
+	s.us.Set([]byte("1"), []byte("1"))
+	c.Assert(s.us.SetLarge(), IsNil)
+	c.Assert(s.us.GetOption(LargeTxn), NotNil)
+	// The data written before the transaction becomes large is kept.
+	v, err := s.us.Get([]byte("1"))
+	c.Assert(err, IsNil)
+	c.Assert(v, BytesEquals, []byte("1"))
+
+	var keys, values [][]byte
+	for i := 0; i < 100; i++ {
+		k, v := []byte(fmt.Sprintf("key%03d", i)), make([]byte, 100)
+		c.Assert(s.us.Set(k, v), IsNil)
+		keys, values = append(keys, k), append(values, v)
+	}
+	mb := s.us.GetMemBuffer().(*lazyMemBuffer).mb.(*largeMemBuffer)
+	c.Assert(mb.disk, NotNil)
+	dir := mb.dir
+
+	// The spilled buffer works as the memory buffer.
+	c.Assert(s.us.Delete([]byte("1")), IsNil)
+	_, err = s.us.Get([]byte("1"))
+	c.Assert(IsErrNotFound(err), IsTrue)
+	iter, err := s.us.Seek([]byte("key"))
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, keys, values)
+	iter, err = s.us.GetMemBuffer().SeekReverse([]byte("key001"))
+	c.Assert(err, IsNil)
+	checkIterator(c, iter, [][]byte{keys[0], []byte("1")}, [][]byte{values[0], {}})
+	c.Assert(s.us.GetMemBuffer().Len(), Equals, 102)
+
+	_, err = os.Stat(dir)
+	c.Assert(err, IsNil)
+	s.us.Release()
+	_, err = os.Stat(dir)
+	c.Assert(os.IsNotExist(err), IsTrue)
+}
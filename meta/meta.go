@@ -485,8 +485,8 @@ func (m *Meta) GetDDLJob(index int64) (*model.Job, error) {
 	return job, errors.Trace(err)
 }
 
-func (m *Meta) updateDDLJob(index int64, job *model.Job, key []byte) error {
-	b, err := job.Encode(true)
+func (m *Meta) updateDDLJob(index int64, job *model.Job, key []byte, updateRawArgs bool) error {
+	b, err := job.Encode(updateRawArgs)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -494,8 +494,27 @@ func (m *Meta) updateDDLJob(index int64, job *model.Job, key []byte) error {
 }
 
 // UpdateDDLJob updates the DDL job with index.
-func (m *Meta) UpdateDDLJob(index int64, job *model.Job) error {
-	return m.updateDDLJob(index, job, mDDLJobListKey)
+// updateRawArgs is used to determine whether to update the raw args encoded from job.Args.
+func (m *Meta) UpdateDDLJob(index int64, job *model.Job, updateRawArgs bool) error {
+	return m.updateDDLJob(index, job, mDDLJobListKey, updateRawArgs)
+}
+
+// GetAllDDLJobs gets all the DDL jobs in the queue, they're in the order of execution.
+func (m *Meta) GetAllDDLJobs() ([]*model.Job, error) {
+	values, err := m.txn.LGetAll(mDDLJobListKey)
+	if err != nil || values == nil {
+		return nil, errors.Trace(err)
+	}
+	jobs := make([]*model.Job, 0, len(values))
+	for _, val := range values {
+		job := &model.Job{}
+		err = job.Decode(val)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
 }
 
 // DDLJobQueueLen returns the DDL job queue length.
@@ -539,6 +558,37 @@ func (m *Meta) GetHistoryDDLJob(id int64) (*model.Job, error) {
 	return m.getHistoryDDLJob(mDDLJobHistoryKey, id)
 }
 
+// GetLastNHistoryDDLJobs gets the latest num history DDL jobs, the most recent job comes first.
+// The job IDs are global IDs, so it scans the history backward from the current global ID in
+// growing ranges of job IDs, and stops when it gets enough jobs.
+func (m *Meta) GetLastNHistoryDDLJobs(num int) ([]*model.Job, error) {
+	maxID, err := m.GetGlobalID()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	jobs := make([]*model.Job, 0, num)
+	end := maxID + 1
+	for step := int64(num); len(jobs) < num && end > 0; step *= 2 {
+		start := end - step
+		if start < 0 {
+			start = 0
+		}
+		pairs, err := m.txn.HGetRange(mDDLJobHistoryKey, m.jobIDKey(start), m.jobIDKey(end))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		for i := len(pairs) - 1; i >= 0 && len(jobs) < num; i-- {
+			job := &model.Job{}
+			if err = job.Decode(pairs[i].Value); err != nil {
+				return nil, errors.Trace(err)
+			}
+			jobs = append(jobs, job)
+		}
+		end = start
+	}
+	return jobs, nil
+}
+
 // GetAllHistoryDDLJobs gets all history DDL jobs.
 func (m *Meta) GetAllHistoryDDLJobs() ([]*model.Job, error) {
 	pairs, err := m.txn.HGetAll(mDDLJobHistoryKey)
@@ -607,6 +657,22 @@ func (m *Meta) GetDDLReorgHandle(job *model.Job) (int64, error) {
 	return value, errors.Trace(err)
 }
 
+// UpdateDDLReorgPartition saves the partition which the job is reorganizing for later resuming.
+func (m *Meta) UpdateDDLReorgPartition(job *model.Job, partitionID int64) error {
+	err := m.txn.HSet(mDDLJobReorgKey, m.reorgPartitionKey(job.ID), []byte(strconv.FormatInt(partitionID, 10)))
+	return errors.Trace(err)
+}
+
+// GetDDLReorgPartition gets the partition which the job is reorganizing, it returns 0 if it's not saved.
+func (m *Meta) GetDDLReorgPartition(job *model.Job) (int64, error) {
+	value, err := m.txn.HGetInt64(mDDLJobReorgKey, m.reorgPartitionKey(job.ID))
+	return value, errors.Trace(err)
+}
+
+func (m *Meta) reorgPartitionKey(id int64) []byte {
+	return append(m.jobIDKey(id), "_partition"...)
+}
+
 // DDL background job structure
 //	BgJobOnwer: []byte
 //	BgJobList: list jobs
@@ -623,7 +689,7 @@ var (
 
 // UpdateBgJob updates the background job with index.
 func (m *Meta) UpdateBgJob(index int64, job *model.Job) error {
-	return m.updateDDLJob(index, job, mBgJobListKey)
+	return m.updateDDLJob(index, job, mBgJobListKey, true)
 }
 
 // GetBgJob returns the background job with index.
@@ -696,6 +762,119 @@ func (m *Meta) SetSchemaDiff(diff *model.SchemaDiff) error {
 	return errors.Trace(err)
 }
 
+// User level lock structure
+//	UserLocks: hash
+//		lock name -> owner of the lock []byte
+//
+// The user level locks are acquired by GET_LOCK, they are stored in the store
+// so that they are shared by all the TiDB servers.
+
+var mUserLocksKey = []byte("UserLocks")
+
+// GetUserLock gets the owner of the user level lock, it returns nil if the lock doesn't exist.
+func (m *Meta) GetUserLock(name string) ([]byte, error) {
+	value, err := m.txn.HGet(mUserLocksKey, []byte(name))
+	return value, errors.Trace(err)
+}
+
+// SetUserLock sets the owner of the user level lock.
+func (m *Meta) SetUserLock(name string, owner []byte) error {
+	err := m.txn.HSet(mUserLocksKey, []byte(name), owner)
+	return errors.Trace(err)
+}
+
+// RemoveUserLock removes the user level lock.
+func (m *Meta) RemoveUserLock(name string) error {
+	err := m.txn.HDel(mUserLocksKey, []byte(name))
+	return errors.Trace(err)
+}
+
+// Deadlock detection structure
+//	DeadlockServers: hash
+//		server ID -> server info []byte
+//	DeadlockWaitFor_{server ID}: hash
+//		wait-for edge -> edge info []byte
+//	DeadlockVictims_{server ID}: hash
+//		transaction -> victim info []byte
+//
+// The wait-for edges are reported by the transactions waiting for the pessimistic locks on all the TiDB servers,
+// and the owner of the deadlock detection chooses the victims to break the deadlocks. Every server writes its
+// own edges and reads its own victims, so the servers don't conflict with each other.
+
+var (
+	mDeadlockServersKey    = []byte("DeadlockServers")
+	mDeadlockWaitForPrefix = "DeadlockWaitFor"
+	mDeadlockVictimsPrefix = "DeadlockVictims"
+)
+
+func deadlockWaitForKey(server []byte) []byte {
+	return []byte(fmt.Sprintf("%s_%s", mDeadlockWaitForPrefix, server))
+}
+
+func deadlockVictimsKey(server []byte) []byte {
+	return []byte(fmt.Sprintf("%s_%s", mDeadlockVictimsPrefix, server))
+}
+
+// GetDeadlockServers gets all the servers which report the wait-for edges.
+func (m *Meta) GetDeadlockServers() ([]structure.HashPair, error) {
+	res, err := m.txn.HGetAll(mDeadlockServersKey)
+	return res, errors.Trace(err)
+}
+
+// SetDeadlockServer sets the server which reports the wait-for edges.
+func (m *Meta) SetDeadlockServer(server []byte, info []byte) error {
+	err := m.txn.HSet(mDeadlockServersKey, server, info)
+	return errors.Trace(err)
+}
+
+// RemoveDeadlockServer removes the server and its wait-for edges and victims.
+func (m *Meta) RemoveDeadlockServer(server []byte) error {
+	if err := m.txn.HClear(deadlockWaitForKey(server)); err != nil {
+		return errors.Trace(err)
+	}
+	if err := m.txn.HClear(deadlockVictimsKey(server)); err != nil {
+		return errors.Trace(err)
+	}
+	err := m.txn.HDel(mDeadlockServersKey, server)
+	return errors.Trace(err)
+}
+
+// GetWaitForEdges gets all the wait-for edges reported by the server.
+func (m *Meta) GetWaitForEdges(server []byte) ([]structure.HashPair, error) {
+	res, err := m.txn.HGetAll(deadlockWaitForKey(server))
+	return res, errors.Trace(err)
+}
+
+// SetWaitForEdge sets the wait-for edge reported by the server.
+func (m *Meta) SetWaitForEdge(server []byte, edge []byte, info []byte) error {
+	err := m.txn.HSet(deadlockWaitForKey(server), edge, info)
+	return errors.Trace(err)
+}
+
+// RemoveWaitForEdges removes the wait-for edges reported by the server.
+func (m *Meta) RemoveWaitForEdges(server []byte, edges ...[]byte) error {
+	err := m.txn.HDel(deadlockWaitForKey(server), edges...)
+	return errors.Trace(err)
+}
+
+// GetDeadlockVictims gets the deadlock victims among the transactions of the server.
+func (m *Meta) GetDeadlockVictims(server []byte) ([]structure.HashPair, error) {
+	res, err := m.txn.HGetAll(deadlockVictimsKey(server))
+	return res, errors.Trace(err)
+}
+
+// SetDeadlockVictim marks the transaction of the server as a deadlock victim.
+func (m *Meta) SetDeadlockVictim(server []byte, txn []byte, info []byte) error {
+	err := m.txn.HSet(deadlockVictimsKey(server), txn, info)
+	return errors.Trace(err)
+}
+
+// RemoveDeadlockVictims removes the victim marks of the transactions of the server.
+func (m *Meta) RemoveDeadlockVictims(server []byte, txns ...[]byte) error {
+	err := m.txn.HDel(deadlockVictimsKey(server), txns...)
+	return errors.Trace(err)
+}
+
 // meta error codes.
 const (
 	codeInvalidTableKey terror.ErrCode = 1
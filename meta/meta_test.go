@@ -280,8 +280,11 @@ func (s *testSuite) TestDDL(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v, IsNil)
 	job.ID = 2
-	err = t.UpdateDDLJob(0, job)
+	err = t.UpdateDDLJob(0, job, true)
 	c.Assert(err, IsNil)
+	jobs, err := t.GetAllDDLJobs()
+	c.Assert(err, IsNil)
+	c.Assert(jobs, DeepEquals, []*model.Job{job})
 
 	err = t.UpdateDDLReorgHandle(job, 1)
 	c.Assert(err, IsNil)
@@ -296,6 +299,9 @@ func (s *testSuite) TestDDL(c *C) {
 	v, err = t.DeQueueDDLJob()
 	c.Assert(err, IsNil)
 	c.Assert(v, DeepEquals, job)
+	jobs, err = t.GetAllDDLJobs()
+	c.Assert(err, IsNil)
+	c.Assert(jobs, HasLen, 0)
 
 	err = t.AddHistoryDDLJob(job)
 	c.Assert(err, IsNil)
@@ -311,6 +317,25 @@ func (s *testSuite) TestDDL(c *C) {
 		lastID = job.ID
 	}
 
+	for i := 0; i < 3; i++ {
+		id, err1 := t.GenGlobalID()
+		c.Assert(err1, IsNil)
+		c.Assert(t.AddHistoryDDLJob(&model.Job{ID: id}), IsNil)
+	}
+	maxID, err := t.GetGlobalID()
+	c.Assert(err, IsNil)
+	lastJobs, err := t.GetLastNHistoryDDLJobs(2)
+	c.Assert(err, IsNil)
+	c.Assert(lastJobs, HasLen, 2)
+	c.Assert(lastJobs[0].ID, Equals, maxID)
+	c.Assert(lastJobs[1].ID, Equals, maxID-1)
+	all, err = t.GetAllHistoryDDLJobs()
+	c.Assert(err, IsNil)
+	lastJobs, err = t.GetLastNHistoryDDLJobs(100)
+	c.Assert(err, IsNil)
+	c.Assert(lastJobs, HasLen, len(all))
+	c.Assert(lastJobs[len(lastJobs)-1].ID, Equals, all[0].ID)
+
 	bgJob := &model.Job{ID: 1}
 	err = t.EnQueueBgJob(bgJob)
 	c.Assert(err, IsNil)
@@ -42,6 +42,10 @@ const (
 	ActionModifyColumn
 	ActionRenameTable
 	ActionSetDefaultValue
+	ActionCreateView
+	ActionAddTablePartition
+	ActionDropTablePartition
+	ActionTruncateTablePartition
 )
 
 func (action ActionType) String() string {
@@ -74,6 +78,14 @@ func (action ActionType) String() string {
 		return "rename table"
 	case ActionSetDefaultValue:
 		return "set default value"
+	case ActionCreateView:
+		return "create view"
+	case ActionAddTablePartition:
+		return "add partition"
+	case ActionDropTablePartition:
+		return "drop partition"
+	case ActionTruncateTablePartition:
+		return "truncate partition"
 	default:
 		return "none"
 	}
@@ -111,7 +123,9 @@ type Job struct {
 	// ErrorCount will be increased, every time we meet an error when running job.
 	ErrorCount int64 `json:"err_count"`
 	// RowCount means the number of rows that are processed.
-	RowCount int64         `json:"row_count"`
+	RowCount int64 `json:"row_count"`
+	// Progress is the estimated completion percent of the reorganization, from 0 to 100.
+	Progress float64       `json:"progress"`
 	Mu       sync.Mutex    `json:"-"`
 	Args     []interface{} `json:"-"`
 	// RawArgs : We must use json raw message to delay parsing special args.
@@ -122,6 +136,8 @@ type Job struct {
 	// LastUpdateTS now uses unix nano seconds
 	// TODO: Use timestamp allocated by TSO.
 	LastUpdateTS int64 `json:"last_update_ts"`
+	// StartTS is the start timestamp of the transaction which adds the job to the queue.
+	StartTS uint64 `json:"start_ts"`
 	// Query string of the ddl job.
 	Query      string       `json:"query"`
 	BinlogInfo *HistoryInfo `json:"binlog"`
@@ -143,6 +159,22 @@ func (job *Job) GetRowCount() int64 {
 	return job.RowCount
 }
 
+// SetProgress sets the estimated completion percent of the reorganization.
+func (job *Job) SetProgress(progress float64) {
+	job.Mu.Lock()
+	defer job.Mu.Unlock()
+
+	job.Progress = progress
+}
+
+// GetProgress gets the estimated completion percent of the reorganization.
+func (job *Job) GetProgress() float64 {
+	job.Mu.Lock()
+	defer job.Mu.Unlock()
+
+	return job.Progress
+}
+
 // Encode encodes job with json format.
 // updateRawArgs is used to determine whether to update the raw args.
 func (job *Job) Encode(updateRawArgs bool) ([]byte, error) {
@@ -204,6 +236,16 @@ func (job *Job) IsDone() bool {
 	return job.State == JobDone
 }
 
+// IsCancelling returns whether the job is being cancelled by the user.
+func (job *Job) IsCancelling() bool {
+	return job.State == JobCancelling
+}
+
+// IsPaused returns whether the job is paused by the user.
+func (job *Job) IsPaused() bool {
+	return job.State == JobPaused
+}
+
 // IsRunning returns whether job is still running or not.
 func (job *Job) IsRunning() bool {
 	return job.State == JobRunning
@@ -226,6 +268,12 @@ const (
 	// JobSynced is used to mark the information about the completion of this job
 	// has been synchronized to all servers.
 	JobSynced
+	// JobCancelling is used to mark the job is cancelled by the user, the DDL worker will
+	// cancel or roll back the job.
+	JobCancelling
+	// JobPaused is used to mark the job is paused by the user, the DDL worker stops the
+	// reorganization and keeps the job at the head of the queue until it's resumed.
+	JobPaused
 )
 
 // String implements fmt.Stringer interface.
@@ -243,6 +291,10 @@ func (s JobState) String() string {
 		return "cancelled"
 	case JobSynced:
 		return "synced"
+	case JobCancelling:
+		return "cancelling"
+	case JobPaused:
+		return "paused"
 	default:
 		return "none"
 	}
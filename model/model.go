@@ -70,6 +70,19 @@ type ColumnInfo struct {
 	types.FieldType     `json:"type"`
 	State               SchemaState `json:"state"`
 	Comment             string      `json:"comment"`
+	// ChangeStateInfo is set if the column is being changed by a modify column job which reorganizes the data.
+	ChangeStateInfo *ChangeStateInfo `json:"change_state_info"`
+}
+
+// ChangeStateInfo is the information of a non-public column which is being changed by a modify column job.
+// The value of the column isn't given by the statements which write the row, it's converted from the value
+// of the column it depends on.
+type ChangeStateInfo struct {
+	// DependencyColumnOffset is the offset of the column which the column depends on.
+	DependencyColumnOffset int `json:"relative_col_offset"`
+	// Obsolete is true if the column is the original column which is being dropped,
+	// the conversion errors of an obsolete column are ignored.
+	Obsolete bool `json:"obsolete"`
 }
 
 // Clone clones ColumnInfo.
@@ -99,6 +112,11 @@ type TableInfo struct {
 	// We need to save original schemaID to keep autoID unchanged
 	// while renaming a table from one database to another.
 	OldSchemaID int64 `json:"old_schema_id,omitempty"`
+
+	// View is not nil if this table is a view.
+	View *ViewInfo `json:"view_info"`
+	// Partition is not nil if this table is partitioned.
+	Partition *PartitionInfo `json:"partition"`
 }
 
 // Clone clones TableInfo.
@@ -120,9 +138,80 @@ func (t *TableInfo) Clone() *TableInfo {
 		nt.ForeignKeys[i] = t.ForeignKeys[i].Clone()
 	}
 
+	if t.Partition != nil {
+		nt.Partition = t.Partition.Clone()
+	}
+
 	return &nt
 }
 
+// IsView checks if table is a view.
+func (t *TableInfo) IsView() bool {
+	return t.View != nil
+}
+
+// ViewInfo provides meta data describing a DB view.
+type ViewInfo struct {
+	// Definer is the user who created the view, in the form of "user@host".
+	Definer string `json:"view_definer"`
+	// SelectStmt is the original text of the select statement that defines the view.
+	SelectStmt string `json:"view_select"`
+	// Cols are the column names of the view.
+	Cols []CIStr `json:"view_cols"`
+}
+
+// PartitionType is the type for PartitionInfo.
+type PartitionType int
+
+// Partition types.
+const (
+	PartitionTypeRange PartitionType = 1
+	PartitionTypeHash  PartitionType = 2
+)
+
+// String implements fmt.Stringer interface.
+func (p PartitionType) String() string {
+	switch p {
+	case PartitionTypeRange:
+		return "RANGE"
+	case PartitionTypeHash:
+		return "HASH"
+	default:
+		return ""
+	}
+}
+
+// PartitionInfo provides meta data describing the partitions of a table.
+type PartitionInfo struct {
+	Type PartitionType `json:"type"`
+	// Expr is the text of the partition expression.
+	Expr string `json:"expr"`
+	// Num is the number of partitions.
+	Num         uint64                `json:"num"`
+	Definitions []PartitionDefinition `json:"definitions"`
+}
+
+// Clone clones PartitionInfo.
+func (pi *PartitionInfo) Clone() *PartitionInfo {
+	npi := *pi
+	npi.Definitions = make([]PartitionDefinition, len(pi.Definitions))
+	for i, def := range pi.Definitions {
+		npi.Definitions[i] = def
+		npi.Definitions[i].LessThan = append([]string(nil), def.LessThan...)
+	}
+	return &npi
+}
+
+// PartitionDefinition defines a single partition.
+// Each partition stores its data under its own physical table ID.
+type PartitionDefinition struct {
+	ID   int64 `json:"id"`
+	Name CIStr `json:"name"`
+	// LessThan is the upper bound of a range partition, it is empty for a hash partition,
+	// and "MAXVALUE" for the last range partition without upper bound.
+	LessThan []string `json:"less_than"`
+}
+
 // GetPkName will return the pk name if pk exists.
 func (t *TableInfo) GetPkName() CIStr {
 	if t.PKIsHandle {
@@ -882,12 +882,26 @@ const (
 	ErrMustChangePasswordLogin                                      = 1862
 	ErrRowInWrongPartition                                          = 1863
 	ErrErrorLast                                                    = 1863
+	ErrQueryTimeout                                                 = 3024
+	ErrUserLockWrongName                                            = 3057
 	ErrBadGeneratedColumn                                           = 3105
 	ErrUnsupportedOnGeneratedColumn                                 = 3106
 	ErrGeneratedColumnNonPrior                                      = 3107
 	ErrDependentByGeneratedColumn                                   = 3108
+	ErrWarnConflictingHint                                          = 3126
+	ErrUnresolvedHintName                                           = 3128
 	ErrInvalidJSONText                                              = 3140
 	ErrInvalidJSONPath                                              = 3143
 	ErrInvalidJSONData                                              = 3146
 	ErrJSONUsedAsKey                                                = 3152
+	ErrCTERecursiveRequiresUnion                                    = 3573
+	ErrCTERecursiveRequiresNonRecursiveFirst                        = 3574
+	ErrCTERecursiveForbidsAggregation                               = 3575
+	ErrCTERecursiveRequiresSingleReference                          = 3577
+	ErrWindowFrameStartIllegal                                      = 3581
+	ErrWindowFrameEndIllegal                                        = 3582
+	ErrWindowFrameIllegal                                           = 3586
+	ErrWindowRangeFrameOrderType                                    = 3587
+	ErrWindowInvalidWindowFuncUse                                   = 3593
+	ErrCTEMaxRecursionDepth                                         = 3636
 )
@@ -879,12 +879,26 @@ var MySQLErrName = map[uint16]string{
 	ErrAlterOperationNotSupportedReasonNotNull:               "cannot silently convert NULL values, as required in this SQLMODE",
 	ErrMustChangePasswordLogin:                               "Your password has expired. To log in you must change it using a client that supports expired passwords.",
 	ErrRowInWrongPartition:                                   "Found a row in wrong partition %s",
+	ErrQueryTimeout:                                          "Query execution was interrupted, maximum statement execution time exceeded",
+	ErrUserLockWrongName:                                     "Incorrect user-level lock name '%-.192s'.",
 	ErrBadGeneratedColumn:                                    "The value specified for generated column '%s' in table '%s' is not allowed.",
 	ErrUnsupportedOnGeneratedColumn:                          "'%s' is not supported for generated columns.",
 	ErrGeneratedColumnNonPrior:                               "Generated column can refer only to generated columns defined prior to it.",
 	ErrDependentByGeneratedColumn:                            "Column '%s' has a generated column dependency.",
+	ErrWarnConflictingHint:                                   "Hint %s is ignored as conflicting/duplicated",
+	ErrUnresolvedHintName:                                    "Unresolved name '%s' for %s hint",
 	ErrInvalidJSONText:                                       "Invalid JSON text: %-.192s",
 	ErrInvalidJSONPath:                                       "Invalid JSON path expression %s.",
 	ErrInvalidJSONData:                                       "Invalid data type for JSON data",
 	ErrJSONUsedAsKey:                                         "JSON column '%-.192s' cannot be used in key specification.",
+	ErrCTERecursiveRequiresUnion:                             "Recursive Common Table Expression '%s' should contain a UNION",
+	ErrCTERecursiveRequiresNonRecursiveFirst:                 "Recursive Common Table Expression '%s' should have one or more non-recursive query blocks followed by one or more recursive ones",
+	ErrCTERecursiveForbidsAggregation:                        "Recursive Common Table Expression '%s' can contain neither aggregation nor window functions in recursive query block",
+	ErrCTERecursiveRequiresSingleReference:                   "In recursive query block of Recursive Common Table Expression '%s', the recursive table must be referenced only once, and not in any subquery",
+	ErrWindowFrameStartIllegal:                               "Window '%s': frame start cannot be UNBOUNDED FOLLOWING.",
+	ErrWindowFrameEndIllegal:                                 "Window '%s': frame end cannot be UNBOUNDED PRECEDING.",
+	ErrWindowFrameIllegal:                                    "Window '%s': frame start or end is negative, NULL or of non-integral type",
+	ErrWindowRangeFrameOrderType:                             "Window '%s' with RANGE N PRECEDING/FOLLOWING frame requires exactly one ORDER BY expression, of numeric or temporal type",
+	ErrWindowInvalidWindowFuncUse:                            "You cannot use the window function '%s' in this context.'",
+	ErrCTEMaxRecursionDepth:                                  "Recursive query aborted after %d iterations. Try increasing @@cte_max_recursion_depth to a larger value.",
 }
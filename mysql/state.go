@@ -254,4 +254,15 @@ var MySQLState = map[uint16]string{
 	ErrInvalidJSONPath:                     "42000",
 	ErrInvalidJSONData:                     "22032",
 	ErrJSONUsedAsKey:                       "42000",
+	ErrWindowFrameStartIllegal:             "HY000",
+	ErrWindowFrameEndIllegal:               "HY000",
+	ErrWindowFrameIllegal:                  "HY000",
+	ErrWindowRangeFrameOrderType:           "HY000",
+	ErrWindowInvalidWindowFuncUse:          "HY000",
+	ErrCTEMaxRecursionDepth:                "HY000",
+
+	ErrCTERecursiveRequiresUnion:             "HY000",
+	ErrCTERecursiveRequiresNonRecursiveFirst: "HY000",
+	ErrCTERecursiveForbidsAggregation:        "HY000",
+	ErrCTERecursiveRequiresSingleReference:   "HY000",
 }
@@ -0,0 +1,84 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Normalize returns the normalized form of the sql, the statements of the same shape have the same
+// normalized form. The literals are replaced by "?", a list of literals like "?, ?, ?" in the IN list or
+// the VALUES list is reduced to "...", the comments are removed, the keywords and the identifiers are
+// turned to lower case, and the tokens are separated by a single space.
+// For example, "SELECT * FROM t WHERE a IN (1, 2, 3) AND b = 'x'" is normalized to
+// "select * from t where a in ( ... ) and b = ?".
+func Normalize(sql string) string {
+	s := NewScanner(sql)
+	tokens := make([]string, 0, 16)
+	for {
+		tok, _, lit := s.scan()
+		// The scanner doesn't move forward on an illegal character, so stop on it.
+		if tok == 0 || tok == invalid || (tok == unicode.ReplacementChar && s.r.eof()) {
+			break
+		}
+		switch tok {
+		case stringLit, intLit, floatLit, decLit, hexLit, bitLit:
+			tokens = appendLiteral(tokens)
+			continue
+		case identifier, quotedIdentifier:
+			lit = strings.ToLower(lit)
+		case hintBegin:
+			lit = "/*+"
+		case hintEnd:
+			lit = "*/"
+		default:
+			if lit == "" && tok > 0 && tok < unicode.MaxASCII {
+				lit = string(rune(tok))
+			}
+			// "( ? )" is reduced to "( ... )" too, so the lists of one literal and more literals are the same.
+			if n := len(tokens); lit == ")" && n >= 2 && tokens[n-1] == "?" && tokens[n-2] == "(" {
+				tokens[n-1] = "..."
+			}
+		}
+		tokens = append(tokens, lit)
+	}
+	return strings.Join(tokens, " ")
+}
+
+// appendLiteral appends a "?" to tokens, "?, ?" is reduced to "..." and "..., ?" is reduced to "...".
+func appendLiteral(tokens []string) []string {
+	n := len(tokens)
+	if n >= 2 && tokens[n-1] == "," {
+		switch tokens[n-2] {
+		case "?":
+			return append(tokens[:n-2], "...")
+		case "...":
+			return tokens[:n-1]
+		}
+	}
+	return append(tokens, "?")
+}
+
+// DigestHash returns the hex encoded sha256 hash of the normalized sql.
+func DigestHash(sql string) string {
+	return DigestNormalized(Normalize(sql))
+}
+
+// DigestNormalized returns the hex encoded sha256 hash of the sql which is normalized already.
+func DigestNormalized(normalized string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(normalized)))
+}
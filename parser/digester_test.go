@@ -0,0 +1,50 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+var _ = Suite(&testDigesterSuite{})
+
+type testDigesterSuite struct {
+}
+
+func (s *testDigesterSuite) TestNormalize(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input  string
+		expect string
+	}{
+		{"SELECT 1", "select ?"},
+		{"select * from t where a = 1 and b > 'x' /* comment */", "select * from t where a = ? and b > ?"},
+		{"select * from `T` where A in (1, 2.5, 'a', 0x10)", "select * from t where a in ( ... )"},
+		{"insert into t values (1, 2), (3, 4)", "insert into t values ( ... ) , ( ... )"},
+		{"select a/2, b-1 from t  limit 10", "select a / ? , b - ? from t limit ?"},
+		{"select /*+ TIDB_SMJ(t1) */ * from t1 where c = @a", "select /*+ tidb_smj ( t1 ) */ * from t1 where c = @a"},
+		{"update t set a = a + 1 where id = 10;", "update t set a = a + ? where id = ? ;"},
+		{"select \x00", "select"},
+		{"select 1 /* unterminated", "select ?"},
+	}
+	for _, t := range tests {
+		c.Assert(Normalize(t.input), Equals, t.expect, Commentf("%s", t.input))
+	}
+
+	c.Assert(DigestHash("select * from t where a = 1"), Equals, DigestHash("SELECT * FROM t WHERE a = 100"))
+	c.Assert(DigestHash("select * from t where a in (1)"), Equals, DigestHash("select * from t where a in (1, 2, 3)"))
+	c.Assert(DigestHash("select * from t where a = 1"), Not(Equals), DigestHash("select * from t where b = 1"))
+	c.Assert(DigestHash("select 1"), HasLen, 64)
+}
@@ -95,6 +95,8 @@ func init() {
 	initTokenByte('<', int('<'))
 	initTokenByte('(', int('('))
 	initTokenByte(')', int(')'))
+	initTokenByte('[', int('['))
+	initTokenByte(']', int(']'))
 	initTokenByte(';', int(';'))
 	initTokenByte(',', int(','))
 	initTokenByte('&', int('&'))
@@ -159,7 +161,11 @@ var tokenMap = map[string]int{
 	"AUTO_INCREMENT":             autoIncrement,
 	"AVG":                        avg,
 	"AVG_ROW_LENGTH":             avgRowLength,
+	"BATCH":                      batch,
 	"BEGIN":                      begin,
+	"BINDING":                    binding,
+	"BINDINGS":                   bindings,
+	"CANCEL":                     cancel,
 	"BETWEEN":                    between,
 	"BIN":                        bin,
 	"BINLOG":                     binlog,
@@ -176,6 +182,7 @@ var tokenMap = map[string]int{
 	"CHARSET":                    charsetKwd,
 	"CHECK":                      check,
 	"CHECKSUM":                   checksum,
+	"CLEANUP":                    cleanup,
 	"COALESCE":                   coalesce,
 	"COLLATE":                    collate,
 	"COLLATION":                  collation,
@@ -207,6 +214,7 @@ var tokenMap = map[string]int{
 	"CURTIME":                    curTime,
 	"CURRENT_TIME":               currentTime,
 	"CURRENT_USER":               currentUser,
+	"CURRENT":                    current,
 	"DATA":                       data,
 	"DATABASE":                   database,
 	"DATABASES":                  databases,
@@ -223,6 +231,7 @@ var tokenMap = map[string]int{
 	"DEALLOCATE":                 deallocate,
 	"DEGREES":                    degrees,
 	"DEFAULT":                    defaultKwd,
+	"DENSE_RANK":                 denseRank,
 	"DELAYED":                    delayed,
 	"DELAY_KEY_WRITE":            delayKeyWrite,
 	"DELETE":                     deleteKwd,
@@ -263,7 +272,9 @@ var tokenMap = map[string]int{
 	"FIELDS":                     fields,
 	"FIND_IN_SET":                findInSet,
 	"FIRST":                      first,
+	"FIRST_VALUE":                firstValue,
 	"FIXED":                      fixed,
+	"FOLLOWING":                  following,
 	"FOREIGN":                    foreign,
 	"FOR":                        forKwd,
 	"FORCE":                      force,
@@ -309,11 +320,15 @@ var tokenMap = map[string]int{
 	"IS":                         is,
 	"ISNULL":                     isNull,
 	"ISOLATION":                  isolation,
+	"JOBS":                       jobs,
 	"JOIN":                       join,
 	"KEY":                        key,
 	"KEY_BLOCK_SIZE":             keyBlockSize,
 	"KEYS":                       keys,
 	"LAST_INSERT_ID":             lastInsertID,
+	"LAG":                        lag,
+	"LAST_VALUE":                 lastValue,
+	"LEAD":                       lead,
 	"LEADING":                    leading,
 	"LEAST":                      least,
 	"LEFT":                       left,
@@ -365,19 +380,24 @@ var tokenMap = map[string]int{
 	"OFFSET":                     offset,
 	"ON":                         on,
 	"ONLY":                       only,
+	"OPTIMISTIC":                 optimistic,
 	"OPTION":                     option,
 	"OR":                         or,
 	"ORD":                        ord,
 	"ORDER":                      order,
 	"OUTER":                      outer,
+	"OVER":                       over,
 	"PASSWORD":                   password,
+	"PAUSE":                      pause,
 	"PERIOD_ADD":                 periodAdd,
+	"PESSIMISTIC":                pessimistic,
 	"PERIOD_DIFF":                periodDiff,
 	"PI":                         pi,
 	"POSITION":                   position,
 	"POW":                        pow,
 	"POWER":                      power,
 	"PREPARE":                    prepare,
+	"PRECEDING":                  preceding,
 	"PRIMARY":                    primary,
 	"PRIVILEGES":                 privileges,
 	"PROCEDURE":                  procedure,
@@ -390,7 +410,10 @@ var tokenMap = map[string]int{
 	"QUOTE":                      quote,
 	"RANGE":                      rangeKwd,
 	"RAND":                       rand,
+	"RANK":                       rank,
 	"READ":                       read,
+	"RECURSIVE":                  recursive,
+	"RECOVER":                    recover,
 	"REDUNDANT":                  redundant,
 	"REFERENCES":                 references,
 	"REGEXP":                     regexpKwd,
@@ -398,7 +421,9 @@ var tokenMap = map[string]int{
 	"RENAME":                     rename,
 	"REPEAT":                     repeat,
 	"REPEATABLE":                 repeatable,
+	"RESUME":                     resume,
 	"REPLACE":                    replace,
+	"REQUIRE":                    require,
 	"REVOKE":                     revoke,
 	"RIGHT":                      right,
 	"RLIKE":                      rlike,
@@ -406,6 +431,8 @@ var tokenMap = map[string]int{
 	"ROUND":                      round,
 	"ROW":                        row,
 	"ROW_FORMAT":                 rowFormat,
+	"ROWS":                       rows,
+	"ROW_NUMBER":                 rowNumber,
 	"RTRIM":                      rtrim,
 	"REVERSE":                    reverse,
 	"SCHEMA":                     schema,
@@ -427,6 +454,7 @@ var tokenMap = map[string]int{
 	"SOME":                       some,
 	"SPACE":                      space,
 	"SQRT":                       sqrt,
+	"SSL":                        ssl,
 	"START":                      start,
 	"STARTING":                   starting,
 	"STATS":                      stats,
@@ -470,6 +498,7 @@ var tokenMap = map[string]int{
 	"TRUE":                       trueKwd,
 	"TRUNCATE":                   truncate,
 	"UNCOMMITTED":                uncommitted,
+	"UNBOUNDED":                  unbounded,
 	"UNKNOWN":                    unknown,
 	"UNION":                      union,
 	"UNIQUE":                     unique,
@@ -497,6 +526,7 @@ var tokenMap = map[string]int{
 	"WHERE":                      where,
 	"WITH":                       with,
 	"WRITE":                      write,
+	"X509":                       x509,
 	"XOR":                        xor,
 	"YEARWEEK":                   yearweek,
 	"ZEROFILL":                   zerofill,
@@ -173,6 +173,7 @@ import (
 	ord			"ORD"
 	order			"ORDER"
 	outer			"OUTER"
+	over			"OVER"
 	partition		"PARTITION"
 	partitions		"PARTITIONS"
 	position		"POSITION"
@@ -182,12 +183,14 @@ import (
 	quote			"QUOTE"
 	rangeKwd		"RANGE"
 	read			"READ"
+	recursive		"RECURSIVE"
 	realType		"REAL"
 	references		"REFERENCES"
 	regexpKwd		"REGEXP"
 	rename         		"RENAME"
 	repeat			"REPEAT"
 	replace			"REPLACE"
+	require			"REQUIRE"
 	restrict		"RESTRICT"
 	revoke			"REVOKE"
 	right			"RIGHT"
@@ -199,6 +202,7 @@ import (
 	set			"SET"
 	show			"SHOW"
 	smallIntType		"SMALLINT"
+	ssl			"SSL"
 	starting		"STARTING"
 	tableKwd		"TABLE"
 	stored			"STORED"
@@ -266,6 +270,7 @@ import (
 	dayofweek			"DAYOFWEEK"
 	dayofyear			"DAYOFYEAR"
 	degrees				"DEGREES"
+	denseRank			"DENSE_RANK"
 	fromDays			"FROM_DAYS"
 	events				"EVENTS"
 	exp				"EXP"
@@ -273,6 +278,7 @@ import (
 	exportSet			"EXPORT_SET"
 	fieldKwd			"FIELD_KWD"
 	findInSet			"FIND_IN_SET"
+	firstValue			"FIRST_VALUE"
 	floor				"FLOOR"
 	format				"FORMAT"
 	foundRows			"FOUND_ROWS"
@@ -301,7 +307,10 @@ import (
 	jsonArray			"JSON_ARRAY"
 	kill				"KILL"
 	lastInsertID			"LAST_INSERT_ID"
+	lag				"LAG"
+	lastValue			"LAST_VALUE"
 	lcase				"LCASE"
+	lead				"LEAD"
 	length				"LENGTH"
 	least				"LEAST"
 	ln				"LN"
@@ -331,8 +340,10 @@ import (
 	process				"PROCESS"
 	query				"QUERY"
 	rand				"RAND"
+	rank				"RANK"
 	radians				"RADIANS"
 	rowCount			"ROW_COUNT"
+	rowNumber			"ROW_NUMBER"
 	secToTime			"SEC_TO_TIME"
 	second				"SECOND"
 	sessionUser			"SESSION_USER"
@@ -424,15 +435,20 @@ import (
 	autoIncrement	"AUTO_INCREMENT"
 	avgRowLength	"AVG_ROW_LENGTH"
 	avg		"AVG"
+	batch		"BATCH"
 	begin		"BEGIN"
+	binding		"BINDING"
+	bindings	"BINDINGS"
 	binlog		"BINLOG"
 	bitType		"BIT"
 	booleanType	"BOOLEAN"
 	boolType	"BOOL"
 	btree		"BTREE"
 	byteType	"BYTE"
+	cancel		"CANCEL"
 	charsetKwd	"CHARSET"
 	checksum	"CHECKSUM"
+	cleanup		"CLEANUP"
 	collation	"COLLATION"
 	columns		"COLUMNS"
 	comment 	"COMMENT"
@@ -443,6 +459,7 @@ import (
 	compression	"COMPRESSION"
 	connection 	"CONNECTION"
 	consistent	"CONSISTENT"
+	current		"CURRENT"
 	data 		"DATA"
 	dateType	"DATE"
 	datetimeType	"DATETIME"
@@ -462,6 +479,7 @@ import (
 	fields		"FIELDS"
 	first		"FIRST"
 	fixed		"FIXED"
+	following	"FOLLOWING"
 	flush		"FLUSH"
 	full		"FULL"
 	function	"FUNCTION"
@@ -470,6 +488,7 @@ import (
 	isolation	"ISOLATION"
 	indexes		"INDEXES"
 	jsonType	"JSON"
+	jobs		"JOBS"
 	keyBlockSize	"KEY_BLOCK_SIZE"
 	local		"LOCAL"
 	less		"LESS"
@@ -484,18 +503,25 @@ import (
 	none		"NONE"
 	offset		"OFFSET"
 	only		"ONLY"
+	optimistic	"OPTIMISTIC"
 	password	"PASSWORD"
+	pause		"PAUSE"
+	pessimistic	"PESSIMISTIC"
 	prepare		"PREPARE"
+	preceding	"PRECEDING"
 	privileges	"PRIVILEGES"
 	processlist	"PROCESSLIST"
 	quarter		"QUARTER"
 	quick		"QUICK"
+	recover		"RECOVER"
 	redundant	"REDUNDANT"
 	repeatable	"REPEATABLE"
+	resume		"RESUME"
 	reverse		"REVERSE"
 	rollback	"ROLLBACK"
 	row 		"ROW"
 	rowFormat	"ROW_FORMAT"
+	rows		"ROWS"
 	serializable	"SERIALIZABLE"
 	session		"SESSION"
 	share		"SHARE"
@@ -526,6 +552,7 @@ import (
 	triggers	"TRIGGERS"
 	truncate	"TRUNCATE"
 	uncommitted	"UNCOMMITTED"
+	unbounded	"UNBOUNDED"
 	unknown 	"UNKNOWN"
 	user		"USER"
 	value		"VALUE"
@@ -533,6 +560,7 @@ import (
 	view		"VIEW"
 	warnings	"WARNINGS"
 	week		"WEEK"
+	x509		"X509"
 	yearType	"YEAR"
 
 %token	<item>
@@ -585,6 +613,7 @@ import (
 	ColumnNameListOptWithBrackets "column name list opt with brackets"
 	ColumnSetValue		"insert statement set value by column name"
 	ColumnSetValueList	"insert statement set value by column name list"
+	CommonTableExpr		"Common table expression"
 	CommitStmt		"COMMIT statement"
 	CompareOp		"Compare opcode"
 	ColumnOption		"column definition option"
@@ -602,10 +631,18 @@ import (
 	DatabaseOptionListOpt	"CREATE Database specification list opt"
 	CreateTableStmt		"CREATE TABLE statement"
 	CreateUserStmt		"CREATE User statement"
+	CreateBindingStmt	"CREATE BINDING statement"
+	DropBindingStmt		"DROP BINDING statement"
+	CreateViewStmt		"CREATE VIEW statement"
+	AlterViewStmt		"ALTER VIEW statement"
+	OrReplace		"OR REPLACE or empty"
+	ViewSelectStmt		"SELECT or UNION statement of a view"
 	DBName			"Database Name"
 	DeallocateStmt		"Deallocate prepared statement"
 	DefaultValueExpr	"DefaultValueExpr(Now or Signed Literal)"
 	DeleteFromStmt		"DELETE FROM statement"
+	NonTransactionalDMLStmt	"Non-transactional DML statement"
+	ShardableDMLStmt	"DML statement which can be split into batches"
 	DistinctOpt		"Distinct option"
 	DoStmt			"Do statement"
 	DropDatabaseStmt	"DROP DATABASE statement"
@@ -688,6 +725,10 @@ import (
 	OnDuplicateKeyUpdate	"ON DUPLICATE KEY UPDATE value list"
 	Operand			"operand"
 	OptFull			"Full or empty"
+	OptLeadLagInfo		"Optional LEAD/LAG offset and default value"
+	OptPartitionClause	"Optional PARTITION BY clause of a window"
+	OptWindowFrameClause	"Optional frame clause of a window"
+	OptWindowingClause	"Optional OVER clause"
 	Order			"ORDER BY clause optional collation specification"
 	OrderBy			"ORDER BY clause"
 	ByItem			"BY item"
@@ -697,6 +738,7 @@ import (
 	PartitionDefinition	"Partition definition"
 	PartitionDefinitionList "Partition definition list"
 	PartitionDefinitionListOpt	"Partition definition list option"
+	PartitionNameList	"Partition name list"
 	PartitionOpt		"Partition option"
 	PartitionNumOpt		"PARTITION NUM option"
 	PartDefValuesOpt	"VALUES {LESS THAN {(expr | value_list) | MAXVALUE} | IN {value_list}"
@@ -788,8 +830,19 @@ import (
 	WhereClauseOptional	"Optional WHERE clause"
 	WhenClause		"When clause"
 	WhenClauseList		"When clause list"
+	WindowFrameBound	"Window frame bound"
+	WindowFrameExtent	"Window frame extent"
+	WindowFrameStart	"Window frame start bound"
+	WindowFrameUnits	"Window frame units, ROWS or RANGE"
+	WindowFuncCall		"Window function call"
+	WindowingClause		"OVER clause of a window function"
+	WindowSpecDetails	"Window specification"
+	WithClause		"WITH clause"
+	WithList		"List of common table expressions"
 	WithReadLockOpt		"With Read Lock opt"
+	WithSelectStmt		"SELECT or UNION statement with a WITH clause"
 	WithGrantOptionOpt	"With Grant Option opt"
+	RequireClauseOpt	"Require clause opt"
 	ElseOpt			"Optional else clause"
 	ExpressionOpt		"Optional expression"
 	Type			"Types"
@@ -821,7 +874,15 @@ import (
 	OptCollate		"Optional Collate setting"
 	NUM			"numbers"
 	LengthNum		"Field length num(uint64)"
+	NumList			"Number list"
+	HandleRange		"Handle range"
+	HandleRangeList		"Handle range list"
+	HandleRangeListOpt	"Optional handle range list"
+	SignedNum		"Signed number"
 	HintTableList		"Table list in optimizer hint"
+	HintArg			"Argument token or parenthesized arguments in optimizer hint"
+	HintArgList		"Argument list in optimizer hint"
+	HintArgListOpt		"Optional argument list in optimizer hint"
 	TableOptimizerHintOpt	"Table level optimizer hint"
 	TableOptimizerHints	"Table level optimizer hints"
 	TableOptimizerHintList	"Table level optimizer hint list"
@@ -1063,6 +1124,27 @@ AlterTableSpec:
 			NewTable:      $3.(*ast.TableName),
 		}
 	}
+|	"ADD" "PARTITION" '(' PartitionDefinitionList ')'
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableAddPartitions,
+			PartDefinitions:$4.([]*ast.PartitionDefinition),
+		}
+	}
+|	"DROP" "PARTITION" PartitionNameList %prec lowerThanComma
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableDropPartition,
+			PartitionNames:	$3.([]model.CIStr),
+		}
+	}
+|	"TRUNCATE" "PARTITION" PartitionNameList %prec lowerThanComma
+	{
+		$$ = &ast.AlterTableSpec{
+			Tp:		ast.AlterTableTruncatePartition,
+			PartitionNames:	$3.([]model.CIStr),
+		}
+	}
 |	LockClause
 	{
 		$$ = &ast.AlterTableSpec{
@@ -1071,6 +1153,16 @@ AlterTableSpec:
 		}
 	}
 
+PartitionNameList:
+	Identifier
+	{
+		$$ = []model.CIStr{model.NewCIStr($1)}
+	}
+|	PartitionNameList ',' Identifier
+	{
+		$$ = append($1.([]model.CIStr), model.NewCIStr($3))
+	}
+
 LockClause: 
 	"LOCK" eq "NONE"
 	{
@@ -1198,6 +1290,14 @@ BeginTransactionStmt:
 	{
 		$$ = &ast.BeginStmt{}
 	}
+|	"BEGIN" "PESSIMISTIC"
+	{
+		$$ = &ast.BeginStmt{Mode: ast.PessimisticTxnMode}
+	}
+|	"BEGIN" "OPTIMISTIC"
+	{
+		$$ = &ast.BeginStmt{Mode: ast.OptimisticTxnMode}
+	}
 |	"START" "TRANSACTION"
 	{
 		$$ = &ast.BeginStmt{}
@@ -1689,6 +1789,9 @@ CreateTableStmt:
 			Constraints:    constraints,
 			Options:        $8.([]*ast.TableOption),
 		}
+		if $9 != nil {
+			$$.(*ast.CreateTableStmt).Partition = $9.(*ast.PartitionOptions)
+		}
 	}
 |	"CREATE" "TABLE" IfNotExists TableName "LIKE" TableName
 	{
@@ -1704,38 +1807,84 @@ DefaultKwdOpt:
 |	"DEFAULT"
 
 PartitionOpt:
-	{}
+	{
+		$$ = nil
+	}
 |	"PARTITION" "BY" "HASH" '(' Expression ')' PartitionNumOpt PartitionDefinitionListOpt
-	{}
+	{
+		startOffset := parser.startOffset(&yyS[yypt-3])
+		endOffset := parser.endOffset(&yyS[yypt-2])
+		expr := $5.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeHash,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
 |	"PARTITION" "BY" "RANGE" '(' Expression ')' PartitionNumOpt  PartitionDefinitionListOpt
-	{}
+	{
+		startOffset := parser.startOffset(&yyS[yypt-3])
+		endOffset := parser.endOffset(&yyS[yypt-2])
+		expr := $5.(ast.ExprNode)
+		expr.SetText(parser.src[startOffset:endOffset])
+		$$ = &ast.PartitionOptions{
+			Tp:		model.PartitionTypeRange,
+			Expr:		expr,
+			Num:		$7.(uint64),
+			Definitions:	$8.([]*ast.PartitionDefinition),
+		}
+	}
 
 PartitionNumOpt:
-	{}
+	{
+		$$ = uint64(0)
+	}
 |	"PARTITIONS" NUM
-	{}
+	{
+		$$ = getUint64FromNUM($2)
+	}
 
 PartitionDefinitionListOpt:
-	{}
+	{
+		$$ = []*ast.PartitionDefinition(nil)
+	}
 |	'(' PartitionDefinitionList ')'
-	{}
+	{
+		$$ = $2.([]*ast.PartitionDefinition)
+	}
 
 PartitionDefinitionList:
 	PartitionDefinition
-	{}
+	{
+		$$ = []*ast.PartitionDefinition{$1.(*ast.PartitionDefinition)}
+	}
 |	PartitionDefinitionList ',' PartitionDefinition
-	{}
+	{
+		$$ = append($1.([]*ast.PartitionDefinition), $3.(*ast.PartitionDefinition))
+	}
 
 PartitionDefinition:
 	"PARTITION" Identifier PartDefValuesOpt PartDefStorageOpt
-	{}
+	{
+		partDef := $3.(*ast.PartitionDefinition)
+		partDef.Name = model.NewCIStr($2)
+		$$ = partDef
+	}
 
 PartDefValuesOpt:
-	{}
+	{
+		$$ = &ast.PartitionDefinition{}
+	}
 |	"VALUES" "LESS" "THAN" "MAXVALUE"
-	{}
+	{
+		$$ = &ast.PartitionDefinition{MaxValue: true}
+	}
 |	"VALUES" "LESS" "THAN" '(' ExpressionList ')'
-	{}
+	{
+		$$ = &ast.PartitionDefinition{LessThan: $5.([]ast.ExprNode)}
+	}
 
 PartDefStorageOpt:
 	{}
@@ -1842,11 +1991,70 @@ DropTableStmt:
 	}
 
 DropViewStmt:
-	"DROP" "VIEW" "IF" "EXISTS" TableNameList
+	"DROP" "VIEW" TableNameList
+	{
+		$$ = &ast.DropTableStmt{Tables: $3.([]*ast.TableName), IsView: true}
+	}
+|	"DROP" "VIEW" "IF" "EXISTS" TableNameList
 	{
-		$$ = &ast.DoStmt{}
+		$$ = &ast.DropTableStmt{IfExists: true, Tables: $5.([]*ast.TableName), IsView: true}
 	}
 
+/*******************************************************************
+ *
+ *  Create View Statement
+ *
+ *  Example:
+ *      CREATE OR REPLACE VIEW v (a, b) AS SELECT c, d FROM t
+ *******************************************************************/
+CreateViewStmt:
+	"CREATE" OrReplace "VIEW" TableName ColumnNameListOptWithBrackets "AS" ViewSelectStmt
+	{
+		startOffset := parser.startOffset(&yyS[yypt])
+		selStmt := $7.(ast.StmtNode)
+		selStmt.SetText(strings.TrimSpace(parser.src[startOffset:parser.yylval.offset]))
+		x := &ast.CreateViewStmt{
+			OrReplace: $2.(bool),
+			ViewName:  $4.(*ast.TableName),
+			Select:    selStmt,
+		}
+		for _, col := range $5.([]*ast.ColumnName) {
+			x.Cols = append(x.Cols, col.Name)
+		}
+		$$ = x
+	}
+
+AlterViewStmt:
+	"ALTER" "VIEW" TableName ColumnNameListOptWithBrackets "AS" ViewSelectStmt
+	{
+		startOffset := parser.startOffset(&yyS[yypt])
+		selStmt := $6.(ast.StmtNode)
+		selStmt.SetText(strings.TrimSpace(parser.src[startOffset:parser.yylval.offset]))
+		x := &ast.CreateViewStmt{
+			OrReplace: true,
+			ViewName:  $3.(*ast.TableName),
+			Select:    selStmt,
+		}
+		for _, col := range $4.([]*ast.ColumnName) {
+			x.Cols = append(x.Cols, col.Name)
+		}
+		$$ = x
+	}
+
+OrReplace:
+	{
+		$$ = false
+	}
+|	"OR" "REPLACE"
+	{
+		$$ = true
+	}
+
+ViewSelectStmt:
+	SelectStmt
+|	UnionStmt
+|	WithSelectStmt
+
 DropUserStmt:
     "DROP" "USER" UsernameList
 	{
@@ -1863,6 +2071,44 @@ DropStatsStmt:
 		$$ = &ast.DropStatsStmt{Table: $3.(*ast.TableName)}
 	}
 
+/*******************************************************************
+ *
+ *  Create Binding Statement
+ *
+ *  Example:
+ *      CREATE GLOBAL BINDING FOR SELECT * FROM t1, t2 WHERE t1.a = t2.a
+ *      USING SELECT /*+ TIDB_SMJ(t1, t2) *\/ * FROM t1, t2 WHERE t1.a = t2.a
+ *******************************************************************/
+CreateBindingStmt:
+	"CREATE" GlobalScope "BINDING" "FOR" SelectStmt "USING" SelectStmt
+	{
+		startOffset := parser.startOffset(&yyS[yypt-2])
+		endOffset := parser.endOffset(&yyS[yypt-1])
+		originSel := $5.(ast.StmtNode)
+		originSel.SetText(strings.TrimSpace(parser.src[startOffset:endOffset]))
+
+		startOffset = parser.startOffset(&yyS[yypt])
+		hintedSel := $7.(ast.StmtNode)
+		hintedSel.SetText(strings.TrimSpace(parser.src[startOffset:parser.yylval.offset]))
+		$$ = &ast.CreateBindingStmt{
+			GlobalScope: $2.(bool),
+			OriginSel:   originSel,
+			HintedSel:   hintedSel,
+		}
+	}
+
+DropBindingStmt:
+	"DROP" GlobalScope "BINDING" "FOR" SelectStmt
+	{
+		startOffset := parser.startOffset(&yyS[yypt])
+		originSel := $5.(ast.StmtNode)
+		originSel.SetText(strings.TrimSpace(parser.src[startOffset:parser.yylval.offset]))
+		$$ = &ast.DropBindingStmt{
+			GlobalScope: $2.(bool),
+			OriginSel:   originSel,
+		}
+	}
+
 TableOrTables:
 	"TABLE"
 |	"TABLES"
@@ -1902,7 +2148,24 @@ ExplainStmt:
 	}
 |	ExplainSym ExplainableStmt
 	{
-		$$ = &ast.ExplainStmt{Stmt: $2.(ast.StmtNode)}
+		$$ = &ast.ExplainStmt{
+			Stmt:	$2.(ast.StmtNode),
+			Format:	ast.ExplainFormatJSON,
+		}
+	}
+|	ExplainSym "FORMAT" "=" stringLit ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:	$5.(ast.StmtNode),
+			Format:	strings.ToLower($4),
+		}
+	}
+|	ExplainSym "ANALYZE" ExplainableStmt
+	{
+		$$ = &ast.ExplainStmt{
+			Stmt:		$3.(ast.StmtNode),
+			Analyze:	true,
+		}
 	}
 
 LengthNum:
@@ -2370,6 +2633,8 @@ UnReservedKeyword:
 | "REPEATABLE" | "COMMITTED" | "UNCOMMITTED" | "ONLY" | "SERIALIZABLE" | "LEVEL" | "VARIABLES" | "SQL_CACHE" | "INDEXES" | "PROCESSLIST"
 | "SQL_NO_CACHE" | "DISABLE"  | "ENABLE" | "REVERSE" | "SPACE" | "PRIVILEGES" | "NO" | "BINLOG" | "FUNCTION" | "VIEW" | "MODIFY" | "EVENTS" | "PARTITIONS"
 | "TIMESTAMPDIFF" | "NONE" | "SUPER" | "SHARED" | "EXCLUSIVE" | "STATS" | "STATS_META" | "STATS_HISTOGRAMS" | "STATS_BUCKETS"
+| "CURRENT" | "FOLLOWING" | "PRECEDING" | "ROWS" | "UNBOUNDED" | "X509" | "PESSIMISTIC" | "OPTIMISTIC" | "BATCH" | "QUERY"
+| "BINDING" | "BINDINGS" | "JOBS" | "CANCEL" | "RECOVER" | "CLEANUP" | "PAUSE" | "RESUME"
 
 ReservedKeyword:
 "ADD" | "ALL" | "ALTER" | "ANALYZE" | "AND" | "AS" | "ASC" | "BETWEEN" | "BIGINT"
@@ -2384,9 +2649,9 @@ ReservedKeyword:
 | "INTERVAL" | "IS" | "JOIN" | "KEY" | "KEYS" | "KILL" | "LEADING" | "LEFT" | "LIKE" | "LIMIT" | "LINES" | "LOAD"
 | "LOCALTIME" | "LOCALTIMESTAMP" | "LOCK" | "LONGBLOB" | "LONGTEXT" | "MAXVALUE" | "MEDIUMBLOB" | "MEDIUMINT" | "MEDIUMTEXT"
 | "MINUTE_MICROSECOND" | "MINUTE_SECOND" | "MOD" | "NOT" | "NO_WRITE_TO_BINLOG" | "NULL" | "NUMERIC"
-| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ" 
-| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
-| "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT"
+| "ON" | "OPTION" | "OR" | "ORDER" | "OUTER" | "OVER" | "PARTITION" | "PRECISION" | "PRIMARY" | "PROCEDURE" | "RANGE" | "READ" | "RECURSIVE"
+| "REAL" | "REFERENCES" | "REGEXP" | "RENAME" | "REPEAT" | "REPLACE" | "REQUIRE" | "RESTRICT" | "REVOKE" | "RIGHT" | "RLIKE"
+| "SCHEMA" | "SCHEMAS" | "SECOND_MICROSECOND" | "SELECT" | "SET" | "SHOW" | "SMALLINT" | "SSL"
 | "STARTING" | "TABLE" | "STORED" | "TERMINATED" | "THEN" | "TINYBLOB" | "TINYINT" | "TINYTEXT" | "TO"
 | "TRAILING" | "TRIGGER" | "TRUE" | "UNION" | "UNIQUE" | "UNLOCK" | "UNSIGNED"
 | "UPDATE" | "USE" | "USING" | "UTC_DATE" | "UTC_TIMESTAMP" | "VALUES" | "VARBINARY" | "VARCHAR" | "VIRTUAL"
@@ -2408,6 +2673,7 @@ NotKeywordToken:
 |	"ANY_VALUE" | "INET_ATON" | "INET_NTOA" | "INET6_ATON" | "INET6_NTOA" | "IS_FREE_LOCK" | "IS_IPV4" | "IS_IPV4_COMPAT" | "IS_IPV4_MAPPED" | "IS_IPV6" | "IS_USED_LOCK" | "MASTER_POS_WAIT" | "NAME_CONST" | "RELEASE_ALL_LOCKS" | "UUID" | "UUID_SHORT"
 |	"COMPRESS" | "DECODE" | "DES_DECRYPT" | "DES_ENCRYPT" | "ENCODE" | "ENCRYPT" | "MD5" | "OLD_PASSWORD" | "RANDOM_BYTES" | "SHA1" | "SHA" | "SHA2" | "UNCOMPRESS" | "UNCOMPRESSED_LENGTH" | "VALIDATE_PASSWORD_STRENGTH"
 |	"JSON_EXTRACT" | "JSON_UNQUOTE" | "JSON_TYPE" | "JSON_MERGE" | "JSON_SET" | "JSON_INSERT" | "JSON_REPLACE" | "JSON_REMOVE" | "JSON_OBJECT" | "JSON_ARRAY" | "TIDB_VERSION"
+|	"ROW_NUMBER" | "RANK" | "DENSE_RANK" | "LEAD" | "LAG" | "FIRST_VALUE" | "LAST_VALUE"
 
 /************************************************************************************
  *
@@ -2451,6 +2717,10 @@ InsertValues:
 	{
 		$$ = &ast.InsertStmt{Columns: $2.([]*ast.ColumnName), Select: $4.(*ast.UnionStmt)}
 	}
+|	'(' ColumnNameListOpt ')' WithSelectStmt
+	{
+		$$ = &ast.InsertStmt{Columns: $2.([]*ast.ColumnName), Select: $4.(ast.ResultSetNode)}
+	}
 |	ValueSym ExpressionListList %prec insertValues
 	{
 		$$ = &ast.InsertStmt{Lists:  $2.([][]ast.ExprNode)}
@@ -2463,6 +2733,10 @@ InsertValues:
 	{
 		$$ = &ast.InsertStmt{Select: $1.(*ast.UnionStmt)}
 	}
+|	WithSelectStmt
+	{
+		$$ = &ast.InsertStmt{Select: $1.(ast.ResultSetNode)}
+	}
 |	"SET" ColumnSetValueList
 	{
 		$$ = &ast.InsertStmt{Setlist: $2.([]*ast.Assignment)}
@@ -2746,6 +3020,7 @@ Function:
 |	FunctionCallNonKeyword
 |	FunctionCallConflict
 |	FunctionCallAgg
+|	WindowFuncCall
 
 FunctionNameConflict:
 	"DATABASE"
@@ -3785,46 +4060,189 @@ TrimDirection:
 	}
 
 FunctionCallAgg:
-	"AVG" '(' DistinctOpt Expression ')'
+	"AVG" '(' DistinctOpt Expression ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+		$$ = newAggregateOrWindowFunc($1, []ast.ExprNode{$4.(ast.ExprNode)}, $3.(bool), $6)
 	}
 |	"BIT_XOR" '(' Expression ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}}
 	}
-|	"COUNT" '(' "DISTINCT" ExpressionList ')'
+|	"COUNT" '(' "DISTINCT" ExpressionList ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: $4.([]ast.ExprNode), Distinct: true}
+		$$ = newAggregateOrWindowFunc($1, $4.([]ast.ExprNode), true, $6)
 	}
-|	"COUNT" '(' "ALL" Expression ')'
+|	"COUNT" '(' "ALL" Expression ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}}
+		$$ = newAggregateOrWindowFunc($1, []ast.ExprNode{$4.(ast.ExprNode)}, false, $6)
 	}
-|	"COUNT" '(' Expression ')'
+|	"COUNT" '(' Expression ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}}
+		$$ = newAggregateOrWindowFunc($1, []ast.ExprNode{$3.(ast.ExprNode)}, false, $5)
 	}
-|	"COUNT" '(' '*' ')'
+|	"COUNT" '(' '*' ')' OptWindowingClause
 	{
 		args := []ast.ExprNode{ast.NewValueExpr(1)}
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: args}
+		$$ = newAggregateOrWindowFunc($1, args, false, $5)
 	}
 |	"GROUP_CONCAT" '(' DistinctOpt ExpressionList ')'
 	{
 		$$ = &ast.AggregateFuncExpr{F: $1, Args: $4.([]ast.ExprNode), Distinct: $3.(bool)}
 	}
-|	"MAX" '(' DistinctOpt Expression ')'
+|	"MAX" '(' DistinctOpt Expression ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+		$$ = newAggregateOrWindowFunc($1, []ast.ExprNode{$4.(ast.ExprNode)}, $3.(bool), $6)
 	}
-|	"MIN" '(' DistinctOpt Expression ')'
+|	"MIN" '(' DistinctOpt Expression ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+		$$ = newAggregateOrWindowFunc($1, []ast.ExprNode{$4.(ast.ExprNode)}, $3.(bool), $6)
 	}
-|	"SUM" '(' DistinctOpt Expression ')'
+|	"SUM" '(' DistinctOpt Expression ')' OptWindowingClause
 	{
-		$$ = &ast.AggregateFuncExpr{F: $1, Args: []ast.ExprNode{$4.(ast.ExprNode)}, Distinct: $3.(bool)}
+		$$ = newAggregateOrWindowFunc($1, []ast.ExprNode{$4.(ast.ExprNode)}, $3.(bool), $6)
+	}
+
+WindowFuncCall:
+	"ROW_NUMBER" '(' ')' WindowingClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Spec: $4.(ast.WindowSpec)}
+	}
+|	"RANK" '(' ')' WindowingClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Spec: $4.(ast.WindowSpec)}
+	}
+|	"DENSE_RANK" '(' ')' WindowingClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Spec: $4.(ast.WindowSpec)}
+	}
+|	"LEAD" '(' Expression OptLeadLagInfo ')' WindowingClause
+	{
+		args := append([]ast.ExprNode{$3.(ast.ExprNode)}, $4.([]ast.ExprNode)...)
+		$$ = &ast.WindowFuncExpr{F: $1, Args: args, Spec: $6.(ast.WindowSpec)}
+	}
+|	"LAG" '(' Expression OptLeadLagInfo ')' WindowingClause
+	{
+		args := append([]ast.ExprNode{$3.(ast.ExprNode)}, $4.([]ast.ExprNode)...)
+		$$ = &ast.WindowFuncExpr{F: $1, Args: args, Spec: $6.(ast.WindowSpec)}
+	}
+|	"FIRST_VALUE" '(' Expression ')' WindowingClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}, Spec: $5.(ast.WindowSpec)}
+	}
+|	"LAST_VALUE" '(' Expression ')' WindowingClause
+	{
+		$$ = &ast.WindowFuncExpr{F: $1, Args: []ast.ExprNode{$3.(ast.ExprNode)}, Spec: $5.(ast.WindowSpec)}
+	}
+
+OptLeadLagInfo:
+	{
+		$$ = []ast.ExprNode{}
+	}
+|	',' NUM
+	{
+		$$ = []ast.ExprNode{ast.NewValueExpr($2)}
+	}
+|	',' NUM ',' Expression
+	{
+		$$ = []ast.ExprNode{ast.NewValueExpr($2), $4.(ast.ExprNode)}
+	}
+
+OptWindowingClause:
+	{
+		$$ = nil
+	}
+|	WindowingClause
+	{
+		spec := $1.(ast.WindowSpec)
+		$$ = &spec
+	}
+
+WindowingClause:
+	"OVER" '(' WindowSpecDetails ')'
+	{
+		$$ = $3.(ast.WindowSpec)
+	}
+
+WindowSpecDetails:
+	OptPartitionClause OrderByOptional OptWindowFrameClause
+	{
+		spec := ast.WindowSpec{}
+		if $1 != nil {
+			spec.PartitionBy = $1.(*ast.PartitionByClause)
+		}
+		if $2 != nil {
+			spec.OrderBy = $2.(*ast.OrderByClause)
+		}
+		if $3 != nil {
+			spec.Frame = $3.(*ast.FrameClause)
+		}
+		$$ = spec
+	}
+
+OptPartitionClause:
+	{
+		$$ = nil
+	}
+|	"PARTITION" "BY" ByList
+	{
+		$$ = &ast.PartitionByClause{Items: $3.([]*ast.ByItem)}
+	}
+
+OptWindowFrameClause:
+	{
+		$$ = nil
+	}
+|	WindowFrameUnits WindowFrameExtent
+	{
+		$$ = &ast.FrameClause{Type: $1.(ast.FrameType), Extent: $2.(ast.FrameExtent)}
+	}
+
+WindowFrameUnits:
+	"ROWS"
+	{
+		$$ = ast.Rows
+	}
+|	"RANGE"
+	{
+		$$ = ast.Ranges
+	}
+
+WindowFrameExtent:
+	WindowFrameStart
+	{
+		$$ = ast.FrameExtent{Start: $1.(ast.FrameBound), End: ast.FrameBound{Type: ast.CurrentRow}}
+	}
+|	"BETWEEN" WindowFrameBound "AND" WindowFrameBound
+	{
+		$$ = ast.FrameExtent{Start: $2.(ast.FrameBound), End: $4.(ast.FrameBound)}
+	}
+
+WindowFrameStart:
+	"UNBOUNDED" "PRECEDING"
+	{
+		$$ = ast.FrameBound{Type: ast.Preceding, UnBounded: true}
+	}
+|	NumLiteral "PRECEDING"
+	{
+		$$ = ast.FrameBound{Type: ast.Preceding, Expr: ast.NewValueExpr($1)}
+	}
+|	"CURRENT" "ROW"
+	{
+		$$ = ast.FrameBound{Type: ast.CurrentRow}
+	}
+
+WindowFrameBound:
+	WindowFrameStart
+	{
+		$$ = $1
+	}
+|	"UNBOUNDED" "FOLLOWING"
+	{
+		$$ = ast.FrameBound{Type: ast.Following, UnBounded: true}
+	}
+|	NumLiteral "FOLLOWING"
+	{
+		$$ = ast.FrameBound{Type: ast.Following, Expr: ast.NewValueExpr($1)}
 	}
 
 FuncDatetimePrec:
@@ -4463,6 +4881,10 @@ TableFactor:
 	{
 		$$ = &ast.TableSource{Source: $2.(*ast.UnionStmt), AsName: $4.(model.CIStr)}
 	}
+|	'(' WithSelectStmt ')' TableAsName
+	{
+		$$ = &ast.TableSource{Source: $2.(ast.ResultSetNode), AsName: $4.(model.CIStr)}
+	}
 |	'(' TableRefs ')'
 	{
 		$$ = $2
@@ -4703,6 +5125,80 @@ HintTableList:
 		$$ = append($1.([]model.CIStr), model.NewCIStr($3))
 	}
 
+HintArg:
+	IdentifierOrReservedKeyword
+	{
+		$$ = hintArg{tok: identifier, ident: $1}
+	}
+|	singleAtIdentifier
+	{
+		$$ = hintArg{tok: singleAtIdentifier, ident: $1}
+	}
+|	intLit
+	{
+		$$ = hintArg{tok: intLit, val: $1}
+	}
+|	floatLit
+	{
+		$$ = hintArg{tok: floatLit, val: $1}
+	}
+|	decLit
+	{
+		$$ = hintArg{tok: decLit, val: $1}
+	}
+|	stringLit
+	{
+		$$ = hintArg{tok: stringLit, val: $1}
+	}
+|	','
+	{
+		$$ = hintArg{tok: ','}
+	}
+|	'.'
+	{
+		$$ = hintArg{tok: '.'}
+	}
+|	'['
+	{
+		$$ = hintArg{tok: '['}
+	}
+|	']'
+	{
+		$$ = hintArg{tok: ']'}
+	}
+|	'+'
+	{
+		$$ = hintArg{tok: '+'}
+	}
+|	'-'
+	{
+		$$ = hintArg{tok: '-'}
+	}
+|	"="
+	{
+		$$ = hintArg{tok: eq}
+	}
+|	'(' HintArgListOpt ')'
+	{
+		$$ = hintArg{tok: '(', args: $2.([]hintArg)}
+	}
+
+HintArgList:
+	HintArg
+	{
+		$$ = []hintArg{$1.(hintArg)}
+	}
+|	HintArgList HintArg
+	{
+		$$ = append($1.([]hintArg), $2.(hintArg))
+	}
+
+HintArgListOpt:
+	{
+		$$ = []hintArg{}
+	}
+|	HintArgList
+
 TableOptimizerHintList:
 	TableOptimizerHintOpt
 	{
@@ -4722,6 +5218,10 @@ TableOptimizerHintOpt:
 	{
 		$$ = &ast.TableOptimizerHint{HintName: model.NewCIStr($1), Tables: $3.([]model.CIStr)}
 	}
+|	Identifier '(' HintArgListOpt ')'
+	{
+		$$ = newTableOptimizerHint($1, $3.([]hintArg))
+	}
 
 SelectStmtCalcFoundRows:
 	%prec lowerThanCalcFoundRows
@@ -4779,6 +5279,59 @@ SubSelect:
 		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
 		$$ = &ast.SubqueryExpr{Query: s}
 	}
+|	'(' WithSelectStmt ')'
+	{
+		s := $2.(ast.ResultSetNode)
+		src := parser.src
+		// See the implementation of yyParse function
+		s.SetText(src[yyS[yypt-1].offset-1:yyS[yypt].offset-1])
+		$$ = &ast.SubqueryExpr{Query: s}
+	}
+
+// See https://dev.mysql.com/doc/refman/8.0/en/with.html
+WithSelectStmt:
+	WithClause SelectStmt
+	{
+		st := $2.(*ast.SelectStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+|	WithClause UnionStmt
+	{
+		st := $2.(*ast.UnionStmt)
+		st.With = $1.(*ast.WithClause)
+		$$ = st
+	}
+
+WithClause:
+	"WITH" WithList
+	{
+		$$ = &ast.WithClause{CTEs: $2.([]*ast.CommonTableExpression)}
+	}
+|	"WITH" "RECURSIVE" WithList
+	{
+		$$ = &ast.WithClause{IsRecursive: true, CTEs: $3.([]*ast.CommonTableExpression)}
+	}
+
+WithList:
+	CommonTableExpr
+	{
+		$$ = []*ast.CommonTableExpression{$1.(*ast.CommonTableExpression)}
+	}
+|	WithList ',' CommonTableExpr
+	{
+		$$ = append($1.([]*ast.CommonTableExpression), $3.(*ast.CommonTableExpression))
+	}
+
+CommonTableExpr:
+	Identifier ColumnNameListOptWithBrackets "AS" SubSelect
+	{
+		cte := &ast.CommonTableExpression{Name: model.NewCIStr($1), Query: $4.(*ast.SubqueryExpr)}
+		for _, col := range $2.([]*ast.ColumnName) {
+			cte.ColNameList = append(cte.ColNameList, col.Name)
+		}
+		$$ = cte
+	}
 
 // See https://dev.mysql.com/doc/refman/5.7/en/innodb-locking-reads.html
 SelectLockOpt:
@@ -5127,6 +5680,31 @@ AdminStmt:
 	{
 		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDL}
 	}
+|	"ADMIN" "SHOW" "DDL" "JOBS"
+	{
+		$$ = &ast.AdminStmt{Tp: ast.AdminShowDDLJobs}
+	}
+|	"ADMIN" "CANCEL" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCancelDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
+|	"ADMIN" "PAUSE" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminPauseDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
+|	"ADMIN" "RESUME" "DDL" "JOBS" NumList
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminResumeDDLJobs,
+			JobIDs:	$5.([]int64),
+		}
+	}
 |	"ADMIN" "CHECK" "TABLE" TableNameList
 	{
 		$$ = &ast.AdminStmt{
@@ -5134,6 +5712,83 @@ AdminStmt:
 			Tables: $4.([]*ast.TableName),
 		}
 	}
+|	"ADMIN" "CHECK" "INDEX" TableName Identifier HandleRangeListOpt
+	{
+		stmt := &ast.AdminStmt{
+			Tp:	ast.AdminCheckIndex,
+			Tables:	[]*ast.TableName{$4.(*ast.TableName)},
+			Index:	$5,
+		}
+		if $6 != nil {
+			stmt.HandleRanges = $6.([]ast.HandleRange)
+		}
+		$$ = stmt
+	}
+|	"ADMIN" "RECOVER" "INDEX" TableName Identifier
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminRecoverIndex,
+			Tables:	[]*ast.TableName{$4.(*ast.TableName)},
+			Index:	$5,
+		}
+	}
+|	"ADMIN" "CLEANUP" "INDEX" TableName Identifier
+	{
+		$$ = &ast.AdminStmt{
+			Tp:	ast.AdminCleanupIndex,
+			Tables:	[]*ast.TableName{$4.(*ast.TableName)},
+			Index:	$5,
+		}
+	}
+
+HandleRangeListOpt:
+	{
+		$$ = nil
+	}
+|	HandleRangeList
+	{
+		$$ = $1
+	}
+
+HandleRangeList:
+	HandleRange
+	{
+		$$ = []ast.HandleRange{$1.(ast.HandleRange)}
+	}
+|	HandleRangeList ',' HandleRange
+	{
+		$$ = append($1.([]ast.HandleRange), $3.(ast.HandleRange))
+	}
+
+HandleRange:
+	'(' SignedNum ',' SignedNum ')'
+	{
+		$$ = ast.HandleRange{Begin: $2.(int64), End: $4.(int64)}
+	}
+
+SignedNum:
+	intLit
+	{
+		$$ = int64(getUint64FromNUM($1))
+	}
+|	'+' intLit
+	{
+		$$ = int64(getUint64FromNUM($2))
+	}
+|	'-' intLit
+	{
+		$$ = -int64(getUint64FromNUM($2))
+	}
+
+NumList:
+	intLit
+	{
+		$$ = []int64{int64(getUint64FromNUM($1))}
+	}
+|	NumList ',' intLit
+	{
+		$$ = append($1.([]int64), int64(getUint64FromNUM($3)))
+	}
 
 /****************************Show Statement*******************************/
 ShowStmt:
@@ -5156,6 +5811,13 @@ ShowStmt:
 			Table:	$4.(*ast.TableName),
 		}
 	}
+|	"SHOW" "CREATE" "VIEW" TableName
+	{
+		$$ = &ast.ShowStmt{
+			Tp:	ast.ShowCreateView,
+			Table:	$4.(*ast.TableName),
+		}
+	}
 |	"SHOW" "CREATE" "DATABASE" DBName 
 	{
 		$$ = &ast.ShowStmt{
@@ -5317,6 +5979,13 @@ ShowTargetFilterable:
 			GlobalScope: $1.(bool),
 		}
 	}
+|	GlobalScope "BINDINGS"
+	{
+		$$ = &ast.ShowStmt{
+			Tp: ast.ShowBindings,
+			GlobalScope: $1.(bool),
+		}
+	}
 |	"COLLATION"
 	{
 		$$ = &ast.ShowStmt{
@@ -5474,6 +6143,7 @@ Statement:
 |	AdminStmt
 |	AlterTableStmt
 |	AlterUserStmt
+|	AlterViewStmt
 |	AnalyzeTableStmt
 |	BeginTransactionStmt
 |	BinlogStmt
@@ -5486,6 +6156,8 @@ Statement:
 |	CreateIndexStmt
 |	CreateTableStmt
 |	CreateUserStmt
+|	CreateViewStmt
+|	CreateBindingStmt
 |	DoStmt
 |	DropDatabaseStmt
 |	DropIndexStmt
@@ -5493,11 +6165,13 @@ Statement:
 |	DropViewStmt
 |	DropUserStmt
 |	DropStatsStmt
+|	DropBindingStmt
 |	FlushStmt
 |	GrantStmt
 |	InsertIntoStmt
 |	KillStmt
 |	LoadDataStmt
+|	NonTransactionalDMLStmt
 |	PreparedStmt
 |	RollbackStmt
 |	RenameTableStmt
@@ -5505,6 +6179,7 @@ Statement:
 |	RevokeStmt
 |	SelectStmt
 |	UnionStmt
+|	WithSelectStmt
 |	SetStmt
 |	ShowStmt
 |	TruncateTableStmt
@@ -5519,6 +6194,25 @@ Statement:
 |	UnlockTablesStmt
 |	LockTablesStmt
 
+/******************************************************************
+ * Non-transactional DML statement
+ * BATCH ON col LIMIT n DELETE FROM t WHERE ...
+ * BATCH ON col LIMIT n UPDATE t SET ... WHERE ...
+ ******************************************************************/
+NonTransactionalDMLStmt:
+	"BATCH" "ON" ColumnName "LIMIT" NUM ShardableDMLStmt
+	{
+		$$ = &ast.NonTransactionalDMLStmt{
+			ShardColumn:	$3.(*ast.ColumnName),
+			Limit:		getUint64FromNUM($5),
+			DMLStmt:	$6.(ast.StmtNode),
+		}
+	}
+
+ShardableDMLStmt:
+	DeleteFromStmt
+|	UpdateStmt
+
 ExplainableStmt:
 	SelectStmt
 |	DeleteFromStmt
@@ -5526,6 +6220,7 @@ ExplainableStmt:
 |	InsertIntoStmt
 |	ReplaceIntoStmt
 |	UnionStmt
+|	WithSelectStmt
 
 StatementList:
 	Statement
@@ -6222,22 +6917,24 @@ CommaOpt:
  *  https://dev.mysql.com/doc/refman/5.7/en/account-management-sql.html
  ************************************************************************************/
 CreateUserStmt:
-	"CREATE" "USER" IfNotExists UserSpecList
+	"CREATE" "USER" IfNotExists UserSpecList RequireClauseOpt
 	{
  		// See https://dev.mysql.com/doc/refman/5.7/en/create-user.html
 		$$ = &ast.CreateUserStmt{
 			IfNotExists: $3.(bool),
 			Specs: $4.([]*ast.UserSpec),
+			Require: $5.(ast.RequireType),
 		}
 	}
 
 /* See http://dev.mysql.com/doc/refman/5.7/en/alter-user.html */
 AlterUserStmt:
-	"ALTER" "USER" IfExists UserSpecList
+	"ALTER" "USER" IfExists UserSpecList RequireClauseOpt
 	{
 		$$ = &ast.AlterUserStmt{
 			IfExists: $3.(bool),
 			Specs: $4.([]*ast.UserSpec),
+			Require: $5.(ast.RequireType),
 		}
 	}
 | 	"ALTER" "USER" IfExists "USER" '(' ')' "IDENTIFIED" "BY" AuthString
@@ -6303,17 +7000,36 @@ HashString:
  * See https://dev.mysql.com/doc/refman/5.7/en/grant.html
  *************************************************************************************/
 GrantStmt:
-	 "GRANT" PrivElemList "ON" ObjectType PrivLevel "TO" UserSpecList WithGrantOptionOpt
+	 "GRANT" PrivElemList "ON" ObjectType PrivLevel "TO" UserSpecList RequireClauseOpt WithGrantOptionOpt
 	 {
 		$$ = &ast.GrantStmt{
 			Privs: $2.([]*ast.PrivElem),
 			ObjectType: $4.(ast.ObjectTypeType),
 			Level: $5.(*ast.GrantLevel),
 			Users: $7.([]*ast.UserSpec),
-			WithGrant: $8.(bool),
+			Require: $8.(ast.RequireType),
+			WithGrant: $9.(bool),
 		}
 	 }
 
+/* See https://dev.mysql.com/doc/refman/5.7/en/create-user.html#create-user-tls */
+RequireClauseOpt:
+	{
+		$$ = ast.RequireNotSpecified
+	}
+|	"REQUIRE" "NONE"
+	{
+		$$ = ast.RequireNone
+	}
+|	"REQUIRE" "SSL"
+	{
+		$$ = ast.RequireSSL
+	}
+|	"REQUIRE" "X509"
+	{
+		$$ = ast.RequireX509
+	}
+
 WithGrantOptionOpt:
 	{
 		$$ = false
@@ -6369,6 +7085,10 @@ PrivType:
 	{
 		$$ = mysql.CreateUserPriv
 	}
+|	"CREATE" "VIEW"
+	{
+		$$ = mysql.CreateViewPriv
+	}
 |	"TRIGGER"
 	{
 		$$ = mysql.TriggerPriv
@@ -6409,6 +7129,10 @@ PrivType:
 	{
 		$$ = mysql.ShowDBPriv
 	}
+|	"SHOW" "VIEW"
+	{
+		$$ = mysql.ShowViewPriv
+	}
 |	"UPDATE"
 	{
 		$$ = mysql.UpdatePriv
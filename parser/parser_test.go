@@ -21,6 +21,7 @@ import (
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/charset"
 	"github.com/pingcap/tidb/util/testleak"
@@ -90,10 +91,10 @@ func (s *testParserSuite) TestSimple(c *C) {
 		"delay_key_write", "isolation", "partitions", "repeatable", "committed", "uncommitted", "only", "serializable", "level",
 		"curtime", "variables", "dayname", "version", "btree", "hash", "row_format", "dynamic", "fixed", "compressed",
 		"compact", "redundant", "sql_no_cache sql_no_cache", "sql_cache sql_cache", "action", "round",
-		"enable", "disable", "reverse", "space", "privileges", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
+		"enable", "disable", "reverse", "space", "privileges", "pessimistic", "optimistic", "batch", "query", "binding", "bindings", "jobs", "cancel", "recover", "cleanup", "pause", "resume", "get_lock", "release_lock", "sleep", "no", "greatest", "least",
 		"binlog", "hex", "unhex", "function", "indexes", "from_unixtime", "processlist", "events", "less", "than", "timediff",
 		"ln", "log", "log2", "log10", "timestampdiff", "pi", "quote", "none", "super", "default", "shared", "exclusive",
-		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version",
+		"always", "stats", "stats_meta", "stats_histogram", "stats_buckets", "tidb_version", "x509",
 	}
 	for _, kw := range unreservedKws {
 		src := fmt.Sprintf("SELECT %s FROM tbl;", kw)
@@ -246,6 +247,9 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 			WHERE stuff.value >= ALL (SELECT stuff.value
 			FROM stuff)`, true},
 		{"BEGIN", true},
+		{"BEGIN PESSIMISTIC", true},
+		{"BEGIN OPTIMISTIC", true},
+		{"BEGIN PESSIMISTIC OPTIMISTIC", false},
 		{"START TRANSACTION", true},
 		// 45
 		{"COMMIT", true},
@@ -270,6 +274,22 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		{"DO 1", true},
 		{"DO 1 from t", false},
 
+		// non-transactional DML statement
+		{"BATCH ON id LIMIT 10 DELETE FROM t WHERE a > 1", true},
+		{"BATCH ON t.id LIMIT 10 UPDATE t SET a = a + 1 WHERE a > 1", true},
+		{"BATCH ON id LIMIT 10 INSERT INTO t VALUES (1)", false},
+		{"BATCH ON id DELETE FROM t", false},
+		{"BATCH ON id LIMIT 1.5 DELETE FROM t", false},
+
+		// binding statement
+		{"CREATE BINDING FOR SELECT * FROM t WHERE a > 1 USING SELECT /*+ TIDB_INLJ(t) */ * FROM t WHERE a > 1", true},
+		{"CREATE GLOBAL BINDING FOR SELECT * FROM t USING SELECT * FROM t", true},
+		{"CREATE BINDING FOR DELETE FROM t USING DELETE FROM t", false},
+		{"DROP SESSION BINDING FOR SELECT * FROM t WHERE a > 1", true},
+		{"DROP BINDING SELECT * FROM t", false},
+		{"SHOW BINDINGS", true},
+		{"SHOW GLOBAL BINDINGS", true},
+
 		// load data
 		{"load data infile '/tmp/t.csv' into table t", true},
 		{"load data infile '/tmp/t.csv' into table t fields terminated by 'ab'", true},
@@ -316,6 +336,24 @@ func (s *testParserSuite) TestDMLStmt(c *C) {
 		// for admin
 		{"admin show ddl;", true},
 		{"admin check table t1, t2;", true},
+		{"admin show ddl jobs;", true},
+		{"admin cancel ddl jobs 1", true},
+		{"admin cancel ddl jobs 1, 2", true},
+		{"admin cancel ddl jobs", false},
+		{"admin pause ddl jobs 1", true},
+		{"admin pause ddl jobs 1, 2", true},
+		{"admin pause ddl jobs", false},
+		{"admin resume ddl jobs 1, 2", true},
+		{"admin resume ddl jobs", false},
+		{"admin check index t idx", true},
+		{"admin check index test.t idx (1, 10)", true},
+		{"admin check index t idx (-10, -1), (+1, 100)", true},
+		{"admin check index t idx ()", false},
+		{"admin check index t", false},
+		{"admin recover index t idx", true},
+		{"admin recover index test.t idx", true},
+		{"admin cleanup index t idx", true},
+		{"admin cleanup index t", false},
 
 		// for on duplicate key update
 		{"INSERT INTO t (a,b,c) VALUES (1,2,3),(4,5,6) ON DUPLICATE KEY UPDATE c=VALUES(a)+VALUES(b);", true},
@@ -407,6 +445,8 @@ func (s *testParserSuite) TestDBAStmt(c *C) {
 		// for show create table
 		{"show create table test.t", true},
 		{"show create table t", true},
+		{"show create view test.v", true},
+		{"show create view v", true},
 		// for show stats_meta.
 		{"show stats_meta", true},
 		{"show stats_meta where table_name = 't'", true},
@@ -1171,6 +1211,16 @@ func (s *testParserSuite) TestDDL(c *C) {
 		{"drop table if exists xxx", true},
 		{"drop table if not exists xxx", false},
 		{"drop view if exists xxx", true},
+		{"drop view xxx, yyy", true},
+		// for create/alter view
+		{"create view v as select * from t", true},
+		{"create or replace view v as select a, b from t where a > 1", true},
+		{"create view v (a, b) as select c, d from t", true},
+		{"create view v as select 1 union select 2", true},
+		{"create view if not exists v as select 1", false},
+		{"create view v", false},
+		{"alter view v as select * from t", true},
+		{"alter view v (a) as select c from t", true},
 		{"drop stats t", true},
 		// for issue 974
 		{`CREATE TABLE address (
@@ -1365,6 +1415,49 @@ func (s *testParserSuite) TestOptimizerHints(c *C) {
 	c.Assert(hints[1].HintName.L, Equals, "tidb_inlj")
 	c.Assert(hints[1].Tables[0].L, Equals, "t3")
 	c.Assert(hints[1].Tables[1].L, Equals, "t4")
+
+	stmt, err = parser.Parse("select /*+ HASH_JOIN(t1, t2) USE_INDEX(t1, idx1, IDX2) IGNORE_INDEX(t2, idx3) HASH_AGG() stream_agg() "+
+		"NO_AGG_PUSH_DOWN() MAX_EXECUTION_TIME(1000) READ_FROM_STORAGE(TIKV[t1, t2]) BKA(t1) */ c1, c2 from t1, t2", "", "")
+	c.Assert(err, IsNil)
+	hints = stmt[0].(*ast.SelectStmt).TableHints
+	c.Assert(hints, HasLen, 9)
+	c.Assert(hints[0].HintName.L, Equals, "hash_join")
+	c.Assert(hints[0].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t1"), model.NewCIStr("t2")})
+	c.Assert(hints[1].HintName.L, Equals, "use_index")
+	c.Assert(hints[1].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t1")})
+	c.Assert(hints[1].Indexes, DeepEquals, []model.CIStr{model.NewCIStr("idx1"), model.NewCIStr("IDX2")})
+	c.Assert(hints[2].HintName.L, Equals, "ignore_index")
+	c.Assert(hints[2].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t2")})
+	c.Assert(hints[2].Indexes, DeepEquals, []model.CIStr{model.NewCIStr("idx3")})
+	c.Assert(hints[3].HintName.L, Equals, "hash_agg")
+	c.Assert(hints[3].Tables, HasLen, 0)
+	c.Assert(hints[4].HintName.L, Equals, "stream_agg")
+	c.Assert(hints[5].HintName.L, Equals, "no_agg_push_down")
+	c.Assert(hints[6].HintName.L, Equals, "max_execution_time")
+	c.Assert(hints[6].MaxExecutionTime, Equals, uint64(1000))
+	c.Assert(hints[7].HintName.L, Equals, "read_from_storage")
+	c.Assert(hints[7].StoreType.L, Equals, "tikv")
+	c.Assert(hints[7].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t1"), model.NewCIStr("t2")})
+	c.Assert(hints[8].HintName.L, Equals, "bka")
+
+	// A USE_INDEX hint without the indexes means no index is used.
+	stmt, err = parser.Parse("select /*+ USE_INDEX(t1) */ c1 from t1", "", "")
+	c.Assert(err, IsNil)
+	hints = stmt[0].(*ast.SelectStmt).TableHints
+	c.Assert(hints[0].Tables, DeepEquals, []model.CIStr{model.NewCIStr("t1")})
+	c.Assert(hints[0].Indexes, HasLen, 0)
+
+	// The hints with unrecognized arguments don't fail the statement, they're marked invalid.
+	stmt, err = parser.Parse("select /*+ FOO(1, 'x') BAR(t1,) QB_NAME(@qb) HASH_JOIN(t1) BAZ(a(b, 1.5), [c]) SET_VAR(x = -1) */ c1 from t1", "", "")
+	c.Assert(err, IsNil)
+	hints = stmt[0].(*ast.SelectStmt).TableHints
+	c.Assert(hints, HasLen, 6)
+	for i, hint := range hints {
+		c.Assert(hint.InvalidArgs, Equals, hint.HintName.L != "hash_join", Commentf("hint %d %s", i, hint.HintName))
+	}
+	// The arguments must be balanced.
+	_, err = parser.Parse("select /*+ FOO(1, (2) */ c1 from t1", "", "")
+	c.Assert(err, NotNil)
 }
 
 func (s *testParserSuite) TestType(c *C) {
@@ -1444,11 +1537,16 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{`ALTER USER IF EXISTS USER() IDENTIFIED BY 'new-password'`, true},
 		{`DROP USER 'root'@'localhost', 'root1'@'localhost'`, true},
 		{`DROP USER IF EXISTS 'root'@'localhost'`, true},
+		{`CREATE USER 'root'@'%' IDENTIFIED BY 'new-password' REQUIRE SSL`, true},
+		{`CREATE USER 'root'@'%' REQUIRE X509`, true},
+		{`ALTER USER 'root'@'%' REQUIRE NONE`, true},
+		{`CREATE USER 'root'@'%' REQUIRE`, false},
 
 		// for grant statement
 		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost';", true},
 		{"GRANT ALL ON db1.* TO 'jeffrey'@'localhost' WITH GRANT OPTION;", true},
 		{"GRANT SELECT ON db2.invoice TO 'jeffrey'@'localhost';", true},
+		{"GRANT CREATE VIEW, SHOW VIEW ON db2.* TO 'jeffrey'@'localhost';", true},
 		{"GRANT ALL ON *.* TO 'someuser'@'somehost';", true},
 		{"GRANT SELECT, INSERT ON *.* TO 'someuser'@'somehost';", true},
 		{"GRANT ALL ON mydb.* TO 'someuser'@'somehost';", true},
@@ -1458,6 +1556,8 @@ func (s *testParserSuite) TestPrivilege(c *C) {
 		{"GRANT SELECT (col1), INSERT (col1,col2) ON mydb.mytbl TO 'someuser'@'somehost';", true},
 		{"grant all privileges on zabbix.* to 'zabbix'@'localhost' identified by 'password';", true},
 		{"GRANT SELECT ON test.* to 'test'", true}, // For issue 2654.
+		{"GRANT ALL ON *.* TO 'someuser'@'somehost' REQUIRE SSL WITH GRANT OPTION;", true},
+		{"GRANT ALL ON *.* TO 'someuser'@'somehost' WITH GRANT OPTION REQUIRE SSL;", false},
 
 		// for revoke statement
 		{"REVOKE ALL ON db1.* FROM 'jeffrey'@'localhost';", true},
@@ -1647,6 +1747,12 @@ func (s *testParserSuite) TestExplain(c *C) {
 		{"explain replace into foo values (1 || 2)", true},
 		{"explain update t set id = id + 1 order by id desc;", true},
 		{"explain select c1 from t1 union (select c2 from t2) limit 1, 1", true},
+		{"explain analyze select c1 from t1", true},
+		{"explain analyze delete from t1 where c1 > 1", true},
+		{"explain analyze t1", false},
+		{"explain format = 'row' select c1 from t1", true},
+		{"explain format = \"json\" select c1 from t1", true},
+		{"explain format = row select c1 from t1", false},
 	}
 	s.RunTest(c, table)
 }
@@ -1762,6 +1868,153 @@ func (s *testParserSuite) TestAnalyze(c *C) {
 	s.RunTest(c, table)
 }
 
+func (s *testParserSuite) TestCreateView(c *C) {
+	defer testleak.AfterTest(c)()
+	tests := []struct {
+		input     string
+		orReplace bool
+		cols      int
+		sel       string
+	}{
+		{"create view v as select * from t", false, 0, "select * from t"},
+		{"create or replace view v (a, b) as select c, d from t where c > 1 ; select 1", true, 2, "select c, d from t where c > 1"},
+		{"alter view v as select 1 union select 2", true, 0, "select 1 union select 2"},
+	}
+	parser := New()
+	for _, tt := range tests {
+		stmts, err := parser.Parse(tt.input, "", "")
+		c.Assert(err, IsNil)
+		stmt := stmts[0].(*ast.CreateViewStmt)
+		c.Assert(stmt.OrReplace, Equals, tt.orReplace)
+		c.Assert(stmt.Cols, HasLen, tt.cols)
+		c.Assert(stmt.Select.Text(), Equals, tt.sel)
+	}
+}
+
+func (s *testParserSuite) TestBinding(c *C) {
+	defer testleak.AfterTest(c)()
+	parser := New()
+	stmts, err := parser.Parse("create global binding for select * from t1 join t2 using (a) where t1.b = 1 using "+
+		"select /*+ tidb_smj(t1, t2) */ * from t1 join t2 using (a) where t1.b = 1 ; select 1", "", "")
+	c.Assert(err, IsNil)
+	create := stmts[0].(*ast.CreateBindingStmt)
+	c.Assert(create.GlobalScope, IsTrue)
+	c.Assert(create.OriginSel.Text(), Equals, "select * from t1 join t2 using (a) where t1.b = 1")
+	c.Assert(create.HintedSel.Text(), Equals, "select /*+ tidb_smj(t1, t2) */ * from t1 join t2 using (a) where t1.b = 1")
+	c.Assert(create.HintedSel.(*ast.SelectStmt).TableHints, HasLen, 1)
+
+	stmts, err = parser.Parse("drop session binding for select * from t where a in (1, 2)", "", "")
+	c.Assert(err, IsNil)
+	drop := stmts[0].(*ast.DropBindingStmt)
+	c.Assert(drop.GlobalScope, IsFalse)
+	c.Assert(drop.OriginSel.Text(), Equals, "select * from t where a in (1, 2)")
+}
+
+func (s *testParserSuite) TestWindowFunction(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"select row_number() over () from t", true},
+		{"select rank() over (partition by a order by b desc), dense_rank() over (order by b) from t", true},
+		{"select lead(a) over (order by b), lag(a, 2) over (order by b), lag(a, 1, 0) over (order by b) from t", true},
+		{"select first_value(a) over (partition by b order by c rows unbounded preceding) from t", true},
+		{"select last_value(a) over (order by b rows between 1 preceding and 1 following) from t", true},
+		{"select sum(a) over (order by b range between unbounded preceding and current row) from t", true},
+		{"select count(*) over (partition by a), avg(b) over (), max(c) over (), min(c) over () from t", true},
+		{"select sum(a) over (rows between current row and unbounded following) from t", true},
+		{"select count(distinct a) over () from t", true},
+		{"select a, sum(b) from t group by a", true},
+		{"select rows, current, preceding, following, unbounded from t", true},
+		{"select row_number() from t", false},
+		{"select row_number() over from t", false},
+		{"select sum(a) over (rows unbounded following) from t", false},
+		{"select a over from t", false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("select sum(a) over (partition by b order by c rows between 2 preceding and current row) from t", "", "")
+	c.Assert(err, IsNil)
+	wf, ok := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.WindowFuncExpr)
+	c.Assert(ok, IsTrue)
+	c.Assert(wf.F, Equals, "sum")
+	c.Assert(wf.Args, HasLen, 1)
+	c.Assert(wf.Spec.PartitionBy.Items, HasLen, 1)
+	c.Assert(wf.Spec.OrderBy.Items, HasLen, 1)
+	c.Assert(wf.Spec.Frame.Type, Equals, ast.Rows)
+	c.Assert(wf.Spec.Frame.Extent.Start.Type, Equals, ast.Preceding)
+	c.Assert(wf.Spec.Frame.Extent.Start.Expr.GetValue(), Equals, int64(2))
+	c.Assert(wf.Spec.Frame.Extent.End.Type, Equals, ast.CurrentRow)
+}
+
+func (s *testParserSuite) TestCommonTableExpression(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"with cte as (select 1) select * from cte", true},
+		{"with cte(a, b) as (select 1, 2), cte2 as (select a from cte) select * from cte2", true},
+		{"with recursive cte(n) as (select 1 union all select n + 1 from cte where n < 10) select * from cte", true},
+		{"with cte as (select 1) select * from cte union select 2", true},
+		{"select * from (with cte as (select 1) select * from cte) as t", true},
+		{"select (with cte as (select 1) select * from cte)", true},
+		{"insert into t with cte as (select 1) select * from cte", true},
+		{"insert into t (a) with cte as (select 1) select * from cte", true},
+		{"explain with cte as (select 1) select * from cte", true},
+		{"create view v as with cte as (select 1) select * from cte", true},
+		{"with cte as select 1 select * from cte", false},
+		{"with cte as (select 1)", false},
+		{"with recursive as (select 1) select 1", false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("with recursive cte(n) as (select 1 union select n + 1 from cte) select n from cte", "", "")
+	c.Assert(err, IsNil)
+	sel, ok := stmt.(*ast.SelectStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(sel.With.IsRecursive, IsTrue)
+	c.Assert(sel.With.CTEs, HasLen, 1)
+	cte := sel.With.CTEs[0]
+	c.Assert(cte.Name.L, Equals, "cte")
+	c.Assert(cte.ColNameList, HasLen, 1)
+	c.Assert(cte.ColNameList[0].L, Equals, "n")
+	union, ok := cte.Query.Query.(*ast.UnionStmt)
+	c.Assert(ok, IsTrue)
+	c.Assert(union.SelectList.Selects, HasLen, 2)
+}
+
+func (s *testParserSuite) TestPartition(c *C) {
+	defer testleak.AfterTest(c)()
+	table := []testCase{
+		{"create table t (a int) partition by hash (a) partitions 4", true},
+		{"create table t (a date) partition by range (to_days(a)) (partition p0 values less than (to_days('2017-01-01')), partition p1 values less than maxvalue)", true},
+		{"alter table t add partition (partition p2 values less than (2000))", true},
+		{"alter table t add partition (partition p2 values less than (2000), partition p3 values less than maxvalue)", true},
+		{"alter table t drop partition p1", true},
+		{"alter table t drop partition p1, p2", true},
+		{"alter table t truncate partition p1, p2", true},
+		{"alter table t add partition p2 values less than (2000)", false},
+		{"alter table t drop partition", false},
+	}
+	s.RunTest(c, table)
+
+	parser := New()
+	stmt, err := parser.ParseOneStmt("create table t (a int, b date) partition by range ( year(b) ) (partition p0 values less than (2000), partition p1 values less than maxvalue)", "", "")
+	c.Assert(err, IsNil)
+	partition := stmt.(*ast.CreateTableStmt).Partition
+	c.Assert(partition, NotNil)
+	c.Assert(partition.Tp, Equals, model.PartitionTypeRange)
+	c.Assert(partition.Expr.Text(), Equals, "year(b)")
+	c.Assert(partition.Definitions, HasLen, 2)
+	c.Assert(partition.Definitions[0].Name.L, Equals, "p0")
+	c.Assert(partition.Definitions[0].LessThan, HasLen, 1)
+	c.Assert(partition.Definitions[1].MaxValue, IsTrue)
+
+	stmt, err = parser.ParseOneStmt("alter table t drop partition p0, P1", "", "")
+	c.Assert(err, IsNil)
+	spec := stmt.(*ast.AlterTableStmt).Specs[0]
+	c.Assert(spec.Tp, Equals, ast.AlterTableDropPartition)
+	c.Assert(spec.PartitionNames, DeepEquals, []model.CIStr{model.NewCIStr("p0"), model.NewCIStr("P1")})
+}
+
 func (s *testParserSuite) TestGeneratedColumn(c *C) {
 	defer testleak.AfterTest(c)()
 	tests := []struct {
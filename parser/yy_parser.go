@@ -21,6 +21,7 @@ import (
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/hack"
@@ -231,3 +232,73 @@ func getUint64FromNUM(num interface{}) uint64 {
 	}
 	return 0
 }
+
+// newAggregateOrWindowFunc creates a window function if the aggregate function is followed by an OVER clause,
+// otherwise it creates an aggregate function.
+func newAggregateOrWindowFunc(name string, args []ast.ExprNode, distinct bool, spec interface{}) ast.ExprNode {
+	if spec != nil {
+		return &ast.WindowFuncExpr{F: name, Args: args, Distinct: distinct, Spec: *(spec.(*ast.WindowSpec))}
+	}
+	return &ast.AggregateFuncExpr{F: name, Args: args, Distinct: distinct}
+}
+
+// hintArg is a token in the arguments of an optimizer hint, or the arguments in parentheses if tok is '('.
+type hintArg struct {
+	tok   int
+	ident string
+	val   interface{}
+	args  []hintArg
+}
+
+// newTableOptimizerHint creates an optimizer hint from its arguments. The arguments can be a table list,
+// a number, or a storage with a table list like TIKV[t1, t2]. Any other arguments are accepted by the grammar,
+// so an unknown hint doesn't fail the statement, and the hint is marked invalid to be ignored with a warning.
+func newTableOptimizerHint(name string, args []hintArg) *ast.TableOptimizerHint {
+	hint := &ast.TableOptimizerHint{HintName: model.NewCIStr(name)}
+	if tables, ok := hintTableList(args); ok {
+		switch hint.HintName.L {
+		case "use_index", "ignore_index":
+			// The first argument is the table, the others are the indexes.
+			if len(tables) > 0 {
+				hint.Tables, hint.Indexes = tables[:1], tables[1:]
+			}
+		default:
+			hint.Tables = tables
+		}
+		return hint
+	}
+	if len(args) == 1 && args[0].tok == intLit {
+		hint.MaxExecutionTime = getUint64FromNUM(args[0].val)
+		return hint
+	}
+	if n := len(args); n > 3 && args[0].tok == identifier && args[1].tok == '[' && args[n-1].tok == ']' {
+		if tables, ok := hintTableList(args[2 : n-1]); ok && len(tables) > 0 {
+			hint.StoreType, hint.Tables = model.NewCIStr(args[0].ident), tables
+			return hint
+		}
+	}
+	hint.InvalidArgs = true
+	return hint
+}
+
+// hintTableList converts the arguments like t1, t2 to a table list.
+func hintTableList(args []hintArg) ([]model.CIStr, bool) {
+	tables := make([]model.CIStr, 0, (len(args)+1)/2)
+	for i, arg := range args {
+		if i%2 == 1 {
+			if arg.tok != ',' {
+				return nil, false
+			}
+			continue
+		}
+		if arg.tok != identifier {
+			return nil, false
+		}
+		tables = append(tables, model.NewCIStr(arg.ident))
+	}
+	if len(args)%2 == 0 && len(args) > 0 {
+		// The list ends with a comma.
+		return nil, false
+	}
+	return tables, true
+}
@@ -36,6 +36,7 @@ const (
 	TableStagesCurrent          = "EVENTS_STAGES_CURRENT"
 	TableStagesHistory          = "EVENTS_STAGES_HISTORY"
 	TableStagesHistoryLong      = "EVENTS_STAGES_HISTORY_LONG"
+	TableStmtsSummaryByDigest   = "EVENTS_STATEMENTS_SUMMARY_BY_DIGEST"
 )
 
 // PerfSchemaTables is a shortcut to involve all table names.
@@ -55,6 +56,7 @@ var PerfSchemaTables = []string{
 	TableStagesCurrent,
 	TableStagesHistory,
 	TableStagesHistoryLong,
+	TableStmtsSummaryByDigest,
 }
 
 // ColumnSetupActors contains the column name definitions for table setup_actors, same as MySQL.
@@ -690,3 +692,40 @@ var ColumnStagesHistoryLong = []string{
 	"NESTING_EVENT_ID",
 	"NESTING_EVENT_TYPE",
 }
+
+// ColumnStmtsSummaryByDigest contains the column name definitions for table events_statements_summary_by_digest,
+// it's a subset of MySQL. The timers are in picoseconds as MySQL's, and DIGEST is the sha256 hash of DIGEST_TEXT.
+//
+// CREATE TABLE if not exists performance_schema.events_statements_summary_by_digest (
+// 		SCHEMA_NAME		VARCHAR(64),
+// 		DIGEST			VARCHAR(64),
+// 		DIGEST_TEXT		LONGTEXT,
+// 		COUNT_STAR		BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		MIN_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		AVG_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		MAX_TIMER_WAIT	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ERRORS		BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_WARNINGS	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ROWS_AFFECTED	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ROWS_SENT	BIGINT(20) UNSIGNED NOT NULL,
+// 		SUM_ROWS_EXAMINED	BIGINT(20) UNSIGNED NOT NULL,
+// 		FIRST_SEEN		DATETIME NOT NULL,
+// 		LAST_SEEN		DATETIME NOT NULL);
+var ColumnStmtsSummaryByDigest = []string{
+	"SCHEMA_NAME",
+	"DIGEST",
+	"DIGEST_TEXT",
+	"COUNT_STAR",
+	"SUM_TIMER_WAIT",
+	"MIN_TIMER_WAIT",
+	"AVG_TIMER_WAIT",
+	"MAX_TIMER_WAIT",
+	"SUM_ERRORS",
+	"SUM_WARNINGS",
+	"SUM_ROWS_AFFECTED",
+	"SUM_ROWS_SENT",
+	"SUM_ROWS_EXAMINED",
+	"FIRST_SEEN",
+	"LAST_SEEN",
+}
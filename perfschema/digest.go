@@ -0,0 +1,185 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// maxDigestTextLen is the maximum length of DIGEST_TEXT, the longer text is truncated, same as the default
+// performance_schema_max_digest_length of MySQL.
+const maxDigestTextLen = 1024
+
+// StmtExecInfo is the execution information of a finished statement.
+type StmtExecInfo struct {
+	// SchemaName is the current schema when the statement runs.
+	SchemaName string
+	// OriginalSQL is the text of the statement, it's normalized to get the digest.
+	OriginalSQL  string
+	Latency      time.Duration
+	Err          error
+	Warnings     uint64
+	RowsAffected uint64
+	RowsSent     uint64
+	RowsExamined uint64
+	EndTime      time.Time
+}
+
+type digestKey struct {
+	schemaName string
+	digest     string
+}
+
+// digestSummaryElem is the summary of the statements of a digest, it's a row of events_statements_summary_by_digest.
+type digestSummaryElem struct {
+	key        digestKey
+	digestText string
+	// handle is the handle of the row in the memory table.
+	handle          int64
+	execCount       uint64
+	sumLatency      time.Duration
+	minLatency      time.Duration
+	maxLatency      time.Duration
+	sumErrors       uint64
+	sumWarnings     uint64
+	sumRowsAffected uint64
+	sumRowsSent     uint64
+	sumRowsExamined uint64
+	firstSeen       time.Time
+	lastSeen        time.Time
+}
+
+func (e *digestSummaryElem) add(info *StmtExecInfo) {
+	if e.execCount == 0 || info.Latency < e.minLatency {
+		e.minLatency = info.Latency
+	}
+	if info.Latency > e.maxLatency {
+		e.maxLatency = info.Latency
+	}
+	if e.execCount == 0 {
+		e.firstSeen = info.EndTime
+	}
+	e.execCount++
+	e.sumLatency += info.Latency
+	if info.Err != nil {
+		e.sumErrors++
+	}
+	e.sumWarnings += info.Warnings
+	e.sumRowsAffected += info.RowsAffected
+	e.sumRowsSent += info.RowsSent
+	e.sumRowsExamined += info.RowsExamined
+	e.lastSeen = info.EndTime
+}
+
+func datetimeDatum(t time.Time) types.Time {
+	return types.Time{Time: types.FromGoTime(t.Truncate(time.Second)), Type: mysql.TypeDatetime}
+}
+
+// picoseconds converts the duration to picoseconds, the unit of the timers of MySQL's performance schema.
+func picoseconds(d time.Duration) uint64 {
+	return uint64(d.Nanoseconds()) * 1000
+}
+
+func (e *digestSummaryElem) toRecord() []types.Datum {
+	return types.MakeDatums(
+		e.key.schemaName,                      // SCHEMA_NAME
+		e.key.digest,                          // DIGEST
+		e.digestText,                          // DIGEST_TEXT
+		e.execCount,                           // COUNT_STAR
+		picoseconds(e.sumLatency),             // SUM_TIMER_WAIT
+		picoseconds(e.minLatency),             // MIN_TIMER_WAIT
+		picoseconds(e.sumLatency)/e.execCount, // AVG_TIMER_WAIT
+		picoseconds(e.maxLatency),             // MAX_TIMER_WAIT
+		e.sumErrors,                           // SUM_ERRORS
+		e.sumWarnings,                         // SUM_WARNINGS
+		e.sumRowsAffected,                     // SUM_ROWS_AFFECTED
+		e.sumRowsSent,                         // SUM_ROWS_SENT
+		e.sumRowsExamined,                     // SUM_ROWS_EXAMINED
+		datetimeDatum(e.firstSeen),            // FIRST_SEEN
+		datetimeDatum(e.lastSeen),             // LAST_SEEN
+	)
+}
+
+// digestSummary is a LRU of the digest summaries, the summaries are kept in the memory table
+// events_statements_summary_by_digest, the least recently used one is removed from the table if there are
+// more than capacity digests.
+type digestSummary struct {
+	mu       sync.Mutex
+	capacity int
+	elems    map[digestKey]*list.Element
+	lru      *list.List
+}
+
+func newDigestSummary(capacity int) *digestSummary {
+	return &digestSummary{
+		capacity: capacity,
+		elems:    make(map[digestKey]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+func (s *digestSummary) add(tbl table.Table, key digestKey, digestText string, info *StmtExecInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.elems[key]; ok {
+		s.lru.MoveToFront(e)
+		elem := e.Value.(*digestSummaryElem)
+		elem.add(info)
+		return errors.Trace(tbl.UpdateRecord(nil, elem.handle, nil, elem.toRecord(), nil))
+	}
+
+	elem := &digestSummaryElem{key: key, digestText: digestText}
+	elem.add(info)
+	handle, err := tbl.AddRecord(nil, elem.toRecord())
+	if err != nil {
+		return errors.Trace(err)
+	}
+	elem.handle = handle
+	s.elems[key] = s.lru.PushFront(elem)
+	if s.lru.Len() <= s.capacity {
+		return nil
+	}
+	oldest := s.lru.Remove(s.lru.Back()).(*digestSummaryElem)
+	delete(s.elems, oldest.key)
+	return errors.Trace(tbl.RemoveRecord(nil, oldest.handle, nil))
+}
+
+// SummarizeStatement implements StatementSummary SummarizeStatement interface.
+func (ps *perfSchema) SummarizeStatement(info *StmtExecInfo) {
+	if !enablePerfSchema {
+		return
+	}
+	tbl := ps.mTables[TableStmtsSummaryByDigest]
+	if tbl == nil {
+		return
+	}
+	normalized := parser.Normalize(info.OriginalSQL)
+	key := digestKey{schemaName: info.SchemaName, digest: parser.DigestNormalized(normalized)}
+	if len(normalized) > maxDigestTextLen {
+		normalized = normalized[:maxDigestTextLen]
+	}
+	err := ps.digestSummary.add(tbl, key, normalized, info)
+	if err != nil {
+		log.Errorf("Unable to update events_statements_summary_by_digest table %v", errors.ErrorStack(err))
+	}
+}
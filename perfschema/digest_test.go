@@ -0,0 +1,73 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package perfschema
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+type testDigestSuit struct {
+}
+
+var _ = Suite(&testDigestSuit{})
+
+func (p *testDigestSuit) TestDigestSummary(c *C) {
+	defer testleak.AfterTest(c)()
+	handle, err := NewPerfHandle()
+	c.Assert(err, IsNil)
+	defer func(enabled bool) {
+		enablePerfSchema = enabled
+	}(enablePerfSchema)
+	EnablePerfSchema()
+	ps := handle.(*perfSchema)
+	ps.digestSummary = newDigestSummary(2)
+	tbl := ps.mTables[TableStmtsSummaryByDigest]
+
+	now := time.Now()
+	ps.SummarizeStatement(&StmtExecInfo{SchemaName: "test", OriginalSQL: "select * from t where a = 1", Latency: 3,
+		RowsSent: 1, RowsExamined: 10, EndTime: now})
+	ps.SummarizeStatement(&StmtExecInfo{SchemaName: "test", OriginalSQL: "SELECT * FROM t WHERE a = 2", Latency: 1,
+		Err: errors.New("err"), EndTime: now.Add(time.Second)})
+	ps.SummarizeStatement(&StmtExecInfo{SchemaName: "test", OriginalSQL: "delete from t", Latency: 5,
+		RowsAffected: 4, EndTime: now})
+	c.Assert(ps.digestSummary.lru.Len(), Equals, 2)
+
+	elem := ps.digestSummary.elems[digestKey{"test", parser.DigestHash("select * from t where a = 3")}]
+	c.Assert(elem, NotNil)
+	row, err := tbl.Row(nil, elem.Value.(*digestSummaryElem).handle)
+	c.Assert(err, IsNil)
+	c.Assert(row, HasLen, len(ColumnStmtsSummaryByDigest))
+	c.Assert(row[2].GetString(), Equals, "select * from t where a = ?")
+	// COUNT_STAR, SUM_TIMER_WAIT, MIN_TIMER_WAIT, AVG_TIMER_WAIT, MAX_TIMER_WAIT, SUM_ERRORS, the timers are in
+	// picoseconds.
+	for i, v := range []uint64{2, 4000, 1000, 2000, 3000, 1} {
+		c.Assert(row[3+i].GetUint64(), Equals, v)
+	}
+	// SUM_ROWS_SENT, SUM_ROWS_EXAMINED
+	c.Assert(row[11].GetUint64(), Equals, uint64(1))
+	c.Assert(row[12].GetUint64(), Equals, uint64(10))
+
+	// The least recently used digest is evicted.
+	ps.SummarizeStatement(&StmtExecInfo{SchemaName: "test", OriginalSQL: "select 1", EndTime: now})
+	c.Assert(ps.digestSummary.lru.Len(), Equals, 2)
+	_, ok := ps.digestSummary.elems[digestKey{"test", parser.DigestHash("delete from t")}]
+	c.Assert(ok, IsTrue)
+	_, err = tbl.Row(nil, elem.Value.(*digestSummaryElem).handle)
+	c.Assert(err, NotNil)
+}
@@ -39,6 +39,9 @@ const (
 	// historyElemMax is maximum allowed number of elements in table events_xxx_history.
 	// TODO: make it configurable?
 	historyElemMax int64 = 1024
+	// digestSummaryElemMax is maximum allowed number of digests in table events_statements_summary_by_digest,
+	// the least recently used digest is evicted if it's exceeded.
+	digestSummaryElemMax = 1024
 )
 
 var setupActorsCols = []columnInfo{
@@ -197,6 +200,24 @@ var stagesCurrentCols = []columnInfo{
 	{mysql.TypeEnum, -1, 0, nil, []string{"TRANSACTION", "STATEMENT", "STAGE"}},
 }
 
+var stmtsSummaryByDigestCols = []columnInfo{
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeVarchar, 64, 0, nil, nil},
+	{mysql.TypeLongBlob, -1, 0, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeLonglong, 20, mysql.NotNullFlag | mysql.UnsignedFlag, nil, nil},
+	{mysql.TypeDatetime, 19, mysql.NotNullFlag, nil, nil},
+	{mysql.TypeDatetime, 19, mysql.NotNullFlag, nil, nil},
+}
+
 func createMemoryTable(meta *model.TableInfo, alloc autoid.Allocator) (table.Table, error) {
 	tbl, _ := tables.MemoryTableFromMeta(alloc, meta)
 	return tbl, nil
@@ -338,6 +359,7 @@ func (ps *perfSchema) initialize() (err error) {
 	ps.tables = make(map[string]*model.TableInfo)
 	ps.mTables = make(map[string]table.Table, len(ps.tables))
 	ps.stmtHandles = make([]int64, currentElemMax)
+	ps.digestSummary = newDigestSummary(digestSummaryElemMax)
 
 	allColDefs := [][]columnInfo{
 		setupActorsCols,
@@ -355,6 +377,7 @@ func (ps *perfSchema) initialize() (err error) {
 		stagesCurrentCols,
 		stagesCurrentCols, // same as above
 		stagesCurrentCols, // same as above
+		stmtsSummaryByDigestCols,
 	}
 
 	allColNames := [][]string{
@@ -373,6 +396,7 @@ func (ps *perfSchema) initialize() (err error) {
 		ColumnStagesCurrent,
 		ColumnStagesHistory,
 		ColumnStagesHistoryLong,
+		ColumnStmtsSummaryByDigest,
 	}
 
 	// initialize all table, column and result field definitions
@@ -37,11 +37,19 @@ type StatementInstrument interface {
 	EndStatement(state *StatementState)
 }
 
+// StatementSummary defines the methods for the statement summary by digest.
+type StatementSummary interface {
+	// SummarizeStatement adds a finished statement to the summary of its digest.
+	SummarizeStatement(info *StmtExecInfo)
+}
+
 // PerfSchema defines the methods to be invoked by the executor
 type PerfSchema interface {
 
 	// StatementInstrument is for statement instrumentation only.
 	StatementInstrument
+	// StatementSummary is for statement summary by digest.
+	StatementSummary
 
 	// GetDBMeta returns db info for PerformanceSchema.
 	GetDBMeta() *model.DBInfo
@@ -56,6 +64,8 @@ type perfSchema struct {
 	mTables     map[string]table.Table // Memory tables for perfSchema
 	stmtHandles []int64
 	stmtInfos   map[reflect.Type]*statementInfo
+	// digestSummary holds the rows of table events_statements_summary_by_digest.
+	digestSummary *digestSummary
 }
 
 var (
@@ -155,6 +155,53 @@ func (p *testPerfSchemaSuit) TestConcurrentStatement(c *C) {
 	wg.Wait()
 }
 
+func (p *testPerfSchemaSuit) TestStmtsSummaryByDigest(c *C) {
+	defer testleak.AfterTest(c)()
+	store, err := tidb.NewStore(tidb.EngineGoLevelDBMemory + "/test_digest")
+	c.Assert(err, IsNil)
+	defer store.Close()
+	_, err = tidb.BootstrapSession(store)
+	c.Assert(err, IsNil)
+	se := newSession(c, store, "test_digest")
+	defer se.Close()
+
+	mustExecSQL(c, se, "create table t (a int primary key, b int)")
+	mustExecSQL(c, se, "insert into t values (1, 1), (2, 2), (3, 3)")
+	mustExecSQL(c, se, "INSERT INTO t VALUES (4, 4)")
+	// The overflow error happens when the statement runs.
+	_, err = exec(se, "update t set b = b + 9223372036854775807")
+	c.Assert(err, NotNil)
+	for _, sql := range []string{"select * from t where b = 1", "select * from t where b in (1, 2)"} {
+		rs := mustExecSQL(c, se, sql)
+		for row, err := rs.Next(); row != nil; row, err = rs.Next() {
+			c.Assert(err, IsNil)
+		}
+		c.Assert(rs.Close(), IsNil)
+	}
+
+	rs := mustExecSQL(c, se, `select digest_text, count_star, sum_errors, sum_rows_affected, sum_rows_sent, sum_rows_examined,
+		min_timer_wait <= max_timer_wait, first_seen <= last_seen from performance_schema.events_statements_summary_by_digest
+		where schema_name = 'test_digest' and digest_text like '% t %' order by digest_text`)
+	var result []string
+	for row, err := rs.Next(); row != nil; row, err = rs.Next() {
+		c.Assert(err, IsNil)
+		line := fmt.Sprintf("%v", row.Data[0].GetValue())
+		for _, d := range row.Data[1:] {
+			line += fmt.Sprintf(" %v", d.GetValue())
+		}
+		result = append(result, line)
+	}
+	c.Assert(rs.Close(), IsNil)
+	c.Assert(result, DeepEquals, []string{
+		"create table t ( a int primary key , b int ) 1 0 0 0 0 1 1",
+		"insert into t values ( ... ) 1 0 1 0 0 1 1",
+		"insert into t values ( ... ) , ( ... ) , ( ... ) 1 0 3 0 0 1 1",
+		"select * from t where b = ? 1 0 0 1 1 1 1",
+		"select * from t where b in ( ... ) 1 0 0 2 2 1 1",
+		"update t set b = b + ? 1 1 0 0 4 1 1",
+	})
+}
+
 func exec(se tidb.Session, sql string, args ...interface{}) (ast.RecordSet, error) {
 	if len(args) == 0 {
 		rs, err := se.Execute(sql)
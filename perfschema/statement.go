@@ -23,6 +23,7 @@ import (
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -35,9 +36,7 @@ type statementInfo struct {
 }
 
 // StatementState provides temporary storage to a statement runtime statistics.
-// TODO:
-// 1. support statement digest.
-// 2. support prepared statement.
+// TODO: support prepared statement.
 type StatementState struct {
 	// connID means connection identifier
 	connID uint64
@@ -211,6 +210,11 @@ func (ps *perfSchema) EndStatement(state *StatementState) {
 }
 
 func state2Record(state *StatementState) []types.Datum {
+	digestText := parser.Normalize(state.sqlText)
+	digest := parser.DigestNormalized(digestText)
+	if len(digestText) > maxDigestTextLen {
+		digestText = digestText[:maxDigestTextLen]
+	}
 	return types.MakeDatums(
 		state.connID,             // THREAD_ID
 		state.info.key,           // EVENT_ID
@@ -222,8 +226,8 @@ func state2Record(state *StatementState) []types.Datum {
 		nil, // TIMER_WAIT
 		uint64(state.lockTime),             // LOCK_TIME
 		state.sqlText,                      // SQL_TEXT
-		nil,                                // DIGEST
-		nil,                                // DIGEST_TEXT
+		digest,                             // DIGEST
+		digestText,                         // DIGEST_TEXT
 		state.schemaName,                   // CURRENT_SCHEMA
 		nil,                                // OBJECT_TYPE
 		nil,                                // OBJECT_SCHEMA
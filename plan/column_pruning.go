@@ -133,6 +133,31 @@ func (p *Sort) PruneColumns(parentUsedCols []*expression.Column) {
 	p.SetSchema(p.children[0].Schema())
 }
 
+// PruneColumns implements LogicalPlan interface.
+func (p *LogicalWindow) PruneColumns(parentUsedCols []*expression.Column) {
+	child := p.children[0].(LogicalPlan)
+	var selfUsedCols []*expression.Column
+	for _, col := range parentUsedCols {
+		if child.Schema().Contains(col) {
+			selfUsedCols = append(selfUsedCols, col)
+		}
+	}
+	for _, desc := range p.WindowFuncDescs {
+		for _, arg := range desc.Args {
+			selfUsedCols = append(selfUsedCols, expression.ExtractColumns(arg)...)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		selfUsedCols = append(selfUsedCols, expression.ExtractColumns(item.Expr)...)
+	}
+	child.PruneColumns(selfUsedCols)
+	windowCols := p.schema.Columns[len(p.schema.Columns)-len(p.WindowFuncDescs):]
+	p.SetSchema(expression.MergeSchema(child.Schema(), expression.NewSchema(windowCols...)))
+}
+
 // PruneColumns implements LogicalPlan interface.
 func (p *Union) PruneColumns(parentUsedCols []*expression.Column) {
 	used := getUsedList(parentUsedCols, p.Schema())
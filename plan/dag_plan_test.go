@@ -55,6 +55,14 @@ func (s *testPlanSuite) TestDAGPlanBuilderSimpleCase(c *C) {
 			sql:  "select * from t t1 use index(c_d_e)",
 			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]], Table(t))",
 		},
+		{
+			sql:  "select /*+ USE_INDEX(t1, c_d_e) */ * from t t1",
+			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]], Table(t))",
+		},
+		{
+			sql:  "select /*+ IGNORE_INDEX(t, c_d_e) */ c from t where c = 1",
+			best: "TableReader(Table(t)->Sel([eq(test.t.c, 1)]))",
+		},
 		// Test ts + Sort vs. DoubleRead + filter.
 		{
 			sql:  "select a from t where a between 1 and 2 order by c",
@@ -300,6 +308,15 @@ func (s *testPlanSuite) TestDAGPlanBuilderJoin(c *C) {
 			sql:  "select /*+ TIDB_INLJ(t1) */ * from t t1 right outer join t t2 on t1.a = t2.b",
 			best: "IndexJoin{TableReader(Table(t))->TableReader(Table(t))}(t2.b,t1.a)->Projection",
 		},
+		// Test hash join hint.
+		{
+			sql:  "select /*+ HASH_JOIN(t1, t2) */ * from t t1 join t t2 on t1.a = t2.a",
+			best: "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(t1.a,t2.a)",
+		},
+		{
+			sql:  "select /*+ HASH_JOIN(t1) */ * from t t1 join t t2 on t1.a = t2.a order by t1.a",
+			best: "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(t1.a,t2.a)->Sort",
+		},
 	}
 	for _, tt := range tests {
 		comment := Commentf("for %s", tt.sql)
@@ -550,6 +567,31 @@ func (s *testPlanSuite) TestDAGPlanBuilderAgg(c *C) {
 			sql:  "select sum(to_base64(e)) from t where c = 1",
 			best: "IndexReader(Index(t.c_d_e)[[1,1]])->HashAgg",
 		},
+		// Test agg hints.
+		{
+			sql:  "select /*+ STREAM_AGG() */ sum(b) from t group by c",
+			best: "IndexLookUp(Index(t.c_d_e)[[<nil>,+inf]], Table(t))->StreamAgg",
+		},
+		{
+			sql:  "select /*+ STREAM_AGG() */ distinct c from t",
+			best: "IndexReader(Index(t.c_d_e)[[<nil>,+inf]])->StreamAgg",
+		},
+		{
+			sql:  "select /*+ HASH_AGG() */ sum(b) from t group by c",
+			best: "TableReader(Table(t)->HashAgg)->HashAgg",
+		},
+		{
+			sql:  "select /*+ STREAM_AGG() */ sum(b) from t group by c + 1",
+			best: "TableReader(Table(t)->HashAgg)->HashAgg",
+		},
+		{
+			sql:  "select sum(t1.b) from t t1 join t t2 on t1.b = t2.b",
+			best: "RightHashJoin{TableReader(Table(t)->HashAgg)->HashAgg->TableReader(Table(t))}(t1.b,t2.b)->HashAgg",
+		},
+		{
+			sql:  "select /*+ NO_AGG_PUSH_DOWN() */ sum(t1.b) from t t1 join t t2 on t1.b = t2.b",
+			best: "LeftHashJoin{TableReader(Table(t))->TableReader(Table(t))}(t1.b,t2.b)->HashAgg",
+		},
 	}
 	for _, tt := range tests {
 		comment := Commentf("for %s", tt.sql)
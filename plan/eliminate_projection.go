@@ -152,6 +152,10 @@ func (pe *projectionEliminater) eliminate(p LogicalPlan, replace map[string]*exp
 			newSchema.Append(p.Schema().Columns[len(p.Schema().Columns)-1])
 			p.SetSchema(newSchema)
 		}
+	case *LogicalWindow:
+		x := p.(*LogicalWindow)
+		windowCols := x.schema.Columns[x.schema.Len()-len(x.WindowFuncDescs):]
+		x.SetSchema(expression.MergeSchema(p.Children()[0].Schema(), expression.NewSchema(windowCols...)))
 	default:
 		for _, dst := range p.Schema().Columns {
 			resolveColumnAndReplace(dst, replace)
@@ -238,3 +242,17 @@ func (p *TopN) replaceExprColumns(replace map[string]*expression.Column) {
 		resolveExprAndReplace(byItem.Expr, replace)
 	}
 }
+
+func (p *LogicalWindow) replaceExprColumns(replace map[string]*expression.Column) {
+	for _, desc := range p.WindowFuncDescs {
+		for _, arg := range desc.Args {
+			resolveExprAndReplace(arg, replace)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		resolveExprAndReplace(item.Expr, replace)
+	}
+	for _, item := range p.OrderBy {
+		resolveExprAndReplace(item.Expr, replace)
+	}
+}
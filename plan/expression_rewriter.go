@@ -55,6 +55,59 @@ func evalAstExpr(expr ast.ExprNode, ctx context.Context) (types.Datum, error) {
 	return newExpr.Eval(nil)
 }
 
+// rewriteAstExpr rewrites ast expression, whose columns refer to the columns of tblInfo, to expression.Expression.
+func rewriteAstExpr(ctx context.Context, expr ast.ExprNode, tblInfo *model.TableInfo) (expression.Expression, error) {
+	resolver := &tableColumnResolver{tblInfo: tblInfo}
+	expr.Accept(resolver)
+	if resolver.err != nil {
+		return nil, errors.Trace(resolver.err)
+	}
+	err := expression.InferType(ctx.GetSessionVars().StmtCtx, expr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	b := &planBuilder{
+		ctx:       ctx,
+		allocator: new(idAllocator),
+		colMapper: make(map[*ast.ColumnNameExpr]int),
+	}
+	schema := expression.TableInfo2Schema(tblInfo)
+	p := TableDual{}.init(b.allocator, ctx)
+	p.SetSchema(schema)
+	newExpr, _, err := b.rewrite(expr, p, nil, true)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	newExpr.ResolveIndices(schema)
+	return newExpr, nil
+}
+
+// tableColumnResolver resolves the column names in an expression to the columns of a single table.
+type tableColumnResolver struct {
+	tblInfo *model.TableInfo
+	err     error
+}
+
+// Enter implements ast.Visitor interface.
+func (r *tableColumnResolver) Enter(inNode ast.Node) (ast.Node, bool) {
+	return inNode, false
+}
+
+// Leave implements ast.Visitor interface.
+func (r *tableColumnResolver) Leave(inNode ast.Node) (ast.Node, bool) {
+	if v, ok := inNode.(*ast.ColumnNameExpr); ok {
+		for _, col := range r.tblInfo.Columns {
+			if col.Name.L == v.Name.Name.L {
+				v.Refer = &ast.ResultField{Column: col, Table: r.tblInfo}
+				return inNode, true
+			}
+		}
+		r.err = ErrUnknownColumn.GenByArgs(v.Name.Name.O, "partition function")
+		return inNode, false
+	}
+	return inNode, true
+}
+
 // rewrite function rewrites ast expr to expression.Expression.
 // aggMapper maps ast.AggregateFuncExpr to the columns offset in p's output schema.
 // asScalar means whether this expression must be treated as a scalar expression.
@@ -233,6 +286,14 @@ func (er *expressionRewriter) Enter(inNode ast.Node) (ast.Node, bool) {
 			er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
 			return inNode, true
 		}
+	case *ast.WindowFuncExpr:
+		index, ok := er.b.windowMapper[v]
+		if !ok {
+			er.err = ErrWindowInvalidWindowFuncUse.GenByArgs(v.F)
+			return inNode, true
+		}
+		er.ctxStack = append(er.ctxStack, er.schema.Columns[index])
+		return inNode, true
 	case *ast.CompareSubqueryExpr:
 		return er.handleCompareSubquery(v)
 	case *ast.ExistsSubqueryExpr:
@@ -673,7 +734,7 @@ func (er *expressionRewriter) Leave(originInNode ast.Node) (retNode ast.Node, ok
 	}
 	switch v := inNode.(type) {
 	case *ast.AggregateFuncExpr, *ast.ColumnNameExpr, *ast.ParenthesesExpr, *ast.WhenClause,
-		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr:
+		*ast.SubqueryExpr, *ast.ExistsSubqueryExpr, *ast.CompareSubqueryExpr, *ast.ValuesExpr, *ast.WindowFuncExpr:
 	case *ast.ValueExpr:
 		tp := &types.FieldType{}
 		types.DefaultTypeForValue(v.GetValue(), tp)
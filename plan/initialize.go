@@ -85,6 +85,12 @@ const (
 	TypeTableReader = "TableReader"
 	// TypeIndexReader is the type of IndexReader.
 	TypeIndexReader = "IndexReader"
+	// TypeWindow is the type of Window.
+	TypeWindow = "Window"
+	// TypeCTE is the type of CTE.
+	TypeCTE = "CTE"
+	// TypeCTETable is the type of CTETable.
+	TypeCTETable = "CTETable"
 )
 
 func (p LogicalAggregation) init(allocator *idAllocator, ctx context.Context) *LogicalAggregation {
@@ -93,6 +99,12 @@ func (p LogicalAggregation) init(allocator *idAllocator, ctx context.Context) *L
 	return &p
 }
 
+func (p LogicalWindow) init(allocator *idAllocator, ctx context.Context) *LogicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	return &p
+}
+
 func (p LogicalJoin) init(allocator *idAllocator, ctx context.Context) *LogicalJoin {
 	p.basePlan = newBasePlan(TypeJoin, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -160,6 +172,20 @@ func (p TableDual) init(allocator *idAllocator, ctx context.Context) *TableDual
 	return &p
 }
 
+func (p CTE) init(allocator *idAllocator, ctx context.Context) *CTE {
+	p.basePlan = newBasePlan(TypeCTE, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
+func (p CTETable) init(allocator *idAllocator, ctx context.Context) *CTETable {
+	p.basePlan = newBasePlan(TypeCTETable, allocator, ctx, &p)
+	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p Exists) init(allocator *idAllocator, ctx context.Context) *Exists {
 	p.basePlan = newBasePlan(TypeExists, allocator, ctx, &p)
 	p.baseLogicalPlan = newBaseLogicalPlan(p.basePlan)
@@ -259,6 +285,12 @@ func (p PhysicalAggregation) init(allocator *idAllocator, ctx context.Context) *
 	return &p
 }
 
+func (p PhysicalWindow) init(allocator *idAllocator, ctx context.Context) *PhysicalWindow {
+	p.basePlan = newBasePlan(TypeWindow, allocator, ctx, &p)
+	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
+	return &p
+}
+
 func (p PhysicalApply) init(allocator *idAllocator, ctx context.Context) *PhysicalApply {
 	p.basePlan = newBasePlan(TypeApply, allocator, ctx, &p)
 	p.basePhysicalPlan = newBasePhysicalPlan(p.basePlan)
@@ -36,6 +36,22 @@ const (
 	TiDBMergeJoin = "tidb_smj"
 	// TiDBIndexNestedLoopJoin is hint enforce index nested loop join.
 	TiDBIndexNestedLoopJoin = "tidb_inlj"
+	// HintHashJoin is hint enforce hash join.
+	HintHashJoin = "hash_join"
+	// HintHashAgg is hint enforce hash aggregation.
+	HintHashAgg = "hash_agg"
+	// HintStreamAgg is hint enforce stream aggregation.
+	HintStreamAgg = "stream_agg"
+	// HintUseIndex is hint enforce using the indexes of a table.
+	HintUseIndex = "use_index"
+	// HintIgnoreIndex is hint enforce ignoring the indexes of a table.
+	HintIgnoreIndex = "ignore_index"
+	// HintReadFromStorage is hint enforce reading the tables from a storage, only TiKV is supported.
+	HintReadFromStorage = "read_from_storage"
+	// HintNoAggPushDown is hint disable pushing down the aggregations across the joins and unions.
+	HintNoAggPushDown = "no_agg_push_down"
+	// HintMaxExecutionTime is hint set the timeout in milliseconds of the statement.
+	HintMaxExecutionTime = "max_execution_time"
 )
 
 type idAllocator struct {
@@ -56,11 +72,31 @@ func (p *LogicalAggregation) collectGroupByColumns() {
 	}
 }
 
+// preferAggType returns the aggregation type preferred by the hints for the aggregation grouped by gbyItems.
+func (b *planBuilder) preferAggType(gbyItems []expression.Expression) uint {
+	hintInfo := b.TableHints()
+	if hintInfo == nil {
+		return 0
+	}
+	if hintInfo.preferAggType == preferStreamAgg {
+		// The stream aggregation requires its child to be sorted by the group by items.
+		for _, item := range gbyItems {
+			if _, ok := item.(*expression.Column); !ok {
+				b.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrInapplicableHint.GenByArgs(HintStreamAgg,
+					"the group by items aren't all columns"))
+				return 0
+			}
+		}
+	}
+	return hintInfo.preferAggType
+}
+
 func (b *planBuilder) buildAggregation(p LogicalPlan, aggFuncList []*ast.AggregateFuncExpr, gbyItems []expression.Expression) (LogicalPlan, map[int]int) {
 	b.optFlag = b.optFlag | flagBuildKeyInfo
 	b.optFlag = b.optFlag | flagAggregationOptimize
 
 	agg := LogicalAggregation{AggFuncs: make([]expression.AggregationFunction, 0, len(aggFuncList))}.init(b.allocator, b.ctx)
+	agg.preferAggType = b.preferAggType(gbyItems)
 	schema := expression.NewSchema(make([]*expression.Column, 0, len(aggFuncList)+p.Schema().Len())...)
 	// aggIdxMap maps the old index to new index after applying common aggregation functions elimination.
 	aggIndexMap := make(map[int]int)
@@ -130,6 +166,7 @@ func (b *planBuilder) buildResultSetNode(node ast.ResultSetNode) LogicalPlan {
 		}
 		if v, ok := p.(*DataSource); ok {
 			v.TableAsName = &x.AsName
+			b.applyTableHints(v)
 		}
 		if x.AsName.L != "" {
 			for _, col := range p.Schema().Columns {
@@ -245,13 +282,15 @@ func (b *planBuilder) buildJoin(join *ast.Join) LogicalPlan {
 
 	if b.TableHints() != nil {
 		joinPlan.preferMergeJoin = b.TableHints().ifPreferMergeJoin(leftAlias, rightAlias)
+		joinPlan.preferHashJoin = b.TableHints().ifPreferHashJoin(leftAlias, rightAlias)
 		if b.TableHints().ifPreferINLJ(leftAlias) {
 			joinPlan.preferINLJ = joinPlan.preferINLJ | preferLeftAsOuter
 		}
 		if b.TableHints().ifPreferINLJ(rightAlias) {
 			joinPlan.preferINLJ = joinPlan.preferINLJ | preferRightAsOuter
 		}
-		if joinPlan.preferMergeJoin && joinPlan.preferINLJ > 0 {
+		if (joinPlan.preferMergeJoin && joinPlan.preferINLJ > 0) ||
+			(joinPlan.preferHashJoin && (joinPlan.preferMergeJoin || joinPlan.preferINLJ > 0)) {
 			b.err = errors.New("Optimizer Hints is conflict")
 			return nil
 		}
@@ -455,6 +494,7 @@ func (b *planBuilder) buildDistinct(child LogicalPlan, length int) LogicalPlan {
 		AggFuncs:     make([]expression.AggregationFunction, 0, child.Schema().Len()),
 		GroupByItems: expression.Column2Exprs(child.Schema().Clone().Columns[:length]),
 	}.init(b.allocator, b.ctx)
+	agg.preferAggType = b.preferAggType(agg.GroupByItems)
 	agg.collectGroupByColumns()
 	for _, col := range child.Schema().Columns {
 		agg.AggFuncs = append(agg.AggFuncs, expression.NewAggFunction(ast.AggFuncFirstRow, []expression.Expression{col}, false))
@@ -465,10 +505,18 @@ func (b *planBuilder) buildDistinct(child LogicalPlan, length int) LogicalPlan {
 }
 
 func (b *planBuilder) buildUnion(union *ast.UnionStmt) LogicalPlan {
+	if union.With != nil {
+		oldCTEs := b.ctes
+		b.buildWith(union.With)
+		defer func() { b.ctes = oldCTEs }()
+	}
 	u := Union{}.init(b.allocator, b.ctx)
 	u.children = make([]Plan, len(union.SelectList.Selects))
 	for i, sel := range union.SelectList.Selects {
 		u.children[i] = b.buildSelect(sel)
+		if b.err != nil {
+			return nil
+		}
 	}
 	firstSchema := u.children[0].Schema().Clone()
 	for i, sel := range u.children {
@@ -845,6 +893,354 @@ func (b *planBuilder) extractAggFuncs(fields []*ast.SelectField) ([]*ast.Aggrega
 	return aggList, totalAggMapper
 }
 
+// resolveWindowFunction resolves the columns and aggregate functions used by the window functions in the select fields.
+// Like resolveHavingAndOrderBy, it appends the columns and aggregate functions to the select fields as auxiliary fields,
+// so that the window plans can be built upon the projection of the select fields.
+func (b *planBuilder) resolveWindowFunction(sel *ast.SelectStmt, p LogicalPlan) map[*ast.AggregateFuncExpr]int {
+	extractor := &havingAndOrderbyExprResolver{
+		p:            p,
+		selectFields: sel.Fields.Fields,
+		aggMapper:    make(map[*ast.AggregateFuncExpr]int),
+		colMapper:    b.colMapper,
+		outerSchemas: b.outerSchemas,
+		orderBy:      true,
+	}
+	for _, field := range sel.Fields.Fields {
+		if field.Auxiliary || !ast.HasWindowFlag(field.Expr) {
+			continue
+		}
+		extractor.inExpr = false
+		n, ok := field.Expr.Accept(extractor)
+		if !ok {
+			b.err = errors.Trace(extractor.err)
+			return nil
+		}
+		field.Expr = n.(ast.ExprNode)
+	}
+	sel.Fields.Fields = extractor.selectFields
+	return extractor.aggMapper
+}
+
+// windowPlaceholderFields replaces the select fields that contain window functions with NULL, because the window
+// functions are evaluated by the projection above the window plans rather than the projection of the select fields.
+func windowPlaceholderFields(fields []*ast.SelectField) []*ast.SelectField {
+	result := make([]*ast.SelectField, 0, len(fields))
+	for _, field := range fields {
+		if !field.Auxiliary && ast.HasWindowFlag(field.Expr) {
+			field = &ast.SelectField{Expr: ast.NewValueExpr(nil), AsName: field.AsName}
+		}
+		result = append(result, field)
+	}
+	return result
+}
+
+// windowFuncExtractor collects the window functions of an expression.
+type windowFuncExtractor struct {
+	windowFuncs []*ast.WindowFuncExpr
+}
+
+// Enter implements Visitor interface.
+func (e *windowFuncExtractor) Enter(n ast.Node) (ast.Node, bool) {
+	switch n.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return n, true
+	}
+	return n, false
+}
+
+// Leave implements Visitor interface.
+func (e *windowFuncExtractor) Leave(n ast.Node) (ast.Node, bool) {
+	if v, ok := n.(*ast.WindowFuncExpr); ok {
+		e.windowFuncs = append(e.windowFuncs, v)
+	}
+	return n, true
+}
+
+// windowGroup is a group of window functions that share the same window specification.
+type windowGroup struct {
+	key         string
+	partitionBy []*ByItems
+	orderBy     []*ByItems
+	frame       *WindowFrame
+	funcs       []*ast.WindowFuncExpr
+}
+
+// buildWindowFunctions builds the window plans for the window functions in the select fields. The window functions
+// with the same window specification are computed by one window plan, whose child is a sort plan ordered by the
+// partition by items and the order by items. At last, a projection evaluates the select fields that contain window
+// functions, while the other select fields, which have been evaluated by the child projection, are passed through.
+func (b *planBuilder) buildWindowFunctions(p LogicalPlan, fields []*ast.SelectField, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	extractor := &windowFuncExtractor{}
+	for _, field := range fields {
+		if !field.Auxiliary && ast.HasWindowFlag(field.Expr) {
+			field.Expr.Accept(extractor)
+		}
+	}
+	var groups []*windowGroup
+	for _, windowFunc := range extractor.windowFuncs {
+		group := b.buildWindowSpec(p, windowFunc, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+		found := false
+		for _, g := range groups {
+			if g.key == group.key {
+				g.funcs = append(g.funcs, windowFunc)
+				found = true
+				break
+			}
+		}
+		if !found {
+			groups = append(groups, group)
+		}
+	}
+	if b.windowMapper == nil {
+		b.windowMapper = make(map[*ast.WindowFuncExpr]int)
+	}
+	projSchema := p.Schema()
+	for _, group := range groups {
+		p = b.buildWindow(p, group, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+	}
+
+	proj := Projection{Exprs: make([]expression.Expression, 0, len(fields))}.init(b.allocator, b.ctx)
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(fields))...)
+	for i, field := range fields {
+		if field.Auxiliary || !ast.HasWindowFlag(field.Expr) {
+			proj.Exprs = append(proj.Exprs, projSchema.Columns[i].Clone())
+			col := projSchema.Columns[i].Clone().(*expression.Column)
+			col.FromID = proj.id
+			schema.Append(col)
+			continue
+		}
+		newExpr, np, err := b.rewrite(field.Expr, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		p = np
+		proj.Exprs = append(proj.Exprs, newExpr)
+		colName := field.AsName
+		if colName.L == "" {
+			colName = model.NewCIStr(parser.SpecFieldPattern.ReplaceAllStringFunc(field.Text(), parser.TrimComment))
+		}
+		schema.Append(&expression.Column{
+			FromID:   proj.id,
+			ColName:  colName,
+			Position: schema.Len() + 1,
+			RetType:  newExpr.GetType(),
+		})
+	}
+	proj.SetSchema(schema)
+	addChild(proj, p)
+	return proj
+}
+
+// buildWindowSpec rewrites the window specification of a window function, and checks its frame.
+func (b *planBuilder) buildWindowSpec(p LogicalPlan, windowFunc *ast.WindowFuncExpr, aggMapper map[*ast.AggregateFuncExpr]int) *windowGroup {
+	if windowFunc.Distinct {
+		b.err = ErrNotSupportedYet.GenByArgs("<window function>(DISTINCT ..)")
+		return nil
+	}
+	group := &windowGroup{funcs: []*ast.WindowFuncExpr{windowFunc}}
+	spec := windowFunc.Spec
+	if spec.PartitionBy != nil {
+		group.partitionBy = b.buildWindowByItems(p, spec.PartitionBy.Items, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+	}
+	if spec.OrderBy != nil {
+		group.orderBy = b.buildWindowByItems(p, spec.OrderBy.Items, aggMapper)
+		if b.err != nil {
+			return nil
+		}
+	}
+	group.frame = b.buildWindowFrame(spec.Frame, group.orderBy)
+	if b.err != nil {
+		return nil
+	}
+	group.key = fmt.Sprintf("%s|%s|%s", group.partitionBy, group.orderBy, group.frame)
+	return group
+}
+
+func (b *planBuilder) buildWindowByItems(p LogicalPlan, byItems []*ast.ByItem, aggMapper map[*ast.AggregateFuncExpr]int) []*ByItems {
+	items := make([]*ByItems, 0, len(byItems))
+	for _, item := range byItems {
+		expr, _, err := b.rewrite(item.Expr, p, aggMapper, true)
+		if err != nil {
+			b.err = errors.Trace(err)
+			return nil
+		}
+		items = append(items, &ByItems{Expr: expr, Desc: item.Desc})
+	}
+	return items
+}
+
+// buildWindowFrame converts the frame clause to a window frame. When the frame clause is omitted, the frame is the
+// whole partition if there is no ORDER BY, otherwise it is from the start of the partition to the current row and
+// its peers.
+func (b *planBuilder) buildWindowFrame(frame *ast.FrameClause, orderBy []*ByItems) *WindowFrame {
+	if frame == nil {
+		if len(orderBy) == 0 {
+			return &WindowFrame{
+				Type:  ast.Rows,
+				Start: &FrameBound{Type: ast.Preceding, UnBounded: true},
+				End:   &FrameBound{Type: ast.Following, UnBounded: true},
+			}
+		}
+		return &WindowFrame{
+			Type:  ast.Ranges,
+			Start: &FrameBound{Type: ast.Preceding, UnBounded: true},
+			End:   &FrameBound{Type: ast.CurrentRow},
+		}
+	}
+	start, end := frame.Extent.Start, frame.Extent.End
+	if start.Type == ast.Following && start.UnBounded {
+		b.err = ErrWindowFrameStartIllegal.GenByArgs("<unnamed window>")
+		return nil
+	}
+	if end.Type == ast.Preceding && end.UnBounded {
+		b.err = ErrWindowFrameEndIllegal.GenByArgs("<unnamed window>")
+		return nil
+	}
+	result := &WindowFrame{Type: frame.Type}
+	result.Start = b.buildFrameBound(frame.Type, &start, orderBy)
+	if b.err != nil {
+		return nil
+	}
+	result.End = b.buildFrameBound(frame.Type, &end, orderBy)
+	if b.err != nil {
+		return nil
+	}
+	// Like MySQL, the frame can't start after its end by the types of the bounds, e.g. it can't start N FOLLOWING
+	// and end at the current row, but the frames like 1 PRECEDING AND 2 PRECEDING are allowed and empty.
+	if boundOrder(start.Type) > boundOrder(end.Type) {
+		b.err = ErrWindowFrameIllegal.GenByArgs("<unnamed window>")
+		return nil
+	}
+	return result
+}
+
+// boundOrder returns the order of the bound types from the start of a partition to its end.
+func boundOrder(tp ast.BoundType) int {
+	switch tp {
+	case ast.Preceding:
+		return 0
+	case ast.CurrentRow:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (b *planBuilder) buildFrameBound(tp ast.FrameType, bound *ast.FrameBound, orderBy []*ByItems) *FrameBound {
+	result := &FrameBound{Type: bound.Type, UnBounded: bound.UnBounded}
+	if bound.Type == ast.CurrentRow || bound.UnBounded {
+		return result
+	}
+	num := *bound.Expr.GetDatum()
+	switch num.Kind() {
+	case types.KindInt64:
+		if num.GetInt64() < 0 {
+			b.err = ErrWindowFrameIllegal.GenByArgs("<unnamed window>")
+			return nil
+		}
+	case types.KindUint64:
+	case types.KindFloat64, types.KindMysqlDecimal:
+		if tp == ast.Rows {
+			b.err = ErrWindowFrameIllegal.GenByArgs("<unnamed window>")
+			return nil
+		}
+	default:
+		b.err = ErrWindowFrameIllegal.GenByArgs("<unnamed window>")
+		return nil
+	}
+	if tp == ast.Ranges {
+		// A RANGE frame with an offset compares the values of the only one ORDER BY item.
+		if len(orderBy) != 1 {
+			b.err = ErrWindowRangeFrameOrderType.GenByArgs("<unnamed window>")
+			return nil
+		}
+		switch orderBy[0].Expr.GetType().ToClass() {
+		case types.ClassInt, types.ClassDecimal, types.ClassReal:
+		default:
+			b.err = ErrWindowRangeFrameOrderType.GenByArgs("<unnamed window>")
+			return nil
+		}
+	}
+	result.Num = num
+	return result
+}
+
+// buildWindow builds a window plan for a group of window functions, and a sort plan as its child.
+func (b *planBuilder) buildWindow(p LogicalPlan, group *windowGroup, aggMapper map[*ast.AggregateFuncExpr]int) LogicalPlan {
+	descs := make([]*WindowFuncDesc, 0, len(group.funcs))
+	for _, windowFunc := range group.funcs {
+		args := make([]expression.Expression, 0, len(windowFunc.Args))
+		for _, arg := range windowFunc.Args {
+			newArg, np, err := b.rewrite(arg, p, aggMapper, true)
+			if err != nil {
+				b.err = errors.Trace(err)
+				return nil
+			}
+			p = np
+			args = append(args, newArg)
+		}
+		descs = append(descs, newWindowFuncDesc(windowFunc.F, args))
+	}
+	byItems := make([]*ByItems, 0, len(group.partitionBy)+len(group.orderBy))
+	byItems = append(byItems, group.partitionBy...)
+	byItems = append(byItems, group.orderBy...)
+	if len(byItems) > 0 {
+		sort := Sort{ByItems: byItems}.init(b.allocator, b.ctx)
+		addChild(sort, p)
+		sort.SetSchema(p.Schema().Clone())
+		p = sort
+	}
+	window := LogicalWindow{
+		WindowFuncDescs: descs,
+		PartitionBy:     group.partitionBy,
+		OrderBy:         group.orderBy,
+		Frame:           group.frame,
+	}.init(b.allocator, b.ctx)
+	schema := p.Schema().Clone()
+	for i, desc := range descs {
+		b.windowMapper[group.funcs[i]] = schema.Len()
+		schema.Append(&expression.Column{
+			FromID:      window.id,
+			ColName:     model.NewCIStr(fmt.Sprintf("%s_col_%d", window.id, i)),
+			Position:    schema.Len() + 1,
+			IsAggOrSubq: true,
+			RetType:     desc.RetType,
+		})
+	}
+	window.SetSchema(schema)
+	addChild(window, p)
+	return window
+}
+
+// newWindowFuncDesc creates a window function description and infers its return type.
+func newWindowFuncDesc(name string, args []expression.Expression) *WindowFuncDesc {
+	desc := &WindowFuncDesc{Name: name, Args: args}
+	switch name {
+	case ast.WindowFuncRowNumber, ast.WindowFuncRank, ast.WindowFuncDenseRank:
+		desc.RetType = types.NewFieldType(mysql.TypeLonglong)
+		desc.RetType.Flen = mysql.MaxIntWidth
+		desc.RetType.Flag |= mysql.NotNullFlag
+	case ast.WindowFuncLead, ast.WindowFuncLag, ast.WindowFuncFirstValue, ast.WindowFuncLastValue:
+		tp := *args[0].GetType()
+		// The result is NULL if the row is out of the partition or the frame is empty.
+		tp.Flag &^= mysql.NotNullFlag
+		desc.RetType = &tp
+	default:
+		desc.RetType = expression.NewAggFunction(name, args, false).GetType()
+	}
+	return desc
+}
+
 // gbyResolver resolves group by items from select fields.
 type gbyResolver struct {
 	fields []*ast.SelectField
@@ -946,22 +1342,74 @@ func (b *planBuilder) unfoldWildStar(p LogicalPlan, selectFields []*ast.SelectFi
 	return
 }
 
-func (b *planBuilder) pushTableHints(hints []*ast.TableOptimizerHint) bool {
-	var sortMergeTables, INLJTables []model.CIStr
+func (b *planBuilder) pushTableHints(hints []*ast.TableOptimizerHint, isTopSelect bool) bool {
+	var sortMergeTables, INLJTables, hashJoinTables, tikvTables []model.CIStr
+	var indexHintList []indexHintInfo
+	var preferAggType uint
+	sc := b.ctx.GetSessionVars().StmtCtx
 	for _, hint := range hints {
+		if hint.InvalidArgs {
+			sc.AppendWarning(ErrInapplicableHint.GenByArgs(hint.HintName.O, "the arguments can't be recognized"))
+			continue
+		}
 		switch hint.HintName.L {
 		case TiDBMergeJoin:
 			sortMergeTables = append(sortMergeTables, hint.Tables...)
 		case TiDBIndexNestedLoopJoin:
 			INLJTables = append(INLJTables, hint.Tables...)
+		case HintHashJoin:
+			hashJoinTables = append(hashJoinTables, hint.Tables...)
+		case HintHashAgg:
+			preferAggType |= preferHashAgg
+		case HintStreamAgg:
+			preferAggType |= preferStreamAgg
+		case HintUseIndex, HintIgnoreIndex:
+			if len(hint.Tables) == 0 {
+				sc.AppendWarning(ErrInapplicableHint.GenByArgs(hint.HintName.O, "the table isn't specified"))
+				continue
+			}
+			hintType := ast.HintUse
+			if hint.HintName.L == HintIgnoreIndex {
+				hintType = ast.HintIgnore
+			}
+			indexHintList = append(indexHintList, indexHintInfo{
+				tblName:   hint.Tables[0],
+				indexHint: &ast.IndexHint{IndexNames: hint.Indexes, HintType: hintType, HintScope: ast.HintForScan},
+			})
+		case HintReadFromStorage:
+			if hint.StoreType.L != "tikv" {
+				sc.AppendWarning(ErrInapplicableHint.GenByArgs(hint.HintName.O,
+					fmt.Sprintf("storage %s is not supported", hint.StoreType.O)))
+				continue
+			}
+			tikvTables = append(tikvTables, hint.Tables...)
+		case HintNoAggPushDown, HintMaxExecutionTime:
+			if !isTopSelect {
+				sc.AppendWarning(ErrInapplicableHint.GenByArgs(hint.HintName.O, "it's only supported in the top level SELECT"))
+				continue
+			}
+			if hint.HintName.L == HintNoAggPushDown {
+				b.noAggPushDown = true
+			} else {
+				sc.MaxExecutionTime = hint.MaxExecutionTime
+			}
 		default:
-			// ignore hints that not implemented
+			sc.AppendWarning(ErrInapplicableHint.GenByArgs(hint.HintName.O, "the hint is unknown"))
 		}
 	}
-	if len(sortMergeTables) != 0 || len(INLJTables) != 0 {
+	if preferAggType == preferHashAgg|preferStreamAgg {
+		sc.AppendWarning(ErrWarnConflictingHint.GenByArgs(HintStreamAgg))
+		preferAggType = preferHashAgg
+	}
+	if len(sortMergeTables) != 0 || len(INLJTables) != 0 || len(hashJoinTables) != 0 || len(tikvTables) != 0 ||
+		len(indexHintList) != 0 || preferAggType != 0 {
 		b.tableHintInfo = append(b.tableHintInfo, tableHintInfo{
-			sortMergeJoinTables:       sortMergeTables,
-			indexNestedLoopJoinTables: INLJTables,
+			sortMergeJoinTables:       tableNames2HintTableInfo(sortMergeTables),
+			indexNestedLoopJoinTables: tableNames2HintTableInfo(INLJTables),
+			hashJoinTables:            tableNames2HintTableInfo(hashJoinTables),
+			tikvTables:                tableNames2HintTableInfo(tikvTables),
+			indexHintList:             indexHintList,
+			preferAggType:             preferAggType,
 		})
 		return true
 	}
@@ -969,9 +1417,43 @@ func (b *planBuilder) pushTableHints(hints []*ast.TableOptimizerHint) bool {
 }
 
 func (b *planBuilder) popTableHints() {
+	hintInfo := b.tableHintInfo[len(b.tableHintInfo)-1]
+	for _, warning := range hintInfo.unmatchedTableHintWarnings() {
+		b.ctx.GetSessionVars().StmtCtx.AppendWarning(warning)
+	}
 	b.tableHintInfo = b.tableHintInfo[:len(b.tableHintInfo)-1]
 }
 
+// applyTableHints applies the USE_INDEX, IGNORE_INDEX and READ_FROM_STORAGE hints of the table to ds.
+func (b *planBuilder) applyTableHints(ds *DataSource) {
+	hintInfo := b.TableHints()
+	if hintInfo == nil {
+		return
+	}
+	alias := extractTableAlias(ds)
+	matchTableName([]*model.CIStr{alias}, hintInfo.tikvTables)
+	for i, hint := range hintInfo.indexHintList {
+		if hint.tblName.L != alias.L {
+			continue
+		}
+		hintInfo.indexHintList[i].matched = true
+		for _, idxName := range hint.indexHint.IndexNames {
+			if findIndexByName(ds.tableInfo.Indices, idxName) == nil {
+				hintName := HintUseIndex
+				if hint.indexHint.HintType == ast.HintIgnore {
+					hintName = HintIgnoreIndex
+				}
+				b.ctx.GetSessionVars().StmtCtx.AppendWarning(ErrInapplicableHint.GenByArgs(hintName,
+					fmt.Sprintf("index %s doesn't exist in table %s", idxName.O, alias.O)))
+			}
+		}
+		// The index hints of the table name are shared by the executions of a prepared statement, so they're copied.
+		indexHints := make([]*ast.IndexHint, 0, len(ds.indexHints)+1)
+		indexHints = append(indexHints, ds.indexHints...)
+		ds.indexHints = append(indexHints, hint.indexHint)
+	}
+}
+
 // TableHints returns the *tableHintInfo of PlanBuilder.
 func (b *planBuilder) TableHints() *tableHintInfo {
 	if b.tableHintInfo == nil || len(b.tableHintInfo) == 0 {
@@ -983,16 +1465,23 @@ func (b *planBuilder) TableHints() *tableHintInfo {
 func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 	if sel.TableHints != nil {
 		// table hints without query block support only visible in current SELECT
-		if b.pushTableHints(sel.TableHints) {
+		if b.pushTableHints(sel.TableHints, sel == b.topSelect) {
 			defer b.popTableHints()
 		}
 	}
+	if sel.With != nil {
+		oldCTEs := b.ctes
+		b.buildWith(sel.With)
+		defer func() { b.ctes = oldCTEs }()
+	}
 
 	hasAgg := b.detectSelectAgg(sel)
+	hasWindowFunc := b.detectSelectWindow(sel)
 	var (
 		p                             LogicalPlan
 		aggFuncs                      []*ast.AggregateFuncExpr
 		havingMap, orderMap, totalMap map[*ast.AggregateFuncExpr]int
+		windowAggMap                  map[*ast.AggregateFuncExpr]int
 		gbyCols                       []expression.Expression
 	)
 	if sel.From != nil {
@@ -1018,6 +1507,12 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 	// because when the query is "select a+1 as b from t having sum(b) < 0", we must replace sum(b) to sum(a+1),
 	// which only can be done before building projection and extracting Agg functions.
 	havingMap, orderMap = b.resolveHavingAndOrderBy(sel, p)
+	if hasWindowFunc {
+		windowAggMap = b.resolveWindowFunction(sel, p)
+		if b.err != nil {
+			return nil
+		}
+	}
 	if sel.Where != nil {
 		p = b.buildSelection(p, sel.Where, nil)
 		if b.err != nil {
@@ -1042,7 +1537,7 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 		}
 	}
 	var oldLen int
-	p, oldLen = b.buildProjection(p, sel.Fields.Fields, totalMap)
+	p, oldLen = b.buildProjection(p, windowPlaceholderFields(sel.Fields.Fields), totalMap)
 	if b.err != nil {
 		return nil
 	}
@@ -1052,6 +1547,13 @@ func (b *planBuilder) buildSelect(sel *ast.SelectStmt) LogicalPlan {
 			return nil
 		}
 	}
+	// Window functions are computed after HAVING and before DISTINCT, ORDER BY and LIMIT.
+	if hasWindowFunc {
+		p = b.buildWindowFunctions(p, sel.Fields.Fields, windowAggMap)
+		if b.err != nil {
+			return nil
+		}
+	}
 	if sel.Distinct {
 		p = b.buildDistinct(p, oldLen)
 		if b.err != nil {
@@ -1092,6 +1594,15 @@ func (b *planBuilder) buildTableDual() LogicalPlan {
 }
 
 func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
+	if tn.Schema.L == "" {
+		// Only the name which refers to a common table expression is left without schema by the resolver.
+		if info := b.findCTE(tn.Name); info != nil {
+			return b.buildCTE(tn, info)
+		}
+	}
+	if tn.TableInfo != nil && tn.TableInfo.IsView() {
+		return b.buildDataSourceFromView(tn)
+	}
 	handle := sessionctx.GetDomain(b.ctx).StatsHandle()
 	var statisticTable *statistics.Table
 	if handle == nil {
@@ -1113,13 +1624,17 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 		return nil
 	}
 	tableInfo := tbl.Meta()
+	if tableInfo.Partition != nil {
+		b.optFlag = b.optFlag | flagPartitionProcessor
+	}
 
 	p := DataSource{
-		indexHints:     tn.IndexHints,
-		tableInfo:      tableInfo,
-		statisticTable: statisticTable,
-		DBName:         schemaName,
-		Columns:        make([]*model.ColumnInfo, 0, len(tableInfo.Columns)),
+		indexHints:      tn.IndexHints,
+		tableInfo:       tableInfo,
+		physicalTableID: tableInfo.ID,
+		statisticTable:  statisticTable,
+		DBName:          schemaName,
+		Columns:         make([]*model.ColumnInfo, 0, len(tableInfo.Columns)),
 	}.init(b.allocator, b.ctx)
 
 	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, schemaName.L, tableInfo.Name.L, "")
@@ -1146,6 +1661,328 @@ func (b *planBuilder) buildDataSource(tn *ast.TableName) LogicalPlan {
 	return p
 }
 
+// cteInfo stores a common table expression defined in the WITH clause during building the plan.
+type cteInfo struct {
+	def         *ast.CommonTableExpression
+	isRecursive bool
+	// scope is the common table expressions visible in the definition.
+	scope []*cteInfo
+	// definition is built when the common table expression is referenced for the first time.
+	definition *CTEDefinition
+	// seedSchema is the schema of the seed part, which decides the output columns.
+	seedSchema *expression.Schema
+	// building is true when the recursive part is being built, the references at that time are recursive references.
+	building bool
+}
+
+// buildWith makes the common table expressions in the WITH clause visible to the query.
+// A non-recursive common table expression can only refer to the ones defined before it,
+// while a recursive one can also refer to itself.
+func (b *planBuilder) buildWith(with *ast.WithClause) {
+	for _, cte := range with.CTEs {
+		info := &cteInfo{def: cte, isRecursive: with.IsRecursive}
+		if info.isRecursive {
+			b.ctes = append(b.ctes, info)
+		}
+		// Limit the capacity so the appending outside of the scope will not overwrite it.
+		info.scope = b.ctes[:len(b.ctes):len(b.ctes)]
+		if !info.isRecursive {
+			b.ctes = append(b.ctes, info)
+		}
+	}
+}
+
+func (b *planBuilder) findCTE(name model.CIStr) *cteInfo {
+	for i := len(b.ctes) - 1; i >= 0; i-- {
+		if b.ctes[i].def.Name.L == name.L {
+			return b.ctes[i]
+		}
+	}
+	return nil
+}
+
+// buildCTE builds the plan which reads the result of the common table expression.
+func (b *planBuilder) buildCTE(tn *ast.TableName, info *cteInfo) LogicalPlan {
+	var p LogicalPlan
+	if info.building {
+		p = CTETable{Definition: info.definition}.init(b.allocator, b.ctx)
+	} else {
+		if info.definition == nil {
+			b.buildCTEDefinition(info)
+			if b.err != nil {
+				return nil
+			}
+		}
+		p = CTE{Definition: info.definition}.init(b.allocator, b.ctx)
+	}
+	schema := expression.NewSchema(make([]*expression.Column, 0, info.seedSchema.Len())...)
+	for i, col := range info.seedSchema.Columns {
+		name := col.ColName
+		if len(info.def.ColNameList) > 0 {
+			name = info.def.ColNameList[i]
+		}
+		tp := *col.RetType
+		schema.Append(&expression.Column{
+			FromID:   p.ID(),
+			ColName:  name,
+			TblName:  tn.Name,
+			RetType:  &tp,
+			Position: i,
+		})
+	}
+	p.SetSchema(schema)
+	return p
+}
+
+// buildCTEDefinition builds and optimizes the plan of the common table expression.
+// The query of a recursive common table expression is split into the seed part,
+// which consists of the query blocks without recursive reference, and the recursive part.
+func (b *planBuilder) buildCTEDefinition(info *cteInfo) {
+	oldCTEs, oldOuterSchemas := b.ctes, b.outerSchemas
+	b.ctes, b.outerSchemas = info.scope, nil
+	defer func() { b.ctes, b.outerSchemas = oldCTEs, oldOuterSchemas }()
+
+	name := info.def.Name
+	def := &CTEDefinition{Name: name}
+	query := info.def.Query.Query
+	union, ok := query.(*ast.UnionStmt)
+	refs, _ := countCTERefs(query, name)
+	if !info.isRecursive || refs == 0 {
+		p := b.buildResultSetNode(query)
+		if b.err != nil {
+			return
+		}
+		info.seedSchema = p.Schema()
+		def.SeedPlan = b.optimizeCTEPart(p)
+		info.definition = def
+		return
+	}
+	if !ok {
+		b.err = ErrCTERecursiveRequiresUnion.GenByArgs(name.O)
+		return
+	}
+	if union.OrderBy != nil || union.Limit != nil {
+		b.err = ErrNotSupportedYet.GenByArgs("ORDER BY / LIMIT over UNION in recursive Common Table Expression")
+		return
+	}
+	var seeds, recursives []*ast.SelectStmt
+	for _, sel := range union.SelectList.Selects {
+		refs, inSubquery := countCTERefs(sel, name)
+		if refs == 0 {
+			if len(recursives) > 0 {
+				b.err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(name.O)
+				return
+			}
+			seeds = append(seeds, sel)
+			continue
+		}
+		if refs > 1 || inSubquery {
+			b.err = ErrCTERecursiveRequiresSingleReference.GenByArgs(name.O)
+			return
+		}
+		if b.detectSelectAgg(sel) || b.detectSelectWindow(sel) {
+			b.err = ErrCTERecursiveForbidsAggregation.GenByArgs(name.O)
+			return
+		}
+		if sel.Distinct || sel.OrderBy != nil || sel.Limit != nil {
+			b.err = ErrNotSupportedYet.GenByArgs("DISTINCT / ORDER BY / LIMIT in recursive query block")
+			return
+		}
+		recursives = append(recursives, sel)
+	}
+	if len(seeds) == 0 {
+		b.err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(name.O)
+		return
+	}
+
+	seed := b.buildQueryBlocks(seeds, union.Distinct)
+	if b.err != nil {
+		return
+	}
+	info.seedSchema = seed.Schema()
+	def.IsDistinct = union.Distinct
+	def.SeedPlan = b.optimizeCTEPart(seed)
+	if b.err != nil {
+		return
+	}
+	info.definition = def
+	info.building = true
+	recursive := b.buildQueryBlocks(recursives, union.Distinct)
+	info.building = false
+	if b.err != nil {
+		return
+	}
+	if recursive.Schema().Len() != info.seedSchema.Len() {
+		b.err = errors.New("The used SELECT statements have a different number of columns")
+		return
+	}
+	// The types of the result columns are decided by the seed part only.
+	var castExprs []expression.Expression
+	for i, col := range recursive.Schema().Columns {
+		seedTp := info.seedSchema.Columns[i].RetType
+		if col.RetType.ToClass() != seedTp.ToClass() {
+			if castExprs == nil {
+				castExprs = expression.Column2Exprs(recursive.Schema().Columns)
+			}
+			castExprs[i] = expression.NewCastFunc(types.NewFieldType(seedTp.Tp), col, b.ctx)
+		}
+	}
+	if castExprs != nil {
+		proj := Projection{Exprs: castExprs}.init(b.allocator, b.ctx)
+		schema := recursive.Schema().Clone()
+		for i, col := range schema.Columns {
+			col.FromID = proj.ID()
+			col.RetType = castExprs[i].GetType()
+		}
+		proj.SetSchema(schema)
+		addChild(proj, recursive)
+		recursive = proj
+	}
+	def.RecursivePlan = b.optimizeCTEPart(recursive)
+}
+
+// buildQueryBlocks builds the plan of the query blocks which are combined by UNION.
+func (b *planBuilder) buildQueryBlocks(sels []*ast.SelectStmt, distinct bool) LogicalPlan {
+	if len(sels) == 1 {
+		return b.buildSelect(sels[0])
+	}
+	return b.buildUnion(&ast.UnionStmt{Distinct: distinct, SelectList: &ast.UnionSelectList{Selects: sels}})
+}
+
+func (b *planBuilder) optimizeCTEPart(p LogicalPlan) PhysicalPlan {
+	physicalPlan, err := doOptimize(b.optFlag, p, b.ctx, b.allocator)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	return physicalPlan
+}
+
+// cteRefCounter counts the references to a common table expression.
+type cteRefCounter struct {
+	name          model.CIStr
+	refs          int
+	subqueryDepth int
+	inSubquery    bool
+}
+
+// Enter implements Visitor interface.
+func (c *cteRefCounter) Enter(in ast.Node) (ast.Node, bool) {
+	switch x := in.(type) {
+	case *ast.SubqueryExpr:
+		c.subqueryDepth++
+	case *ast.TableName:
+		if x.Schema.L == "" && x.Name.L == c.name.L {
+			c.refs++
+			if c.subqueryDepth > 0 {
+				c.inSubquery = true
+			}
+		}
+	}
+	return in, false
+}
+
+// Leave implements Visitor interface.
+func (c *cteRefCounter) Leave(in ast.Node) (ast.Node, bool) {
+	if _, ok := in.(*ast.SubqueryExpr); ok {
+		c.subqueryDepth--
+	}
+	return in, true
+}
+
+// countCTERefs returns the number of references to the common table expression in the node,
+// and whether it is referenced in a subquery.
+func countCTERefs(node ast.Node, name model.CIStr) (int, bool) {
+	counter := &cteRefCounter{name: name}
+	node.Accept(counter)
+	return counter.refs, counter.inSubquery
+}
+
+// pushExpandingView marks the view as being expanded, it returns false if the view is being expanded already.
+func (b *planBuilder) pushExpandingView(schemaName, viewName model.CIStr) bool {
+	if b.expandingViews == nil {
+		b.expandingViews = make(map[string]struct{})
+	}
+	key := schemaName.L + "." + viewName.L
+	if _, ok := b.expandingViews[key]; ok {
+		return false
+	}
+	b.expandingViews[key] = struct{}{}
+	return true
+}
+
+func (b *planBuilder) popExpandingView(schemaName, viewName model.CIStr) {
+	delete(b.expandingViews, schemaName.L+"."+viewName.L)
+}
+
+// buildDataSourceFromView builds the plan of the select statement which defines the view,
+// and renames its output columns to the columns of the view.
+func (b *planBuilder) buildDataSourceFromView(tn *ast.TableName) LogicalPlan {
+	schemaName := tn.Schema
+	if schemaName.L == "" {
+		schemaName = model.NewCIStr(b.ctx.GetSessionVars().CurrentDB)
+	}
+	tbl, err := b.is.TableByName(schemaName, tn.Name)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	viewInfo := tbl.Meta()
+	if !b.pushExpandingView(schemaName, viewInfo.Name) {
+		b.err = ErrViewRecursive.GenByArgs(schemaName.O, viewInfo.Name.O)
+		return nil
+	}
+	defer b.popExpandingView(schemaName, viewInfo.Name)
+
+	charset, collation := b.ctx.GetSessionVars().GetCharsetInfo()
+	stmt, err := parser.New().ParseOneStmt(viewInfo.View.SelectStmt, charset, collation)
+	if err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+	// The names in the select statement are resolved in the database of the view.
+	if err = resolveNameWithSchema(stmt, b.is, schemaName, b.ctx); err != nil {
+		b.err = ErrViewInvalid.GenByArgs(schemaName.O, viewInfo.Name.O)
+		return nil
+	}
+	if err = expression.InferType(b.ctx.GetSessionVars().StmtCtx, stmt); err != nil {
+		b.err = errors.Trace(err)
+		return nil
+	}
+
+	// The view is executed with the privileges of its definer, so we only check the privilege
+	// on the view itself rather than the tables referenced by it.
+	oldVisitInfo, oldInUpdateStmt := b.visitInfo, b.inUpdateStmt
+	b.inUpdateStmt = false
+	p := b.buildResultSetNode(stmt.(ast.ResultSetNode))
+	b.visitInfo, b.inUpdateStmt = oldVisitInfo, oldInUpdateStmt
+	if b.err != nil {
+		return nil
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, schemaName.L, viewInfo.Name.L, "")
+
+	if p.Schema().Len() != len(viewInfo.Columns) {
+		b.err = ErrViewInvalid.GenByArgs(schemaName.O, viewInfo.Name.O)
+		return nil
+	}
+	b.optFlag |= flagEliminateProjection
+	proj := Projection{Exprs: expression.Column2Exprs(p.Schema().Columns)}.init(b.allocator, b.ctx)
+	schema := expression.NewSchema(make([]*expression.Column, 0, len(viewInfo.Columns))...)
+	for i, col := range p.Schema().Columns {
+		schema.Append(&expression.Column{
+			FromID:   proj.id,
+			ColName:  viewInfo.Columns[i].Name,
+			TblName:  viewInfo.Name,
+			DBName:   schemaName,
+			RetType:  col.GetType(),
+			Position: i,
+		})
+	}
+	proj.SetSchema(schema)
+	addChild(proj, p)
+	return proj
+}
+
 // ApplyConditionChecker checks whether all or any output of apply matches a condition.
 type ApplyConditionChecker struct {
 	Condition expression.Expression
@@ -1261,6 +2098,10 @@ func (b *planBuilder) buildUpdate(update *ast.UpdateStmt) LogicalPlan {
 	var tableList []*ast.TableName
 	tableList = extractTableList(sel.From.TableRefs, tableList)
 	for _, t := range tableList {
+		if t.TableInfo.IsView() {
+			b.err = ErrNonUpdatableTable.GenByArgs(t.Name.O, "UPDATE")
+			return nil
+		}
 		dbName := t.Schema.L
 		if dbName == "" {
 			dbName = b.ctx.GetSessionVars().CurrentDB
@@ -1345,6 +2186,12 @@ func (b *planBuilder) buildUpdateLists(tableList []*ast.TableName, list []*ast.A
 
 func (b *planBuilder) buildDelete(delete *ast.DeleteStmt) LogicalPlan {
 	sel := &ast.SelectStmt{Fields: &ast.FieldList{}, From: delete.TableRefs, Where: delete.Where, OrderBy: delete.Order, Limit: delete.Limit}
+	for _, t := range extractTableList(sel.From.TableRefs, nil) {
+		if t.TableInfo.IsView() {
+			b.err = ErrNonUpdatableTable.GenByArgs(t.Name.O, "DELETE")
+			return nil
+		}
+	}
 	p := b.buildResultSetNode(sel.From.TableRefs)
 	if b.err != nil {
 		return nil
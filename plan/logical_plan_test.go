@@ -1604,6 +1604,8 @@ func (s *testPlanSuite) TestVisitInfo(c *C) {
 				{mysql.AlterPriv, "test", "", ""},
 				{mysql.ExecutePriv, "test", "", ""},
 				{mysql.IndexPriv, "test", "", ""},
+				{mysql.CreateViewPriv, "test", "", ""},
+				{mysql.ShowViewPriv, "test", "", ""},
 			},
 		},
 		{
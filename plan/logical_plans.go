@@ -14,6 +14,9 @@
 package plan
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
 	"github.com/pingcap/tidb/expression"
@@ -41,6 +44,9 @@ var (
 	_ LogicalPlan = &Limit{}
 	_ LogicalPlan = &Show{}
 	_ LogicalPlan = &Insert{}
+	_ LogicalPlan = &LogicalWindow{}
+	_ LogicalPlan = &CTE{}
+	_ LogicalPlan = &CTETable{}
 )
 
 // JoinType contains CrossJoin, InnerJoin, LeftOuterJoin, RightOuterJoin, FullOuterJoin, SemiJoin.
@@ -75,6 +81,7 @@ type LogicalJoin struct {
 	cartesianJoin   bool
 	preferINLJ      int
 	preferMergeJoin bool
+	preferHashJoin  bool
 
 	EqualConditions []*expression.ScalarFunction
 	LeftConditions  expression.CNFExprs
@@ -164,6 +171,8 @@ type LogicalAggregation struct {
 	groupByCols []*expression.Column
 
 	possibleProperties [][]*expression.Column
+	// preferAggType is set by the HASH_AGG and STREAM_AGG hints.
+	preferAggType uint
 }
 
 func (p *LogicalAggregation) extractCorrelatedCols() []*expression.CorrelatedColumn {
@@ -250,6 +259,37 @@ type TableDual struct {
 	RowCount int
 }
 
+// CTEDefinition is the plan of a common table expression. The result of the seed plan is the
+// initial result, if the common table expression is recursive, the recursive plan is executed
+// repeatedly over the rows produced by the previous iteration until no new row is produced.
+type CTEDefinition struct {
+	Name          model.CIStr
+	SeedPlan      PhysicalPlan
+	RecursivePlan PhysicalPlan
+	// IsDistinct is true if the query blocks are combined by UNION DISTINCT.
+	IsDistinct bool
+}
+
+// CTE represents a reference to a common table expression. All the references to the same
+// common table expression share the definition, so its result is materialized only once.
+type CTE struct {
+	*basePlan
+	baseLogicalPlan
+	basePhysicalPlan
+
+	Definition *CTEDefinition
+}
+
+// CTETable represents the recursive reference to a common table expression in its own definition,
+// it reads the rows produced by the previous iteration.
+type CTETable struct {
+	*basePlan
+	baseLogicalPlan
+	basePhysicalPlan
+
+	Definition *CTEDefinition
+}
+
 // DataSource represents a tablescan without condition push down.
 type DataSource struct {
 	*basePlan
@@ -260,6 +300,9 @@ type DataSource struct {
 	Columns    []*model.ColumnInfo
 	DBName     model.CIStr
 
+	// physicalTableID is the ID of the partition if the DataSource reads a partition, otherwise it's the table ID.
+	physicalTableID int64
+
 	TableAsName *model.CIStr
 
 	LimitCount *int64
@@ -328,6 +371,89 @@ func (t *TopN) isLimit() bool {
 	return len(t.ByItems) == 0
 }
 
+// WindowFuncDesc describes a window function, e.g. row_number() or an aggregate function over a window.
+type WindowFuncDesc struct {
+	Name    string
+	Args    []expression.Expression
+	RetType *types.FieldType
+}
+
+// String implements fmt.Stringer interface.
+func (d *WindowFuncDesc) String() string {
+	args := make([]string, 0, len(d.Args))
+	for _, arg := range d.Args {
+		args = append(args, arg.String())
+	}
+	return fmt.Sprintf("%s(%s)", d.Name, strings.Join(args, ", "))
+}
+
+// FrameBound is the boundary of a window frame.
+type FrameBound struct {
+	Type      ast.BoundType
+	UnBounded bool
+	// Num is the offset of a bounded PRECEDING or FOLLOWING boundary.
+	Num types.Datum
+}
+
+// String implements fmt.Stringer interface.
+func (b *FrameBound) String() string {
+	if b.Type == ast.CurrentRow {
+		return "current row"
+	}
+	str := "unbounded"
+	if !b.UnBounded {
+		str, _ = b.Num.ToString()
+	}
+	if b.Type == ast.Preceding {
+		return str + " preceding"
+	}
+	return str + " following"
+}
+
+// WindowFrame is the frame of a window, which decides the rows that a window function works on.
+type WindowFrame struct {
+	Type  ast.FrameType
+	Start *FrameBound
+	End   *FrameBound
+}
+
+// String implements fmt.Stringer interface.
+func (f *WindowFrame) String() string {
+	tp := "rows"
+	if f.Type == ast.Ranges {
+		tp = "range"
+	}
+	return fmt.Sprintf("%s between %s and %s", tp, f.Start, f.End)
+}
+
+// LogicalWindow represents a window plan. Its child is sorted by the partition by items and the order by items,
+// and it appends the results of the window functions to the rows of its child.
+type LogicalWindow struct {
+	*basePlan
+	baseLogicalPlan
+
+	WindowFuncDescs []*WindowFuncDesc
+	PartitionBy     []*ByItems
+	OrderBy         []*ByItems
+	Frame           *WindowFrame
+}
+
+func (p *LogicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, desc := range p.WindowFuncDescs {
+		for _, arg := range desc.Args {
+			corCols = append(corCols, extractCorColumns(arg)...)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 // Update represents Update plan.
 type Update struct {
 	*basePlan
@@ -255,6 +255,9 @@ func (p *LogicalJoin) generatePhysicalPlans() []PhysicalPlan {
 	case SemiJoin, LeftOuterSemiJoin:
 		return []PhysicalPlan{p.getSemiJoin()}
 	default:
+		if p.preferHashJoin {
+			return p.getHashJoins()
+		}
 		mj := p.getMergeJoin()
 		if p.preferMergeJoin && len(mj) > 0 {
 			return mj
@@ -268,16 +271,22 @@ func (p *LogicalJoin) generatePhysicalPlans() []PhysicalPlan {
 			return idxJoins
 		}
 		joins = append(joins, idxJoins...)
-		if p.JoinType != RightOuterJoin {
-			leftJoin := p.getHashJoin(1)
-			joins = append(joins, leftJoin)
-		}
-		if p.JoinType != LeftOuterJoin {
-			rightJoin := p.getHashJoin(0)
-			joins = append(joins, rightJoin)
-		}
-		return joins
+		return append(joins, p.getHashJoins()...)
+	}
+}
+
+// getHashJoins returns the hash joins which build the hash table on the left or the right child.
+func (p *LogicalJoin) getHashJoins() []PhysicalPlan {
+	joins := make([]PhysicalPlan, 0, 2)
+	if p.JoinType != RightOuterJoin {
+		leftJoin := p.getHashJoin(1)
+		joins = append(joins, leftJoin)
+	}
+	if p.JoinType != LeftOuterJoin {
+		rightJoin := p.getHashJoin(0)
+		joins = append(joins, rightJoin)
 	}
+	return joins
 }
 
 func getPermutation(cols1, cols2 []*expression.Column) ([]int, []*expression.Column) {
@@ -681,6 +690,7 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 		DBName:           p.DBName,
 		Columns:          p.Columns,
 		Index:            idx,
+		PhysicalTableID:  p.physicalTableID,
 		dataSourceSchema: p.schema,
 	}.init(p.allocator, p.ctx)
 	statsTbl := p.statisticTable
@@ -715,7 +725,7 @@ func (p *DataSource) convertToIndexScan(prop *requiredProp, idx *model.IndexInfo
 	}
 	if !isCoveringIndex(is.Columns, is.Index.Columns, is.Table.PKIsHandle) {
 		// On this way, it's double read case.
-		cop.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table}.init(p.allocator, p.ctx)
+		cop.tablePlan = PhysicalTableScan{Columns: p.Columns, Table: is.Table, PhysicalTableID: p.physicalTableID}.init(p.allocator, p.ctx)
 		cop.tablePlan.SetSchema(p.schema)
 		// If it's parent requires single read task, return max cost.
 		if prop.taskTp == copSingleReadTaskType {
@@ -820,10 +830,11 @@ func (p *DataSource) convertToTableScan(prop *requiredProp) (task task, err erro
 		return &copTask{cst: math.MaxFloat64}, nil
 	}
 	ts := PhysicalTableScan{
-		Table:       p.tableInfo,
-		Columns:     p.Columns,
-		TableAsName: p.TableAsName,
-		DBName:      p.DBName,
+		Table:           p.tableInfo,
+		Columns:         p.Columns,
+		TableAsName:     p.TableAsName,
+		DBName:          p.DBName,
+		PhysicalTableID: p.physicalTableID,
 	}.init(p.allocator, p.ctx)
 	ts.SetSchema(p.schema)
 	sc := p.ctx.GetSessionVars().StmtCtx
@@ -984,25 +995,76 @@ func (p *Limit) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	return props
 }
 
+func (p *Union) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	// The union doesn't keep the order of its children, so the order is always enforced above it.
+	if !prop.isEmpty() {
+		return nil
+	}
+	props := make([]*requiredProp, 0, len(p.children))
+	for range p.children {
+		props = append(props, &requiredProp{taskTp: rootTaskType})
+	}
+	return [][]*requiredProp{props}
+}
+
 func (p *LogicalAggregation) generatePhysicalPlans() []PhysicalPlan {
-	ha := PhysicalAggregation{
+	aggType := CompleteAgg
+	// The stream aggregation is only used by hint, it requires the group by items to be columns to sort its child.
+	if p.preferAggType == preferStreamAgg {
+		aggType = StreamedAgg
+		for _, item := range p.GroupByItems {
+			if _, ok := item.(*expression.Column); !ok {
+				aggType = CompleteAgg
+				break
+			}
+		}
+	}
+	agg := PhysicalAggregation{
 		GroupByItems: p.GroupByItems,
 		AggFuncs:     p.AggFuncs,
 		HasGby:       len(p.GroupByItems) > 0,
-		AggType:      CompleteAgg,
+		AggType:      aggType,
 	}.init(p.allocator, p.ctx)
-	ha.SetSchema(p.schema)
-	ha.profile = p.profile
-	return []PhysicalPlan{ha}
+	agg.SetSchema(p.schema)
+	agg.profile = p.profile
+	return []PhysicalPlan{agg}
 }
 
 func (p *PhysicalAggregation) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
 	if !prop.isEmpty() {
 		return nil
 	}
+	if p.AggType == StreamedAgg {
+		cols := make([]*expression.Column, 0, len(p.GroupByItems))
+		for _, item := range p.GroupByItems {
+			cols = append(cols, item.(*expression.Column))
+		}
+		return [][]*requiredProp{{{taskTp: rootTaskType, cols: cols}}}
+	}
 	props := make([][]*requiredProp, 0, len(wholeTaskTypes))
 	for _, tp := range wholeTaskTypes {
 		props = append(props, []*requiredProp{{taskTp: tp}})
 	}
 	return props
 }
+
+func (p *LogicalWindow) generatePhysicalPlans() []PhysicalPlan {
+	window := PhysicalWindow{
+		WindowFuncDescs: p.WindowFuncDescs,
+		PartitionBy:     p.PartitionBy,
+		OrderBy:         p.OrderBy,
+		Frame:           p.Frame,
+	}.init(p.allocator, p.ctx)
+	window.SetSchema(p.schema)
+	window.profile = p.profile
+	return []PhysicalPlan{window}
+}
+
+func (p *PhysicalWindow) getChildrenPossibleProps(prop *requiredProp) [][]*requiredProp {
+	// The child of window has been sorted by the partition by and order by items, so the order of the window's
+	// output is decided by them and cannot match another required property.
+	if !prop.isEmpty() {
+		return nil
+	}
+	return [][]*requiredProp{{&requiredProp{taskTp: rootTaskType}}}
+}
@@ -14,13 +14,18 @@
 package plan
 
 import (
+	"strings"
+
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/bindinfo"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/infoschema"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
 	"github.com/pingcap/tidb/privilege"
+	"github.com/pingcap/tidb/sessionctx"
 	"github.com/pingcap/tidb/terror"
 )
 
@@ -33,6 +38,7 @@ const (
 	flagBuildKeyInfo
 	flagDecorrelate
 	flagPredicatePushDown
+	flagPartitionProcessor
 	flagAggregationOptimize
 	flagPushDownTopN
 )
@@ -43,6 +49,7 @@ var optRuleList = []logicalOptRule{
 	&buildKeySolver{},
 	&decorrelateSolver{},
 	&ppdSolver{},
+	&partitionProcessor{},
 	&aggregationOptimizer{},
 	&pushDownTopNOptimizer{},
 }
@@ -59,6 +66,9 @@ func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Pla
 	if err := expression.InferType(ctx.GetSessionVars().StmtCtx, node); err != nil {
 		return nil, errors.Trace(err)
 	}
+	if stmt, record := matchBinding(ctx, node); record != nil {
+		defer record.ApplyHints(stmt)()
+	}
 	allocator := new(idAllocator)
 	builder := &planBuilder{
 		ctx:       ctx,
@@ -70,6 +80,9 @@ func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Pla
 	if builder.err != nil {
 		return nil, errors.Trace(builder.err)
 	}
+	if builder.noAggPushDown {
+		builder.optFlag &^= flagAggregationOptimize
+	}
 
 	// Maybe it's better to move this to Preprocess, but check privilege need table
 	// information, which is collected into visitInfo during logical plan builder.
@@ -85,6 +98,59 @@ func Optimize(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Pla
 	return p, nil
 }
 
+// matchBinding returns the binding of the select statement in node and the statement, the session bindings
+// override the global bindings. The statement explained by EXPLAIN is matched too.
+func matchBinding(ctx context.Context, node ast.Node) (ast.StmtNode, *bindinfo.BindRecord) {
+	if ctx.GetSessionVars().InRestrictedSQL {
+		return nil, nil
+	}
+	sessionHandle := bindinfo.GetSessionHandle(ctx)
+	var globalHandle *bindinfo.Handle
+	if do := sessionctx.GetDomain(ctx); do != nil {
+		globalHandle = do.BindHandle()
+	}
+	if (sessionHandle == nil || sessionHandle.Size() == 0) && (globalHandle == nil || globalHandle.Size() == 0) {
+		return nil, nil
+	}
+
+	var stmt ast.StmtNode
+	var normalized string
+	switch x := node.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		stmt = x.(ast.StmtNode)
+		normalized = parser.Normalize(stmt.Text())
+	case *ast.ExplainStmt:
+		switch x.Stmt.(type) {
+		case *ast.SelectStmt, *ast.UnionStmt:
+		default:
+			return nil, nil
+		}
+		// The text of the explained statement isn't kept, so it's cut from the normalized EXPLAIN statement.
+		stmt = x.Stmt
+		normalized = parser.Normalize(x.Text())
+		pos := strings.Index(normalized, "select ")
+		if pos < 0 {
+			return nil, nil
+		}
+		normalized = normalized[pos:]
+	default:
+		return nil, nil
+	}
+
+	db := ctx.GetSessionVars().CurrentDB
+	if sessionHandle != nil {
+		if record := sessionHandle.GetBindRecord(normalized, db); record != nil {
+			return stmt, record
+		}
+	}
+	if globalHandle != nil {
+		if record := globalHandle.GetBindRecord(normalized, db); record != nil {
+			return stmt, record
+		}
+	}
+	return nil, nil
+}
+
 // BuildLogicalPlan is exported and only used for test.
 func BuildLogicalPlan(ctx context.Context, node ast.Node, is infoschema.InfoSchema) (Plan, error) {
 	// We have to infer type again because after parameter is set, the expression type may change.
@@ -233,4 +299,5 @@ func init() {
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizer] = mySQLErrCodes
 	expression.EvalAstExpr = evalAstExpr
+	expression.RewriteAstExpr = rewriteAstExpr
 }
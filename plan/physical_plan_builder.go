@@ -52,6 +52,7 @@ func (p *DataSource) convert2TableScan(prop *requiredProperty) (*physicalPlanInf
 		Columns:             p.Columns,
 		TableAsName:         p.TableAsName,
 		DBName:              p.DBName,
+		PhysicalTableID:     p.physicalTableID,
 		physicalTableSource: physicalTableSource{client: client},
 	}.init(p.allocator, p.ctx)
 	ts.SetSchema(p.Schema())
@@ -118,6 +119,7 @@ func (p *DataSource) convert2IndexScan(prop *requiredProperty, index *model.Inde
 		TableAsName:         p.TableAsName,
 		OutOfOrder:          true,
 		DBName:              p.DBName,
+		PhysicalTableID:     p.physicalTableID,
 		physicalTableSource: physicalTableSource{client: client},
 	}.init(p.allocator, p.ctx)
 	is.SetSchema(p.schema)
@@ -1182,9 +1184,14 @@ func (p *LogicalAggregation) convert2PhysicalPlan(prop *requiredProperty) (*phys
 			return nil, errors.Trace(err)
 		}
 	}
-	streamInfo, err := p.convert2PhysicalPlanStream(removeLimit(prop))
-	if planInfo == nil || streamInfo.cost < planInfo.cost {
-		planInfo = streamInfo
+	if planInfo == nil || p.preferAggType != preferHashAgg {
+		streamInfo, err := p.convert2PhysicalPlanStream(removeLimit(prop))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if planInfo == nil || streamInfo.cost < planInfo.cost || (p.preferAggType == preferStreamAgg && streamInfo.p != nil) {
+			planInfo = streamInfo
+		}
 	}
 	planInfo = enforceProperty(limitProperty(limit), planInfo)
 	err = p.storePlanInfo(prop, planInfo)
@@ -1252,6 +1259,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 			Columns:             ds.Columns,
 			TableAsName:         ds.TableAsName,
 			DBName:              ds.DBName,
+			PhysicalTableID:     ds.physicalTableID,
 			physicalTableSource: physicalTableSource{client: ds.ctx.GetClient()},
 		}.init(p.allocator, p.ctx)
 		ts.SetSchema(ds.schema)
@@ -1280,6 +1288,7 @@ func (p *Selection) makeScanController() *physicalPlanInfo {
 					TableAsName:         ds.TableAsName,
 					OutOfOrder:          true,
 					DBName:              ds.DBName,
+					PhysicalTableID:     ds.physicalTableID,
 					physicalTableSource: physicalTableSource{client: ds.ctx.GetClient()},
 				}.init(p.allocator, p.ctx)
 				is.SetSchema(ds.schema)
@@ -1528,6 +1537,33 @@ func (p *Sort) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo,
 	return sortedPlanInfo, nil
 }
 
+// convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
+func (p *LogicalWindow) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
+	info, err := p.getPlanInfo(prop)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if info != nil {
+		return info, nil
+	}
+	info, err = p.children[0].(LogicalPlan).convert2PhysicalPlan(&requiredProperty{})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	window := PhysicalWindow{
+		WindowFuncDescs: p.WindowFuncDescs,
+		PartitionBy:     p.PartitionBy,
+		OrderBy:         p.OrderBy,
+		Frame:           p.Frame,
+	}.init(p.allocator, p.ctx)
+	window.SetSchema(p.schema)
+	info = addPlanToResponse(window, info)
+	info.cost += info.count * cpuFactor
+	info = enforceProperty(prop, info)
+	p.storePlanInfo(prop, info)
+	return info, nil
+}
+
 // convert2PhysicalPlan implements the LogicalPlan convert2PhysicalPlan interface.
 func (p *LogicalApply) convert2PhysicalPlan(prop *requiredProperty) (*physicalPlanInfo, error) {
 	info, err := p.getPlanInfo(prop)
@@ -65,6 +65,9 @@ var (
 	_ PhysicalPlan = &PhysicalMergeJoin{}
 	_ PhysicalPlan = &PhysicalUnionScan{}
 	_ PhysicalPlan = &Cache{}
+	_ PhysicalPlan = &PhysicalWindow{}
+	_ PhysicalPlan = &CTE{}
+	_ PhysicalPlan = &CTETable{}
 )
 
 // PhysicalTableReader is the table reader in tidb.
@@ -138,6 +141,8 @@ type PhysicalIndexScan struct {
 	DBName     model.CIStr
 	Desc       bool
 	OutOfOrder bool
+	// PhysicalTableID is the ID of the partition if the scan reads a partition, otherwise it's the table ID.
+	PhysicalTableID int64
 	// DoubleRead means if the index executor will read kv two times.
 	// If the query requires the columns that don't belong to index, DoubleRead will be true.
 	DoubleRead bool
@@ -432,6 +437,8 @@ type PhysicalTableScan struct {
 	Desc    bool
 	Ranges  []types.IntColumnRange
 	pkCol   *expression.Column
+	// PhysicalTableID is the ID of the partition if the scan reads a partition, otherwise it's the table ID.
+	PhysicalTableID int64
 
 	TableAsName *model.CIStr
 
@@ -537,6 +544,17 @@ type PhysicalAggregation struct {
 	GroupByItems []expression.Expression
 }
 
+// PhysicalWindow is Window's physical plan.
+type PhysicalWindow struct {
+	*basePlan
+	basePhysicalPlan
+
+	WindowFuncDescs []*WindowFuncDesc
+	PartitionBy     []*ByItems
+	OrderBy         []*ByItems
+	Frame           *WindowFrame
+}
+
 // PhysicalUnionScan represents a union scan operator.
 type PhysicalUnionScan struct {
 	*basePlan
@@ -647,6 +665,22 @@ func (p *PhysicalAggregation) extractCorrelatedCols() []*expression.CorrelatedCo
 	return corCols
 }
 
+func (p *PhysicalWindow) extractCorrelatedCols() []*expression.CorrelatedColumn {
+	corCols := p.basePlan.extractCorrelatedCols()
+	for _, desc := range p.WindowFuncDescs {
+		for _, arg := range desc.Args {
+			corCols = append(corCols, extractCorColumns(arg)...)
+		}
+	}
+	for _, item := range p.PartitionBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	for _, item := range p.OrderBy {
+		corCols = append(corCols, extractCorColumns(item.Expr)...)
+	}
+	return corCols
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *PhysicalIndexScan) Copy() PhysicalPlan {
 	np := *p
@@ -1037,6 +1071,24 @@ func (p *TableDual) Copy() PhysicalPlan {
 	return &np
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *CTE) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.baseLogicalPlan = newBaseLogicalPlan(np.basePlan)
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// Copy implements the PhysicalPlan Copy interface.
+func (p *CTETable) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.baseLogicalPlan = newBaseLogicalPlan(np.basePlan)
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *SelectLock) Copy() PhysicalPlan {
 	np := *p
@@ -1072,6 +1124,42 @@ func (p *PhysicalAggregation) MarshalJSON() ([]byte, error) {
 	return buffer.Bytes(), nil
 }
 
+// Copy implements the PhysicalPlan Copy interface.
+func (p *PhysicalWindow) Copy() PhysicalPlan {
+	np := *p
+	np.basePlan = p.basePlan.copy()
+	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
+	return &np
+}
+
+// MarshalJSON implements json.Marshaler interface.
+func (p *PhysicalWindow) MarshalJSON() ([]byte, error) {
+	buffer := bytes.NewBufferString("{")
+	funcs := make([]string, 0, len(p.WindowFuncDescs))
+	for _, desc := range p.WindowFuncDescs {
+		funcs = append(funcs, desc.String())
+	}
+	funcsJSON, err := json.Marshal(funcs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	partitionBy, err := json.Marshal(p.PartitionBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	orderBy, err := json.Marshal(p.OrderBy)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	buffer.WriteString(fmt.Sprintf(
+		"\"WindowFuncs\": %s,\n"+
+			"\"PartitionBy\": %s,\n"+
+			"\"OrderBy\": %s,\n"+
+			"\"Frame\": \"%s\",\n"+
+			"\"child\": \"%s\"}", funcsJSON, partitionBy, orderBy, p.Frame, p.children[0].ID()))
+	return buffer.Bytes(), nil
+}
+
 // Copy implements the PhysicalPlan Copy interface.
 func (p *Update) Copy() PhysicalPlan {
 	np := *p
@@ -1114,3 +1202,212 @@ func (p *Cache) Copy() PhysicalPlan {
 	np.basePhysicalPlan = newBasePhysicalPlan(np.basePlan)
 	return &np
 }
+
+// writePartitionName writes the name of the partition if the scan reads a partition of a partitioned table.
+func writePartitionName(buffer *bytes.Buffer, tbl *model.TableInfo, physicalTableID int64) {
+	if tbl.Partition == nil {
+		return
+	}
+	for _, def := range tbl.Partition.Definitions {
+		if def.ID == physicalTableID {
+			buffer.WriteString(fmt.Sprintf(", partition:%s", def.Name.O))
+			return
+		}
+	}
+}
+
+// ExplainInfo returns the operator information of a physical plan for EXPLAIN FORMAT="row",
+// e.g. the accessed table, index and ranges of a scan, or the conditions of a selection.
+func ExplainInfo(p Plan) string {
+	buffer := bytes.NewBufferString("")
+	switch x := p.(type) {
+	case *PhysicalTableScan:
+		tblName := x.Table.Name.O
+		if x.TableAsName != nil && x.TableAsName.O != "" {
+			tblName = x.TableAsName.O
+		}
+		buffer.WriteString(fmt.Sprintf("table:%s", tblName))
+		writePartitionName(buffer, x.Table, x.PhysicalTableID)
+		if x.pkCol != nil {
+			buffer.WriteString(fmt.Sprintf(", pk col:%s", x.pkCol))
+		}
+		if len(x.Ranges) > 0 {
+			buffer.WriteString(fmt.Sprintf(", range:%v", x.Ranges))
+		}
+		buffer.WriteString(fmt.Sprintf(", keep order:%v", x.KeepOrder))
+	case *PhysicalIndexScan:
+		tblName := x.Table.Name.O
+		if x.TableAsName != nil && x.TableAsName.O != "" {
+			tblName = x.TableAsName.O
+		}
+		buffer.WriteString(fmt.Sprintf("table:%s", tblName))
+		writePartitionName(buffer, x.Table, x.PhysicalTableID)
+		buffer.WriteString(", index:")
+		for i, idxCol := range x.Index.Columns {
+			buffer.WriteString(idxCol.Name.O)
+			if i+1 < len(x.Index.Columns) {
+				buffer.WriteString(", ")
+			}
+		}
+		if len(x.Ranges) > 0 {
+			buffer.WriteString(fmt.Sprintf(", range:%v", x.Ranges))
+		}
+		buffer.WriteString(fmt.Sprintf(", out of order:%v", x.OutOfOrder))
+	case *PhysicalMemTable:
+		buffer.WriteString(fmt.Sprintf("table:%s.%s", x.DBName.O, x.Table.Name.O))
+	case *Selection:
+		buffer.WriteString(explainExprs(x.Conditions))
+	case *Projection:
+		buffer.WriteString(explainExprs(x.Exprs))
+	case *Sort:
+		buffer.WriteString(explainByItems(x.ByItems))
+	case *TopN:
+		buffer.WriteString(fmt.Sprintf("%s, offset:%d, count:%d", explainByItems(x.ByItems), x.Offset, x.Count))
+	case *Limit:
+		buffer.WriteString(fmt.Sprintf("offset:%d, count:%d", x.Offset, x.Count))
+	case *TableDual:
+		buffer.WriteString(fmt.Sprintf("rows:%d", x.RowCount))
+	case *CTE:
+		buffer.WriteString(fmt.Sprintf("cte:%s", x.Definition.Name.O))
+	case *CTETable:
+		buffer.WriteString(fmt.Sprintf("cte:%s", x.Definition.Name.O))
+	case *PhysicalAggregation:
+		// The group by items of a final aggregation are unnamed partial results, so they may be empty.
+		if gbyStr := explainExprs(x.GroupByItems); gbyStr != "" {
+			buffer.WriteString(fmt.Sprintf("group by:%s, ", gbyStr))
+		}
+		buffer.WriteString("funcs:")
+		for i, agg := range x.AggFuncs {
+			buffer.WriteString(fmt.Sprintf("%s", agg))
+			if i+1 < len(x.AggFuncs) {
+				buffer.WriteString(", ")
+			}
+		}
+	case *PhysicalWindow:
+		buffer.WriteString(explainWindowFuncs(x.WindowFuncDescs))
+		buffer.WriteString(" over(")
+		if len(x.PartitionBy) > 0 {
+			buffer.WriteString(fmt.Sprintf("partition by %s ", explainByItems(x.PartitionBy)))
+		}
+		if len(x.OrderBy) > 0 {
+			buffer.WriteString(fmt.Sprintf("order by %s ", explainByItems(x.OrderBy)))
+		}
+		buffer.WriteString(fmt.Sprintf("%s)", x.Frame))
+	case *PhysicalHashJoin:
+		buffer.WriteString(explainJoinType(x.JoinType))
+		writeJoinConditions(buffer, x.EqualConditions, x.LeftConditions, x.RightConditions, x.OtherConditions)
+	case *PhysicalMergeJoin:
+		buffer.WriteString(explainJoinType(x.JoinType))
+		writeJoinConditions(buffer, x.EqualConditions, x.LeftConditions, x.RightConditions, x.OtherConditions)
+	case *PhysicalHashSemiJoin:
+		if x.Anti {
+			buffer.WriteString("anti semi join")
+		} else {
+			buffer.WriteString("semi join")
+		}
+		if x.WithAux {
+			buffer.WriteString(" with aux")
+		}
+		writeJoinConditions(buffer, x.EqualConditions, x.LeftConditions, x.RightConditions, x.OtherConditions)
+	case *PhysicalIndexJoin:
+		if x.Outer {
+			buffer.WriteString("outer join")
+		} else {
+			buffer.WriteString("inner join")
+		}
+		buffer.WriteString(fmt.Sprintf(", outer key:%s, inner key:%s", explainColumns(x.OuterJoinKeys), explainColumns(x.InnerJoinKeys)))
+		if len(x.OtherConditions) > 0 {
+			buffer.WriteString(fmt.Sprintf(", other cond:%s", explainExprs(x.OtherConditions)))
+		}
+	case *SelectLock:
+		if x.Lock == ast.SelectLockForUpdate {
+			buffer.WriteString("for update")
+		} else if x.Lock == ast.SelectLockInShareMode {
+			buffer.WriteString("lock in share mode")
+		}
+	}
+	return buffer.String()
+}
+
+func explainExprs(exprs []expression.Expression) string {
+	buffer := bytes.NewBufferString("")
+	for i, expr := range exprs {
+		buffer.WriteString(expr.String())
+		if i+1 < len(exprs) {
+			buffer.WriteString(", ")
+		}
+	}
+	return buffer.String()
+}
+
+func explainWindowFuncs(descs []*WindowFuncDesc) string {
+	buffer := bytes.NewBufferString("")
+	for i, desc := range descs {
+		buffer.WriteString(desc.String())
+		if i+1 < len(descs) {
+			buffer.WriteString(", ")
+		}
+	}
+	return buffer.String()
+}
+
+func explainColumns(cols []*expression.Column) string {
+	buffer := bytes.NewBufferString("")
+	for i, col := range cols {
+		buffer.WriteString(col.String())
+		if i+1 < len(cols) {
+			buffer.WriteString(", ")
+		}
+	}
+	return buffer.String()
+}
+
+func explainByItems(byItems []*ByItems) string {
+	buffer := bytes.NewBufferString("")
+	for i, item := range byItems {
+		buffer.WriteString(item.Expr.String())
+		if item.Desc {
+			buffer.WriteString(":desc")
+		}
+		if i+1 < len(byItems) {
+			buffer.WriteString(", ")
+		}
+	}
+	return buffer.String()
+}
+
+func explainJoinType(tp JoinType) string {
+	switch tp {
+	case LeftOuterJoin:
+		return "left outer join"
+	case RightOuterJoin:
+		return "right outer join"
+	case SemiJoin:
+		return "semi join"
+	case LeftOuterSemiJoin:
+		return "left outer semi join"
+	default:
+		return "inner join"
+	}
+}
+
+func writeJoinConditions(buffer *bytes.Buffer, eqConds []*expression.ScalarFunction, leftConds, rightConds, otherConds []expression.Expression) {
+	if len(eqConds) > 0 {
+		buffer.WriteString(", equal:")
+		for i, cond := range eqConds {
+			buffer.WriteString(cond.String())
+			if i+1 < len(eqConds) {
+				buffer.WriteString(", ")
+			}
+		}
+	}
+	if len(leftConds) > 0 {
+		buffer.WriteString(fmt.Sprintf(", left cond:%s", explainExprs(leftConds)))
+	}
+	if len(rightConds) > 0 {
+		buffer.WriteString(fmt.Sprintf(", right cond:%s", explainExprs(rightConds)))
+	}
+	if len(otherConds) > 0 {
+		buffer.WriteString(fmt.Sprintf(", other cond:%s", explainExprs(otherConds)))
+	}
+}
@@ -15,6 +15,7 @@ package plan
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/pingcap/tidb/ast"
@@ -31,34 +32,103 @@ import (
 
 // Error instances.
 var (
-	ErrUnsupportedType      = terror.ClassOptimizerPlan.New(CodeUnsupportedType, "Unsupported type")
-	SystemInternalErrorType = terror.ClassOptimizerPlan.New(SystemInternalError, "System internal error")
-	ErrUnknownColumn        = terror.ClassOptimizerPlan.New(CodeUnknownColumn, "Unknown column '%s' in '%s'")
-	ErrWrongArguments       = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
-	ErrAmbiguous            = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
-	ErrAnalyzeMissIndex     = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
-	ErrAlterAutoID          = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
-	ErrBadGeneratedColumn   = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrUnsupportedType                       = terror.ClassOptimizerPlan.New(CodeUnsupportedType, "Unsupported type")
+	SystemInternalErrorType                  = terror.ClassOptimizerPlan.New(SystemInternalError, "System internal error")
+	ErrUnknownColumn                         = terror.ClassOptimizerPlan.New(CodeUnknownColumn, "Unknown column '%s' in '%s'")
+	ErrWrongArguments                        = terror.ClassOptimizerPlan.New(CodeWrongArguments, "Incorrect arguments to EXECUTE")
+	ErrAmbiguous                             = terror.ClassOptimizerPlan.New(CodeAmbiguous, "Column '%s' in field list is ambiguous")
+	ErrAnalyzeMissIndex                      = terror.ClassOptimizerPlan.New(CodeAnalyzeMissIndex, "Index '%s' in field list does not exist in table '%s'")
+	ErrAlterAutoID                           = terror.ClassAutoid.New(CodeAlterAutoID, "No support for setting auto_increment using alter_table")
+	ErrBadGeneratedColumn                    = terror.ClassOptimizerPlan.New(CodeBadGeneratedColumn, mysql.MySQLErrName[mysql.ErrBadGeneratedColumn])
+	ErrUnknownExplainFormat                  = terror.ClassOptimizerPlan.New(CodeUnknownExplainFormat, mysql.MySQLErrName[mysql.ErrUnknownExplainFormat])
+	ErrViewWrongList                         = terror.ClassOptimizerPlan.New(CodeViewWrongList, mysql.MySQLErrName[mysql.ErrViewWrongList])
+	ErrViewInvalid                           = terror.ClassOptimizerPlan.New(CodeViewInvalid, mysql.MySQLErrName[mysql.ErrViewInvalid])
+	ErrViewRecursive                         = terror.ClassOptimizerPlan.New(CodeViewRecursive, mysql.MySQLErrName[mysql.ErrViewRecursive])
+	ErrNonUpdatableTable                     = terror.ClassOptimizerPlan.New(CodeNonUpdatableTable, mysql.MySQLErrName[mysql.ErrNonUpdatableTable])
+	ErrNonInsertableTable                    = terror.ClassOptimizerPlan.New(CodeNonInsertableTable, mysql.MySQLErrName[mysql.ErrNonInsertableTable])
+	ErrDupFieldName                          = terror.ClassOptimizerPlan.New(CodeDupFieldName, mysql.MySQLErrName[mysql.ErrDupFieldName])
+	ErrNotSupportedYet                       = terror.ClassOptimizerPlan.New(CodeNotSupportedYet, mysql.MySQLErrName[mysql.ErrNotSupportedYet])
+	ErrWindowInvalidWindowFuncUse            = terror.ClassOptimizerPlan.New(CodeWindowInvalidWindowFuncUse, mysql.MySQLErrName[mysql.ErrWindowInvalidWindowFuncUse])
+	ErrWindowFrameStartIllegal               = terror.ClassOptimizerPlan.New(CodeWindowFrameStartIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameStartIllegal])
+	ErrWindowFrameEndIllegal                 = terror.ClassOptimizerPlan.New(CodeWindowFrameEndIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameEndIllegal])
+	ErrWindowFrameIllegal                    = terror.ClassOptimizerPlan.New(CodeWindowFrameIllegal, mysql.MySQLErrName[mysql.ErrWindowFrameIllegal])
+	ErrWindowRangeFrameOrderType             = terror.ClassOptimizerPlan.New(CodeWindowRangeFrameOrderType, mysql.MySQLErrName[mysql.ErrWindowRangeFrameOrderType])
+	ErrNonUniqTable                          = terror.ClassOptimizerPlan.New(CodeNonUniqTable, mysql.MySQLErrName[mysql.ErrNonuniqTable])
+	ErrCTERecursiveRequiresUnion             = terror.ClassOptimizerPlan.New(CodeCTERecursiveRequiresUnion, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresUnion])
+	ErrCTERecursiveRequiresNonRecursiveFirst = terror.ClassOptimizerPlan.New(CodeCTERecursiveRequiresNonRecursiveFirst, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresNonRecursiveFirst])
+	ErrCTERecursiveForbidsAggregation        = terror.ClassOptimizerPlan.New(CodeCTERecursiveForbidsAggregation, mysql.MySQLErrName[mysql.ErrCTERecursiveForbidsAggregation])
+	ErrCTERecursiveRequiresSingleReference   = terror.ClassOptimizerPlan.New(CodeCTERecursiveRequiresSingleReference, mysql.MySQLErrName[mysql.ErrCTERecursiveRequiresSingleReference])
+	ErrInvalidNonTransactionalDML            = terror.ClassOptimizerPlan.New(CodeInvalidNonTransactionalDML, "Invalid non-transactional DML: %s")
+	ErrInapplicableHint                      = terror.ClassOptimizerPlan.New(CodeInapplicableHint, "Optimizer hint %s is inapplicable, %s")
+	ErrWarnConflictingHint                   = terror.ClassOptimizerPlan.New(CodeWarnConflictingHint, mysql.MySQLErrName[mysql.ErrWarnConflictingHint])
+	ErrUnresolvedHintName                    = terror.ClassOptimizerPlan.New(CodeUnresolvedHintName, mysql.MySQLErrName[mysql.ErrUnresolvedHintName])
+	ErrKeyDoesNotExist                       = terror.ClassOptimizerPlan.New(CodeKeyDoesNotExist, mysql.MySQLErrName[mysql.ErrKeyDoesNotExits])
 )
 
 // Error codes.
 const (
-	CodeUnsupportedType    terror.ErrCode = 1
-	SystemInternalError    terror.ErrCode = 2
-	CodeAlterAutoID        terror.ErrCode = 3
-	CodeAnalyzeMissIndex   terror.ErrCode = 4
-	CodeAmbiguous          terror.ErrCode = 1052
-	CodeUnknownColumn      terror.ErrCode = 1054
-	CodeWrongArguments     terror.ErrCode = 1210
-	CodeBadGeneratedColumn terror.ErrCode = mysql.ErrBadGeneratedColumn
+	CodeUnsupportedType                       terror.ErrCode = 1
+	SystemInternalError                       terror.ErrCode = 2
+	CodeAlterAutoID                           terror.ErrCode = 3
+	CodeAnalyzeMissIndex                      terror.ErrCode = 4
+	CodeInvalidNonTransactionalDML            terror.ErrCode = 5
+	CodeInapplicableHint                      terror.ErrCode = 6
+	CodeAmbiguous                             terror.ErrCode = 1052
+	CodeUnknownColumn                         terror.ErrCode = 1054
+	CodeWrongArguments                        terror.ErrCode = 1210
+	CodeBadGeneratedColumn                    terror.ErrCode = mysql.ErrBadGeneratedColumn
+	CodeUnknownExplainFormat                  terror.ErrCode = mysql.ErrUnknownExplainFormat
+	CodeViewWrongList                         terror.ErrCode = mysql.ErrViewWrongList
+	CodeViewInvalid                           terror.ErrCode = mysql.ErrViewInvalid
+	CodeViewRecursive                         terror.ErrCode = mysql.ErrViewRecursive
+	CodeNonUpdatableTable                     terror.ErrCode = mysql.ErrNonUpdatableTable
+	CodeNonInsertableTable                    terror.ErrCode = mysql.ErrNonInsertableTable
+	CodeDupFieldName                          terror.ErrCode = mysql.ErrDupFieldName
+	CodeNotSupportedYet                       terror.ErrCode = mysql.ErrNotSupportedYet
+	CodeWarnConflictingHint                   terror.ErrCode = mysql.ErrWarnConflictingHint
+	CodeUnresolvedHintName                    terror.ErrCode = mysql.ErrUnresolvedHintName
+	CodeKeyDoesNotExist                       terror.ErrCode = mysql.ErrKeyDoesNotExits
+	CodeWindowInvalidWindowFuncUse            terror.ErrCode = mysql.ErrWindowInvalidWindowFuncUse
+	CodeWindowFrameStartIllegal               terror.ErrCode = mysql.ErrWindowFrameStartIllegal
+	CodeWindowFrameEndIllegal                 terror.ErrCode = mysql.ErrWindowFrameEndIllegal
+	CodeWindowFrameIllegal                    terror.ErrCode = mysql.ErrWindowFrameIllegal
+	CodeWindowRangeFrameOrderType             terror.ErrCode = mysql.ErrWindowRangeFrameOrderType
+	CodeNonUniqTable                          terror.ErrCode = mysql.ErrNonuniqTable
+	CodeCTERecursiveRequiresUnion             terror.ErrCode = mysql.ErrCTERecursiveRequiresUnion
+	CodeCTERecursiveRequiresNonRecursiveFirst terror.ErrCode = mysql.ErrCTERecursiveRequiresNonRecursiveFirst
+	CodeCTERecursiveForbidsAggregation        terror.ErrCode = mysql.ErrCTERecursiveForbidsAggregation
+	CodeCTERecursiveRequiresSingleReference   terror.ErrCode = mysql.ErrCTERecursiveRequiresSingleReference
 )
 
 func init() {
 	tableMySQLErrCodes := map[terror.ErrCode]uint16{
-		CodeUnknownColumn:      mysql.ErrBadField,
-		CodeAmbiguous:          mysql.ErrNonUniq,
-		CodeWrongArguments:     mysql.ErrWrongArguments,
-		CodeBadGeneratedColumn: mysql.ErrBadGeneratedColumn,
+		CodeUnknownColumn:                         mysql.ErrBadField,
+		CodeAmbiguous:                             mysql.ErrNonUniq,
+		CodeWrongArguments:                        mysql.ErrWrongArguments,
+		CodeBadGeneratedColumn:                    mysql.ErrBadGeneratedColumn,
+		CodeUnknownExplainFormat:                  mysql.ErrUnknownExplainFormat,
+		CodeViewWrongList:                         mysql.ErrViewWrongList,
+		CodeViewInvalid:                           mysql.ErrViewInvalid,
+		CodeViewRecursive:                         mysql.ErrViewRecursive,
+		CodeNonUpdatableTable:                     mysql.ErrNonUpdatableTable,
+		CodeNonInsertableTable:                    mysql.ErrNonInsertableTable,
+		CodeDupFieldName:                          mysql.ErrDupFieldName,
+		CodeNotSupportedYet:                       mysql.ErrNotSupportedYet,
+		CodeWarnConflictingHint:                   mysql.ErrWarnConflictingHint,
+		CodeUnresolvedHintName:                    mysql.ErrUnresolvedHintName,
+		CodeKeyDoesNotExist:                       mysql.ErrKeyDoesNotExits,
+		CodeWindowInvalidWindowFuncUse:            mysql.ErrWindowInvalidWindowFuncUse,
+		CodeWindowFrameStartIllegal:               mysql.ErrWindowFrameStartIllegal,
+		CodeWindowFrameEndIllegal:                 mysql.ErrWindowFrameEndIllegal,
+		CodeWindowFrameIllegal:                    mysql.ErrWindowFrameIllegal,
+		CodeWindowRangeFrameOrderType:             mysql.ErrWindowRangeFrameOrderType,
+		CodeNonUniqTable:                          mysql.ErrNonuniqTable,
+		CodeCTERecursiveRequiresUnion:             mysql.ErrCTERecursiveRequiresUnion,
+		CodeCTERecursiveRequiresNonRecursiveFirst: mysql.ErrCTERecursiveRequiresNonRecursiveFirst,
+		CodeCTERecursiveForbidsAggregation:        mysql.ErrCTERecursiveForbidsAggregation,
+		CodeCTERecursiveRequiresSingleReference:   mysql.ErrCTERecursiveRequiresSingleReference,
+		// MySQL reports the optimizer hints it ignores by the warnings of ER_PARSE_ERROR.
+		CodeInapplicableHint: mysql.ErrParse,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassOptimizerPlan] = tableMySQLErrCodes
 }
@@ -70,9 +140,62 @@ type visitInfo struct {
 	column    string
 }
 
+// hintTableInfo is a table in the table list of a hint, matched is set when the table is found in the query.
+type hintTableInfo struct {
+	name    model.CIStr
+	matched bool
+}
+
+func tableNames2HintTableInfo(tableNames []model.CIStr) []hintTableInfo {
+	if len(tableNames) == 0 {
+		return nil
+	}
+	hintTables := make([]hintTableInfo, 0, len(tableNames))
+	for _, tableName := range tableNames {
+		hintTables = append(hintTables, hintTableInfo{name: tableName})
+	}
+	return hintTables
+}
+
+// indexHintInfo is a USE_INDEX or IGNORE_INDEX hint of a table.
+type indexHintInfo struct {
+	tblName   model.CIStr
+	indexHint *ast.IndexHint
+	matched   bool
+}
+
+const (
+	preferHashAgg = 1 << iota
+	preferStreamAgg
+)
+
 type tableHintInfo struct {
-	indexNestedLoopJoinTables []model.CIStr
-	sortMergeJoinTables       []model.CIStr
+	indexNestedLoopJoinTables []hintTableInfo
+	sortMergeJoinTables       []hintTableInfo
+	hashJoinTables            []hintTableInfo
+	// tikvTables are the tables of READ_FROM_STORAGE(TIKV[...]) hints.
+	tikvTables    []hintTableInfo
+	indexHintList []indexHintInfo
+	// preferAggType is preferHashAgg or preferStreamAgg.
+	preferAggType uint
+}
+
+// matchTableName returns true if any of tableNames is on the list, the matched entries are marked.
+func matchTableName(tableNames []*model.CIStr, hintTables []hintTableInfo) bool {
+	hintMatched := false
+	for _, tableName := range tableNames {
+		if tableName == nil {
+			continue
+		}
+		for i, curEntry := range hintTables {
+			if curEntry.name.L == tableName.L {
+				hintTables[i].matched = true
+				hintMatched = true
+				break
+			}
+		}
+	}
+	return hintMatched
 }
 
 func (info *tableHintInfo) ifPreferMergeJoin(tableNames ...*model.CIStr) bool {
@@ -83,31 +206,41 @@ func (info *tableHintInfo) ifPreferMergeJoin(tableNames ...*model.CIStr) bool {
 	// Which it joins on with depend on sequence of traverse
 	// and without reorder, user might adjust themselves.
 	// This is similar to MySQL hints.
-	for _, tableName := range tableNames {
-		if tableName == nil {
-			continue
-		}
-		for _, curEntry := range info.sortMergeJoinTables {
-			if curEntry.L == tableName.L {
-				return true
-			}
-		}
-	}
-	return false
+	return matchTableName(tableNames, info.sortMergeJoinTables)
 }
 
 func (info *tableHintInfo) ifPreferINLJ(tableNames ...*model.CIStr) bool {
-	for _, tableName := range tableNames {
-		if tableName == nil {
-			continue
+	return matchTableName(tableNames, info.indexNestedLoopJoinTables)
+}
+
+func (info *tableHintInfo) ifPreferHashJoin(tableNames ...*model.CIStr) bool {
+	return matchTableName(tableNames, info.hashJoinTables)
+}
+
+// unmatchedTableHintWarnings returns the warnings of the tables in the hints which aren't found in the query.
+func (info *tableHintInfo) unmatchedTableHintWarnings() []error {
+	var warnings []error
+	collect := func(hintName string, hintTables []hintTableInfo) {
+		for _, hintTable := range hintTables {
+			if !hintTable.matched {
+				warnings = append(warnings, ErrUnresolvedHintName.GenByArgs(hintTable.name.O, hintName))
+			}
 		}
-		for _, curEntry := range info.indexNestedLoopJoinTables {
-			if curEntry.L == tableName.L {
-				return true
+	}
+	collect(TiDBMergeJoin, info.sortMergeJoinTables)
+	collect(TiDBIndexNestedLoopJoin, info.indexNestedLoopJoinTables)
+	collect(HintHashJoin, info.hashJoinTables)
+	collect(HintReadFromStorage, info.tikvTables)
+	for _, hint := range info.indexHintList {
+		if !hint.matched {
+			hintName := HintUseIndex
+			if hint.indexHint.HintType == ast.HintIgnore {
+				hintName = HintIgnoreIndex
 			}
+			warnings = append(warnings, ErrUnresolvedHintName.GenByArgs(hint.tblName.O, hintName))
 		}
 	}
-	return false
+	return warnings
 }
 
 // planBuilder builds Plan from an ast.Node.
@@ -123,10 +256,21 @@ type planBuilder struct {
 	inUpdateStmt bool
 	// colMapper stores the column that must be pre-resolved.
 	colMapper map[*ast.ColumnNameExpr]int
+	// windowMapper maps the window functions to the columns of the window plan that computes them.
+	windowMapper map[*ast.WindowFuncExpr]int
 	// Collect the visit information for privilege check.
 	visitInfo     []visitInfo
 	tableHintInfo []tableHintInfo
 	optFlag       uint64
+	// ctes are the common table expressions visible to the query being built.
+	ctes []*cteInfo
+	// topSelect is the statement being built if it's a SELECT, the statement level hints are only supported in it.
+	topSelect *ast.SelectStmt
+	// noAggPushDown is set by the NO_AGG_PUSH_DOWN hint.
+	noAggPushDown bool
+	// expandingViews are the views being expanded, in the format of "db.view", they are used to detect the
+	// view recursion.
+	expandingViews map[string]struct{}
 }
 
 func (b *planBuilder) build(node ast.Node) Plan {
@@ -149,6 +293,7 @@ func (b *planBuilder) build(node ast.Node) Plan {
 	case *ast.PrepareStmt:
 		return b.buildPrepare(x)
 	case *ast.SelectStmt:
+		b.topSelect = x
 		return b.buildSelect(x)
 	case *ast.UnionStmt:
 		return b.buildUnion(x)
@@ -162,9 +307,12 @@ func (b *planBuilder) build(node ast.Node) Plan {
 		return b.buildSet(x)
 	case *ast.AnalyzeTableStmt:
 		return b.buildAnalyze(x)
+	case *ast.NonTransactionalDMLStmt:
+		return b.buildNonTransactionalDML(x)
 	case *ast.BinlogStmt, *ast.FlushStmt, *ast.UseStmt,
 		*ast.BeginStmt, *ast.CommitStmt, *ast.RollbackStmt, *ast.CreateUserStmt, *ast.SetPwdStmt,
-		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt:
+		*ast.GrantStmt, *ast.DropUserStmt, *ast.AlterUserStmt, *ast.RevokeStmt, *ast.KillStmt, *ast.DropStatsStmt,
+		*ast.CreateBindingStmt, *ast.DropBindingStmt:
 		return b.buildSimple(node.(ast.StmtNode))
 	case ast.DDLNode:
 		return b.buildDDL(x)
@@ -259,6 +407,16 @@ func (b *planBuilder) detectSelectAgg(sel *ast.SelectStmt) bool {
 	return false
 }
 
+// Detect window function in select fields.
+func (b *planBuilder) detectSelectWindow(sel *ast.SelectStmt) bool {
+	for _, f := range sel.Fields.Fields {
+		if f.Expr != nil && ast.HasWindowFlag(f.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
 func availableIndices(hints []*ast.IndexHint, tableInfo *model.TableInfo) (indices []*model.IndexInfo, includeTableScan bool) {
 	var usableHints []*ast.IndexHint
 	for _, hint := range hints {
@@ -362,12 +520,59 @@ func (b *planBuilder) buildAdmin(as *ast.AdminStmt) Plan {
 	case ast.AdminShowDDL:
 		p = &ShowDDL{}
 		p.SetSchema(buildShowDDLFields())
+	case ast.AdminShowDDLJobs:
+		p = &ShowDDLJobs{}
+		p.SetSchema(buildShowDDLJobsFields())
+	case ast.AdminCancelDDLJobs:
+		p = &CancelDDLJobs{JobIDs: as.JobIDs}
+		p.SetSchema(buildDDLJobsResultFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminPauseDDLJobs:
+		p = &PauseDDLJobs{JobIDs: as.JobIDs}
+		p.SetSchema(buildDDLJobsResultFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminResumeDDLJobs:
+		p = &ResumeDDLJobs{JobIDs: as.JobIDs}
+		p.SetSchema(buildDDLJobsResultFields())
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case ast.AdminCheckIndex:
+		tn := as.Tables[0]
+		if b.checkAdminIndex(tn, as.Index) {
+			p = &CheckIndex{Table: tn, IndexName: as.Index, HandleRanges: as.HandleRanges}
+			p.SetSchema(buildCheckIndexFields())
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, tn.Schema.L, tn.Name.L, "")
+		}
+	case ast.AdminRecoverIndex:
+		tn := as.Tables[0]
+		if b.checkAdminIndex(tn, as.Index) {
+			p = &RecoverIndex{Table: tn, IndexName: as.Index}
+			p.SetSchema(buildRecoverIndexFields())
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
+	case ast.AdminCleanupIndex:
+		tn := as.Tables[0]
+		if b.checkAdminIndex(tn, as.Index) {
+			p = &CleanupIndex{Table: tn, IndexName: as.Index}
+			p.SetSchema(buildCleanupIndexFields())
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
 	default:
 		b.err = ErrUnsupportedType.Gen("Unsupported type %T", as)
 	}
 	return p
 }
 
+// checkAdminIndex checks that the table of the admin index statements has a public index named idxName.
+func (b *planBuilder) checkAdminIndex(tn *ast.TableName, idxName string) bool {
+	for _, idx := range tn.TableInfo.Indices {
+		if idx.Name.L == strings.ToLower(idxName) && idx.State == model.StatePublic {
+			return true
+		}
+	}
+	b.err = ErrKeyDoesNotExist.GenByArgs(idxName, tn.Name.O)
+	return false
+}
+
 // getColsInfo returns the info of index columns, normal columns and primary key.
 func getColsInfo(tn *ast.TableName) (indicesInfo []*model.IndexInfo, colsInfo []*model.ColumnInfo, pkCol *model.ColumnInfo) {
 	tbl := tn.TableInfo
@@ -440,6 +645,13 @@ func (b *planBuilder) buildAnalyzeIndex(as *ast.AnalyzeTableStmt) Plan {
 }
 
 func (b *planBuilder) buildAnalyze(as *ast.AnalyzeTableStmt) Plan {
+	for _, tbl := range as.TableNames {
+		// The statistics are collected by the table ID, but the rows of a partitioned table are stored by the partition IDs.
+		if tbl.TableInfo.Partition != nil {
+			b.err = ErrNotSupportedYet.GenByArgs("ANALYZE on partitioned tables")
+			return nil
+		}
+	}
 	if len(as.IndexNames) == 0 {
 		return b.buildAnalyzeTable(as)
 	}
@@ -459,6 +671,56 @@ func buildShowDDLFields() *expression.Schema {
 	return schema
 }
 
+func buildShowDDLJobsFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 10)...)
+	schema.Append(buildColumn("", "JOB_ID", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "SCHEMA_ID", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "TABLE_ID", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "JOB_TYPE", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "SCHEMA_STATE", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "ROW_COUNT", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "PROGRESS", mysql.TypeDouble, 8))
+	schema.Append(buildColumn("", "START_TIME", mysql.TypeDatetime, 19))
+	schema.Append(buildColumn("", "LAST_UPDATE_TIME", mysql.TypeDatetime, 19))
+	schema.Append(buildColumn("", "STATE", mysql.TypeVarchar, 64))
+
+	return schema
+}
+
+// buildDDLJobsResultFields builds the schema of the cancel, pause and resume DDL jobs statements.
+func buildDDLJobsResultFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema.Append(buildColumn("", "JOB_ID", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "RESULT", mysql.TypeVarchar, 128))
+
+	return schema
+}
+
+func buildCheckIndexFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 3)...)
+	schema.Append(buildColumn("", "HANDLE", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "INDEX_VALUES", mysql.TypeVarchar, 256))
+	schema.Append(buildColumn("", "RECORD_VALUES", mysql.TypeVarchar, 256))
+
+	return schema
+}
+
+func buildRecoverIndexFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema.Append(buildColumn("", "ADDED_COUNT", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "SCAN_COUNT", mysql.TypeLonglong, 4))
+
+	return schema
+}
+
+func buildCleanupIndexFields() *expression.Schema {
+	schema := expression.NewSchema(make([]*expression.Column, 0, 2)...)
+	schema.Append(buildColumn("", "REMOVED_COUNT", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "SCAN_COUNT", mysql.TypeLonglong, 4))
+
+	return schema
+}
+
 func buildColumn(tableName, name string, tp byte, size int) *expression.Column {
 	cs, cl := types.DefaultCharsetForType(tp)
 	flag := mysql.UnsignedFlag
@@ -515,6 +777,13 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 		User:   show.User,
 	}.init(b.allocator, b.ctx)
 	resultPlan = p
+	if show.Tp == ast.ShowBindings {
+		p.GlobalScope = show.GlobalScope
+	}
+	if show.Tp == ast.ShowCreateView {
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.ShowViewPriv, show.Table.Schema.L, show.Table.Name.L, "")
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, show.Table.Schema.L, show.Table.Name.L, "")
+	}
 	switch show.Tp {
 	case ast.ShowProcedureStatus:
 		p.SetSchema(buildShowProcedureSchema())
@@ -559,6 +828,114 @@ func (b *planBuilder) buildShow(show *ast.ShowStmt) Plan {
 	return resultPlan
 }
 
+func (b *planBuilder) buildNonTransactionalDML(stmt *ast.NonTransactionalDMLStmt) Plan {
+	if stmt.Limit == 0 {
+		b.err = ErrInvalidNonTransactionalDML.GenByArgs("the batch size must be positive")
+		return nil
+	}
+	var (
+		refs     *ast.TableRefsClause
+		priv     mysql.PrivilegeType
+		hasOrder bool
+		hasLimit bool
+	)
+	switch x := stmt.DMLStmt.(type) {
+	case *ast.DeleteStmt:
+		if x.IsMultiTable {
+			b.err = ErrInvalidNonTransactionalDML.GenByArgs("only the single table DELETE is supported")
+			return nil
+		}
+		refs, priv, hasOrder, hasLimit = x.TableRefs, mysql.DeletePriv, x.Order != nil, x.Limit != nil
+	case *ast.UpdateStmt:
+		refs, priv, hasOrder, hasLimit = x.TableRefs, mysql.UpdatePriv, x.Order != nil, x.Limit != nil
+	}
+	if hasOrder || hasLimit {
+		b.err = ErrInvalidNonTransactionalDML.GenByArgs("ORDER BY or LIMIT is not supported")
+		return nil
+	}
+	var ts *ast.TableSource
+	if join := refs.TableRefs; join.Right == nil {
+		ts, _ = join.Left.(*ast.TableSource)
+	}
+	var tn *ast.TableName
+	if ts != nil {
+		tn, _ = ts.Source.(*ast.TableName)
+	}
+	if tn == nil {
+		b.err = ErrInvalidNonTransactionalDML.GenByArgs("only the DML on a single table is supported")
+		return nil
+	}
+
+	dbName := tn.Schema.L
+	if dbName == "" {
+		dbName = b.ctx.GetSessionVars().CurrentDB
+	}
+	col := stmt.ShardColumn
+	tblName := tn.Name
+	if ts.AsName.L != "" {
+		tblName = ts.AsName
+	}
+	if (col.Schema.L != "" && col.Schema.L != strings.ToLower(dbName)) || (col.Table.L != "" && col.Table.L != tblName.L) {
+		b.err = ErrUnknownColumn.GenByArgs(col.Name.O, "batch on")
+		return nil
+	}
+	var shardCol *model.ColumnInfo
+	for _, c := range tn.TableInfo.Columns {
+		if c.Name.L == col.Name.L && c.State == model.StatePublic {
+			shardCol = c
+			break
+		}
+	}
+	if shardCol == nil {
+		b.err = ErrUnknownColumn.GenByArgs(col.Name.O, "batch on")
+		return nil
+	}
+	// The batches are read by the ranges on the shard column, so it must be the handle or an index prefix.
+	var shardIdx *model.IndexInfo
+	isHandle := tn.TableInfo.PKIsHandle && mysql.HasPriKeyFlag(shardCol.Flag)
+	if !isHandle {
+		for _, idx := range tn.TableInfo.Indices {
+			if idx.State == model.StatePublic && idx.Columns[0].Name.L == shardCol.Name.L {
+				shardIdx = idx
+				break
+			}
+		}
+	}
+	if !isHandle && shardIdx == nil {
+		b.err = ErrInvalidNonTransactionalDML.GenByArgs(fmt.Sprintf("column %s is neither the handle nor the first column of an index", shardCol.Name.O))
+		return nil
+	}
+	// The batches walk the shard column forward, the rows would be read again by the later batches if the
+	// shard column is updated.
+	if upd, ok := stmt.DMLStmt.(*ast.UpdateStmt); ok {
+		for _, assign := range upd.List {
+			if assign.Column.Name.L == shardCol.Name.L && (assign.Column.Table.L == "" || assign.Column.Table.L == tblName.L) {
+				b.err = ErrInvalidNonTransactionalDML.GenByArgs(fmt.Sprintf("the shard column %s can't be updated", shardCol.Name.O))
+				return nil
+			}
+		}
+	}
+
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SelectPriv, dbName, tn.Name.L, "")
+	b.visitInfo = appendVisitInfo(b.visitInfo, priv, dbName, tn.Name.L, "")
+
+	p := &NonTransactionalDML{
+		Stmt:        stmt,
+		Table:       tn,
+		TableAsName: ts.AsName,
+		ShardColumn: shardCol,
+		ShardIndex:  shardIdx,
+	}
+	schema := expression.NewSchema(make([]*expression.Column, 0, 5)...)
+	schema.Append(buildColumn("", "BATCH", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "START", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "END", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn("", "AFFECTED_ROWS", mysql.TypeLonglong, 4))
+	schema.Append(buildColumn("", "ERROR", mysql.TypeVarchar, 256))
+	p.SetSchema(schema)
+	return p
+}
+
 func (b *planBuilder) buildSimple(node ast.StmtNode) Plan {
 	p := &Simple{Statement: node}
 	p.SetSchema(expression.NewSchema())
@@ -570,6 +947,14 @@ func (b *planBuilder) buildSimple(node ast.StmtNode) Plan {
 		b.visitInfo = collectVisitInfoFromGrantStmt(b.visitInfo, raw)
 	case *ast.SetPwdStmt, *ast.RevokeStmt, *ast.KillStmt:
 		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+	case *ast.CreateBindingStmt:
+		if raw.GlobalScope {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
+	case *ast.DropBindingStmt:
+		if raw.GlobalScope {
+			b.visitInfo = appendVisitInfo(b.visitInfo, mysql.SuperPriv, "", "", "")
+		}
 	}
 	return p
 }
@@ -635,6 +1020,10 @@ func (b *planBuilder) buildInsert(insert *ast.InsertStmt) Plan {
 		return nil
 	}
 	tableInfo := tn.TableInfo
+	if tableInfo.IsView() {
+		b.err = ErrNonInsertableTable.GenByArgs(tn.Name.O, "INSERT")
+		return nil
+	}
 	schema := expression.TableInfo2Schema(tableInfo)
 	tableInPlan, ok := b.is.TableByID(tableInfo.ID)
 	if !ok {
@@ -851,6 +1240,11 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 				table:     v.ReferTable.Name.L,
 			})
 		}
+	case *ast.CreateViewStmt:
+		b.buildCreateView(v)
+		if b.err != nil {
+			return nil
+		}
 	case *ast.DropDatabaseStmt:
 		b.visitInfo = append(b.visitInfo, visitInfo{
 			privilege: mysql.DropPriv,
@@ -894,6 +1288,42 @@ func (b *planBuilder) buildDDL(node ast.DDLNode) Plan {
 	return p
 }
 
+// buildCreateView checks the select statement of the view and collects the privileges it needs.
+func (b *planBuilder) buildCreateView(v *ast.CreateViewStmt) {
+	// The view being created is treated as being expanded, so the select statement which references it,
+	// directly or through other views, is rejected.
+	schemaName := v.ViewName.Schema
+	if schemaName.L == "" {
+		schemaName = model.NewCIStr(b.ctx.GetSessionVars().CurrentDB)
+	}
+	b.pushExpandingView(schemaName, v.ViewName.Name)
+	p := b.buildResultSetNode(v.Select.(ast.ResultSetNode))
+	if b.err != nil {
+		return
+	}
+	schema := p.Schema()
+	if len(v.Cols) > 0 && len(v.Cols) != schema.Len() {
+		b.err = ErrViewWrongList
+		return
+	}
+	names := make(map[string]struct{}, schema.Len())
+	for i, col := range schema.Columns {
+		name := col.ColName
+		if len(v.Cols) > 0 {
+			name = v.Cols[i]
+		}
+		if _, ok := names[name.L]; ok {
+			b.err = ErrDupFieldName.GenByArgs(name.O)
+			return
+		}
+		names[name.L] = struct{}{}
+	}
+	b.visitInfo = appendVisitInfo(b.visitInfo, mysql.CreateViewPriv, v.ViewName.Schema.L, v.ViewName.Name.L, "")
+	if v.OrReplace {
+		b.visitInfo = appendVisitInfo(b.visitInfo, mysql.DropPriv, v.ViewName.Schema.L, v.ViewName.Name.L, "")
+	}
+}
+
 func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	if show, ok := explain.Stmt.(*ast.ShowStmt); ok {
 		return b.buildShow(show)
@@ -903,8 +1333,22 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 		b.err = errors.Trace(err)
 		return nil
 	}
-	p := &Explain{StmtPlan: targetPlan}
+	p := &Explain{StmtPlan: targetPlan, Format: explain.Format, Analyze: explain.Analyze}
 	addChild(p, targetPlan)
+	if explain.Analyze {
+		p.SetSchema(buildExplainAnalyzeSchema())
+		return p
+	}
+	switch p.Format {
+	case ast.ExplainFormatRow:
+		p.SetSchema(buildExplainRowSchema())
+		return p
+	case ast.ExplainFormatJSON, "":
+		p.Format = ast.ExplainFormatJSON
+	default:
+		b.err = ErrUnknownExplainFormat.GenByArgs(p.Format)
+		return nil
+	}
 	schema := expression.NewSchema(make([]*expression.Column, 0, 3)...)
 	schema.Append(&expression.Column{
 		ColName: model.NewCIStr("ID"),
@@ -922,6 +1366,28 @@ func (b *planBuilder) buildExplain(explain *ast.ExplainStmt) Plan {
 	return p
 }
 
+func buildExplainRowSchema() *expression.Schema {
+	tblName := "EXPLAIN"
+	schema := expression.NewSchema(make([]*expression.Column, 0, 4)...)
+	schema.Append(buildColumn(tblName, "id", mysql.TypeVarchar, 256))
+	schema.Append(buildColumn(tblName, "count", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn(tblName, "task", mysql.TypeVarchar, 16))
+	schema.Append(buildColumn(tblName, "operator info", mysql.TypeVarchar, 1024))
+	return schema
+}
+
+func buildExplainAnalyzeSchema() *expression.Schema {
+	tblName := "EXPLAIN"
+	schema := expression.NewSchema(make([]*expression.Column, 0, 6)...)
+	schema.Append(buildColumn(tblName, "ID", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn(tblName, "ParentID", mysql.TypeVarchar, 64))
+	schema.Append(buildColumn(tblName, "EstRows", mysql.TypeDouble, 22))
+	schema.Append(buildColumn(tblName, "ActRows", mysql.TypeLonglong, 21))
+	schema.Append(buildColumn(tblName, "NextCalls", mysql.TypeLonglong, 21))
+	schema.Append(buildColumn(tblName, "Time", mysql.TypeVarchar, 64))
+	return schema
+}
+
 func buildShowProcedureSchema() *expression.Schema {
 	tblName := "ROUTINES"
 	schema := expression.NewSchema(make([]*expression.Column, 0, 11)...)
@@ -1053,8 +1519,15 @@ func buildShowSchema(s *ast.ShowStmt) (schema *expression.Schema) {
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong}
 	case ast.ShowCreateTable:
 		names = []string{"Table", "Create Table"}
+	case ast.ShowCreateView:
+		names = []string{"View", "Create View", "character_set_client", "collation_connection"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
+	case ast.ShowBindings:
+		names = []string{"Original_sql", "Bind_sql", "Default_db", "Status", "Create_time", "Update_time", "Charset",
+			"Collation"}
+		ftypes = []byte{mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeDatetime,
+			mysql.TypeDatetime, mysql.TypeVarchar, mysql.TypeVarchar}
 	case ast.ShowGrants:
 		names = []string{fmt.Sprintf("Grants for %s", s.User)}
 	case ast.ShowIndex:
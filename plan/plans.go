@@ -26,6 +26,32 @@ type ShowDDL struct {
 	basePlan
 }
 
+// ShowDDLJobs is for showing DDL job list.
+type ShowDDLJobs struct {
+	basePlan
+}
+
+// CancelDDLJobs represents a cancel DDL jobs plan.
+type CancelDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
+// PauseDDLJobs represents a pause DDL jobs plan.
+type PauseDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
+// ResumeDDLJobs represents a resume DDL jobs plan.
+type ResumeDDLJobs struct {
+	basePlan
+
+	JobIDs []int64
+}
+
 // CheckTable is used for checking table data, built from the 'admin check table' statement.
 type CheckTable struct {
 	basePlan
@@ -33,6 +59,46 @@ type CheckTable struct {
 	Tables []*ast.TableName
 }
 
+// CheckIndex is used for checking an index over handle ranges, built from the 'admin check index' statement.
+type CheckIndex struct {
+	basePlan
+
+	Table        *ast.TableName
+	IndexName    string
+	HandleRanges []ast.HandleRange
+}
+
+// RecoverIndex is used for adding the missing index entries, built from the 'admin recover index' statement.
+type RecoverIndex struct {
+	basePlan
+
+	Table     *ast.TableName
+	IndexName string
+}
+
+// CleanupIndex is used for deleting the dangling index entries, built from the 'admin cleanup index' statement.
+type CleanupIndex struct {
+	basePlan
+
+	Table     *ast.TableName
+	IndexName string
+}
+
+// NonTransactionalDML splits a DELETE or UPDATE statement into batches by the shard column, it's built from
+// the non-transactional DML statement.
+type NonTransactionalDML struct {
+	basePlan
+
+	Stmt *ast.NonTransactionalDMLStmt
+	// Table is the table of the DML statement, and TableAsName is its alias.
+	Table       *ast.TableName
+	TableAsName model.CIStr
+	// ShardColumn is the column the rows are split by, it's the handle or the first column of an index.
+	ShardColumn *model.ColumnInfo
+	// ShardIndex is the index the batches are read by, it's nil if ShardColumn is the handle.
+	ShardIndex *model.IndexInfo
+}
+
 // SelectLock represents a select lock plan.
 type SelectLock struct {
 	*basePlan
@@ -171,4 +237,6 @@ type Explain struct {
 	basePlan
 
 	StmtPlan Plan
+	Format   string
+	Analyze  bool
 }
@@ -127,6 +127,7 @@ func (p *PhysicalIndexJoin) ResolveIndices() {
 
 // ResolveIndices implements Plan interface.
 func (p *PhysicalUnionScan) ResolveIndices() {
+	p.basePlan.ResolveIndices()
 	for _, expr := range p.Conditions {
 		expr.ResolveIndices(p.children[0].Schema())
 	}
@@ -201,6 +202,32 @@ func (p *TopN) ResolveIndices() {
 	}
 }
 
+// ResolveIndices implements Plan interface.
+func (p *LogicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	resolveWindowIndices(p.WindowFuncDescs, p.PartitionBy, p.OrderBy, p.children[0].Schema())
+}
+
+// ResolveIndices implements Plan interface.
+func (p *PhysicalWindow) ResolveIndices() {
+	p.basePlan.ResolveIndices()
+	resolveWindowIndices(p.WindowFuncDescs, p.PartitionBy, p.OrderBy, p.children[0].Schema())
+}
+
+func resolveWindowIndices(descs []*WindowFuncDesc, partitionBy, orderBy []*ByItems, schema *expression.Schema) {
+	for _, desc := range descs {
+		for _, arg := range desc.Args {
+			arg.ResolveIndices(schema)
+		}
+	}
+	for _, item := range partitionBy {
+		item.Expr.ResolveIndices(schema)
+	}
+	for _, item := range orderBy {
+		item.Expr.ResolveIndices(schema)
+	}
+}
+
 // ResolveIndices implements Plan interface.
 func (p *LogicalApply) ResolveIndices() {
 	p.LogicalJoin.ResolveIndices()
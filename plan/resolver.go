@@ -35,6 +35,14 @@ func ResolveName(node ast.Node, info infoschema.InfoSchema, ctx context.Context)
 	return errors.Trace(resolver.Err)
 }
 
+// resolveNameWithSchema resolves names in node with defaultSchema as the default database,
+// it's used to resolve the select statement of a view, which belongs to the database of the view.
+func resolveNameWithSchema(node ast.Node, info infoschema.InfoSchema, defaultSchema model.CIStr, ctx context.Context) error {
+	resolver := nameResolver{Info: info, Ctx: ctx, DefaultSchema: defaultSchema}
+	node.Accept(&resolver)
+	return errors.Trace(resolver.Err)
+}
+
 // MockResolveName only serves for test.
 func MockResolveName(node ast.Node, info infoschema.InfoSchema, defaultSchema string, ctx context.Context) error {
 	resolver := nameResolver{Info: info, Ctx: ctx, DefaultSchema: model.NewCIStr(defaultSchema)}
@@ -108,6 +116,10 @@ type resolverContext struct {
 	inShow bool
 	// When visiting create/alter table statement.
 	inColumnOption bool
+	// When visiting the WITH RECURSIVE clause, a common table expression is visible in its own definition.
+	inRecursiveWith bool
+	// ctes are the common table expressions defined in the WITH clause of current statement.
+	ctes []*ast.CommonTableExpression
 }
 
 // currentContext gets the current resolverContext.
@@ -184,8 +196,15 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 	case *ast.CreateTableStmt:
 		nr.pushContext()
 		nr.currentContext().inCreateOrDropTable = true
+	case *ast.CreateViewStmt:
+		nr.pushContext()
+		nr.currentContext().inCreateOrDropTable = true
 	case *ast.ColumnOption:
 		nr.currentContext().inColumnOption = true
+	case *ast.CommonTableExpression:
+		if nr.currentContext().inRecursiveWith {
+			nr.addCTE(v)
+		}
 	case *ast.DeleteStmt:
 		nr.pushContext()
 	case *ast.DeleteTableList:
@@ -238,6 +257,8 @@ func (nr *nameResolver) Enter(inNode ast.Node) (outNode ast.Node, skipChildren b
 		nr.pushContext()
 	case *ast.UpdateStmt:
 		nr.pushContext()
+	case *ast.WithClause:
+		nr.currentContext().inRecursiveWith = v.IsRecursive
 	}
 	return inNode, false
 }
@@ -266,8 +287,12 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.CreateTableStmt:
 		nr.popContext()
+	case *ast.CreateViewStmt:
+		nr.popContext()
 	case *ast.ColumnOption:
 		nr.currentContext().inColumnOption = false
+	case *ast.CommonTableExpression:
+		nr.handleCTE(v)
 	case *ast.DeleteTableList:
 		nr.currentContext().inDeleteTableList = false
 	case *ast.DoStmt:
@@ -344,6 +369,8 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 		nr.popContext()
 	case *ast.UpdateStmt:
 		nr.popContext()
+	case *ast.WithClause:
+		nr.currentContext().inRecursiveWith = false
 	}
 	return inNode, nr.Err == nil
 }
@@ -351,8 +378,11 @@ func (nr *nameResolver) Leave(inNode ast.Node) (node ast.Node, ok bool) {
 // handleTableName looks up and sets the schema information and result fields for table name.
 func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 	if tn.Schema.L == "" {
-		sessionVars := nr.Ctx.GetSessionVars()
-		if sessionVars.CurrentDB == "" {
+		if cte := nr.findCTE(tn.Name); cte != nil {
+			nr.handleCTEName(tn, cte)
+			return
+		}
+		if nr.DefaultSchema.L == "" {
 			nr.Err = errors.Trace(ErrNoDB)
 			return
 		}
@@ -420,6 +450,87 @@ func (nr *nameResolver) handleTableName(tn *ast.TableName) {
 	return
 }
 
+// addCTE puts the common table expression in current resolverContext.
+func (nr *nameResolver) addCTE(cte *ast.CommonTableExpression) {
+	ctx := nr.currentContext()
+	for _, v := range ctx.ctes {
+		if v.Name.L == cte.Name.L {
+			nr.Err = ErrNonUniqTable.GenByArgs(cte.Name.O)
+			return
+		}
+	}
+	ctx.ctes = append(ctx.ctes, cte)
+}
+
+// handleCTE checks the column name list of the common table expression and makes it visible
+// to the following part of the statement.
+func (nr *nameResolver) handleCTE(cte *ast.CommonTableExpression) {
+	ctx := nr.currentContext()
+	if !ctx.inRecursiveWith {
+		nr.addCTE(cte)
+		if nr.Err != nil {
+			return
+		}
+	}
+	if len(cte.ColNameList) > 0 && len(cte.ColNameList) != len(cte.Query.Query.GetResultFields()) {
+		nr.Err = ErrViewWrongList
+	}
+}
+
+// findCTE looks up the common table expression from top to bottom in the context stack.
+func (nr *nameResolver) findCTE(name model.CIStr) *ast.CommonTableExpression {
+	for i := len(nr.contextStack) - 1; i >= 0; i-- {
+		for _, cte := range nr.contextStack[i].ctes {
+			if cte.Name.L == name.L {
+				return cte
+			}
+		}
+	}
+	return nil
+}
+
+// handleCTEName sets the result fields for the table name which refers to a common table expression.
+// A recursive reference appears in the definition of the common table expression itself,
+// so its result fields come from the first query block which must be non-recursive.
+func (nr *nameResolver) handleCTEName(tn *ast.TableName, cte *ast.CommonTableExpression) {
+	srcFields := cte.Query.Query.GetResultFields()
+	if srcFields == nil {
+		union, ok := cte.Query.Query.(*ast.UnionStmt)
+		if !ok {
+			nr.Err = ErrCTERecursiveRequiresUnion.GenByArgs(cte.Name.O)
+			return
+		}
+		srcFields = union.SelectList.Selects[0].GetResultFields()
+		if srcFields == nil {
+			nr.Err = ErrCTERecursiveRequiresNonRecursiveFirst.GenByArgs(cte.Name.O)
+			return
+		}
+	}
+	if len(cte.ColNameList) > 0 && len(cte.ColNameList) != len(srcFields) {
+		nr.Err = ErrViewWrongList
+		return
+	}
+	rfs := make([]*ast.ResultField, 0, len(srcFields))
+	tblInfo := &model.TableInfo{Name: tn.Name}
+	for i, f := range srcFields {
+		name := f.ColumnAsName
+		if name.L == "" {
+			name = f.Column.Name
+		}
+		if len(cte.ColNameList) > 0 {
+			name = cte.ColNameList[i]
+		}
+		rfs = append(rfs, &ast.ResultField{
+			Column:       &model.ColumnInfo{Name: name},
+			ColumnAsName: name,
+			Table:        tblInfo,
+			Expr:         f.Expr,
+			TableName:    tn,
+		})
+	}
+	tn.SetResultFields(rfs)
+}
+
 // handleTableSources checks name duplication
 // and puts the table source in current resolverContext.
 // Note:
@@ -938,6 +1049,8 @@ func (nr *nameResolver) fillShowFields(s *ast.ShowStmt) {
 			mysql.TypeVarchar, mysql.TypeVarchar, mysql.TypeLonglong}
 	case ast.ShowCreateTable:
 		names = []string{"Table", "Create Table"}
+	case ast.ShowCreateView:
+		names = []string{"View", "Create View", "character_set_client", "collation_connection"}
 	case ast.ShowCreateDatabase:
 		names = []string{"Database", "Create Database"}
 	case ast.ShowGrants:
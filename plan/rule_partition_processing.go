@@ -0,0 +1,238 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/parser"
+	"github.com/pingcap/tidb/util/ranger"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// partitionProcessor rewrites the DataSource of a partitioned table to the union of its partitions,
+// because the rows of a partitioned table are stored under the IDs of the partitions.
+// The partitions which can't contain any row satisfying the conditions are pruned.
+type partitionProcessor struct {
+	ctx       context.Context
+	allocator *idAllocator
+}
+
+func (s *partitionProcessor) optimize(lp LogicalPlan, ctx context.Context, allocator *idAllocator) (LogicalPlan, error) {
+	s.ctx = ctx
+	s.allocator = allocator
+	return s.rewriteDataSource(lp)
+}
+
+func (s *partitionProcessor) rewriteDataSource(lp LogicalPlan) (LogicalPlan, error) {
+	switch p := lp.(type) {
+	case *DataSource:
+		if p.tableInfo.Partition != nil {
+			return s.prune(p, nil)
+		}
+	case *Selection:
+		// The conditions which can't be pushed down are kept in the Selection, they are used for pruning too.
+		if ds, ok := p.children[0].(*DataSource); ok && ds.tableInfo.Partition != nil {
+			return s.prune(ds, p)
+		}
+	}
+	for i, child := range lp.Children() {
+		newChild, err := s.rewriteDataSource(child.(LogicalPlan))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		lp.Children()[i] = newChild
+		newChild.SetParents(lp)
+	}
+	return lp, nil
+}
+
+// prune builds the plan reading the partitions which are not pruned. If sel is not nil, it is the Selection over ds,
+// and it is copied over each partition.
+func (s *partitionProcessor) prune(ds *DataSource, sel *Selection) (LogicalPlan, error) {
+	var top LogicalPlan = ds
+	conds := ds.pushedDownConds
+	if sel != nil {
+		top = sel
+		conds = append(conds[:len(conds):len(conds)], sel.Conditions...)
+	}
+	defs, err := s.pruneByConditions(ds, conds)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if len(defs) == 0 {
+		dual := TableDual{}.init(s.allocator, s.ctx)
+		dual.SetSchema(top.Schema().Clone())
+		return dual, nil
+	}
+
+	children := make([]Plan, 0, len(defs))
+	for _, def := range defs {
+		newDS := DataSource{
+			indexHints:      ds.indexHints,
+			tableInfo:       ds.tableInfo,
+			Columns:         ds.Columns,
+			DBName:          ds.DBName,
+			physicalTableID: def.ID,
+			TableAsName:     ds.TableAsName,
+			LimitCount:      ds.LimitCount,
+			pushedDownConds: cloneExprs(ds.pushedDownConds),
+			statisticTable:  ds.statisticTable,
+		}.init(s.allocator, s.ctx)
+		// The columns keep the FromID of the original DataSource, so the parent plans can still refer to them.
+		newDS.SetSchema(ds.Schema().Clone())
+		var child LogicalPlan = newDS
+		if sel != nil {
+			newSel := Selection{
+				Conditions: cloneExprs(sel.Conditions),
+				onTable:    sel.onTable,
+			}.init(s.allocator, s.ctx)
+			newSel.SetSchema(sel.Schema().Clone())
+			newSel.SetChildren(newDS)
+			newDS.SetParents(newSel)
+			child = newSel
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return children[0].(LogicalPlan), nil
+	}
+	union := Union{}.init(s.allocator, s.ctx)
+	union.SetSchema(top.Schema().Clone())
+	union.SetChildren(children...)
+	for _, child := range children {
+		child.SetParents(union)
+	}
+	return union, nil
+}
+
+// pruneByConditions returns the partitions which may contain the rows satisfying the conditions.
+// Only the partitions whose expression is a column are pruned.
+func (s *partitionProcessor) pruneByConditions(ds *DataSource, conds []expression.Expression) ([]model.PartitionDefinition, error) {
+	pi := ds.tableInfo.Partition
+	col, err := s.findPartitionColumn(ds)
+	if err != nil || col == nil || len(conds) == 0 {
+		return pi.Definitions, errors.Trace(err)
+	}
+	accessConds, _ := ranger.DetachColumnConditions(conds, col.ColName)
+	if len(accessConds) == 0 {
+		return pi.Definitions, nil
+	}
+	ranges, err := ranger.BuildTableRange(accessConds, s.ctx.GetSessionVars().StmtCtx)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	switch pi.Type {
+	case model.PartitionTypeRange:
+		return pruneRangePartitions(pi, ranges)
+	case model.PartitionTypeHash:
+		return pruneHashPartitions(pi, ranges), nil
+	}
+	return pi.Definitions, nil
+}
+
+// findPartitionColumn returns the column in the schema of ds if the partition expression is a column.
+func (s *partitionProcessor) findPartitionColumn(ds *DataSource) (*expression.Column, error) {
+	charset, collation := s.ctx.GetSessionVars().GetCharsetInfo()
+	stmt, err := parser.New().ParseOneStmt("select "+ds.tableInfo.Partition.Expr, charset, collation)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	colExpr, ok := stmt.(*ast.SelectStmt).Fields.Fields[0].Expr.(*ast.ColumnNameExpr)
+	if !ok {
+		return nil, nil
+	}
+	for _, col := range ds.Schema().Columns {
+		// The unsigned values larger than math.MaxInt64 can't be represented by the int ranges.
+		if col.ColName.L == colExpr.Name.Name.L && !mysql.HasUnsignedFlag(col.RetType.Flag) {
+			return col, nil
+		}
+	}
+	return nil, nil
+}
+
+// pruneRangePartitions returns the range partitions which intersect with the ranges.
+// NULL is converted to math.MinInt64 by the ranger, so it always falls into the first partition.
+func pruneRangePartitions(pi *model.PartitionInfo, ranges []types.IntColumnRange) ([]model.PartitionDefinition, error) {
+	var defs []model.PartitionDefinition
+	lower := int64(math.MinInt64)
+	for _, def := range pi.Definitions {
+		isMaxValue := len(def.LessThan) == 0 || def.LessThan[0] == "MAXVALUE"
+		upper := int64(math.MaxInt64)
+		if !isMaxValue {
+			var err error
+			upper, err = strconv.ParseInt(def.LessThan[0], 10, 64)
+			if err != nil {
+				return nil, errors.Trace(err)
+			}
+		}
+		for _, ran := range ranges {
+			if ran.HighVal >= lower && (isMaxValue || ran.LowVal < upper) {
+				defs = append(defs, def)
+				break
+			}
+		}
+		lower = upper
+	}
+	return defs, nil
+}
+
+// pruneHashPartitions returns the hash partitions of the points if all the ranges are points,
+// otherwise all the partitions are returned.
+func pruneHashPartitions(pi *model.PartitionInfo, ranges []types.IntColumnRange) []model.PartitionDefinition {
+	num := int64(len(pi.Definitions))
+	if int64(len(ranges)) > num {
+		return pi.Definitions
+	}
+	used := make([]bool, num)
+	for _, ran := range ranges {
+		if ran.LowVal != ran.HighVal {
+			return pi.Definitions
+		}
+		idx := ran.LowVal % num
+		if idx < 0 {
+			idx = -idx
+		}
+		used[idx] = true
+		if ran.LowVal == math.MinInt64 {
+			// The point may be NULL, which is stored in the first partition.
+			used[0] = true
+		}
+	}
+	var defs []model.PartitionDefinition
+	for i, def := range pi.Definitions {
+		if used[i] {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+func cloneExprs(exprs []expression.Expression) []expression.Expression {
+	if exprs == nil {
+		return nil
+	}
+	cloned := make([]expression.Expression, 0, len(exprs))
+	for _, expr := range exprs {
+		cloned = append(cloned, expr.Clone())
+	}
+	return cloned
+}
@@ -42,6 +42,20 @@ func (p *basePhysicalPlan) statsProfile() *statsProfile {
 	return p.basePlan.profile
 }
 
+// EstimatedRowCount returns the output row count of a physical plan estimated by the optimizer.
+// The second return value is false if the plan carries no statistics.
+func EstimatedRowCount(p Plan) (float64, bool) {
+	pp, ok := p.(PhysicalPlan)
+	if !ok {
+		return 0, false
+	}
+	profile := pp.statsProfile()
+	if profile == nil {
+		return 0, false
+	}
+	return profile.count, true
+}
+
 func (p *baseLogicalPlan) prepareStatsProfile() *statsProfile {
 	if len(p.basePlan.children) == 0 {
 		profile := &statsProfile{
@@ -190,6 +204,35 @@ func (p *LogicalAggregation) prepareStatsProfile() *statsProfile {
 	return p.profile
 }
 
+func (p *LogicalWindow) prepareStatsProfile() *statsProfile {
+	childProfile := p.children[0].(LogicalPlan).prepareStatsProfile()
+	p.profile = &statsProfile{
+		count:       childProfile.count,
+		cardinality: make([]float64, p.schema.Len()),
+	}
+	copy(p.profile.cardinality, childProfile.cardinality)
+	// We cannot estimate the cardinality of the window function results, so we use a conservative strategy.
+	for i := len(childProfile.cardinality); i < len(p.profile.cardinality); i++ {
+		p.profile.cardinality[i] = childProfile.count
+	}
+	return p.profile
+}
+
+func (p *CTE) prepareStatsProfile() *statsProfile {
+	p.profile = &statsProfile{
+		count:       float64(1),
+		cardinality: make([]float64, p.schema.Len()),
+	}
+	// The rows produced by recursion cannot be estimated, so we only use the statistics of the seed part.
+	if seedProfile := p.Definition.SeedPlan.statsProfile(); seedProfile != nil {
+		p.profile.count = seedProfile.count
+	}
+	for i := range p.profile.cardinality {
+		p.profile.cardinality[i] = p.profile.count
+	}
+	return p.profile
+}
+
 // If the type of join is SemiJoin, the selectivity of it will be same as selection's.
 // If the type of join is LeftOuterSemiJoin, it will not add or remove any row. The last column is a boolean value, whose cardinality should be two.
 // If the type of join is inner/outer join, the output of join(s, t) should be N(s) * N(t) / (V(s.key) * V(t.key)) * Min(s.key, t.key).
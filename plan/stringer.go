@@ -38,6 +38,12 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 	switch x := in.(type) {
 	case *CheckTable:
 		str = "CheckTable"
+	case *CheckIndex:
+		str = "CheckIndex"
+	case *RecoverIndex:
+		str = "RecoverIndex"
+	case *CleanupIndex:
+		str = "CleanupIndex"
 	case *PhysicalIndexScan:
 		str = fmt.Sprintf("Index(%s.%s)%v", x.Table.Name.L, x.Index.Name.L, x.Ranges)
 	case *PhysicalTableScan:
@@ -98,6 +104,14 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = "Lock"
 	case *ShowDDL:
 		str = "ShowDDL"
+	case *ShowDDLJobs:
+		str = "ShowDDLJobs"
+	case *CancelDDLJobs:
+		str = "CancelDDLJobs"
+	case *PauseDDLJobs:
+		str = "PauseDDLJobs"
+	case *ResumeDDLJobs:
+		str = "ResumeDDLJobs"
 	case *Sort:
 		str = "Sort"
 		if x.ExecLimit != nil {
@@ -139,6 +153,10 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 		str = fmt.Sprintf("TopN(%s,%d,%d)", x.ByItems, x.Offset, x.Count)
 	case *TableDual:
 		str = "Dual"
+	case *CTE:
+		str = fmt.Sprintf("CTE(%s)", x.Definition.Name.O)
+	case *CTETable:
+		str = fmt.Sprintf("CTETable(%s)", x.Definition.Name.O)
 	case *PhysicalAggregation:
 		switch x.AggType {
 		case StreamedAgg:
@@ -155,6 +173,10 @@ func toString(in Plan, strs []string, idxs []int) ([]string, []int) {
 			}
 		}
 		str += ")"
+	case *LogicalWindow:
+		str = fmt.Sprintf("Window(%s)", explainWindowFuncs(x.WindowFuncDescs))
+	case *PhysicalWindow:
+		str = fmt.Sprintf("Window(%s)", explainWindowFuncs(x.WindowFuncDescs))
 	case *Cache:
 		str = "Cache"
 	case *PhysicalTableReader:
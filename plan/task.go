@@ -380,6 +380,13 @@ func (sel *Selection) attach2Task(tasks ...task) task {
 	return task
 }
 
+func (p *PhysicalWindow) attach2Task(tasks ...task) task {
+	task := finishCopTask(tasks[0].copy(), p.ctx, p.allocator)
+	task.addCost(task.count() * cpuFactor)
+	task = attachPlan2Task(p.Copy(), task)
+	return task
+}
+
 func (p *PhysicalAggregation) newPartialAggregate() (partialAgg, finalAgg *PhysicalAggregation) {
 	finalAgg = p.Copy().(*PhysicalAggregation)
 	// Check if this aggregation can push down.
@@ -452,9 +459,9 @@ func (p *PhysicalAggregation) attach2Task(tasks ...task) task {
 	if tasks[0].plan() == nil {
 		return tasks[0]
 	}
-	// TODO: We only consider hash aggregation here.
 	task := tasks[0].copy()
-	if cop, ok := task.(*copTask); ok {
+	// The stream aggregation isn't pushed down, its child is a root task sorted by the group by items.
+	if cop, ok := task.(*copTask); ok && p.AggType != StreamedAgg {
 		partialAgg, finalAgg := p.newPartialAggregate()
 		if partialAgg != nil {
 			if cop.tablePlan != nil {
@@ -14,6 +14,8 @@
 package privilege
 
 import (
+	"crypto/tls"
+
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/util/types"
@@ -35,7 +37,8 @@ type Manager interface {
 	// If table is not "", check global/db/table scope privileges.
 	RequestVerification(db, table, column string, priv mysql.PrivilegeType) bool
 	// ConnectionVerification verifies user privilege for connection.
-	ConnectionVerification(host, user string, auth, salt []byte) bool
+	// tlsState is nil if the connection doesn't use TLS.
+	ConnectionVerification(host, user string, auth, salt []byte, tlsState *tls.ConnectionState) bool
 
 	// DBIsVisible returns true is the database is visible to current user.
 	DBIsVisible(db string) bool
@@ -14,6 +14,8 @@
 package privileges
 
 import (
+	"crypto/tls"
+
 	"strings"
 
 	"github.com/juju/errors"
@@ -74,7 +76,7 @@ func (p *UserPrivileges) RequestVerification(db, table, column string, priv mysq
 }
 
 // ConnectionVerification implements the Manager interface.
-func (p *UserPrivileges) ConnectionVerification(user, host string, auth, salt []byte) bool {
+func (p *UserPrivileges) ConnectionVerification(user, host string, auth, salt []byte, tlsState *tls.ConnectionState) bool {
 	if SkipWithGrant {
 		p.user = user
 		p.host = host
@@ -88,6 +90,11 @@ func (p *UserPrivileges) ConnectionVerification(user, host string, auth, salt []
 		return false
 	}
 
+	if !checkSSLType(record.SSLType, tlsState) {
+		log.Errorf("User [%s] is required to connect with SSL", user)
+		return false
+	}
+
 	pwd := record.Password
 	if len(pwd) != 0 && len(pwd) != mysql.PWDHashLen+1 {
 		log.Errorf("User [%s] password from SystemDB not like a sha1sum", user)
@@ -120,6 +127,18 @@ func (p *UserPrivileges) ConnectionVerification(user, host string, auth, salt []
 	return true
 }
 
+// checkSSLType checks whether the connection satisfies the ssl_type of the user.
+// 'SPECIFIED' isn't supported, so it is treated as 'X509'.
+func checkSSLType(sslType string, tlsState *tls.ConnectionState) bool {
+	switch sslType {
+	case "ANY":
+		return tlsState != nil
+	case "X509", "SPECIFIED":
+		return tlsState != nil && len(tlsState.PeerCertificates) > 0
+	}
+	return true
+}
+
 // DBIsVisible implements the Manager interface.
 func (p *UserPrivileges) DBIsVisible(db string) bool {
 	if !Enable || SkipWithGrant {
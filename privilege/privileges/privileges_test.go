@@ -14,8 +14,14 @@
 package privileges_test
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb"
@@ -24,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/privilege"
 	"github.com/pingcap/tidb/privilege/privileges"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tidb/util/testleak"
 	"github.com/pingcap/tidb/util/testutil"
 )
@@ -244,6 +251,42 @@ func (s *testPrivilegeSuite) TestDropTablePriv(c *C) {
 	mustExec(c, se, `DROP TABLE todrop;`)
 }
 
+func (s *testPrivilegeSuite) TestViewPriv(c *C) {
+	defer testleak.AfterTest(c)()
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE TABLE viewbase(c int);`)
+	mustExec(c, se, `INSERT INTO viewbase VALUES (1);`)
+	c.Assert(se.Auth("root@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `CREATE USER 'view'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.viewbase TO 'view'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+
+	c.Assert(se.Auth("view@localhost", nil, nil), IsTrue)
+	_, err := se.Execute("CREATE VIEW v_by_user AS SELECT c FROM viewbase;")
+	c.Assert(err, NotNil)
+
+	se = newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth("root@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `GRANT Create View ON test.* TO 'view'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth("view@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `CREATE VIEW v_by_user AS SELECT c FROM viewbase;`)
+
+	// A view is executed with the privileges of its definer.
+	se = newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth("root@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `CREATE VIEW v_by_root AS SELECT c FROM viewbase;`)
+	mustExec(c, se, `CREATE USER 'view2'@'localhost';`)
+	mustExec(c, se, `GRANT Select ON test.v_by_root TO 'view2'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth("view2@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `SELECT * FROM v_by_root;`)
+	_, err = se.Execute("SELECT * FROM viewbase;")
+	c.Assert(err, NotNil)
+	_, err = se.Execute("SHOW CREATE VIEW v_by_root;")
+	c.Assert(err, NotNil)
+}
+
 func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -262,6 +305,27 @@ func (s *testPrivilegeSuite) TestCheckAuthenticate(c *C) {
 	mustExec(c, se1, "drop user 'u2'@'localhost'")
 }
 
+func (s *testPrivilegeSuite) TestCheckRequireSSL(c *C) {
+	defer testleak.AfterTest(c)()
+
+	se := newSession(c, s.store, s.dbName)
+	mustExec(c, se, `CREATE USER 'ssl1'@'localhost' REQUIRE SSL;`)
+	mustExec(c, se, `CREATE USER 'ssl2'@'localhost' REQUIRE X509;`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth("ssl1@localhost", nil, nil), IsFalse)
+	c.Assert(se.Auth("ssl2@localhost", nil, nil), IsFalse)
+	se.GetSessionVars().TLSConnectionState = &tls.ConnectionState{}
+	c.Assert(se.Auth("ssl1@localhost", nil, nil), IsTrue)
+	// REQUIRE X509 requires the client certificate.
+	c.Assert(se.Auth("ssl2@localhost", nil, nil), IsFalse)
+	se.GetSessionVars().TLSConnectionState = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{}}}
+	c.Assert(se.Auth("ssl2@localhost", nil, nil), IsTrue)
+
+	se1 := newSession(c, s.store, s.dbName)
+	mustExec(c, se1, "drop user 'ssl1'@'localhost'")
+	mustExec(c, se1, "drop user 'ssl2'@'localhost'")
+}
+
 func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	defer testleak.AfterTest(c)()
 
@@ -274,6 +338,44 @@ func (s *testPrivilegeSuite) TestInformationSchema(c *C) {
 	mustExec(c, se, `select * from information_schema.key_column_usage`)
 }
 
+func (s *testPrivilegeSuite) TestSlowQueryPriv(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "slowlog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	c.Assert(slowlog.SetFile(filepath.Join(dir, "slow.log")), IsNil)
+	defer slowlog.SetFile("")
+	c.Assert(slowlog.Write(&slowlog.Entry{Time: time.Now(), User: "root@localhost", Query: "select 1;"}), IsNil)
+	c.Assert(slowlog.Write(&slowlog.Entry{Time: time.Now(), User: "slow@localhost", Query: "select 2;"}), IsNil)
+
+	se := newSession(c, s.store, s.dbName)
+	c.Assert(se.Auth("root@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `CREATE USER 'slow'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(slowQueries(c, se), DeepEquals, []string{"select 1;", "select 2;"})
+
+	// The users without the PROCESS privilege can only see their own queries.
+	c.Assert(se.Auth("slow@localhost", nil, nil), IsTrue)
+	c.Assert(slowQueries(c, se), DeepEquals, []string{"select 2;"})
+	c.Assert(se.Auth("root@localhost", nil, nil), IsTrue)
+	mustExec(c, se, `GRANT Process ON *.* TO 'slow'@'localhost';`)
+	mustExec(c, se, `FLUSH PRIVILEGES;`)
+	c.Assert(se.Auth("slow@localhost", nil, nil), IsTrue)
+	c.Assert(slowQueries(c, se), DeepEquals, []string{"select 1;", "select 2;"})
+}
+
+func slowQueries(c *C, se tidb.Session) []string {
+	rss, err := se.Execute("SELECT query FROM information_schema.slow_query ORDER BY time, query;")
+	c.Assert(err, IsNil)
+	rows, err := tidb.GetRows(rss[0])
+	c.Assert(err, IsNil)
+	var queries []string
+	for _, row := range rows {
+		queries = append(queries, row[0].GetString())
+	}
+	return queries
+}
+
 func mustExec(c *C, se tidb.Session, sql string) {
 	_, err := se.Execute(sql)
 	c.Assert(err, IsNil)
@@ -23,4 +23,9 @@ type Config struct {
 	ReportStatus bool   `json:"report_status" toml:"report_status"`
 	StorePath    string `json:"store_path" toml:"store_path"`
 	Store        string `json:"store" toml:"store"`
+	// SSLCA, SSLCert and SSLKey are the paths of the certificate files for TLS connections.
+	// TLS is enabled if SSLCert and SSLKey are set, the client certificates are verified if SSLCA is set.
+	SSLCA   string `json:"ssl_ca" toml:"ssl_ca"`
+	SSLCert string `json:"ssl_cert" toml:"ssl_cert"`
+	SSLKey  string `json:"ssl_key" toml:"ssl_key"`
 }
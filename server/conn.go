@@ -35,7 +35,9 @@
 package server
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/tls"
 	"encoding/binary"
 	"fmt"
 	"io"
@@ -59,7 +61,7 @@ var defaultCapability = mysql.ClientLongPassword | mysql.ClientLongFlag |
 	mysql.ClientConnectWithDB | mysql.ClientProtocol41 |
 	mysql.ClientTransactions | mysql.ClientSecureConnection | mysql.ClientFoundRows |
 	mysql.ClientMultiStatements | mysql.ClientMultiResults | mysql.ClientLocalFiles |
-	mysql.ClientConnectAtts
+	mysql.ClientConnectAtts | mysql.ClientCompress
 
 // clientConn represents a connection between server and client, it maintains connection specific state,
 // handles client query.
@@ -107,12 +109,20 @@ func (cc *clientConn) handshake() error {
 	}
 
 	err := cc.writePacket(data)
-	cc.pkt.sequence = 0
+	cc.pkt.resetSequence()
 	if err != nil {
 		return errors.Trace(err)
 	}
 
-	return errors.Trace(cc.flush())
+	err = cc.flush()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The packets after the handshake are compressed if the client asks for compression.
+	if cc.capability&mysql.ClientCompress > 0 {
+		cc.pkt.setCompressed()
+	}
+	return nil
 }
 
 func (cc *clientConn) Close() error {
@@ -144,15 +154,16 @@ func (cc *clientConn) writeInitialHandshake() error {
 	data = append(data, cc.salt[0:8]...)
 	// filler [00]
 	data = append(data, 0)
-	// capability flag lower 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability), byte(defaultCapability>>8))
+	// capability flag lower 2 bytes
+	capability := cc.server.capability()
+	data = append(data, byte(capability), byte(capability>>8))
 	// charset, utf-8 default
 	data = append(data, uint8(mysql.DefaultCollationID))
 	//status
 	data = append(data, dumpUint16(mysql.ServerStatusAutocommit)...)
 	// below 13 byte may not be used
-	// capability flag upper 2 bytes, using default capability here
-	data = append(data, byte(defaultCapability>>16), byte(defaultCapability>>24))
+	// capability flag upper 2 bytes
+	data = append(data, byte(capability>>16), byte(capability>>24))
 	// filler [0x15], for wireshark dump, value is 0x15
 	data = append(data, 0x15)
 	// reserved 10 [00]
@@ -284,24 +295,74 @@ func parseAttrs(data []byte) (map[string]string, error) {
 	return attrs, nil
 }
 
+// sslRequestLen is the length of the SSLRequest packet, which is the first 32 bytes of the handshake response.
+// See https://dev.mysql.com/doc/internals/en/connection-phase-packets.html#packet-Protocol::SSLRequest
+const sslRequestLen = 32
+
+func isSSLRequest(data []byte) bool {
+	return len(data) == sslRequestLen && binary.LittleEndian.Uint32(data[:4])&mysql.ClientSSL > 0
+}
+
+// upgradeToTLS does the TLS handshake on the connection after the SSLRequest packet,
+// the following packets are read and written in the TLS connection.
+func (cc *clientConn) upgradeToTLS() (*tls.ConnectionState, error) {
+	// The client may send the TLS handshake right after the SSLRequest packet, so it may have been buffered.
+	tlsConn := tls.Server(&bufferedReadConn{Conn: cc.conn, rb: cc.pkt.rb}, cc.server.tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	cc.conn = tlsConn
+	sequence := cc.pkt.sequence
+	cc.pkt = newPacketIO(tlsConn)
+	cc.pkt.sequence = sequence
+	state := tlsConn.ConnectionState()
+	return &state, nil
+}
+
+// bufferedReadConn is a net.Conn which reads from the buffered reader of the connection.
+type bufferedReadConn struct {
+	net.Conn
+	rb *bufio.Reader
+}
+
+func (conn *bufferedReadConn) Read(b []byte) (int, error) {
+	return conn.rb.Read(b)
+}
+
 func (cc *clientConn) readHandshakeResponse() error {
 	data, err := cc.readPacket()
 	if err != nil {
 		return errors.Trace(err)
 	}
 
+	var tlsState *tls.ConnectionState
+	if isSSLRequest(data) {
+		if cc.server.tlsConfig == nil {
+			return errors.Trace(mysql.ErrMalformPacket)
+		}
+		tlsState, err = cc.upgradeToTLS()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		// The handshake response follows the SSLRequest packet in the TLS connection.
+		data, err = cc.readPacket()
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+
 	var p handshakeResponse41
 	if err = handshakeResponseFromData(&p, data); err != nil {
 		return errors.Trace(err)
 	}
-	cc.capability = p.Capability & defaultCapability
+	cc.capability = p.Capability & cc.server.capability()
 	cc.user = p.User
 	cc.dbname = p.DBName
 	cc.collation = p.Collation
 	cc.attrs = p.Attrs
 
 	// Open session and do auth
-	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), cc.dbname)
+	cc.ctx, err = cc.server.driver.OpenCtx(uint64(cc.connectionID), cc.capability, uint8(cc.collation), cc.dbname, tlsState)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -378,7 +439,7 @@ func (cc *clientConn) Run() {
 			cc.writeError(err)
 		}
 		cc.addMetrics(data[0], startTime, err)
-		cc.pkt.sequence = 0
+		cc.pkt.resetSequence()
 	}
 }
 
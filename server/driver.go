@@ -14,6 +14,7 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 
 	"github.com/pingcap/tidb/util"
@@ -22,8 +23,9 @@ import (
 
 // IDriver opens IContext.
 type IDriver interface {
-	// OpenCtx opens an IContext with connection id, client capability, collation and dbname.
-	OpenCtx(connID uint64, capability uint32, collation uint8, dbname string) (QueryCtx, error)
+	// OpenCtx opens an IContext with connection id, client capability, collation, dbname and
+	// the TLS state of the connection, which is nil if the connection doesn't use TLS.
+	OpenCtx(connID uint64, capability uint32, collation uint8, dbname string, tlsState *tls.ConnectionState) (QueryCtx, error)
 }
 
 // QueryCtx is the interface to execute command.
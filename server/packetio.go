@@ -36,7 +36,10 @@ package server
 
 import (
 	"bufio"
+	"bytes"
+	"compress/zlib"
 	"io"
+	"io/ioutil"
 	"net"
 
 	"github.com/juju/errors"
@@ -46,6 +49,13 @@ import (
 const (
 	defaultReaderSize = 16 * 1024
 	defaultWriterSize = 16 * 1024
+
+	// compressedHeaderLen is the length of the header of a compressed packet, it contains the length of
+	// the compressed payload, the compressed sequence and the length of the payload before compression.
+	compressedHeaderLen = 7
+	// minCompressLen is the minimum length of the payload to be compressed, the shorter payloads are
+	// sent without compression like MySQL.
+	minCompressLen = 50
 )
 
 // packetIO is a helper to read and write data in packet format.
@@ -54,6 +64,13 @@ type packetIO struct {
 	wb *bufio.Writer
 
 	sequence uint8
+
+	// compressed is set if the compressed protocol is used. The packets are framed in the compressed packets,
+	// which have their own sequence. The written packets are buffered in compressedBuf until flush.
+	compressed         bool
+	compressedSequence uint8
+	compressedReader   *compressedReader
+	compressedBuf      bytes.Buffer
 }
 
 func newPacketIO(conn net.Conn) *packetIO {
@@ -65,10 +82,37 @@ func newPacketIO(conn net.Conn) *packetIO {
 	return p
 }
 
+// setCompressed enables the compressed protocol for the following packets.
+func (p *packetIO) setCompressed() {
+	p.compressed = true
+	p.compressedReader = &compressedReader{p: p}
+}
+
+// resetSequence resets the sequences at the beginning of a command.
+func (p *packetIO) resetSequence() {
+	p.sequence = 0
+	p.compressedSequence = 0
+}
+
+func (p *packetIO) reader() io.Reader {
+	if p.compressed {
+		return p.compressedReader
+	}
+	return p.rb
+}
+
+func (p *packetIO) writer() io.Writer {
+	if p.compressed {
+		return &p.compressedBuf
+	}
+	return p.wb
+}
+
 func (p *packetIO) readOnePacket() ([]byte, error) {
 	var header [4]byte
 
-	if _, err := io.ReadFull(p.rb, header[:]); err != nil {
+	r := p.reader()
+	if _, err := io.ReadFull(r, header[:]); err != nil {
 		return nil, errors.Trace(err)
 	}
 
@@ -82,7 +126,7 @@ func (p *packetIO) readOnePacket() ([]byte, error) {
 	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
 
 	data := make([]byte, length)
-	if _, err := io.ReadFull(p.rb, data); err != nil {
+	if _, err := io.ReadFull(r, data); err != nil {
 		return nil, errors.Trace(err)
 	}
 	return data, nil
@@ -126,7 +170,7 @@ func (p *packetIO) writePacket(data []byte) error {
 
 		data[3] = p.sequence
 
-		if n, err := p.wb.Write(data[:4+mysql.MaxPayloadLen]); err != nil {
+		if n, err := p.writer().Write(data[:4+mysql.MaxPayloadLen]); err != nil {
 			return mysql.ErrBadConn
 		} else if n != (4 + mysql.MaxPayloadLen) {
 			return mysql.ErrBadConn
@@ -142,16 +186,136 @@ func (p *packetIO) writePacket(data []byte) error {
 	data[2] = byte(length >> 16)
 	data[3] = p.sequence
 
-	if n, err := p.wb.Write(data); err != nil {
+	if n, err := p.writer().Write(data); err != nil {
 		return errors.Trace(mysql.ErrBadConn)
 	} else if n != len(data) {
 		return errors.Trace(mysql.ErrBadConn)
 	} else {
 		p.sequence++
-		return nil
 	}
+	// Don't buffer too many packets for compression, they are compressed and written once the buffer is large enough.
+	if p.compressed && p.compressedBuf.Len() >= defaultWriterSize {
+		return errors.Trace(p.writeCompressed())
+	}
+	return nil
 }
 
 func (p *packetIO) flush() error {
+	if p.compressed {
+		if err := p.writeCompressed(); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return p.wb.Flush()
 }
+
+// writeCompressed writes the buffered packets in the compressed packets.
+func (p *packetIO) writeCompressed() error {
+	data := p.compressedBuf.Bytes()
+	defer p.compressedBuf.Reset()
+	for len(data) > 0 {
+		n := len(data)
+		if n > mysql.MaxPayloadLen {
+			n = mysql.MaxPayloadLen
+		}
+		if err := p.writeOneCompressedPacket(data[:n]); err != nil {
+			return errors.Trace(err)
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+func (p *packetIO) writeOneCompressedPacket(data []byte) error {
+	payload := data
+	uncompressedLen := 0
+	if len(data) >= minCompressLen {
+		var buf bytes.Buffer
+		w := zlib.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return errors.Trace(err)
+		}
+		if err := w.Close(); err != nil {
+			return errors.Trace(err)
+		}
+		// The data is sent without compression if it can't be compressed.
+		if buf.Len() < len(data) {
+			payload = buf.Bytes()
+			uncompressedLen = len(data)
+		}
+	}
+
+	header := []byte{
+		byte(len(payload)), byte(len(payload) >> 8), byte(len(payload) >> 16),
+		p.compressedSequence,
+		byte(uncompressedLen), byte(uncompressedLen >> 8), byte(uncompressedLen >> 16),
+	}
+	if _, err := p.wb.Write(header); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	if _, err := p.wb.Write(payload); err != nil {
+		return errors.Trace(mysql.ErrBadConn)
+	}
+	p.compressedSequence++
+	return nil
+}
+
+// compressedReader reads the uncompressed payloads of the compressed packets.
+type compressedReader struct {
+	p *packetIO
+	// buf is the unread data of the current compressed packet.
+	buf []byte
+}
+
+func (r *compressedReader) Read(b []byte) (int, error) {
+	if len(r.buf) == 0 {
+		if err := r.readCompressedPacket(); err != nil {
+			return 0, errors.Trace(err)
+		}
+	}
+	n := copy(b, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *compressedReader) readCompressedPacket() error {
+	var header [compressedHeaderLen]byte
+	if _, err := io.ReadFull(r.p.rb, header[:]); err != nil {
+		return errors.Trace(err)
+	}
+
+	sequence := header[3]
+	if sequence != r.p.compressedSequence {
+		return errInvalidSequence.Gen("invalid compressed sequence %d != %d", sequence, r.p.compressedSequence)
+	}
+	r.p.compressedSequence++
+
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	uncompressedLen := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(r.p.rb, payload); err != nil {
+		return errors.Trace(err)
+	}
+	// The payload isn't compressed if the uncompressed length is 0.
+	if uncompressedLen == 0 {
+		r.buf = payload
+		return nil
+	}
+
+	zr, err := zlib.NewReader(bytes.NewReader(payload))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	defer zr.Close()
+	// Read one more byte than the uncompressed length to detect the longer payload, and never inflate more than
+	// it, so a small payload that inflates to a huge one can't use up the memory.
+	data, err := ioutil.ReadAll(io.LimitReader(zr, int64(uncompressedLen)+1))
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if len(data) != uncompressedLen {
+		return errInvalidPayloadLen.Gen("invalid uncompressed length %d != %d", len(data), uncompressedLen)
+	}
+	r.buf = data
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+
+	. "github.com/pingcap/check"
+)
+
+type PacketIOTestSuite struct{}
+
+var _ = Suite(PacketIOTestSuite{})
+
+func newTestPacketIO(buf *bytes.Buffer) *packetIO {
+	return &packetIO{
+		rb: bufio.NewReader(buf),
+		wb: bufio.NewWriter(buf),
+	}
+}
+
+func (ts PacketIOTestSuite) TestCompressedPacket(c *C) {
+	c.Parallel()
+	var buf bytes.Buffer
+	w := newTestPacketIO(&buf)
+	w.setCompressed()
+	payloads := [][]byte{
+		[]byte("short"),
+		bytes.Repeat([]byte("compressible"), 1000),
+		bytes.Repeat([]byte{'a'}, defaultWriterSize*3),
+	}
+	for _, payload := range payloads {
+		data := make([]byte, 4, 4+len(payload))
+		data = append(data, payload...)
+		c.Assert(w.writePacket(data), IsNil)
+	}
+	// The packets are compressed once the buffered packets are large enough.
+	c.Assert(w.compressedBuf.Len(), Equals, 0)
+	c.Assert(w.compressedSequence, Equals, uint8(1))
+	c.Assert(w.flush(), IsNil)
+	c.Assert(buf.Len(), Less, defaultWriterSize)
+
+	r := newTestPacketIO(&buf)
+	r.setCompressed()
+	for _, payload := range payloads {
+		data, err := r.readPacket()
+		c.Assert(err, IsNil)
+		c.Assert(data, DeepEquals, payload)
+	}
+	c.Assert(r.sequence, Equals, w.sequence)
+	c.Assert(r.compressedSequence, Equals, w.compressedSequence)
+
+	// The short payload is sent without compression.
+	buf.Reset()
+	w.resetSequence()
+	c.Assert(w.writePacket([]byte{0, 0, 0, 0, 'o', 'k'}), IsNil)
+	c.Assert(w.flush(), IsNil)
+	c.Assert(buf.Bytes(), DeepEquals, []byte{6, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0, 'o', 'k'})
+
+	// The compressed sequence is checked.
+	r.resetSequence()
+	r.compressedSequence = 1
+	_, err := r.readPacket()
+	c.Assert(err, NotNil)
+
+	// The payload which inflates to more than its uncompressed length is rejected without being inflated fully.
+	var compressed bytes.Buffer
+	zw := zlib.NewWriter(&compressed)
+	_, err = zw.Write(make([]byte, 1<<24))
+	c.Assert(err, IsNil)
+	c.Assert(zw.Close(), IsNil)
+	buf.Reset()
+	n := compressed.Len()
+	buf.Write([]byte{byte(n), byte(n >> 8), byte(n >> 16), 0, 10, 0, 0})
+	buf.Write(compressed.Bytes())
+	r = newTestPacketIO(&buf)
+	r.setCompressed()
+	_, err = r.readPacket()
+	c.Assert(errInvalidPayloadLen.Equal(err), IsTrue)
+}
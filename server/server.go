@@ -29,6 +29,9 @@
 package server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
 	"math/rand"
 	"net"
 	"sync"
@@ -66,6 +69,8 @@ type Server struct {
 	rwlock            *sync.RWMutex
 	concurrentLimiter *TokenLimiter
 	clients           map[uint32]*clientConn
+	// tlsConfig is nil if TLS isn't enabled.
+	tlsConfig *tls.Config
 
 	// When a critical error occurred, we don't want to exit the process, because there may be
 	// a supervisor automatically restart it, then new client connection will be created, but we can't server it.
@@ -119,6 +124,15 @@ func (s *Server) newConn(conn net.Conn) *clientConn {
 	return cc
 }
 
+// capability returns the capability advertised to the clients.
+func (s *Server) capability() uint32 {
+	capability := defaultCapability
+	if s.tlsConfig != nil {
+		capability |= mysql.ClientSSL
+	}
+	return capability
+}
+
 func (s *Server) skipAuth() bool {
 	return s.cfg.SkipAuth
 }
@@ -137,6 +151,10 @@ func NewServer(cfg *Config, driver IDriver) (*Server, error) {
 	}
 
 	var err error
+	s.tlsConfig, err = loadTLSConfig(cfg)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	if cfg.Socket != "" {
 		cfg.SkipAuth = true
 		s.listener, err = net.Listen("unix", cfg.Socket)
@@ -153,6 +171,35 @@ func NewServer(cfg *Config, driver IDriver) (*Server, error) {
 	return s, nil
 }
 
+// loadTLSConfig loads the certificates for TLS connections, it returns nil if TLS isn't configured.
+func loadTLSConfig(cfg *Config) (*tls.Config, error) {
+	if cfg.SSLCert == "" || cfg.SSLKey == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.SSLCert, cfg.SSLKey)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if cfg.SSLCA != "" {
+		caCert, err := ioutil.ReadFile(cfg.SSLCA)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, errors.Errorf("failed to load CA certificate from %s", cfg.SSLCA)
+		}
+		// The client certificate is optional, it is required by the users with REQUIRE X509.
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	log.Infof("Server enables TLS connections with certificate %s", cfg.SSLCert)
+	return tlsConfig, nil
+}
+
 // Run runs the server.
 func (s *Server) Run() error {
 	// Start HTTP API to report tidb info such as TPS.
@@ -0,0 +1,78 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"crypto/tls"
+
+	"github.com/pingcap/tidb/sessionctx/variable"
+)
+
+var (
+	sslCipher  = "Ssl_cipher"
+	sslVersion = "Ssl_version"
+)
+
+// tlsVersions maps the TLS versions to the names shown by MySQL.
+var tlsVersions = map[uint16]string{
+	tls.VersionTLS10: "TLSv1",
+	tls.VersionTLS11: "TLSv1.1",
+	tls.VersionTLS12: "TLSv1.2",
+	tls.VersionTLS13: "TLSv1.3",
+}
+
+// tlsCiphers maps the cipher suites to the OpenSSL names shown by MySQL.
+var tlsCiphers = map[uint16]string{
+	tls.TLS_RSA_WITH_AES_128_CBC_SHA:            "AES128-SHA",
+	tls.TLS_RSA_WITH_AES_256_CBC_SHA:            "AES256-SHA",
+	tls.TLS_RSA_WITH_AES_128_GCM_SHA256:         "AES128-GCM-SHA256",
+	tls.TLS_RSA_WITH_AES_256_GCM_SHA384:         "AES256-GCM-SHA384",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA:      "ECDHE-RSA-AES128-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA:      "ECDHE-RSA-AES256-SHA",
+	tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256:   "ECDHE-RSA-AES128-GCM-SHA256",
+	tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384:   "ECDHE-RSA-AES256-GCM-SHA384",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA:    "ECDHE-ECDSA-AES128-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA:    "ECDHE-ECDSA-AES256-SHA",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256: "ECDHE-ECDSA-AES128-GCM-SHA256",
+	tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384: "ECDHE-ECDSA-AES256-GCM-SHA384",
+	tls.TLS_AES_128_GCM_SHA256:                  "TLS_AES_128_GCM_SHA256",
+	tls.TLS_AES_256_GCM_SHA384:                  "TLS_AES_256_GCM_SHA384",
+	tls.TLS_CHACHA20_POLY1305_SHA256:            "TLS_CHACHA20_POLY1305_SHA256",
+}
+
+// tlsStatistics provides the status variables of the TLS connection of the current session.
+type tlsStatistics struct{}
+
+func init() {
+	variable.RegisterStatistics(tlsStatistics{})
+}
+
+// GetScope gets the status variables scope.
+func (s tlsStatistics) GetScope(status string) variable.ScopeFlag {
+	return variable.ScopeSession
+}
+
+// Stats returns the TLS status variables, they are empty if the session doesn't use TLS.
+func (s tlsStatistics) Stats(vars *variable.SessionVars) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, 2)
+	m[sslCipher] = ""
+	m[sslVersion] = ""
+	if vars == nil || vars.TLSConnectionState == nil {
+		return m, nil
+	}
+	state := vars.TLSConnectionState
+	m[sslCipher] = tlsCiphers[state.CipherSuite]
+	m[sslVersion] = tlsVersions[state.Version]
+	return m, nil
+}
@@ -15,7 +15,17 @@
 package server
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"database/sql"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/ngaut/log"
@@ -133,6 +143,83 @@ func (ts *TidbTestSuite) TestSocket(c *C) {
 	server.Close()
 }
 
+func (ts *TidbTestSuite) TestTLS(c *C) {
+	dir, err := ioutil.TempDir("", "tidb-tls")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	certFile, keyFile := generateCert(c, dir)
+	cfg := &Config{
+		Addr:       ":4002",
+		LogLevel:   "debug",
+		StatusAddr: ":10092",
+		SSLCert:    certFile,
+		SSLKey:     keyFile,
+	}
+	server, err := NewServer(cfg, ts.tidbdrv)
+	c.Assert(err, IsNil)
+	go server.Run()
+	defer server.Close()
+	time.Sleep(time.Millisecond * 100)
+
+	runTests(c, "root@tcp(127.0.0.1:4002)/test?tls=skip-verify", func(dbt *DBTest) {
+		var name, version, cipher string
+		err := dbt.db.QueryRow("show status like 'Ssl_version'").Scan(&name, &version)
+		dbt.Check(err, IsNil)
+		dbt.Check(version, Not(Equals), "")
+		err = dbt.db.QueryRow("show status like 'Ssl_cipher'").Scan(&name, &cipher)
+		dbt.Check(err, IsNil)
+		dbt.Check(cipher, Not(Equals), "")
+		dbt.mustExec("create user 'tls_user'@'%' require ssl")
+		dbt.mustExec("flush privileges")
+	})
+	runTests(c, "root@tcp(127.0.0.1:4002)/test", func(dbt *DBTest) {
+		var name, version string
+		err := dbt.db.QueryRow("show status like 'Ssl_version'").Scan(&name, &version)
+		dbt.Check(err, IsNil)
+		dbt.Check(version, Equals, "")
+	})
+
+	// The user required to connect with SSL can't connect without TLS.
+	db, err := sql.Open("mysql", "tls_user@tcp(127.0.0.1:4002)/test")
+	c.Assert(err, IsNil)
+	c.Assert(db.Ping(), NotNil)
+	db.Close()
+	db, err = sql.Open("mysql", "tls_user@tcp(127.0.0.1:4002)/test?tls=skip-verify")
+	c.Assert(err, IsNil)
+	c.Assert(db.Ping(), IsNil)
+	db.Close()
+
+	runTests(c, dsn, func(dbt *DBTest) {
+		dbt.mustExec("drop user 'tls_user'@'%'")
+	})
+}
+
+// generateCert generates a self-signed certificate and its key in dir.
+func generateCert(c *C, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	c.Assert(err, IsNil)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "tidb-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	c.Assert(err, IsNil)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	c.Assert(err, IsNil)
+
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	c.Assert(err, IsNil)
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	c.Assert(err, IsNil)
+	return certFile, keyFile
+}
+
 func (ts *TidbTestSuite) TestIssue3662(c *C) {
 	c.Parallel()
 	db, err := sql.Open("mysql", "root@tcp(localhost:4001)/a_database_not_exist")
@@ -30,6 +30,7 @@ import (
 	"github.com/ngaut/log"
 	"github.com/ngaut/pools"
 	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/bindinfo"
 	"github.com/pingcap/tidb/context"
 	"github.com/pingcap/tidb/domain"
 	"github.com/pingcap/tidb/executor"
@@ -50,6 +51,7 @@ import (
 	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util"
 	"github.com/pingcap/tidb/util/types"
+	"github.com/pingcap/tidb/util/userlock"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -113,6 +115,9 @@ type session struct {
 	// goCtx is used for cancelling the execution of current transaction.
 	goCtx      goctx.Context
 	cancelFunc goctx.CancelFunc
+	// stmtGoCtx is derived from goCtx for the executing statement which has a max execution time,
+	// it's returned by GoCtx instead of goCtx until the statement finishes.
+	stmtGoCtx goctx.Context
 
 	mu struct {
 		sync.RWMutex
@@ -143,9 +148,17 @@ func (s *session) Cancel() {
 
 // GoCtx returns the standard context.Context that bind with current transaction.
 func (s *session) GoCtx() goctx.Context {
+	if s.stmtGoCtx != nil {
+		return s.stmtGoCtx
+	}
 	return s.goCtx
 }
 
+// SetStmtGoCtx sets the standard context.Context of the executing statement, it's reset by a nil ctx.
+func (s *session) SetStmtGoCtx(ctx goctx.Context) {
+	s.stmtGoCtx = ctx
+}
+
 func (s *session) cleanRetryInfo() {
 	if !s.sessionVars.RetryInfo.Retrying {
 		retryInfo := s.sessionVars.RetryInfo
@@ -350,6 +363,9 @@ func (s *session) retry(maxCnt int, infoSchemaChanged bool) error {
 	if s.sessionVars.TxnCtx.ForUpdate {
 		return errors.Errorf("[%d] can not retry select for update statement", connID)
 	}
+	if s.sessionVars.TxnCtx.IsPessimistic {
+		return errors.Errorf("[%d] can not retry pessimistic transaction", connID)
+	}
 	s.sessionVars.RetryInfo.Retrying = true
 	retryCnt := 0
 	defer func() {
@@ -595,7 +611,8 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 		log.Warnf("[%d] parse error:\n%v\n%s", connID, err, sql)
 		return nil, errors.Trace(err)
 	}
-	sessionExecuteParseDuration.Observe(time.Since(startTS).Seconds())
+	parseDuration := time.Since(startTS)
+	sessionExecuteParseDuration.Observe(parseDuration.Seconds())
 
 	var rs []ast.RecordSet
 	ph := sessionctx.GetDomain(s).PerfSchema()
@@ -610,9 +627,11 @@ func (s *session) Execute(sql string) ([]ast.RecordSet, error) {
 			s.RollbackTxn()
 			return nil, errors.Trace(err1)
 		}
-		sessionExecuteCompileDuration.Observe(time.Since(startTS).Seconds())
+		compileDuration := time.Since(startTS)
+		sessionExecuteCompileDuration.Observe(compileDuration.Seconds())
+		s.sessionVars.DurationParse, s.sessionVars.DurationCompile = parseDuration, compileDuration
 
-		s.stmtState = ph.StartStatement(sql, connID, perfschema.CallerNameSessionExecute, rawStmts[i])
+		s.stmtState = ph.StartStatement(st.OriginText(), connID, perfschema.CallerNameSessionExecute, rawStmts[i])
 		s.SetValue(context.QueryString, st.OriginText())
 
 		startTS = time.Now()
@@ -707,6 +726,7 @@ func (s *session) ExecutePreparedStmt(stmtID uint32, args ...interface{}) (ast.R
 		return nil, errors.Trace(err)
 	}
 	s.prepareTxnCtx()
+	s.sessionVars.DurationParse, s.sessionVars.DurationCompile = 0, 0
 	st := executor.CompileExecutePreparedStmt(s, stmtID, args...)
 
 	r, err := runStmt(s, st)
@@ -772,6 +792,11 @@ func (s *session) NewTxn() error {
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if s.sessionVars.LargeTxn {
+		if err = txn.SetLarge(); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	s.txn = txn
 	return nil
 }
@@ -803,6 +828,10 @@ func (s *session) Close() {
 	if err := s.RollbackTxn(); err != nil {
 		log.Error("session Close error:", errors.ErrorStack(err))
 	}
+	// The user level locks are owned by the session, they are released when the session is closed.
+	if _, err := userlock.GetManager(s).ReleaseAllLocks(s); err != nil {
+		log.Error("session Close release user level locks error:", errors.ErrorStack(err))
+	}
 	return
 }
 
@@ -838,14 +867,14 @@ func (s *session) Auth(user string, auth []byte, salt []byte) bool {
 	pm := privilege.GetPrivilegeManager(s)
 
 	// Check IP.
-	if pm.ConnectionVerification(name, host, auth, salt) {
+	if pm.ConnectionVerification(name, host, auth, salt, s.sessionVars.TLSConnectionState) {
 		s.sessionVars.User = name + "@" + host
 		return true
 	}
 
 	// Check Hostname.
 	for _, addr := range getHostByIP(host) {
-		if pm.ConnectionVerification(name, addr, auth, salt) {
+		if pm.ConnectionVerification(name, addr, auth, salt, s.sessionVars.TLSConnectionState) {
 			s.sessionVars.User = name + "@" + addr
 			return true
 		}
@@ -923,7 +952,30 @@ func BootstrapSession(store kv.Storage) (*domain.Domain, error) {
 		return nil, errors.Trace(err)
 	}
 	err = dom.UpdateTableStatsLoop(se1)
-	return dom, errors.Trace(err)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The automatic ANALYZE runs by a dedicated session, because it may take long.
+	se4, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dom.AutoAnalyzeLoop(se4)
+	se2, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	err = dom.LoadBindInfoLoop(se2)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	// The DDL worker reads the global variables by a dedicated session, because it runs in the background.
+	se3, err := createSession(store)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	dom.DDL().SetGlobalVarsAccessor(se3)
+	return dom, nil
 }
 
 // runInBootstrapSession create a special session for boostrap to run.
@@ -964,6 +1016,8 @@ func createSession(store kv.Storage) (*session, error) {
 	}
 	s.mu.values = make(map[fmt.Stringer]interface{})
 	sessionctx.BindDomain(s, domain)
+	userlock.BindManager(s, domain.UserLockManager())
+	bindinfo.BindSessionHandle(s, bindinfo.NewSessionHandle())
 	// session implements variable.GlobalVarAccessor. Bind it to ctx.
 	s.sessionVars.GlobalVarsAccessor = s
 	s.sessionVars.BinlogClient = binloginfo.GetPumpClient()
@@ -972,7 +1026,7 @@ func createSession(store kv.Storage) (*session, error) {
 
 const (
 	notBootstrapped         = 0
-	currentBootstrapVersion = 14
+	currentBootstrapVersion = 17
 )
 
 func getStoreBootstrapVersion(store kv.Storage) int64 {
@@ -1021,12 +1075,17 @@ const loadCommonGlobalVarsSQL = "select * from mysql.global_variables where vari
 	variable.AutocommitVar + quoteCommaQuote +
 	variable.SQLModeVar + quoteCommaQuote +
 	variable.MaxAllowedPacket + quoteCommaQuote +
+	variable.CTEMaxRecursionDepth + quoteCommaQuote +
+	variable.InnodbLockWaitTimeout + quoteCommaQuote +
+	variable.MaxExecutionTime + quoteCommaQuote +
 	/* TiDB specific global variables: */
 	variable.TiDBSkipUTF8Check + quoteCommaQuote +
 	variable.TiDBIndexLookupSize + quoteCommaQuote +
 	variable.TiDBIndexLookupConcurrency + quoteCommaQuote +
 	variable.TiDBIndexSerialScanConcurrency + quoteCommaQuote +
 	variable.TiDBMaxRowCountForINLJ + quoteCommaQuote +
+	variable.TiDBTxnMode + quoteCommaQuote +
+	variable.TiDBSlowLogThreshold + quoteCommaQuote +
 	variable.TiDBDistSQLScanConcurrency + "')"
 
 // loadCommonGlobalVariablesIfNeeded loads and applies commonly used global variables for the session.
@@ -1133,6 +1192,14 @@ func (s *session) ActivePendingTxn() error {
 	if s.sessionVars.Systems[variable.TxnIsolation] == ast.ReadCommitted {
 		txn.SetOption(kv.IsolationLevel, kv.RC)
 	}
+	if s.sessionVars.LargeTxn {
+		if err = txn.SetLarge(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	if !s.sessionVars.IsAutocommit() && s.sessionVars.TxnMode == variable.PessimisticTxnMode {
+		s.sessionVars.TxnCtx.IsPessimistic = true
+	}
 	return nil
 }
 
@@ -14,12 +14,14 @@
 package variable
 
 import (
+	"crypto/tls"
 	"math"
 	"sync"
 	"time"
 
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/execdetails"
 )
 
 const (
@@ -78,6 +80,7 @@ func (r *RetryInfo) GetCurrAutoIncrementID() (int64, error) {
 // TransactionContext is used to store variables that has transaction scope.
 type TransactionContext struct {
 	ForUpdate     bool
+	IsPessimistic bool
 	DirtyDB       interface{}
 	Binlog        interface{}
 	InfoSchema    interface{}
@@ -85,6 +88,10 @@ type TransactionContext struct {
 	SchemaVersion int64
 	StartTS       uint64
 	TableDeltaMap map[int64]TableDelta
+
+	// ForUpdateTS is set when a pessimistic locking statement is running, the statement reads the data of
+	// this timestamp and locks the rows it writes.
+	ForUpdateTS uint64
 }
 
 // UpdateDeltaForTable updates the delta info for some table.
@@ -125,6 +132,9 @@ type SessionVars struct {
 	// ClientCapability is client's capability.
 	ClientCapability uint32
 
+	// TLSConnectionState is the TLS state of the connection, it is nil if the connection doesn't use TLS.
+	TLSConnectionState *tls.ConnectionState
+
 	// ConnectionID is the connection id of the current session.
 	ConnectionID uint64
 
@@ -178,6 +188,15 @@ type SessionVars struct {
 
 	SQLMode mysql.SQLMode
 
+	// CTEMaxRecursionDepth is the maximum number of iterations of a recursive common table expression.
+	CTEMaxRecursionDepth int
+
+	// LockWaitTimeout is the max seconds a pessimistic transaction waits for the row locks held by others.
+	LockWaitTimeout int
+
+	// MaxExecutionTime is the timeout in milliseconds of the SELECT statements, 0 means there is no timeout.
+	MaxExecutionTime uint64
+
 	/* TiDB system variables */
 
 	// SkipConstraintCheck is true when importing data.
@@ -206,6 +225,19 @@ type SessionVars struct {
 
 	// MaxRowCountForINLJ defines max row count that the outer table of index nested loop join could be without force hint.
 	MaxRowCountForINLJ int
+
+	// TxnMode is the mode of the explicit transactions, "optimistic" or "pessimistic".
+	TxnMode string
+
+	// LargeTxn indicates if the transactions are large transactions.
+	LargeTxn bool
+
+	// SlowLogThreshold is the threshold of the slow queries in milliseconds.
+	SlowLogThreshold int
+
+	// DurationParse and DurationCompile are the time spent on parsing and compiling the current statement.
+	DurationParse   time.Duration
+	DurationCompile time.Duration
 }
 
 // NewSessionVars creates a session vars object.
@@ -227,6 +259,10 @@ func NewSessionVars() *SessionVars {
 		IndexSerialScanConcurrency: DefIndexSerialScanConcurrency,
 		DistSQLScanConcurrency:     DefDistSQLScanConcurrency,
 		MaxRowCountForINLJ:         DefMaxRowCountForINLJ,
+		CTEMaxRecursionDepth:       DefCTEMaxRecursionDepth,
+		LockWaitTimeout:            DefInnodbLockWaitTimeout,
+		TxnMode:                    DefTxnMode,
+		SlowLogThreshold:           DefSlowLogThreshold,
 	}
 }
 
@@ -305,6 +341,12 @@ const (
 	MaxAllowedPacket    = "max_allowed_packet"
 	TimeZone            = "time_zone"
 	TxnIsolation        = "tx_isolation"
+	// CTEMaxRecursionDepth is the name for cte_max_recursion_depth system variable.
+	CTEMaxRecursionDepth = "cte_max_recursion_depth"
+	// InnodbLockWaitTimeout is the name for innodb_lock_wait_timeout system variable.
+	InnodbLockWaitTimeout = "innodb_lock_wait_timeout"
+	// MaxExecutionTime is the name for max_execution_time system variable.
+	MaxExecutionTime = "max_execution_time"
 )
 
 // TableDelta stands for the changed count for one table.
@@ -322,6 +364,8 @@ type StatementContext struct {
 	IgnoreTruncate       bool
 	TruncateAsWarning    bool
 	InShowWarning        bool
+	// MaxExecutionTime is the timeout in milliseconds set by the MAX_EXECUTION_TIME hint, 0 means it isn't set.
+	MaxExecutionTime uint64
 
 	// mu struct holds variables that change during execution.
 	mu struct {
@@ -333,6 +377,9 @@ type StatementContext struct {
 
 	// Copied from SessionVars.TimeZone.
 	TimeZone *time.Location
+
+	// ExecDetails records the coprocessor requests of the statement, it's written to the slow query log.
+	ExecDetails execdetails.ExecDetails
 }
 
 // AddAffectedRows adds affected rows.
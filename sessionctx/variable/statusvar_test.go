@@ -51,7 +51,7 @@ func (ms *mockStatistics) GetScope(status string) ScopeFlag {
 	return scope
 }
 
-func (ms *mockStatistics) Stats() (map[string]interface{}, error) {
+func (ms *mockStatistics) Stats(vars *SessionVars) (map[string]interface{}, error) {
 	m := make(map[string]interface{}, len(specificStatusScopes))
 	m[testStatus] = testStatusVal
 
@@ -65,7 +65,7 @@ func (s *testStatusVarSuite) TestStatusVar(c *C) {
 	scope = s.ms.GetScope(testSessionStatus)
 	c.Assert(scope, Equals, ScopeSession)
 
-	vars, err := GetStatusVars()
+	vars, err := GetStatusVars(nil)
 	c.Assert(err, IsNil)
 	v := &StatusVal{Scope: DefaultScopeFlag, Value: testStatusVal}
 	c.Assert(v, DeepEquals, vars[testStatus])
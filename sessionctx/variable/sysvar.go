@@ -58,6 +58,7 @@ func GetSysVar(name string) *SysVar {
 const (
 	CodeUnknownStatusVar terror.ErrCode = 1
 	CodeUnknownSystemVar terror.ErrCode = 1193
+	CodeWrongValueForVar terror.ErrCode = 1231
 	CodeIncorrectScope   terror.ErrCode = 1238
 	CodeUnknownTimeZone  terror.ErrCode = 1298
 	CodeReadOnly         terror.ErrCode = 1621
@@ -65,11 +66,12 @@ const (
 
 // Variable errors
 var (
-	UnknownStatusVar   = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
-	UnknownSystemVar   = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
-	ErrIncorrectScope  = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
-	ErrUnknownTimeZone = terror.ClassVariable.New(CodeUnknownTimeZone, "unknown or incorrect time zone: %s")
-	ErrReadOnly        = terror.ClassVariable.New(CodeReadOnly, "variable is read only")
+	UnknownStatusVar    = terror.ClassVariable.New(CodeUnknownStatusVar, "unknown status variable")
+	UnknownSystemVar    = terror.ClassVariable.New(CodeUnknownSystemVar, "unknown system variable '%s'")
+	ErrWrongValueForVar = terror.ClassVariable.New(CodeWrongValueForVar, mysql.MySQLErrName[mysql.ErrWrongValueForVar])
+	ErrIncorrectScope   = terror.ClassVariable.New(CodeIncorrectScope, "Incorrect variable scope")
+	ErrUnknownTimeZone  = terror.ClassVariable.New(CodeUnknownTimeZone, "unknown or incorrect time zone: %s")
+	ErrReadOnly         = terror.ClassVariable.New(CodeReadOnly, "variable is read only")
 )
 
 func init() {
@@ -81,6 +83,7 @@ func init() {
 	// Register terror to mysql error map.
 	mySQLErrCodes := map[terror.ErrCode]uint16{
 		CodeUnknownSystemVar: mysql.ErrUnknownSystemVariable,
+		CodeWrongValueForVar: mysql.ErrWrongValueForVar,
 		CodeIncorrectScope:   mysql.ErrIncorrectGlobalLocalVar,
 		CodeUnknownTimeZone:  mysql.ErrUnknownTimeZone,
 		CodeReadOnly:         mysql.ErrVariableIsReadonly,
@@ -453,6 +456,7 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, "read_buffer_size", "131072"},
 	{ScopeNone, "innodb_read_io_threads", "4"},
 	{ScopeGlobal | ScopeSession, "max_sp_recursion_depth", "0"},
+	{ScopeGlobal | ScopeSession, CTEMaxRecursionDepth, strconv.Itoa(DefCTEMaxRecursionDepth)},
 	{ScopeNone, "ignore_builtin_innodb", "OFF"},
 	{ScopeGlobal, "rpl_semi_sync_master_enabled", ""},
 	{ScopeGlobal, "slow_query_log_file", "/usr/local/mysql/data/localhost-slow.log"},
@@ -530,7 +534,8 @@ var defaultSysVars = []*SysVar{
 	{ScopeNone, "basedir", "/usr/local/mysql"},
 	{ScopeGlobal, "innodb_old_blocks_time", "1000"},
 	{ScopeGlobal, "innodb_stats_method", "nulls_equal"},
-	{ScopeGlobal | ScopeSession, "innodb_lock_wait_timeout", "50"},
+	{ScopeGlobal | ScopeSession, InnodbLockWaitTimeout, strconv.Itoa(DefInnodbLockWaitTimeout)},
+	{ScopeGlobal | ScopeSession, MaxExecutionTime, "0"},
 	{ScopeGlobal, "local_infile", "ON"},
 	{ScopeGlobal | ScopeSession, "myisam_stats_method", "nulls_unequal"},
 	{ScopeNone, "version_compile_os", "osx10.8"},
@@ -609,7 +614,16 @@ var defaultSysVars = []*SysVar{
 	{ScopeGlobal | ScopeSession, TiDBMaxRowCountForINLJ, strconv.Itoa(DefMaxRowCountForINLJ)},
 	{ScopeGlobal | ScopeSession, TiDBSkipUTF8Check, boolToIntStr(DefSkipUTF8Check)},
 	{ScopeSession, TiDBBatchInsert, boolToIntStr(DefBatchInsert)},
+	{ScopeGlobal | ScopeSession, TiDBTxnMode, DefTxnMode},
+	{ScopeSession, TiDBLargeTxn, boolToIntStr(DefLargeTxn)},
+	{ScopeGlobal | ScopeSession, TiDBSlowLogThreshold, strconv.Itoa(DefSlowLogThreshold)},
 	{ScopeSession, TiDBCurrentTS, strconv.Itoa(DefCurretTS)},
+	{ScopeGlobal, TiDBAutoAnalyzeRatio, strconv.FormatFloat(DefAutoAnalyzeRatio, 'f', -1, 64)},
+	{ScopeGlobal, TiDBAutoAnalyzeStartTime, DefAutoAnalyzeStartTime},
+	{ScopeGlobal, TiDBAutoAnalyzeEndTime, DefAutoAnalyzeEndTime},
+	{ScopeGlobal, TiDBDDLReorgWorkerCnt, strconv.Itoa(DefDDLReorgWorkerCnt)},
+	{ScopeGlobal, TiDBDDLReorgBatchSize, strconv.Itoa(DefDDLReorgBatchSize)},
+	{ScopeGlobal, TiDBDDLReorgRateLimit, strconv.Itoa(DefDDLReorgRateLimit)},
 }
 
 // SetNamesVariables is the system variable names related to set names statements.
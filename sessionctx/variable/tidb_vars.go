@@ -90,6 +90,49 @@ const (
 	// It controls the max row count of outer table when do index nested loop join without hint.
 	// After the row count of the inner table is accurate, this variable will be removed.
 	TiDBMaxRowCountForINLJ = "tidb_max_row_count_for_inlj"
+
+	// tidb_txn_mode is the mode of the explicit transactions, the value can be "optimistic" or "pessimistic".
+	// In the pessimistic mode, UPDATE, DELETE and SELECT FOR UPDATE statements lock the rows when they run.
+	// It can be overridden by the BEGIN PESSIMISTIC and BEGIN OPTIMISTIC statements.
+	TiDBTxnMode = "tidb_txn_mode"
+
+	// tidb_large_txn is used to enable/disable the large transactions. The data written by a large transaction
+	// isn't limited by the size and entry count limits of the transactions, it spills to disk when the buffer
+	// is too large, and the transaction is committed chunk by chunk. If TiKV doesn't support the heartbeats
+	// of the transactions, the locks of a large transaction have a TTL by its size, up to an hour.
+	TiDBLargeTxn = "tidb_large_txn"
+
+	// tidb_slow_log_threshold is the threshold of the slow queries in milliseconds, the statements which run
+	// longer than it are written to the slow query log.
+	TiDBSlowLogThreshold = "tidb_slow_log_threshold"
+
+	/* Global only */
+
+	// tidb_auto_analyze_ratio is used to enable/disable the automatic ANALYZE of the stats owner.
+	// A table is analyzed automatically if its modify count divided by its row count exceeds this value,
+	// 0 disables the automatic ANALYZE.
+	TiDBAutoAnalyzeRatio = "tidb_auto_analyze_ratio"
+
+	// tidb_auto_analyze_start_time and tidb_auto_analyze_end_time are the time window of a day, in the format
+	// of "15:04 -0700", in which the automatic ANALYZE is allowed to run.
+	TiDBAutoAnalyzeStartTime = "tidb_auto_analyze_start_time"
+	TiDBAutoAnalyzeEndTime   = "tidb_auto_analyze_end_time"
+
+	// tidb_ddl_reorg_worker_cnt and tidb_ddl_reorg_batch_size are the number of the concurrent backfill tasks
+	// of ADD INDEX, and the number of rows handled by each task. They're read by the DDL owner before every
+	// round of the backfill, so they can be changed while the job is running.
+	TiDBDDLReorgWorkerCnt = "tidb_ddl_reorg_worker_cnt"
+	TiDBDDLReorgBatchSize = "tidb_ddl_reorg_batch_size"
+
+	// tidb_ddl_reorg_rate_limit is the max number of rows backfilled per second by ADD INDEX,
+	// 0 means no limit.
+	TiDBDDLReorgRateLimit = "tidb_ddl_reorg_rate_limit"
+)
+
+// The values of tidb_txn_mode.
+const (
+	OptimisticTxnMode  = "optimistic"
+	PessimisticTxnMode = "pessimistic"
 )
 
 // Default TiDB system variable values.
@@ -105,4 +148,18 @@ const (
 	DefOptInSubqUnfolding         = false
 	DefBatchInsert                = false
 	DefCurretTS                   = 0
+	DefCTEMaxRecursionDepth       = 1000
+	DefInnodbLockWaitTimeout      = 50
+	DefAutoAnalyzeRatio           = 0.5
+	DefAutoAnalyzeStartTime       = "00:00 +0000"
+	DefAutoAnalyzeEndTime         = "23:59 +0000"
+	DefTxnMode                    = OptimisticTxnMode
+	DefLargeTxn                   = false
+	DefSlowLogThreshold           = 300
+	DefDDLReorgWorkerCnt          = 16
+	DefDDLReorgBatchSize          = 128
+	DefDDLReorgRateLimit          = 0
 )
+
+// AutoAnalyzeTimeLayout is the layout of tidb_auto_analyze_start_time and tidb_auto_analyze_end_time.
+const AutoAnalyzeTimeLayout = "15:04 -0700"
@@ -92,6 +92,9 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 	if err != nil {
 		return errors.Trace(err)
 	}
+	if err = ValidateSystemVar(name, sVal); err != nil {
+		return errors.Trace(err)
+	}
 	switch name {
 	case variable.TimeZone:
 		vars.TimeZone, err = parseTimeZone(sVal)
@@ -142,8 +145,20 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 		vars.IndexSerialScanConcurrency = tidbOptPositiveInt(sVal, variable.DefIndexSerialScanConcurrency)
 	case variable.TiDBBatchInsert:
 		vars.BatchInsert = tidbOptOn(sVal)
+	case variable.TiDBTxnMode:
+		vars.TxnMode = strings.ToLower(sVal)
+	case variable.TiDBLargeTxn:
+		vars.LargeTxn = tidbOptOn(sVal)
+	case variable.TiDBSlowLogThreshold:
+		vars.SlowLogThreshold = tidbOptPositiveInt(sVal, variable.DefSlowLogThreshold)
 	case variable.TiDBMaxRowCountForINLJ:
 		vars.MaxRowCountForINLJ = tidbOptPositiveInt(sVal, variable.DefMaxRowCountForINLJ)
+	case variable.CTEMaxRecursionDepth:
+		vars.CTEMaxRecursionDepth = tidbOptPositiveInt(sVal, variable.DefCTEMaxRecursionDepth)
+	case variable.InnodbLockWaitTimeout:
+		vars.LockWaitTimeout = tidbOptPositiveInt(sVal, variable.DefInnodbLockWaitTimeout)
+	case variable.MaxExecutionTime:
+		vars.MaxExecutionTime = uint64(tidbOptPositiveInt(sVal, 0))
 	case variable.TiDBCurrentTS:
 		return variable.ErrReadOnly
 	}
@@ -151,6 +166,36 @@ func SetSessionSystemVar(vars *variable.SessionVars, name string, value types.Da
 	return nil
 }
 
+// ValidateSystemVar checks the value of the variable which only accepts some values, it's used by both
+// SET and SET GLOBAL.
+func ValidateSystemVar(name string, value string) error {
+	switch strings.ToLower(name) {
+	case variable.TiDBTxnMode:
+		if !strings.EqualFold(value, variable.OptimisticTxnMode) && !strings.EqualFold(value, variable.PessimisticTxnMode) {
+			return variable.ErrWrongValueForVar.GenByArgs(name, value)
+		}
+	case variable.TiDBAutoAnalyzeRatio:
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 {
+			return variable.ErrWrongValueForVar.GenByArgs(name, value)
+		}
+	case variable.TiDBAutoAnalyzeStartTime, variable.TiDBAutoAnalyzeEndTime:
+		if _, err := time.Parse(variable.AutoAnalyzeTimeLayout, value); err != nil {
+			return variable.ErrWrongValueForVar.GenByArgs(name, value)
+		}
+	case variable.TiDBDDLReorgWorkerCnt, variable.TiDBDDLReorgBatchSize:
+		if val, err := strconv.Atoi(value); err != nil || val <= 0 {
+			return variable.ErrWrongValueForVar.GenByArgs(name, value)
+		}
+	case variable.TiDBDDLReorgRateLimit:
+		// 0 means no limit.
+		if val, err := strconv.Atoi(value); err != nil || val < 0 {
+			return variable.ErrWrongValueForVar.GenByArgs(name, value)
+		}
+	}
+	return nil
+}
+
 // tidbOptOn could be used for all tidb session variable options, we use "ON"/1 to turn on those options.
 func tidbOptOn(opt string) bool {
 	return strings.EqualFold(opt, "ON") || opt == "1"
@@ -156,6 +156,21 @@ func (s *testVarsutilSuite) TestVarsutil(c *C) {
 	c.Assert(v.MaxRowCountForINLJ, Equals, 128)
 	SetSessionSystemVar(v, variable.TiDBMaxRowCountForINLJ, types.NewStringDatum("127"))
 	c.Assert(v.MaxRowCountForINLJ, Equals, 127)
+
+	// Test case for the DDL reorganization variables.
+	for _, name := range []string{variable.TiDBDDLReorgWorkerCnt, variable.TiDBDDLReorgBatchSize} {
+		c.Assert(ValidateSystemVar(name, "1"), IsNil)
+		for _, value := range []string{"0", "abc", "-5", ""} {
+			err = ValidateSystemVar(name, value)
+			c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("%s = %q", name, value))
+		}
+	}
+	c.Assert(ValidateSystemVar(variable.TiDBDDLReorgRateLimit, "0"), IsNil)
+	c.Assert(ValidateSystemVar(variable.TiDBDDLReorgRateLimit, "100"), IsNil)
+	for _, value := range []string{"abc", "-5", "1.5"} {
+		err = ValidateSystemVar(variable.TiDBDDLReorgRateLimit, value)
+		c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue, Commentf("value %q", value))
+	}
 }
 
 type mockGlobalAccessor struct {
@@ -112,23 +112,29 @@ func (b *SortedBuilder) Iterate(datums []types.Datum) error {
 	return nil
 }
 
-// BuildIndex builds histogram for index.
-func BuildIndex(ctx context.Context, numBuckets, id int64, records ast.RecordSet) (int64, *Histogram, error) {
+// BuildIndex builds histogram and CM sketch for index.
+func BuildIndex(ctx context.Context, numBuckets, id int64, records ast.RecordSet, cmsDepth, cmsWidth int32) (int64, *Histogram, *CMSketch, error) {
 	b := NewSortedBuilder(ctx, numBuckets, id, false)
+	cms := NewCMSketch(cmsDepth, cmsWidth)
 	for {
 		row, err := records.Next()
 		if err != nil {
-			return 0, nil, errors.Trace(err)
+			return 0, nil, nil, errors.Trace(err)
 		}
 		if row == nil {
 			break
 		}
 		err = b.Iterate(row.Data)
 		if err != nil {
-			return 0, nil, errors.Trace(err)
+			return 0, nil, nil, errors.Trace(err)
 		}
+		bytes, err := codec.EncodeKey(nil, row.Data...)
+		if err != nil {
+			return 0, nil, nil, errors.Trace(err)
+		}
+		cms.InsertBytes(bytes)
 	}
-	return int64(b.Hist.totalRowCount()), b.Hist, nil
+	return int64(b.Hist.totalRowCount()), b.Hist, cms, nil
 }
 
 // BuildColumn builds histogram from samples for column.
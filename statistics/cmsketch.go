@@ -0,0 +1,164 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/codec"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// CMSketch is used to estimate the count of a value, it is more accurate than the histogram for the skewed values.
+// See https://en.wikipedia.org/wiki/Count%E2%80%93min_sketch
+type CMSketch struct {
+	depth int32
+	width int32
+	count uint64
+	table [][]uint32
+}
+
+// NewCMSketch returns a new CM sketch.
+func NewCMSketch(depth, width int32) *CMSketch {
+	tbl := make([][]uint32, depth)
+	for i := range tbl {
+		tbl[i] = make([]uint32, width)
+	}
+	return &CMSketch{depth: depth, width: width, table: tbl}
+}
+
+// hashes returns the two hash values used to locate the counters of the bytes in every row.
+func hashes(bytes []byte) (uint32, uint32) {
+	h := fnv.New64a()
+	// Writing to the hash never fails.
+	h.Write(bytes)
+	sum := h.Sum64()
+	return uint32(sum), uint32(sum >> 32)
+}
+
+// InsertBytes inserts the bytes into the CM sketch.
+func (c *CMSketch) InsertBytes(bytes []byte) {
+	c.count++
+	h1, h2 := hashes(bytes)
+	for i := range c.table {
+		j := (h1 + h2*uint32(i)) % uint32(c.width)
+		c.table[i][j]++
+	}
+}
+
+// InsertValue inserts the value into the CM sketch.
+func (c *CMSketch) InsertValue(value types.Datum) error {
+	bytes, err := codec.EncodeValue(nil, value)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	c.InsertBytes(bytes)
+	return nil
+}
+
+// queryBytes returns the estimated count of the bytes. The counters of the rare values are dominated by the
+// collisions, so the estimated noise is subtracted from every counter and the median is used, which is known as
+// the Count-Mean-Min sketch, and the result never exceeds the minimum counter.
+func (c *CMSketch) queryBytes(bytes []byte) uint64 {
+	h1, h2 := hashes(bytes)
+	vals := make([]uint64, c.depth)
+	min := uint64(math.MaxUint64)
+	for i := range c.table {
+		j := (h1 + h2*uint32(i)) % uint32(c.width)
+		counter := uint64(c.table[i][j])
+		if counter < min {
+			min = counter
+		}
+		noise := (c.count - counter) / uint64(c.width-1)
+		if counter > noise {
+			vals[i] = counter - noise
+		}
+	}
+	sort.Slice(vals, func(i, j int) bool { return vals[i] < vals[j] })
+	res := vals[(c.depth-1)/2] + (vals[c.depth/2]-vals[(c.depth-1)/2])/2
+	if res > min {
+		res = min
+	}
+	return res
+}
+
+// queryValue returns the estimated count of the value, the value is converted to the type of the column first,
+// the second result is false if the value can't be converted.
+func (c *CMSketch) queryValue(sc *variable.StatementContext, value types.Datum, tp *types.FieldType) (uint64, bool) {
+	if tp != nil {
+		converted, err := value.ConvertTo(sc, tp)
+		if err != nil {
+			return 0, false
+		}
+		cmp, err := converted.CompareDatum(sc, value)
+		if err != nil || cmp != 0 {
+			return 0, false
+		}
+		value = converted
+	}
+	bytes, err := codec.EncodeValue(nil, value)
+	if err != nil {
+		return 0, false
+	}
+	return c.queryBytes(bytes), true
+}
+
+// encodeCMSketch encodes the CM sketch to bytes, it is stored in the cm_sketch column of mysql.stats_histograms.
+func encodeCMSketch(c *CMSketch) []byte {
+	if c == nil {
+		return nil
+	}
+	data := make([]byte, 16, 16+4*int(c.depth)*int(c.width))
+	binary.BigEndian.PutUint32(data[0:], uint32(c.depth))
+	binary.BigEndian.PutUint32(data[4:], uint32(c.width))
+	binary.BigEndian.PutUint64(data[8:], c.count)
+	var buf [4]byte
+	for _, row := range c.table {
+		for _, counter := range row {
+			binary.BigEndian.PutUint32(buf[:], counter)
+			data = append(data, buf[:]...)
+		}
+	}
+	return data
+}
+
+// decodeCMSketch decodes the bytes encoded by encodeCMSketch, it returns nil if data is empty.
+func decodeCMSketch(data []byte) (*CMSketch, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if len(data) < 16 {
+		return nil, errors.Errorf("invalid CM sketch length %d", len(data))
+	}
+	depth := int32(binary.BigEndian.Uint32(data[0:]))
+	width := int32(binary.BigEndian.Uint32(data[4:]))
+	if depth <= 0 || width <= 0 || len(data) != 16+4*int(depth)*int(width) {
+		return nil, errors.Errorf("invalid CM sketch length %d for depth %d and width %d", len(data), depth, width)
+	}
+	c := NewCMSketch(depth, width)
+	c.count = binary.BigEndian.Uint64(data[8:])
+	pos := 16
+	for i := range c.table {
+		for j := range c.table[i] {
+			c.table[i][j] = binary.BigEndian.Uint32(data[pos:])
+			pos += 4
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,67 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package statistics
+
+import (
+	"math"
+	"math/rand"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/util/types"
+)
+
+func (s *testStatisticsSuite) TestCMSketch(c *C) {
+	cms := NewCMSketch(5, 2048)
+	// The values follow the zipf distribution, so a few values are very frequent.
+	zipf := rand.NewZipf(rand.New(rand.NewSource(1)), 1.1, 1, 10000)
+	counts := make(map[int64]uint64)
+	total := 100000
+	for i := 0; i < total; i++ {
+		val := int64(zipf.Uint64())
+		counts[val]++
+		c.Assert(cms.InsertValue(types.NewIntDatum(val)), IsNil)
+	}
+	c.Assert(cms.count, Equals, uint64(total))
+
+	sc := new(variable.StatementContext)
+	tp := types.NewFieldType(mysql.TypeLonglong)
+	var sumErr float64
+	for val, count := range counts {
+		estimated, ok := cms.queryValue(sc, types.NewIntDatum(val), tp)
+		c.Assert(ok, IsTrue)
+		sumErr += math.Abs(float64(estimated) - float64(count))
+		// The estimation of the frequent values is accurate.
+		if count > uint64(total)/100 {
+			c.Assert(math.Abs(float64(estimated)-float64(count)), Less, float64(count)/10)
+		}
+	}
+	c.Assert(sumErr/float64(len(counts)), Less, 10.0)
+	// The value -1 is never inserted.
+	estimated, ok := cms.queryValue(sc, types.NewIntDatum(-1), tp)
+	c.Assert(ok, IsTrue)
+	c.Assert(estimated, Less, uint64(10))
+	_, ok = cms.queryValue(sc, types.NewFloat64Datum(1.5), tp)
+	c.Assert(ok, IsFalse)
+
+	decoded, err := decodeCMSketch(encodeCMSketch(cms))
+	c.Assert(err, IsNil)
+	c.Assert(decoded, DeepEquals, cms)
+	decoded, err = decodeCMSketch(nil)
+	c.Assert(err, IsNil)
+	c.Assert(decoded, IsNil)
+	_, err = decodeCMSketch([]byte{0, 0, 0, 1})
+	c.Assert(err, NotNil)
+}
@@ -86,10 +86,12 @@ func assertTableEqual(c *C, a *statistics.Table, b *statistics.Table) {
 	c.Assert(len(a.Columns), Equals, len(b.Columns))
 	for i := range a.Columns {
 		assertHistogramEqual(c, a.Columns[i].Histogram, b.Columns[i].Histogram)
+		c.Assert(a.Columns[i].CMSketch, DeepEquals, b.Columns[i].CMSketch)
 	}
 	c.Assert(len(a.Indices), Equals, len(b.Indices))
 	for i := range a.Indices {
 		assertHistogramEqual(c, a.Indices[i].Histogram, b.Indices[i].Histogram)
+		c.Assert(a.Indices[i].CMSketch, DeepEquals, b.Indices[i].CMSketch)
 	}
 }
 
@@ -129,6 +131,8 @@ func (s *testStatsCacheSuite) TestStatsStoreAndLoad(c *C) {
 	c.Assert(statsTbl2.Count, Equals, int64(recordCount))
 
 	assertTableEqual(c, statsTbl1, statsTbl2)
+	c.Assert(statsTbl2.Columns[tableInfo.Columns[0].ID].CMSketch, NotNil)
+	c.Assert(statsTbl2.Indices[tableInfo.Indices[0].ID].CMSketch, NotNil)
 }
 
 func (s *testStatsCacheSuite) TestEmptyTable(c *C) {
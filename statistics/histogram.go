@@ -14,6 +14,7 @@
 package statistics
 
 import (
+	"bytes"
 	"fmt"
 	"math"
 	"sort"
@@ -56,8 +57,8 @@ type Bucket struct {
 	Repeats    int64
 }
 
-// SaveToStorage saves the histogram to storage.
-func (hg *Histogram) SaveToStorage(ctx context.Context, tableID int64, count int64, isIndex int) error {
+// SaveToStorage saves the histogram and the CM sketch to storage, cms may be nil.
+func (hg *Histogram) SaveToStorage(ctx context.Context, tableID int64, count int64, isIndex int, cms *CMSketch) error {
 	exec := ctx.(sqlexec.SQLExecutor)
 	_, err := exec.Execute("begin")
 	if err != nil {
@@ -70,7 +71,7 @@ func (hg *Histogram) SaveToStorage(ctx context.Context, tableID int64, count int
 	if err != nil {
 		return errors.Trace(err)
 	}
-	replaceSQL = fmt.Sprintf("replace into mysql.stats_histograms (table_id, is_index, hist_id, distinct_count, version, null_count) values (%d, %d, %d, %d, %d, %d)", tableID, isIndex, hg.ID, hg.NDV, version, hg.NullCount)
+	replaceSQL = fmt.Sprintf("replace into mysql.stats_histograms (table_id, is_index, hist_id, distinct_count, version, null_count, cm_sketch) values (%d, %d, %d, %d, %d, %d, X'%X')", tableID, isIndex, hg.ID, hg.NDV, version, hg.NullCount, encodeCMSketch(cms))
 	_, err = exec.Execute(replaceSQL)
 	if err != nil {
 		return errors.Trace(err)
@@ -337,13 +338,25 @@ func (hg *Histogram) getIncreaseFactor(totalCount int64) float64 {
 // Column represents a column histogram.
 type Column struct {
 	Histogram
-	Info *model.ColumnInfo
+	CMSketch *CMSketch
+	Info     *model.ColumnInfo
 }
 
 func (c *Column) String() string {
 	return c.Histogram.toString(false)
 }
 
+// equalRowCount estimates the row count where the column equals to value, it prefers the CM sketch to the
+// histogram because the histogram can't tell the count of the values that are not the bucket bounds.
+func (c *Column) equalRowCount(sc *variable.StatementContext, value types.Datum) (float64, error) {
+	if c.CMSketch != nil {
+		if count, ok := c.CMSketch.queryValue(sc, value, &c.Info.FieldType); ok {
+			return float64(count), nil
+		}
+	}
+	return c.Histogram.equalRowCount(sc, value)
+}
+
 // getIntColumnRowCount estimates the row count by a slice of IntColumnRange.
 func (c *Column) getIntColumnRowCount(sc *variable.StatementContext, intRanges []types.IntColumnRange,
 	totalRowCount float64) (float64, error) {
@@ -429,7 +442,8 @@ func (c *Column) getColumnRowCount(sc *variable.StatementContext, ranges []*type
 // Index represents an index histogram.
 type Index struct {
 	Histogram
-	Info *model.IndexInfo
+	CMSketch *CMSketch
+	Info     *model.IndexInfo
 }
 
 func (idx *Index) String() string {
@@ -444,13 +458,18 @@ func (idx *Index) getRowCount(sc *variable.StatementContext, indexRanges []*type
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
-		if indexRange.LowExclude {
-			lb = append(lb, 0)
-		}
 		rb, err := codec.EncodeKey(nil, indexRange.HighVal...)
 		if err != nil {
 			return 0, errors.Trace(err)
 		}
+		if idx.CMSketch != nil && !indexRange.LowExclude && !indexRange.HighExclude && bytes.Equal(lb, rb) {
+			// The point range on all the index columns.
+			totalCount += float64(idx.CMSketch.queryBytes(lb))
+			continue
+		}
+		if indexRange.LowExclude {
+			lb = append(lb, 0)
+		}
 		if !indexRange.HighExclude {
 			rb = append(rb, 0)
 		}
@@ -182,3 +182,77 @@ func (s *testSelectivitySuite) TestSelectivity(c *C) {
 		c.Assert(math.Abs(ratio-tt.selectivity) < eps, IsTrue, comment)
 	}
 }
+
+func (s *testSelectivitySuite) TestSelectivityWithCMSketch(c *C) {
+	defer testleak.AfterTest(c)()
+	store, do, err := newStoreWithBootstrap()
+	defer store.Close()
+	c.Assert(err, IsNil)
+
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("drop table if exists t")
+	testKit.MustExec("create table t(a int, b int, index idx_b(b))")
+	// The value 1 appears in 900 rows, and every other value appears only once.
+	for i := 0; i < 1000; i++ {
+		v := 1
+		if i >= 900 {
+			v = i
+		}
+		testKit.MustExec("insert into t values (?, ?)", v, v)
+	}
+	testKit.MustExec("analyze table t")
+
+	is := do.InfoSchema()
+	tb, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	statsTbl := do.StatsHandle().GetTableStats(tb.Meta().ID)
+
+	tests := []struct {
+		exprs       string
+		selectivity float64
+	}{
+		{
+			exprs:       "a = 1",
+			selectivity: 0.9,
+		},
+		{
+			exprs:       "a = 950",
+			selectivity: 0.001,
+		},
+		{
+			exprs:       "a in (1, 950)",
+			selectivity: 0.901,
+		},
+		{
+			exprs:       "b = 1",
+			selectivity: 0.9,
+		},
+		{
+			exprs:       "b in (1, 950, 2)",
+			selectivity: 0.901,
+		},
+	}
+	for _, tt := range tests {
+		sql := "select * from t where " + tt.exprs
+		comment := Commentf("for %s", tt.exprs)
+		ctx := testKit.Se.(context.Context)
+		stmts, err := tidb.Parse(ctx, sql)
+		c.Assert(err, IsNil, comment)
+		err = plan.ResolveName(stmts[0], is, ctx)
+		c.Assert(err, IsNil, comment)
+		p, err := plan.BuildLogicalPlan(ctx, stmts[0], is)
+		c.Assert(err, IsNil, comment)
+		var sel *plan.Selection
+		for _, child := range p.Children() {
+			if p, ok := child.(*plan.Selection); ok {
+				sel = p
+				break
+			}
+		}
+		c.Assert(sel, NotNil, comment)
+		ratio, err := statsTbl.Selectivity(ctx, sel.Conditions)
+		c.Assert(err, IsNil, comment)
+		c.Assert(math.Abs(ratio-tt.selectivity) < 0.002, IsTrue, Commentf("for %s, got %v", tt.exprs, ratio))
+	}
+}
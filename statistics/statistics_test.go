@@ -185,10 +185,12 @@ func (s *testStatisticsSuite) TestBuild(c *C) {
 	c.Check(err, IsNil)
 	c.Check(int(count), Equals, 9)
 
-	tblCount, col, err := BuildIndex(ctx, bucketCount, 1, ast.RecordSet(s.rc))
+	tblCount, col, cms, err := BuildIndex(ctx, bucketCount, 1, ast.RecordSet(s.rc), 5, 2048)
 	c.Check(err, IsNil)
 	c.Check(int(tblCount), Equals, 100000)
-	count, err = col.equalRowCount(sc, encodeKey(types.NewIntDatum(10000)))
+	key := encodeKey(types.NewIntDatum(10000))
+	c.Check(cms.queryBytes(key.GetBytes()), LessEqual, uint64(10))
+	count, err = col.equalRowCount(sc, key)
 	c.Check(err, IsNil)
 	c.Check(int(count), Equals, 1)
 	count, err = col.lessRowCount(sc, encodeKey(types.NewIntDatum(20000)))
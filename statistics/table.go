@@ -80,7 +80,7 @@ func (h *Handle) tableStatsFromStorage(tableInfo *model.TableInfo) (*Table, erro
 		// We copy it before writing to avoid race.
 		table = table.copy()
 	}
-	selSQL := fmt.Sprintf("select table_id, is_index, hist_id, distinct_count, version, null_count from mysql.stats_histograms where table_id = %d", tableInfo.ID)
+	selSQL := fmt.Sprintf("select table_id, is_index, hist_id, distinct_count, version, null_count, cm_sketch from mysql.stats_histograms where table_id = %d", tableInfo.ID)
 	rows, _, err := h.ctx.(sqlexec.RestrictedSQLExecutor).ExecRestrictedSQL(h.ctx, selSQL)
 	if err != nil {
 		return nil, errors.Trace(err)
@@ -94,6 +94,10 @@ func (h *Handle) tableStatsFromStorage(tableInfo *model.TableInfo) (*Table, erro
 		histID := row.Data[2].GetInt64()
 		histVer := row.Data[4].GetUint64()
 		nullCount := row.Data[5].GetInt64()
+		cms, err := decodeCMSketch(row.Data[6].GetBytes())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
 		if row.Data[1].GetInt64() > 0 {
 			// process index
 			idx := table.Indices[histID]
@@ -104,7 +108,7 @@ func (h *Handle) tableStatsFromStorage(tableInfo *model.TableInfo) (*Table, erro
 						if err != nil {
 							return nil, errors.Trace(err)
 						}
-						idx = &Index{Histogram: *hg, Info: idxInfo}
+						idx = &Index{Histogram: *hg, CMSketch: cms, Info: idxInfo}
 					}
 					break
 				}
@@ -124,7 +128,7 @@ func (h *Handle) tableStatsFromStorage(tableInfo *model.TableInfo) (*Table, erro
 						if err != nil {
 							return nil, errors.Trace(err)
 						}
-						col = &Column{Histogram: *hg, Info: colInfo}
+						col = &Column{Histogram: *hg, CMSketch: cms, Info: colInfo}
 					}
 					break
 				}
@@ -15,11 +15,19 @@ package statistics
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/infoschema"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/sessionctx/varsutil"
 	"github.com/pingcap/tidb/util/sqlexec"
 )
 
@@ -128,3 +136,93 @@ func (h *Handle) dumpTableStatDeltaToKV(id int64, delta variable.TableDelta) err
 	_, err = h.ctx.(sqlexec.SQLExecutor).Execute("commit")
 	return errors.Trace(err)
 }
+
+// AutoAnalyzeMinCnt means the tables with fewer rows than this value are not analyzed automatically.
+// Exported for test.
+var AutoAnalyzeMinCnt int64 = 1000
+
+// HandleAutoAnalyze analyzes a table whose modify count divided by its row count exceeds tidb_auto_analyze_ratio,
+// if the current time is within the time window of the automatic ANALYZE. At most one table is analyzed in every
+// call to limit the impact on the online workload. It should be called only by the stats owner. The ANALYZE runs
+// in ctx rather than the context of the handle, so it doesn't block the loading of the stats.
+func (h *Handle) HandleAutoAnalyze(ctx context.Context, is infoschema.InfoSchema) error {
+	ratio, start, end, err := autoAnalyzeParameters(ctx)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if ratio <= 0 || !withinTimeWindow(start, end, time.Now()) {
+		return nil
+	}
+	for _, db := range is.AllSchemaNames() {
+		if strings.EqualFold(db, mysql.SystemDB) {
+			continue
+		}
+		for _, tbl := range is.SchemaTables(model.NewCIStr(db)) {
+			tblInfo := tbl.Meta()
+			// ANALYZE doesn't support the partitioned tables yet.
+			if tblInfo.Partition != nil {
+				continue
+			}
+			if !needAnalyzeTable(h.GetTableStats(tblInfo.ID), ratio) {
+				continue
+			}
+			log.Infof("[stats] auto analyze table %s.%s now", db, tblInfo.Name.O)
+			_, err = ctx.(sqlexec.SQLExecutor).Execute(fmt.Sprintf("analyze table `%s`.`%s`", db, tblInfo.Name.O))
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// autoAnalyzeParameters reads the global variables of the automatic ANALYZE.
+func autoAnalyzeParameters(ctx context.Context) (float64, time.Time, time.Time, error) {
+	vars := ctx.GetSessionVars()
+	ratioStr, err := varsutil.GetGlobalSystemVar(vars, variable.TiDBAutoAnalyzeRatio)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, errors.Trace(err)
+	}
+	ratio, err := strconv.ParseFloat(ratioStr, 64)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, errors.Trace(err)
+	}
+	startStr, err := varsutil.GetGlobalSystemVar(vars, variable.TiDBAutoAnalyzeStartTime)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, errors.Trace(err)
+	}
+	start, err := time.Parse(variable.AutoAnalyzeTimeLayout, startStr)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, errors.Trace(err)
+	}
+	endStr, err := varsutil.GetGlobalSystemVar(vars, variable.TiDBAutoAnalyzeEndTime)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, errors.Trace(err)
+	}
+	end, err := time.Parse(variable.AutoAnalyzeTimeLayout, endStr)
+	if err != nil {
+		return 0, time.Time{}, time.Time{}, errors.Trace(err)
+	}
+	return ratio, start, end, nil
+}
+
+// needAnalyzeTable checks if the table has enough rows and is modified enough since it was analyzed last time.
+// The modify count of a table that is never analyzed equals to its row count, so it is analyzed as well.
+func needAnalyzeTable(tbl *Table, ratio float64) bool {
+	if tbl.Pseudo || tbl.Count < AutoAnalyzeMinCnt {
+		return false
+	}
+	return float64(tbl.ModifyCount)/float64(tbl.Count) > ratio
+}
+
+// withinTimeWindow checks if the time of day of now is within [start, end], the window may span midnight.
+// Only the hours, minutes and time zones of start and end are used.
+func withinTimeWindow(start, end, now time.Time) bool {
+	minutesOfDay := func(t time.Time) int {
+		t = t.UTC()
+		return t.Hour()*60 + t.Minute()
+	}
+	s, e, n := minutesOfDay(start), minutesOfDay(end), minutesOfDay(now)
+	if s <= e {
+		return s <= n && n <= e
+	}
+	return n >= s || n <= e
+}
@@ -14,8 +14,14 @@
 package statistics_test
 
 import (
+	"fmt"
+	"time"
+
 	. "github.com/pingcap/check"
 	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/sessionctx/variable"
+	"github.com/pingcap/tidb/statistics"
+	"github.com/pingcap/tidb/terror"
 	"github.com/pingcap/tidb/util/testkit"
 	"github.com/pingcap/tidb/util/types"
 )
@@ -215,3 +221,91 @@ func (s *testStatsUpdateSuite) TestTxnWithFailure(c *C) {
 	stats1 = h.GetTableStats(tableInfo1.ID)
 	c.Assert(stats1.Count, Equals, int64(rowCount1+1))
 }
+
+func (s *testStatsUpdateSuite) TestAutoAnalyze(c *C) {
+	store, do, err := newStoreWithBootstrap()
+	c.Assert(err, IsNil)
+	defer store.Close()
+	testKit := testkit.NewTestKit(c, store)
+	testKit.MustExec("use test")
+	testKit.MustExec("create table t (a int, b int, index idx_b(b))")
+	origMinCnt := statistics.AutoAnalyzeMinCnt
+	defer func() {
+		statistics.AutoAnalyzeMinCnt = origMinCnt
+	}()
+	statistics.AutoAnalyzeMinCnt = 0
+
+	is := do.InfoSchema()
+	tbl, err := is.TableByName(model.NewCIStr("test"), model.NewCIStr("t"))
+	c.Assert(err, IsNil)
+	tableInfo := tbl.Meta()
+	h := do.StatsHandle()
+	h.HandleDDLEvent(<-h.DDLEventCh())
+
+	analyzed := func() bool {
+		statsTbl := h.GetTableStats(tableInfo.ID)
+		return len(statsTbl.Columns[tableInfo.Columns[0].ID].Buckets) > 0
+	}
+	// The table is empty.
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), IsNil)
+	h.Update(is)
+	c.Assert(analyzed(), IsFalse)
+
+	// The table which is never analyzed is analyzed once it has rows.
+	testKit.MustExec("insert into t values (1, 1), (2, 2)")
+	h.DumpStatsDeltaToKV()
+	h.Update(is)
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), IsNil)
+	h.Update(is)
+	c.Assert(analyzed(), IsTrue)
+	statsTbl := h.GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.Count, Equals, int64(2))
+	c.Assert(statsTbl.ModifyCount, Equals, int64(0))
+
+	// The modify ratio doesn't exceed the threshold.
+	testKit.MustExec("insert into t values (3, 3)")
+	h.DumpStatsDeltaToKV()
+	h.Update(is)
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), IsNil)
+	h.Update(is)
+	c.Assert(h.GetTableStats(tableInfo.ID).ModifyCount, Equals, int64(1))
+
+	// The auto analyze is disabled.
+	testKit.MustExec("set @@global.tidb_auto_analyze_ratio = 0")
+	testKit.MustExec("insert into t values (4, 4), (5, 5), (6, 6)")
+	h.DumpStatsDeltaToKV()
+	h.Update(is)
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), IsNil)
+	h.Update(is)
+	c.Assert(h.GetTableStats(tableInfo.ID).ModifyCount, Equals, int64(4))
+
+	// The current time is out of the time window.
+	testKit.MustExec("set @@global.tidb_auto_analyze_ratio = 0.5")
+	now := time.Now().UTC()
+	testKit.MustExec(fmt.Sprintf("set @@global.tidb_auto_analyze_start_time = '%s'", now.Add(time.Hour).Format("15:04 -0700")))
+	testKit.MustExec(fmt.Sprintf("set @@global.tidb_auto_analyze_end_time = '%s'", now.Add(2*time.Hour).Format("15:04 -0700")))
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), IsNil)
+	h.Update(is)
+	c.Assert(h.GetTableStats(tableInfo.ID).ModifyCount, Equals, int64(4))
+
+	// The time window spans midnight.
+	testKit.MustExec(fmt.Sprintf("set @@global.tidb_auto_analyze_start_time = '%s'", now.Add(-time.Hour).Format("15:04 -0700")))
+	testKit.MustExec(fmt.Sprintf("set @@global.tidb_auto_analyze_end_time = '%s'", now.Add(-2*time.Hour).Format("15:04 -0700")))
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), IsNil)
+	h.Update(is)
+	statsTbl = h.GetTableStats(tableInfo.ID)
+	c.Assert(statsTbl.Count, Equals, int64(6))
+	c.Assert(statsTbl.ModifyCount, Equals, int64(0))
+
+	_, err = testKit.Exec("set @@global.tidb_auto_analyze_start_time = 'invalid'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = testKit.Exec("set @@global.tidb_auto_analyze_end_time = '25:00 +0000'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = testKit.Exec("set @@global.tidb_auto_analyze_ratio = -1")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	_, err = testKit.Exec("set @@global.tidb_auto_analyze_ratio = 'abc'")
+	c.Assert(terror.ErrorEqual(err, variable.ErrWrongValueForVar), IsTrue)
+	// The invalid value written directly is still reported.
+	testKit.MustExec("update mysql.global_variables set variable_value = 'invalid' where variable_name = 'tidb_auto_analyze_start_time'")
+	c.Assert(h.HandleAutoAnalyze(testKit.Se, is), NotNil)
+}
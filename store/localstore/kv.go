@@ -362,6 +362,11 @@ func (s *dbStore) CurrentVersion() (kv.Version, error) {
 	return globalVersionProvider.CurrentVersion()
 }
 
+// SupportLargeTxn implements the kv.LargeTxnStorage interface.
+func (s *dbStore) SupportLargeTxn() bool {
+	return true
+}
+
 // Begin transaction
 func (s *dbStore) Begin() (kv.Transaction, error) {
 	s.mu.RLock()
@@ -84,6 +84,10 @@ func (txn *dbTxn) DelOption(opt kv.Option) {
 	txn.us.DelOption(opt)
 }
 
+func (txn *dbTxn) SetLarge() error {
+	return errors.Trace(txn.us.SetLarge())
+}
+
 func (txn *dbTxn) doCommit() error {
 	// Check schema lease.
 	checker, ok := txn.us.GetOption(kv.SchemaLeaseChecker).(schemaLeaseChecker)
@@ -131,6 +135,7 @@ type schemaLeaseChecker interface {
 }
 
 func (txn *dbTxn) close() error {
+	txn.us.Release()
 	txn.lockedKeys = nil
 	txn.valid = false
 	return nil
@@ -15,6 +15,7 @@ package tikv
 
 import (
 	"bytes"
+	"hash/fnv"
 	"math"
 	"sync"
 	"sync/atomic"
@@ -26,9 +27,11 @@ import (
 	pb "github.com/pingcap/kvproto/pkg/kvrpcpb"
 	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/deadlock"
 	"github.com/pingcap/tipb/go-binlog"
 	goctx "golang.org/x/net/context"
 )
@@ -39,6 +42,8 @@ const (
 	actionPrewrite twoPhaseCommitAction = 1
 	actionCommit   twoPhaseCommitAction = 2
 	actionCleanup  twoPhaseCommitAction = 3
+	// actionPessimisticLock acquires the pessimistic locks before the 2PC.
+	actionPessimisticLock twoPhaseCommitAction = 4
 )
 
 func (ca twoPhaseCommitAction) String() string {
@@ -49,6 +54,8 @@ func (ca twoPhaseCommitAction) String() string {
 		return "commit"
 	case actionCleanup:
 		return "cleanup"
+	case actionPessimisticLock:
+		return "pessimistic_lock"
 	}
 	return "unknown"
 }
@@ -67,7 +74,15 @@ type twoPhaseCommitter struct {
 	mutations map[string]*pb.Mutation
 	lockTTL   uint64
 	commitTS  uint64
-	mu        struct {
+	// primaryKey is the first key locked by the pessimistic transaction, it is used as the primary key
+	// when the committer acquires the pessimistic locks.
+	primaryKey      []byte
+	forUpdateTS     uint64
+	lockWaitTimeout time.Duration
+	// large is true for a large transaction, the values of its mutations are read from the buffer of the
+	// transaction when they are sent, and its 2PC runs chunk by chunk.
+	large bool
+	mu    struct {
 		sync.RWMutex
 		writtenKeys  [][]byte
 		committed    bool
@@ -85,22 +100,18 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 		delCnt  int
 		lockCnt int
 	)
+	large := txn.us.GetOption(kv.LargeTxn) != nil
 	mutations := make(map[string]*pb.Mutation)
 	err := txn.us.WalkBuffer(func(k kv.Key, v []byte) error {
 		if len(v) > 0 {
-			mutations[string(k)] = &pb.Mutation{
-				Op:    pb.Op_Put,
-				Key:   k,
-				Value: v,
-			}
 			putCnt++
 		} else {
-			mutations[string(k)] = &pb.Mutation{
-				Op:  pb.Op_Del,
-				Key: k,
-			}
 			delCnt++
 		}
+		// The mutations of a large transaction are not kept in memory, they are read from the buffer later.
+		if !large {
+			mutations[string(k)] = newMutation(k, v)
+		}
 		keys = append(keys, k)
 		entrySize := len(k) + len(v)
 		if entrySize > kv.TxnEntrySizeLimit {
@@ -113,21 +124,41 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 		return nil, errors.Trace(err)
 	}
 	for _, lockKey := range txn.lockKeys {
-		if _, ok := mutations[string(lockKey)]; !ok {
-			mutations[string(lockKey)] = &pb.Mutation{
-				Op:  pb.Op_Lock,
-				Key: lockKey,
+		if _, ok := mutations[string(lockKey)]; ok {
+			continue
+		}
+		if large {
+			_, err = txn.us.GetMemBuffer().Get(lockKey)
+			if err == nil {
+				continue
+			}
+			if !kv.IsErrNotFound(err) {
+				return nil, errors.Trace(err)
 			}
-			lockCnt++
-			keys = append(keys, lockKey)
-			size += len(lockKey)
 		}
+		mutations[string(lockKey)] = &pb.Mutation{
+			Op:  pb.Op_Lock,
+			Key: lockKey,
+		}
+		lockCnt++
+		keys = append(keys, lockKey)
+		size += len(lockKey)
 	}
 	if len(keys) == 0 {
 		return nil, nil
 	}
+	if len(txn.primaryKey) > 0 {
+		// The key locked first by the pessimistic transaction must be the primary key, because it is
+		// recorded as the primary in the pessimistic locks.
+		for i, k := range keys {
+			if bytes.Equal(k, txn.primaryKey) {
+				keys[0], keys[i] = keys[i], keys[0]
+				break
+			}
+		}
+	}
 	entrylimit := atomic.LoadUint64(&kv.TxnEntryCountLimit)
-	if len(keys) > int(entrylimit) || size > kv.TxnTotalSizeLimit {
+	if !large && (len(keys) > int(entrylimit) || size > kv.TxnTotalSizeLimit) {
 		return nil, kv.ErrTxnTooLarge
 	}
 	const logEntryCount = 10000
@@ -140,18 +171,53 @@ func newTwoPhaseCommitter(txn *tikvTxn) (*twoPhaseCommitter, error) {
 
 	txnWriteKVCountHistogram.Observe(float64(len(keys)))
 	txnWriteSizeHistogram.Observe(float64(size / 1024))
+	lockTTL := txnLockTTL(txn.startTime, size)
+	if large && !txn.store.supportTxnHeartBeat() {
+		// The locks of the large transaction can't be kept alive by the heartbeats.
+		lockTTL = largeTxnLockTTL(txn.startTime, size)
+	}
 	return &twoPhaseCommitter{
 		store:     txn.store,
 		txn:       txn,
 		startTS:   txn.StartTS(),
 		keys:      keys,
 		mutations: mutations,
-		lockTTL:   txnLockTTL(txn.startTime, size),
+		lockTTL:   lockTTL,
 		priority:  getTxnPriority(txn),
+		large:     large,
 	}, nil
 }
 
+func newMutation(k kv.Key, v []byte) *pb.Mutation {
+	if len(v) > 0 {
+		return &pb.Mutation{
+			Op:    pb.Op_Put,
+			Key:   k,
+			Value: v,
+		}
+	}
+	return &pb.Mutation{
+		Op:  pb.Op_Del,
+		Key: k,
+	}
+}
+
+// mutation returns the mutation of the key, the mutations of a large transaction are read from its buffer.
+func (c *twoPhaseCommitter) mutation(key []byte) (*pb.Mutation, error) {
+	if m, ok := c.mutations[string(key)]; ok || !c.large {
+		return m, nil
+	}
+	v, err := c.txn.us.GetMemBuffer().Get(key)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return newMutation(key, v), nil
+}
+
 func (c *twoPhaseCommitter) primary() []byte {
+	if len(c.primaryKey) > 0 {
+		return c.primaryKey
+	}
 	return c.keys[0]
 }
 
@@ -181,6 +247,19 @@ func txnLockTTL(startTime monotime.Time, txnSize int) uint64 {
 	return lockTTL + uint64(elapsed)
 }
 
+// largeTxnLockTTL returns the lock TTL of a large transaction on the store without the TxnHeartBeat request.
+// The TTL is long enough to commit the whole transaction at largeTxnLockTTLPerMiB, it's limited by
+// maxLargeTxnLockTTL, and the transaction fails to commit if it takes longer than the TTL. Notice that
+// if the TiDB server crashes, its locks block the other transactions until they're expired.
+func largeTxnLockTTL(startTime monotime.Time, txnSize int) uint64 {
+	lockTTL := defaultLockTTL + uint64(txnSize/bytesPerMiB)*largeTxnLockTTLPerMiB
+	if lockTTL > maxLargeTxnLockTTL {
+		lockTTL = maxLargeTxnLockTTL
+	}
+	elapsed := time.Duration(monotime.Now()-startTime) / time.Millisecond
+	return lockTTL + uint64(elapsed)
+}
+
 // doActionOnKeys groups keys into primary batch and secondary batches, if primary batch exists in the key,
 // it does action on primary batch first, then on secondary batches. If action is commit, secondary batches
 // is done in background goroutine.
@@ -188,28 +267,14 @@ func (c *twoPhaseCommitter) doActionOnKeys(bo *Backoffer, action twoPhaseCommitA
 	if len(keys) == 0 {
 		return nil
 	}
-	groups, firstRegion, err := c.store.regionCache.GroupKeysByRegion(bo, keys)
+	batches, err := c.groupIntoBatches(bo, action, keys)
 	if err != nil {
 		return errors.Trace(err)
 	}
 
-	txnRegionsNumHistogram.WithLabelValues(action.MetricsTag()).Observe(float64(len(groups)))
-
-	var batches []batchKeys
-	var sizeFunc = c.keySize
-	if action == actionPrewrite {
-		sizeFunc = c.keyValueSize
-	}
-	// Make sure the group that contains primary key goes first.
-	batches = appendBatchBySize(batches, firstRegion, groups[firstRegion], sizeFunc, txnCommitBatchSize)
-	delete(groups, firstRegion)
-	for id, g := range groups {
-		batches = appendBatchBySize(batches, id, g, sizeFunc, txnCommitBatchSize)
-	}
-
 	firstIsPrimary := bytes.Equal(keys[0], c.primary())
-	if firstIsPrimary && (action == actionCommit || action == actionCleanup) {
-		// primary should be committed/cleanup first
+	if firstIsPrimary && (action == actionCommit || action == actionCleanup || action == actionPessimisticLock) {
+		// primary should be committed/cleanup/locked first
 		err = c.doActionOnBatches(bo, action, batches[:1])
 		if err != nil {
 			return errors.Trace(err)
@@ -231,6 +296,63 @@ func (c *twoPhaseCommitter) doActionOnKeys(bo *Backoffer, action twoPhaseCommitA
 	return errors.Trace(err)
 }
 
+// groupIntoBatches groups the keys into batches by region and size, the batch of the first key goes first.
+func (c *twoPhaseCommitter) groupIntoBatches(bo *Backoffer, action twoPhaseCommitAction, keys [][]byte) ([]batchKeys, error) {
+	groups, firstRegion, err := c.store.regionCache.GroupKeysByRegion(bo, keys)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+
+	txnRegionsNumHistogram.WithLabelValues(action.MetricsTag()).Observe(float64(len(groups)))
+
+	var batches []batchKeys
+	var sizeFunc = c.keySize
+	if action == actionPrewrite {
+		sizeFunc = c.keyValueSize
+	}
+	// Make sure the group that contains primary key goes first.
+	batches = appendBatchBySize(batches, firstRegion, groups[firstRegion], sizeFunc, txnCommitBatchSize)
+	delete(groups, firstRegion)
+	for id, g := range groups {
+		batches = appendBatchBySize(batches, id, g, sizeFunc, txnCommitBatchSize)
+	}
+	return batches, nil
+}
+
+// doActionOnKeysInChunks does action on the keys of a large transaction chunk by chunk, so the mutations
+// read from the buffer and the requests sent concurrently are limited.
+func (c *twoPhaseCommitter) doActionOnKeysInChunks(bo *Backoffer, action twoPhaseCommitAction, keys [][]byte) error {
+	for start := 0; start < len(keys); start += largeTxnChunkKeys {
+		end := start + largeTxnChunkKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := c.doActionOnKeys(bo, action, keys[start:end]); err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
+
+// commitSecondariesInChunks commits the secondary keys of a large transaction chunk by chunk in the background
+// after its primary key is committed.
+func (c *twoPhaseCommitter) commitSecondariesInChunks(bo *Backoffer, keys [][]byte) {
+	for start := 0; start < len(keys); start += largeTxnChunkKeys {
+		end := start + largeTxnChunkKeys
+		if end > len(keys) {
+			end = len(keys)
+		}
+		batches, err := c.groupIntoBatches(bo, actionCommit, keys[start:end])
+		if err == nil {
+			err = c.doActionOnBatches(bo, actionCommit, batches)
+		}
+		if err != nil {
+			log.Debugf("2PC async commit secondary keys of large txn err: %v, tid: %d", err, c.startTS)
+			return
+		}
+	}
+}
+
 // reserveStack reserves 4KB memory on the stack to avoid runtime.morestack, call it after new a goroutine if necessary.
 func reserveStack(dummy bool) {
 	var buf [8 << 10]byte
@@ -255,6 +377,8 @@ func (c *twoPhaseCommitter) doActionOnBatches(bo *Backoffer, action twoPhaseComm
 		singleBatchActionFunc = c.commitSingleBatch
 	case actionCleanup:
 		singleBatchActionFunc = c.cleanupSingleBatch
+	case actionPessimisticLock:
+		singleBatchActionFunc = c.pessimisticLockSingleBatch
 	}
 	if len(batches) == 1 {
 		e := singleBatchActionFunc(bo, batches[0])
@@ -264,10 +388,10 @@ func (c *twoPhaseCommitter) doActionOnBatches(bo *Backoffer, action twoPhaseComm
 		return errors.Trace(e)
 	}
 
-	// For prewrite, stop sending other requests after receiving first error.
+	// For prewrite and pessimistic lock, stop sending other requests after receiving first error.
 	backoffer := bo
 	var cancel goctx.CancelFunc
-	if action == actionPrewrite {
+	if action == actionPrewrite || action == actionPessimisticLock {
 		backoffer, cancel = bo.Fork()
 	}
 
@@ -312,7 +436,7 @@ func (c *twoPhaseCommitter) doActionOnBatches(bo *Backoffer, action twoPhaseComm
 
 func (c *twoPhaseCommitter) keyValueSize(key []byte) int {
 	size := len(key)
-	if mutation := c.mutations[string(key)]; mutation != nil {
+	if mutation, err := c.mutation(key); err == nil && mutation != nil {
 		size += len(mutation.Value)
 	}
 	return size
@@ -325,7 +449,11 @@ func (c *twoPhaseCommitter) keySize(key []byte) int {
 func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys) error {
 	mutations := make([]*pb.Mutation, len(batch.keys))
 	for i, k := range batch.keys {
-		mutations[i] = c.mutations[string(k)]
+		m, err := c.mutation(k)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		mutations[i] = m
 	}
 
 	req := &tikvrpc.Request{
@@ -397,6 +525,113 @@ func (c *twoPhaseCommitter) prewriteSingleBatch(bo *Backoffer, batch batchKeys)
 	}
 }
 
+func (c *twoPhaseCommitter) pessimisticLockSingleBatch(bo *Backoffer, batch batchKeys) error {
+	req := &tikvrpc.Request{
+		Type:     tikvrpc.CmdPessimisticLock,
+		Priority: c.priority,
+		PessimisticLock: &tikvrpc.PessimisticLockRequest{
+			Keys:         batch.keys,
+			PrimaryLock:  c.primary(),
+			StartVersion: c.startTS,
+			ForUpdateTs:  c.forUpdateTS,
+			LockTtl:      c.lockTTL,
+		},
+	}
+	var waitStart time.Time
+	detector := c.store.deadlockDetector
+	// The wait-for edges are removed when the locking finishes, the lock holders may still be running.
+	waitFor := make(map[uint64]uint64)
+	defer func() {
+		for txnID, keyHash := range waitFor {
+			detector.CleanUpWaitFor(c.startTS, txnID, keyHash)
+		}
+	}()
+	for {
+		resp, err := c.store.SendReq(bo, req, batch.region, readTimeoutShort)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		regionErr, err := resp.GetRegionError()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if regionErr != nil {
+			err = bo.Backoff(boRegionMiss, errors.New(regionErr.String()))
+			if err != nil {
+				return errors.Trace(err)
+			}
+			err = c.pessimisticLockKeys(bo, batch.keys)
+			return errors.Trace(err)
+		}
+		lockResp := resp.PessimisticLock
+		if lockResp == nil {
+			return errors.Trace(errBodyMissing)
+		}
+		keyErrs := lockResp.GetErrors()
+		if len(keyErrs) == 0 {
+			c.mu.Lock()
+			defer c.mu.Unlock()
+			// Keep the primary key in the front, so it is rolled back first.
+			if bytes.Equal(batch.keys[0], c.primary()) {
+				tmpKeys := make([][]byte, 0, len(batch.keys)+len(c.mu.writtenKeys))
+				tmpKeys = append(tmpKeys, batch.keys...)
+				c.mu.writtenKeys = append(tmpKeys, c.mu.writtenKeys...)
+			} else {
+				c.mu.writtenKeys = append(c.mu.writtenKeys, batch.keys...)
+			}
+			return nil
+		}
+		var locks []*Lock
+		for _, keyErr := range keyErrs {
+			if keyErr.Retryable != "" {
+				log.Debugf("2PC pessimistic lock encounters write conflict: %s, tid: %d", keyErr.Retryable, c.startTS)
+				return errors.Trace(kv.ErrWriteConflict)
+			}
+			lock, err1 := extractLockFromKeyErr(keyErr)
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+			log.Debugf("2PC pessimistic lock encounters lock: %v", lock)
+			locks = append(locks, lock)
+		}
+		ok, err := c.store.lockResolver.ResolveLocks(bo, locks)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if ok {
+			continue
+		}
+		// Wait for the lock holders to finish.
+		for _, lock := range locks {
+			keyHash := hashKey(lock.Key)
+			if err1 := detector.Detect(c.startTS, lock.TxnID, keyHash); err1 != nil {
+				if _, ok := err1.(*deadlock.ErrDeadlock); !ok {
+					return errors.Trace(err1)
+				}
+				log.Infof("2PC pessimistic lock detects deadlock, tid: %d, wait for: %d", c.startTS, lock.TxnID)
+				return errors.Trace(kv.ErrDeadlock)
+			}
+			waitFor[lock.TxnID] = keyHash
+		}
+		if waitStart.IsZero() {
+			waitStart = time.Now()
+		} else if time.Since(waitStart) >= c.lockWaitTimeout {
+			return errors.Trace(kv.ErrLockWaitTimeout)
+		}
+		select {
+		case <-bo.ctx.Done():
+			return errors.Trace(bo.ctx.Err())
+		case <-time.After(lockWaitInterval):
+		}
+	}
+}
+
+func hashKey(key []byte) uint64 {
+	h := fnv.New64a()
+	h.Write(key)
+	return h.Sum64()
+}
+
 func getTxnPriority(txn *tikvTxn) pb.CommandPri {
 	if pri := txn.us.GetOption(kv.Priority); pri != nil {
 		return kvPriorityToCommandPri(pri.(int))
@@ -513,6 +748,9 @@ func (c *twoPhaseCommitter) cleanupSingleBatch(bo *Backoffer, batch batchKeys) e
 }
 
 func (c *twoPhaseCommitter) prewriteKeys(bo *Backoffer, keys [][]byte) error {
+	if c.large {
+		return c.doActionOnKeysInChunks(bo, actionPrewrite, keys)
+	}
 	return c.doActionOnKeys(bo, actionPrewrite, keys)
 }
 
@@ -521,9 +759,16 @@ func (c *twoPhaseCommitter) commitKeys(bo *Backoffer, keys [][]byte) error {
 }
 
 func (c *twoPhaseCommitter) cleanupKeys(bo *Backoffer, keys [][]byte) error {
+	if c.large {
+		return c.doActionOnKeysInChunks(bo, actionCleanup, keys)
+	}
 	return c.doActionOnKeys(bo, actionCleanup, keys)
 }
 
+func (c *twoPhaseCommitter) pessimisticLockKeys(bo *Backoffer, keys [][]byte) error {
+	return c.doActionOnKeys(bo, actionPessimisticLock, keys)
+}
+
 // The max time a Txn may use (in ms) from its startTS to commitTS.
 // We use it to guarantee GC worker will not influence any active txn. The value
 // should be less than `gcRunInterval`.
@@ -552,6 +797,11 @@ func (c *twoPhaseCommitter) execute() error {
 	}()
 
 	ctx := goctx.Background()
+	if c.large && c.store.supportTxnHeartBeat() {
+		stop := make(chan struct{})
+		defer close(stop)
+		go c.keepAlive(stop)
+	}
 	binlogChan := c.prewriteBinlog()
 	err := c.prewriteKeys(NewBackoffer(prewriteMaxBackoff, ctx), c.keys)
 	if binlogChan != nil {
@@ -589,7 +839,15 @@ func (c *twoPhaseCommitter) execute() error {
 		return errors.Annotate(err, txnRetryableMark)
 	}
 
-	err = c.commitKeys(NewBackoffer(commitMaxBackoff, ctx), c.keys)
+	if c.large {
+		// Only the primary key is committed synchronously, the secondary keys are committed in the background.
+		err = c.commitKeys(NewBackoffer(commitMaxBackoff, ctx), c.keys[:1])
+		if err == nil {
+			go c.commitSecondariesInChunks(NewBackoffer(commitMaxBackoff, ctx), c.keys[1:])
+		}
+	} else {
+		err = c.commitKeys(NewBackoffer(commitMaxBackoff, ctx), c.keys)
+	}
 	if err != nil {
 		if errors.Cause(err) == terror.ErrResultUndetermined {
 			c.mu.undetermined = true
@@ -603,6 +861,84 @@ func (c *twoPhaseCommitter) execute() error {
 	return nil
 }
 
+// keepAlive sends the heartbeats of a large transaction until stop is closed, the TTL of the primary lock
+// is extended, so the locks are not resolved by the other transactions during the long prewrite.
+func (c *twoPhaseCommitter) keepAlive(stop chan struct{}) {
+	ticker := time.NewTicker(largeTxnHeartBeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			elapsed := oracle.GetPhysical(time.Now()) - oracle.ExtractPhysical(c.startTS)
+			ttl := uint64(elapsed) + defaultLockTTL
+			bo := NewBackoffer(txnHeartBeatMaxBackoff, goctx.Background())
+			newTTL, err := c.store.txnHeartBeat(bo, c.primary(), c.startTS, ttl)
+			if err != nil {
+				log.Warnf("2PC txn heartbeat failed: %v, tid: %d", err, c.startTS)
+				continue
+			}
+			// The primary lock may be not prewritten yet.
+			log.Debugf("2PC txn heartbeat, ttl: %d, tid: %d", newTTL, c.startTS)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// supportTxnHeartBeat returns whether the store supports the TxnHeartBeat request, only mocktikv handles it for now.
+func (s *tikvStore) supportTxnHeartBeat() bool {
+	return s.mock
+}
+
+// SupportLargeTxn implements the kv.LargeTxnStorage interface. The large transaction keeps its locks alive by
+// the TxnHeartBeat requests while it commits, or its locks have a TTL by its size if the store doesn't
+// support the TxnHeartBeat request.
+func (s *tikvStore) SupportLargeTxn() bool {
+	return true
+}
+
+// txnHeartBeat extends the TTL of the primary lock of the transaction to adviseTTL, and returns the TTL of
+// the lock after it. It returns 0 if the primary lock doesn't exist.
+func (s *tikvStore) txnHeartBeat(bo *Backoffer, primary []byte, startTS, adviseTTL uint64) (uint64, error) {
+	req := &tikvrpc.Request{
+		Type: tikvrpc.CmdTxnHeartBeat,
+		TxnHeartBeat: &tikvrpc.TxnHeartBeatRequest{
+			PrimaryLock:   primary,
+			StartVersion:  startTS,
+			AdviseLockTtl: adviseTTL,
+		},
+	}
+	for {
+		loc, err := s.regionCache.LocateKey(bo, primary)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		resp, err := s.SendReq(bo, req, loc.Region, readTimeoutShort)
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		regionErr, err := resp.GetRegionError()
+		if err != nil {
+			return 0, errors.Trace(err)
+		}
+		if regionErr != nil {
+			err = bo.Backoff(boRegionMiss, errors.New(regionErr.String()))
+			if err != nil {
+				return 0, errors.Trace(err)
+			}
+			continue
+		}
+		cmdResp := resp.TxnHeartBeat
+		if cmdResp == nil {
+			return 0, errors.Trace(errBodyMissing)
+		}
+		if cmdResp.GetError() != nil {
+			return 0, nil
+		}
+		return cmdResp.LockTtl, nil
+	}
+}
+
 type schemaLeaseChecker interface {
 	Check(txnTS uint64) error
 }
@@ -659,6 +995,13 @@ func (c *twoPhaseCommitter) shouldWriteBinlog() bool {
 // Key+Value size below 16KB.
 const txnCommitBatchSize = 16 * 1024
 
+// largeTxnChunkKeys is the number of the keys in a chunk of a large transaction, the 2PC of a large
+// transaction runs chunk by chunk.
+const largeTxnChunkKeys = 10240
+
+// largeTxnHeartBeatInterval is the interval of the heartbeats of a large transaction.
+var largeTxnHeartBeatInterval = time.Second
+
 // batchKeys is a batch of keys in the same region.
 type batchKeys struct {
 	region RegionVerID
@@ -19,13 +19,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/coreos/etcd/pkg/monotime"
 	"github.com/juju/errors"
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/errorpb"
 	"github.com/pingcap/kvproto/pkg/kvrpcpb"
+	"github.com/pingcap/tidb/kv"
 	"github.com/pingcap/tidb/store/tikv/mock-tikv"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
 	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/deadlock"
 	goctx "golang.org/x/net/context"
 )
 
@@ -490,3 +493,274 @@ func (s *testCommitterSuite) TestCommitTimeout(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(len(value), Greater, 0)
 }
+
+func (s *testCommitterSuite) setForUpdateTS(c *C, txn *tikvTxn) {
+	ts, err := s.store.getTimestampWithRetry(NewBackoffer(tsoMaxBackoff, goctx.Background()))
+	c.Assert(err, IsNil)
+	txn.SetOption(kv.ForUpdateTS, ts)
+}
+
+func (s *testCommitterSuite) TestPessimisticLock(c *C) {
+	s.mustCommit(c, map[string]string{
+		"a": "a",
+		"b": "b",
+	})
+
+	txn1 := s.begin(c)
+	s.setForUpdateTS(c, txn1)
+	c.Assert(txn1.LockKeys(kv.Key("a")), IsNil)
+	c.Assert(txn1.primaryKey, DeepEquals, []byte("a"))
+	// The pessimistic lock doesn't block the reads.
+	s.checkValues(c, map[string]string{"a": "a"})
+	c.Assert(txn1.Set([]byte("a"), []byte("a1")), IsNil)
+	c.Assert(txn1.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"a": "a1"})
+
+	// The key is written after the forUpdateTS.
+	txn2 := s.begin(c)
+	s.setForUpdateTS(c, txn2)
+	s.mustCommit(c, map[string]string{"b": "b1"})
+	err := txn2.LockKeys(kv.Key("b"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrWriteConflict), IsTrue)
+	// Lock it again with a new forUpdateTS, the commit doesn't conflict though the key is written after startTS.
+	s.setForUpdateTS(c, txn2)
+	c.Assert(txn2.LockKeys(kv.Key("b")), IsNil)
+	c.Assert(txn2.Set([]byte("b"), []byte("b2")), IsNil)
+	c.Assert(txn2.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"b": "b2"})
+}
+
+func (s *testCommitterSuite) TestPessimisticLockTTL(c *C) {
+	defer func(ttl, pessimisticTTL uint64, interval time.Duration) {
+		defaultLockTTL, pessimisticLockTTL, largeTxnHeartBeatInterval = ttl, pessimisticTTL, interval
+	}(defaultLockTTL, pessimisticLockTTL, largeTxnHeartBeatInterval)
+	defaultLockTTL, pessimisticLockTTL, largeTxnHeartBeatInterval = 100, 100, 20*time.Millisecond
+
+	// The TTL of the primary lock is extended by the heartbeats while the transaction is alive.
+	txn := s.begin(c)
+	s.setForUpdateTS(c, txn)
+	c.Assert(txn.LockKeys(kv.Key("a")), IsNil)
+	time.Sleep(300 * time.Millisecond)
+	bo := NewBackoffer(prewriteMaxBackoff, goctx.Background())
+	lock := &Lock{Key: []byte("a"), Primary: []byte("a"), TxnID: txn.StartTS(), TTL: pessimisticLockTTL}
+	ok, err := s.store.lockResolver.ResolveLocks(bo, []*Lock{lock})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(txn.LockKeys(kv.Key("b")), IsNil)
+	c.Assert(txn.Set([]byte("a"), []byte("a1")), IsNil)
+	c.Assert(txn.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"a": "a1"})
+
+	// Without the heartbeats, the transaction fails after the TTL of the primary lock.
+	s.store.mock = false
+	defer func() { s.store.mock = true }()
+	txn = s.begin(c)
+	s.setForUpdateTS(c, txn)
+	c.Assert(txn.LockKeys(kv.Key("a")), IsNil)
+	time.Sleep(300 * time.Millisecond)
+	err = txn.LockKeys(kv.Key("b"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrPessimisticLockExpired), IsTrue)
+	c.Assert(txn.Set([]byte("a"), []byte("a2")), IsNil)
+	err = txn.Commit()
+	c.Assert(terror.ErrorEqual(err, kv.ErrPessimisticLockExpired), IsTrue)
+	s.checkValues(c, map[string]string{"a": "a1"})
+}
+
+// waitNotifyDetector sends the transaction it waits for to the channel when a transaction waits for a lock.
+// The waiting transaction checks the lock periodically, so the channel is unbuffered to drop the stale ones.
+type waitNotifyDetector struct {
+	*deadlock.Detector
+	waitFor chan uint64
+}
+
+func (d *waitNotifyDetector) Detect(sourceTxn, waitForTxn, keyHash uint64) error {
+	err := d.Detector.Detect(sourceTxn, waitForTxn, keyHash)
+	if err == nil {
+		select {
+		case d.waitFor <- waitForTxn:
+		default:
+		}
+	}
+	return err
+}
+
+// waitForLock returns after a transaction waits for the lock held by the transaction startTS.
+func (d *waitNotifyDetector) waitForLock(startTS uint64) {
+	for txn := range d.waitFor {
+		if txn == startTS {
+			return
+		}
+	}
+}
+
+func (s *testCommitterSuite) setWaitNotifyDetector() *waitNotifyDetector {
+	detector := &waitNotifyDetector{Detector: deadlock.NewDetector(), waitFor: make(chan uint64)}
+	s.store.SetDeadlockService(detector)
+	return detector
+}
+
+func (s *testCommitterSuite) TestPessimisticLockWait(c *C) {
+	defer func(timeout time.Duration) {
+		lockWaitTimeout = timeout
+	}(lockWaitTimeout)
+	lockWaitTimeout = 100 * time.Millisecond
+	detector := s.setWaitNotifyDetector()
+
+	txn1 := s.begin(c)
+	s.setForUpdateTS(c, txn1)
+	c.Assert(txn1.LockKeys(kv.Key("a")), IsNil)
+	txn2 := s.begin(c)
+	s.setForUpdateTS(c, txn2)
+	err := txn2.LockKeys(kv.Key("a"), kv.Key("b"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrLockWaitTimeout), IsTrue)
+
+	lockWaitTimeout = 5 * time.Second
+	// The lock is acquired after the holder rolls back.
+	go func() {
+		detector.waitForLock(txn1.StartTS())
+		txn1.Rollback()
+	}()
+	c.Assert(txn2.LockKeys(kv.Key("a")), IsNil)
+
+	// The holder commits after the forUpdateTS of the waiter.
+	txn3 := s.begin(c)
+	s.setForUpdateTS(c, txn3)
+	go func() {
+		detector.waitForLock(txn2.StartTS())
+		txn2.Set([]byte("a"), []byte("a2"))
+		txn2.Commit()
+	}()
+	err = txn3.LockKeys(kv.Key("a"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrWriteConflict), IsTrue)
+	c.Assert(txn3.Rollback(), IsNil)
+	s.checkValues(c, map[string]string{"a": "a2"})
+
+	// The waiter stops waiting when its statement is cancelled.
+	txn4 := s.begin(c)
+	s.setForUpdateTS(c, txn4)
+	c.Assert(txn4.LockKeys(kv.Key("a")), IsNil)
+	txn5 := s.begin(c)
+	s.setForUpdateTS(c, txn5)
+	ctx, cancel := goctx.WithCancel(goctx.Background())
+	txn5.SetOption(kv.LockCtx, ctx)
+	go func() {
+		detector.waitForLock(txn4.StartTS())
+		cancel()
+	}()
+	err = txn5.LockKeys(kv.Key("a"))
+	c.Assert(terror.ErrorEqual(err, goctx.Canceled), IsTrue)
+	c.Assert(txn5.Rollback(), IsNil)
+	c.Assert(txn4.Rollback(), IsNil)
+}
+
+func (s *testCommitterSuite) TestPessimisticDeadlock(c *C) {
+	detector := s.setWaitNotifyDetector()
+	txn1 := s.begin(c)
+	s.setForUpdateTS(c, txn1)
+	c.Assert(txn1.LockKeys(kv.Key("a")), IsNil)
+	txn2 := s.begin(c)
+	s.setForUpdateTS(c, txn2)
+	c.Assert(txn2.LockKeys(kv.Key("b")), IsNil)
+
+	ch := make(chan error)
+	go func() {
+		ch <- txn1.LockKeys(kv.Key("b"))
+	}()
+	detector.waitForLock(txn2.StartTS())
+	err := txn2.LockKeys(kv.Key("a"))
+	c.Assert(terror.ErrorEqual(err, kv.ErrDeadlock), IsTrue)
+	c.Assert(txn2.Rollback(), IsNil)
+	c.Assert(<-ch, IsNil)
+	c.Assert(txn1.Set([]byte("b"), []byte("b1")), IsNil)
+	c.Assert(txn1.Commit(), IsNil)
+	s.checkValues(c, map[string]string{"b": "b1"})
+}
+
+func (s *testCommitterSuite) TestLargeTxn(c *C) {
+	defer func(limit uint64, size int) {
+		kv.TxnEntryCountLimit, kv.LargeTxnSpillSize = limit, size
+	}(kv.TxnEntryCountLimit, kv.LargeTxnSpillSize)
+	kv.TxnEntryCountLimit, kv.LargeTxnSpillSize = 10, 1024
+
+	m := make(map[string]string)
+	for i := 0; i < 100; i++ {
+		k, v := randKV(10, 10)
+		m[k] = v
+	}
+	txn := s.begin(c)
+	var err error
+	for k, v := range m {
+		if err = txn.Set([]byte(k), []byte(v)); err != nil {
+			break
+		}
+	}
+	c.Assert(terror.ErrorEqual(err, kv.ErrTxnTooLarge), IsTrue)
+	c.Assert(txn.Rollback(), IsNil)
+
+	txn = s.begin(c)
+	c.Assert(txn.SetLarge(), IsNil)
+	for k, v := range m {
+		c.Assert(txn.Set([]byte(k), []byte(v)), IsNil)
+	}
+	committer, err := newTwoPhaseCommitter(txn)
+	c.Assert(err, IsNil)
+	c.Assert(committer.lockTTL, Less, maxLockTTL)
+	c.Assert(txn.Commit(), IsNil)
+	s.checkValues(c, m)
+
+	// Without the heartbeats, which are only supported by mocktikv, the lock TTL of the large transaction
+	// is by its size.
+	s.store.mock = false
+	defer func() { s.store.mock = true }()
+	txn = s.begin(c)
+	c.Assert(txn.SetLarge(), IsNil)
+	for k := range m {
+		c.Assert(txn.Set([]byte(k), []byte("v")), IsNil)
+	}
+	committer, err = newTwoPhaseCommitter(txn)
+	c.Assert(err, IsNil)
+	c.Assert(committer.lockTTL, GreaterEqual, defaultLockTTL)
+	c.Assert(txn.Commit(), IsNil)
+	for k := range m {
+		m[k] = "v"
+	}
+	s.checkValues(c, m)
+	c.Assert(largeTxnLockTTL(monotime.Now(), 100*bytesPerMiB), GreaterEqual, defaultLockTTL+100*largeTxnLockTTLPerMiB)
+	c.Assert(largeTxnLockTTL(monotime.Now(), 100*1024*bytesPerMiB), GreaterEqual, maxLargeTxnLockTTL)
+	c.Assert(largeTxnLockTTL(monotime.Now(), 100*1024*bytesPerMiB), Less, maxLargeTxnLockTTL+1000)
+}
+
+func (s *testCommitterSuite) TestLargeTxnHeartBeat(c *C) {
+	defer func(ttl uint64, interval time.Duration) {
+		defaultLockTTL, largeTxnHeartBeatInterval = ttl, interval
+	}(defaultLockTTL, largeTxnHeartBeatInterval)
+	defaultLockTTL, largeTxnHeartBeatInterval = 100, 20*time.Millisecond
+
+	txn := s.begin(c)
+	c.Assert(txn.SetLarge(), IsNil)
+	c.Assert(txn.Set([]byte("a"), []byte("a")), IsNil)
+	c.Assert(txn.Set([]byte("b"), []byte("b")), IsNil)
+	committer, err := newTwoPhaseCommitter(txn)
+	c.Assert(err, IsNil)
+	bo := NewBackoffer(prewriteMaxBackoff, goctx.Background())
+	c.Assert(committer.prewriteKeys(bo, committer.keys), IsNil)
+	lock := &Lock{Key: []byte("b"), Primary: []byte("a"), TxnID: txn.StartTS(), TTL: committer.lockTTL}
+
+	// The locks are not resolved while the transaction is alive.
+	stop := make(chan struct{})
+	go committer.keepAlive(stop)
+	time.Sleep(300 * time.Millisecond)
+	ok, err := s.store.lockResolver.ResolveLocks(bo, []*Lock{lock})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	c.Assert(s.isKeyLocked(c, []byte("b")), IsTrue)
+
+	// The locks are resolved after the heartbeats stop and the TTL expires.
+	close(stop)
+	time.Sleep(300 * time.Millisecond)
+	ok, err = s.store.lockResolver.ResolveLocks(bo, []*Lock{lock})
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(s.isKeyLocked(c, []byte("b")), IsFalse)
+	c.Assert(txn.Rollback(), IsNil)
+}
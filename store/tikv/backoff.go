@@ -128,6 +128,7 @@ const (
 	gcMaxBackoff            = 100000
 	gcResolveLockMaxBackoff = 100000
 	rawkvMaxBackoff         = 20000
+	txnHeartBeatMaxBackoff  = 5000
 )
 
 // Backoffer is a utility for retrying queries.
@@ -292,6 +292,10 @@ func (c *rpcClient) callRPC(ctx goctx.Context, client tikvpb.TikvClient, req *ti
 		}
 		resp.Cop = r
 		return resp, nil
+	case tikvrpc.CmdPessimisticLock:
+		return nil, errors.New("pessimistic lock is not supported by TiKV")
+	case tikvrpc.CmdTxnHeartBeat:
+		return nil, errors.New("txn heartbeat is not supported by TiKV")
 	default:
 		return nil, errors.Errorf("invalid request type: %v", req.Type)
 	}
@@ -91,6 +91,7 @@ func (c *CopClient) Send(ctx goctx.Context, req *kv.Request) kv.Response {
 		req:         req,
 		concurrency: req.Concurrency,
 		finished:    make(chan struct{}),
+		ctx:         ctx,
 	}
 	it.tasks = tasks
 	if it.concurrency > len(tasks) {
@@ -292,6 +293,9 @@ type copIterator struct {
 	concurrency int
 	finished    chan struct{}
 	taskCh      chan *copTask
+	// ctx is the context of the request, the workers stop when it's canceled, for example,
+	// the statement is killed or it exceeds its max execution time.
+	ctx goctx.Context
 
 	// If keepOrder, results are stored in copTask.respChan, read them out one by one.
 	tasks []*copTask
@@ -403,7 +407,8 @@ func (it *copIterator) Next() ([]byte, error) {
 		// Get next fetched resp from chan
 		resp, ok = <-it.respChan
 		if !ok {
-			return nil, nil
+			// The workers exit early if the request is canceled, the results are incomplete then.
+			return nil, errors.Trace(it.ctx.Err())
 		}
 	} else {
 		for {
@@ -412,7 +417,12 @@ func (it *copIterator) Next() ([]byte, error) {
 				return nil, nil
 			}
 			task := it.tasks[it.curr]
-			resp, ok = <-task.respChan
+			select {
+			case resp, ok = <-task.respChan:
+			case <-it.ctx.Done():
+				// The worker of the task may exit without closing its respChan if the request is canceled.
+				return nil, errors.Trace(it.ctx.Err())
+			}
 			if ok {
 				break
 			}
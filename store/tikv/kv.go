@@ -30,6 +30,7 @@ import (
 	"github.com/pingcap/tidb/store/tikv/oracle"
 	"github.com/pingcap/tidb/store/tikv/oracle/oracles"
 	"github.com/pingcap/tidb/store/tikv/tikvrpc"
+	"github.com/pingcap/tidb/util/deadlock"
 	goctx "golang.org/x/net/context"
 )
 
@@ -107,6 +108,9 @@ type tikvStore struct {
 	gcWorker     *GCWorker
 	etcdAddrs    []string
 	mock         bool
+
+	// deadlockDetector detects the deadlocks among the pessimistic transactions waiting for the locks.
+	deadlockDetector deadlock.Service
 }
 
 func newTikvStore(uuid string, pdClient pd.Client, client Client, enableGC bool) (*tikvStore, error) {
@@ -122,6 +126,8 @@ func newTikvStore(uuid string, pdClient pd.Client, client Client, enableGC bool)
 		client:      client,
 		regionCache: NewRegionCache(pdClient),
 		mock:        mock,
+
+		deadlockDetector: deadlock.NewDetector(),
 	}
 	store.lockResolver = newLockResolver(store)
 	if enableGC {
@@ -137,6 +143,18 @@ func (s *tikvStore) EtcdAddrs() []string {
 	return s.etcdAddrs
 }
 
+// SupportPessimisticTxn implements the kv.PessimisticTxnStorage interface. Only mocktikv handles the
+// PessimisticLock request for now.
+func (s *tikvStore) SupportPessimisticTxn() bool {
+	return s.mock
+}
+
+// SetDeadlockService sets the service detecting the deadlocks among the transactions of all the TiDB servers.
+// The store detects the deadlocks among its own transactions by default, it should be set before the store is used.
+func (s *tikvStore) SetDeadlockService(svc deadlock.Service) {
+	s.deadlockDetector = svc
+}
+
 type mockOptions struct {
 	cluster        *mocktikv.Cluster
 	mvccStore      *mocktikv.MvccStore
@@ -84,6 +84,13 @@ var maxLockTTL uint64 = 120000
 // ttl = ttlFactor * sqrt(writeSizeInMiB)
 var ttlFactor = 6000
 
+// The lock TTL of a large transaction which can't send the heartbeats,
+// ttl = largeTxnLockTTLPerMiB * writeSizeInMiB, and it's limited by maxLargeTxnLockTTL.
+var (
+	largeTxnLockTTLPerMiB uint64 = 1000
+	maxLargeTxnLockTTL    uint64 = 3600000
+)
+
 // Lock represents a lock from tikv server.
 type Lock struct {
 	Key     []byte
@@ -147,7 +154,15 @@ func (lr *LockResolver) ResolveLocks(bo *Backoffer, locks []*Lock) (ok bool, err
 
 	var expiredLocks []*Lock
 	for _, l := range locks {
-		if lr.store.oracle.IsExpired(l.TxnID, l.TTL) {
+		expired := lr.store.oracle.IsExpired(l.TxnID, l.TTL)
+		if expired {
+			alive, err := lr.isPrimaryAlive(bo, l)
+			if err != nil {
+				return false, errors.Trace(err)
+			}
+			expired = !alive
+		}
+		if expired {
 			lockResolverCounter.WithLabelValues("expired").Inc()
 			expiredLocks = append(expiredLocks, l)
 		} else {
@@ -181,6 +196,22 @@ func (lr *LockResolver) ResolveLocks(bo *Backoffer, locks []*Lock) (ok bool, err
 	return len(expiredLocks) == len(locks), nil
 }
 
+// isPrimaryAlive checks whether the primary lock of the expired lock is still alive, the TTL of the primary
+// lock of a large transaction is extended by its heartbeats.
+func (lr *LockResolver) isPrimaryAlive(bo *Backoffer, l *Lock) (bool, error) {
+	if !lr.store.supportTxnHeartBeat() {
+		return false, nil
+	}
+	if _, ok := lr.getResolved(l.TxnID); ok {
+		return false, nil
+	}
+	ttl, err := lr.store.txnHeartBeat(bo, l.Primary, l.TxnID, 0)
+	if err != nil {
+		return false, errors.Trace(err)
+	}
+	return ttl > 0 && !lr.store.oracle.IsExpired(l.TxnID, ttl), nil
+}
+
 // GetTxnStatus queries tikv-server for a txn's status (commit/rollback).
 // If the primary key is still locked, it will launch a Rollback to abort it.
 // To avoid unnecessarily aborting too many txns, it is wiser to wait a few
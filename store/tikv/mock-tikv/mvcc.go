@@ -45,6 +45,9 @@ type mvccLock struct {
 	value   []byte
 	op      kvrpcpb.Op
 	ttl     uint64
+	// forUpdateTS is not zero if the lock is a pessimistic lock, which is acquired before the 2PC and does
+	// not block the reads. It becomes a normal lock when the transaction prewrites the key.
+	forUpdateTS uint64
 }
 
 type mvccEntry struct {
@@ -77,6 +80,8 @@ func (e *mvccEntry) Clone() *mvccEntry {
 			value:   append([]byte(nil), e.lock.value...),
 			op:      e.lock.op,
 			ttl:     e.lock.ttl,
+
+			forUpdateTS: e.lock.forUpdateTS,
 		}
 	}
 	return &entry
@@ -97,7 +102,7 @@ func (e *mvccEntry) lockErr() error {
 
 func (e *mvccEntry) Get(ts uint64, isoLevel kvrpcpb.IsolationLevel) ([]byte, error) {
 	if isoLevel == kvrpcpb.IsolationLevel_SI {
-		if e.lock != nil && e.lock.startTS <= ts {
+		if e.lock != nil && e.lock.startTS <= ts && e.lock.forUpdateTS == 0 {
 			return nil, e.lockErr()
 		}
 	}
@@ -110,6 +115,18 @@ func (e *mvccEntry) Get(ts uint64, isoLevel kvrpcpb.IsolationLevel) ([]byte, err
 }
 
 func (e *mvccEntry) Prewrite(mutation *kvrpcpb.Mutation, startTS uint64, primary []byte, ttl uint64) error {
+	if e.lock != nil && e.lock.startTS == startTS && e.lock.forUpdateTS != 0 {
+		// The key is locked by the pessimistic lock of this transaction, the write conflict has been checked
+		// when the lock was acquired.
+		e.lock = &mvccLock{
+			startTS: startTS,
+			primary: primary,
+			value:   mutation.Value,
+			op:      mutation.GetOp(),
+			ttl:     ttl,
+		}
+		return nil
+	}
 	if len(e.values) > 0 {
 		if e.values[0].commitTS >= startTS {
 			return ErrRetryable("write conflict")
@@ -131,6 +148,40 @@ func (e *mvccEntry) Prewrite(mutation *kvrpcpb.Mutation, startTS uint64, primary
 	return nil
 }
 
+// PessimisticLock acquires a pessimistic lock on the key. It fails if the key is locked by another transaction,
+// or the key is written by a transaction committed after forUpdateTS.
+func (e *mvccEntry) PessimisticLock(startTS, forUpdateTS uint64, primary []byte, ttl uint64) error {
+	if e.lock != nil {
+		if e.lock.startTS != startTS {
+			return e.lockErr()
+		}
+		if e.lock.forUpdateTS != 0 && e.lock.forUpdateTS < forUpdateTS {
+			e.lock.forUpdateTS = forUpdateTS
+		}
+		return nil
+	}
+	for _, v := range e.values {
+		if v.valueType == typeRollback {
+			if v.startTS == startTS {
+				return ErrAbort("txn already rolled back")
+			}
+			continue
+		}
+		if v.commitTS >= forUpdateTS {
+			return ErrRetryable("write conflict")
+		}
+		break
+	}
+	e.lock = &mvccLock{
+		startTS:     startTS,
+		primary:     primary,
+		op:          kvrpcpb.Op_Lock,
+		ttl:         ttl,
+		forUpdateTS: forUpdateTS,
+	}
+	return nil
+}
+
 func (e *mvccEntry) getTxnCommitInfo(startTS uint64) *mvccValue {
 	for _, v := range e.values {
 		if v.startTS == startTS {
@@ -379,6 +430,48 @@ func (s *MvccStore) Prewrite(mutations []*kvrpcpb.Mutation, primary []byte, star
 	return errs
 }
 
+// PessimisticLock acquires the pessimistic locks on the keys before the 2PC.
+func (s *MvccStore) PessimisticLock(keys [][]byte, primary []byte, startTS, forUpdateTS uint64, ttl uint64) []error {
+	s.Lock()
+	defer s.Unlock()
+
+	var (
+		errs    []error
+		ents    []*mvccEntry
+		anyFail bool
+	)
+	for _, k := range keys {
+		entry := s.getOrNewEntry(NewMvccKey(k))
+		err := entry.PessimisticLock(startTS, forUpdateTS, primary, ttl)
+		if err != nil {
+			anyFail = true
+		}
+		ents = append(ents, entry)
+		errs = append(errs, err)
+	}
+	// Like TiKV, the keys are locked all or nothing, so a waiting txn doesn't hold part of the locks.
+	if !anyFail {
+		s.submit(ents...)
+	}
+	return errs
+}
+
+// TxnHeartBeat extends the TTL of the primary lock to adviseTTL, and returns the TTL of the lock after it.
+func (s *MvccStore) TxnHeartBeat(primary []byte, startTS, adviseTTL uint64) (uint64, error) {
+	s.Lock()
+	defer s.Unlock()
+
+	entry := s.getOrNewEntry(NewMvccKey(primary))
+	if entry.lock == nil || entry.lock.startTS != startTS {
+		return 0, ErrAbort("lock not found")
+	}
+	if entry.lock.ttl < adviseTTL {
+		entry.lock.ttl = adviseTTL
+		s.submit(entry)
+	}
+	return entry.lock.ttl, nil
+}
+
 // Commit commits the lock on a key. (2nd phase of 2PC).
 func (s *MvccStore) Commit(keys [][]byte, startTS, commitTS uint64) error {
 	s.Lock()
@@ -239,6 +239,33 @@ func (h *rpcHandler) handleKvPrewrite(req *kvrpcpb.PrewriteRequest) *kvrpcpb.Pre
 	}
 }
 
+func (h *rpcHandler) handleKvPessimisticLock(req *tikvrpc.PessimisticLockRequest) *tikvrpc.PessimisticLockResponse {
+	for _, k := range req.Keys {
+		if !h.checkKeyInRegion(k) {
+			panic("KvPessimisticLock: key not in region")
+		}
+	}
+	errors := h.mvccStore.PessimisticLock(req.Keys, req.PrimaryLock, req.StartVersion, req.ForUpdateTs, req.LockTtl)
+	return &tikvrpc.PessimisticLockResponse{
+		Errors: convertToKeyErrors(errors),
+	}
+}
+
+func (h *rpcHandler) handleKvTxnHeartBeat(req *tikvrpc.TxnHeartBeatRequest) *tikvrpc.TxnHeartBeatResponse {
+	if !h.checkKeyInRegion(req.PrimaryLock) {
+		panic("KvTxnHeartBeat: key not in region")
+	}
+	ttl, err := h.mvccStore.TxnHeartBeat(req.PrimaryLock, req.StartVersion, req.AdviseLockTtl)
+	if err != nil {
+		return &tikvrpc.TxnHeartBeatResponse{
+			Error: convertToKeyError(err),
+		}
+	}
+	return &tikvrpc.TxnHeartBeatResponse{
+		LockTtl: ttl,
+	}
+}
+
 func (h *rpcHandler) handleKvCommit(req *kvrpcpb.CommitRequest) *kvrpcpb.CommitResponse {
 	for _, k := range req.Keys {
 		if !h.checkKeyInRegion(k) {
@@ -508,6 +535,20 @@ func (c *RPCClient) SendReq(ctx goctx.Context, addr string, req *tikvrpc.Request
 			return nil, err
 		}
 		resp.Cop = res
+	case tikvrpc.CmdPessimisticLock:
+		r := req.PessimisticLock
+		if err := handler.checkRequestContext(reqCtx); err != nil {
+			resp.PessimisticLock = &tikvrpc.PessimisticLockResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.PessimisticLock = handler.handleKvPessimisticLock(r)
+	case tikvrpc.CmdTxnHeartBeat:
+		r := req.TxnHeartBeat
+		if err := handler.checkRequestContext(reqCtx); err != nil {
+			resp.TxnHeartBeat = &tikvrpc.TxnHeartBeatResponse{RegionError: err}
+			return resp, nil
+		}
+		resp.TxnHeartBeat = handler.handleKvTxnHeartBeat(r)
 	default:
 		return nil, errors.Errorf("unsupport this request type %v", req.Type)
 	}
@@ -36,6 +36,8 @@ const (
 	CmdScanLock
 	CmdResolveLock
 	CmdGC
+	CmdPessimisticLock
+	CmdTxnHeartBeat
 
 	CmdRawGet CmdType = 256 + iota
 	CmdRawPut
@@ -64,6 +66,9 @@ type Request struct {
 	RawDelete     *kvrpcpb.RawDeleteRequest
 	RawScan       *kvrpcpb.RawScanRequest
 	Cop           *coprocessor.Request
+
+	PessimisticLock *PessimisticLockRequest
+	TxnHeartBeat    *TxnHeartBeatRequest
 }
 
 // GetContext returns the rpc context for the underlying concrete request.
@@ -100,6 +105,10 @@ func (req *Request) GetContext() (*kvrpcpb.Context, error) {
 		c = req.RawScan.GetContext()
 	case CmdCop:
 		c = req.Cop.GetContext()
+	case CmdPessimisticLock:
+		c = req.PessimisticLock.GetContext()
+	case CmdTxnHeartBeat:
+		c = req.TxnHeartBeat.GetContext()
 	default:
 		return nil, fmt.Errorf("invalid request type %v", req.Type)
 	}
@@ -124,6 +133,9 @@ type Response struct {
 	RawDelete     *kvrpcpb.RawDeleteResponse
 	RawScan       *kvrpcpb.RawScanResponse
 	Cop           *coprocessor.Response
+
+	PessimisticLock *PessimisticLockResponse
+	TxnHeartBeat    *TxnHeartBeatResponse
 }
 
 // SetContext set the Context field for the given req to the specified ctx.
@@ -160,6 +172,10 @@ func SetContext(req *Request, ctx *kvrpcpb.Context) error {
 		req.RawScan.Context = ctx
 	case CmdCop:
 		req.Cop.Context = ctx
+	case CmdPessimisticLock:
+		req.PessimisticLock.Context = ctx
+	case CmdTxnHeartBeat:
+		req.TxnHeartBeat.Context = ctx
 	default:
 		return fmt.Errorf("invalid request type %v", req.Type)
 	}
@@ -232,6 +248,14 @@ func GenRegionErrorResp(req *Request, e *errorpb.Error) (*Response, error) {
 		resp.Cop = &coprocessor.Response{
 			RegionError: e,
 		}
+	case CmdPessimisticLock:
+		resp.PessimisticLock = &PessimisticLockResponse{
+			RegionError: e,
+		}
+	case CmdTxnHeartBeat:
+		resp.TxnHeartBeat = &TxnHeartBeatResponse{
+			RegionError: e,
+		}
 	default:
 		return nil, fmt.Errorf("invalid request type %v", req.Type)
 	}
@@ -272,8 +296,95 @@ func (resp *Response) GetRegionError() (*errorpb.Error, error) {
 		e = resp.RawScan.GetRegionError()
 	case CmdCop:
 		e = resp.Cop.GetRegionError()
+	case CmdPessimisticLock:
+		e = resp.PessimisticLock.GetRegionError()
+	case CmdTxnHeartBeat:
+		e = resp.TxnHeartBeat.GetRegionError()
 	default:
 		return nil, fmt.Errorf("invalid response type %v", resp.Type)
 	}
 	return e, nil
 }
+
+// PessimisticLockRequest acquires the pessimistic locks of the keys before the transaction commits.
+// The pessimistic lock is only supported by the mock TiKV now.
+type PessimisticLockRequest struct {
+	Context      *kvrpcpb.Context
+	Keys         [][]byte
+	PrimaryLock  []byte
+	StartVersion uint64
+	ForUpdateTs  uint64
+	LockTtl      uint64
+}
+
+// GetContext returns the rpc context of the request.
+func (r *PessimisticLockRequest) GetContext() *kvrpcpb.Context {
+	if r != nil {
+		return r.Context
+	}
+	return nil
+}
+
+// PessimisticLockResponse is the response of the PessimisticLockRequest.
+type PessimisticLockResponse struct {
+	RegionError *errorpb.Error
+	Errors      []*kvrpcpb.KeyError
+}
+
+// GetRegionError returns the region error of the response.
+func (r *PessimisticLockResponse) GetRegionError() *errorpb.Error {
+	if r != nil {
+		return r.RegionError
+	}
+	return nil
+}
+
+// GetErrors returns the key errors of the response.
+func (r *PessimisticLockResponse) GetErrors() []*kvrpcpb.KeyError {
+	if r != nil {
+		return r.Errors
+	}
+	return nil
+}
+
+// TxnHeartBeatRequest extends the TTL of the primary lock of a transaction, so the locks of a long running
+// transaction are not resolved by the others. It returns the current TTL of the lock if AdviseLockTtl is
+// less than it. The heartbeat is only supported by the mock TiKV now.
+type TxnHeartBeatRequest struct {
+	Context       *kvrpcpb.Context
+	PrimaryLock   []byte
+	StartVersion  uint64
+	AdviseLockTtl uint64
+}
+
+// GetContext returns the rpc context of the request.
+func (r *TxnHeartBeatRequest) GetContext() *kvrpcpb.Context {
+	if r != nil {
+		return r.Context
+	}
+	return nil
+}
+
+// TxnHeartBeatResponse is the response of the TxnHeartBeatRequest.
+type TxnHeartBeatResponse struct {
+	RegionError *errorpb.Error
+	// Error is set if the primary lock doesn't exist.
+	Error   *kvrpcpb.KeyError
+	LockTtl uint64
+}
+
+// GetRegionError returns the region error of the response.
+func (r *TxnHeartBeatResponse) GetRegionError() *errorpb.Error {
+	if r != nil {
+		return r.RegionError
+	}
+	return nil
+}
+
+// GetError returns the key error of the response.
+func (r *TxnHeartBeatResponse) GetError() *kvrpcpb.KeyError {
+	if r != nil {
+		return r.Error
+	}
+	return nil
+}
@@ -29,6 +29,18 @@ var (
 	_ kv.Transaction = (*tikvTxn)(nil)
 )
 
+var (
+	// lockWaitTimeout is the default max time the pessimistic lock waits for the locks held by other transactions.
+	lockWaitTimeout = 50 * time.Second
+	// lockWaitInterval is the interval to retry the pessimistic lock when it is waiting.
+	lockWaitInterval = 10 * time.Millisecond
+	// pessimisticLockTTL is the TTL of the pessimistic locks after the time they are acquired, it should be
+	// long enough for the transaction to run the rest statements. The TTL of the primary lock is extended by
+	// the heartbeats while the transaction is alive if the store supports them, otherwise the transaction
+	// fails if it lasts longer than the TTL.
+	pessimisticLockTTL uint64 = 20000
+)
+
 // tikvTxn implements kv.Transaction.
 type tikvTxn struct {
 	snapshot  *tikvSnapshot
@@ -40,6 +52,21 @@ type tikvTxn struct {
 	valid     bool
 	lockKeys  [][]byte
 	dirty     bool
+
+	// forUpdateTS is set when a pessimistic locking statement is running.
+	forUpdateTS uint64
+	// primaryKey is the first key locked by the pessimistic locks.
+	primaryKey []byte
+	// pessimisticLocked records the keys locked by the pessimistic locks.
+	pessimisticLocked map[string]struct{}
+	// primaryLockTTL is the TTL of the primary pessimistic lock when it's acquired.
+	primaryLockTTL uint64
+	// stopKeepAlive stops the heartbeats of the primary pessimistic lock.
+	stopKeepAlive chan struct{}
+	// lockWaitTimeout overrides the default lock wait timeout if it's not 0.
+	lockWaitTimeout time.Duration
+	// lockCtx is the context of the running pessimistic locking statement.
+	lockCtx goctx.Context
 }
 
 func newTiKVTxn(store *tikvStore) (*tikvTxn, error) {
@@ -120,16 +147,38 @@ func (txn *tikvTxn) SetOption(opt kv.Option, val interface{}) {
 		txn.snapshot.isolationLevel = val.(kv.IsoLevel)
 	case kv.Priority:
 		txn.snapshot.priority = kvPriorityToCommandPri(val.(int))
+	case kv.ForUpdateTS:
+		txn.forUpdateTS = val.(uint64)
+		txn.snapshot.version = kv.NewVersion(txn.forUpdateTS)
+	case kv.LockWaitTimeout:
+		txn.lockWaitTimeout = val.(time.Duration)
+	case kv.LockCtx:
+		txn.lockCtx = val.(goctx.Context)
 	}
 }
 
 func (txn *tikvTxn) DelOption(opt kv.Option) {
 	txn.us.DelOption(opt)
-	if opt == kv.IsolationLevel {
+	switch opt {
+	case kv.IsolationLevel:
 		txn.snapshot.isolationLevel = kv.SI
+	case kv.ForUpdateTS:
+		txn.forUpdateTS = 0
+		txn.snapshot.version = kv.NewVersion(txn.startTS)
+	case kv.LockWaitTimeout:
+		txn.lockWaitTimeout = 0
+	case kv.LockCtx:
+		txn.lockCtx = nil
 	}
 }
 
+func (txn *tikvTxn) SetLarge() error {
+	if !txn.store.SupportLargeTxn() {
+		return kv.ErrLargeTxnNotSupported
+	}
+	return errors.Trace(txn.us.SetLarge())
+}
+
 func (txn *tikvTxn) Commit() error {
 	if !txn.valid {
 		return kv.ErrInvalidTxn
@@ -141,11 +190,17 @@ func (txn *tikvTxn) Commit() error {
 	defer func() { txnCmdHistogram.WithLabelValues("commit").Observe(time.Since(start).Seconds()) }()
 
 	if err := txn.us.CheckLazyConditionPairs(); err != nil {
+		txn.rollbackPessimisticLocks()
+		return errors.Trace(err)
+	}
+	if err := txn.checkPessimisticLockExpired(); err != nil {
+		txn.rollbackPessimisticLocks()
 		return errors.Trace(err)
 	}
 
 	committer, err := newTwoPhaseCommitter(txn)
 	if err != nil {
+		txn.rollbackPessimisticLocks()
 		return errors.Trace(err)
 	}
 	if committer == nil {
@@ -154,6 +209,12 @@ func (txn *tikvTxn) Commit() error {
 	err = committer.execute()
 	if err != nil {
 		committer.writeFinishBinlog(binlog.BinlogType_Rollback, 0)
+		committer.mu.RLock()
+		undetermined := committer.mu.committed || committer.mu.undetermined
+		committer.mu.RUnlock()
+		if !undetermined {
+			txn.rollbackPessimisticLocks()
+		}
 		return errors.Trace(err)
 	}
 	committer.writeFinishBinlog(binlog.BinlogType_Commit, int64(committer.commitTS))
@@ -162,7 +223,12 @@ func (txn *tikvTxn) Commit() error {
 }
 
 func (txn *tikvTxn) close() error {
+	txn.us.Release()
 	txn.valid = false
+	if txn.stopKeepAlive != nil {
+		close(txn.stopKeepAlive)
+		txn.stopKeepAlive = nil
+	}
 	return nil
 }
 
@@ -174,17 +240,136 @@ func (txn *tikvTxn) Rollback() error {
 	log.Infof("[kv] Rollback txn %d", txn.StartTS())
 	txnCmdCounter.WithLabelValues("rollback").Inc()
 
+	txn.rollbackPessimisticLocks()
 	return nil
 }
 
 func (txn *tikvTxn) LockKeys(keys ...kv.Key) error {
 	txnCmdCounter.WithLabelValues("lock_keys").Inc()
+	if txn.forUpdateTS != 0 {
+		return errors.Trace(txn.pessimisticLockKeys(keys))
+	}
 	for _, key := range keys {
 		txn.lockKeys = append(txn.lockKeys, key)
 	}
 	return nil
 }
 
+// newPessimisticCommitter creates a committer to acquire or release the pessimistic locks.
+func (txn *tikvTxn) newPessimisticCommitter() *twoPhaseCommitter {
+	elapsed := time.Duration(monotime.Now()-txn.startTime) / time.Millisecond
+	timeout := txn.lockWaitTimeout
+	if timeout == 0 {
+		timeout = lockWaitTimeout
+	}
+	return &twoPhaseCommitter{
+		store:           txn.store,
+		txn:             txn,
+		startTS:         txn.startTS,
+		primaryKey:      txn.primaryKey,
+		forUpdateTS:     txn.forUpdateTS,
+		lockTTL:         pessimisticLockTTL + uint64(elapsed),
+		lockWaitTimeout: timeout,
+		priority:        getTxnPriority(txn),
+	}
+}
+
+// pessimisticLockKeys acquires the pessimistic locks of the keys at forUpdateTS. It waits if a key is locked by
+// another transaction, and fails if the key is written after forUpdateTS. The keys locked successfully are
+// kept even if it fails, they are released when the transaction finishes.
+func (txn *tikvTxn) pessimisticLockKeys(keys []kv.Key) error {
+	var toLock [][]byte
+	for _, key := range keys {
+		if _, ok := txn.pessimisticLocked[string(key)]; ok {
+			continue
+		}
+		if txn.pessimisticLocked == nil {
+			txn.pessimisticLocked = make(map[string]struct{})
+		}
+		txn.pessimisticLocked[string(key)] = struct{}{}
+		toLock = append(toLock, key)
+	}
+	if len(toLock) == 0 {
+		return nil
+	}
+	if err := txn.checkPessimisticLockExpired(); err != nil {
+		for _, key := range toLock {
+			delete(txn.pessimisticLocked, string(key))
+		}
+		return errors.Trace(err)
+	}
+	newPrimary := len(txn.primaryKey) == 0
+	if newPrimary {
+		txn.primaryKey = toLock[0]
+	}
+	ctx := txn.lockCtx
+	if ctx == nil {
+		ctx = goctx.Background()
+	}
+	committer := txn.newPessimisticCommitter()
+	bo := NewBackoffer(prewriteMaxBackoff, ctx)
+	err := committer.pessimisticLockKeys(bo, toLock)
+	committer.mu.RLock()
+	locked := committer.mu.writtenKeys
+	committer.mu.RUnlock()
+	if err != nil {
+		lockedSet := make(map[string]struct{}, len(locked))
+		for _, key := range locked {
+			lockedSet[string(key)] = struct{}{}
+		}
+		for _, key := range toLock {
+			if _, ok := lockedSet[string(key)]; !ok {
+				delete(txn.pessimisticLocked, string(key))
+			}
+		}
+		if newPrimary && len(locked) == 0 {
+			txn.primaryKey = nil
+		}
+	}
+	if newPrimary && len(txn.primaryKey) > 0 {
+		txn.primaryLockTTL = committer.lockTTL
+		if txn.store.supportTxnHeartBeat() {
+			txn.stopKeepAlive = make(chan struct{})
+			go committer.keepAlive(txn.stopKeepAlive)
+		}
+	}
+	// The locked keys are committed with the transaction, so the locks are released when it commits.
+	txn.lockKeys = append(txn.lockKeys, locked...)
+	return errors.Trace(err)
+}
+
+// checkPessimisticLockExpired returns an error if the primary pessimistic lock may be expired and released by the
+// other transactions. The TTL of the lock can't be extended if the store doesn't support the heartbeats.
+func (txn *tikvTxn) checkPessimisticLockExpired() error {
+	if len(txn.primaryKey) == 0 || txn.stopKeepAlive != nil {
+		return nil
+	}
+	if txn.store.oracle.IsExpired(txn.startTS, txn.primaryLockTTL) {
+		return kv.ErrPessimisticLockExpired.GenByArgs(txn.primaryLockTTL)
+	}
+	return nil
+}
+
+// rollbackPessimisticLocks releases the pessimistic locks held by the transaction.
+func (txn *tikvTxn) rollbackPessimisticLocks() {
+	if len(txn.pessimisticLocked) == 0 {
+		return
+	}
+	keys := make([][]byte, 0, len(txn.pessimisticLocked))
+	keys = append(keys, txn.primaryKey)
+	for key := range txn.pessimisticLocked {
+		if key != string(txn.primaryKey) {
+			keys = append(keys, []byte(key))
+		}
+	}
+	txn.pessimisticLocked = nil
+	committer := txn.newPessimisticCommitter()
+	err := committer.cleanupKeys(NewBackoffer(cleanupMaxBackoff, goctx.Background()), keys)
+	if err != nil {
+		log.Warnf("[kv] rollback pessimistic locks of txn %d err: %v", txn.startTS, err)
+	}
+}
+
 func (txn *tikvTxn) IsReadOnly() bool {
 	return !txn.dirty
 }
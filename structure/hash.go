@@ -208,6 +208,35 @@ func (t *TxStructure) HGetAll(key []byte) ([]HashPair, error) {
 	return res, errors.Trace(err)
 }
 
+// HGetRange gets the fields and values in a hash whose field is in [start, end), ordered by the field.
+func (t *TxStructure) HGetRange(key []byte, start []byte, end []byte) ([]HashPair, error) {
+	dataPrefix := t.hashDataKeyPrefix(key)
+	it, err := t.reader.Seek(t.encodeHashDataKey(key, start))
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer it.Close()
+
+	var res []HashPair
+	for it.Valid() && it.Key().HasPrefix(dataPrefix) {
+		_, field, err := t.decodeHashDataKey(it.Key())
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if bytes.Compare(field, end) >= 0 {
+			break
+		}
+		res = append(res, HashPair{
+			Field: append([]byte{}, field...),
+			Value: append([]byte{}, it.Value()...),
+		})
+		if err = it.Next(); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	return res, nil
+}
+
 // HClear removes the hash value of the key.
 func (t *TxStructure) HClear(key []byte) error {
 	metaKey := t.encodeHashMetaKey(key)
@@ -153,6 +153,25 @@ func (t *TxStructure) LIndex(key []byte, index int64) ([]byte, error) {
 	return nil, nil
 }
 
+// LGetAll gets all the elements of a list from left to right.
+func (t *TxStructure) LGetAll(key []byte) ([][]byte, error) {
+	metaKey := t.encodeListMetaKey(key)
+	meta, err := t.loadListMeta(metaKey)
+	if err != nil || meta.IsEmpty() {
+		return nil, errors.Trace(err)
+	}
+
+	values := make([][]byte, 0, meta.RIndex-meta.LIndex)
+	for index := meta.LIndex; index < meta.RIndex; index++ {
+		val, err := t.reader.Get(t.encodeListDataKey(key, index))
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
 // LSet updates an element in the list by its index.
 func (t *TxStructure) LSet(key []byte, index int64, value []byte) error {
 	if t.readWriter == nil {
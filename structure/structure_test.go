@@ -142,6 +142,10 @@ func (s *testTxStructureSuite) TestList(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(value, DeepEquals, []byte("4"))
 
+	values, err := tx.LGetAll(key)
+	c.Assert(err, IsNil)
+	c.Assert(values, DeepEquals, [][]byte{[]byte("2"), []byte("3"), []byte("4")})
+
 	value, err = tx.RPop(key)
 	c.Assert(err, IsNil)
 	c.Assert(value, DeepEquals, []byte("4"))
@@ -168,6 +172,10 @@ func (s *testTxStructureSuite) TestList(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(l, Equals, int64(0))
 
+	values, err = tx.LGetAll(key)
+	c.Assert(err, IsNil)
+	c.Assert(values, HasLen, 0)
+
 	err = txn.Commit()
 	c.Assert(err, IsNil)
 }
@@ -210,6 +218,16 @@ func (s *testTxStructureSuite) TestHash(c *C) {
 		{[]byte("1"), []byte("1")},
 		{[]byte("2"), []byte("2")}})
 
+	res, err = tx.HGetRange(key, []byte("1"), []byte("2"))
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []HashPair{{[]byte("1"), []byte("1")}})
+	res, err = tx.HGetRange(key, []byte("11"), []byte("3"))
+	c.Assert(err, IsNil)
+	c.Assert(res, DeepEquals, []HashPair{{[]byte("2"), []byte("2")}})
+	res, err = tx.HGetRange([]byte("b"), []byte("1"), []byte("3"))
+	c.Assert(err, IsNil)
+	c.Assert(res, HasLen, 0)
+
 	err = tx.HDel(key, []byte("1"))
 	c.Assert(err, IsNil)
 
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/tidb/expression"
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/util/types"
 )
 
@@ -155,6 +156,57 @@ func CastValue(ctx context.Context, val types.Datum, col *model.ColumnInfo) (cas
 	return casted, errors.Trace(err)
 }
 
+// CastChangingValue casts the value of the column which a changing column depends on to the type of the changing
+// column, see model.ChangeStateInfo. The errors are handled by sc like the truncated errors.
+func CastChangingValue(sc *variable.StatementContext, val types.Datum, col *model.ColumnInfo) (types.Datum, error) {
+	if val.IsNull() {
+		if !mysql.HasNotNullFlag(col.Flag) {
+			return val, nil
+		}
+		return GetZeroValue(col), errors.Trace(sc.HandleTruncate(errColumnCantNull))
+	}
+	if col.Tp == mysql.TypeEnum || col.Tp == mysql.TypeSet {
+		// Enum and set values are converted by their names rather than their numbers, the same as MySQL.
+		switch val.Kind() {
+		case types.KindMysqlEnum:
+			val = types.NewStringDatum(val.GetMysqlEnum().String())
+		case types.KindMysqlSet:
+			val = types.NewStringDatum(val.GetMysqlSet().String())
+		}
+	}
+	casted, err := val.ConvertTo(sc, &col.FieldType)
+	return casted, errors.Trace(sc.HandleTruncate(err))
+}
+
+// FillChangingValues returns the row with the values of the changing columns of the table, the row is extended to
+// contain all the columns of the table if it's necessary. The row isn't modified.
+// The obsolete columns and the columns which can't be written are always converted with the errors ignored.
+func FillChangingValues(sc *variable.StatementContext, tblInfo *model.TableInfo, row []types.Datum) ([]types.Datum, error) {
+	var newRow []types.Datum
+	for _, col := range tblInfo.Columns {
+		if col.ChangeStateInfo == nil {
+			continue
+		}
+		if newRow == nil {
+			newRow = make([]types.Datum, len(tblInfo.Columns))
+			copy(newRow, row)
+		}
+		colSC := sc
+		if col.ChangeStateInfo.Obsolete || col.State == model.StateDeleteOnly || col.State == model.StateDeleteReorganization {
+			colSC = &variable.StatementContext{IgnoreTruncate: true}
+		}
+		val, err := CastChangingValue(colSC, newRow[col.ChangeStateInfo.DependencyColumnOffset], col)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		newRow[col.Offset] = val
+	}
+	if newRow == nil {
+		return row, nil
+	}
+	return newRow, nil
+}
+
 // ColDesc describes column information like MySQL desc and show columns do.
 type ColDesc struct {
 	Field        string
@@ -267,8 +319,12 @@ func (c *Column) IsPKHandleColumn(tbInfo *model.TableInfo) bool {
 }
 
 // CheckNotNull checks if row has nil value set to a column with NotNull flag set.
+// The non-public columns are skipped, their values are filled when the row is written.
 func CheckNotNull(cols []*Column, row []types.Datum) error {
 	for _, c := range cols {
+		if c.State != model.StatePublic {
+			continue
+		}
 		if err := c.CheckNotNull(row[c.Offset]); err != nil {
 			return errors.Trace(err)
 		}
@@ -55,6 +55,8 @@ var (
 	ErrInvalidRecordKey = terror.ClassTable.New(codeInvalidRecordKey, "invalid record key")
 	// ErrTruncateWrongValue returns for truncate wrong value for field.
 	ErrTruncateWrongValue = terror.ClassTable.New(codeTruncateWrongValue, "Incorrect value")
+	// ErrNoPartitionForGivenValue returns when there is no partition for the row.
+	ErrNoPartitionForGivenValue = terror.ClassTable.New(codeNoPartitionForGivenValue, mysql.MySQLErrName[mysql.ErrNoPartitionForGivenValue])
 )
 
 // RecordIterFunc is used for low-level record iteration.
@@ -120,6 +122,26 @@ type Table interface {
 	Seek(ctx context.Context, h int64) (handle int64, found bool, err error)
 }
 
+// PhysicalTable is a Table which stores its data under its own physical table ID.
+// A non-partitioned table and a partition of a partitioned table are physical tables.
+type PhysicalTable interface {
+	Table
+
+	// GetPhysicalID returns the ID used to encode the keys of the table data.
+	GetPhysicalID() int64
+}
+
+// PartitionedTable is a Table whose rows are stored in its partitions.
+type PartitionedTable interface {
+	Table
+
+	// GetPartition returns the partition with the physical ID.
+	GetPartition(physicalID int64) PhysicalTable
+
+	// GetPartitionByRow returns the partition the row belongs to.
+	GetPartitionByRow(ctx context.Context, r []types.Datum) (PhysicalTable, error)
+}
+
 // TableFromMeta builds a table.Table from *model.TableInfo.
 // Currently, it is assigned to tables.TableFromMeta in tidb package's init function.
 var TableFromMeta func(alloc autoid.Allocator, tblInfo *model.TableInfo) (Table, error)
@@ -144,6 +166,8 @@ const (
 	codeDuplicateColumn    = 1110
 	codeNoDefaultValue     = 1364
 	codeTruncateWrongValue = 1366
+
+	codeNoPartitionForGivenValue = 1526
 )
 
 // Slice is used for table sorting.
@@ -164,6 +188,8 @@ func init() {
 		codeDuplicateColumn:    mysql.ErrFieldSpecifiedTwice,
 		codeNoDefaultValue:     mysql.ErrNoDefaultForField,
 		codeTruncateWrongValue: mysql.ErrTruncatedWrongValueForField,
+
+		codeNoPartitionForGivenValue: mysql.ErrNoPartitionForGivenValue,
 	}
 	terror.ErrClassToMySQLCodes[terror.ClassTable] = tableMySQLErrCodes
 }
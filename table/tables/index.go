@@ -99,12 +99,13 @@ type index struct {
 	prefix  kv.Key
 }
 
-// NewIndex builds a new Index object.
-func NewIndex(tableInfo *model.TableInfo, indexInfo *model.IndexInfo) table.Index {
+// NewIndex builds a new Index object. The index data is stored under the physicalID,
+// which is the ID of the table or the ID of a partition of the table.
+func NewIndex(physicalID int64, tableInfo *model.TableInfo, indexInfo *model.IndexInfo) table.Index {
 	index := &index{
 		tblInfo: tableInfo,
 		idxInfo: indexInfo,
-		prefix:  kv.Key(tablecodec.EncodeTableIndexPrefix(tableInfo.ID, indexInfo.ID)),
+		prefix:  kv.Key(tablecodec.EncodeTableIndexPrefix(physicalID, indexInfo.ID)),
 	}
 	return index
 }
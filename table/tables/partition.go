@@ -0,0 +1,291 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tables
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/juju/errors"
+	"github.com/pingcap/tidb/ast"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/expression"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/model"
+	"github.com/pingcap/tidb/table"
+	"github.com/pingcap/tidb/tablecodec"
+	"github.com/pingcap/tidb/terror"
+	"github.com/pingcap/tidb/util/types"
+)
+
+// partition is a partition of a partitioned table. It stores its data under the partition ID,
+// and shares the meta and the auto ID allocator with the partitioned table.
+type partition struct {
+	Table
+}
+
+// partitionedTable implements the table.PartitionedTable interface.
+// The rows are routed to the partitions by the value of the partition expression.
+type partitionedTable struct {
+	Table
+
+	partitions map[int64]*partition
+	// definitions is the partition list in the order of the partition definitions.
+	definitions []*partition
+
+	// exprMu protects partitionExpr, because rewriting it sets the types of the ast nodes.
+	exprMu        sync.Mutex
+	partitionExpr ast.ExprNode
+	// colOffset is the offset of the column if the partition expression is a column, otherwise it is -1.
+	colOffset int
+	// rangeBounds are the values of VALUES LESS THAN for range partitioning,
+	// the bound of a MAXVALUE partition is math.MaxInt64 and maxValue is true.
+	rangeBounds []int64
+	maxValue    bool
+}
+
+func newPartitionedTable(tbl *Table, tblInfo *model.TableInfo) (table.Table, error) {
+	pi := tblInfo.Partition
+	expr, err := parseExpression(pi.Expr)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	expr, err = simpleResolveName(expr, tblInfo)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	t := &partitionedTable{
+		Table:         *tbl,
+		partitions:    make(map[int64]*partition, len(pi.Definitions)),
+		partitionExpr: expr,
+		colOffset:     -1,
+	}
+	if col, ok := expr.(*ast.ColumnNameExpr); ok {
+		t.colOffset = col.Refer.Column.Offset
+	}
+	for _, def := range pi.Definitions {
+		p := &partition{Table: *tbl}
+		p.physicalTableID = def.ID
+		p.recordPrefix = tablecodec.GenTableRecordPrefix(def.ID)
+		p.indexPrefix = tablecodec.GenTableIndexPrefix(def.ID)
+		p.indices = make([]table.Index, 0, len(tblInfo.Indices))
+		for _, idxInfo := range tblInfo.Indices {
+			p.indices = append(p.indices, NewIndex(def.ID, tblInfo, idxInfo))
+		}
+		t.partitions[def.ID] = p
+		t.definitions = append(t.definitions, p)
+
+		if pi.Type != model.PartitionTypeRange {
+			continue
+		}
+		if len(def.LessThan) == 0 || def.LessThan[0] == "MAXVALUE" {
+			t.rangeBounds = append(t.rangeBounds, math.MaxInt64)
+			t.maxValue = true
+			continue
+		}
+		bound, err := strconv.ParseInt(def.LessThan[0], 10, 64)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		t.rangeBounds = append(t.rangeBounds, bound)
+	}
+	return t, nil
+}
+
+// GetPartition implements table.PartitionedTable GetPartition interface.
+func (t *partitionedTable) GetPartition(physicalID int64) table.PhysicalTable {
+	p, ok := t.partitions[physicalID]
+	if !ok {
+		return nil
+	}
+	return p
+}
+
+// GetPartitionByRow implements table.PartitionedTable GetPartitionByRow interface.
+func (t *partitionedTable) GetPartitionByRow(ctx context.Context, r []types.Datum) (table.PhysicalTable, error) {
+	p, err := t.locatePartition(ctx, r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return p, nil
+}
+
+// locatePartition finds the partition the row belongs to.
+func (t *partitionedTable) locatePartition(ctx context.Context, r []types.Datum) (*partition, error) {
+	v, isNull, err := t.evalPartitionExpr(ctx, r)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	var idx int
+	switch t.meta.Partition.Type {
+	case model.PartitionTypeHash:
+		// NULL is treated as 0 in hash partitioning.
+		if !isNull {
+			idx = int(v % int64(len(t.definitions)))
+			if idx < 0 {
+				idx = -idx
+			}
+		}
+	case model.PartitionTypeRange:
+		// NULL is less than any other value, so it is stored in the first partition.
+		if !isNull {
+			idx = sort.Search(len(t.rangeBounds), func(i int) bool {
+				return v < t.rangeBounds[i] || (t.maxValue && i == len(t.rangeBounds)-1)
+			})
+		}
+		if idx >= len(t.definitions) {
+			return nil, table.ErrNoPartitionForGivenValue.GenByArgs(strconv.FormatInt(v, 10))
+		}
+	}
+	return t.definitions[idx], nil
+}
+
+// evalPartitionExpr evaluates the partition expression on the row.
+func (t *partitionedTable) evalPartitionExpr(ctx context.Context, r []types.Datum) (int64, bool, error) {
+	var d types.Datum
+	if t.colOffset >= 0 {
+		d = r[t.colOffset]
+	} else {
+		t.exprMu.Lock()
+		expr, err := expression.RewriteAstExpr(ctx, t.partitionExpr, t.meta)
+		t.exprMu.Unlock()
+		if err != nil {
+			return 0, false, errors.Trace(err)
+		}
+		d, err = expr.Eval(r)
+		if err != nil {
+			return 0, false, errors.Trace(err)
+		}
+	}
+	if d.IsNull() {
+		return 0, true, nil
+	}
+	v, err := d.ToInt64(ctx.GetSessionVars().StmtCtx)
+	return v, false, errors.Trace(err)
+}
+
+// AddRecord implements table.Table AddRecord interface.
+func (t *partitionedTable) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
+	p, err := t.locatePartition(ctx, r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	// The handles are allocated by the partitioned table, so they are unique among all the partitions.
+	recordID, err = t.getRecordID(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	h, err := p.addRecord(ctx, recordID, r)
+	if err != nil {
+		return h, errors.Trace(err)
+	}
+	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
+	return recordID, nil
+}
+
+// UpdateRecord implements table.Table UpdateRecord interface.
+// If the new row belongs to another partition, the row is moved to that partition with the same handle.
+func (t *partitionedTable) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum, newData []types.Datum, touched map[int]bool) error {
+	from, err := t.locatePartition(ctx, oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	// The on update columns may be used in the partition expression, so they are set before locating the partition.
+	currentData := make([]types.Datum, len(t.WritableCols()))
+	copy(currentData, newData)
+	err = t.setOnUpdateData(ctx, touched, currentData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	t.composeNewData(touched, currentData, oldData)
+	to, err := t.locatePartition(ctx, currentData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if from == to {
+		return errors.Trace(from.UpdateRecord(ctx, h, oldData, currentData, touched))
+	}
+	err = from.RemoveRecord(ctx, h, oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	_, err = to.addRecord(ctx, h, currentData)
+	return errors.Trace(err)
+}
+
+// RemoveRecord implements table.Table RemoveRecord interface.
+func (t *partitionedTable) RemoveRecord(ctx context.Context, h int64, r []types.Datum) error {
+	p, err := t.locatePartition(ctx, r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(p.RemoveRecord(ctx, h, r))
+}
+
+// RowWithCols implements table.Table RowWithCols interface.
+// The handle is unique among all the partitions, so the partitions are searched one by one.
+func (t *partitionedTable) RowWithCols(ctx context.Context, h int64, cols []*table.Column) ([]types.Datum, error) {
+	for _, p := range t.definitions {
+		row, err := p.RowWithCols(ctx, h, cols)
+		if terror.ErrorEqual(err, kv.ErrNotExist) {
+			continue
+		}
+		return row, errors.Trace(err)
+	}
+	return nil, errors.Trace(kv.ErrNotExist)
+}
+
+// Row implements table.Table Row interface.
+func (t *partitionedTable) Row(ctx context.Context, h int64) ([]types.Datum, error) {
+	r, err := t.RowWithCols(ctx, h, t.Cols())
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return r, nil
+}
+
+// IterRecords implements table.Table IterRecords interface.
+// The records of the partitions are iterated in the order of the partition definitions.
+// If the startKey is a record key of a partition, the iteration starts from it,
+// otherwise it starts from the first partition.
+func (t *partitionedTable) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.Column,
+	fn table.RecordIterFunc) error {
+	start := 0
+	for i, p := range t.definitions {
+		if startKey.HasPrefix(p.RecordPrefix()) {
+			start = i
+			break
+		}
+	}
+	stopped := false
+	for i := start; i < len(t.definitions) && !stopped; i++ {
+		p := t.definitions[i]
+		key := p.FirstKey()
+		if startKey.HasPrefix(p.RecordPrefix()) {
+			key = startKey
+		}
+		err := p.IterRecords(ctx, key, cols, func(h int64, rec []types.Datum, cols []*table.Column) (bool, error) {
+			more, err := fn(h, rec, cols)
+			stopped = !more
+			return more, errors.Trace(err)
+		})
+		if err != nil {
+			return errors.Trace(err)
+		}
+	}
+	return nil
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pingcap/tidb/model"
 	"github.com/pingcap/tidb/mysql"
 	"github.com/pingcap/tidb/sessionctx/binloginfo"
+	"github.com/pingcap/tidb/sessionctx/variable"
 	"github.com/pingcap/tidb/table"
 	"github.com/pingcap/tidb/tablecodec"
 	"github.com/pingcap/tidb/terror"
@@ -44,6 +45,10 @@ type Table struct {
 	Name    model.CIStr
 	Columns []*table.Column
 
+	// physicalTableID is the ID used to encode the keys of the table data.
+	// It differs from ID when the Table is a partition of a partitioned table.
+	physicalTableID int64
+
 	publicColumns   []*table.Column
 	writableColumns []*table.Column
 	indices         []table.Index
@@ -99,22 +104,26 @@ func TableFromMeta(alloc autoid.Allocator, tblInfo *model.TableInfo) (table.Tabl
 			return nil, table.ErrIndexStateCantNone.Gen("index %s can't be in none state", idxInfo.Name)
 		}
 
-		idx := NewIndex(tblInfo, idxInfo)
+		idx := NewIndex(tblInfo.ID, tblInfo, idxInfo)
 		t.indices = append(t.indices, idx)
 	}
 
 	t.meta = tblInfo
+	if tblInfo.Partition != nil {
+		return newPartitionedTable(t, tblInfo)
+	}
 	return t, nil
 }
 
 // newTable constructs a Table instance.
 func newTable(tableID int64, cols []*table.Column, alloc autoid.Allocator) *Table {
 	t := &Table{
-		ID:           tableID,
-		recordPrefix: tablecodec.GenTableRecordPrefix(tableID),
-		indexPrefix:  tablecodec.GenTableIndexPrefix(tableID),
-		alloc:        alloc,
-		Columns:      cols,
+		ID:              tableID,
+		physicalTableID: tableID,
+		recordPrefix:    tablecodec.GenTableRecordPrefix(tableID),
+		indexPrefix:     tablecodec.GenTableIndexPrefix(tableID),
+		alloc:           alloc,
+		Columns:         cols,
 	}
 
 	t.publicColumns = t.Cols()
@@ -132,6 +141,11 @@ func (t *Table) Meta() *model.TableInfo {
 	return t.meta
 }
 
+// GetPhysicalID implements table.PhysicalTable GetPhysicalID interface.
+func (t *Table) GetPhysicalID() int64 {
+	return t.physicalTableID
+}
+
 // Cols implements table.Table Cols interface.
 func (t *Table) Cols() []*table.Column {
 	if len(t.publicColumns) > 0 {
@@ -203,9 +217,24 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 
 	// Compose new row
 	t.composeNewData(touched, currentData, oldData)
+	// The changing columns are converted from the new values of the columns they depend on, their old values are
+	// converted again to remove the old index entries.
+	currentData, err = table.FillChangingValues(ctx.GetSessionVars().StmtCtx, t.meta, currentData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	oldRow, err := table.FillChangingValues(&variable.StatementContext{IgnoreTruncate: true}, t.meta, oldData)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	for _, col := range t.Columns {
+		if col.ChangeStateInfo != nil {
+			touched[col.Offset] = true
+		}
+	}
 	colIDs := make([]int64, 0, len(t.WritableCols()))
 	for i, col := range t.WritableCols() {
-		if col.State != model.StatePublic && currentData[i].IsNull() {
+		if col.State != model.StatePublic && col.ChangeStateInfo == nil && currentData[i].IsNull() {
 			defaultVal, err1 := table.GetColDefaultValue(ctx, col.ToInfo())
 			if err1 != nil {
 				return errors.Trace(err1)
@@ -216,7 +245,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	}
 	// Set new row data into KV.
 	key := t.RecordKey(h)
-	value, err := tablecodec.EncodeRow(currentData, colIDs, ctx.GetSessionVars().GetTimeZone())
+	value, err := tablecodec.EncodeRow(currentData[:len(colIDs)], colIDs, ctx.GetSessionVars().GetTimeZone())
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -225,7 +254,7 @@ func (t *Table) UpdateRecord(ctx context.Context, h int64, oldData []types.Datum
 	}
 
 	// rebuild index
-	if err = t.rebuildIndices(bs, h, touched, oldData, currentData); err != nil {
+	if err = t.rebuildIndices(bs, h, touched, oldRow, currentData); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -302,20 +331,35 @@ func (t *Table) rebuildIndices(rm kv.RetrieverMutator, h int64, touched map[int]
 
 // AddRecord implements table.Table AddRecord interface.
 func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64, err error) {
-	var hasRecordID bool
+	recordID, err = t.getRecordID(r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
+	h, err := t.addRecord(ctx, recordID, r)
+	if err != nil {
+		return h, errors.Trace(err)
+	}
+	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
+	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
+	return recordID, nil
+}
+
+// getRecordID returns the handle of the new row, it is the value of the primary key if the primary key is the handle,
+// otherwise it is allocated.
+func (t *Table) getRecordID(r []types.Datum) (int64, error) {
 	for _, col := range t.Cols() {
 		if col.IsPKHandleColumn(t.meta) {
-			recordID = r[col.Offset].GetInt64()
-			hasRecordID = true
-			break
-		}
-	}
-	if !hasRecordID {
-		recordID, err = t.alloc.Alloc(t.ID)
-		if err != nil {
-			return 0, errors.Trace(err)
+			return r[col.Offset].GetInt64(), nil
 		}
 	}
+	recordID, err := t.alloc.Alloc(t.ID)
+	return recordID, errors.Trace(err)
+}
+
+// addRecord writes the row and its index entries with the handle recordID.
+// If a unique index entry already exists, it returns the handle of the existing row.
+func (t *Table) addRecord(ctx context.Context, recordID int64, r []types.Datum) (int64, error) {
+	var err error
 	txn := ctx.Txn()
 	skipCheck := ctx.GetSessionVars().SkipConstraintCheck
 	if skipCheck {
@@ -323,6 +367,10 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 	}
 
 	bs := kv.NewBufferStore(txn)
+	r, err = table.FillChangingValues(ctx.GetSessionVars().StmtCtx, t.meta, r)
+	if err != nil {
+		return 0, errors.Trace(err)
+	}
 	// Insert new entries into indices.
 	h, err := t.addIndices(ctx, recordID, r, bs)
 	if err != nil {
@@ -337,7 +385,11 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 			continue
 		}
 		var value types.Datum
-		if col.State == model.StateWriteOnly || col.State == model.StateWriteReorganization {
+		if col.ChangeStateInfo != nil {
+			// The value of the changing column is always saved, the null value can't be omitted
+			// because the column doesn't use the origin default value of the column it depends on.
+			value = r[col.Offset]
+		} else if col.State == model.StateWriteOnly || col.State == model.StateWriteReorganization {
 			// if col is in write only or write reorganization state, we must add it with its default value.
 			value, err = table.GetColDefaultValue(ctx, col.ToInfo())
 			if err != nil {
@@ -372,8 +424,6 @@ func (t *Table) AddRecord(ctx context.Context, r []types.Datum) (recordID int64,
 		mutation.InsertedRows = append(mutation.InsertedRows, bin)
 		mutation.Sequence = append(mutation.Sequence, binlog.MutationType_Insert)
 	}
-	ctx.GetSessionVars().StmtCtx.AddAffectedRows(1)
-	ctx.GetSessionVars().TxnCtx.UpdateDeltaForTable(t.ID, 1, 1)
 	return recordID, nil
 }
 
@@ -510,7 +560,12 @@ func (t *Table) RemoveRecord(ctx context.Context, h int64, r []types.Datum) erro
 		return errors.Trace(err)
 	}
 
-	err = t.removeRowIndices(ctx, h, r)
+	// The index entries of the changing columns are generated by their converted values.
+	row, err := table.FillChangingValues(&variable.StatementContext{IgnoreTruncate: true}, t.meta, r)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	err = t.removeRowIndices(ctx, h, row)
 	if err != nil {
 		return errors.Trace(err)
 	}
@@ -672,12 +727,12 @@ func (t *Table) IterRecords(ctx context.Context, startKey kv.Key, cols []*table.
 // The defaultVals is used to avoid calculating the default value multiple times.
 func GetColDefaultValue(ctx context.Context, col *table.Column, defaultVals []types.Datum) (
 	colVal types.Datum, err error) {
-	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
-		return colVal, errors.New("Miss column")
-	}
 	if col.State != model.StatePublic {
 		return colVal, nil
 	}
+	if col.OriginDefaultValue == nil && mysql.HasNotNullFlag(col.Flag) {
+		return colVal, errors.New("Miss column")
+	}
 	if defaultVals[col.Offset].IsNull() {
 		colVal, err = table.GetColOriginDefaultValue(ctx, col.ToInfo())
 		if err != nil {
@@ -708,7 +763,7 @@ func (t *Table) RebaseAutoID(newBase int64, isSetStep bool) error {
 
 // Seek implements table.Table Seek interface.
 func (t *Table) Seek(ctx context.Context, h int64) (int64, bool, error) {
-	seekKey := tablecodec.EncodeRowKeyWithHandle(t.ID, h)
+	seekKey := tablecodec.EncodeRowKeyWithHandle(t.physicalTableID, h)
 	iter, err := ctx.Txn().Seek(seekKey)
 	if !iter.Valid() || !iter.Key().HasPrefix(t.RecordPrefix()) {
 		// No more records in the table, skip to the end.
@@ -37,6 +37,7 @@ import (
 	"github.com/pingcap/tidb/store/localstore/boltdb"
 	"github.com/pingcap/tidb/store/tikv"
 	"github.com/pingcap/tidb/util/printer"
+	"github.com/pingcap/tidb/util/slowlog"
 	"github.com/pingcap/tipb/go-binlog"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/push"
@@ -58,6 +59,7 @@ var (
 	enablePrivilege = flag.Bool("privilege", true, "If enable privilege check feature. This flag will be removed in the future.")
 	reportStatus    = flag.Bool("report-status", true, "If enable status report HTTP service.")
 	logFile         = flag.String("log-file", "", "log file path")
+	slowLogFile     = flag.String("slow-log-file", "", "slow query log file path, the queries running longer than tidb_slow_log_threshold are written to it.")
 	joinCon         = flag.Int("join-concurrency", 5, "the number of goroutines that participate joining.")
 	crossJoin       = flag.Bool("cross-join", true, "whether support cartesian product or not.")
 	metricsAddr     = flag.String("metrics-addr", "", "prometheus pushgateway address, leaves it empty will disable prometheus push.")
@@ -66,6 +68,9 @@ var (
 	runDDL          = flag.Bool("run-ddl", true, "run ddl worker on this tidb-server")
 	retryLimit      = flag.Int("retry-limit", 10, "the maximum number of retries when commit a transaction")
 	skipGrantTable  = flag.Bool("skip-grant-table", false, "This option causes the server to start without using the privilege system at all.")
+	sslCA           = flag.String("ssl-ca", "", "path of the CA certificate file to verify the client certificates.")
+	sslCert         = flag.String("ssl-cert", "", "path of the server certificate file, TLS is enabled if it is set with ssl-key.")
+	sslKey          = flag.String("ssl-key", "", "path of the server private key file, TLS is enabled if it is set with ssl-cert.")
 
 	timeJumpBackCounter = prometheus.NewCounter(
 		prometheus.CounterOpts{
@@ -108,6 +113,9 @@ func main() {
 		ReportStatus: *reportStatus,
 		Store:        *store,
 		StorePath:    *storePath,
+		SSLCA:        *sslCA,
+		SSLCert:      *sslCert,
+		SSLKey:       *sslKey,
 	}
 
 	// set log options
@@ -119,6 +127,9 @@ func main() {
 		log.SetRotateByDay()
 		log.SetHighlighting(false)
 	}
+	if err := slowlog.SetFile(*slowLogFile); err != nil {
+		log.Fatal(errors.ErrorStack(err))
+	}
 
 	if joinCon != nil && *joinCon > 0 {
 		plan.JoinConcurrency = *joinCon
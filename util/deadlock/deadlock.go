@@ -0,0 +1,140 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadlock
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ErrDeadlock is returned when a deadlock is detected.
+type ErrDeadlock struct {
+	// KeyHash is the hash of the key on the wait-for edge which closes the cycle.
+	KeyHash uint64
+}
+
+func (e *ErrDeadlock) Error() string {
+	return fmt.Sprintf("deadlock(%d)", e.KeyHash)
+}
+
+// Service is the interface of the deadlock detection shared by the transactions waiting for the locks.
+type Service interface {
+	// Detect records that sourceTxn waits for the lock on the key with keyHash held by waitForTxn.
+	// It returns an ErrDeadlock if sourceTxn has to be aborted to break a deadlock.
+	Detect(sourceTxn, waitForTxn, keyHash uint64) error
+	// CleanUpWaitFor removes the record when sourceTxn doesn't wait for waitForTxn any more.
+	CleanUpWaitFor(sourceTxn, waitForTxn, keyHash uint64)
+}
+
+var _ Service = (*Detector)(nil)
+
+// Detector detects the deadlocks among the transactions waiting for the locks with a wait-for graph.
+// A vertex of the graph is a transaction, an edge from txn A to txn B means A waits for the lock held by B.
+type Detector struct {
+	mu         sync.Mutex
+	waitForMap map[uint64]*txnList
+}
+
+type txnList struct {
+	txns []txnKeyHashPair
+}
+
+type txnKeyHashPair struct {
+	txn     uint64
+	keyHash uint64
+}
+
+// NewDetector creates a deadlock detector.
+func NewDetector() *Detector {
+	return &Detector{
+		waitForMap: make(map[uint64]*txnList),
+	}
+}
+
+// Detect adds the edge from sourceTxn to waitForTxn into the graph if it doesn't form a cycle,
+// otherwise the edge is not added and an ErrDeadlock is returned.
+func (d *Detector) Detect(sourceTxn, waitForTxn, keyHash uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.doDetect(sourceTxn, waitForTxn); err != nil {
+		return err
+	}
+	d.register(sourceTxn, waitForTxn, keyHash)
+	return nil
+}
+
+// doDetect checks whether sourceTxn is reachable from waitForTxn.
+func (d *Detector) doDetect(sourceTxn, waitForTxn uint64) *ErrDeadlock {
+	visited := make(map[uint64]struct{})
+	stack := []uint64{waitForTxn}
+	for len(stack) > 0 {
+		txn := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if _, ok := visited[txn]; ok {
+			continue
+		}
+		visited[txn] = struct{}{}
+		list, ok := d.waitForMap[txn]
+		if !ok {
+			continue
+		}
+		for _, next := range list.txns {
+			if next.txn == sourceTxn {
+				return &ErrDeadlock{KeyHash: next.keyHash}
+			}
+			stack = append(stack, next.txn)
+		}
+	}
+	return nil
+}
+
+func (d *Detector) register(sourceTxn, waitForTxn, keyHash uint64) {
+	list, ok := d.waitForMap[sourceTxn]
+	if !ok {
+		d.waitForMap[sourceTxn] = &txnList{txns: []txnKeyHashPair{{txn: waitForTxn, keyHash: keyHash}}}
+		return
+	}
+	for _, pair := range list.txns {
+		if pair.txn == waitForTxn && pair.keyHash == keyHash {
+			return
+		}
+	}
+	list.txns = append(list.txns, txnKeyHashPair{txn: waitForTxn, keyHash: keyHash})
+}
+
+// CleanUp removes all the edges from the txn, it is called when the txn doesn't wait for any lock.
+func (d *Detector) CleanUp(txn uint64) {
+	d.mu.Lock()
+	delete(d.waitForMap, txn)
+	d.mu.Unlock()
+}
+
+// CleanUpWaitFor removes the edge from the txn to waitForTxn with the keyHash.
+func (d *Detector) CleanUpWaitFor(txn, waitForTxn, keyHash uint64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	list, ok := d.waitForMap[txn]
+	if !ok {
+		return
+	}
+	for i, pair := range list.txns {
+		if pair.txn == waitForTxn && pair.keyHash == keyHash {
+			list.txns = append(list.txns[:i], list.txns[i+1:]...)
+			break
+		}
+	}
+	if len(list.txns) == 0 {
+		delete(d.waitForMap, txn)
+	}
+}
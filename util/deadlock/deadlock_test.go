@@ -0,0 +1,57 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deadlock
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testDeadlockSuite{})
+
+type testDeadlockSuite struct{}
+
+func (s *testDeadlockSuite) TestDeadlock(c *C) {
+	d := NewDetector()
+	c.Assert(d.Detect(1, 2, 100), IsNil)
+	c.Assert(d.Detect(2, 3, 200), IsNil)
+	// Registering the same edge again is allowed.
+	c.Assert(d.Detect(2, 3, 200), IsNil)
+	c.Assert(d.waitForMap[2].txns, HasLen, 1)
+
+	err := d.Detect(3, 1, 300)
+	c.Assert(err, NotNil)
+	c.Assert(err.(*ErrDeadlock).KeyHash, Equals, uint64(200))
+	// The edge which forms the cycle is not added.
+	_, ok := d.waitForMap[3]
+	c.Assert(ok, IsFalse)
+
+	d.CleanUpWaitFor(2, 3, 200)
+	c.Assert(d.Detect(3, 1, 300), IsNil)
+	err = d.Detect(1, 3, 400)
+	c.Assert(err, NotNil)
+	c.Assert(err.(*ErrDeadlock).KeyHash, Equals, uint64(300))
+
+	d.CleanUp(3)
+	c.Assert(d.Detect(1, 3, 400), IsNil)
+	c.Assert(d.waitForMap[1].txns, HasLen, 2)
+	d.CleanUp(1)
+	c.Assert(d.waitForMap, HasLen, 0)
+}
@@ -0,0 +1,503 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waitfor
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/structure"
+	"github.com/pingcap/tidb/util/deadlock"
+	"github.com/twinj/uuid"
+)
+
+var (
+	// DetectInterval is the interval for the owner to detect the deadlocks in the wait-for graph while the graph
+	// isn't empty, it's also the interval for a server to write its wait-for edges and read its victims.
+	DetectInterval = 50 * time.Millisecond
+	// IdleDetectInterval is the interval for the owner to check the wait-for graph while the graph is empty.
+	IdleDetectInterval = time.Second
+	// RecordTTL is the time a wait-for edge, a victim mark or a server lives after it was written for the last
+	// time, so the records left by the crashed TiDB servers are removed eventually.
+	RecordTTL = 30 * time.Second
+)
+
+var _ deadlock.Service = (*GraphService)(nil)
+
+// edge means the source transaction waits for the lock on the key with keyHash held by the waitFor transaction.
+type edge struct {
+	source  uint64
+	waitFor uint64
+	keyHash uint64
+}
+
+func (e edge) encode() []byte {
+	b := make([]byte, 24)
+	binary.BigEndian.PutUint64(b, e.source)
+	binary.BigEndian.PutUint64(b[8:], e.waitFor)
+	binary.BigEndian.PutUint64(b[16:], e.keyHash)
+	return b
+}
+
+func decodeEdge(b []byte) (edge, error) {
+	if len(b) != 24 {
+		return edge{}, errors.Errorf("invalid wait-for edge %v", b)
+	}
+	return edge{
+		source:  binary.BigEndian.Uint64(b),
+		waitFor: binary.BigEndian.Uint64(b[8:]),
+		keyHash: binary.BigEndian.Uint64(b[16:]),
+	}, nil
+}
+
+func encodeTxn(txn uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, txn)
+	return b
+}
+
+func decodeTxn(b []byte) (uint64, error) {
+	if len(b) != 8 {
+		return 0, errors.Errorf("invalid transaction %v", b)
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// record is the value of a wait-for edge or a victim mark saved in the store.
+type record struct {
+	// KeyHash is the hash of the key on the edge closing the deadlock, it's only used by the victim marks.
+	KeyHash uint64 `json:"key_hash,omitempty"`
+	// Expire is the unix nano time after which the record is removed.
+	Expire int64 `json:"expire"`
+}
+
+func newRecord(keyHash uint64, now time.Time) []byte {
+	// Marshaling the record never fails.
+	data, _ := json.Marshal(&record{KeyHash: keyHash, Expire: now.Add(RecordTTL).UnixNano()})
+	return data
+}
+
+func decodeRecord(data []byte) (*record, error) {
+	r := &record{}
+	err := json.Unmarshal(data, r)
+	return r, errors.Trace(err)
+}
+
+// GraphService implements the deadlock.Service interface. The wait-for graph is saved in the store, so it's
+// shared by the transactions on all the TiDB servers. Every server writes the edges of its transactions in
+// batches to its own part of the graph, only the owner reads the whole graph and detects the deadlocks. The
+// owner chooses the youngest transaction in a deadlock as the victim, the server of the victim reads the victim
+// mark with its next batch, and the victim is aborted with an ErrDeadlock when it checks the graph the next time.
+type GraphService struct {
+	store   kv.Storage
+	isOwner func() bool
+	// id is the ID of this server in the graph.
+	id []byte
+
+	mu struct {
+		sync.Mutex
+		// edges is the wait-for edges of the transactions on this server to the last time they are written,
+		// it's the zero time if the edge isn't written yet.
+		edges map[edge]time.Time
+		// removed is the edges which are written but not waited for any more.
+		removed map[edge]struct{}
+		// victims is the victims among the transactions on this server to the key hash closing the deadlock.
+		victims map[uint64]uint64
+		// registered is the last time this server is written into the graph.
+		registered time.Time
+		// flushing is set when the flush loop is running.
+		flushing bool
+	}
+
+	wg   sync.WaitGroup
+	exit chan struct{}
+}
+
+// NewGraphService creates a GraphService and starts to detect the deadlocks when isOwner returns true.
+func NewGraphService(store kv.Storage, isOwner func() bool) *GraphService {
+	s := &GraphService{
+		store:   store,
+		isOwner: isOwner,
+		id:      []byte(uuid.NewV4().String()),
+		exit:    make(chan struct{}),
+	}
+	s.mu.edges = make(map[edge]time.Time)
+	s.mu.removed = make(map[edge]struct{})
+	s.mu.victims = make(map[uint64]uint64)
+	s.wg.Add(1)
+	go s.detectLoop()
+	return s
+}
+
+// Close stops detecting the deadlocks.
+func (s *GraphService) Close() {
+	s.mu.Lock()
+	close(s.exit)
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Detect implements deadlock.Service Detect interface. It's called repeatedly while the transaction is waiting,
+// it adds the edge to the next batch for the first time, and checks whether the transaction is chosen as a victim.
+func (s *GraphService) Detect(sourceTxn, waitForTxn, keyHash uint64) error {
+	e := edge{source: sourceTxn, waitFor: waitForTxn, keyHash: keyHash}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if victimKeyHash, ok := s.mu.victims[sourceTxn]; ok {
+		delete(s.mu.victims, sourceTxn)
+		return &deadlock.ErrDeadlock{KeyHash: victimKeyHash}
+	}
+	if _, ok := s.mu.edges[e]; !ok {
+		s.mu.edges[e] = time.Time{}
+		s.startFlushLocked()
+	}
+	return nil
+}
+
+// CleanUpWaitFor implements deadlock.Service CleanUpWaitFor interface.
+func (s *GraphService) CleanUpWaitFor(sourceTxn, waitForTxn, keyHash uint64) {
+	e := edge{source: sourceTxn, waitFor: waitForTxn, keyHash: keyHash}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lastWrite, ok := s.mu.edges[e]
+	if !ok {
+		return
+	}
+	delete(s.mu.edges, e)
+	if !lastWrite.IsZero() {
+		s.mu.removed[e] = struct{}{}
+		s.startFlushLocked()
+	}
+	// The victim mark is useless when the transaction doesn't wait any more.
+	if !s.isWaitingLocked(sourceTxn) {
+		delete(s.mu.victims, sourceTxn)
+	}
+}
+
+func (s *GraphService) isWaitingLocked(txn uint64) bool {
+	for e := range s.mu.edges {
+		if e.source == txn {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *GraphService) hasEdges() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.mu.edges) > 0
+}
+
+func (s *GraphService) startFlushLocked() {
+	if s.mu.flushing {
+		return
+	}
+	select {
+	case <-s.exit:
+		return
+	default:
+	}
+	s.mu.flushing = true
+	s.wg.Add(1)
+	go s.flushLoop()
+}
+
+// flushLoop writes the changes of the edges of this server and reads its victims every DetectInterval, it exits
+// when this server has no edges.
+func (s *GraphService) flushLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(DetectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.exit:
+			return
+		}
+		if err := s.flush(); err != nil {
+			log.Warnf("[deadlock] write wait-for edges failed %v", errors.ErrorStack(err))
+		}
+		s.mu.Lock()
+		if len(s.mu.edges) == 0 && len(s.mu.removed) == 0 {
+			s.mu.flushing = false
+			s.mu.Unlock()
+			return
+		}
+		s.mu.Unlock()
+	}
+}
+
+// flush writes the new edges and removes the old ones of this server in one transaction, the edges are written
+// again before they expire. The victims of this server are read in the same transaction.
+func (s *GraphService) flush() error {
+	now := time.Now()
+	s.mu.Lock()
+	var toWrite, toRemove []edge
+	for e, lastWrite := range s.mu.edges {
+		if now.Sub(lastWrite) > RecordTTL/3 {
+			toWrite = append(toWrite, e)
+		}
+	}
+	for e := range s.mu.removed {
+		toRemove = append(toRemove, e)
+	}
+	register := len(toWrite) > 0 && now.Sub(s.mu.registered) > RecordTTL/3
+	s.mu.Unlock()
+
+	var victims []structure.HashPair
+	err := kv.RunInNewTxn(s.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		if register {
+			if err := t.SetDeadlockServer(s.id, newRecord(0, now)); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		for _, e := range toWrite {
+			if err := t.SetWaitForEdge(s.id, e.encode(), newRecord(0, now)); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		if len(toRemove) > 0 {
+			fields := make([][]byte, 0, len(toRemove))
+			for _, e := range toRemove {
+				fields = append(fields, e.encode())
+			}
+			if err := t.RemoveWaitForEdges(s.id, fields...); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		var err error
+		victims, err = t.GetDeadlockVictims(s.id)
+		if err != nil || len(victims) == 0 {
+			return errors.Trace(err)
+		}
+		fields := make([][]byte, 0, len(victims))
+		for _, pair := range victims {
+			fields = append(fields, pair.Field)
+		}
+		return errors.Trace(t.RemoveDeadlockVictims(s.id, fields...))
+	})
+	if err != nil {
+		return errors.Trace(err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if register {
+		s.mu.registered = now
+	}
+	for _, e := range toWrite {
+		if _, ok := s.mu.edges[e]; ok {
+			s.mu.edges[e] = now
+		} else {
+			// The edge is cleaned up while it's being written.
+			s.mu.removed[e] = struct{}{}
+		}
+	}
+	for _, e := range toRemove {
+		// The edge may be added again while it's being removed, it's written again by the next batch then.
+		delete(s.mu.removed, e)
+	}
+	for _, pair := range victims {
+		txn, err1 := decodeTxn(pair.Field)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		r, err1 := decodeRecord(pair.Value)
+		if err1 != nil {
+			return errors.Trace(err1)
+		}
+		if s.isWaitingLocked(txn) {
+			s.mu.victims[txn] = r.KeyHash
+		}
+	}
+	return nil
+}
+
+// detectLoop detects the deadlocks every DetectInterval when this server is the owner. The graph is checked every
+// IdleDetectInterval instead while it's empty, unless this server has edges.
+func (s *GraphService) detectLoop() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(DetectInterval)
+	defer ticker.Stop()
+	var (
+		active     bool
+		lastDetect time.Time
+	)
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.exit:
+			return
+		}
+		if !s.isOwner() {
+			active = false
+			continue
+		}
+		if !active && !s.hasEdges() && time.Since(lastDetect) < IdleDetectInterval {
+			continue
+		}
+		lastDetect = time.Now()
+		var err error
+		active, err = s.detect()
+		if err != nil {
+			log.Warnf("[deadlock] detect deadlocks failed %v", errors.ErrorStack(err))
+		}
+	}
+}
+
+// serverEdge is a wait-for edge and the server which writes it.
+type serverEdge struct {
+	edge
+	server []byte
+}
+
+// detect chooses a victim for every deadlock in the graph, and removes the expired records. It returns whether
+// the graph isn't empty.
+func (s *GraphService) detect() (bool, error) {
+	var active bool
+	err := kv.RunInNewTxn(s.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		now := time.Now()
+		active = false
+		servers, err := t.GetDeadlockServers()
+		if err != nil {
+			return errors.Trace(err)
+		}
+		var edges []serverEdge
+		// The transactions which are chosen as victims already aren't chosen again.
+		victims := make(map[uint64]struct{})
+		for _, server := range servers {
+			r, err1 := decodeRecord(server.Value)
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+			if r.Expire < now.UnixNano() {
+				// The server has no edges or crashed.
+				if err1 = t.RemoveDeadlockServer(server.Field); err1 != nil {
+					return errors.Trace(err1)
+				}
+				continue
+			}
+			active = true
+			serverEdges, serverVictims, err1 := s.loadServer(t, server.Field, now)
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+			edges = append(edges, serverEdges...)
+			for _, victim := range serverVictims {
+				victims[victim] = struct{}{}
+			}
+		}
+
+		// The edges are added in the order of the source transactions, so the edge closing a cycle is from
+		// the youngest transaction in the cycle.
+		sort.Slice(edges, func(i, j int) bool {
+			if edges[i].source != edges[j].source {
+				return edges[i].source < edges[j].source
+			}
+			if edges[i].waitFor != edges[j].waitFor {
+				return edges[i].waitFor < edges[j].waitFor
+			}
+			return edges[i].keyHash < edges[j].keyHash
+		})
+		d := deadlock.NewDetector()
+		for _, e := range edges {
+			if _, ok := victims[e.source]; ok {
+				continue
+			}
+			err1 := d.Detect(e.source, e.waitFor, e.keyHash)
+			if err1 == nil {
+				continue
+			}
+			log.Infof("[deadlock] txn %d waits for txn %d and forms a deadlock, abort it", e.source, e.waitFor)
+			victims[e.source] = struct{}{}
+			d.CleanUp(e.source)
+			err1 = t.SetDeadlockVictim(e.server, encodeTxn(e.source), newRecord(err1.(*deadlock.ErrDeadlock).KeyHash, now))
+			if err1 != nil {
+				return errors.Trace(err1)
+			}
+		}
+		return nil
+	})
+	return active, errors.Trace(err)
+}
+
+// loadServer reads the unexpired edges and victims of the server, and removes the expired ones.
+func (s *GraphService) loadServer(t *meta.Meta, server []byte, now time.Time) ([]serverEdge, []uint64, error) {
+	pairs, err := t.GetWaitForEdges(server)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var (
+		edges   []serverEdge
+		expired [][]byte
+	)
+	for _, pair := range pairs {
+		e, err1 := decodeEdge(pair.Field)
+		if err1 != nil {
+			return nil, nil, errors.Trace(err1)
+		}
+		r, err1 := decodeRecord(pair.Value)
+		if err1 != nil {
+			return nil, nil, errors.Trace(err1)
+		}
+		if r.Expire < now.UnixNano() {
+			expired = append(expired, pair.Field)
+			continue
+		}
+		edges = append(edges, serverEdge{edge: e, server: server})
+	}
+	if len(expired) > 0 {
+		if err = t.RemoveWaitForEdges(server, expired...); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+
+	pairs, err = t.GetDeadlockVictims(server)
+	if err != nil {
+		return nil, nil, errors.Trace(err)
+	}
+	var victims []uint64
+	expired = expired[:0]
+	for _, pair := range pairs {
+		txn, err1 := decodeTxn(pair.Field)
+		if err1 != nil {
+			return nil, nil, errors.Trace(err1)
+		}
+		r, err1 := decodeRecord(pair.Value)
+		if err1 != nil {
+			return nil, nil, errors.Trace(err1)
+		}
+		if r.Expire < now.UnixNano() {
+			expired = append(expired, pair.Field)
+			continue
+		}
+		victims = append(victims, txn)
+	}
+	if len(expired) > 0 {
+		if err = t.RemoveDeadlockVictims(server, expired...); err != nil {
+			return nil, nil, errors.Trace(err)
+		}
+	}
+	return edges, victims, nil
+}
@@ -0,0 +1,149 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package waitfor_test
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/deadlock"
+	"github.com/pingcap/tidb/util/deadlock/waitfor"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testGraphServiceSuite{})
+
+type testGraphServiceSuite struct {
+	store kv.Storage
+}
+
+func (s *testGraphServiceSuite) SetUpSuite(c *C) {
+	store, err := tikv.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	s.store = store
+}
+
+func (s *testGraphServiceSuite) TearDownSuite(c *C) {
+	s.store.Close()
+}
+
+func (s *testGraphServiceSuite) countRecords(c *C) (int, int) {
+	var edges, victims int
+	err := kv.RunInNewTxn(s.store, false, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		servers, err := t.GetDeadlockServers()
+		c.Assert(err, IsNil)
+		edges, victims = 0, 0
+		for _, server := range servers {
+			pairs, err := t.GetWaitForEdges(server.Field)
+			c.Assert(err, IsNil)
+			edges += len(pairs)
+			pairs, err = t.GetDeadlockVictims(server.Field)
+			c.Assert(err, IsNil)
+			victims += len(pairs)
+		}
+		return nil
+	})
+	c.Assert(err, IsNil)
+	return edges, victims
+}
+
+// waitRecords waits for the servers to write their edges and the owner to detect the deadlocks until the numbers
+// of the records are expected.
+func (s *testGraphServiceSuite) waitRecords(c *C, expectedEdges, expectedVictims int) {
+	var edges, victims int
+	for i := 0; i < 100; i++ {
+		edges, victims = s.countRecords(c)
+		if edges == expectedEdges && victims == expectedVictims {
+			return
+		}
+		time.Sleep(waitfor.DetectInterval)
+	}
+	c.Fatalf("edges %d victims %d, expected edges %d victims %d", edges, victims, expectedEdges, expectedVictims)
+}
+
+// waitDeadlock checks the graph repeatedly as a waiting transaction does until the transaction is aborted.
+func waitDeadlock(c *C, service *waitfor.GraphService, sourceTxn, waitForTxn, keyHash uint64) error {
+	for i := 0; i < 100; i++ {
+		if err := service.Detect(sourceTxn, waitForTxn, keyHash); err != nil {
+			return err
+		}
+		time.Sleep(waitfor.DetectInterval)
+	}
+	c.Fatalf("txn %d isn't aborted", sourceTxn)
+	return nil
+}
+
+func (s *testGraphServiceSuite) TestGraphService(c *C) {
+	defer testleak.AfterTest(c)()
+	defer func(interval, idleInterval, ttl time.Duration) {
+		waitfor.DetectInterval = interval
+		waitfor.IdleDetectInterval = idleInterval
+		waitfor.RecordTTL = ttl
+	}(waitfor.DetectInterval, waitfor.IdleDetectInterval, waitfor.RecordTTL)
+	waitfor.DetectInterval = 10 * time.Millisecond
+	waitfor.IdleDetectInterval = 50 * time.Millisecond
+	// The services simulate two TiDB servers sharing the same store, s1 is the owner when isOwner is 1.
+	var isOwner int32
+	s1 := waitfor.NewGraphService(s.store, func() bool { return atomic.LoadInt32(&isOwner) == 1 })
+	defer s1.Close()
+	s2 := waitfor.NewGraphService(s.store, func() bool { return false })
+	defer s2.Close()
+
+	// The edges are written in batches.
+	c.Assert(s1.Detect(1, 2, 100), IsNil)
+	c.Assert(s2.Detect(2, 3, 200), IsNil)
+	c.Assert(s2.Detect(3, 1, 300), IsNil)
+	c.Assert(s1.Detect(1, 2, 100), IsNil)
+	s.waitRecords(c, 3, 0)
+
+	// The youngest transaction in the deadlock is the victim.
+	atomic.StoreInt32(&isOwner, 1)
+	err := waitDeadlock(c, s2, 3, 1, 300)
+	c.Assert(err.(*deadlock.ErrDeadlock).KeyHash, Equals, uint64(200))
+	c.Assert(s1.Detect(1, 2, 100), IsNil)
+	c.Assert(s2.Detect(2, 3, 200), IsNil)
+	s2.CleanUpWaitFor(3, 1, 300)
+	s.waitRecords(c, 2, 0)
+
+	// The victim mark isn't kept once the victim stops waiting.
+	c.Assert(s2.Detect(3, 1, 300), IsNil)
+	c.Assert(waitDeadlock(c, s2, 3, 1, 300), NotNil)
+	s2.CleanUpWaitFor(3, 1, 300)
+	s1.CleanUpWaitFor(1, 2, 100)
+	s2.CleanUpWaitFor(2, 3, 200)
+	s.waitRecords(c, 0, 0)
+	c.Assert(s1.Detect(1, 2, 100), IsNil)
+	s1.CleanUpWaitFor(1, 2, 100)
+
+	// The records left by a crashed server are removed by the owner once they expire.
+	waitfor.RecordTTL = 500 * time.Millisecond
+	atomic.StoreInt32(&isOwner, 0)
+	s3 := waitfor.NewGraphService(s.store, func() bool { return false })
+	c.Assert(s3.Detect(4, 5, 400), IsNil)
+	s.waitRecords(c, 1, 0)
+	s3.Close()
+	atomic.StoreInt32(&isOwner, 1)
+	s.waitRecords(c, 0, 0)
+}
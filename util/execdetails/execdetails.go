@@ -0,0 +1,43 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package execdetails
+
+import (
+	"sync/atomic"
+)
+
+// ExecDetails contains the details of the coprocessor requests of a statement.
+// It's updated by the goroutines fetching the coprocessor responses concurrently.
+type ExecDetails struct {
+	copTasks int64
+	// processedKeys is the number of the rows returned by the coprocessor tasks,
+	// TiKV doesn't report the number of the keys it scans yet.
+	processedKeys int64
+}
+
+// AddCopTask records a finished coprocessor task which returns rows.
+func (d *ExecDetails) AddCopTask(rows int64) {
+	atomic.AddInt64(&d.copTasks, 1)
+	atomic.AddInt64(&d.processedKeys, rows)
+}
+
+// CopTasks returns the number of the finished coprocessor tasks.
+func (d *ExecDetails) CopTasks() int64 {
+	return atomic.LoadInt64(&d.copTasks)
+}
+
+// ProcessedKeys returns the number of the keys processed by the coprocessor tasks.
+func (d *ExecDetails) ProcessedKeys() int64 {
+	return atomic.LoadInt64(&d.processedKeys)
+}
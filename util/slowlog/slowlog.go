@@ -0,0 +1,297 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package slowlog writes the slow queries to the slow query log file and parses the file.
+//
+// An entry of the file looks like:
+//
+//	# Time: 2017-10-17T12:04:05.123456+08:00
+//	# Txn_start_ts: 395216544395313153
+//	# User: root@127.0.0.1
+//	# Conn_ID: 3
+//	# Query_time: 0.512
+//	# Parse_time: 0.000105
+//	# Compile_time: 0.000481
+//	# Cop_tasks: 4
+//	# Process_keys: 20480
+//	# DB: test
+//	# Index_names: t:idx_a
+//	# Digest: 9d6f7e9b...
+//	select * from t where a > 1;
+//
+// The query always ends with ";". It's written in a single line, the backslashes and line breaks in it are
+// escaped, so it can't be mistaken for the fields or another entry.
+package slowlog
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+)
+
+// The field names in the slow query log.
+const (
+	fieldPrefix      = "# "
+	fieldTime        = "Time"
+	fieldTxnStartTS  = "Txn_start_ts"
+	fieldUser        = "User"
+	fieldConnID      = "Conn_ID"
+	fieldQueryTime   = "Query_time"
+	fieldParseTime   = "Parse_time"
+	fieldCompileTime = "Compile_time"
+	fieldCopTasks    = "Cop_tasks"
+	fieldProcessKeys = "Process_keys"
+	fieldDB          = "DB"
+	fieldIndexNames  = "Index_names"
+	fieldDigest      = "Digest"
+)
+
+// Entry is a slow query.
+type Entry struct {
+	Time        time.Time
+	TxnStartTS  uint64
+	User        string
+	ConnID      uint64
+	QueryTime   time.Duration
+	ParseTime   time.Duration
+	CompileTime time.Duration
+	CopTasks    int64
+	ProcessKeys int64
+	DB          string
+	// IndexNames are the indices used by the query, in the format of "table:index".
+	IndexNames []string
+	Digest     string
+	Query      string
+}
+
+var logger = struct {
+	sync.Mutex
+	path string
+	file *os.File
+}{}
+
+// SetFile sets the slow query log file, the slow queries are appended to it.
+// An empty path disables the slow query log.
+func SetFile(path string) error {
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.file != nil {
+		logger.file.Close()
+		logger.file, logger.path = nil, ""
+	}
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	logger.file, logger.path = f, path
+	return nil
+}
+
+// FilePath returns the path of the slow query log file, it's empty if the slow query log is disabled.
+func FilePath() string {
+	logger.Lock()
+	defer logger.Unlock()
+	return logger.path
+}
+
+// Write appends the entry to the slow query log file, it does nothing if the slow query log is disabled.
+func Write(e *Entry) error {
+	logger.Lock()
+	defer logger.Unlock()
+	if logger.file == nil {
+		return nil
+	}
+	_, err := io.WriteString(logger.file, e.String())
+	return errors.Trace(err)
+}
+
+func formatDuration(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// String returns the entry in the format of the slow query log.
+func (e *Entry) String() string {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{fieldTime, e.Time.Format(time.RFC3339Nano)},
+		{fieldTxnStartTS, strconv.FormatUint(e.TxnStartTS, 10)},
+		{fieldUser, escape(e.User)},
+		{fieldConnID, strconv.FormatUint(e.ConnID, 10)},
+		{fieldQueryTime, formatDuration(e.QueryTime)},
+		{fieldParseTime, formatDuration(e.ParseTime)},
+		{fieldCompileTime, formatDuration(e.CompileTime)},
+		{fieldCopTasks, strconv.FormatInt(e.CopTasks, 10)},
+		{fieldProcessKeys, strconv.FormatInt(e.ProcessKeys, 10)},
+		{fieldDB, escape(e.DB)},
+		{fieldIndexNames, escape(strings.Join(e.IndexNames, ","))},
+		{fieldDigest, escape(e.Digest)},
+	}
+	var buf bytes.Buffer
+	for _, f := range fields {
+		fmt.Fprintf(&buf, "%s%s: %s\n", fieldPrefix, f.name, f.value)
+	}
+	query := escape(e.Query)
+	if strings.HasPrefix(query, "#") {
+		// The query beginning with a comment must not be taken as a field.
+		query = `\` + query
+	}
+	buf.WriteString(query)
+	if !strings.HasSuffix(e.Query, ";") {
+		buf.WriteString(";")
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
+
+// ReadFile parses the last maxSize bytes of the slow query log file, the earlier entries are ignored.
+func ReadFile(path string, maxSize int64) ([]*Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if size := stat.Size(); size > maxSize {
+		// The entry cut off at the beginning has no time, so it's dropped by Parse.
+		if _, err = f.Seek(size-maxSize, io.SeekStart); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+	entries, err := Parse(f)
+	return entries, errors.Trace(err)
+}
+
+// Parse parses the slow query log. The invalid fields are ignored, and an entry is dropped if it has no time.
+func Parse(r io.Reader) ([]*Entry, error) {
+	var (
+		entries []*Entry
+		e       *Entry
+	)
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, errors.Trace(err)
+		}
+		eof := err == io.EOF
+		line = strings.TrimSuffix(line, "\n")
+		if strings.HasPrefix(line, fieldPrefix+fieldTime+": ") {
+			e = &Entry{}
+		}
+		if e != nil && line != "" {
+			if strings.HasPrefix(line, fieldPrefix) {
+				e.parseField(line[len(fieldPrefix):])
+			} else {
+				e.Query = unescape(line)
+				if !e.Time.IsZero() {
+					entries = append(entries, e)
+				}
+				e = nil
+			}
+		}
+		if eof {
+			return entries, nil
+		}
+	}
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`)
+
+// escape escapes the backslashes and line breaks in s.
+func escape(s string) string {
+	return escaper.Replace(s)
+}
+
+// unescape reverses escape, a backslash followed by any other character is dropped.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	buf := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			buf = append(buf, s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		default:
+			buf = append(buf, s[i])
+		}
+	}
+	return string(buf)
+}
+
+func (e *Entry) parseField(field string) {
+	idx := strings.Index(field, ": ")
+	if idx < 0 {
+		return
+	}
+	name, value := field[:idx], field[idx+2:]
+	switch name {
+	case fieldTime:
+		e.Time, _ = time.Parse(time.RFC3339Nano, value)
+	case fieldTxnStartTS:
+		e.TxnStartTS, _ = strconv.ParseUint(value, 10, 64)
+	case fieldUser:
+		e.User = unescape(value)
+	case fieldConnID:
+		e.ConnID, _ = strconv.ParseUint(value, 10, 64)
+	case fieldQueryTime:
+		e.QueryTime = parseDuration(value)
+	case fieldParseTime:
+		e.ParseTime = parseDuration(value)
+	case fieldCompileTime:
+		e.CompileTime = parseDuration(value)
+	case fieldCopTasks:
+		e.CopTasks, _ = strconv.ParseInt(value, 10, 64)
+	case fieldProcessKeys:
+		e.ProcessKeys, _ = strconv.ParseInt(value, 10, 64)
+	case fieldDB:
+		e.DB = unescape(value)
+	case fieldIndexNames:
+		if value != "" {
+			e.IndexNames = strings.Split(unescape(value), ",")
+		}
+	case fieldDigest:
+		e.Digest = unescape(value)
+	}
+}
+
+func parseDuration(s string) time.Duration {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
@@ -0,0 +1,121 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package slowlog
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/util/testleak"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testSlowLogSuite{})
+
+type testSlowLogSuite struct {
+}
+
+func (s *testSlowLogSuite) TestWriteAndRead(c *C) {
+	defer testleak.AfterTest(c)()
+	dir, err := ioutil.TempDir("", "slowlog")
+	c.Assert(err, IsNil)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+
+	// Nothing is written if the slow query log is disabled.
+	e1 := &Entry{
+		Time:        time.Unix(1508212345, 123456000),
+		TxnStartTS:  395216544395313153,
+		User:        "root@127.0.0.1",
+		ConnID:      3,
+		QueryTime:   512 * time.Millisecond,
+		ParseTime:   105 * time.Microsecond,
+		CompileTime: 481 * time.Microsecond,
+		CopTasks:    4,
+		ProcessKeys: 20480,
+		DB:          "test",
+		IndexNames:  []string{"t:idx_a", "t:idx_b"},
+		Digest:      "abc",
+		Query:       "select * from t where a > 1;",
+	}
+	c.Assert(Write(e1), IsNil)
+	c.Assert(FilePath(), Equals, "")
+
+	c.Assert(SetFile(path), IsNil)
+	defer SetFile("")
+	c.Assert(FilePath(), Equals, path)
+	c.Assert(Write(e1), IsNil)
+	e2 := &Entry{
+		Time:  time.Unix(1508212346, 0),
+		Query: "select *\n# not a field\nfrom t;",
+	}
+	c.Assert(Write(e2), IsNil)
+
+	entries, err := ReadFile(path, math.MaxInt64)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 2)
+	c.Assert(entries[0].Time.Equal(e1.Time), IsTrue)
+	entries[0].Time = e1.Time
+	c.Assert(entries[0], DeepEquals, e1)
+	c.Assert(entries[1].Query, Equals, e2.Query)
+	c.Assert(entries[1].IndexNames, IsNil)
+
+	// Only the entries in the last maxSize bytes are read.
+	entries, err = ReadFile(path, int64(len(e2.String())+10))
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].Query, Equals, e2.Query)
+
+	// The invalid fields are ignored, and the entries without time are dropped.
+	log := "# Time: 2017-10-17T12:04:05+08:00\n# Conn_ID: x\n# Unknown: 1\nselect 1;\n# Conn_ID: 2\nselect 2;\n"
+	entries, err = Parse(strings.NewReader(log))
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, 1)
+	c.Assert(entries[0].ConnID, Equals, uint64(0))
+	c.Assert(entries[0].Query, Equals, "select 1;")
+}
+
+func (s *testSlowLogSuite) TestForgedEntry(c *C) {
+	defer testleak.AfterTest(c)()
+	// The query and the fields can't forge the fields or the entries.
+	queries := []string{
+		"select ';\n# Time: 2017-10-17T12:04:05+08:00\n# User: root@127.0.0.1\nselect 1;';",
+		"# Time: 2017-10-17T12:04:05+08:00\nselect 1;",
+		"select '\\n', '\r\n';",
+	}
+	var buf bytes.Buffer
+	for _, query := range queries {
+		e := &Entry{Time: time.Unix(1508212345, 0), User: "a\n# DB: b", DB: "c\\", Query: query}
+		buf.WriteString(e.String())
+	}
+	entries, err := Parse(&buf)
+	c.Assert(err, IsNil)
+	c.Assert(entries, HasLen, len(queries))
+	for i, e := range entries {
+		c.Assert(e.Query, Equals, queries[i])
+		c.Assert(e.User, Equals, "a\n# DB: b")
+		c.Assert(e.DB, Equals, "c\\")
+	}
+}
@@ -46,6 +46,10 @@ func interestingGoroutines() (gs []string) {
 			strings.Contains(stack, "testing.(*T).Run") ||
 			strings.Contains(stack, "domain.(*Domain).LoadPrivilegeLoop") ||
 			strings.Contains(stack, "domain.(*Domain).UpdateTableStatsLoop") ||
+			strings.Contains(stack, "domain.(*Domain).AutoAnalyzeLoop") ||
+			strings.Contains(stack, "userlocks.(*LockManager).renewLoop") ||
+			strings.Contains(stack, "waitfor.(*GraphService).detectLoop") ||
+			strings.Contains(stack, "waitfor.(*GraphService).flushLoop") ||
 			strings.Contains(stack, "testing.Main(") ||
 			strings.Contains(stack, "runtime.goexit") ||
 			strings.Contains(stack, "created by runtime.gc") ||
@@ -0,0 +1,58 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userlock
+
+import (
+	"time"
+
+	"github.com/pingcap/tidb/context"
+)
+
+type keyType int
+
+func (k keyType) String() string {
+	return "user-lock-key"
+}
+
+// Manager is the interface for the user level locks acquired by GET_LOCK.
+// The locks are owned by the sessions.
+type Manager interface {
+	// GetLock tries to acquire the lock for the session in timeout, it waits forever if timeout is negative.
+	// It returns false if the lock is held by another session until timeout.
+	// A session can acquire the same lock multiple times, and it has to release the lock the same times.
+	GetLock(ctx context.Context, name string, timeout time.Duration) (bool, error)
+	// ReleaseLock releases the lock once, it returns false if the session doesn't hold the lock.
+	ReleaseLock(ctx context.Context, name string) (bool, error)
+	// ReleaseAllLocks releases all the locks held by the session and returns the number of the locks released,
+	// the locks acquired multiple times are counted multiple times.
+	ReleaseAllLocks(ctx context.Context) (int64, error)
+	// IsUsedLock returns the connection ID of the session holding the lock, the second result is false if
+	// the lock is free.
+	IsUsedLock(name string) (uint64, bool, error)
+}
+
+const key keyType = 0
+
+// BindManager binds Manager to context.
+func BindManager(ctx context.Context, m Manager) {
+	ctx.SetValue(key, m)
+}
+
+// GetManager gets Manager from context.
+func GetManager(ctx context.Context) Manager {
+	if v, ok := ctx.Value(key).(Manager); ok {
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userlocks
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/ngaut/log"
+	"github.com/pingcap/tidb/context"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/meta"
+	"github.com/pingcap/tidb/util/userlock"
+	"github.com/twinj/uuid"
+)
+
+var (
+	// LockTTL is the time a lock lives after it was renewed by its TiDB server for the last time.
+	LockTTL = 30 * time.Second
+	// waitInterval is the interval for checking whether a lock is released when waiting for it.
+	waitInterval = 50 * time.Millisecond
+)
+
+var _ userlock.Manager = (*LockManager)(nil)
+
+// lockOwner is the value of a lock saved in the store.
+type lockOwner struct {
+	ServerID string `json:"server_id"`
+	// OwnerID identifies the session in the TiDB server, the connection ID can't be used
+	// because it's 0 for the internal sessions.
+	OwnerID uint64 `json:"owner_id"`
+	ConnID  uint64 `json:"conn_id"`
+	// Expire is the unix nano time after which the lock can be acquired by other sessions.
+	Expire int64 `json:"expire"`
+}
+
+func (o *lockOwner) expired(now time.Time) bool {
+	return o.Expire < now.UnixNano()
+}
+
+// session holds the locks acquired by a session.
+type session struct {
+	ownerID uint64
+	connID  uint64
+	// locks is the lock name to the number of times the lock is acquired.
+	locks map[string]int
+}
+
+// LockManager implements the userlock.Manager interface. The locks are stored in the store,
+// so they are exclusive among all the TiDB servers sharing the same store.
+// A lock is owned by a session, and it is released when the session releases it or is closed.
+// If the TiDB server of the owner is gone, the lock expires after LockTTL.
+type LockManager struct {
+	store    kv.Storage
+	serverID string
+
+	mu struct {
+		sync.Mutex
+		nextOwnerID uint64
+		sessions    map[context.Context]*session
+	}
+
+	wg   sync.WaitGroup
+	exit chan struct{}
+}
+
+// NewLockManager creates a LockManager and starts to renew the locks it holds.
+func NewLockManager(store kv.Storage) *LockManager {
+	m := &LockManager{
+		store:    store,
+		serverID: uuid.NewV4().String(),
+		exit:     make(chan struct{}),
+	}
+	m.mu.sessions = make(map[context.Context]*session)
+	m.wg.Add(1)
+	go m.renewLoop()
+	return m
+}
+
+// Close stops renewing the locks.
+func (m *LockManager) Close() {
+	close(m.exit)
+	m.wg.Wait()
+}
+
+func (m *LockManager) getSession(ctx context.Context, createIfNotExists bool) *session {
+	s, ok := m.mu.sessions[ctx]
+	if !ok && createIfNotExists {
+		m.mu.nextOwnerID++
+		s = &session{
+			ownerID: m.mu.nextOwnerID,
+			connID:  ctx.GetSessionVars().ConnectionID,
+			locks:   make(map[string]int),
+		}
+		m.mu.sessions[ctx] = s
+	}
+	return s
+}
+
+func (m *LockManager) newOwner(s *session) *lockOwner {
+	return &lockOwner{
+		ServerID: m.serverID,
+		OwnerID:  s.ownerID,
+		ConnID:   s.connID,
+		Expire:   time.Now().Add(LockTTL).UnixNano(),
+	}
+}
+
+func (m *LockManager) isOwner(s *session, o *lockOwner) bool {
+	return o.ServerID == m.serverID && o.OwnerID == s.ownerID
+}
+
+// GetLock implements userlock.Manager GetLock interface.
+func (m *LockManager) GetLock(ctx context.Context, name string, timeout time.Duration) (bool, error) {
+	name = strings.ToLower(name)
+	m.mu.Lock()
+	s := m.getSession(ctx, true)
+	if s.locks[name] > 0 {
+		s.locks[name]++
+		m.mu.Unlock()
+		return true, nil
+	}
+	m.mu.Unlock()
+
+	deadline := time.Now().Add(timeout)
+	for {
+		acquired, err := m.tryLock(s, name)
+		if err != nil {
+			return false, errors.Trace(err)
+		}
+		if acquired {
+			m.mu.Lock()
+			s.locks[name]++
+			m.mu.Unlock()
+			return true, nil
+		}
+		wait := waitInterval
+		if timeout >= 0 {
+			remaining := deadline.Sub(time.Now())
+			if remaining <= 0 {
+				return false, nil
+			}
+			if remaining < wait {
+				wait = remaining
+			}
+		}
+		// The waiting is stopped by KILL QUERY or max_execution_time too.
+		select {
+		case <-time.After(wait):
+		case <-ctx.GoCtx().Done():
+			return false, errors.Trace(ctx.GoCtx().Err())
+		case <-m.exit:
+			return false, nil
+		}
+	}
+}
+
+// tryLock acquires the lock in the store if it's not held by others or it's expired.
+func (m *LockManager) tryLock(s *session, name string) (bool, error) {
+	acquired := false
+	err := kv.RunInNewTxn(m.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		o, err := getOwner(t, name)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		if o != nil && !o.expired(time.Now()) && !m.isOwner(s, o) {
+			acquired = false
+			return nil
+		}
+		acquired = true
+		return errors.Trace(setOwner(t, name, m.newOwner(s)))
+	})
+	return acquired, errors.Trace(err)
+}
+
+// ReleaseLock implements userlock.Manager ReleaseLock interface.
+// The lock is removed from the store without holding m.mu, so a slow store doesn't block the other sessions.
+func (m *LockManager) ReleaseLock(ctx context.Context, name string) (bool, error) {
+	name = strings.ToLower(name)
+	m.mu.Lock()
+	s := m.getSession(ctx, false)
+	if s == nil || s.locks[name] == 0 {
+		m.mu.Unlock()
+		return false, nil
+	}
+	s.locks[name]--
+	if s.locks[name] > 0 {
+		m.mu.Unlock()
+		return true, nil
+	}
+	delete(s.locks, name)
+	m.mu.Unlock()
+	err := m.removeLocks(s, []string{name})
+	return true, errors.Trace(err)
+}
+
+// ReleaseAllLocks implements userlock.Manager ReleaseAllLocks interface.
+func (m *LockManager) ReleaseAllLocks(ctx context.Context) (int64, error) {
+	m.mu.Lock()
+	s := m.getSession(ctx, false)
+	if s == nil {
+		m.mu.Unlock()
+		return 0, nil
+	}
+	delete(m.mu.sessions, ctx)
+	var count int64
+	names := make([]string, 0, len(s.locks))
+	for name, cnt := range s.locks {
+		count += int64(cnt)
+		names = append(names, name)
+	}
+	m.mu.Unlock()
+	err := m.removeLocks(s, names)
+	return count, errors.Trace(err)
+}
+
+// removeLocks removes the locks from the store if they are still owned by the session.
+func (m *LockManager) removeLocks(s *session, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	err := kv.RunInNewTxn(m.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		for _, name := range names {
+			o, err := getOwner(t, name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if o == nil || !m.isOwner(s, o) {
+				continue
+			}
+			if err = t.RemoveUserLock(name); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return errors.Trace(err)
+}
+
+// IsUsedLock implements userlock.Manager IsUsedLock interface.
+func (m *LockManager) IsUsedLock(name string) (uint64, bool, error) {
+	name = strings.ToLower(name)
+	var o *lockOwner
+	err := kv.RunInNewTxn(m.store, false, func(txn kv.Transaction) error {
+		var err error
+		o, err = getOwner(meta.NewMeta(txn), name)
+		return errors.Trace(err)
+	})
+	if err != nil {
+		return 0, false, errors.Trace(err)
+	}
+	if o == nil || o.expired(time.Now()) {
+		return 0, false, nil
+	}
+	return o.ConnID, true, nil
+}
+
+// renewLoop extends the expire time of the locks held by the sessions periodically,
+// so they don't expire as long as the TiDB server is alive.
+func (m *LockManager) renewLoop() {
+	defer m.wg.Done()
+	ticker := time.NewTicker(LockTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.renew(); err != nil {
+				log.Warnf("[userlocks] renew locks failed %v", errors.ErrorStack(err))
+			}
+		case <-m.exit:
+			return
+		}
+	}
+}
+
+// heldLock is a lock held by a session, it's a snapshot of the locks to renew.
+type heldLock struct {
+	s    *session
+	name string
+}
+
+// renew extends the expire time of the locks in one transaction. The held locks are copied under m.mu, and the
+// store is written without holding it, so a slow store doesn't block the other sessions.
+func (m *LockManager) renew() error {
+	m.mu.Lock()
+	var locks []heldLock
+	for _, s := range m.mu.sessions {
+		for name := range s.locks {
+			locks = append(locks, heldLock{s: s, name: name})
+		}
+	}
+	m.mu.Unlock()
+	if len(locks) == 0 {
+		return nil
+	}
+	err := kv.RunInNewTxn(m.store, true, func(txn kv.Transaction) error {
+		t := meta.NewMeta(txn)
+		for _, l := range locks {
+			o, err := getOwner(t, l.name)
+			if err != nil {
+				return errors.Trace(err)
+			}
+			if o == nil {
+				// The lock is released after the snapshot.
+				continue
+			}
+			if !m.isOwner(l.s, o) {
+				// The lock expired and was acquired by another session.
+				log.Warnf("[userlocks] lock %s of connection %d is lost", l.name, l.s.connID)
+				continue
+			}
+			if err = setOwner(t, l.name, m.newOwner(l.s)); err != nil {
+				return errors.Trace(err)
+			}
+		}
+		return nil
+	})
+	return errors.Trace(err)
+}
+
+func getOwner(t *meta.Meta, name string) (*lockOwner, error) {
+	data, err := t.GetUserLock(name)
+	if err != nil || data == nil {
+		return nil, errors.Trace(err)
+	}
+	o := &lockOwner{}
+	err = json.Unmarshal(data, o)
+	return o, errors.Trace(err)
+}
+
+func setOwner(t *meta.Meta, name string, o *lockOwner) error {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Trace(t.SetUserLock(name, data))
+}
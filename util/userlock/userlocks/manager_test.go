@@ -0,0 +1,170 @@
+// Copyright 2017 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package userlocks_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juju/errors"
+	. "github.com/pingcap/check"
+	"github.com/pingcap/tidb/kv"
+	"github.com/pingcap/tidb/store/tikv"
+	"github.com/pingcap/tidb/util/mock"
+	"github.com/pingcap/tidb/util/testleak"
+	"github.com/pingcap/tidb/util/userlock/userlocks"
+	goctx "golang.org/x/net/context"
+)
+
+func TestT(t *testing.T) {
+	CustomVerboseFlag = true
+	TestingT(t)
+}
+
+var _ = Suite(&testUserLockSuite{})
+
+type testUserLockSuite struct {
+	store kv.Storage
+}
+
+func (s *testUserLockSuite) SetUpSuite(c *C) {
+	store, err := tikv.NewMockTikvStore()
+	c.Assert(err, IsNil)
+	s.store = store
+}
+
+func (s *testUserLockSuite) TearDownSuite(c *C) {
+	s.store.Close()
+}
+
+func newContext(connID uint64) *mock.Context {
+	ctx := mock.NewContext()
+	ctx.GetSessionVars().ConnectionID = connID
+	return ctx
+}
+
+// cancelContext is a mock context whose execution can be cancelled.
+type cancelContext struct {
+	*mock.Context
+	goCtx goctx.Context
+}
+
+func (c *cancelContext) GoCtx() goctx.Context {
+	return c.goCtx
+}
+
+func (s *testUserLockSuite) TestUserLock(c *C) {
+	defer testleak.AfterTest(c)()
+	// The managers simulate two TiDB servers sharing the same store.
+	m1 := userlocks.NewLockManager(s.store)
+	defer m1.Close()
+	m2 := userlocks.NewLockManager(s.store)
+	defer m2.Close()
+	ctx1, ctx2, ctx3 := newContext(1), newContext(2), newContext(3)
+
+	ok, err := m1.GetLock(ctx1, "a", 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	// The lock can be acquired by the same session again, and the names are case insensitive.
+	ok, err = m1.GetLock(ctx1, "A", 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	ok, err = m1.GetLock(ctx2, "a", 10*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	ok, err = m2.GetLock(ctx3, "a", 10*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	connID, used, err := m2.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsTrue)
+	c.Assert(connID, Equals, uint64(1))
+
+	ok, err = m1.ReleaseLock(ctx2, "a")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+	ok, err = m1.ReleaseLock(ctx1, "a")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	_, used, err = m2.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsTrue)
+
+	// The waiting session gets the lock after it's released.
+	done := make(chan bool)
+	go func() {
+		ok, err := m2.GetLock(ctx3, "a", -1)
+		c.Assert(err, IsNil)
+		done <- ok
+	}()
+	time.Sleep(20 * time.Millisecond)
+	ok, err = m1.ReleaseLock(ctx1, "a")
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	c.Assert(<-done, IsTrue)
+
+	// The waiting without timeout is stopped when the statement is cancelled.
+	goCtx, cancel := goctx.WithCancel(goctx.Background())
+	ctx4 := &cancelContext{Context: newContext(4), goCtx: goCtx}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	ok, err = m1.GetLock(ctx4, "a", -1)
+	c.Assert(errors.Cause(err), Equals, goctx.Canceled)
+	c.Assert(ok, IsFalse)
+
+	ok, err = m2.GetLock(ctx3, "b", 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	count, err := m2.ReleaseAllLocks(ctx3)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(2))
+	_, used, err = m1.IsUsedLock("a")
+	c.Assert(err, IsNil)
+	c.Assert(used, IsFalse)
+	count, err = m2.ReleaseAllLocks(ctx3)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(0))
+}
+
+func (s *testUserLockSuite) TestLockExpire(c *C) {
+	defer testleak.AfterTest(c)()
+	originTTL := userlocks.LockTTL
+	userlocks.LockTTL = 150 * time.Millisecond
+	defer func() {
+		userlocks.LockTTL = originTTL
+	}()
+	m1 := userlocks.NewLockManager(s.store)
+	m2 := userlocks.NewLockManager(s.store)
+	defer m2.Close()
+	ctx1, ctx2 := newContext(1), newContext(2)
+
+	ok, err := m1.GetLock(ctx1, "c", 0)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	// The lock is renewed, so it doesn't expire while the server of the owner is alive.
+	ok, err = m2.GetLock(ctx2, "c", 300*time.Millisecond)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsFalse)
+
+	// The lock expires if it's not renewed any more.
+	m1.Close()
+	ok, err = m2.GetLock(ctx2, "c", time.Second)
+	c.Assert(err, IsNil)
+	c.Assert(ok, IsTrue)
+	count, err := m2.ReleaseAllLocks(ctx2)
+	c.Assert(err, IsNil)
+	c.Assert(count, Equals, int64(1))
+}